@@ -0,0 +1,1214 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"d3-domain-tool/internal/altroot"
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/audit"
+	"d3-domain-tool/internal/blockchain"
+	"d3-domain-tool/internal/brand"
+	"d3-domain-tool/internal/checker"
+	"d3-domain-tool/internal/cloud"
+	"d3-domain-tool/internal/collateral"
+	"d3-domain-tool/internal/compare"
+	"d3-domain-tool/internal/config"
+	"d3-domain-tool/internal/dnsaudit"
+	"d3-domain-tool/internal/doma"
+	"d3-domain-tool/internal/evidence"
+	"d3-domain-tool/internal/history"
+	"d3-domain-tool/internal/iac"
+	"d3-domain-tool/internal/identity"
+	"d3-domain-tool/internal/monitor"
+	"d3-domain-tool/internal/notify"
+	"d3-domain-tool/internal/output"
+	"d3-domain-tool/internal/policy"
+	"d3-domain-tool/internal/portfolio"
+	"d3-domain-tool/internal/query"
+	"d3-domain-tool/internal/rawarchive"
+	"d3-domain-tool/internal/renewal"
+	"d3-domain-tool/internal/schedule"
+	"d3-domain-tool/internal/server"
+	"d3-domain-tool/internal/signing"
+	"d3-domain-tool/internal/store"
+	"d3-domain-tool/internal/subdomains"
+	"d3-domain-tool/internal/suggest"
+	"d3-domain-tool/internal/usage"
+	"d3-domain-tool/internal/version"
+	"d3-domain-tool/internal/watch"
+	"d3-domain-tool/internal/whois"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// commands maps subcommand names to their entry points. Each receives the
+// arguments after the subcommand name.
+var commands = map[string]func(args []string) error{
+	"analyze":        runAnalyze,
+	"audit-export":   runAuditExport,
+	"audit-iac":      runAuditIAC,
+	"cloudflare":     runCloudflare,
+	"collateral":     runCollateral,
+	"compare":        runCompare,
+	"dns-audit":      runDNSAudit,
+	"ens-bulk-renew": runENSBulkRenew,
+	"ens-renewals":   runENSRenewals,
+	"history":        runHistory,
+	"identity":       runIdentity,
+	"monitor":        runMonitor,
+	"monitor-brand":  runMonitorBrand,
+	"policy":         runPolicy,
+	"raw-archive":    runRawArchive,
+	"rpc-health":     runRPCHealth,
+	"serve":          runServe,
+	"subdomains":     runSubdomains,
+	"suggest":        runSuggest,
+	"verify":         runVerify,
+	"version":        runVersion,
+	"watch":          runWatch,
+}
+
+// domainArg returns the single positional domain argument of a subcommand.
+func domainArg(fs *flag.FlagSet) (string, error) {
+	if fs.NArg() != 1 {
+		return "", fmt.Errorf("%s: expected exactly one domain", fs.Name())
+	}
+	domain := analyzer.Canonicalize(fs.Arg(0))
+	if domain == "" {
+		return "", fmt.Errorf("domain cannot be empty")
+	}
+	return domain, nil
+}
+
+func runAuditIAC(args []string) error {
+	fs := flag.NewFlagSet("audit-iac", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	domains := fs.String("domains", "", "Comma-separated registered domains that should each have a zone")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("audit-iac: expected Terraform state, zone export or zone files")
+	}
+	inv, err := iac.Load(fs.Args()...)
+	if err != nil {
+		return err
+	}
+	report := iac.NewAuditor().Audit(inv, splitList(*domains))
+	return output.NewFormatter(*format).DisplayIACAudit(report)
+}
+
+func runCloudflare(args []string) error {
+	fs := flag.NewFlagSet("cloudflare", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	token := fs.String("token", "", "Cloudflare API token (default: $CLOUDFLARE_API_TOKEN)")
+	rules := fs.String("rules", "", "Also check every zone against this JSON policy file")
+	strict := fs.Bool("strict", false, "With -rules, also fail when a rule could not be evaluated")
+	fs.Parse(args)
+
+	var evaluator *policy.Evaluator
+	if *rules != "" {
+		p, err := policy.Load(*rules)
+		if err != nil {
+			return err
+		}
+		if evaluator, err = policy.NewEvaluator(p); err != nil {
+			return err
+		}
+	}
+
+	report, err := cloud.NewCloudflare(*token).Account()
+	if err != nil {
+		return err
+	}
+
+	a := analyzer.New()
+	var names []string
+	for _, z := range report.Zones {
+		names = append(names, z.Name)
+		if z.Analysis, err = a.AnalyzeDomain(z.Name); err != nil {
+			return err
+		}
+		if z.Analysis.WhoisData != nil {
+			z.CheckDelegation(z.Analysis.WhoisData.NameServers)
+		}
+	}
+	if evaluator != nil {
+		report.Policy = evaluator.Evaluate(names)
+	}
+
+	if err := output.NewFormatter(*format).DisplayCloudflare(report); err != nil {
+		return err
+	}
+	if report.Policy != nil && !report.Policy.OK(*strict) {
+		return fmt.Errorf("cloudflare: %d policy rule(s) failed, %d skipped", report.Policy.Failed, report.Policy.Skipped)
+	}
+	return nil
+}
+
+func runCollateral(args []string) error {
+	fs := flag.NewFlagSet("collateral", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	value := fs.Int("value", 0, "Domain value in USD (default: the valuation estimate)")
+	ltv := fs.String("ltv", "", "Lending platforms: name=ltv[/apr], comma-separated, e.g. NFTfi=0.3/0.15")
+	domaKey := fs.String("doma-api-key", os.Getenv("DOMA_API_KEY"), "DOMA API key; adds DOMA Lending's live terms")
+	domaAPI := fs.String("doma-api", "", "DOMA API base URL (default: https://api.doma.xyz)")
+	fs.Parse(args)
+
+	domain, err := domainArg(fs)
+	if err != nil {
+		return err
+	}
+	platforms, err := collateral.ParsePlatforms(*ltv)
+	if err != nil {
+		return err
+	}
+
+	var client *doma.Client
+	if *domaKey != "" {
+		client = doma.NewClient()
+		client.SetAPIKey(*domaKey)
+		if *domaAPI != "" {
+			client.SetEndpoint(*domaAPI)
+		}
+	}
+
+	report, err := collateral.NewCalculator(platforms, client).Calculate(domain, *value)
+	if err != nil {
+		return err
+	}
+	return output.NewFormatter(*format).DisplayCollateral(report)
+}
+
+func runDNSAudit(args []string) error {
+	fs := flag.NewFlagSet("dns-audit", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	fs.Parse(args)
+
+	domain, err := domainArg(fs)
+	if err != nil {
+		return err
+	}
+
+	report, err := dnsaudit.NewAuditor().Audit(domain)
+	if err != nil {
+		return err
+	}
+	return output.NewFormatter(*format).DisplayDNSAudit(report)
+}
+
+func runENSBulkRenew(args []string) error {
+	fs := flag.NewFlagSet("ens-bulk-renew", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format for the summary: table, json")
+	rpcURL := fs.String("eth-rpc", "", "Ethereum JSON-RPC URL for expiries and the renewal fee quote (required)")
+	within := fs.Int("within", 30, "Renew names expiring within this many days")
+	years := fs.Int("years", 1, "Years to renew for")
+	safe := fs.String("safe", "", "Safe address to record in the bundle")
+	out := fs.String("out", "", "Write the Safe Transaction Builder JSON to this file and print a summary (default: print the JSON)")
+	fs.Parse(args)
+
+	if *rpcURL == "" {
+		return fmt.Errorf("ens-bulk-renew: -eth-rpc is required")
+	}
+	var names []string
+	for _, arg := range fs.Args() {
+		names = append(names, splitList(arg)...)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("ens-bulk-renew: expected one or more .eth names")
+	}
+
+	batch, err := renewal.NewAdvisor(blockchain.NewRPCClient(*rpcURL)).Batch(names, *within, *years, *safe)
+	if err != nil {
+		return err
+	}
+	if batch.Bundle == nil {
+		return output.NewFormatter(*format).DisplayRenewalBatch(batch)
+	}
+
+	data, err := json.MarshalIndent(batch.Bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return output.NewFormatter(*format).DisplayRenewalBatch(batch)
+}
+
+func runENSRenewals(args []string) error {
+	fs := flag.NewFlagSet("ens-renewals", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	rpcURL := fs.String("eth-rpc", "", "Ethereum JSON-RPC URL for gas prices, expiries and the ETH/USD feed (required)")
+	history := fs.Duration("history", renewal.DefaultHistory, "How far back to sample base fees")
+	ethUSD := fs.Float64("eth-usd", 0, "ETH price in USD (default: the Chainlink ETH/USD feed)")
+	fs.Parse(args)
+
+	if *rpcURL == "" {
+		return fmt.Errorf("ens-renewals: -eth-rpc is required")
+	}
+	var names []string
+	for _, arg := range fs.Args() {
+		names = append(names, splitList(arg)...)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("ens-renewals: expected one or more .eth names")
+	}
+
+	advisor := renewal.NewAdvisor(blockchain.NewRPCClient(*rpcURL))
+	advisor.SetHistory(*history)
+	advisor.SetETHPrice(*ethUSD)
+
+	report, err := advisor.Plan(names)
+	if err != nil {
+		return err
+	}
+	return output.NewFormatter(*format).DisplayRenewals(report)
+}
+
+func runIdentity(args []string) error {
+	fs := flag.NewFlagSet("identity", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	tlds := fs.String("tlds", "", "Comma-separated DNS TLDs to check (default: com,net,org,io)")
+	web3 := fs.String("web3", "", "Comma-separated blockchain TLDs to check (default: eth,crypto,sol)")
+	platforms := fs.String("platforms", "", "Replace the social platforms: name=profile-url-with-%s, comma-separated")
+	owners := fs.String("owner", "", "Comma-separated identifiers of the brand's own assets: wallet addresses, organization names, email domains")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("identity: expected exactly one name, e.g. acme")
+	}
+	socials, err := identity.ParsePlatforms(*platforms)
+	if err != nil {
+		return err
+	}
+
+	c := identity.NewChecker(analyzer.New())
+	c.SetTLDs(splitList(*tlds))
+	c.SetWeb3(splitList(*web3))
+	c.SetPlatforms(socials)
+	c.SetOwners(splitList(*owners))
+
+	report, err := c.Check(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return output.NewFormatter(*format).DisplayIdentity(report)
+}
+
+func runPolicy(args []string) error {
+	fs := flag.NewFlagSet("policy", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	rules := fs.String("rules", "", "JSON policy file (required)")
+	strict := fs.Bool("strict", false, "Also fail when a rule could not be evaluated")
+	fs.Parse(args)
+
+	if *rules == "" {
+		return fmt.Errorf("policy: -rules is required")
+	}
+	var domains []string
+	for _, arg := range fs.Args() {
+		domains = append(domains, splitList(arg)...)
+	}
+	if len(domains) == 0 {
+		return fmt.Errorf("policy: expected one or more domains")
+	}
+
+	p, err := policy.Load(*rules)
+	if err != nil {
+		return err
+	}
+	evaluator, err := policy.NewEvaluator(p)
+	if err != nil {
+		return err
+	}
+
+	report := evaluator.Evaluate(domains)
+	if err := output.NewFormatter(*format).DisplayPolicy(report); err != nil {
+		return err
+	}
+	if !report.OK(*strict) {
+		return fmt.Errorf("policy: %d rule(s) failed, %d skipped", report.Failed, report.Skipped)
+	}
+	return nil
+}
+
+func runRPCHealth(args []string) error {
+	fs := flag.NewFlagSet("rpc-health", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	rpcURL := fs.String("eth-rpc", "", "Comma-separated Ethereum JSON-RPC URLs to check (required)")
+	fs.Parse(args)
+
+	if *rpcURL == "" {
+		return fmt.Errorf("rpc-health: -eth-rpc is required")
+	}
+	health := blockchain.NewRPCClient(*rpcURL).Health()
+	if err := output.NewFormatter(*format).DisplayRPCHealth(health); err != nil {
+		return err
+	}
+	for _, h := range health {
+		if h.Healthy {
+			return nil
+		}
+	}
+	return fmt.Errorf("rpc-health: no healthy endpoint")
+}
+
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "Address to listen on")
+	rpcURL := fs.String("eth-rpc", "", "Ethereum JSON-RPC URL, or several comma-separated to fail over")
+	udKey := fs.String("ud-api-key", "", "Unstoppable Domains Resolution API key")
+	domaKey := fs.String("doma-api-key", os.Getenv("DOMA_API_KEY"), "DOMA API key for tokenization status")
+	domaAPI := fs.String("doma-api", "", "DOMA API base URL (default: https://api.doma.xyz)")
+	subgraph := fs.String("ens-subgraph", "", "ENS subgraph GraphQL URL for .eth name history")
+	tenants := fs.String("tenants", "", "JSON file of tenants and their API keys (default: no API keys required)")
+	dataDir := fs.String("data-dir", "", "Directory to keep each tenant's history, watchlist and portfolios in")
+	auditPath := fs.String("audit-log", "", "Append every analysis request and the WHOIS and HTTP queries it causes to this log")
+	budgets := fs.String("budgets", "", "Soft limits of calls per paid provider and -budget-period, e.g. whoisxml=1000,eth_rpc=50000")
+	period := fs.Duration("budget-period", 24*time.Hour, "Period after which the -budgets start over (0 = never)")
+	profile := fs.String("profile", "standard", "Analysis depth: quick (DNS only), standard or deep")
+	timeout := fs.Duration("timeout", 0, "Bound each DOMA, blockchain, WHOIS, RDAP and DNS lookup (0 = client defaults)")
+	privateCallbacks := fs.Bool("allow-private-callbacks", false, "Let bulk request callback URLs reach private, loopback and link-local addresses")
+	metricsToken := fs.String("metrics-token", os.Getenv("D3_METRICS_TOKEN"), "Key a Prometheus scraper reads every tenant's /metrics counters with")
+	fs.Parse(args)
+
+	depth, err := analyzer.LookupProfile(*profile)
+	if err != nil {
+		return fmt.Errorf("-profile: %v", err)
+	}
+	// -config was taken out of args; the per-TLD overrides apply here too.
+	path, given, _ := config.PathFromArgs(os.Args[2:])
+	cfg, err := config.Load(path, given)
+	if err != nil {
+		return err
+	}
+	tlds, err := tldOptions(cfg.TLDs)
+	if err != nil {
+		return err
+	}
+
+	if *budgets != "" {
+		limits, err := usage.ParseBudgets(*budgets)
+		if err != nil {
+			return fmt.Errorf("-budgets: %v", err)
+		}
+		usage.Default.SetBudgets(limits)
+	}
+	usage.Default.SetPeriod(*period)
+	usage.Default.AddHosts(usage.EthRPC, splitList(*rpcURL)...)
+	usage.Default.AddHosts(usage.ENSSubgraph, *subgraph)
+
+	var auditor *audit.Log
+	if *auditPath != "" {
+		if auditor, err = openAuditLog(*auditPath); err != nil {
+			return err
+		}
+	}
+	opts := analyzer.Options{
+		ErrorPolicy: analyzer.PolicyDegrade,
+		EthRPC:      *rpcURL,
+		UDAPIKey:    *udKey,
+		DomaAPIKey:  *domaKey,
+		DomaAPI:     *domaAPI,
+		ENSSubgraph: *subgraph,
+		AuditLog:    auditor,
+		Timeout:     *timeout,
+		TLDs:        tlds,
+	}
+	depth.Apply(&opts)
+	a := analyzer.NewWithOptions(opts)
+	s := server.New(a)
+	s.SetMeter(usage.Default)
+	s.SetAllowPrivateCallbacks(*privateCallbacks)
+	s.SetMetricsToken(*metricsToken)
+	if auditor != nil {
+		s.SetAuditLog(auditor)
+	}
+	if *tenants != "" {
+		list, err := server.LoadTenants(*tenants)
+		if err != nil {
+			return err
+		}
+		if err := s.SetTenants(list); err != nil {
+			return err
+		}
+	}
+	if *dataDir != "" {
+		st, err := store.Open(*dataDir)
+		if err != nil {
+			return err
+		}
+		s.SetStore(st)
+	}
+	fmt.Fprintf(os.Stderr, "Listening on %s (GET /v1/analyze?domain=, POST /v1/analyze/bulk, POST /graphql, gRPC d3.v1.DomainAnalysis, API docs at /docs)\n", *addr)
+	// gRPC needs HTTP/2; h2c serves it in cleartext next to HTTP/1.1.
+	return http.ListenAndServe(*addr, h2c.NewHandler(s.Handler(), &http2.Server{}))
+}
+
+func runSubdomains(args []string) error {
+	fs := flag.NewFlagSet("subdomains", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	wordlist := fs.String("wordlist", "", "File with one subdomain label per line (default: built-in list)")
+	rate := fs.Int("rate", 20, "Maximum DNS lookups per second (0 = unlimited)")
+	noCT := fs.Bool("no-ct", false, "Skip certificate transparency log lookup")
+	fs.Parse(args)
+
+	domain, err := domainArg(fs)
+	if err != nil {
+		return err
+	}
+
+	opts := subdomains.Options{Rate: *rate, SkipCT: *noCT}
+	if *wordlist != "" {
+		if opts.Wordlist, err = subdomains.LoadWordlist(*wordlist); err != nil {
+			return err
+		}
+	}
+
+	result, err := subdomains.NewEnumerator().Enumerate(domain, opts)
+	if err != nil {
+		return err
+	}
+	return output.NewFormatter(*format).DisplaySubdomains(result)
+}
+
+func runSuggest(args []string) error {
+	fs := flag.NewFlagSet("suggest", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	tlds := fs.String("tlds", strings.Join(suggest.DefaultTLDs, ","), "Comma-separated TLDs to spin the keyword across; combinations use the first two")
+	limit := fs.Int("limit", 30, "Maximum number of candidates to check")
+	availableOnly := fs.Bool("available", false, "List only names that are available")
+	rpcURL := fs.String("eth-rpc", "", "Ethereum JSON-RPC URL for .eth availability")
+	udKey := fs.String("ud-api-key", "", "Unstoppable Domains Resolution API key")
+	fs.Parse(args)
+
+	keyword := suggest.Keyword(strings.Join(fs.Args(), ""))
+	if keyword == "" {
+		return fmt.Errorf("suggest: expected a keyword, e.g. suggest fastpay")
+	}
+	candidates := suggest.Generate(keyword, suggest.Options{TLDs: splitList(*tlds), Limit: *limit})
+
+	// Only availability and valuation matter for ranking.
+	a := analyzer.NewWithOptions(analyzer.Options{
+		Only:     []string{"blockchain", "dns", "whois", "valuation"},
+		EthRPC:   *rpcURL,
+		UDAPIKey: *udKey,
+	})
+	suggestions := suggest.Analyze(candidates, 8, a.AnalyzeDomain)
+	if *availableOnly {
+		var available []suggest.Suggestion
+		for _, s := range suggestions {
+			if s.Verdict == analyzer.VerdictAvailable {
+				available = append(available, s)
+			}
+		}
+		suggestions = available
+	}
+	return output.NewFormatter(*format).DisplaySuggestions(keyword, suggestions)
+}
+
+// runMonitor manages the monitor schedules kept in the configuration file
+// (add, remove, list, import) or, without an action, runs them until
+// interrupted.
+func runMonitor(args []string) error {
+	// The schedules live in the configuration file, which the command
+	// rewrites; -config was taken out of args, so find it again.
+	path, given, _ := config.PathFromArgs(os.Args[2:])
+	cfg, err := config.Load(path, given)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		switch args[0] {
+		case "add", "remove", "list":
+			return monitorSchedules(path, cfg, args[0], args[1:])
+		case "import":
+			return monitorImport(path, cfg, args[1:])
+		}
+	}
+
+	fs := flag.NewFlagSet("monitor", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	webhook := fs.String("webhook", "", "POST each poll with changes as JSON to this URL")
+	notifyURLs := fs.String("notify", "", "Comma-separated URLs to POST the analysis of each changed domain to, HMAC-signed with -notify-secret")
+	secret := fs.String("notify-secret", os.Getenv("D3_WEBHOOK_SECRET"), "Key signing -notify requests (X-D3-Signature: sha256=<HMAC-SHA256 of the body>)")
+	statePath := fs.String("state", "", "Keep the last known state of each domain in this file across restarts")
+	rpcURL := fs.String("eth-rpc", "", "Ethereum JSON-RPC URL for .eth availability and expiry")
+	udKey := fs.String("ud-api-key", "", "Unstoppable Domains Resolution API key")
+	domaKey := fs.String("doma-api-key", os.Getenv("DOMA_API_KEY"), "DOMA API key for tokenization status")
+	domaAPI := fs.String("doma-api", "", "DOMA API base URL (default: https://api.doma.xyz)")
+	auditPath := fs.String("audit-log", "", "Append every WHOIS query and HTTP request to this log")
+	fs.Parse(args)
+
+	if len(cfg.Monitor) == 0 {
+		return fmt.Errorf("monitor: no domains scheduled; add one with: monitor add example.com @daily")
+	}
+	var entries []monitor.Entry
+	for _, m := range cfg.Monitor {
+		s, _ := schedule.Parse(m.Schedule)
+		entries = append(entries, monitor.Entry{Domain: analyzer.Canonicalize(m.Domain), Schedule: s})
+	}
+	notifier, err := newNotifier(*notifyURLs, *secret)
+	if err != nil {
+		return fmt.Errorf("monitor: -notify: %v", err)
+	}
+
+	var auditor *audit.Log
+	if *auditPath != "" {
+		if auditor, err = openAuditLog(*auditPath); err != nil {
+			return err
+		}
+	}
+	w := watch.NewWatcher(analyzer.NewWithOptions(analyzer.Options{
+		EthRPC:     *rpcURL,
+		UDAPIKey:   *udKey,
+		DomaAPIKey: *domaKey,
+		DomaAPI:    *domaAPI,
+		Only:       watch.Modules,
+		AuditLog:   auditor,
+	}))
+	if *statePath != "" {
+		states, err := monitor.LoadState(*statePath)
+		if err != nil {
+			return fmt.Errorf("monitor: reading state: %v", err)
+		}
+		w.Restore(states)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	formatter := output.NewFormatter(*format)
+	fmt.Fprintf(os.Stderr, "Monitoring %d domains (Ctrl-C to stop)\n", len(entries))
+	err = monitor.New(w, entries).Run(ctx, func(result *watch.Result) {
+		if err := formatter.DisplayWatch(result); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		if *webhook != "" && len(result.Changes) > 0 {
+			if err := watch.Alert(*webhook, result); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+		if notifier != nil {
+			if err := notifier.Notify(result); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+		if *statePath != "" {
+			if err := monitor.SaveState(*statePath, w.States()); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: saving state: %v\n", err)
+			}
+		}
+	})
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
+
+// monitorSchedules adds, removes or lists the monitor entries of the
+// configuration file at path.
+func monitorSchedules(path string, cfg *config.Config, action string, args []string) error {
+	if action == "list" {
+		if len(cfg.Monitor) == 0 {
+			fmt.Println("No domains scheduled")
+		}
+		for _, m := range cfg.Monitor {
+			fmt.Printf("%s\t%s\n", m.Domain, m.Schedule)
+		}
+		return nil
+	}
+	if path == "" {
+		return fmt.Errorf("monitor: no configuration file; pass -config")
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("monitor %s: expected a domain", action)
+	}
+	domain := analyzer.Canonicalize(args[0])
+	var kept []config.MonitorEntry
+	for _, m := range cfg.Monitor {
+		if m.Domain != domain {
+			kept = append(kept, m)
+		}
+	}
+	switch action {
+	case "add":
+		// Cron expressions hold spaces; accept them quoted or not.
+		spec := strings.Join(args[1:], " ")
+		if spec == "" {
+			return fmt.Errorf("monitor add: expected a schedule, e.g. monitor add %s @daily", domain)
+		}
+		if _, err := schedule.Parse(spec); err != nil {
+			return err
+		}
+		kept = append(kept, config.MonitorEntry{Domain: domain, Schedule: spec})
+	case "remove":
+		if len(kept) == len(cfg.Monitor) {
+			return fmt.Errorf("monitor remove: %s is not scheduled", domain)
+		}
+	}
+	cfg.Monitor = kept
+	if err := config.Save(path, cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Saved %d monitor schedules to %s\n", len(kept), path)
+	return nil
+}
+
+// monitorImport adds the domains of other services' watchlists to the
+// monitor entries of the configuration file at path. Domains already
+// scheduled keep their schedule.
+func monitorImport(path string, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("monitor import", flag.ExitOnError)
+	spec := fs.String("schedule", "@daily", "Schedule of imported domains whose list gives none this tool understands")
+	fs.Parse(args)
+
+	if path == "" {
+		return fmt.Errorf("monitor: no configuration file; pass -config")
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("monitor import: expected a watchlist file or URL")
+	}
+	if _, err := schedule.Parse(*spec); err != nil {
+		return fmt.Errorf("monitor import: -schedule: %v", err)
+	}
+	scheduled := map[string]bool{}
+	for _, m := range cfg.Monitor {
+		scheduled[m.Domain] = true
+	}
+	added, skipped := 0, 0
+	for _, source := range fs.Args() {
+		list, err := monitor.LoadWatchlist(source)
+		if err != nil {
+			return fmt.Errorf("monitor import: %s: %v", source, err)
+		}
+		for _, e := range list {
+			if scheduled[e.Domain] {
+				skipped++
+				continue
+			}
+			scheduled[e.Domain] = true
+			if e.Schedule == "" {
+				e.Schedule = *spec
+			}
+			cfg.Monitor = append(cfg.Monitor, config.MonitorEntry{Domain: e.Domain, Schedule: e.Schedule})
+			added++
+		}
+	}
+	if err := config.Save(path, cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Imported %d domains (%d already scheduled); %d monitor schedules saved to %s\n", added, skipped, len(cfg.Monitor), path)
+	return nil
+}
+
+func runMonitorBrand(args []string) error {
+	fs := flag.NewFlagSet("monitor-brand", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	keywords := fs.String("keywords", "", "Comma-separated brand keywords (required)")
+	feeds := fs.String("feed", "", "Comma-separated newly-registered-domain feeds: URLs or files, one domain per line or a zone file")
+	zoneOld := fs.String("zone-old", "", "Previous copy of a zone file; with -zone-new only added names are matched")
+	zoneNew := fs.String("zone-new", "", "Current copy of the zone file given in -zone-old")
+	maxDist := fs.Int("max-distance", 1, "Largest edit distance reported as a typo (0 disables typo matching)")
+	interval := fs.Duration("interval", 0, "Poll the feeds again at this interval (0 = run once)")
+	webhook := fs.String("webhook", "", "POST each poll with hits as JSON to this URL")
+	fs.Parse(args)
+
+	words := splitList(*keywords)
+	if len(words) == 0 {
+		return fmt.Errorf("monitor-brand: -keywords is required")
+	}
+
+	var sources []brand.Feed
+	for _, feed := range splitList(*feeds) {
+		sources = append(sources, brand.Feed{Source: feed})
+	}
+	if (*zoneOld == "") != (*zoneNew == "") {
+		return fmt.Errorf("monitor-brand: -zone-old and -zone-new must be given together")
+	}
+	if *zoneNew != "" {
+		sources = append(sources, brand.Feed{Source: *zoneNew, Baseline: *zoneOld})
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("monitor-brand: give at least one -feed or -zone-old/-zone-new pair")
+	}
+
+	monitor := brand.NewMonitor(brand.NewMatcher(words, *maxDist))
+	formatter := output.NewFormatter(*format)
+	for {
+		result := monitor.Poll(sources)
+		if err := formatter.DisplayBrandHits(result); err != nil {
+			return err
+		}
+		if *webhook != "" && len(result.Hits) > 0 {
+			if err := brand.Alert(*webhook, result); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+		if *interval <= 0 {
+			return nil
+		}
+		time.Sleep(*interval)
+	}
+}
+
+func runWatch(args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	interval := fs.Duration("interval", 15*time.Minute, "Time between polls")
+	count := fs.Int("count", 0, "Stop after this many polls (0 = keep watching)")
+	webhook := fs.String("webhook", "", "POST each poll with changes as JSON to this URL")
+	notifyURLs := fs.String("notify", "", "Comma-separated URLs to POST the analysis of each changed domain to, HMAC-signed with -notify-secret")
+	secret := fs.String("notify-secret", os.Getenv("D3_WEBHOOK_SECRET"), "Key signing -notify requests (X-D3-Signature: sha256=<HMAC-SHA256 of the body>)")
+	rpcURL := fs.String("eth-rpc", "", "Ethereum JSON-RPC URL for .eth availability and expiry")
+	udKey := fs.String("ud-api-key", "", "Unstoppable Domains Resolution API key")
+	domaKey := fs.String("doma-api-key", os.Getenv("DOMA_API_KEY"), "DOMA API key for tokenization status")
+	domaAPI := fs.String("doma-api", "", "DOMA API base URL (default: https://api.doma.xyz)")
+	auditPath := fs.String("audit-log", "", "Append every WHOIS query and HTTP request to this log")
+	fs.Parse(args)
+
+	var names []string
+	for _, arg := range fs.Args() {
+		for _, name := range splitList(arg) {
+			names = append(names, analyzer.Canonicalize(name))
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("watch: expected at least one domain")
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("watch: -interval must be positive")
+	}
+	notifier, err := newNotifier(*notifyURLs, *secret)
+	if err != nil {
+		return fmt.Errorf("watch: -notify: %v", err)
+	}
+
+	var auditor *audit.Log
+	if *auditPath != "" {
+		if auditor, err = openAuditLog(*auditPath); err != nil {
+			return err
+		}
+	}
+	w := watch.NewWatcher(analyzer.NewWithOptions(analyzer.Options{
+		EthRPC:     *rpcURL,
+		UDAPIKey:   *udKey,
+		DomaAPIKey: *domaKey,
+		DomaAPI:    *domaAPI,
+		Only:       watch.Modules,
+		AuditLog:   auditor,
+	}))
+	formatter := output.NewFormatter(*format)
+	for poll := 1; ; poll++ {
+		result := w.Poll(names)
+		if err := formatter.DisplayWatch(result); err != nil {
+			return err
+		}
+		if *webhook != "" && len(result.Changes) > 0 {
+			if err := watch.Alert(*webhook, result); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+		if notifier != nil {
+			if err := notifier.Notify(result); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+		if *count > 0 && poll >= *count {
+			return nil
+		}
+		time.Sleep(*interval)
+	}
+}
+
+// newNotifier returns the notifier of a -notify list, nil when it is
+// empty.
+func newNotifier(urls, secret string) (*notify.Notifier, error) {
+	list := splitList(urls)
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return notify.New(list, secret)
+}
+
+// runHistory lists the past analyses of a domain kept with -cache-dir (or
+// by serve with -data-dir) and what changed between them.
+func runHistory(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	cacheDir := fs.String("cache-dir", "", "Directory the results were kept in with -cache-dir, or serve's -data-dir")
+	tenant := fs.String("tenant", "local", "Whose history to read: local for -cache-dir, or a serve tenant (default without -tenants)")
+	limit := fs.Int("limit", 0, "Show only the most recent analyses (0 = all)")
+	changed := fs.Bool("changes", false, "Show only analyses in which something changed")
+	fs.Parse(args)
+
+	domain, err := domainArg(fs)
+	if err != nil {
+		return err
+	}
+	if *cacheDir == "" {
+		return fmt.Errorf("history: -cache-dir is required (results are kept by runs with -cache-dir)")
+	}
+	st, err := store.Open(*cacheDir)
+	if err != nil {
+		return err
+	}
+	ns, err := st.Namespace(*tenant)
+	if err != nil {
+		return err
+	}
+	results, err := ns.History(domain)
+	if err != nil {
+		return err
+	}
+
+	entries := history.Build(results)
+	if *changed {
+		var kept []history.Entry
+		for _, e := range entries {
+			if len(e.Changes) > 0 {
+				kept = append(kept, e)
+			}
+		}
+		entries = kept
+	}
+	if *limit > 0 && len(entries) > *limit {
+		entries = entries[len(entries)-*limit:]
+	}
+	return output.NewFormatter(*format).DisplayHistory(domain, len(results), entries)
+}
+
+func runAuditExport(args []string) error {
+	fs := flag.NewFlagSet("audit-export", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json, csv")
+	since := fs.String("since", "", "Only entries at or after this date (YYYY-MM-DD or RFC 3339)")
+	until := fs.String("until", "", "Only entries before this date (YYYY-MM-DD or RFC 3339)")
+	domain := fs.String("domain", "", "Only entries about this domain")
+	actor := fs.String("actor", "", "Only entries made by this user or tenant")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("audit-export: expected exactly one log file")
+	}
+	filter := audit.Filter{Domain: strings.TrimSpace(*domain), Actor: *actor}
+	var err error
+	if filter.Since, err = parseTime(*since); err != nil {
+		return fmt.Errorf("audit-export: -since: %v", err)
+	}
+	if filter.Until, err = parseTime(*until); err != nil {
+		return fmt.Errorf("audit-export: -until: %v", err)
+	}
+
+	export, err := audit.NewExport(fs.Arg(0), filter)
+	if err != nil {
+		return err
+	}
+	if err := output.NewFormatter(*format).DisplayAuditExport(export); err != nil {
+		return err
+	}
+	if !export.Verified {
+		return fmt.Errorf("audit log failed verification: %s", export.VerifyError)
+	}
+	return nil
+}
+
+func runRawArchive(args []string) error {
+	fs := flag.NewFlagSet("raw-archive", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	dir := fs.String("dir", "", "Directory written by -raw-archive")
+	reparse := fs.Bool("reparse", false, "Parse archived WHOIS responses again with the current parser")
+	fs.Parse(args)
+
+	domain, err := domainArg(fs)
+	if err != nil {
+		return err
+	}
+	if *dir == "" {
+		return fmt.Errorf("raw-archive: -dir is required")
+	}
+	a, err := rawarchive.Open(*dir, 0)
+	if err != nil {
+		return err
+	}
+	entries, err := a.List(domain)
+	if err != nil {
+		return err
+	}
+	if *reparse {
+		client := whois.NewClient()
+		for i, e := range entries {
+			if e.Protocol != rawarchive.WHOIS {
+				continue
+			}
+			raw, err := rawarchive.Read(e)
+			if err != nil {
+				return err
+			}
+			parsed := client.Parse(domain, string(raw))
+			parsed.CheckedAt = e.Time
+			parsed.RawData = ""
+			entries[i].Parsed = parsed
+		}
+	}
+	return output.NewFormatter(*format).DisplayRawArchive(domain, entries)
+}
+
+// parseTime parses a date or an RFC 3339 time; empty is the zero time.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	pubKey := fs.String("pubkey", "", "Trusted signer public key (PEM); without it only integrity is checked")
+	tsaCA := fs.String("tsa-ca", "", "CA certificates (PEM) the TSA's certificate must chain to; without it timestamps are unverified")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("verify: expected exactly one file (use - for stdin)")
+	}
+
+	var trusted ed25519.PublicKey
+	if *pubKey != "" {
+		var err error
+		if trusted, err = signing.LoadPublicKey(*pubKey); err != nil {
+			return err
+		}
+	}
+	var tsaRoots *x509.CertPool
+	if *tsaCA != "" {
+		var err error
+		if tsaRoots, err = signing.LoadCertPool(*tsaCA); err != nil {
+			return err
+		}
+	}
+
+	in := os.Stdin
+	if name := fs.Arg(0); name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", name, err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	// A multi-domain run writes one envelope per report.
+	decoder := json.NewDecoder(in)
+	failed, unverified := 0, false
+	for n := 1; ; n++ {
+		var env signing.Envelope
+		if err := decoder.Decode(&env); err == io.EOF {
+			if n == 1 {
+				return fmt.Errorf("no signed results found")
+			}
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to parse signed result %d: %v", n, err)
+		}
+
+		if err := signing.Verify(&env, trusted, tsaRoots); err != nil {
+			fmt.Printf("❌ Result %d: %v\n", n, err)
+			failed++
+			continue
+		}
+		var proof []string
+		if env.Signature != "" {
+			pub, _ := base64.StdEncoding.DecodeString(env.PublicKey)
+			proof = append(proof, fmt.Sprintf("signed %s by key %s", env.SignedAt.Format(time.RFC3339), signing.Fingerprint(pub)))
+		}
+		if env.Timestamp != nil && tsaRoots == nil {
+			// The token is self-consistent, but anyone can make one.
+			unverified = true
+			proof = append(proof, fmt.Sprintf("timestamp %s by %s unverified", env.Timestamp.Time.Format(time.RFC3339), env.Timestamp.TSA))
+			fmt.Printf("⚠️  Result %d: %s\n", n, strings.Join(proof, ", "))
+			continue
+		}
+		if env.Timestamp != nil {
+			proof = append(proof, fmt.Sprintf("timestamped %s by %s", env.Timestamp.Time.Format(time.RFC3339), env.Timestamp.TSA))
+		}
+		fmt.Printf("✅ Result %d: valid, %s\n", n, strings.Join(proof, ", "))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d signed result(s) failed verification", failed)
+	}
+	if trusted == nil {
+		fmt.Println("Note: signer identity not checked; pass -pubkey to require a specific key")
+	}
+	if unverified {
+		fmt.Println("Note: timestamps are unverified; pass -tsa-ca with the TSA's CA certificate to check who issued them")
+	}
+	return nil
+}
+
+func runVersion(args []string) error {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	short := fs.Bool("short", false, "Print only the version, commit and build date on one line")
+	fs.Parse(args)
+
+	info := version.Get()
+	if *short {
+		fmt.Println(info.Short())
+		return nil
+	}
+	info.Features = append(info.Features, version.Feature{Name: "WHOIS TLDs", Items: whois.TLDs()})
+	blockchainTLDs := make([]string, len(analyzer.BlockchainTLDs))
+	for i, tld := range analyzer.BlockchainTLDs {
+		blockchainTLDs[i] = strings.TrimPrefix(tld, ".")
+	}
+	info.Features = append(info.Features, version.Feature{Name: "Blockchain TLDs", Items: blockchainTLDs})
+	for _, ns := range altroot.Namespaces() {
+		tlds := make([]string, len(ns.TLDs))
+		for i, tld := range ns.TLDs {
+			tlds[i] = strings.TrimPrefix(tld, ".")
+		}
+		info.Features = append(info.Features, version.Feature{Name: ns.Name + " TLDs", Items: tlds})
+	}
+	info.Features = append(info.Features, version.Feature{Name: "Modules", Items: analyzer.Modules})
+	var profiles []string
+	for _, p := range analyzer.Profiles {
+		profiles = append(profiles, p.Name)
+	}
+	info.Features = append(info.Features, version.Feature{Name: "Profiles", Items: profiles})
+	return output.NewFormatter(*format).DisplayVersion(info)
+}
+
+func runAnalyze(args []string) error {
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json, csv")
+	fields := fs.String("fields", "", "Only output these comma-separated fields")
+	queryStr := fs.String("query", "", "JMESPath expression evaluated against each result")
+	fromEvidence := fs.String("from-evidence", "", "Re-analyze the raw artifacts in an evidence bundle (zip) without network access")
+	verbose := fs.Bool("verbose-dns", false, "Show the captured DNS answers with TTLs")
+	ttls := fs.Bool("ttl-report", false, "Report record TTLs and DNS change readiness")
+	fs.Parse(args)
+
+	if *fromEvidence == "" {
+		return fmt.Errorf("analyze: -from-evidence is required; use -domain for a live analysis")
+	}
+
+	archive, err := evidence.Open(*fromEvidence)
+	if err != nil {
+		return err
+	}
+	domains := archive.Domains()
+	if len(domains) == 0 {
+		return fmt.Errorf("no reports found in %s", *fromEvidence)
+	}
+
+	a := analyzer.NewWithOptions(analyzer.Options{VerboseDNS: *verbose, TTLReport: *ttls})
+	formatter := output.NewFormatter(*format)
+	formatter.SetFields(output.ParseFields(*fields))
+	if *queryStr != "" {
+		expr, err := query.Compile(*queryStr)
+		if err != nil {
+			return err
+		}
+		formatter.SetQuery(expr)
+	}
+
+	var results []*analyzer.Result
+	for _, d := range domains {
+		data, _ := archive.File(d + "/report.json")
+		var captured analyzer.Result
+		if err := json.Unmarshal(data, &captured); err != nil {
+			return fmt.Errorf("failed to parse %s/report.json: %v", d, err)
+		}
+
+		var whoisRaw string
+		if raw, ok := archive.File(d + "/whois.txt"); ok {
+			whoisRaw = string(raw)
+		}
+		var answers []checker.Record
+		if raw, ok := archive.File(d + "/dns-answers.json"); ok {
+			if err := json.Unmarshal(raw, &answers); err != nil {
+				return fmt.Errorf("failed to parse %s/dns-answers.json: %v", d, err)
+			}
+		}
+
+		result := a.Replay(&captured, whoisRaw, answers)
+		if err := formatter.Display(result); err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+
+	if len(results) > 1 && *queryStr == "" {
+		return formatter.DisplayPortfolio(portfolio.Summarize(results))
+	}
+	return nil
+}
+
+func runCompare(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	rpcURL := fs.String("eth-rpc", "", "Ethereum JSON-RPC URL for .eth availability")
+	udKey := fs.String("ud-api-key", "", "Unstoppable Domains Resolution API key")
+	domaKey := fs.String("doma-api-key", os.Getenv("DOMA_API_KEY"), "DOMA API key for tokenization status")
+	domaAPI := fs.String("doma-api", "", "DOMA API base URL (default: https://api.doma.xyz)")
+	auditPath := fs.String("audit-log", "", "Append every WHOIS query and HTTP request to this log")
+	fs.Parse(args)
+
+	var names []string
+	seen := map[string]bool{}
+	for _, arg := range fs.Args() {
+		for _, name := range splitList(arg) {
+			if name = analyzer.Canonicalize(name); name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) < 2 {
+		return fmt.Errorf("compare: expected at least two domains, e.g. compare a.com b.io c.eth")
+	}
+
+	var auditor *audit.Log
+	if *auditPath != "" {
+		var err error
+		if auditor, err = openAuditLog(*auditPath); err != nil {
+			return err
+		}
+	}
+	a := analyzer.NewWithOptions(analyzer.Options{
+		EthRPC:     *rpcURL,
+		UDAPIKey:   *udKey,
+		DomaAPIKey: *domaKey,
+		DomaAPI:    *domaAPI,
+		AuditLog:   auditor,
+	})
+	results := make([]*analyzer.Result, 0, len(names))
+	for _, name := range names {
+		result, err := a.AnalyzeDomain(name)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+	return output.NewFormatter(*format).DisplayComparison(compare.Rank(results))
+}
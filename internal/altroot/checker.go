@@ -0,0 +1,177 @@
+package altroot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/checker"
+)
+
+// Namespace is a naming system outside the ICANN root whose names only
+// resolve through resolvers that carry it.
+type Namespace struct {
+	Name      string
+	TLDs      []string
+	Resolvers []string
+	Caveat    string
+	// Flag is the command-line flag setting resolvers for this namespace
+	// alone, if there is one; -altroot-resolvers serves every namespace.
+	Flag string
+}
+
+var namespaces = []Namespace{
+	{
+		Name: "Namecoin",
+		TLDs: []string{".bit"},
+		// No public resolver carries .bit any more; users point this at
+		// their own ncdns or Namecoin-aware resolver.
+		Caveat: "Namecoin .bit names do not resolve in standard browsers or public DNS; visitors need ncdns, a Namecoin-aware resolver or a browser extension",
+	},
+	{
+		Name: "OpenNIC",
+		// OpenNIC-operated TLDs plus the New Nations TLDs it peers with.
+		TLDs: []string{
+			".bbs", ".chan", ".cyb", ".dyn", ".epic", ".fur", ".geek", ".gopher",
+			".indy", ".libre", ".neo", ".null", ".o", ".oss", ".oz", ".parody", ".pirate",
+			".ku", ".te", ".ti", ".uu",
+		},
+		// Tier-2 servers come and go; see https://servers.opennic.org for a
+		// current list.
+		Caveat: "OpenNIC TLDs only resolve for users whose DNS points at an OpenNIC resolver; public DNS returns NXDOMAIN for them",
+		Flag:   "opennic-resolvers",
+	},
+}
+
+// Checker looks up alt-root names through resolvers that serve the
+// namespace.
+type Checker struct {
+	resolvers   []string
+	byNamespace map[string][]string
+}
+
+type Result struct {
+	Namespace  string           `json:"namespace"`
+	AltRoot    bool             `json:"alt_root"`
+	Available  bool             `json:"available"`
+	Registered bool             `json:"registered"`
+	Resolver   string           `json:"resolver,omitempty"`
+	Records    []checker.Record `json:"records,omitempty"`
+	Caveat     string           `json:"caveat"`
+	CheckedAt  time.Time        `json:"checked_at"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// NewChecker returns a checker that uses each namespace's default
+// resolvers.
+func NewChecker() *Checker {
+	return &Checker{
+		byNamespace: make(map[string][]string),
+	}
+}
+
+// SetResolvers overrides the resolvers ("host" or "host:port") used for
+// every alt-root namespace without a namespace-specific setting.
+func (c *Checker) SetResolvers(resolvers []string) {
+	c.resolvers = resolvers
+}
+
+// SetNamespaceResolvers sets the resolvers for one namespace (e.g.
+// "OpenNIC"), taking precedence over SetResolvers.
+func (c *Checker) SetNamespaceResolvers(namespace string, resolvers []string) {
+	c.byNamespace[strings.ToLower(namespace)] = resolvers
+}
+
+// Namespaces returns the alternative roots the checker knows.
+func Namespaces() []Namespace {
+	return append([]Namespace(nil), namespaces...)
+}
+
+// NamespaceFor returns the alt-root namespace serving domain, if any.
+func NamespaceFor(domain string) *Namespace {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	for i := range namespaces {
+		for _, tld := range namespaces[i].TLDs {
+			if strings.HasSuffix(domain, tld) {
+				return &namespaces[i]
+			}
+		}
+	}
+	return nil
+}
+
+// IsAltRoot reports whether domain belongs to an alternative root.
+func IsAltRoot(domain string) bool {
+	return NamespaceFor(domain) != nil
+}
+
+// Resolvers returns the resolvers that would be queried for domain.
+func (c *Checker) Resolvers(domain string) []string {
+	ns := NamespaceFor(domain)
+	if ns == nil {
+		return nil
+	}
+	if r := c.byNamespace[strings.ToLower(ns.Name)]; len(r) > 0 {
+		return r
+	}
+	if len(c.resolvers) > 0 {
+		return c.resolvers
+	}
+	return ns.Resolvers
+}
+
+func (c *Checker) Check(domain string) (*Result, error) {
+	ns := NamespaceFor(domain)
+	if ns == nil {
+		return nil, fmt.Errorf("%s is not in a known alternative root", domain)
+	}
+
+	result := &Result{
+		Namespace: ns.Name,
+		AltRoot:   true,
+		Caveat:    ns.Caveat,
+		CheckedAt: time.Now(),
+	}
+
+	resolvers := c.Resolvers(domain)
+	if len(resolvers) == 0 {
+		flags := "-altroot-resolvers"
+		if ns.Flag != "" {
+			flags = "-" + ns.Flag + " or " + flags
+		}
+		result.Error = fmt.Sprintf("no %s resolver configured; availability cannot be determined from public DNS (set %s)", ns.Name, flags)
+		return result, nil
+	}
+
+	// Try each resolver until one gives an authoritative-looking answer;
+	// SERVFAIL or REFUSED usually means it does not carry the namespace.
+	var failures []string
+	for _, server := range resolvers {
+		r := checker.NewResolverFor(server)
+		resp, err := r.Query(domain, "NS")
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", server, err))
+			continue
+		}
+
+		switch resp.Rcode {
+		case "NXDOMAIN":
+			result.Resolver = r.Server()
+			result.Available = true
+			return result, nil
+		case "NOERROR":
+			result.Resolver = r.Server()
+			result.Registered = true
+			result.Records = append(result.Records, resp.Answers...)
+			if a, err := r.Query(domain, "A"); err == nil {
+				result.Records = append(result.Records, a.Answers...)
+			}
+			return result, nil
+		default:
+			failures = append(failures, fmt.Sprintf("%s: %s", server, resp.Rcode))
+		}
+	}
+
+	result.Error = "no resolver could answer for " + ns.Name + " (" + strings.Join(failures, "; ") + ")"
+	return result, nil
+}
@@ -0,0 +1,112 @@
+package altroot
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// serveDNS answers NS queries for registered.bit and NXDOMAIN otherwise.
+func serveDNS(t *testing.T, rcode dnsmessage.RCode) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) == 0 {
+				continue
+			}
+			q := query.Questions[0]
+			reply := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.Header.ID, Response: true, RCode: rcode},
+				Questions: query.Questions,
+			}
+			if rcode == dnsmessage.RCodeSuccess && q.Name.String() == "registered.bit." {
+				if q.Type == dnsmessage.TypeNS {
+					reply.Answers = []dnsmessage.Resource{{
+						Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeNS, Class: dnsmessage.ClassINET, TTL: 3600},
+						Body:   &dnsmessage.NSResource{NS: dnsmessage.MustNewName("ns1.registered.bit.")},
+					}}
+				}
+			} else if rcode == dnsmessage.RCodeSuccess {
+				reply.Header.RCode = dnsmessage.RCodeNameError
+			}
+			packed, _ := reply.Pack()
+			conn.WriteTo(packed, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestChecker_Check(t *testing.T) {
+	c := NewChecker()
+	c.SetResolvers([]string{serveDNS(t, dnsmessage.RCodeRefused), serveDNS(t, dnsmessage.RCodeSuccess)})
+
+	result, err := c.Check("registered.bit")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.AltRoot || result.Namespace != "Namecoin" || result.Caveat == "" {
+		t.Errorf("expected labeled Namecoin result, got %+v", result)
+	}
+	if !result.Registered || result.Available || len(result.Records) != 1 {
+		t.Errorf("expected registered name via the second resolver, got %+v", result)
+	}
+
+	result, _ = c.Check("free.bit")
+	if !result.Available || result.Registered {
+		t.Errorf("expected NXDOMAIN to mean available, got %+v", result)
+	}
+}
+
+func TestChecker_NoResolver(t *testing.T) {
+	result, err := NewChecker().Check("example.bit")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(result.Error, "(set -altroot-resolvers)") || result.Available {
+		t.Errorf("expected an unconfigured resolver error, got %+v", result)
+	}
+	result, _ = NewChecker().Check("wiki.libre")
+	if !strings.HasSuffix(result.Error, "(set -opennic-resolvers or -altroot-resolvers)") {
+		t.Errorf("expected the OpenNIC flag to be named, got %q", result.Error)
+	}
+
+	if IsAltRoot("example.com") {
+		t.Error("example.com is not alt-root")
+	}
+}
+
+func TestChecker_OpenNIC(t *testing.T) {
+	if ns := NamespaceFor("wiki.libre"); ns == nil || ns.Name != "OpenNIC" {
+		t.Fatalf("expected .libre to be OpenNIC, got %+v", ns)
+	}
+	// Single-letter TLDs must match on a label boundary.
+	if IsAltRoot("example.io") {
+		t.Error(".io is an ICANN TLD")
+	}
+
+	c := NewChecker()
+	c.SetResolvers([]string{"192.0.2.1"})
+	c.SetNamespaceResolvers("OpenNIC", []string{"192.0.2.53"})
+	if r := c.Resolvers("wiki.libre"); len(r) != 1 || r[0] != "192.0.2.53" {
+		t.Errorf("expected namespace resolver, got %v", r)
+	}
+	if r := c.Resolvers("example.bit"); len(r) != 1 || r[0] != "192.0.2.1" {
+		t.Errorf("expected global resolver for .bit, got %v", r)
+	}
+}
@@ -1,94 +1,1010 @@
 package analyzer
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
+	"d3-domain-tool/internal/altroot"
+	"d3-domain-tool/internal/audit"
 	"d3-domain-tool/internal/blockchain"
 	"d3-domain-tool/internal/checker"
+	"d3-domain-tool/internal/dangling"
 	"d3-domain-tool/internal/doma"
+	"d3-domain-tool/internal/email"
+	"d3-domain-tool/internal/evidence"
+	"d3-domain-tool/internal/findings"
+	"d3-domain-tool/internal/geodns"
+	"d3-domain-tool/internal/ipv6"
+	"d3-domain-tool/internal/linkage"
+	"d3-domain-tool/internal/portscan"
+	"d3-domain-tool/internal/provider"
+	"d3-domain-tool/internal/rawarchive"
+	"d3-domain-tool/internal/seizure"
+	"d3-domain-tool/internal/threatintel"
+	"d3-domain-tool/internal/udrp"
 	"d3-domain-tool/internal/valuation"
+	"d3-domain-tool/internal/vcr"
+	"d3-domain-tool/internal/webaudit"
 	"d3-domain-tool/internal/whois"
 )
 
+// ErrorPolicy controls what happens when a sub-check fails or returns no data.
+type ErrorPolicy string
+
+const (
+	// PolicyDegrade keeps going and marks the result as degraded (default).
+	PolicyDegrade ErrorPolicy = "degrade"
+	// PolicyWarn behaves like PolicyDegrade; callers are expected to surface
+	// the section errors as warnings.
+	PolicyWarn ErrorPolicy = "warn"
+	// PolicyFail aborts the analysis on the first failed sub-check.
+	PolicyFail ErrorPolicy = "fail"
+)
+
+// ParseErrorPolicy validates a policy name given on the command line.
+func ParseErrorPolicy(s string) (ErrorPolicy, error) {
+	switch p := ErrorPolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case PolicyDegrade, PolicyWarn, PolicyFail:
+		return p, nil
+	case "", "best-effort":
+		return PolicyDegrade, nil
+	default:
+		return "", fmt.Errorf("unknown error policy %q (want degrade, warn or fail)", s)
+	}
+}
+
+// Options configures an Analyzer.
+type Options struct {
+	ErrorPolicy ErrorPolicy
+	// VerboseDNS captures raw DNS answers (values, TTLs, priorities).
+	VerboseDNS bool
+	// TTLReport adds the TTL / change-readiness report to the DNS section.
+	TTLReport bool
+	// SMTPProbe connects to MX hosts to verify mail servers are alive.
+	SMTPProbe bool
+	// ScanPorts checks a small curated port set on the domain's A records.
+	ScanPorts bool
+	// WebAudit follows the HTTP redirect chain and grades HSTS and
+	// security headers for domains with an A record.
+	WebAudit bool
+	// HSTSPreload checks HSTS preload list membership and eligibility.
+	HSTSPreload bool
+	// Dangling checks CNAME, MX, NS and SPF include targets for references
+	// to hosts that no longer exist.
+	Dangling bool
+	// WhoisHistoryKey enables historical WHOIS snapshots from WhoisXML API.
+	WhoisHistoryKey string
+	// UDRP searches public UDRP decision databases for disputes over the
+	// domain's name.
+	UDRP bool
+	// UDRPSources replaces the default dispute databases.
+	UDRPSources []udrp.Source
+	// ThreatIntel looks the domain up in threat-intel feeds.
+	ThreatIntel bool
+	// ThreatFeeds are the feeds ThreatIntel searches: abuse.ch, MISP or
+	// TAXII.
+	ThreatFeeds []threatintel.Feed
+	// IPv6 grades AAAA coverage and IPv6 reachability of the website,
+	// nameservers and mail exchangers.
+	IPv6 bool
+	// EmailSecurity audits SPF and related email authentication records.
+	EmailSecurity bool
+	// Seizure looks for law-enforcement seizure nameservers and banners.
+	Seizure bool
+	// GeoDNS compares the domain's answers across vantage points to detect
+	// location-dependent routing.
+	GeoDNS bool
+	// GeoVantages replaces the default GeoDNS vantage points.
+	GeoVantages []geodns.Vantage
+	// AltRootResolvers overrides the resolvers used for alternative-root
+	// namespaces such as Namecoin .bit ("host" or "host:port").
+	AltRootResolvers []string
+	// OpenNICResolvers are used for OpenNIC TLDs (.geek, .libre, ...) in
+	// preference to AltRootResolvers.
+	OpenNICResolvers []string
+	// EthRPC is an Ethereum JSON-RPC endpoint used for ENS availability and
+	// to detect whether token owners are Safe multi-sigs, registrar or
+	// marketplace contracts, other contracts or plain accounts. Without it
+	// ENS availability is unknown.
+	EthRPC string
+	// ChainCache answers repeated EthRPC lookups (contract code, ENS
+	// owners, resolvers and expiries) across runs.
+	ChainCache *blockchain.Cache
+	// ChainLinks detects DNS domains imported into ENS through DNSSEC or
+	// resolvable through Unstoppable Domains. Registry ownership is only
+	// checked when EthRPC is set, and UD records only with UDAPIKey.
+	ChainLinks bool
+	// UDAPIKey is an Unstoppable Domains Resolution API key, used for the
+	// availability of UD names and by ChainLinks.
+	UDAPIKey string
+	// DomaAPIKey is a DOMA API key, used for the tokenization status of
+	// every domain. Without it the status is unknown.
+	DomaAPIKey string
+	// DomaAPI overrides the DOMA API base URL.
+	DomaAPI string
+	// ENSSubgraph is an ENS subgraph GraphQL URL used for the registration,
+	// renewal and transfer history of .eth names. Empty disables it.
+	ENSSubgraph string
+	// Explorers overrides the block explorer used per chain for owner,
+	// contract and token links.
+	Explorers blockchain.Explorers
+	// RenewalPrices overrides the yearly renewal price per TLD used for the
+	// carrying cost of a name.
+	RenewalPrices map[string]float64
+	// Negotiation adds price anchors for valuation.Buyer or
+	// valuation.Seller to each valuation. Empty disables them.
+	Negotiation string
+	// LeaseCapRate, when positive, adds monthly lease and rent-to-own
+	// pricing at this yearly cap rate to each valuation.
+	LeaseCapRate float64
+	// Cassette, when set, records WHOIS queries into or replays them from a
+	// vcr fixture. HTTP traffic is recorded by wrapping the HTTP transport.
+	Cassette *vcr.Cassette
+	// AuditLog, when set, logs every WHOIS query sent to a server. HTTP
+	// requests are logged by wrapping the HTTP transport.
+	AuditLog *audit.Log
+	// RawArchive, when set, archives every WHOIS response a server sends.
+	// RDAP responses are archived by wrapping the HTTP transport.
+	RawArchive *rawarchive.Archive
+	// Evidence, when set, receives the raw WHOIS response and DNS answers of
+	// each analyzed domain.
+	Evidence *evidence.Bundle
+	// Only, when not empty, restricts the analysis to these modules (see
+	// Modules), turning on opt-in modules it names.
+	Only []string
+	// Skip leaves out these modules.
+	Skip []string
+	// Timeout bounds each DOMA, blockchain, WHOIS, RDAP and DNS lookup; 0
+	// keeps the client defaults.
+	Timeout time.Duration
+	// WHOISTimeout bounds each WHOIS query, overriding Timeout.
+	WHOISTimeout time.Duration
+	// DNSTimeout bounds each DNS lookup, overriding Timeout.
+	DNSTimeout time.Duration
+	// NoShortCircuit runs every selected check even when DNS and RDAP show
+	// the domain does not exist.
+	NoShortCircuit bool
+	// Cache, when set, returns the latest earlier result of a domain, or
+	// nil. Its sections checked within MaxAge are reused instead of being
+	// fetched again, and results list the freshness of every section.
+	Cache func(domain string) *Result
+	// MaxAge is how old a cached section may be; 0 fetches everything.
+	MaxAge time.Duration
+	// TLDs override options for the domains under a TLD, keyed by the TLD
+	// without its leading dot, e.g. "br" or "co.uk". The longest matching
+	// TLD applies.
+	TLDs map[string]TLDOptions
+}
+
+// DefaultOptions returns the options used by New.
+func DefaultOptions() Options {
+	return Options{
+		ErrorPolicy: PolicyDegrade,
+	}
+}
+
 type Analyzer struct {
 	dnsChecker        *checker.DNSChecker
 	blockchainChecker *blockchain.Checker
+	ownerDetector     *blockchain.OwnerDetector
+	ensSubgraph       *blockchain.SubgraphClient
+	linkChecker       *linkage.Checker
 	whoisClient       *whois.Client
+	whoisHistory      *whois.HistoryClient
 	domaClient        *doma.Client
 	valuator          *valuation.Engine
+	mailProber        *email.Prober
+	emailAuditor      *email.Auditor
+	seizureDetector   *seizure.Detector
+	portScanner       *portscan.Scanner
+	webAuditor        *webaudit.Auditor
+	danglingDetector  *dangling.Detector
+	dnsProvider       *provider.Classifier
+	altRootChecker    *altroot.Checker
+	geoDetector       *geodns.Detector
+	ipv6Checker       *ipv6.Checker
+	udrpChecker       *udrp.Checker
+	threatChecker     *threatintel.Checker
+	rdapClient        *whois.RDAPClient
+	only              map[string]bool
+	skip              map[string]bool
+	opts              Options
+	// rdapLookup is rdapClient.LookupContext, replaced in tests.
+	rdapLookup func(ctx context.Context, domain string) (*whois.RDAPResult, error)
+	// dnsAnswers returns the raw DNS answers of a domain, through the
+	// cassette when one is set.
+	dnsAnswers func(domain string) ([]checker.Record, error)
+	// tlds are the analyzers with the overrides of Options.TLDs, and
+	// rdapServer the RDAP server such an override sets.
+	tlds       map[string]*Analyzer
+	rdapServer string
+}
+
+// Result status values.
+const (
+	StatusComplete = "complete"
+	StatusDegraded = "degraded"
+)
+
+// SectionError records a sub-check that failed or returned no usable data.
+type SectionError struct {
+	Section string `json:"section"`
+	Error   string `json:"error"`
+}
+
+// SectionWarning is a data quality issue a section reported without
+// failing, e.g. a WHOIS date in an unknown format. Warnings do not make a
+// result degraded.
+type SectionWarning struct {
+	Section string `json:"section"`
+	Warning string `json:"warning"`
 }
 
 type Result struct {
-	Domain          string             `json:"domain"`
-	Timestamp       time.Time          `json:"timestamp"`
-	DNSAvailability *checker.DNSResult `json:"dns_availability"`
-	BlockchainData  *blockchain.Result `json:"blockchain_data"`
-	DomaData        *doma.Result       `json:"doma_data"`
-	WhoisData       *whois.Result      `json:"whois_data"`
-	ValuationData   *valuation.Result  `json:"valuation_data"`
+	// Domain is the canonical domain analyzed; Input is what was asked for
+	// when it differs, e.g. a pasted URL.
+	Domain          string                  `json:"domain"`
+	Input           string                  `json:"input,omitempty"`
+	Timestamp       time.Time               `json:"timestamp"`
+	DNSAvailability *checker.DNSResult      `json:"dns_availability"`
+	BlockchainData  *blockchain.Result      `json:"blockchain_data"`
+	ENSHistory      *blockchain.ENSHistory  `json:"ens_history,omitempty"`
+	ChainLinks      *linkage.Result         `json:"chain_links,omitempty"`
+	DomaData        *doma.Result            `json:"doma_data"`
+	WhoisData       *whois.Result           `json:"whois_data"`
+	RDAP            *whois.RDAPResult       `json:"rdap,omitempty"`
+	WhoisHistory    *whois.History          `json:"whois_history,omitempty"`
+	UDRP            *udrp.Result            `json:"udrp,omitempty"`
+	ThreatIntel     *threatintel.Result     `json:"threat_intel,omitempty"`
+	ValuationData   *valuation.Result       `json:"valuation_data"`
+	MailProbe       *email.ProbeResult      `json:"mail_probe,omitempty"`
+	PortScan        *portscan.Result        `json:"port_scan,omitempty"`
+	WebSecurity     *webaudit.Result        `json:"web_security,omitempty"`
+	HSTSPreload     *webaudit.PreloadResult `json:"hsts_preload,omitempty"`
+	DanglingRecords *dangling.Result        `json:"dangling_records,omitempty"`
+	EmailSecurity   *email.SecurityResult   `json:"email_security,omitempty"`
+	DNSProvider     *provider.Result        `json:"dns_provider,omitempty"`
+	Seizure         *seizure.Result         `json:"seizure,omitempty"`
+	AltRoot         *altroot.Result         `json:"alt_root,omitempty"`
+	GeoDNS          *geodns.Result          `json:"geo_dns,omitempty"`
+	IPv6            *ipv6.Result            `json:"ipv6,omitempty"`
+	Status          string                  `json:"status"`
+	SectionErrors   []SectionError          `json:"section_errors,omitempty"`
+	Warnings        []SectionWarning        `json:"warnings,omitempty"`
+	Findings        []findings.Finding      `json:"findings,omitempty"`
+	Suppressed      []findings.Finding      `json:"suppressed,omitempty"`
+	SkippedChecks   []SkippedCheck          `json:"skipped_checks,omitempty"`
+	Freshness       []Freshness             `json:"freshness,omitempty"`
+}
+
+// Degraded reports whether any sub-check failed.
+func (r *Result) Degraded() bool {
+	return len(r.SectionErrors) > 0
+}
+
+// Verdict values.
+const (
+	VerdictAvailable = "available"
+	VerdictTaken     = "taken"
+	VerdictUnknown   = "unknown"
+)
+
+// Verdict is the overall availability of the domain. For traditional
+// domains a WHOIS answer wins over DNS, since a registered domain may have
+// no DNS records.
+func (r *Result) Verdict() string {
+	switch {
+	case r.BlockchainData != nil:
+		switch available := r.BlockchainData.Available; {
+		case available == nil:
+			return VerdictUnknown
+		case *available:
+			return VerdictAvailable
+		}
+		return VerdictTaken
+	case r.AltRoot != nil:
+		if r.AltRoot.Registered {
+			return VerdictTaken
+		}
+		if r.AltRoot.Available {
+			return VerdictAvailable
+		}
+		return VerdictUnknown
+	case r.WhoisData != nil && r.WhoisData.Error == "":
+		if r.WhoisData.Available {
+			return VerdictAvailable
+		}
+		return VerdictTaken
+	case r.RDAP != nil && r.RDAP.Error == "":
+		if r.RDAP.Registered {
+			return VerdictTaken
+		}
+		return VerdictAvailable
+	case r.DNSAvailability != nil && r.DNSAvailability.Error == "":
+		if r.DNSAvailability.Available {
+			return VerdictAvailable
+		}
+		return VerdictTaken
+	}
+	return VerdictUnknown
+}
+
+// Usage refines a taken verdict with how the domain is used: seized,
+// according to the seizure check or the nameservers, or else what the
+// nameservers say: parked, sinkholed, registrar default or active. It is "" for
+// other verdicts or when the nameservers were not classified.
+func (r *Result) Usage() string {
+	if r.Verdict() != VerdictTaken {
+		return ""
+	}
+	if r.Seizure != nil && r.Seizure.Seized {
+		return provider.UsageSeized
+	}
+	if r.DNSProvider == nil {
+		return ""
+	}
+	return r.DNSProvider.Usage
 }
 
 func New() *Analyzer {
-	return &Analyzer{
-		dnsChecker:        checker.NewDNSChecker(),
-		blockchainChecker: blockchain.NewChecker(),
-		whoisClient:       whois.NewClient(),
-		domaClient:        doma.NewClient(),
-		valuator:          valuation.NewEngine(),
+	return NewWithOptions(DefaultOptions())
+}
+
+func NewWithOptions(opts Options) *Analyzer {
+	if opts.ErrorPolicy == "" {
+		opts.ErrorPolicy = PolicyDegrade
+	}
+	dnsChecker := checker.NewDNSChecker()
+	dnsChecker.SetVerbose(opts.VerboseDNS)
+	dnsChecker.SetTTLReport(opts.TTLReport)
+	altRootChecker := altroot.NewChecker()
+	altRootChecker.SetResolvers(opts.AltRootResolvers)
+	altRootChecker.SetNamespaceResolvers("OpenNIC", opts.OpenNICResolvers)
+	geoDetector := geodns.NewDetector()
+	geoDetector.SetVantages(opts.GeoVantages)
+	udrpChecker := udrp.NewChecker()
+	udrpChecker.SetSources(opts.UDRPSources)
+	blockchainChecker := blockchain.NewChecker()
+	blockchainChecker.SetUDKey(opts.UDAPIKey)
+	domaClient := doma.NewClient()
+	whoisClient := whois.NewClient()
+	rdapClient := whois.NewRDAPClient()
+	if opts.Timeout > 0 {
+		domaClient.SetTimeout(opts.Timeout)
+		blockchainChecker.SetTimeout(opts.Timeout)
+		whoisClient.SetTimeout(opts.Timeout)
+		rdapClient.SetTimeout(opts.Timeout)
+		dnsChecker.SetTimeout(opts.Timeout)
+	}
+	if opts.WHOISTimeout > 0 {
+		whoisClient.SetTimeout(opts.WHOISTimeout)
+	}
+	if opts.DNSTimeout > 0 {
+		dnsChecker.SetTimeout(opts.DNSTimeout)
+	}
+	// Replayed queries reach no server, so the audit log and the raw
+	// archive wrap the real query beneath the cassette.
+	if opts.AuditLog != nil {
+		whoisClient.WrapQuery(func(next whois.QueryFunc) whois.QueryFunc {
+			return opts.AuditLog.WHOIS(next)
+		})
+	}
+	if opts.RawArchive != nil {
+		whoisClient.WrapQuery(opts.RawArchive.WHOIS)
 	}
+	if opts.Cassette != nil {
+		whoisClient.WrapQuery(func(next whois.QueryFunc) whois.QueryFunc {
+			return opts.Cassette.WHOIS(next)
+		})
+	}
+	if opts.DomaAPIKey != "" {
+		domaClient.SetAPIKey(opts.DomaAPIKey)
+		if opts.DomaAPI != "" {
+			domaClient.SetEndpoint(opts.DomaAPI)
+		}
+		domaClient.SetSource(domaClient.APISource())
+	}
+	if len(opts.Explorers) > 0 {
+		blockchainChecker.SetExplorers(opts.Explorers)
+		domaClient.SetExplorers(opts.Explorers)
+	}
+	var ownerDetector *blockchain.OwnerDetector
+	linkChecker := linkage.NewChecker()
+	if opts.EthRPC != "" {
+		ownerDetector = blockchain.NewOwnerDetector(opts.EthRPC)
+		rpc := blockchain.NewRPCClient(opts.EthRPC)
+		if opts.Timeout > 0 {
+			rpc.SetTimeout(opts.Timeout)
+		}
+		if opts.ChainCache != nil {
+			rpc.SetCache(opts.ChainCache)
+			ownerDetector.SetCache(opts.ChainCache)
+		}
+		blockchainChecker.SetRPC(rpc)
+		linkChecker.SetRPC(rpc)
+	}
+	linkChecker.SetUDKey(opts.UDAPIKey)
+	var ensSubgraph *blockchain.SubgraphClient
+	if opts.ENSSubgraph != "" {
+		ensSubgraph = blockchain.NewSubgraphClient(opts.ENSSubgraph)
+	}
+	dnsAnswers := func(domain string) ([]checker.Record, error) {
+		return dnsChecker.Answers(domain), nil
+	}
+	if opts.Cassette != nil {
+		dnsAnswers = opts.Cassette.DNS(dnsAnswers)
+	}
+	valuator := valuation.NewEngine()
+	valuator.SetRenewalPrices(opts.RenewalPrices)
+	var whoisHistory *whois.HistoryClient
+	if opts.WhoisHistoryKey != "" {
+		whoisHistory = whois.NewHistoryClient(opts.WhoisHistoryKey)
+	}
+
+	a := &Analyzer{
+		dnsChecker:        dnsChecker,
+		blockchainChecker: blockchainChecker,
+		ownerDetector:     ownerDetector,
+		ensSubgraph:       ensSubgraph,
+		linkChecker:       linkChecker,
+		whoisClient:       whoisClient,
+		whoisHistory:      whoisHistory,
+		domaClient:        domaClient,
+		valuator:          valuator,
+		mailProber:        email.NewProber(),
+		emailAuditor:      email.NewAuditor(),
+		seizureDetector:   seizure.NewDetector(),
+		portScanner:       portscan.NewScanner(),
+		webAuditor:        webaudit.NewAuditor(),
+		danglingDetector:  dangling.NewDetector(),
+		dnsProvider:       provider.NewClassifier(),
+		altRootChecker:    altRootChecker,
+		geoDetector:       geoDetector,
+		ipv6Checker:       ipv6.NewChecker(),
+		udrpChecker:       udrpChecker,
+		threatChecker:     threatintel.NewChecker(opts.ThreatFeeds),
+		rdapClient:        rdapClient,
+		rdapLookup:        rdapClient.LookupContext,
+		dnsAnswers:        dnsAnswers,
+		only:              moduleSet(opts.Only),
+		skip:              moduleSet(opts.Skip),
+		opts:              opts,
+	}
+	return a.withTLDs()
 }
 
-func (a *Analyzer) AnalyzeDomain(domain string) (*Result, error) {
+func (a *Analyzer) AnalyzeDomain(input string) (*Result, error) {
+	return a.AnalyzeDomainContext(context.Background(), input)
+}
+
+// AnalyzeDomainContext is AnalyzeDomain with the DOMA, blockchain, DNS,
+// WHOIS, WHOIS history, RDAP, UDRP, threat-intel and web lookups bounded
+// by ctx. A lookup cut short by ctx fails like any other, following the
+// error policy.
+func (a *Analyzer) AnalyzeDomainContext(ctx context.Context, input string) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	domain := Canonicalize(input)
 	if domain == "" {
 		return nil, fmt.Errorf("domain cannot be empty")
 	}
+	a = a.forDomain(domain)
+	ctx = audit.WithDomain(ctx, domain)
 
 	result := &Result{
 		Domain:    domain,
 		Timestamp: time.Now(),
 	}
+	if input != domain {
+		result.Input = input
+	}
+
+	ctx = evidence.WithScope(ctx, domain)
+	prev := a.previous(domain)
 
-	// Always check DOMA Protocol integration first
-	domaData, err := a.domaClient.CheckDomain(domain)
-	if err == nil {
-		result.DomaData = domaData
+	// Check DOMA Protocol integration first
+	if a.runs("doma", true) && !a.reuse(result, prev, "doma") {
+		domaData, err := a.domaClient.CheckDomainContext(ctx, domain)
+		if err == nil {
+			result.DomaData = domaData
+		}
+		if err := a.record(result, "doma", err, errorOf(domaData)); err != nil {
+			return nil, err
+		}
+		if a.ownerDetector != nil && domaData != nil && domaData.DomaRecord != nil &&
+			domaData.DomaRecord.Owner != "" && domaData.TokenizationChain == "ethereum" {
+			info, err := a.ownerDetector.Detect(domaData.DomaRecord.Owner)
+			if err == nil {
+				domaData.DomaRecord.OwnerInfo = info
+			}
+			if err := a.record(result, "doma_owner", err, errorOf(info)); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Check if it's a blockchain domain
 	if isBlockchainDomain(domain) {
-		blockchainData, err := a.blockchainChecker.Check(domain)
-		if err == nil {
-			result.BlockchainData = blockchainData
+		if a.runs("blockchain", true) && !a.reuse(result, prev, "blockchain") {
+			blockchainData, err := a.blockchainChecker.CheckContext(ctx, domain)
+			if err == nil {
+				result.BlockchainData = blockchainData
+			}
+			if err := a.record(result, "blockchain", err, errorOf(blockchainData)); err != nil {
+				return nil, err
+			}
+			if a.ownerDetector != nil && blockchainData != nil && blockchainData.Owner != "" {
+				info, err := a.ownerDetector.Detect(blockchainData.Owner)
+				if err == nil {
+					blockchainData.OwnerInfo = info
+				}
+				if err := a.record(result, "blockchain_owner", err, errorOf(info)); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if a.ensSubgraph != nil && strings.HasSuffix(domain, ".eth") && a.runs("ens_history", true) && !a.reuse(result, prev, "ens_history") {
+			history, err := a.ensSubgraph.History(domain)
+			if err == nil {
+				result.ENSHistory = history
+			}
+			if err := a.record(result, "ens_history", err, errorOf(history)); err != nil {
+				return nil, err
+			}
+		}
+	} else if altroot.IsAltRoot(domain) {
+		// Alt-root names are invisible to the ICANN root, so public DNS
+		// and WHOIS would wrongly report them as available.
+		if a.runs("alt_root", true) && !a.reuse(result, prev, "alt_root") {
+			altData, err := a.altRootChecker.Check(domain)
+			if err == nil {
+				result.AltRoot = altData
+			}
+			if err := a.record(result, "alt_root", err, errorOf(altData)); err != nil {
+				return nil, err
+			}
 		}
 	} else {
-		// Traditional DNS domain
-		dnsData, err := a.dnsChecker.Check(domain)
-		if err == nil {
-			result.DNSAvailability = dnsData
+		// Traditional DNS domain. The answers also gate the provider,
+		// IPv6, web and GeoDNS checks, so the lookup is made for them
+		// when the dns module itself is not selected.
+		var dnsData *checker.DNSResult
+		if a.runs("dns", true) && a.reuse(result, prev, "dns") {
+			dnsData = result.DNSAvailability
+		} else if a.runs("dns", true) {
+			var err error
+			dnsData, err = a.checkDNS(ctx, domain)
+			if err == nil {
+				result.DNSAvailability = dnsData
+			}
+			if err := a.record(result, "dns", err, errorOf(dnsData)); err != nil {
+				return nil, err
+			}
+			if a.opts.Evidence != nil {
+				var answers []checker.Record
+				if dnsData != nil && dnsData.Answers != nil {
+					answers = dnsData.Answers
+				} else {
+					answers = a.dnsChecker.Answers(domain)
+				}
+				if answers == nil {
+					answers = []checker.Record{}
+				}
+				if err := a.record(result, "evidence", a.opts.Evidence.AddJSON(ctx, "dns-answers.json", answers), ""); err != nil {
+					return nil, err
+				}
+			}
+		} else if a.needsDNS() {
+			dnsData, _ = a.checkDNS(ctx, domain)
 		}
+		skip := a.shortCircuit(ctx, result, domain, dnsData)
 
-		whoisData, err := a.whoisClient.Lookup(domain)
-		if err == nil {
-			result.WhoisData = whoisData
+		if a.runs("whois", true) && !pointless(result, skip, "whois") && !a.reuse(result, prev, "whois") {
+			whoisData, err := a.whoisClient.LookupContext(ctx, domain)
+			if err == nil {
+				result.WhoisData = whoisData
+			}
+			if err := a.record(result, "whois", err, errorOf(whoisData)); err != nil {
+				return nil, err
+			}
+			if a.opts.Evidence != nil && whoisData != nil && whoisData.RawData != "" {
+				a.opts.Evidence.Add(ctx, "whois.txt", []byte(whoisData.RawData))
+			}
+		}
+
+		if a.whoisHistory != nil && a.runs("whois_history", true) && !a.reuse(result, prev, "whois_history") {
+			history, err := a.whoisHistory.LookupContext(ctx, domain)
+			if err == nil {
+				result.WhoisHistory = history
+			}
+			if err := a.record(result, "whois_history", err, errorOf(history)); err != nil {
+				return nil, err
+			}
+		}
+
+		if a.runs("udrp", a.opts.UDRP) && !a.reuse(result, prev, "udrp") {
+			disputes, err := a.udrpChecker.CheckContext(ctx, domain)
+			if err == nil {
+				result.UDRP = disputes
+			}
+			if err := a.record(result, "udrp", err, errorOf(disputes)); err != nil {
+				return nil, err
+			}
+		}
+
+		// Feeds keep malicious names after they lapse, so the lookup runs
+		// for names DNS has forgotten too.
+		if a.runs("threat_intel", a.opts.ThreatIntel) && !a.reuse(result, prev, "threat_intel") {
+			intel, err := a.threatChecker.CheckContext(ctx, domain)
+			if err == nil {
+				result.ThreatIntel = intel
+			}
+			if err := a.record(result, "threat_intel", err, errorOf(intel)); err != nil {
+				return nil, err
+			}
+		}
+
+		if a.runs("chain_links", a.opts.ChainLinks) && !pointless(result, skip, "chain_links") && !a.reuse(result, prev, "chain_links") {
+			links, err := a.linkChecker.Check(domain)
+			if err == nil {
+				result.ChainLinks = links
+			}
+			if err := a.record(result, "chain_links", err, errorOf(links)); err != nil {
+				return nil, err
+			}
+		}
+
+		// Identify the DNS provider whenever the domain is delegated
+		if a.runs("dns_provider", true) && hasRecordType(dnsData, "NS") && !a.reuse(result, prev, "dns_provider") {
+			providerData, err := a.dnsProvider.Classify(domain)
+			if err == nil {
+				result.DNSProvider = providerData
+			}
+			if err := a.record(result, "dns_provider", err, errorOf(providerData)); err != nil {
+				return nil, err
+			}
+		}
+
+		if a.runs("ipv6", a.opts.IPv6) && hasRecordType(dnsData, "NS") && !a.reuse(result, prev, "ipv6") {
+			ipv6Data, err := a.ipv6Checker.Check(domain)
+			if err == nil {
+				result.IPv6 = ipv6Data
+			}
+			if err := a.record(result, "ipv6", err, errorOf(ipv6Data)); err != nil {
+				return nil, err
+			}
+		}
+
+		if a.runs("mail_probe", a.opts.SMTPProbe) && !pointless(result, skip, "mail_probe") && !a.reuse(result, prev, "mail_probe") {
+			probe, err := a.mailProber.Probe(domain)
+			if err == nil {
+				result.MailProbe = probe
+			}
+			if err := a.record(result, "mail_probe", err, errorOf(probe)); err != nil {
+				return nil, err
+			}
 		}
+
+		if a.runs("port_scan", a.opts.ScanPorts) && !pointless(result, skip, "port_scan") && !a.reuse(result, prev, "port_scan") {
+			scan, err := a.portScanner.Scan(domain)
+			if err == nil {
+				result.PortScan = scan
+			}
+			if err := a.record(result, "port_scan", err, errorOf(scan)); err != nil {
+				return nil, err
+			}
+		}
+
+		if a.runs("web_security", a.opts.WebAudit) && hasRecordType(dnsData, "A") && !a.reuse(result, prev, "web_security") {
+			audit, err := a.webAuditor.AuditContext(ctx, domain)
+			if err == nil {
+				result.WebSecurity = audit
+			}
+			if err := a.record(result, "web_security", err, errorOf(audit)); err != nil {
+				return nil, err
+			}
+		}
+
+		if a.runs("geo_dns", a.opts.GeoDNS) && hasRecordType(dnsData, "A") && !a.reuse(result, prev, "geo_dns") {
+			geo, err := a.geoDetector.Detect(domain)
+			if err == nil {
+				result.GeoDNS = geo
+			}
+			if err := a.record(result, "geo_dns", err, errorOf(geo)); err != nil {
+				return nil, err
+			}
+		}
+
+		if a.runs("hsts_preload", a.opts.HSTSPreload) && !pointless(result, skip, "hsts_preload") && !a.reuse(result, prev, "hsts_preload") {
+			preload, err := a.webAuditor.CheckPreloadContext(ctx, domain, result.WebSecurity)
+			if err == nil {
+				result.HSTSPreload = preload
+			}
+			if err := a.record(result, "hsts_preload", err, errorOf(preload)); err != nil {
+				return nil, err
+			}
+		}
+
+		if a.runs("dangling_records", a.opts.Dangling) && !pointless(result, skip, "dangling_records") && !a.reuse(result, prev, "dangling_records") {
+			danglingData, err := a.danglingDetector.Detect(domain)
+			if err == nil {
+				result.DanglingRecords = danglingData
+			}
+			if err := a.record(result, "dangling_records", err, errorOf(danglingData)); err != nil {
+				return nil, err
+			}
+		}
+
+		if a.runs("email_security", a.opts.EmailSecurity) && !pointless(result, skip, "email_security") && !a.reuse(result, prev, "email_security") {
+			emailData, err := a.emailAuditor.Audit(domain)
+			if err == nil {
+				result.EmailSecurity = emailData
+			}
+			if err := a.record(result, "email_security", err, errorOf(emailData)); err != nil {
+				return nil, err
+			}
+		}
+
+		if a.runs("seizure", a.opts.Seizure) && !pointless(result, skip, "seizure") && !a.reuse(result, prev, "seizure") {
+			seized, err := a.seizureDetector.Check(domain)
+			if err == nil {
+				result.Seizure = seized
+			}
+			if err := a.record(result, "seizure", err, errorOf(seized)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Run valuation (now enhanced with DOMA data)
+	if a.runs("valuation", true) {
+		valuationData := a.valuator.Evaluate(domain)
+		if h := result.ENSHistory; h != nil && h.FirstRegistered != nil {
+			a.valuator.ApplyAge(valuationData, *h.FirstRegistered)
+		}
+		a.valuator.ApplyCarryingCost(valuationData, domain)
+		a.valuator.ApplyNegotiation(valuationData, a.opts.Negotiation)
+		a.valuator.ApplyLease(valuationData, a.opts.LeaseCapRate)
+		result.ValuationData = valuationData
+		applySeizure(result)
 	}
 
-	// Always run valuation (now enhanced with DOMA data)
-	valuationData := a.valuator.Evaluate(domain)
-	result.ValuationData = valuationData
+	collectWarnings(result)
+	a.stampFreshness(result)
+	result.Status = StatusComplete
+	if result.Degraded() {
+		result.Status = StatusDegraded
+	}
+	collectFindings(result, time.Now())
 
 	return result, nil
 }
 
-func isBlockchainDomain(domain string) bool {
-	blockchainTLDs := []string{".eth", ".crypto", ".nft", ".x", ".wallet", ".bitcoin", ".dao", ".888", ".zil", ".blockchain"}
+// applySeizure drops the valuation's confidence for a seized domain, which
+// cannot be bought, transferred or renewed whatever the name is worth.
+func applySeizure(result *Result) {
+	if result.ValuationData == nil || result.Usage() != provider.UsageSeized {
+		return
+	}
+	result.ValuationData.Confidence = "low"
+	result.ValuationData.Reasoning = "Seized by law enforcement: not for sale or transfer; " + result.ValuationData.Reasoning
+}
+
+// checkDNS runs the DNS availability check. With a cassette the domain's
+// raw answers are recorded alongside, or replayed and checked instead of
+// querying the resolver.
+func (a *Analyzer) checkDNS(ctx context.Context, domain string) (*checker.DNSResult, error) {
+	cassette := a.opts.Cassette
+	if cassette != nil && cassette.Mode() == vcr.ModeReplay {
+		answers, err := a.dnsAnswers(domain)
+		if err != nil {
+			return nil, err
+		}
+		dnsData := checker.ResultFromAnswers(domain, answers, a.opts.TTLReport)
+		dnsData.CheckedAt = time.Now()
+		if !a.opts.VerboseDNS {
+			dnsData.Answers = nil
+		}
+		return dnsData, nil
+	}
+	dnsData, err := a.dnsChecker.CheckContext(ctx, domain)
+	if cassette != nil {
+		a.dnsAnswers(domain)
+	}
+	return dnsData, err
+}
+
+// record notes a failed sub-check on the result. Under PolicyFail it returns
+// an error so the caller can abort the run.
+func (a *Analyzer) record(result *Result, section string, err error, sectionErr string) error {
+	msg := sectionErr
+	if err != nil {
+		msg = err.Error()
+	}
+	if msg == "" {
+		return nil
+	}
+
+	result.SectionErrors = append(result.SectionErrors, SectionError{
+		Section: section,
+		Error:   msg,
+	})
+
+	if a.opts.ErrorPolicy == PolicyFail {
+		return fmt.Errorf("%s check failed: %s", section, msg)
+	}
+	return nil
+}
+
+// collectWarnings gathers the warnings of each section into result.
+func collectWarnings(result *Result) {
+	add := func(section string, warnings []string) {
+		for _, w := range warnings {
+			result.Warnings = append(result.Warnings, SectionWarning{Section: section, Warning: w})
+		}
+	}
+	if result.DNSAvailability != nil {
+		add("dns", result.DNSAvailability.Warnings)
+	}
+	if result.WhoisData != nil {
+		add("whois", result.WhoisData.Warnings)
+	}
+}
+
+// errorOf extracts the Error field that section results use to report
+// soft failures.
+func errorOf(v interface{}) string {
+	switch r := v.(type) {
+	case *doma.Result:
+		if r != nil {
+			return r.Error
+		}
+	case *blockchain.Result:
+		if r != nil {
+			return r.Error
+		}
+	case *blockchain.OwnerInfo:
+		if r != nil {
+			return r.Error
+		}
+	case *blockchain.ENSHistory:
+		if r != nil {
+			return r.Error
+		}
+	case *linkage.Result:
+		if r != nil {
+			return r.Error
+		}
+	case *checker.DNSResult:
+		if r != nil {
+			return r.Error
+		}
+	case *altroot.Result:
+		if r != nil {
+			return r.Error
+		}
+	case *geodns.Result:
+		if r != nil {
+			return r.Error
+		}
+	case *ipv6.Result:
+		if r != nil {
+			return r.Error
+		}
+	case *udrp.Result:
+		if r != nil {
+			return r.Error
+		}
+	case *threatintel.Result:
+		if r != nil {
+			return r.Error
+		}
+	case *whois.Result:
+		if r != nil {
+			return r.Error
+		}
+	case *whois.History:
+		if r != nil {
+			return r.Error
+		}
+	case *email.ProbeResult:
+		if r != nil {
+			return r.Error
+		}
+	case *email.SecurityResult:
+		if r != nil {
+			return r.Error
+		}
+	case *portscan.Result:
+		if r != nil {
+			return r.Error
+		}
+	case *webaudit.Result:
+		if r != nil {
+			return r.Error
+		}
+	case *webaudit.PreloadResult:
+		if r != nil {
+			return r.Error
+		}
+	case *dangling.Result:
+		if r != nil {
+			return r.Error
+		}
+	case *provider.Result:
+		if r != nil {
+			return r.Error
+		}
+	case *seizure.Result:
+		if r != nil {
+			return r.Error
+		}
+	}
+	return ""
+}
+
+func hasRecordType(dns *checker.DNSResult, rrType string) bool {
+	if dns == nil {
+		return false
+	}
+	for _, t := range dns.RecordTypes {
+		if t == rrType {
+			return true
+		}
+	}
+	return false
+}
+
+// BlockchainTLDs are the TLDs analyzed as blockchain names (ENS and
+// Unstoppable Domains) rather than through DNS and WHOIS.
+var BlockchainTLDs = []string{".eth", ".crypto", ".nft", ".x", ".wallet", ".bitcoin", ".dao", ".888", ".zil", ".blockchain"}
 
-	for _, tld := range blockchainTLDs {
+func isBlockchainDomain(domain string) bool {
+	for _, tld := range BlockchainTLDs {
 		if strings.HasSuffix(domain, tld) {
 			return true
 		}
 	}
 	return false
 }
+
+// Replay re-runs parsing and valuation over raw data captured by an earlier
+// run (see Options.Evidence) without any network access. whoisRaw and
+// answers replace the WHOIS and DNS sections when present; all other
+// sections are kept from the captured result.
+func (a *Analyzer) Replay(captured *Result, whoisRaw string, answers []checker.Record) *Result {
+	result := *captured
+	domain := result.Domain
+
+	if answers != nil {
+		dnsData := checker.ResultFromAnswers(domain, answers, a.opts.TTLReport)
+		if prev := captured.DNSAvailability; prev != nil {
+			dnsData.CheckedAt = prev.CheckedAt
+			if !a.opts.VerboseDNS && prev.Answers == nil {
+				dnsData.Answers = nil
+			}
+		}
+		result.DNSAvailability = dnsData
+	}
+
+	if whoisRaw != "" {
+		whoisData := a.whoisClient.Parse(domain, whoisRaw)
+		if prev := captured.WhoisData; prev != nil {
+			whoisData.CheckedAt = prev.CheckedAt
+		}
+		result.WhoisData = whoisData
+	}
+
+	result.ValuationData = a.valuator.Evaluate(domain)
+	a.valuator.ApplyCarryingCost(result.ValuationData, domain)
+	a.valuator.ApplyNegotiation(result.ValuationData, a.opts.Negotiation)
+	a.valuator.ApplyLease(result.ValuationData, a.opts.LeaseCapRate)
+	applySeizure(&result)
+	result.Warnings = nil
+	collectWarnings(&result)
+	collectFindings(&result, time.Now())
+	return &result
+}
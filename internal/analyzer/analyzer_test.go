@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"d3-domain-tool/internal/checker"
+	"d3-domain-tool/internal/vcr"
+)
+
+func TestParseErrorPolicy(t *testing.T) {
+	for input, want := range map[string]ErrorPolicy{
+		"":            PolicyDegrade,
+		"best-effort": PolicyDegrade,
+		"degrade":     PolicyDegrade,
+		" WARN ":      PolicyWarn,
+		"Fail":        PolicyFail,
+	} {
+		if got, err := ParseErrorPolicy(input); err != nil || got != want {
+			t.Errorf("ParseErrorPolicy(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"abort", "fail-fast", "degraded"} {
+		if _, err := ParseErrorPolicy(input); err == nil {
+			t.Errorf("ParseErrorPolicy(%q) succeeded", input)
+		}
+	}
+}
+
+func TestSectionErrors(t *testing.T) {
+	// An empty cassette keeps the analysis off the network.
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := os.WriteFile(path, []byte(`{"interactions": []}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cassette, err := vcr.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failing := func(policy ErrorPolicy) *Analyzer {
+		a := NewWithOptions(Options{ErrorPolicy: policy, Only: []string{"dns"}, Cassette: cassette})
+		a.dnsAnswers = func(string) ([]checker.Record, error) {
+			return nil, errors.New("resolver timed out")
+		}
+		return a
+	}
+
+	result, err := failing(PolicyDegrade).AnalyzeDomain("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != StatusDegraded || !result.Degraded() {
+		t.Errorf("status = %q, want %q", result.Status, StatusDegraded)
+	}
+	if len(result.SectionErrors) != 1 || result.SectionErrors[0].Section != "dns" || result.SectionErrors[0].Error != "resolver timed out" {
+		t.Errorf("unexpected section errors %+v", result.SectionErrors)
+	}
+
+	if _, err := failing(PolicyFail).AnalyzeDomain("example.com"); err == nil || err.Error() != "dns check failed: resolver timed out" {
+		t.Errorf("PolicyFail: got %v", err)
+	}
+}
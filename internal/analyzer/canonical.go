@@ -0,0 +1,43 @@
+package analyzer
+
+import (
+	"strings"
+
+	"d3-domain-tool/internal/suffix"
+)
+
+// Canonicalize turns pasted input such as "https://www.Example.com:443/path"
+// into the domain to analyze, "example.com": it drops the scheme, user
+// info, port, path, query and fragment, a trailing dot and a leading
+// "www." label, and lowercases the rest. Input that is already a domain is
+// only trimmed and lowercased.
+func Canonicalize(input string) string {
+	s := strings.TrimSpace(input)
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+	}
+	if i := strings.IndexAny(s, "/?#"); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		s = s[i+1:]
+	}
+	if i := strings.LastIndex(s, ":"); i >= 0 && digits(s[i+1:]) {
+		s = s[:i]
+	}
+	s = strings.ToLower(strings.TrimRight(s, "."))
+	// "www.com" and "www.co.uk" are domains of their own, not web hosts.
+	if rest := strings.TrimPrefix(s, "www."); rest != s && !suffix.IsPublic(rest) {
+		s = rest
+	}
+	return s
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
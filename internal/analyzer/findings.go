@@ -0,0 +1,122 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/findings"
+)
+
+// expiryWarningDays is how close an expiry must be to raise a finding; it
+// matches the portfolio summary.
+const expiryWarningDays = 60
+
+// collectFindings lists the issues of result in section order, scored by
+// the findings scheme.
+func collectFindings(result *Result, now time.Time) {
+	var list []findings.Finding
+	add := func(module, id, name, detail string) {
+		list = append(list, findings.New(module, id, name, detail))
+	}
+	// The detail of an expiring item names the date only, so it stays the
+	// same from day to day and baselines can match it; the severity rises
+	// as the date nears.
+	expiring := func(module, id, name string, expiry time.Time) {
+		f := findings.New(module, id, name, "expires "+expiry.Format("2006-01-02"))
+		f.Severity = findings.Expiring(int(expiry.Sub(now).Hours() / 24))
+		list = append(list, f)
+	}
+
+	if s := result.Seizure; s != nil && s.Seized {
+		add("seizure", findings.DomainSeized, "Domain seized by law enforcement", strings.Join(s.Evidence, "; "))
+	} else if s != nil && s.Possible {
+		add("seizure", findings.DomainMaybeSeized, "Possible seizure banner", strings.Join(s.Evidence, "; "))
+	}
+	if ti := result.ThreatIntel; ti != nil {
+		for _, m := range ti.Matches {
+			detail := m.Feed + ": " + m.Threat
+			if m.Malware != "" {
+				detail += " (" + m.Malware + ")"
+			}
+			if strings.Contains(strings.ToLower(m.Threat), "botnet_cc") {
+				add("threat_intel", findings.ThreatC2, "Domain listed as botnet C2", detail)
+				continue
+			}
+			add("threat_intel", findings.ThreatListed, "Domain listed in threat feed", detail)
+		}
+	}
+	if w := result.WhoisData; w != nil && !w.Available && w.ExpiryDate != nil {
+		switch expiry := *w.ExpiryDate; {
+		case expiry.Before(now):
+			add("whois", findings.RegistrationExpired, "Registration expired", "expired "+expiry.Format("2006-01-02"))
+		case expiry.Sub(now) <= expiryWarningDays*24*time.Hour:
+			expiring("whois", findings.RegistrationExpiring, "Registration expiring soon", expiry)
+		}
+	}
+	if u := result.UDRP; u != nil {
+		for _, c := range u.Cases {
+			if c.Exact {
+				add("udrp", findings.UDRPDispute, "Domain named in a UDRP dispute", c.Provider+" "+c.ID)
+			}
+		}
+	}
+	if d := result.DanglingRecords; d != nil {
+		for _, f := range d.Findings {
+			detail := fmt.Sprintf("%s %s: %s", f.RecordType, f.Target, f.Reason)
+			if f.Takeover {
+				add("dangling_records", findings.DanglingTakeover, "Dangling record open to takeover", detail)
+			} else {
+				add("dangling_records", findings.DanglingRecord, "Dangling DNS record", detail)
+			}
+		}
+	}
+	if p := result.PortScan; p != nil {
+		for _, e := range p.Exposed {
+			add("port_scan", findings.PortExposed, "Sensitive port exposed", e)
+		}
+	}
+	if web := result.WebSecurity; web != nil {
+		if cert := web.Certificate; cert != nil && !cert.NotAfter.IsZero() {
+			switch {
+			case cert.NotAfter.Before(now):
+				add("web_security", findings.CertificateExpired, "TLS certificate expired", "expired "+cert.NotAfter.Format("2006-01-02"))
+			case cert.NotAfter.Sub(now) <= 30*24*time.Hour:
+				expiring("web_security", findings.CertificateExpiring, "TLS certificate expiring soon", cert.NotAfter)
+			}
+		}
+		for _, f := range web.Findings {
+			add("web_security", findings.WebSecurity, "Web security issue", f)
+		}
+	}
+	if p := result.HSTSPreload; p != nil {
+		for _, problem := range p.Problems {
+			add("hsts_preload", findings.HSTSPreload, "HSTS preload requirement not met", problem)
+		}
+	}
+	if e := result.EmailSecurity; e != nil {
+		if e.SPF != nil {
+			for _, issue := range e.SPF.Issues {
+				add("email_security", findings.SPFIssue, "SPF policy issue", issue)
+			}
+		}
+		if e.DMARC != nil {
+			for _, issue := range e.DMARC.Issues {
+				add("email_security", findings.DMARCIssue, "DMARC policy issue", issue)
+			}
+		}
+	}
+	if v := result.IPv6; v != nil {
+		for _, issue := range v.Issues {
+			add("ipv6", findings.IPv6Issue, "IPv6 readiness issue", issue)
+		}
+	}
+	for _, e := range result.SectionErrors {
+		add(e.Section, findings.CheckFailed, "Check failed", e.Error)
+	}
+	for _, w := range result.Warnings {
+		add(w.Section, findings.DataWarning, "Data quality warning", w.Warning)
+	}
+	result.Findings = list
+	result.Suppressed = nil
+}
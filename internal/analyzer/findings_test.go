@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/dangling"
+	"d3-domain-tool/internal/findings"
+	"d3-domain-tool/internal/webaudit"
+	"d3-domain-tool/internal/whois"
+)
+
+func TestCollectFindings(t *testing.T) {
+	now := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	expiry := now.AddDate(0, 0, 20)
+	result := &Result{
+		Domain:    "example.com",
+		WhoisData: &whois.Result{ExpiryDate: &expiry},
+		DanglingRecords: &dangling.Result{Findings: []dangling.Finding{
+			{RecordType: "CNAME", Target: "gone.s3.amazonaws.com", Reason: "NXDOMAIN", Takeover: true},
+			{RecordType: "MX", Target: "mail.old.example", Reason: "NXDOMAIN"},
+		}},
+		WebSecurity:   &webaudit.Result{Certificate: &webaudit.Certificate{NotAfter: now.AddDate(0, 0, -1)}},
+		SectionErrors: []SectionError{{Section: "doma", Error: "timeout"}},
+	}
+	collectFindings(result, now)
+	want := []struct {
+		module, id string
+		severity   findings.Severity
+	}{
+		{"whois", findings.RegistrationExpiring, findings.Medium},
+		{"dangling_records", findings.DanglingTakeover, findings.Critical},
+		{"dangling_records", findings.DanglingRecord, findings.Medium},
+		{"web_security", findings.CertificateExpired, findings.High},
+		{"doma", findings.CheckFailed, findings.Info},
+	}
+	if len(result.Findings) != len(want) {
+		t.Fatalf("findings = %+v", result.Findings)
+	}
+	for i, w := range want {
+		if f := result.Findings[i]; f.Module != w.module || f.ID != w.id || f.Severity != w.severity {
+			t.Errorf("finding %d = %+v, want %s %s %s", i, f, w.module, w.id, w.severity)
+		}
+	}
+
+	clean := &Result{Domain: "ok.com", WhoisData: &whois.Result{Available: true}}
+	collectFindings(clean, now)
+	if len(clean.Findings) != 0 {
+		t.Errorf("clean = %+v", clean.Findings)
+	}
+}
@@ -0,0 +1,123 @@
+package analyzer
+
+import (
+	"reflect"
+	"time"
+)
+
+// Freshness sources.
+const (
+	SourceLive  = "live"
+	SourceCache = "cache"
+)
+
+// Freshness tells whether a section of a result was fetched for it or
+// served from an earlier result, and when it was checked.
+type Freshness struct {
+	Section   string    `json:"section"`
+	Source    string    `json:"source"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Age is how old the section was when the result was made.
+func (f Freshness) Age(at time.Time) time.Duration {
+	if f.CheckedAt.IsZero() {
+		return 0
+	}
+	return at.Sub(f.CheckedAt)
+}
+
+// cachedSections are the sections that can be served from an earlier
+// result, by module, in report order. field returns a pointer to the
+// section's field of r; every section has CheckedAt and Error fields.
+// Valuation is computed locally and always live.
+var cachedSections = []struct {
+	module string
+	field  func(r *Result) interface{}
+}{
+	{"doma", func(r *Result) interface{} { return &r.DomaData }},
+	{"blockchain", func(r *Result) interface{} { return &r.BlockchainData }},
+	{"ens_history", func(r *Result) interface{} { return &r.ENSHistory }},
+	{"alt_root", func(r *Result) interface{} { return &r.AltRoot }},
+	{"dns", func(r *Result) interface{} { return &r.DNSAvailability }},
+	{"whois", func(r *Result) interface{} { return &r.WhoisData }},
+	{"whois_history", func(r *Result) interface{} { return &r.WhoisHistory }},
+	{"udrp", func(r *Result) interface{} { return &r.UDRP }},
+	{"threat_intel", func(r *Result) interface{} { return &r.ThreatIntel }},
+	{"chain_links", func(r *Result) interface{} { return &r.ChainLinks }},
+	{"dns_provider", func(r *Result) interface{} { return &r.DNSProvider }},
+	{"ipv6", func(r *Result) interface{} { return &r.IPv6 }},
+	{"mail_probe", func(r *Result) interface{} { return &r.MailProbe }},
+	{"port_scan", func(r *Result) interface{} { return &r.PortScan }},
+	{"web_security", func(r *Result) interface{} { return &r.WebSecurity }},
+	{"geo_dns", func(r *Result) interface{} { return &r.GeoDNS }},
+	{"hsts_preload", func(r *Result) interface{} { return &r.HSTSPreload }},
+	{"dangling_records", func(r *Result) interface{} { return &r.DanglingRecords }},
+	{"email_security", func(r *Result) interface{} { return &r.EmailSecurity }},
+	{"seizure", func(r *Result) interface{} { return &r.Seizure }},
+}
+
+// section returns the section value of module in r (nil when absent) and
+// when it was checked.
+func section(r *Result, module string) (reflect.Value, time.Time, bool) {
+	for _, s := range cachedSections {
+		if s.module != module {
+			continue
+		}
+		v := reflect.ValueOf(s.field(r)).Elem()
+		if v.IsNil() {
+			return v, time.Time{}, false
+		}
+		checkedAt, _ := v.Elem().FieldByName("CheckedAt").Interface().(time.Time)
+		return v, checkedAt, true
+	}
+	return reflect.Value{}, time.Time{}, false
+}
+
+// previous returns the earlier result sections may be reused from, or nil.
+func (a *Analyzer) previous(domain string) *Result {
+	if a.opts.Cache == nil || a.opts.MaxAge <= 0 {
+		return nil
+	}
+	return a.opts.Cache(domain)
+}
+
+// reuse copies module's section from prev into result when it was checked
+// within MaxAge and did not fail, and reports whether it did. The caller
+// then skips the fetch.
+func (a *Analyzer) reuse(result, prev *Result, module string) bool {
+	if prev == nil {
+		return false
+	}
+	src, checkedAt, ok := section(prev, module)
+	if !ok || checkedAt.IsZero() || time.Since(checkedAt) > a.opts.MaxAge || errorOf(src.Interface()) != "" {
+		return false
+	}
+	dst, _, _ := section(result, module)
+	dst.Set(src)
+	result.Freshness = append(result.Freshness, Freshness{Section: module, Source: SourceCache, CheckedAt: checkedAt})
+	return true
+}
+
+// stampFreshness lists every section of result, live or cached, in report
+// order. It only runs when a cache is configured, so plain runs keep their
+// output unchanged.
+func (a *Analyzer) stampFreshness(result *Result) {
+	if a.opts.Cache == nil {
+		return
+	}
+	cached := map[string]Freshness{}
+	for _, f := range result.Freshness {
+		cached[f.Section] = f
+	}
+	result.Freshness = nil
+	for _, s := range cachedSections {
+		if f, ok := cached[s.module]; ok {
+			result.Freshness = append(result.Freshness, f)
+			continue
+		}
+		if _, checkedAt, ok := section(result, s.module); ok {
+			result.Freshness = append(result.Freshness, Freshness{Section: s.module, Source: SourceLive, CheckedAt: checkedAt})
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/checker"
+	"d3-domain-tool/internal/udrp"
+	"d3-domain-tool/internal/whois"
+)
+
+func TestReuseFreshSections(t *testing.T) {
+	now := time.Now()
+	prev := &Result{
+		Domain:          "example.com",
+		WhoisData:       &whois.Result{Registrar: "Cached Registrar", CheckedAt: now.Add(-10 * time.Minute)},
+		DNSAvailability: &checker.DNSResult{CheckedAt: now.Add(-2 * time.Hour)},
+		UDRP:            &udrp.Result{CheckedAt: now.Add(-time.Minute), Error: "source unreachable"},
+	}
+	a := NewWithOptions(Options{
+		Cache:  func(string) *Result { return prev },
+		MaxAge: time.Hour,
+	})
+
+	result := &Result{Domain: "example.com"}
+	if p := a.previous("example.com"); p != prev {
+		t.Fatal("previous result not looked up")
+	}
+	if !a.reuse(result, prev, "whois") || result.WhoisData != prev.WhoisData {
+		t.Error("a section checked 10m ago was not reused")
+	}
+	if a.reuse(result, prev, "dns") {
+		t.Error("a section older than -max-age was reused")
+	}
+	if a.reuse(result, prev, "udrp") {
+		t.Error("a failed section was reused")
+	}
+	if a.reuse(result, prev, "port_scan") {
+		t.Error("a missing section was reused")
+	}
+
+	result.DNSAvailability = &checker.DNSResult{CheckedAt: now}
+	a.stampFreshness(result)
+	want := []Freshness{
+		{Section: "dns", Source: SourceLive, CheckedAt: now},
+		{Section: "whois", Source: SourceCache, CheckedAt: prev.WhoisData.CheckedAt},
+	}
+	if len(result.Freshness) != len(want) {
+		t.Fatalf("freshness = %+v", result.Freshness)
+	}
+	for i := range want {
+		if got := result.Freshness[i]; got.Section != want[i].Section || got.Source != want[i].Source || !got.CheckedAt.Equal(want[i].CheckedAt) {
+			t.Errorf("freshness[%d] = %+v, want %+v", i, got, want[i])
+		}
+	}
+	if age := result.Freshness[1].Age(now); age != 10*time.Minute {
+		t.Errorf("cached whois age = %v", age)
+	}
+
+	// Without -max-age every section is fetched.
+	if NewWithOptions(Options{Cache: a.opts.Cache}).previous("example.com") != nil {
+		t.Error("MaxAge 0 reused cached sections")
+	}
+}
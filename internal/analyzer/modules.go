@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Modules lists the analyzer modules by the section names used in results
+// and section errors. Owner detection runs with its doma or blockchain
+// module.
+var Modules = []string{
+	"doma",
+	"blockchain",
+	"ens_history",
+	"alt_root",
+	"dns",
+	"whois",
+	"whois_history",
+	"udrp",
+	"threat_intel",
+	"chain_links",
+	"dns_provider",
+	"ipv6",
+	"mail_probe",
+	"port_scan",
+	"web_security",
+	"geo_dns",
+	"hsts_preload",
+	"dangling_records",
+	"email_security",
+	"seizure",
+	"valuation",
+}
+
+// ParseModules splits a comma-separated module list and checks every name
+// against Modules.
+func ParseModules(s string) ([]string, error) {
+	var modules []string
+	for _, m := range strings.Split(s, ",") {
+		m = strings.ToLower(strings.TrimSpace(m))
+		if m == "" {
+			continue
+		}
+		if !knownModule(m) {
+			return nil, fmt.Errorf("unknown module %q (available: %s)", m, strings.Join(Modules, ", "))
+		}
+		modules = append(modules, m)
+	}
+	return modules, nil
+}
+
+func knownModule(name string) bool {
+	for _, m := range Modules {
+		if m == name {
+			return true
+		}
+	}
+	return false
+}
+
+func moduleSet(modules []string) map[string]bool {
+	if len(modules) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(modules))
+	for _, m := range modules {
+		set[m] = true
+	}
+	return set
+}
+
+// runs reports whether module should run. enabled is whether the options
+// turn it on; a module named in Options.Only runs regardless, provided it
+// has what it needs (an API key or endpoint) to do so.
+func (a *Analyzer) runs(module string, enabled bool) bool {
+	if a.skip[module] {
+		return false
+	}
+	if a.only != nil {
+		return a.only[module]
+	}
+	return enabled
+}
+
+// needsDNS reports whether a module that is gated on the domain's DNS
+// records runs, in which case the DNS lookup is made even when the dns
+// module itself is not selected.
+func (a *Analyzer) needsDNS() bool {
+	return a.runs("dns_provider", true) ||
+		a.runs("ipv6", a.opts.IPv6) ||
+		a.runs("web_security", a.opts.WebAudit) ||
+		a.runs("geo_dns", a.opts.GeoDNS)
+}
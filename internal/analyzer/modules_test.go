@@ -0,0 +1,55 @@
+package analyzer
+
+import (
+	"testing"
+)
+
+func TestParseModules(t *testing.T) {
+	modules, err := ParseModules(" DNS, valuation,,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modules) != 2 || modules[0] != "dns" || modules[1] != "valuation" {
+		t.Errorf("unexpected modules %v", modules)
+	}
+	if _, err := ParseModules("dns,wois"); err == nil {
+		t.Error("expected an error for an unknown module")
+	}
+}
+
+func TestPlanModules(t *testing.T) {
+	sections := func(opts Options) map[string]bool {
+		plan, err := NewWithOptions(opts).Plan("example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen := map[string]bool{}
+		for _, c := range plan.Calls {
+			seen[c.Section] = true
+		}
+		return seen
+	}
+
+	all := sections(Options{})
+	for _, s := range []string{"doma", "dns", "whois", "dns_provider"} {
+		if !all[s] {
+			t.Errorf("default plan is missing %s", s)
+		}
+	}
+
+	only := sections(Options{Only: []string{"whois", "email_security"}})
+	if len(only) != 2 || !only["whois"] || !only["email_security"] {
+		t.Errorf("-only=whois,email_security planned %v", only)
+	}
+
+	skipped := sections(Options{Skip: []string{"whois", "doma"}})
+	if skipped["whois"] || skipped["doma"] || !skipped["dns"] {
+		t.Errorf("-skip=whois,doma planned %v", skipped)
+	}
+
+	// Web checks are gated on A records, so DNS is looked up for them.
+	web := sections(Options{Only: []string{"web_security"}})
+	if !web["dns"] || !web["web_security"] || web["whois"] {
+		t.Errorf("-only=web_security planned %v", web)
+	}
+}
@@ -0,0 +1,206 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"d3-domain-tool/internal/altroot"
+	"d3-domain-tool/internal/portscan"
+	"d3-domain-tool/internal/webaudit"
+)
+
+// PlannedCall is an external connection AnalyzeDomain would make.
+type PlannedCall struct {
+	Section  string `json:"section"`
+	Protocol string `json:"protocol"`
+	Target   string `json:"target"`
+	Purpose  string `json:"purpose"`
+}
+
+// Plan lists the external calls for one domain without making them.
+type Plan struct {
+	Domain string        `json:"domain"`
+	Calls  []PlannedCall `json:"calls"`
+}
+
+// Plan returns the servers and APIs AnalyzeDomain would contact for domain
+// with the current options. No network traffic is generated. Targets that
+// depend on lookups (MX hosts, A records) are described rather than
+// resolved.
+func (a *Analyzer) Plan(domain string) (*Plan, error) {
+	domain = Canonicalize(domain)
+	if domain == "" {
+		return nil, fmt.Errorf("domain cannot be empty")
+	}
+	a = a.forDomain(domain)
+
+	plan := &Plan{Domain: domain}
+	add := func(section, protocol, target, purpose string) {
+		plan.Calls = append(plan.Calls, PlannedCall{Section: section, Protocol: protocol, Target: target, Purpose: purpose})
+	}
+	resolver := a.dnsChecker.Server()
+
+	if a.runs("doma", true) {
+		if a.domaClient.Configured() {
+			add("doma", "https", a.domaClient.Endpoint(), "DOMA tokenization status")
+		} else {
+			add("doma", "none", "-", "DOMA tokenization status (no data source configured; reported as unknown)")
+		}
+		if a.ownerDetector != nil {
+			add("doma", "https", a.ownerDetector.Endpoint(), "eth_getCode / eth_call on the DOMA token owner (only when tokenized on Ethereum)")
+		}
+	}
+
+	if isBlockchainDomain(domain) {
+		a.planBlockchain(domain, add)
+		return plan, nil
+	}
+
+	if ns := altroot.NamespaceFor(domain); ns != nil {
+		if !a.runs("alt_root", true) {
+			return plan, nil
+		}
+		resolvers := a.altRootChecker.Resolvers(domain)
+		if len(resolvers) == 0 {
+			add("alt_root", "none", "-", ns.Name+" lookup skipped; no resolver configured")
+		}
+		for _, r := range resolvers {
+			add("alt_root", "dns/udp+tcp", r, ns.Name+" NS and A lookups (tried in order)")
+		}
+		return plan, nil
+	}
+
+	if a.runs("dns", true) {
+		add("dns", "dns/udp+tcp", resolver, "A, MX, NS and TXT lookups")
+		if a.opts.VerboseDNS || a.opts.TTLReport || a.opts.Evidence != nil {
+			add("dns", "dns/udp+tcp", resolver, "Raw answers with TTLs for A, AAAA, CNAME, MX, NS, TXT")
+		}
+	} else if a.needsDNS() {
+		add("dns", "dns/udp+tcp", resolver, "A, MX, NS and TXT lookups to decide which selected checks apply (not reported)")
+	}
+
+	shortCircuit := !a.opts.NoShortCircuit && (a.runs("dns", true) || a.needsDNS())
+	if a.runs("whois", true) {
+		switch {
+		case shortCircuit && a.rdapServer != "":
+			add("whois", "https", a.rdapServer, "RDAP server configured for this TLD (only when DNS returns NXDOMAIN)")
+		case shortCircuit:
+			add("whois", "https", a.rdapClient.Endpoint(), "RDAP server list, then the TLD's RDAP server (only when DNS returns NXDOMAIN)")
+		}
+		if server := a.whoisClient.Server(domain); server != "" {
+			purpose := "Registration record"
+			if shortCircuit {
+				purpose += " (skipped when DNS returns NXDOMAIN and RDAP confirms the domain is unregistered)"
+			}
+			add("whois", "whois/tcp", server+":43", purpose)
+		} else {
+			add("whois", "none", "-", "No WHOIS server known for this TLD; lookup is skipped")
+		}
+	}
+	if a.whoisHistory != nil && a.runs("whois_history", true) {
+		add("whois_history", "https", a.whoisHistory.Endpoint(), "Archived WHOIS snapshots (uses API credits)")
+	}
+	if a.runs("udrp", a.opts.UDRP) {
+		for _, src := range a.udrpChecker.Sources() {
+			add("udrp", "https", src.URL, src.Name+" dispute decisions mentioning the domain's name")
+		}
+	}
+	if a.runs("threat_intel", a.opts.ThreatIntel) {
+		for _, feed := range a.threatChecker.Feeds() {
+			add("threat_intel", "https", feed.Endpoint(), feed.Name+" entries for the domain")
+		}
+	}
+
+	if a.runs("chain_links", a.opts.ChainLinks) {
+		add("chain_links", "dns/udp+tcp", resolver, "TXT lookups for ENS1 (gasless DNSSEC) and _ens."+domain+" claim records")
+		if rpc := a.linkChecker.RPCEndpoint(); rpc != "" {
+			add("chain_links", "https", rpc, "eth_call for the name's owner and resolver in the ENS registry")
+		}
+		if ud := a.linkChecker.UDEndpoint(); ud != "" {
+			add("chain_links", "https", ud, "Unstoppable Domains record and reverse lookup of the ENS owner")
+		}
+	}
+
+	if a.runs("dns_provider", true) {
+		add("dns_provider", "dns/udp+tcp", resolver, "NS lookup (only when the domain is delegated)")
+		add("dns_provider", "dns/udp+tcp", resolver, "Nameserver addresses and origin ASNs via origin.asn.cymru.com")
+	}
+
+	if a.runs("ipv6", a.opts.IPv6) {
+		add("ipv6", "dns/udp+tcp", resolver, "AAAA lookups for the domain, www, nameservers and MX hosts")
+		add("ipv6", "tcp6", "IPv6 addresses of those hosts ports 443, 53, 25", "Connect test, only when this machine has IPv6 connectivity")
+	}
+
+	if a.runs("mail_probe", a.opts.SMTPProbe) {
+		add("mail_probe", "dns/udp+tcp", resolver, "MX lookup")
+		add("mail_probe", "smtp/tcp", "MX hosts of "+domain+" ports "+joinPorts(a.mailProber.Ports()), "Banner, EHLO and STARTTLS (no mail is sent)")
+	}
+	if a.runs("port_scan", a.opts.ScanPorts) {
+		add("port_scan", "tcp", "A records of "+domain+" ports "+joinPorts(portscan.DefaultPorts), "TCP connect scan")
+	}
+	if a.runs("web_security", a.opts.WebAudit) {
+		add("web_security", "http", "http://"+domain+"/", "Redirect chain (only when the domain has an A record)")
+		add("web_security", "https", "https://"+domain+"/", "HSTS and security headers")
+	}
+	if a.runs("geo_dns", a.opts.GeoDNS) {
+		for _, v := range a.geoDetector.Vantages() {
+			purpose := "A/AAAA lookup from vantage " + v.Name
+			if v.Subnet != "" {
+				purpose += " (client subnet " + v.Subnet + ")"
+			}
+			add("geo_dns", "dns/udp+tcp", v.Resolver, purpose)
+		}
+	}
+	if a.runs("hsts_preload", a.opts.HSTSPreload) {
+		add("hsts_preload", "https", webaudit.DefaultPreloadAPI, "HSTS preload list status")
+	}
+	if a.runs("dangling_records", a.opts.Dangling) {
+		add("dangling_records", "dns/udp+tcp", resolver, "CNAME, MX, NS and SPF include targets")
+	}
+	if a.runs("email_security", a.opts.EmailSecurity) {
+		add("email_security", "dns/udp+tcp", resolver, "SPF includes and _dmarc TXT records")
+	}
+	if a.runs("seizure", a.opts.Seizure) {
+		add("seizure", "dns/udp+tcp", resolver, "NS lookup for law-enforcement seizure nameservers")
+		add("seizure", "http", "http://"+domain+"/", "Home page, searched for a seizure banner")
+	}
+
+	return plan, nil
+}
+
+// planBlockchain adds the calls for a blockchain domain.
+func (a *Analyzer) planBlockchain(domain string, add func(section, protocol, target, purpose string)) {
+	if a.runs("blockchain", true) {
+		if target := a.blockchainChecker.RegistryEndpoint(domain); target != "" {
+			add("blockchain", "https", target, "Registry lookup of availability, owner and resolver")
+			if !strings.HasSuffix(domain, ".eth") {
+				reverse, profile := a.blockchainChecker.ProfileEndpoints()
+				add("blockchain", "https", reverse, "Reverse resolution of the owner's address, unless the name is its reverse record")
+				add("blockchain", "https", profile, "Public profile: display name, verified social accounts and humanity check")
+			}
+		} else {
+			add("blockchain", "none", "-", "Availability (no -eth-rpc / -ud-api-key configured; reported as unknown)")
+		}
+		ens, ud := a.blockchainChecker.MetadataEndpoints()
+		if strings.HasSuffix(domain, ".eth") {
+			add("blockchain", "https", ens, "ERC-721 / ERC-1155 token metadata")
+		} else {
+			add("blockchain", "https", ud, "ERC-721 token metadata")
+		}
+		add("blockchain", "https", "token image URL", "Check that the token image resolves")
+		if a.ownerDetector != nil {
+			add("blockchain", "https", a.ownerDetector.Endpoint(), "eth_getCode / eth_call to classify the owner (account, Safe, registrar, marketplace)")
+		}
+	}
+	if a.ensSubgraph != nil && strings.HasSuffix(domain, ".eth") && a.runs("ens_history", true) {
+		add("ens_history", "https", a.ensSubgraph.Endpoint(), "Registration, renewal and transfer events from the ENS subgraph")
+	}
+}
+
+func joinPorts(ports []int) string {
+	s := make([]string, len(ports))
+	for i, p := range ports {
+		s[i] = fmt.Sprint(p)
+	}
+	return strings.Join(s, ",")
+}
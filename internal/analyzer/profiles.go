@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Profile is a named depth of analysis: which modules run and how long
+// slow lookups may take.
+type Profile struct {
+	Name        string
+	Description string
+	// Only restricts the analysis to these modules when set.
+	Only []string
+	// Deep turns on every opt-in module that only reads public data; the
+	// port scan and SMTP probe, which connect to the domain's hosts, stay
+	// opt-in.
+	Deep bool
+	// WHOISTimeout bounds each WHOIS query; 0 keeps the client default.
+	WHOISTimeout time.Duration
+}
+
+// Profiles are the built-in profiles. Standard is what runs without one.
+var Profiles = []Profile{
+	{
+		Name:        "quick",
+		Description: "DNS availability only",
+		Only:        []string{"dns"},
+	},
+	{
+		Name:        "standard",
+		Description: "the default checks",
+	},
+	{
+		Name: "deep",
+		Description: "due-diligence battery: adds web security, HSTS preload, dangling records, email security, " +
+			"seizure, GeoDNS, IPv6, UDRP and chain links, with a longer WHOIS timeout",
+		Deep:         true,
+		WHOISTimeout: 30 * time.Second,
+	},
+}
+
+// LookupProfile returns the built-in profile called name.
+func LookupProfile(name string) (Profile, error) {
+	var names []string
+	for _, p := range Profiles {
+		if p.Name == strings.ToLower(strings.TrimSpace(name)) {
+			return p, nil
+		}
+		names = append(names, p.Name)
+	}
+	return Profile{}, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// Apply sets the profile on opts. Options already set win: an explicit
+// Only replaces the profile's, opt-in modules turned on stay on, and a
+// Timeout or WHOISTimeout keeps the profile's WHOIS timeout out.
+func (p Profile) Apply(opts *Options) {
+	if len(opts.Only) == 0 {
+		opts.Only = p.Only
+	}
+	if p.Deep {
+		opts.WebAudit = true
+		opts.HSTSPreload = true
+		opts.Dangling = true
+		opts.EmailSecurity = true
+		opts.Seizure = true
+		opts.GeoDNS = true
+		opts.IPv6 = true
+		opts.UDRP = true
+		opts.ChainLinks = true
+	}
+	if opts.WHOISTimeout == 0 && opts.Timeout == 0 {
+		opts.WHOISTimeout = p.WHOISTimeout
+	}
+}
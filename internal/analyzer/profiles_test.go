@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProfiles(t *testing.T) {
+	quick, err := LookupProfile("Quick")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{}
+	quick.Apply(&opts)
+	a := NewWithOptions(opts)
+	if !a.runs("dns", true) || a.runs("whois", true) || a.runs("valuation", true) {
+		t.Error("quick profile should run DNS only")
+	}
+
+	// An explicit -only wins over the profile's.
+	opts = Options{Only: []string{"whois"}}
+	quick.Apply(&opts)
+	if len(opts.Only) != 1 || opts.Only[0] != "whois" {
+		t.Errorf("explicit Only was replaced: %v", opts.Only)
+	}
+
+	deep, _ := LookupProfile("deep")
+	opts = Options{}
+	deep.Apply(&opts)
+	if !opts.WebAudit || !opts.EmailSecurity || !opts.UDRP || opts.ScanPorts || opts.SMTPProbe {
+		t.Errorf("unexpected deep options %+v", opts)
+	}
+	if opts.WHOISTimeout != 30*time.Second {
+		t.Errorf("deep WHOIS timeout = %v", opts.WHOISTimeout)
+	}
+	opts = Options{Timeout: 3 * time.Second}
+	deep.Apply(&opts)
+	if opts.WHOISTimeout != 0 {
+		t.Errorf("deep profile overrode -timeout with WHOIS timeout %v", opts.WHOISTimeout)
+	}
+
+	standard, _ := LookupProfile("standard")
+	opts = Options{}
+	standard.Apply(&opts)
+	if opts.Only != nil || opts.WebAudit || opts.WHOISTimeout != 0 {
+		t.Errorf("standard profile changed options: %+v", opts)
+	}
+
+	if _, err := LookupProfile("exhaustive"); err == nil {
+		t.Error("unknown profile accepted")
+	}
+}
@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"context"
+
+	"d3-domain-tool/internal/checker"
+)
+
+// SkippedCheck records a module that was left out because an earlier,
+// cheaper check made it pointless.
+type SkippedCheck struct {
+	Module string `json:"module"`
+	Reason string `json:"reason"`
+}
+
+// Short-circuit reasons.
+const (
+	reasonNXDomain     = "DNS returned NXDOMAIN"
+	reasonUnregistered = "DNS returned NXDOMAIN and RDAP confirmed the domain is not registered"
+)
+
+// needsName lists the modules that only make sense for a name that exists
+// in DNS: they look up or connect to its records, so for an NXDOMAIN they
+// would spend time (and rate limit budget) finding nothing.
+var needsName = []string{
+	"chain_links",
+	"mail_probe",
+	"port_scan",
+	"hsts_preload",
+	"dangling_records",
+	"email_security",
+	"seizure",
+}
+
+// shortCircuit decides which modules to skip for a traditional domain
+// given its DNS answers. When DNS says the name does not exist, RDAP is
+// asked to confirm before WHOIS, the slowest check, is skipped too; an
+// RDAP failure leaves WHOIS to decide. WHOIS history and UDRP still run,
+// since a dropped name keeps its past.
+func (a *Analyzer) shortCircuit(ctx context.Context, result *Result, domain string, dnsData *checker.DNSResult) map[string]string {
+	if a.opts.NoShortCircuit || dnsData == nil || !dnsData.NXDomain {
+		return nil
+	}
+	skip := make(map[string]string, len(needsName)+1)
+	for _, m := range needsName {
+		skip[m] = reasonNXDomain
+	}
+	if a.runs("whois", true) {
+		rdap, err := a.rdapLookup(ctx, domain)
+		if err == nil {
+			result.RDAP = rdap
+			if rdap.Error == "" && !rdap.Registered {
+				skip["whois"] = reasonUnregistered
+			}
+		}
+	}
+	return skip
+}
+
+// pointless reports whether module is skipped by the short-circuit rules,
+// noting the skip on the result. Call it only for modules that would
+// otherwise run.
+func pointless(result *Result, skip map[string]string, module string) bool {
+	reason, ok := skip[module]
+	if ok {
+		result.SkippedChecks = append(result.SkippedChecks, SkippedCheck{Module: module, Reason: reason})
+	}
+	return ok
+}
@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"d3-domain-tool/internal/checker"
+	"d3-domain-tool/internal/whois"
+)
+
+func TestShortCircuit(t *testing.T) {
+	rdap := &whois.RDAPResult{Server: "https://rdap.example/"}
+	var rdapErr error
+	a := NewWithOptions(Options{})
+	a.rdapLookup = func(context.Context, string) (*whois.RDAPResult, error) { return rdap, rdapErr }
+
+	nx := &checker.DNSResult{Available: true, NXDomain: true}
+
+	result := &Result{}
+	skip := a.shortCircuit(context.Background(), result, "free.com", nx)
+	if skip["whois"] != reasonUnregistered || skip["mail_probe"] != reasonNXDomain {
+		t.Errorf("unregistered NXDOMAIN: skip = %v", skip)
+	}
+	if result.RDAP != rdap || result.Verdict() != VerdictAvailable {
+		t.Errorf("RDAP result not kept: %+v", result.RDAP)
+	}
+	if !pointless(result, skip, "whois") || pointless(result, skip, "udrp") || len(result.SkippedChecks) != 1 {
+		t.Errorf("skipped checks: %+v", result.SkippedChecks)
+	}
+
+	// A registered name, or RDAP being unreachable, leaves WHOIS to decide.
+	rdap = &whois.RDAPResult{Registered: true}
+	if skip := a.shortCircuit(context.Background(), &Result{}, "held.com", nx); skip["whois"] != "" || skip["port_scan"] == "" {
+		t.Errorf("registered NXDOMAIN: skip = %v", skip)
+	}
+	rdap, rdapErr = &whois.RDAPResult{Error: "RDAP lookup returned HTTP 503"}, nil
+	if skip := a.shortCircuit(context.Background(), &Result{}, "free.com", nx); skip["whois"] != "" {
+		t.Errorf("RDAP error: skip = %v", skip)
+	}
+	rdap, rdapErr = nil, errors.New("boom")
+	if skip := a.shortCircuit(context.Background(), &Result{}, "free.com", nx); skip["whois"] != "" {
+		t.Errorf("RDAP failure: skip = %v", skip)
+	}
+
+	// A name that exists without records is not short-circuited.
+	if skip := a.shortCircuit(context.Background(), &Result{}, "empty.com", &checker.DNSResult{Available: true}); skip != nil {
+		t.Errorf("NOERROR without records: skip = %v", skip)
+	}
+	off := NewWithOptions(Options{NoShortCircuit: true})
+	if skip := off.shortCircuit(context.Background(), &Result{}, "free.com", nx); skip != nil {
+		t.Errorf("NoShortCircuit: skip = %v", skip)
+	}
+}
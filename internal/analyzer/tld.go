@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/whois"
+)
+
+// TLDOptions override options for the domains under one TLD.
+type TLDOptions struct {
+	// WHOISTimeout bounds each WHOIS query, overriding Options.Timeout and
+	// Options.WHOISTimeout.
+	WHOISTimeout time.Duration
+	// Skip leaves out these modules in addition to Options.Skip.
+	Skip []string
+	// RDAPServer is the RDAP base URL to ask instead of the server listed
+	// in IANA's bootstrap registry.
+	RDAPServer string
+}
+
+// withTLDs prepares an analyzer per TLD of a.opts.TLDs: a copy of a with
+// the TLD's overrides applied.
+func (a *Analyzer) withTLDs() *Analyzer {
+	if len(a.opts.TLDs) == 0 {
+		return a
+	}
+	a.tlds = make(map[string]*Analyzer, len(a.opts.TLDs))
+	for name, t := range a.opts.TLDs {
+		v := *a
+		if t.WHOISTimeout > 0 {
+			v.whoisClient = a.whoisClient.WithTimeout(t.WHOISTimeout)
+		}
+		if len(t.Skip) > 0 {
+			v.skip = moduleSet(append(append([]string{}, a.opts.Skip...), t.Skip...))
+		}
+		if server := t.RDAPServer; server != "" {
+			v.rdapServer = server
+			v.rdapLookup = func(ctx context.Context, domain string) (*whois.RDAPResult, error) {
+				return a.rdapClient.LookupServer(ctx, server, domain)
+			}
+		}
+		a.tlds[strings.ToLower(strings.Trim(name, "."))] = &v
+	}
+	return a
+}
+
+// forDomain returns the analyzer for domain: the one of the longest TLD
+// in Options.TLDs that domain is under, or a itself.
+func (a *Analyzer) forDomain(domain string) *Analyzer {
+	if len(a.tlds) == 0 {
+		return a
+	}
+	for i := strings.IndexByte(domain, '.'); i >= 0; {
+		if v, ok := a.tlds[domain[i+1:]]; ok {
+			return v
+		}
+		next := strings.IndexByte(domain[i+1:], '.')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return a
+}
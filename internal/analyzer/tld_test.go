@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTLDOverrides(t *testing.T) {
+	a := NewWithOptions(Options{
+		Skip: []string{"doma"},
+		TLDs: map[string]TLDOptions{
+			"br":    {WHOISTimeout: 30 * time.Second},
+			".COM":  {Skip: []string{"whois"}},
+			"co.uk": {RDAPServer: "https://rdap.example/"},
+		},
+	})
+
+	for domain, want := range map[string]string{
+		"example.com.br": "br",
+		"example.com":    "com",
+		"a.b.co.uk":      "co.uk",
+		"example.uk":     "",
+		"example.net":    "",
+	} {
+		got := a.forDomain(domain)
+		if (want == "") != (got == a) || (want != "" && got != a.tlds[want]) {
+			t.Errorf("forDomain(%q) did not pick the %q overrides", domain, want)
+		}
+	}
+
+	br := a.forDomain("example.br")
+	if br.whoisClient == a.whoisClient || a.forDomain("example.net").whoisClient != a.whoisClient {
+		t.Error("the WHOIS timeout override must only apply to .br")
+	}
+
+	plan, err := a.Plan("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range plan.Calls {
+		if c.Section == "whois" || c.Section == "doma" {
+			t.Errorf("skipped module %s is planned for .com", c.Section)
+		}
+	}
+
+	plan, err = a.Plan("example.co.uk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rdap := false
+	for _, c := range plan.Calls {
+		rdap = rdap || (c.Section == "whois" && c.Target == "https://rdap.example/")
+	}
+	if !rdap {
+		t.Errorf("the RDAP server override is not planned: %+v", plan.Calls)
+	}
+}
@@ -0,0 +1,285 @@
+// Package audit keeps an append-only log of every query the tool sends to
+// an external service: who ran it, when, which backend was asked and
+// about which domain. Each entry carries the hash of the one before it,
+// so an edited, inserted or deleted line breaks the chain and Verify
+// reports it. Lines cut off the end of the log leave an intact chain and
+// are not detected.
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+
+	"d3-domain-tool/internal/whois"
+)
+
+// Backends.
+const (
+	BackendWHOIS = "whois"
+	BackendHTTP  = "http"
+	// BackendAPI marks an analysis requested through serve mode; the
+	// queries it causes follow it in the log.
+	BackendAPI = "api"
+)
+
+// Entry is one logged query.
+type Entry struct {
+	Time  time.Time `json:"time"`
+	Actor string    `json:"actor"`
+	// Backend is whois, http or api; Server is the WHOIS server or HTTP
+	// host asked.
+	Backend string `json:"backend"`
+	Server  string `json:"server"`
+	// Query is the WHOIS query, or the HTTP method and URL without its
+	// query string, which may hold API keys.
+	Query  string `json:"query"`
+	Domain string `json:"domain,omitempty"`
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// Prev is the Hash of the previous entry, empty for the first.
+	Prev string `json:"prev"`
+	Hash string `json:"hash"`
+}
+
+// Log appends entries to a file.
+type Log struct {
+	mu    sync.Mutex
+	f     *os.File
+	actor string
+	prev  string
+	now   func() time.Time
+}
+
+// DefaultActor is the name of the user running the tool.
+func DefaultActor() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
+
+// Open opens the log at path for appending, creating it if needed, and
+// records entries under actor. The chain continues from the last entry.
+func Open(path, actor string) (*Log, error) {
+	entries, err := Read(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %v", err)
+	}
+	l := &Log{f: f, actor: actor, now: time.Now}
+	if len(entries) > 0 {
+		l.prev = entries[len(entries)-1].Hash
+	}
+	return l, nil
+}
+
+// Close closes the log file.
+func (l *Log) Close() error {
+	return l.f.Close()
+}
+
+// Add appends e, filling in its time, actor (unless set) and hashes.
+func (l *Log) Add(e Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e.Time = l.now().UTC()
+	if e.Actor == "" {
+		e.Actor = l.actor
+	}
+	e.Prev = l.prev
+	e.Hash = hash(e)
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := l.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %v", err)
+	}
+	l.prev = e.Hash
+	return nil
+}
+
+// add logs e, reporting a failure on stderr: an unwritable log must not
+// fail the query it describes.
+func (l *Log) add(e Entry) {
+	if err := l.Add(e); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// hash is the SHA-256 of e without its Hash.
+func hash(e Entry) string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// WHOIS wraps a port-43 query function so each query is logged.
+func (l *Log) WHOIS(next func(ctx context.Context, server, query string) (string, error)) func(ctx context.Context, server, query string) (string, error) {
+	return func(ctx context.Context, server, query string) (string, error) {
+		body, err := next(ctx, server, query)
+		e := Entry{Backend: BackendWHOIS, Server: server, Query: query, Domain: whois.QueryDomain(query)}
+		e.Actor, _ = ctx.Value(actorKey{}).(string)
+		if err != nil {
+			e.Error = err.Error()
+		}
+		l.add(e)
+		return body, err
+	}
+}
+
+type (
+	domainKey struct{}
+	actorKey  struct{}
+)
+
+// WithDomain returns a copy of ctx under which HTTP requests are logged
+// as queries about domain.
+func WithDomain(ctx context.Context, domain string) context.Context {
+	return context.WithValue(ctx, domainKey{}, domain)
+}
+
+// WithActor returns a copy of ctx under which queries are logged as run
+// by actor rather than the log's own, as for a serve mode tenant.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Transport wraps base so each HTTP request is logged, with the domain
+// and actor of its context set by WithDomain and WithActor.
+func (l *Log) Transport(base http.RoundTripper) http.RoundTripper {
+	return &transport{log: l, base: base}
+}
+
+type transport struct {
+	log  *Log
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u := *req.URL
+	u.RawQuery, u.User, u.Fragment = "", nil, ""
+	e := Entry{Backend: BackendHTTP, Server: req.URL.Hostname(), Query: req.Method + " " + u.String()}
+	e.Domain, _ = req.Context().Value(domainKey{}).(string)
+	e.Actor, _ = req.Context().Value(actorKey{}).(string)
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		e.Error = err.Error()
+	} else {
+		e.Status = resp.StatusCode
+	}
+	t.log.add(e)
+	return resp, err
+}
+
+// Read returns the entries of the log at path.
+func Read(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Verify checks the hash chain of a complete log and returns an error
+// naming the first entry that was altered, removed or inserted. Entries
+// removed from the end cannot be told apart from a shorter log.
+func Verify(entries []Entry) error {
+	prev := ""
+	for i, e := range entries {
+		if e.Prev != prev {
+			return fmt.Errorf("entry %d: chain broken (an entry before it was removed or inserted)", i+1)
+		}
+		if hash(e) != e.Hash {
+			return fmt.Errorf("entry %d: hash mismatch (the entry was altered)", i+1)
+		}
+		prev = e.Hash
+	}
+	return nil
+}
+
+// Filter selects entries for export. Zero fields match everything.
+type Filter struct {
+	Since  time.Time
+	Until  time.Time
+	Domain string
+	Actor  string
+}
+
+// Match reports whether e passes the filter. Until is exclusive.
+func (f Filter) Match(e Entry) bool {
+	switch {
+	case !f.Since.IsZero() && e.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !e.Time.Before(f.Until):
+		return false
+	case f.Domain != "" && !strings.EqualFold(e.Domain, f.Domain):
+		return false
+	case f.Actor != "" && e.Actor != f.Actor:
+		return false
+	}
+	return true
+}
+
+// Export is the result of the audit-export command.
+type Export struct {
+	Log     string  `json:"log"`
+	Total   int     `json:"total"`
+	Entries []Entry `json:"entries"`
+	// Verified reports whether the whole log's hash chain is intact;
+	// VerifyError says where it is not.
+	Verified    bool   `json:"verified"`
+	VerifyError string `json:"verify_error,omitempty"`
+}
+
+// NewExport reads the log at path, verifies it and selects the entries
+// matching filter.
+func NewExport(path string, filter Filter) (*Export, error) {
+	entries, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+	export := &Export{Log: path, Total: len(entries), Entries: []Entry{}, Verified: true}
+	if err := Verify(entries); err != nil {
+		export.Verified = false
+		export.VerifyError = err.Error()
+	}
+	for _, e := range entries {
+		if filter.Match(e) {
+			export.Entries = append(export.Entries, e)
+		}
+	}
+	return export, nil
+}
@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := Open(path, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+	query := l.WHOIS(func(ctx context.Context, server, query string) (string, error) {
+		return "Domain Name: EXAMPLE.COM", nil
+	})
+	query(context.Background(), "whois.verisign-grs.com", "domain example.com")
+	l.Add(Entry{Backend: BackendAPI, Actor: "acme", Query: "analyze", Domain: "b.com"})
+	l.Close()
+
+	// Reopening continues the chain.
+	l, err = Open(path, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	client := &http.Client{Transport: l.Transport(http.DefaultTransport)}
+	req, _ := http.NewRequestWithContext(WithDomain(context.Background(), "example.com"), http.MethodGet, ts.URL+"/v1/lookup?key=secret", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	l.Close()
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	if e := entries[0]; e.Actor != "alice" || e.Backend != BackendWHOIS || e.Domain != "example.com" || e.Server != "whois.verisign-grs.com" {
+		t.Errorf("unexpected WHOIS entry %+v", e)
+	}
+	if e := entries[1]; e.Actor != "acme" {
+		t.Errorf("explicit actor was replaced: %+v", e)
+	}
+	if e := entries[2]; e.Actor != "bob" || e.Status != 200 || e.Domain != "example.com" || strings.Contains(e.Query, "secret") || !strings.HasSuffix(e.Query, "/v1/lookup") {
+		t.Errorf("unexpected HTTP entry %+v", e)
+	}
+	if err := Verify(entries); err != nil {
+		t.Errorf("intact log failed verification: %v", err)
+	}
+
+	tampered := append([]Entry{}, entries...)
+	tampered[1].Domain = "c.com"
+	if err := Verify(tampered); err == nil || !strings.Contains(err.Error(), "entry 2") {
+		t.Errorf("altered entry: got %v", err)
+	}
+	if err := Verify(append(entries[:1:1], entries[2])); err == nil {
+		t.Error("removed entry was not detected")
+	}
+}
+
+func TestWithActor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := Open(path, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithDomain(WithActor(context.Background(), "acme"), "example.com")
+	query := l.WHOIS(func(ctx context.Context, server, query string) (string, error) {
+		return "", nil
+	})
+	query(ctx, "whois.verisign-grs.com", "domain example.com")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	client := &http.Client{Transport: l.Transport(http.DefaultTransport)}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+	if resp, err := client.Do(req); err == nil {
+		resp.Body.Close()
+	}
+	l.Close()
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	for _, e := range entries {
+		if e.Actor != "acme" || e.Domain != "example.com" {
+			t.Errorf("%s entry not attributed to the context's actor and domain: %+v", e.Backend, e)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := Open(path, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := 0
+	l.now = func() time.Time { day++; return time.Date(2026, 3, day, 0, 0, 0, 0, time.UTC) }
+	for _, d := range []string{"a.com", "b.com", "a.com"} {
+		l.Add(Entry{Backend: BackendWHOIS, Query: d, Domain: d})
+	}
+	l.Close()
+
+	export, err := NewExport(path, Filter{Domain: "A.com", Since: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !export.Verified || export.Total != 3 || len(export.Entries) != 1 || export.Entries[0].Time.Day() != 3 {
+		t.Errorf("unexpected export %+v", export)
+	}
+
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, []byte(strings.Replace(string(data), "b.com", "x.com", 1)), 0600)
+	if export, err = NewExport(path, Filter{}); err != nil || export.Verified {
+		t.Errorf("tampered log exported as verified: %+v, %v", export, err)
+	}
+}
@@ -0,0 +1,150 @@
+// Package baseline records accepted findings so later runs only report new
+// ones. A suppression covers one kind of finding on one domain, up to the
+// severity it was accepted at, optionally until it expires.
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/findings"
+)
+
+// Baseline is a set of suppressions, as stored in a baseline file.
+type Baseline struct {
+	Created      time.Time     `json:"created"`
+	Suppressions []Suppression `json:"suppressions"`
+}
+
+// Suppression accepts a finding.
+type Suppression struct {
+	Domain string `json:"domain"`
+	ID     string `json:"id"`
+	// Detail limits the suppression to the finding with this detail; when
+	// empty it covers every finding of the kind on the domain.
+	Detail string `json:"detail,omitempty"`
+	// Severity is the highest severity accepted. A finding that becomes
+	// more severe, such as an expiry drawing near, is reported again.
+	Severity findings.Severity `json:"severity"`
+	Reason   string            `json:"reason,omitempty"`
+	// Expires ends the suppression; nil never expires.
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// Expired reports whether s no longer applies at now.
+func (s Suppression) Expired(now time.Time) bool {
+	return s.Expires != nil && !now.Before(*s.Expires)
+}
+
+func (s Suppression) covers(domain string, f findings.Finding) bool {
+	return s.Domain == domain && s.ID == f.ID &&
+		(s.Detail == "" || s.Detail == f.Detail) && f.Severity <= s.Severity
+}
+
+// Load reads a baseline file.
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	for i, s := range b.Suppressions {
+		if s.Domain == "" || s.ID == "" {
+			return nil, fmt.Errorf("%s: suppression %d needs a domain and an id", path, i+1)
+		}
+	}
+	return &b, nil
+}
+
+// Save writes b to path, ordered by domain and finding.
+func Save(path string, b *Baseline) error {
+	sort.SliceStable(b.Suppressions, func(i, j int) bool {
+		x, y := b.Suppressions[i], b.Suppressions[j]
+		if x.Domain != y.Domain {
+			return x.Domain < y.Domain
+		}
+		return x.ID < y.ID
+	})
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Expired returns the suppressions that have expired at now.
+func (b *Baseline) Expired(now time.Time) []Suppression {
+	var expired []Suppression
+	for _, s := range b.Suppressions {
+		if s.Expired(now) {
+			expired = append(expired, s)
+		}
+	}
+	return expired
+}
+
+// Apply moves the findings of result that an active suppression covers
+// from Findings to Suppressed.
+func (b *Baseline) Apply(result *analyzer.Result, now time.Time) {
+	var kept []findings.Finding
+	for _, f := range result.Findings {
+		if b.suppresses(result.Domain, f, now) {
+			result.Suppressed = append(result.Suppressed, f)
+		} else {
+			kept = append(kept, f)
+		}
+	}
+	result.Findings = kept
+}
+
+func (b *Baseline) suppresses(domain string, f findings.Finding, now time.Time) bool {
+	for _, s := range b.Suppressions {
+		if !s.Expired(now) && s.covers(domain, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// FromResults builds a baseline accepting every finding of results. The
+// suppressions expire after ttl, or never when ttl is 0. Findings already
+// suppressed by prev keep their suppression, reason and expiry included.
+func FromResults(results []*analyzer.Result, prev *Baseline, ttl time.Duration, now time.Time) *Baseline {
+	b := &Baseline{Created: now.UTC()}
+	var expires *time.Time
+	if ttl > 0 {
+		t := now.Add(ttl).UTC()
+		expires = &t
+	}
+	kept := map[int]bool{}
+	for _, r := range results {
+		for _, f := range r.Findings {
+			b.Suppressions = append(b.Suppressions, Suppression{
+				Domain:   r.Domain,
+				ID:       f.ID,
+				Detail:   f.Detail,
+				Severity: f.Severity,
+				Expires:  expires,
+			})
+		}
+		if prev == nil {
+			continue
+		}
+		for _, f := range r.Suppressed {
+			for i, s := range prev.Suppressions {
+				if !kept[i] && !s.Expired(now) && s.covers(r.Domain, f) {
+					kept[i] = true
+					b.Suppressions = append(b.Suppressions, s)
+				}
+			}
+		}
+	}
+	return b
+}
@@ -0,0 +1,62 @@
+package baseline
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/findings"
+)
+
+func result(domain string, list ...findings.Finding) *analyzer.Result {
+	return &analyzer.Result{Domain: domain, Findings: list}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	spf := findings.New("email_security", findings.SPFIssue, "SPF policy issue", "too many lookups")
+	port := findings.New("port_scan", findings.PortExposed, "Sensitive port exposed", "1.2.3.4:22 (ssh)")
+	expiring := findings.New("whois", findings.RegistrationExpiring, "Registration expiring soon", "expires 2026-11-20")
+	expiring.Severity = findings.Low
+
+	b := FromResults([]*analyzer.Result{result("a.com", spf, expiring)}, nil, 24*time.Hour, now)
+	if len(b.Suppressions) != 2 || b.Suppressions[0].Expires == nil {
+		t.Fatalf("baseline = %+v", b)
+	}
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := Save(path, b); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := Load(path); err != nil || len(b.Suppressions) != 2 {
+		t.Fatalf("loaded %+v, %v", b, err)
+	}
+
+	// The same findings are suppressed; a new one, the same kind on another
+	// domain and an escalated expiry are not.
+	escalated := expiring
+	escalated.Severity = findings.High
+	r := result("a.com", spf, port, escalated)
+	other := result("b.com", spf)
+	b.Apply(r, now.Add(time.Hour))
+	b.Apply(other, now.Add(time.Hour))
+	if len(r.Suppressed) != 1 || r.Suppressed[0].ID != findings.SPFIssue || len(r.Findings) != 2 {
+		t.Errorf("a.com findings %+v, suppressed %+v", r.Findings, r.Suppressed)
+	}
+	if len(other.Findings) != 1 {
+		t.Errorf("b.com findings %+v", other.Findings)
+	}
+
+	// After the suppressions expire everything is reported again.
+	later := result("a.com", spf)
+	b.Apply(later, now.Add(25*time.Hour))
+	if len(later.Findings) != 1 || len(b.Expired(now.Add(25*time.Hour))) != 2 {
+		t.Errorf("expired suppression still applied: %+v", later)
+	}
+
+	// Rewriting the baseline keeps the suppressions still in use.
+	kept := FromResults([]*analyzer.Result{r}, b, 0, now.Add(time.Hour))
+	if len(kept.Suppressions) != 3 {
+		t.Errorf("rewritten baseline = %+v", kept.Suppressions)
+	}
+}
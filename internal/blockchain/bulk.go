@@ -0,0 +1,64 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ENSBulkRenewal is the ENS StaticBulkRenewal contract, which renews
+// several .eth names for the same duration in one transaction and
+// refunds any excess payment.
+const ENSBulkRenewal = "0xa12159e5131b1eEf6B4857EEE3e1954744b5033A"
+
+// EncodeRenewAll returns the calldata for renewAll(string[],uint256),
+// renewing labels (names without ".eth") for duration seconds.
+func EncodeRenewAll(labels []string, duration *big.Int) []byte {
+	return append(selector("renewAll(string[],uint256)"), encodeLabelsCall(labels, duration)...)
+}
+
+// BulkRentPrice returns the total fee in wei for renewing labels for
+// duration seconds, as quoted by the bulk renewal contract.
+func (r *RPCClient) BulkRentPrice(labels []string, duration *big.Int) (*big.Int, error) {
+	data := append(selector("rentPrice(string[],uint256)"), encodeLabelsCall(labels, duration)...)
+	ret, err := r.Call(ENSBulkRenewal, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(ret) != 32 {
+		return nil, fmt.Errorf("rentPrice returned %d bytes", len(ret))
+	}
+	return new(big.Int).SetBytes(ret), nil
+}
+
+// encodeLabelsCall ABI-encodes the (string[], uint256) arguments shared
+// by renewAll and rentPrice.
+func encodeLabelsCall(labels []string, duration *big.Int) []byte {
+	var out []byte
+	out = append(out, word(big.NewInt(64))...)
+	out = append(out, word(duration)...)
+
+	// The array is its length, one offset per element relative to the
+	// first offset, then the elements.
+	out = append(out, word(big.NewInt(int64(len(labels))))...)
+	offset := 32 * len(labels)
+	var tail []byte
+	for _, label := range labels {
+		out = append(out, word(big.NewInt(int64(offset+len(tail))))...)
+		tail = append(tail, word(big.NewInt(int64(len(label))))...)
+		tail = append(tail, padRight([]byte(label))...)
+	}
+	return append(out, tail...)
+}
+
+// word left-pads n to a 32-byte ABI word.
+func word(n *big.Int) []byte {
+	return n.FillBytes(make([]byte, 32))
+}
+
+// padRight pads b with zeros to a multiple of 32 bytes.
+func padRight(b []byte) []byte {
+	if rem := len(b) % 32; rem != 0 {
+		b = append(b, make([]byte, 32-rem)...)
+	}
+	return b
+}
@@ -0,0 +1,30 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestEncodeRenewAll(t *testing.T) {
+	data := EncodeRenewAll([]string{"abc", "vitalik"}, big.NewInt(31536000))
+	if got := hex.EncodeToString(data[:4]); got != hex.EncodeToString(selector("renewAll(string[],uint256)")) {
+		t.Fatalf("selector = %s", got)
+	}
+
+	want := strings.Join([]string{
+		"0000000000000000000000000000000000000000000000000000000000000040", // array offset
+		"0000000000000000000000000000000000000000000000000000000001e13380", // duration
+		"0000000000000000000000000000000000000000000000000000000000000002", // length
+		"0000000000000000000000000000000000000000000000000000000000000040", // offset of "abc"
+		"0000000000000000000000000000000000000000000000000000000000000080", // offset of "vitalik"
+		"0000000000000000000000000000000000000000000000000000000000000003",
+		"6162630000000000000000000000000000000000000000000000000000000000",
+		"0000000000000000000000000000000000000000000000000000000000000007",
+		"766974616c696b00000000000000000000000000000000000000000000000000",
+	}, "")
+	if got := hex.EncodeToString(data[4:]); got != want {
+		t.Errorf("arguments =\n%s\nwant\n%s", got, want)
+	}
+}
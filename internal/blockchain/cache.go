@@ -0,0 +1,247 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is how long mutable facts (owner, resolver, expiry,
+// availability) are trusted when registry events are not followed.
+const DefaultCacheTTL = time.Hour
+
+// maxEventSync is the largest block range replayed to invalidate the
+// cache; a cache further behind drops its mutable entries instead.
+const maxEventSync = 50000
+
+// logChunk is the block range asked for per eth_getLogs call, which
+// providers commonly cap.
+const logChunk = 2000
+
+// ENS events that change owners, resolvers or expiries. Their indexed
+// arguments are the namehashes and labelhashes cache entries depend on.
+var invalidatingEvents = []string{
+	Hex(Keccak256([]byte("Transfer(bytes32,address)"))),
+	Hex(Keccak256([]byte("NewOwner(bytes32,bytes32,address)"))),
+	Hex(Keccak256([]byte("NewResolver(bytes32,address)"))),
+	Hex(Keccak256([]byte("Transfer(address,address,uint256)"))),
+	Hex(Keccak256([]byte("NameRegistered(uint256,address,uint256)"))),
+	Hex(Keccak256([]byte("NameRenewed(uint256,uint256)"))),
+}
+
+var newOwnerEvent = invalidatingEvents[1]
+
+// Cache stores on-chain lookups across runs. Immutable facts, such as a
+// contract's code, are kept indefinitely. Mutable facts record the
+// namehashes or labelhashes they depend on; they expire after the TTL or,
+// when events are followed, stay until an ENS event touches one of them.
+type Cache struct {
+	path   string
+	ttl    time.Duration
+	events bool
+	now    func() time.Time
+
+	mu      sync.Mutex
+	data    cacheFile
+	hits    int
+	lookups int
+}
+
+type cacheFile struct {
+	// Block is the last block whose events have been applied.
+	Block   int64                 `json:"block,omitempty"`
+	Entries map[string]cacheEntry `json:"entries"`
+}
+
+type cacheEntry struct {
+	Result   string    `json:"result"`
+	Deps     []string  `json:"deps,omitempty"`
+	Mutable  bool      `json:"mutable,omitempty"`
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// LoadCache opens the cache file at path, starting empty when it does
+// not exist yet. With events set, mutable entries are invalidated by
+// ENS events (see Sync) instead of expiring.
+func LoadCache(path string, events bool) (*Cache, error) {
+	c := &Cache{
+		path:   path,
+		ttl:    DefaultCacheTTL,
+		events: events,
+		now:    time.Now,
+		data:   cacheFile{Entries: map[string]cacheEntry{}},
+	}
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &c.data); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if c.data.Entries == nil {
+		c.data.Entries = map[string]cacheEntry{}
+	}
+	return c, nil
+}
+
+// Save writes the cache back to its file.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	raw, err := json.Marshal(c.data)
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, raw, 0644)
+}
+
+// Stats returns the cache hits and lookups so far.
+func (c *Cache) Stats() (hits, lookups int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.lookups
+}
+
+// get returns the cached result for key, if it is still valid.
+func (c *Cache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lookups++
+	e, ok := c.data.Entries[key]
+	if !ok {
+		return nil, false
+	}
+	if e.Mutable && !c.events && c.now().Sub(e.StoredAt) > c.ttl {
+		delete(c.data.Entries, key)
+		return nil, false
+	}
+	result, err := decodeHex(e.Result)
+	if err != nil {
+		return nil, false
+	}
+	c.hits++
+	return result, true
+}
+
+// put stores result under key. deps are the hex namehashes or labelhashes
+// a mutable result depends on; immutable results have none.
+func (c *Cache) put(key string, result []byte, deps ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data.Entries[key] = cacheEntry{
+		Result:   Hex(result),
+		Deps:     deps,
+		Mutable:  len(deps) > 0,
+		StoredAt: c.now(),
+	}
+}
+
+// Sync applies the ENS registry and registrar events since the last sync,
+// dropping cached facts about every name they touch, and returns how many
+// entries were invalidated. It does nothing unless events are followed.
+func (c *Cache) Sync(rpc *RPCClient) (int, error) {
+	if !c.events {
+		return 0, nil
+	}
+	latest, err := rpc.BlockNumber()
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	synced := c.data.Block
+	c.mu.Unlock()
+	from := synced + 1
+	if synced == 0 || latest-from > maxEventSync {
+		// Too far behind to replay cheaply: start over from here.
+		return c.reset(latest), nil
+	}
+
+	touched := map[string]bool{}
+	for start := from; start <= latest; start += logChunk {
+		end := start + logChunk - 1
+		if end > latest {
+			end = latest
+		}
+		logs, err := rpc.Logs([]string{ENSRegistry, ENSBaseRegistrar}, invalidatingEvents, start, end)
+		if err != nil {
+			return 0, err
+		}
+		for _, l := range logs {
+			if len(l.Topics) == 0 {
+				continue
+			}
+			for _, topic := range l.Topics[1:] {
+				touched[strings.ToLower(topic)] = true
+			}
+			// NewOwner creates or reassigns the subnode keccak(node, label).
+			if strings.EqualFold(l.Topics[0], newOwnerEvent) && len(l.Topics) == 3 {
+				node, err1 := decodeHex(l.Topics[1])
+				label, err2 := decodeHex(l.Topics[2])
+				if err1 == nil && err2 == nil {
+					touched[Hex(Keccak256(node, label))] = true
+				}
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	invalidated := 0
+	for key, e := range c.data.Entries {
+		for _, dep := range e.Deps {
+			if touched[dep] {
+				delete(c.data.Entries, key)
+				invalidated++
+				break
+			}
+		}
+	}
+	c.data.Block = latest
+	return invalidated, nil
+}
+
+// Log is an event log entry.
+type Log struct {
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+	Block   string   `json:"blockNumber"`
+}
+
+// Logs returns the logs emitted by addresses with one of the topic0
+// event signatures between blocks from and to, inclusive.
+func (r *RPCClient) Logs(addresses, events []string, from, to int64) ([]Log, error) {
+	filter := map[string]interface{}{
+		"address":   addresses,
+		"topics":    []interface{}{events},
+		"fromBlock": fmt.Sprintf("0x%x", from),
+		"toBlock":   fmt.Sprintf("0x%x", to),
+	}
+	var logs []Log
+	if err := r.call(context.Background(), "eth_getLogs", []interface{}{filter}, &logs); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// reset drops every mutable entry and marks the cache synced at block.
+func (c *Cache) reset(block int64) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dropped := 0
+	for key, e := range c.data.Entries {
+		if e.Mutable {
+			delete(c.data.Entries, key)
+			dropped++
+		}
+	}
+	c.data.Block = block
+	return dropped
+}
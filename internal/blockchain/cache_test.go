@@ -0,0 +1,117 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cacheNode counts calls and answers owner() for every name, with logs
+// that transfer vault.eth in the registry.
+func cacheNode(t *testing.T, calls map[string]int) *httptest.Server {
+	owner := "0x" + strings.Repeat("0", 24) + strings.Repeat("a", 40)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		calls[req.Method]++
+		reply := map[string]interface{}{"jsonrpc": "2.0", "id": 1}
+		switch req.Method {
+		case "eth_call":
+			reply["result"] = owner
+		case "eth_getCode":
+			reply["result"] = "0x6080"
+		case "eth_blockNumber":
+			reply["result"] = fmt.Sprintf("0x%x", 1000+calls[req.Method])
+		case "eth_getLogs":
+			reply["result"] = []Log{{Topics: []string{invalidatingEvents[0], Hex(NameHash("vault.eth"))}}}
+		}
+		json.NewEncoder(w).Encode(reply)
+	}))
+}
+
+func TestCacheTTL(t *testing.T) {
+	calls := map[string]int{}
+	server := cacheNode(t, calls)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "chain-cache.json")
+	cache, err := LoadCache(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	r := NewRPCClient(server.URL)
+	r.SetCache(cache)
+	for i := 0; i < 3; i++ {
+		if _, _, err := r.ENSRecord("vault.eth"); err != nil {
+			t.Fatal(err)
+		}
+		r.GetCode("0x" + strings.Repeat("c", 40))
+	}
+	// owner() and resolver() once each, then from the cache.
+	if calls["eth_call"] != 2 || calls["eth_getCode"] != 1 {
+		t.Errorf("calls = %v", calls)
+	}
+	if hits, lookups := cache.Stats(); hits != 6 || lookups != 9 {
+		t.Errorf("hits %d of %d lookups", hits, lookups)
+	}
+
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadCache(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded.now = func() time.Time { return now.Add(2 * DefaultCacheTTL) }
+	r.SetCache(reloaded)
+	r.ENSRecord("vault.eth")
+	r.GetCode("0x" + strings.Repeat("c", 40))
+	// Owner facts expired; contract code is kept.
+	if calls["eth_call"] != 4 || calls["eth_getCode"] != 1 {
+		t.Errorf("after TTL calls = %v", calls)
+	}
+}
+
+func TestCacheEvents(t *testing.T) {
+	calls := map[string]int{}
+	server := cacheNode(t, calls)
+	defer server.Close()
+
+	cache, _ := LoadCache(filepath.Join(t.TempDir(), "chain-cache.json"), true)
+	cache.now = func() time.Time { return time.Now().Add(-24 * time.Hour) }
+	r := NewRPCClient(server.URL)
+	r.SetCache(cache)
+
+	// The first sync only records the starting block.
+	if n, err := cache.Sync(r); err != nil || n != 0 || calls["eth_getLogs"] != 0 {
+		t.Fatalf("first sync = %d, %v (calls %v)", n, err, calls)
+	}
+	r.ENSRecord("vault.eth")
+	r.ENSRecord("other.eth")
+	if calls["eth_call"] != 4 {
+		t.Fatalf("calls = %v", calls)
+	}
+
+	// Old entries stay valid while events are followed ...
+	r.ENSRecord("other.eth")
+	// ... until an event touches their name.
+	n, err := cache.Sync(r)
+	if err != nil || n != 2 || calls["eth_getLogs"] != 1 {
+		t.Fatalf("sync invalidated %d, %v (calls %v)", n, err, calls)
+	}
+	r.ENSRecord("vault.eth")
+	r.ENSRecord("other.eth")
+	if calls["eth_call"] != 6 {
+		t.Errorf("calls = %v, want only vault.eth looked up again", calls)
+	}
+}
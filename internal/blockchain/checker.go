@@ -1,26 +1,49 @@
 package blockchain
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
 
+// DefaultUDResolutionAPI is the Unstoppable Domains Resolution API.
+const DefaultUDResolutionAPI = "https://api.unstoppabledomains.com/resolve"
+
 type Checker struct {
-	client  *http.Client
-	timeout time.Duration
+	client         *http.Client
+	timeout        time.Duration
+	ensMetadataAPI string
+	udMetadataAPI  string
+	udAPI          string
+	udProfileAPI   string
+	udKey          string
+	rpc            *RPCClient
+	explorers      Explorers
 }
 
 type Result struct {
-	Available     bool              `json:"available"`
-	Type          string            `json:"type"`
-	Owner         string            `json:"owner,omitempty"`
-	Resolver      string            `json:"resolver,omitempty"`
-	Records       map[string]string `json:"records,omitempty"`
-	ExpiryDate    *time.Time        `json:"expiry_date,omitempty"`
-	CheckedAt     time.Time         `json:"checked_at"`
-	Error         string            `json:"error,omitempty"`
+	// Available is null when no data source for the name's registry is
+	// configured or the lookup failed; see Note.
+	Available  *bool             `json:"available"`
+	Note       string            `json:"note,omitempty"`
+	Type       string            `json:"type"`
+	Owner      string            `json:"owner,omitempty"`
+	Resolver   string            `json:"resolver,omitempty"`
+	Records    map[string]string `json:"records,omitempty"`
+	ExpiryDate *time.Time        `json:"expiry_date,omitempty"`
+	Token      *Token            `json:"token,omitempty"`
+	Profile    *UDProfile        `json:"ud_profile,omitempty"`
+	OwnerInfo  *OwnerInfo        `json:"owner_info,omitempty"`
+	Links      []Link            `json:"links,omitempty"`
+	// RPCEndpoint is the Ethereum RPC endpoint that answered the ENS
+	// lookups, without credentials.
+	RPCEndpoint string    `json:"rpc_endpoint,omitempty"`
+	CheckedAt   time.Time `json:"checked_at"`
+	Error       string    `json:"error,omitempty"`
 }
 
 func NewChecker() *Checker {
@@ -28,80 +51,209 @@ func NewChecker() *Checker {
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-		timeout: 10 * time.Second,
+		timeout:        10 * time.Second,
+		ensMetadataAPI: DefaultENSMetadataAPI,
+		udMetadataAPI:  DefaultUDMetadataAPI,
+		udAPI:          DefaultUDResolutionAPI,
+		udProfileAPI:   DefaultUDProfileAPI,
+		explorers:      DefaultExplorers,
+	}
+}
+
+// SetTimeout bounds each registry, metadata and profile request.
+func (c *Checker) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+	c.client.Timeout = timeout
+}
+
+// SetRPC enables ENS lookups through an Ethereum JSON-RPC endpoint.
+func (c *Checker) SetRPC(rpc *RPCClient) {
+	c.rpc = rpc
+}
+
+// SetUDKey enables Unstoppable Domains lookups through the Resolution API.
+func (c *Checker) SetUDKey(key string) {
+	c.udKey = key
+}
+
+// RegistryEndpoint returns where the availability of domain is looked up,
+// or "" when no source is configured.
+func (c *Checker) RegistryEndpoint(domain string) string {
+	if strings.HasSuffix(domain, ".eth") {
+		if c.rpc != nil {
+			return c.rpc.Endpoint()
+		}
+		return ""
 	}
+	if c.udKey != "" {
+		return c.udAPI
+	}
+	return ""
+}
+
+// MetadataEndpoints returns the token metadata services used for ENS and
+// Unstoppable Domains names.
+func (c *Checker) MetadataEndpoints() (ens, ud string) {
+	return c.ensMetadataAPI, c.udMetadataAPI
+}
+
+// ProfileEndpoints returns the Unstoppable Domains reverse resolution and
+// public profile APIs read for a registered name.
+func (c *Checker) ProfileEndpoints() (reverse, profile string) {
+	return c.udAPI + "/reverse/", c.udProfileAPI
+}
+
+// SetExplorers overrides the block explorers used for links; chains not in
+// e keep their default explorer.
+func (c *Checker) SetExplorers(e Explorers) {
+	c.explorers = e.Merge()
 }
 
 func (c *Checker) Check(domain string) (*Result, error) {
+	return c.CheckContext(context.Background(), domain)
+}
+
+// CheckContext is Check with every registry, RPC and metadata request
+// bounded by ctx.
+func (c *Checker) CheckContext(ctx context.Context, domain string) (*Result, error) {
 	result := &Result{
 		CheckedAt: time.Now(),
 		Records:   make(map[string]string),
 	}
 
 	if strings.HasSuffix(domain, ".eth") {
-		return c.checkENS(domain, result)
-	} else if strings.HasSuffix(domain, ".crypto") || strings.HasSuffix(domain, ".nft") || 
+		c.checkENS(ctx, domain, result)
+	} else if strings.HasSuffix(domain, ".crypto") || strings.HasSuffix(domain, ".nft") ||
 		strings.HasSuffix(domain, ".x") || strings.HasSuffix(domain, ".wallet") ||
 		strings.HasSuffix(domain, ".bitcoin") || strings.HasSuffix(domain, ".dao") ||
 		strings.HasSuffix(domain, ".888") || strings.HasSuffix(domain, ".zil") {
-		return c.checkUnstoppableDomains(domain, result)
+		c.checkUnstoppableDomains(ctx, domain, result)
+	} else {
+		return result, fmt.Errorf("unsupported blockchain domain type")
 	}
 
-	return result, fmt.Errorf("unsupported blockchain domain type")
+	result.Links = append(c.explorers.AddressLink("ethereum", "owner", result.Owner),
+		c.explorers.AddressLink("ethereum", "resolver", result.Resolver)...)
+
+	token, err := c.fetchToken(ctx, domain)
+	if err != nil {
+		if result.Error != "" {
+			result.Error += "; "
+		}
+		result.Error += err.Error()
+	}
+	result.Token = token
+
+	return result, nil
 }
 
-func (c *Checker) checkENS(domain string, result *Result) (*Result, error) {
+func (c *Checker) checkENS(ctx context.Context, domain string, result *Result) {
 	result.Type = "ENS"
-	
-	// Simulate ENS lookup - in a real implementation, you'd use web3 libraries
-	// or call Ethereum nodes directly
-	result.Available = c.simulateENSLookup(domain)
-	
-	if !result.Available {
-		result.Owner = "0x" + strings.Repeat("a", 40) // Simulated address
-		result.Resolver = "0x" + strings.Repeat("b", 40)
-		result.Records["ETH"] = "0x" + strings.Repeat("c", 40)
-		result.Records["BTC"] = "bc1" + strings.Repeat("d", 39)
+	if c.rpc == nil {
+		result.Note = "unknown — Ethereum RPC not configured (-eth-rpc)"
+		return
 	}
 
-	return result, nil
+	owner, resolver, err := c.rpc.ENSRecordContext(ctx, domain)
+	if err != nil {
+		result.Error = fmt.Sprintf("ENS lookup failed: %v", err)
+		return
+	}
+	result.RPCEndpoint = c.rpc.LastEndpoint()
+	result.Owner = owner
+	result.Resolver = resolver
+
+	labels := strings.Split(domain, ".")
+	if len(labels) != 2 {
+		// Subnames exist exactly when their parent created them.
+		result.Available = boolPtr(owner == "")
+		return
+	}
+
+	// Second-level names are registered in the base registrar, which
+	// keeps expired names unavailable during their grace period.
+	id := LabelHash(labels[0])
+	ret, err := c.rpc.cachedCall(ctx, ENSBaseRegistrar, append(selector("available(uint256)"), id...), id)
+	if err != nil || len(ret) != 32 {
+		result.Error = fmt.Sprintf("ENS registrar lookup failed: %v", err)
+		return
+	}
+	result.Available = boolPtr(ret[31] == 1)
+	if !*result.Available {
+		if expiry, err := c.rpc.ENSExpiryContext(ctx, domain); err == nil {
+			result.ExpiryDate = expiry
+		}
+	}
 }
 
-func (c *Checker) checkUnstoppableDomains(domain string, result *Result) (*Result, error) {
+func (c *Checker) checkUnstoppableDomains(ctx context.Context, domain string, result *Result) {
 	result.Type = "Unstoppable Domains"
-	
-	// Simulate Unstoppable Domains lookup
-	result.Available = c.simulateUDLookup(domain)
-	
-	if !result.Available {
-		result.Owner = "0x" + strings.Repeat("e", 40)
-		result.Records["crypto.ETH.address"] = "0x" + strings.Repeat("f", 40)
-		result.Records["crypto.BTC.address"] = "bc1" + strings.Repeat("g", 39)
+	if c.udKey == "" {
+		result.Note = "unknown — Unstoppable Domains API key not configured (-ud-api-key)"
+		return
 	}
 
-	return result, nil
-}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.udAPI+"/domains/"+url.PathEscape(domain), nil)
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+c.udKey)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		result.Error = fmt.Sprintf("Unstoppable Domains lookup failed: %v", err)
+		return
+	}
+	defer resp.Body.Close()
 
-// Simulate blockchain lookups - in production, these would make actual blockchain calls
-func (c *Checker) simulateENSLookup(domain string) bool {
-	// Simulate some domains being taken
-	commonDomains := []string{"test.eth", "example.eth", "hello.eth", "world.eth"}
-	for _, taken := range commonDomains {
-		if domain == taken {
-			return false
+	var body struct {
+		Meta struct {
+			Owner    string `json:"owner"`
+			Resolver string `json:"resolver"`
+			Reverse  bool   `json:"reverse"`
+		} `json:"meta"`
+		Records map[string]string `json:"records"`
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			result.Error = fmt.Sprintf("invalid Unstoppable Domains response: %v", err)
+			return
 		}
+	case http.StatusNotFound:
+	default:
+		result.Error = fmt.Sprintf("Unstoppable Domains lookup returned HTTP %d", resp.StatusCode)
+		return
 	}
-	// Most other domains are available in simulation
-	return len(strings.Split(domain, ".")[0]) > 3
-}
 
-func (c *Checker) simulateUDLookup(domain string) bool {
-	// Similar simulation for Unstoppable Domains
-	commonDomains := []string{"test.crypto", "example.nft", "hello.x"}
-	for _, taken := range commonDomains {
-		if domain == taken {
-			return false
+	owner := body.Meta.Owner
+	if strings.Trim(strings.TrimPrefix(owner, "0x"), "0") == "" {
+		owner = ""
+	}
+	result.Available = boolPtr(owner == "")
+	if owner != "" {
+		result.Owner = owner
+		result.Resolver = body.Meta.Resolver
+		for key, value := range body.Records {
+			result.Records[key] = value
 		}
+
+		profile := newUDProfile(body.Records)
+		profile.Reverse = body.Meta.Reverse
+		var errs []string
+		if !profile.Reverse {
+			if profile.PrimaryName, err = c.udReverse(ctx, owner); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if err := c.loadPublicProfile(ctx, domain, profile); err != nil {
+			errs = append(errs, err.Error())
+		}
+		profile.Error = strings.Join(errs, "; ")
+		result.Profile = profile
 	}
-	return len(strings.Split(domain, ".")[0]) > 3
-}
\ No newline at end of file
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,146 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckWithoutSources(t *testing.T) {
+	metadata := httptest.NewServer(http.NotFoundHandler())
+	defer metadata.Close()
+
+	c := NewChecker()
+	c.ensMetadataAPI, c.udMetadataAPI = metadata.URL, metadata.URL
+	for _, domain := range []string{"abc.eth", "abc.crypto"} {
+		result, err := c.Check(domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Available != nil || result.Owner != "" || len(result.Records) != 0 || result.Note == "" {
+			t.Errorf("%s: expected an unknown result, got %+v", domain, result)
+		}
+	}
+}
+
+func TestCheckENS(t *testing.T) {
+	word := func(n int64) string { return fmt.Sprintf("%064x", n) }
+	call := func(sig string) string { return "0x" + hex.EncodeToString(selector(sig)) }
+	owner := strings.Repeat("0", 24) + strings.Repeat("a", 40)
+
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		var msg struct{ To, Data string }
+		json.Unmarshal(req.Params[0], &msg)
+
+		taken := strings.HasSuffix(msg.Data, hex.EncodeToString(NameHash("taken.eth"))) ||
+			strings.HasSuffix(msg.Data, hex.EncodeToString(LabelHash("taken")))
+		result := "0x" + word(0)
+		switch {
+		case strings.HasPrefix(msg.Data, call("owner(bytes32)")) && taken:
+			result = "0x" + owner
+		case strings.HasPrefix(msg.Data, call("available(uint256)")) && !taken:
+			result = "0x" + word(1)
+		case strings.HasPrefix(msg.Data, call("nameExpires(uint256)")) && taken:
+			result = "0x" + word(1893456000) // 2030-01-01
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": result})
+	}))
+	defer node.Close()
+	metadata := httptest.NewServer(http.NotFoundHandler())
+	defer metadata.Close()
+
+	c := NewChecker()
+	c.ensMetadataAPI = metadata.URL
+	c.SetRPC(NewRPCClient(node.URL))
+
+	result, err := c.Check("taken.eth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Available == nil || *result.Available || result.Owner != "0x"+strings.Repeat("a", 40) {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if result.ExpiryDate == nil || result.ExpiryDate.Year() != 2030 {
+		t.Errorf("unexpected expiry %v", result.ExpiryDate)
+	}
+
+	result, err = c.Check("free.eth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Available == nil || !*result.Available || result.Owner != "" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestCheckUnstoppableDomains(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/domains/taken.crypto" {
+			w.Write([]byte(`{"meta": {"owner": "0x8aad44321a86b170879d7a244c1e8d360c99dda8", "resolver": "0xb66dce2da6afaaa98f2013446dbcb0f4b0ab2842"},
+				"records": {"crypto.ETH.address": "0x8aad44321a86b170879d7a244c1e8d360c99dda8",
+					"crypto.USDT.version.ERC20.address": "0x8aad44321a86b170879d7a244c1e8d360c99dda8",
+					"ipfs.html.value": "QmHash", "social.twitter.username": "taken", "crypto.BTC.address": ""}}`))
+			return
+		}
+		if r.URL.Path == "/reverse/0x8aad44321a86b170879d7a244c1e8d360c99dda8" {
+			w.Write([]byte(`{"meta": {"domain": "main.crypto"}}`))
+			return
+		}
+		w.Write([]byte(`{"meta": {"owner": null}, "records": {}}`))
+	}))
+	defer api.Close()
+	metadata := httptest.NewServer(http.NotFoundHandler())
+	defer metadata.Close()
+	profiles := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"profile": {"displayName": "Taken"}, "humanityCheck": {"verified": true},
+			"socialAccounts": {"twitter": {"location": "taken", "verified": true, "public": true},
+				"github": {"location": "taken-gh", "verified": true, "public": true},
+				"reddit": {"location": "taken-r", "verified": true, "public": true},
+				"discord": {"location": "hidden", "verified": true, "public": false}}}`))
+	}))
+	defer profiles.Close()
+
+	c := NewChecker()
+	c.udMetadataAPI, c.udAPI, c.udProfileAPI = metadata.URL, api.URL, profiles.URL
+	c.SetUDKey("key")
+
+	result, _ := c.Check("taken.crypto")
+	if result.Available == nil || *result.Available || result.Records["crypto.ETH.address"] == "" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	p := result.Profile
+	if p == nil || p.Error != "" {
+		t.Fatalf("profile = %+v", p)
+	}
+	if len(p.Addresses) != 2 || p.Addresses["USDT (ERC20)"] == "" || p.IPFS != "QmHash" {
+		t.Errorf("records profile = %+v", p)
+	}
+	if p.Socials["twitter"] != "taken" || p.Socials["github"] != "taken-gh" || len(p.Socials) != 3 ||
+		strings.Join(p.VerifiedSocials, ",") != "github,reddit,twitter" {
+		t.Errorf("socials = %v, verified %v", p.Socials, p.VerifiedSocials)
+	}
+	if p.Reverse || p.PrimaryName != "main.crypto" || p.DisplayName != "Taken" || p.HumanityCheck == nil || !*p.HumanityCheck {
+		t.Errorf("profile = %+v", p)
+	}
+	result, _ = c.Check("free.crypto")
+	if result.Available == nil || !*result.Available || result.Owner != "" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	c.SetUDKey("wrong")
+	if result, _ = c.Check("taken.crypto"); result.Available != nil || !strings.Contains(result.Error, "401") {
+		t.Errorf("expected an unknown result with an error, got %+v", result)
+	}
+}
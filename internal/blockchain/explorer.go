@@ -0,0 +1,83 @@
+package blockchain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Link is a labelled URL into a block explorer.
+type Link struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// Explorers maps a chain name to its block explorer's base URL.
+type Explorers map[string]string
+
+// DefaultExplorers are used for chains without a configured explorer.
+var DefaultExplorers = Explorers{
+	"ethereum": "https://etherscan.io",
+	"polygon":  "https://polygonscan.com",
+	"arbitrum": "https://arbiscan.io",
+	"base":     "https://basescan.org",
+	"solana":   "https://solscan.io",
+}
+
+// ParseExplorers parses "chain=url,..." explorer overrides.
+func ParseExplorers(spec string) (Explorers, error) {
+	explorers := Explorers{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		chain, base, ok := strings.Cut(item, "=")
+		u, err := url.Parse(base)
+		if !ok || chain == "" || err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid explorer %q: expected chain=https://explorer", item)
+		}
+		explorers[strings.ToLower(strings.TrimSpace(chain))] = strings.TrimRight(base, "/")
+	}
+	return explorers, nil
+}
+
+// Merge returns the default explorers overridden by e.
+func (e Explorers) Merge() Explorers {
+	merged := Explorers{}
+	for chain, base := range DefaultExplorers {
+		merged[chain] = base
+	}
+	for chain, base := range e {
+		merged[chain] = base
+	}
+	return merged
+}
+
+// AddressLink returns an explorer link for an account or contract address,
+// or nil when the chain has no explorer.
+func (e Explorers) AddressLink(chain, label, address string) []Link {
+	base := e[chain]
+	if base == "" || address == "" {
+		return nil
+	}
+	path := "/address/"
+	if chain == "solana" {
+		path = "/account/"
+	}
+	return []Link{{Label: label, URL: base + path + address}}
+}
+
+// TokenLinks returns explorer links for a token contract and token id.
+func (e Explorers) TokenLinks(chain, contract, tokenID string) []Link {
+	base := e[chain]
+	if base == "" {
+		return nil
+	}
+	if chain == "solana" {
+		// Solana NFTs are their own mint accounts.
+		return []Link{{Label: "token", URL: base + "/token/" + tokenID}}
+	}
+	return append(e.AddressLink(chain, "contract", contract),
+		Link{Label: "token", URL: base + "/nft/" + contract + "/" + tokenID})
+}
@@ -0,0 +1,32 @@
+package blockchain
+
+import "testing"
+
+func TestParseExplorers(t *testing.T) {
+	overrides, err := ParseExplorers("ethereum=https://eth.example/, optimism=https://optimistic.etherscan.io")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	explorers := overrides.Merge()
+	if explorers["ethereum"] != "https://eth.example" || explorers["polygon"] != DefaultExplorers["polygon"] {
+		t.Errorf("unexpected merge result: %v", explorers)
+	}
+
+	links := explorers.TokenLinks("optimism", "0xabc", "42")
+	if len(links) != 2 || links[0].URL != "https://optimistic.etherscan.io/address/0xabc" ||
+		links[1].URL != "https://optimistic.etherscan.io/nft/0xabc/42" {
+		t.Errorf("unexpected token links: %+v", links)
+	}
+	if links := explorers.AddressLink("solana", "owner", "So1"); len(links) != 1 || links[0].URL != "https://solscan.io/account/So1" {
+		t.Errorf("unexpected solana link: %+v", links)
+	}
+	if links := explorers.AddressLink("unknown", "owner", "0xabc"); links != nil {
+		t.Errorf("expected no link for a chain without an explorer, got %+v", links)
+	}
+
+	for _, bad := range []string{"ethereum", "=https://x", "ethereum=etherscan.io"} {
+		if _, err := ParseExplorers(bad); err == nil {
+			t.Errorf("ParseExplorers(%q): expected an error", bad)
+		}
+	}
+}
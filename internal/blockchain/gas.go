@@ -0,0 +1,182 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChainlinkETHUSD is the Chainlink ETH/USD price feed on Ethereum mainnet,
+// the oracle the ENS registrar controller prices renewals with.
+const ChainlinkETHUSD = "0x5f4eC3Df9cbd43714FE2740f5E3616155c5b8419"
+
+// SlotTime is the Ethereum block interval since the merge. Missed slots
+// make block times estimated from it slightly early.
+const SlotTime = 12 * time.Second
+
+// maxFeeHistory is the most blocks eth_feeHistory returns per call on
+// common clients and providers.
+const maxFeeHistory = 1024
+
+// GasSample is the base fee of one block.
+type GasSample struct {
+	Block   int64     `json:"block"`
+	Time    time.Time `json:"time"`
+	BaseFee float64   `json:"base_fee_gwei"`
+}
+
+// BlockNumber returns the number of the latest block.
+func (r *RPCClient) BlockNumber() (int64, error) {
+	var n string
+	if err := r.call(context.Background(), "eth_blockNumber", []interface{}{}, &n); err != nil {
+		return 0, err
+	}
+	return parseQuantity(n)
+}
+
+// BlockTime returns the timestamp of block number.
+func (r *RPCClient) BlockTime(number int64) (time.Time, error) {
+	var block struct {
+		Timestamp string `json:"timestamp"`
+	}
+	if err := r.call(context.Background(), "eth_getBlockByNumber", []interface{}{fmt.Sprintf("0x%x", number), false}, &block); err != nil {
+		return time.Time{}, err
+	}
+	ts, err := parseQuantity(block.Timestamp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(ts, 0).UTC(), nil
+}
+
+// MaxPriorityFee returns the node's suggested priority fee in gwei.
+func (r *RPCClient) MaxPriorityFee() (float64, error) {
+	var fee string
+	if err := r.call(context.Background(), "eth_maxPriorityFeePerGas", []interface{}{}, &fee); err != nil {
+		return 0, err
+	}
+	return weiToGwei(fee)
+}
+
+// BaseFees returns the base fee of every block over the last period,
+// oldest first. Block times are estimated from the latest block's
+// timestamp and SlotTime.
+func (r *RPCClient) BaseFees(period time.Duration) ([]GasSample, error) {
+	latest, err := r.BlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	latestTime, err := r.BlockTime(latest)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := int64(period / SlotTime)
+	if blocks < 1 {
+		blocks = 1
+	}
+	oldest := latest - blocks + 1
+	if oldest < 0 {
+		oldest = 0
+	}
+
+	samples := make([]GasSample, 0, latest-oldest+1)
+	for start := oldest; start <= latest; start += maxFeeHistory {
+		count := latest - start + 1
+		if count > maxFeeHistory {
+			count = maxFeeHistory
+		}
+		newest := start + count - 1
+		var reply struct {
+			OldestBlock   string   `json:"oldestBlock"`
+			BaseFeePerGas []string `json:"baseFeePerGas"`
+		}
+		params := []interface{}{fmt.Sprintf("0x%x", count), fmt.Sprintf("0x%x", newest), []int{}}
+		if err := r.call(context.Background(), "eth_feeHistory", params, &reply); err != nil {
+			return nil, err
+		}
+		first, err := parseQuantity(reply.OldestBlock)
+		if err != nil {
+			return nil, err
+		}
+		// baseFeePerGas has one extra entry: the next block's base fee.
+		for i, fee := range reply.BaseFeePerGas {
+			block := first + int64(i)
+			if block > newest {
+				break
+			}
+			gwei, err := weiToGwei(fee)
+			if err != nil {
+				return nil, err
+			}
+			samples = append(samples, GasSample{
+				Block:   block,
+				Time:    latestTime.Add(-time.Duration(latest-block) * SlotTime),
+				BaseFee: gwei,
+			})
+		}
+	}
+	return samples, nil
+}
+
+// ETHPrice returns the ETH/USD price from the Chainlink feed.
+func (r *RPCClient) ETHPrice() (float64, error) {
+	ret, err := r.Call(ChainlinkETHUSD, selector("latestRoundData()"))
+	if err != nil {
+		return 0, err
+	}
+	if len(ret) < 64 {
+		return 0, fmt.Errorf("ETH/USD feed returned %d bytes", len(ret))
+	}
+	answer, _ := new(big.Float).SetInt(new(big.Int).SetBytes(ret[32:64])).Float64()
+	// The feed answers with 8 decimals.
+	return answer / 1e8, nil
+}
+
+// ENSExpiry returns when the .eth name's registration expires, or nil
+// when it is not registered.
+func (r *RPCClient) ENSExpiry(name string) (*time.Time, error) {
+	return r.ENSExpiryContext(context.Background(), name)
+}
+
+// ENSExpiryContext is ENSExpiry bounded by ctx.
+func (r *RPCClient) ENSExpiryContext(ctx context.Context, name string) (*time.Time, error) {
+	labels := strings.Split(name, ".")
+	if len(labels) != 2 || labels[1] != "eth" {
+		return nil, fmt.Errorf("%s is not a second-level .eth name", name)
+	}
+	id := LabelHash(labels[0])
+	ret, err := r.cachedCall(ctx, ENSBaseRegistrar, append(selector("nameExpires(uint256)"), id...), id)
+	if err != nil {
+		return nil, err
+	}
+	if len(ret) != 32 {
+		return nil, fmt.Errorf("nameExpires returned %d bytes", len(ret))
+	}
+	expires := new(big.Int).SetBytes(ret)
+	if expires.Sign() == 0 || !expires.IsInt64() {
+		return nil, nil
+	}
+	expiry := time.Unix(expires.Int64(), 0).UTC()
+	return &expiry, nil
+}
+
+func parseQuantity(s string) (int64, error) {
+	n, err := strconv.ParseInt(strings.TrimPrefix(s, "0x"), 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity %q: %v", s, err)
+	}
+	return n, nil
+}
+
+func weiToGwei(s string) (float64, error) {
+	wei, ok := new(big.Int).SetString(strings.TrimPrefix(s, "0x"), 16)
+	if !ok {
+		return 0, fmt.Errorf("invalid quantity %q", s)
+	}
+	gwei, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e9)).Float64()
+	return gwei, nil
+}
@@ -0,0 +1,77 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBaseFees(t *testing.T) {
+	const latest = 2000
+	latestTime := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		reply := map[string]interface{}{"jsonrpc": "2.0", "id": 1}
+		switch req.Method {
+		case "eth_blockNumber":
+			reply["result"] = fmt.Sprintf("0x%x", latest)
+		case "eth_getBlockByNumber":
+			reply["result"] = map[string]string{"timestamp": fmt.Sprintf("0x%x", latestTime.Unix())}
+		case "eth_feeHistory":
+			calls++
+			count, _ := strconv.ParseInt(strings.TrimPrefix(req.Params[0].(string), "0x"), 16, 64)
+			newest, _ := strconv.ParseInt(strings.TrimPrefix(req.Params[1].(string), "0x"), 16, 64)
+			oldest := newest - count + 1
+			// Each block's base fee is its number in gwei, plus the next block's.
+			var fees []string
+			for b := oldest; b <= newest+1; b++ {
+				fees = append(fees, fmt.Sprintf("0x%x", b*1e9))
+			}
+			reply["result"] = map[string]interface{}{"oldestBlock": fmt.Sprintf("0x%x", oldest), "baseFeePerGas": fees}
+		}
+		json.NewEncoder(w).Encode(reply)
+	}))
+	defer server.Close()
+
+	samples, err := NewRPCClient(server.URL).BaseFees(1500 * SlotTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 1500 || calls != 2 {
+		t.Fatalf("got %d samples in %d calls, want 1500 in 2", len(samples), calls)
+	}
+	first, last := samples[0], samples[len(samples)-1]
+	if first.Block != 501 || first.BaseFee != 501 || last.Block != latest || last.BaseFee != latest {
+		t.Errorf("first %+v, last %+v", first, last)
+	}
+	if !last.Time.Equal(latestTime) || !first.Time.Equal(latestTime.Add(-1499*SlotTime)) {
+		t.Errorf("times %v .. %v", first.Time, last.Time)
+	}
+}
+
+func TestETHPrice(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// latestRoundData: roundId, answer ($2,512.34 with 8 decimals), ...
+		ret := fmt.Sprintf("0x%064x%064x%064x%064x%064x", 1, int64(251234000000), 0, 0, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": ret})
+	}))
+	defer server.Close()
+
+	price, err := NewRPCClient(server.URL).ETHPrice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price != 2512.34 {
+		t.Errorf("price = %v, want 2512.34", price)
+	}
+}
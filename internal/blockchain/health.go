@@ -0,0 +1,84 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MaxBlockLag is how many blocks an endpoint may trail the most advanced
+// endpoint before it is considered unhealthy.
+const MaxBlockLag = 5
+
+// EndpointHealth is the result of health-checking one RPC endpoint.
+type EndpointHealth struct {
+	Endpoint  string `json:"endpoint"`
+	Healthy   bool   `json:"healthy"`
+	Block     int64  `json:"block,omitempty"`
+	Lag       int64  `json:"lag_blocks,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+	// Served and Failures count calls since the client was created.
+	Served   int    `json:"served"`
+	Failures int    `json:"failures"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Health asks every endpoint for its latest block. Endpoints that fail or
+// lag behind the others by more than MaxBlockLag blocks are rested, so
+// following calls prefer the healthy ones.
+func (r *RPCClient) Health() []EndpointHealth {
+	body, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "eth_blockNumber",
+		"params":  []interface{}{},
+	})
+
+	report := make([]EndpointHealth, len(r.urls))
+	var highest int64
+	for i, endpoint := range r.urls {
+		h := EndpointHealth{Endpoint: EndpointLabel(endpoint)}
+		started := r.now()
+		var n string
+		err := r.callEndpoint(context.Background(), endpoint, "eth_blockNumber", body, &n)
+		h.LatencyMS = r.now().Sub(started).Milliseconds()
+		if err == nil {
+			h.Block, err = parseQuantity(n)
+		}
+		if err != nil {
+			h.Error = err.Error()
+		} else {
+			h.Healthy = true
+			if h.Block > highest {
+				highest = h.Block
+			}
+		}
+		report[i] = h
+	}
+
+	for i, endpoint := range r.urls {
+		h := &report[i]
+		if h.Healthy {
+			if h.Lag = highest - h.Block; h.Lag > MaxBlockLag {
+				h.Healthy = false
+				h.Error = fmt.Sprintf("%d blocks behind", h.Lag)
+			}
+		}
+		if h.Healthy {
+			r.markHealthy(endpoint)
+		} else {
+			r.markFailed(endpoint, &rpcError{err: fmt.Errorf("%s", h.Error)})
+		}
+		r.mu.Lock()
+		h.Served, h.Failures = r.states[endpoint].served, r.states[endpoint].failures
+		r.mu.Unlock()
+	}
+	return report
+}
+
+func (r *RPCClient) markHealthy(endpoint string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.states[endpoint].restUntil = time.Time{}
+}
@@ -0,0 +1,139 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ensHoldingsQuery lists the names an account controls in the registry,
+// holds as registrant of a .eth registration or holds wrapped.
+const ensHoldingsQuery = `query($id: String!) {
+  account(id: $id) {
+    domains(first: 1000) { name }
+    registrations(first: 1000) { domain { name } }
+    wrappedDomains(first: 1000) { domain { name } }
+  }
+}`
+
+// NamesOwnedBy returns the ENS names held by address, sorted. Names the
+// subgraph could not decode (shown as [labelhash].eth) are skipped.
+func (s *SubgraphClient) NamesOwnedBy(address string) ([]string, error) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"query":     ensHoldingsQuery,
+		"variables": map[string]string{"id": strings.ToLower(address)},
+	})
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("ENS subgraph query failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ENS subgraph returned HTTP %d", resp.StatusCode)
+	}
+
+	type named struct {
+		Name   string `json:"name"`
+		Domain *struct {
+			Name string `json:"name"`
+		} `json:"domain"`
+	}
+	var reply struct {
+		Data struct {
+			Account *struct {
+				Domains        []named `json:"domains"`
+				Registrations  []named `json:"registrations"`
+				WrappedDomains []named `json:"wrappedDomains"`
+			} `json:"account"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return nil, fmt.Errorf("invalid ENS subgraph response: %v", err)
+	}
+	if len(reply.Errors) > 0 {
+		return nil, fmt.Errorf("ENS subgraph: %s", reply.Errors[0].Message)
+	}
+	account := reply.Data.Account
+	if account == nil {
+		return nil, nil
+	}
+
+	seen := map[string]bool{}
+	var names []string
+	for _, list := range [][]named{account.Domains, account.Registrations, account.WrappedDomains} {
+		for _, n := range list {
+			name := n.Name
+			if n.Domain != nil {
+				name = n.Domain.Name
+			}
+			if name == "" || strings.Contains(name, "[") || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// UDNamesOwnedBy returns the Unstoppable Domains names held by address,
+// following the Resolution API's pagination.
+func (c *Checker) UDNamesOwnedBy(address string) ([]string, error) {
+	if c.udKey == "" {
+		return nil, fmt.Errorf("Unstoppable Domains API key not configured (-ud-api-key)")
+	}
+
+	var names []string
+	after := ""
+	for {
+		u := c.udAPI + "/owners/" + url.PathEscape(strings.ToLower(address)) + "/domains"
+		if after != "" {
+			u += "?startingAfter=" + url.QueryEscape(after)
+		}
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.udKey)
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("Unstoppable Domains lookup failed: %v", err)
+		}
+
+		var page struct {
+			Data []struct {
+				ID string `json:"id"`
+			} `json:"data"`
+			Meta struct {
+				HasMore           bool   `json:"hasMore"`
+				NextStartingAfter string `json:"nextStartingAfter"`
+			} `json:"meta"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("Unstoppable Domains lookup returned HTTP %d", resp.StatusCode)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid Unstoppable Domains response: %v", err)
+		}
+		for _, d := range page.Data {
+			names = append(names, strings.ToLower(d.ID))
+		}
+		if !page.Meta.HasMore || page.Meta.NextStartingAfter == "" || page.Meta.NextStartingAfter == after {
+			break
+		}
+		after = page.Meta.NextStartingAfter
+	}
+	sort.Strings(names)
+	return names, nil
+}
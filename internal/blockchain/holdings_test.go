@@ -0,0 +1,70 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestNamesOwnedBy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables map[string]string `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Variables["id"] != "0xabc" {
+			w.Write([]byte(`{"data": {"account": null}}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"account": {
+			"domains": [{"name": "vault.eth"}, {"name": "pay.vault.eth"}, {"name": "[1234].eth"}],
+			"registrations": [{"domain": {"name": "vault.eth"}}, {"domain": {"name": "alpha.eth"}}],
+			"wrappedDomains": [{"domain": {"name": "wrapped.eth"}}]
+		}}}`))
+	}))
+	defer server.Close()
+
+	s := NewSubgraphClient(server.URL)
+	names, err := s.NamesOwnedBy("0xABC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"alpha.eth", "pay.vault.eth", "vault.eth", "wrapped.eth"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+	if names, err := s.NamesOwnedBy("0xdef"); err != nil || len(names) != 0 {
+		t.Errorf("unknown account: %v, %v", names, err)
+	}
+}
+
+func TestUDNamesOwnedBy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" || r.URL.Path != "/owners/0xabc/domains" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("startingAfter") == "" {
+			w.Write([]byte(`{"data": [{"id": "vault.crypto"}], "meta": {"hasMore": true, "nextStartingAfter": "1"}}`))
+			return
+		}
+		w.Write([]byte(`{"data": [{"id": "Alpha.NFT"}], "meta": {"hasMore": false}}`))
+	}))
+	defer server.Close()
+
+	c := NewChecker()
+	if _, err := c.UDNamesOwnedBy("0xabc"); err == nil {
+		t.Error("expected an error without an API key")
+	}
+	c.udAPI = server.URL
+	c.SetUDKey("key")
+	names, err := c.UDNamesOwnedBy("0xABC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"alpha.nft", "vault.crypto"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+}
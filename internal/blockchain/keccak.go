@@ -0,0 +1,119 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"math/bits"
+	"strings"
+)
+
+// Keccak-256 as used by Ethereum (the original Keccak padding, not
+// NIST SHA3-256). Implemented here to avoid a crypto dependency.
+
+var keccakRoundConstants = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808A, 0x8000000080008000,
+	0x000000000000808B, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008A, 0x0000000000000088, 0x0000000080008009, 0x000000008000000A,
+	0x000000008000808B, 0x800000000000008B, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800A, 0x800000008000000A,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+var keccakRotations = [25]int{
+	0, 1, 62, 28, 27,
+	36, 44, 6, 55, 20,
+	3, 10, 43, 25, 39,
+	41, 45, 15, 21, 8,
+	18, 2, 61, 56, 14,
+}
+
+func keccakF(a *[25]uint64) {
+	var b [25]uint64
+	var c, d [5]uint64
+	for round := 0; round < 24; round++ {
+		// θ
+		for x := 0; x < 5; x++ {
+			c[x] = a[x] ^ a[x+5] ^ a[x+10] ^ a[x+15] ^ a[x+20]
+		}
+		for x := 0; x < 5; x++ {
+			d[x] = c[(x+4)%5] ^ bits.RotateLeft64(c[(x+1)%5], 1)
+		}
+		for i := 0; i < 25; i++ {
+			a[i] ^= d[i%5]
+		}
+		// ρ and π
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				b[y+5*((2*x+3*y)%5)] = bits.RotateLeft64(a[x+5*y], keccakRotations[x+5*y])
+			}
+		}
+		// χ
+		for y := 0; y < 5; y++ {
+			for x := 0; x < 5; x++ {
+				a[x+5*y] = b[x+5*y] ^ (^b[(x+1)%5+5*y] & b[(x+2)%5+5*y])
+			}
+		}
+		// ι
+		a[0] ^= keccakRoundConstants[round]
+	}
+}
+
+// Keccak256 hashes the concatenation of data.
+func Keccak256(data ...[]byte) []byte {
+	const rate = 136
+	var state [25]uint64
+	var msg []byte
+	for _, d := range data {
+		msg = append(msg, d...)
+	}
+
+	// Pad with 0x01 ... 0x80 to a multiple of the rate.
+	padded := make([]byte, len(msg)+rate-len(msg)%rate)
+	copy(padded, msg)
+	padded[len(msg)] ^= 0x01
+	padded[len(padded)-1] ^= 0x80
+
+	for off := 0; off < len(padded); off += rate {
+		for i := 0; i < rate/8; i++ {
+			state[i] ^= binary.LittleEndian.Uint64(padded[off+8*i:])
+		}
+		keccakF(&state)
+	}
+
+	out := make([]byte, 32)
+	for i := 0; i < 4; i++ {
+		binary.LittleEndian.PutUint64(out[8*i:], state[i])
+	}
+	return out
+}
+
+// LabelHash is keccak256 of a single name label.
+func LabelHash(label string) []byte {
+	return Keccak256([]byte(label))
+}
+
+// NameHash implements the EIP-137 namehash used by ENS and Unstoppable
+// Domains to identify names on chain.
+func NameHash(name string) []byte {
+	node := make([]byte, 32)
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = Keccak256(node, LabelHash(labels[i]))
+	}
+	return node
+}
+
+// TokenID renders a 32-byte hash as the decimal uint256 used as an NFT id.
+func TokenID(hash []byte) string {
+	return new(big.Int).SetBytes(hash).String()
+}
+
+// Hex renders bytes as 0x-prefixed hex.
+func Hex(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
@@ -0,0 +1,28 @@
+package blockchain
+
+import "testing"
+
+func TestKeccak256(t *testing.T) {
+	tests := map[string]string{
+		"":    "0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		"abc": "0x4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45",
+	}
+	for in, want := range tests {
+		if got := Hex(Keccak256([]byte(in))); got != want {
+			t.Errorf("Keccak256(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestNameHash(t *testing.T) {
+	tests := map[string]string{
+		"":        "0x0000000000000000000000000000000000000000000000000000000000000000",
+		"eth":     "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae",
+		"foo.eth": "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f",
+	}
+	for in, want := range tests {
+		if got := Hex(NameHash(in)); got != want {
+			t.Errorf("NameHash(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
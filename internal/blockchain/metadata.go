@@ -0,0 +1,190 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Token metadata sources and contracts.
+const (
+	DefaultENSMetadataAPI = "https://metadata.ens.domains/mainnet"
+	DefaultUDMetadataAPI  = "https://metadata.unstoppabledomains.com/metadata"
+
+	// ENSRegistry maps namehashes to owners and resolvers.
+	ENSRegistry = "0x00000000000c2e074ec69a0dfb2997ba6c7d2e1e"
+	// ENSBaseRegistrar holds unwrapped second-level .eth names as ERC-721
+	// tokens, id = labelhash.
+	ENSBaseRegistrar = "0x57f1887a8bf19b14fc0df6fd9b2acc9af147ea85"
+	// ENSNameWrapper holds wrapped names and subnames as ERC-1155 tokens,
+	// id = namehash.
+	ENSNameWrapper = "0xd4416b13d2b3a9abae7acd5d6c2bbdbe25686401"
+	// UNSRegistry is the Unstoppable Domains ERC-721 registry on Ethereum,
+	// id = namehash.
+	UNSRegistry = "0x049aba7510f45ba5b64ea9e658e342f904db358d"
+)
+
+// ipfsGateway resolves ipfs:// image URIs.
+const ipfsGateway = "https://ipfs.io/ipfs/"
+
+// Token describes the NFT that represents a blockchain domain.
+type Token struct {
+	Standard    string            `json:"standard"`
+	Chain       string            `json:"chain"`
+	Contract    string            `json:"contract"`
+	TokenID     string            `json:"token_id"`
+	MetadataURI string            `json:"metadata_uri"`
+	Name        string            `json:"name,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Image       string            `json:"image,omitempty"`
+	ImageOK     bool              `json:"image_ok"`
+	ImageError  string            `json:"image_error,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	Links       []Link            `json:"links,omitempty"`
+}
+
+// tokenCandidates returns the contracts that may hold domain, most likely
+// first.
+func (c *Checker) tokenCandidates(domain string) []Token {
+	labels := strings.Split(domain, ".")
+	if strings.HasSuffix(domain, ".eth") {
+		wrapped := Token{
+			Standard: "ERC-1155",
+			Chain:    "ethereum",
+			Contract: ENSNameWrapper,
+			TokenID:  TokenID(NameHash(domain)),
+		}
+		wrapped.MetadataURI = c.ensMetadataAPI + "/" + wrapped.Contract + "/" + wrapped.TokenID
+		if len(labels) != 2 {
+			return []Token{wrapped}
+		}
+		unwrapped := Token{
+			Standard: "ERC-721",
+			Chain:    "ethereum",
+			Contract: ENSBaseRegistrar,
+			TokenID:  TokenID(LabelHash(labels[0])),
+		}
+		unwrapped.MetadataURI = c.ensMetadataAPI + "/" + unwrapped.Contract + "/" + unwrapped.TokenID
+		return []Token{unwrapped, wrapped}
+	}
+
+	return []Token{{
+		Standard:    "ERC-721",
+		Chain:       "ethereum",
+		Contract:    UNSRegistry,
+		TokenID:     TokenID(NameHash(domain)),
+		MetadataURI: c.udMetadataAPI + "/" + domain,
+	}}
+}
+
+// fetchToken loads the token metadata for domain. It returns nil when no
+// token exists, and an error when the metadata service could not answer.
+func (c *Checker) fetchToken(ctx context.Context, domain string) (*Token, error) {
+	for _, token := range c.tokenCandidates(domain) {
+		found, err := c.loadMetadata(ctx, &token)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		if token.Image != "" {
+			if err := c.checkImage(ctx, token.Image); err != nil {
+				token.ImageError = err.Error()
+			} else {
+				token.ImageOK = true
+			}
+		}
+		token.Links = c.explorers.TokenLinks(token.Chain, token.Contract, token.TokenID)
+		return &token, nil
+	}
+	return nil, nil
+}
+
+func (c *Checker) loadMetadata(ctx context.Context, token *Token) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, token.MetadataURI, nil)
+	if err != nil {
+		return false, fmt.Errorf("invalid metadata URI: %v", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("token metadata lookup failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("token metadata lookup returned HTTP %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Image       string `json:"image"`
+		ImageURL    string `json:"image_url"`
+		Attributes  []struct {
+			TraitType string      `json:"trait_type"`
+			Value     interface{} `json:"value"`
+		} `json:"attributes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return false, fmt.Errorf("failed to parse token metadata: %v", err)
+	}
+	// Unknown names get a placeholder document without a name.
+	if body.Name == "" {
+		return false, nil
+	}
+
+	token.Name = body.Name
+	token.Description = body.Description
+	token.Image = body.Image
+	if token.Image == "" {
+		token.Image = body.ImageURL
+	}
+	for _, attr := range body.Attributes {
+		if attr.TraitType == "" || attr.Value == nil {
+			continue
+		}
+		if token.Attributes == nil {
+			token.Attributes = make(map[string]string)
+		}
+		token.Attributes[attr.TraitType] = fmt.Sprint(attr.Value)
+	}
+	return true, nil
+}
+
+// checkImage verifies that an image URI resolves to an image.
+func (c *Checker) checkImage(ctx context.Context, uri string) error {
+	if strings.HasPrefix(uri, "data:") {
+		if strings.HasPrefix(uri, "data:image/") {
+			return nil
+		}
+		return fmt.Errorf("data URI is not an image")
+	}
+	if cid, ok := strings.CutPrefix(uri, "ipfs://"); ok {
+		uri = ipfsGateway + strings.TrimPrefix(cid, "ipfs/")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return fmt.Errorf("invalid image URI: %v", err)
+	}
+	req.Header.Set("Range", "bytes=0-0")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("image does not resolve: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("image returned HTTP %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(ct, "image/") {
+		return fmt.Errorf("image has content type %s", ct)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package blockchain
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchToken_ENS(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/image.svg":
+			w.Header().Set("Content-Type", "image/svg+xml")
+			w.Write([]byte("<svg/>"))
+		case r.URL.Path == "/broken.svg":
+			http.NotFound(w, r)
+		case strings.HasPrefix(r.URL.Path, "/"+ENSBaseRegistrar+"/"+TokenID(LabelHash("vitalik"))):
+			w.Write([]byte(`{"name": "vitalik.eth", "image": "` + server.URL + `/image.svg",
+				"attributes": [{"trait_type": "Character Set", "display_type": "string", "value": "letter"}]}`))
+		case strings.HasPrefix(r.URL.Path, "/"+ENSNameWrapper+"/"):
+			w.Write([]byte(`{"name": "sub.wrapped.eth", "image": "` + server.URL + `/broken.svg"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := NewChecker()
+	c.ensMetadataAPI = server.URL
+
+	token, err := c.fetchToken(context.Background(), "vitalik.eth")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token == nil || token.Standard != "ERC-721" || token.Contract != ENSBaseRegistrar || !token.ImageOK {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+	if token.Attributes["Character Set"] != "letter" {
+		t.Errorf("expected attributes, got %v", token.Attributes)
+	}
+	if len(token.Links) != 2 || !strings.HasPrefix(token.Links[1].URL, "https://etherscan.io/nft/"+ENSBaseRegistrar+"/") {
+		t.Errorf("unexpected explorer links: %+v", token.Links)
+	}
+
+	token, err = c.fetchToken(context.Background(), "sub.wrapped.eth")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token == nil || token.Standard != "ERC-1155" || token.ImageOK || token.ImageError == "" {
+		t.Errorf("expected a wrapped token with a broken image, got %+v", token)
+	}
+}
+
+func TestFetchToken_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	c := NewChecker()
+	c.udMetadataAPI = server.URL
+
+	token, err := c.fetchToken(context.Background(), "unminted.crypto")
+	if err != nil || token != nil {
+		t.Errorf("expected no token and no error, got %+v, %v", token, err)
+	}
+}
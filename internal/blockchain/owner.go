@@ -0,0 +1,146 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Owner kinds.
+const (
+	OwnerEOA         = "eoa"
+	OwnerSafe        = "safe"
+	OwnerRegistrar   = "registrar"
+	OwnerMarketplace = "marketplace"
+	OwnerContract    = "contract"
+)
+
+// knownContracts maps lowercase addresses of registrar and marketplace
+// contracts that commonly hold domain tokens to their kind and name.
+var knownContracts = map[string][2]string{
+	ENSRegistry:      {OwnerRegistrar, "ENS Registry"},
+	ENSBaseRegistrar: {OwnerRegistrar, "ENS Base Registrar"},
+	ENSNameWrapper:   {OwnerRegistrar, "ENS Name Wrapper"},
+	UNSRegistry:      {OwnerRegistrar, "UNS Registry"},
+	"0x00000000000000adc04c56bf30ac9d3c0aaf14dc": {OwnerMarketplace, "OpenSea Seaport 1.5"},
+	"0x0000000000000068f116a894984e2db1123eb395": {OwnerMarketplace, "OpenSea Seaport 1.6"},
+}
+
+// OwnerInfo describes what kind of account owns a domain token.
+type OwnerInfo struct {
+	Address   string   `json:"address"`
+	Kind      string   `json:"kind"`
+	Name      string   `json:"name,omitempty"`
+	Threshold int      `json:"threshold,omitempty"`
+	Signers   []string `json:"signers,omitempty"`
+	Note      string   `json:"note,omitempty"`
+	// RPCEndpoint is the endpoint that answered, without credentials.
+	RPCEndpoint string    `json:"rpc_endpoint,omitempty"`
+	CheckedAt   time.Time `json:"checked_at"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// OwnerDetector classifies owner addresses as externally owned accounts,
+// Safe multi-sigs, registrar or marketplace contracts, or other contracts.
+type OwnerDetector struct {
+	rpc *RPCClient
+}
+
+// NewOwnerDetector returns a detector using the Ethereum JSON-RPC endpoint
+// at rpcURL.
+func NewOwnerDetector(rpcURL string) *OwnerDetector {
+	return &OwnerDetector{rpc: NewRPCClient(rpcURL)}
+}
+
+// SetCache answers repeated code lookups from c.
+func (d *OwnerDetector) SetCache(c *Cache) {
+	d.rpc.SetCache(c)
+}
+
+// Endpoint returns the JSON-RPC URL.
+func (d *OwnerDetector) Endpoint() string {
+	return d.rpc.Endpoint()
+}
+
+// Detect classifies address. RPC failures are reported in the result's
+// Error field.
+func (d *OwnerDetector) Detect(address string) (*OwnerInfo, error) {
+	info := &OwnerInfo{
+		Address:   address,
+		CheckedAt: time.Now(),
+	}
+
+	if known, ok := knownContracts[strings.ToLower(address)]; ok {
+		info.Kind, info.Name = known[0], known[1]
+		info.Note = ownerNote(info)
+		return info, nil
+	}
+
+	code, err := d.rpc.GetCode(address)
+	if err != nil {
+		info.Error = err.Error()
+		return info, nil
+	}
+	info.RPCEndpoint = d.rpc.LastEndpoint()
+	if len(code) == 0 {
+		info.Kind = OwnerEOA
+		info.Note = ownerNote(info)
+		return info, nil
+	}
+
+	info.Kind = OwnerContract
+	// A Safe answers getThreshold() and getOwners(); other contracts revert.
+	if ret, err := d.rpc.Call(address, selector("getThreshold()")); err == nil && len(ret) == 32 {
+		if threshold := new(big.Int).SetBytes(ret); threshold.Sign() > 0 && threshold.IsInt64() {
+			info.Kind = OwnerSafe
+			info.Name = "Safe multi-sig"
+			info.Threshold = int(threshold.Int64())
+			if ret, err := d.rpc.Call(address, selector("getOwners()")); err == nil {
+				info.Signers = decodeAddresses(ret)
+			}
+		}
+	}
+	info.Note = ownerNote(info)
+	return info, nil
+}
+
+func ownerNote(info *OwnerInfo) string {
+	switch info.Kind {
+	case OwnerEOA:
+		return "Held by a single private key; the holder can transfer it directly"
+	case OwnerSafe:
+		if len(info.Signers) > 0 {
+			return fmt.Sprintf("Multi-sig: a transfer needs %d of %d signers to approve", info.Threshold, len(info.Signers))
+		}
+		return fmt.Sprintf("Multi-sig: a transfer needs %d signers to approve", info.Threshold)
+	case OwnerRegistrar:
+		return "Held by the registrar contract rather than a user (wrapped, expired or unclaimed)"
+	case OwnerMarketplace:
+		return "Held in marketplace escrow; likely listed, buy through the marketplace"
+	default:
+		return "Held by a contract (DAO, vault or custom escrow); control depends on its logic"
+	}
+}
+
+// decodeAddresses decodes an ABI-encoded address[] return value.
+func decodeAddresses(ret []byte) []string {
+	if len(ret) < 64 {
+		return nil
+	}
+	offset := new(big.Int).SetBytes(ret[:32])
+	if !offset.IsInt64() || offset.Int64()+32 > int64(len(ret)) {
+		return nil
+	}
+	start := int(offset.Int64())
+	count := new(big.Int).SetBytes(ret[start : start+32])
+	if !count.IsInt64() || start+32+int(count.Int64())*32 > len(ret) {
+		return nil
+	}
+	addresses := make([]string, 0, count.Int64())
+	for i := 0; i < int(count.Int64()); i++ {
+		word := ret[start+32+i*32 : start+64+i*32]
+		addresses = append(addresses, fmt.Sprintf("0x%x", word[12:]))
+	}
+	return addresses
+}
@@ -0,0 +1,95 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeNode answers eth_getCode and eth_call for a Safe at safeAddr, a
+// plain contract at contractAddr, and treats everything else as an EOA.
+func fakeNode(t *testing.T, safeAddr, contractAddr string) *httptest.Server {
+	threshold := "0x" + hex.EncodeToString(selector("getThreshold()"))
+	owners := "0x" + hex.EncodeToString(selector("getOwners()"))
+	word := func(n int) string { return fmt.Sprintf("%064x", n) }
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		reply := map[string]interface{}{"jsonrpc": "2.0", "id": 1}
+		switch req.Method {
+		case "eth_getCode":
+			var addr string
+			json.Unmarshal(req.Params[0], &addr)
+			reply["result"] = "0x"
+			if addr == safeAddr || addr == contractAddr {
+				reply["result"] = "0x6080"
+			}
+		case "eth_call":
+			var msg struct{ To, Data string }
+			json.Unmarshal(req.Params[0], &msg)
+			switch {
+			case msg.To == safeAddr && msg.Data == threshold:
+				reply["result"] = "0x" + word(2)
+			case msg.To == safeAddr && msg.Data == owners:
+				reply["result"] = "0x" + word(32) + word(3) + word(0xa1) + word(0xa2) + word(0xa3)
+			default:
+				reply["error"] = map[string]interface{}{"code": 3, "message": "execution reverted"}
+			}
+		}
+		json.NewEncoder(w).Encode(reply)
+	}))
+}
+
+func TestOwnerDetector(t *testing.T) {
+	safe := "0x" + strings.Repeat("5", 40)
+	contract := "0x" + strings.Repeat("c", 40)
+	server := fakeNode(t, safe, contract)
+	defer server.Close()
+
+	d := NewOwnerDetector(server.URL)
+	tests := []struct {
+		address string
+		kind    string
+	}{
+		{"0x" + strings.Repeat("1", 40), OwnerEOA},
+		{safe, OwnerSafe},
+		{contract, OwnerContract},
+		{"0x57F1887a8BF19b14fC0dF6Fd9B2acc9Af147eA85", OwnerRegistrar},
+	}
+	for _, tt := range tests {
+		info, err := d.Detect(tt.address)
+		if err != nil || info.Error != "" {
+			t.Fatalf("Detect(%s): %v %s", tt.address, err, info.Error)
+		}
+		if info.Kind != tt.kind {
+			t.Errorf("Detect(%s) = %s, want %s", tt.address, info.Kind, tt.kind)
+		}
+	}
+
+	info, _ := d.Detect(safe)
+	if info.Threshold != 2 || len(info.Signers) != 3 || info.Signers[0] != "0x"+strings.Repeat("0", 38)+"a1" {
+		t.Errorf("unexpected Safe details: %+v", info)
+	}
+}
+
+func TestOwnerDetector_RPCError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	info, err := NewOwnerDetector(server.URL).Detect("0x" + strings.Repeat("1", 40))
+	if err != nil || info.Error == "" || info.Kind != "" {
+		t.Errorf("expected a soft RPC error, got %+v, %v", info, err)
+	}
+}
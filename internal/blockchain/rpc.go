@@ -0,0 +1,343 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RPCClient makes read-only Ethereum JSON-RPC calls. With several
+// endpoints it rotates to the next one when an endpoint is down or rate
+// limited, and rests failing endpoints for a while before trying them
+// again.
+type RPCClient struct {
+	urls   []string
+	client *http.Client
+	now    func() time.Time
+
+	mu     sync.Mutex
+	states map[string]*endpointState
+	last   string
+	cache  *Cache
+}
+
+// endpointState tracks how one endpoint has been doing.
+type endpointState struct {
+	failures  int
+	lastError string
+	restUntil time.Time
+	served    int
+}
+
+// rpcRest is how long an endpoint that failed is skipped while other
+// endpoints are available.
+const rpcRest = 30 * time.Second
+
+// NewRPCClient returns a client for the JSON-RPC endpoint at url, or for
+// several comma-separated endpoints tried in order.
+func NewRPCClient(url string) *RPCClient {
+	r := &RPCClient{
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+		states: map[string]*endpointState{},
+	}
+	for _, u := range strings.Split(url, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			r.urls = append(r.urls, u)
+			r.states[u] = &endpointState{}
+		}
+	}
+	return r
+}
+
+// SetTimeout bounds each request to an endpoint.
+func (r *RPCClient) SetTimeout(timeout time.Duration) {
+	r.client.Timeout = timeout
+}
+
+// SetCache answers repeated lookups from c.
+func (r *RPCClient) SetCache(c *Cache) {
+	r.cache = c
+}
+
+// Endpoint returns the JSON-RPC URLs, comma-separated.
+func (r *RPCClient) Endpoint() string {
+	return strings.Join(r.urls, ",")
+}
+
+// Endpoints returns the JSON-RPC URLs in the order they are tried.
+func (r *RPCClient) Endpoints() []string {
+	return r.urls
+}
+
+// LastEndpoint returns the endpoint that answered the most recent
+// successful call, with any credentials in the URL removed.
+func (r *RPCClient) LastEndpoint() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return EndpointLabel(r.last)
+}
+
+// EndpointLabel shortens an RPC URL to its scheme and host, since
+// provider URLs often carry an API key in the path or query.
+func EndpointLabel(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	label := u.Scheme + "://" + u.Host
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		label += "/…"
+	}
+	return label
+}
+
+// rpcError is a failure that rotating to another endpoint may avoid:
+// the endpoint is unreachable, overloaded or rate limiting.
+type rpcError struct {
+	err  error
+	rest time.Duration
+}
+
+func (e *rpcError) Error() string { return e.err.Error() }
+
+func (r *RPCClient) call(ctx context.Context, method string, params []interface{}, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	if len(r.urls) == 0 {
+		return fmt.Errorf("%s: no RPC endpoint configured", method)
+	}
+
+	var failures []string
+	var last error
+	for _, endpoint := range r.order() {
+		err := r.callEndpoint(ctx, endpoint, method, body, out)
+		var rotate *rpcError
+		if !errors.As(err, &rotate) || ctx.Err() != nil {
+			if err == nil {
+				r.markServed(endpoint)
+			}
+			return err
+		}
+		r.markFailed(endpoint, rotate)
+		failures = append(failures, fmt.Sprintf("%s: %v", EndpointLabel(endpoint), err))
+		last = err
+	}
+	if len(failures) == 1 {
+		return last
+	}
+	return fmt.Errorf("all %d RPC endpoints failed: %s", len(failures), strings.Join(failures, "; "))
+}
+
+// order returns the endpoints to try: resting endpoints go last, so they
+// are only used when every other endpoint has failed too.
+func (r *RPCClient) order() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	var ready, resting []string
+	for _, u := range r.urls {
+		if now.Before(r.states[u].restUntil) {
+			resting = append(resting, u)
+		} else {
+			ready = append(ready, u)
+		}
+	}
+	return append(ready, resting...)
+}
+
+func (r *RPCClient) markServed(endpoint string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.states[endpoint]
+	s.restUntil = time.Time{}
+	s.served++
+	r.last = endpoint
+}
+
+func (r *RPCClient) markFailed(endpoint string, err *rpcError) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.states[endpoint]
+	s.failures++
+	s.lastError = err.Error()
+	rest := err.rest
+	if rest == 0 {
+		rest = rpcRest
+	}
+	s.restUntil = r.now().Add(rest)
+}
+
+func (r *RPCClient) callEndpoint(ctx context.Context, endpoint, method string, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return &rpcError{err: fmt.Errorf("%s failed: %v", method, err)}
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusTooManyRequests:
+		rest := time.Duration(0)
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			rest = time.Duration(secs) * time.Second
+		}
+		return &rpcError{err: fmt.Errorf("%s returned HTTP 429 (rate limited)", method), rest: rest}
+	case resp.StatusCode >= 500:
+		return &rpcError{err: fmt.Errorf("%s returned HTTP %d", method, resp.StatusCode)}
+	default:
+		return fmt.Errorf("%s returned HTTP %d", method, resp.StatusCode)
+	}
+
+	var reply struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return &rpcError{err: fmt.Errorf("%s: invalid response: %v", method, err)}
+	}
+	if reply.Error != nil {
+		err := fmt.Errorf("%s: %s (code %d)", method, reply.Error.Message, reply.Error.Code)
+		// -32005 is the standard "limit exceeded" code; providers also
+		// report rate limits as internal errors with a telling message.
+		message := strings.ToLower(reply.Error.Message)
+		if reply.Error.Code == -32005 || strings.Contains(message, "rate limit") || strings.Contains(message, "too many requests") {
+			return &rpcError{err: err}
+		}
+		return err
+	}
+	return json.Unmarshal(reply.Result, out)
+}
+
+// GetCode returns the runtime bytecode at address; it is empty for
+// externally owned accounts.
+func (r *RPCClient) GetCode(address string) ([]byte, error) {
+	key := "code:" + strings.ToLower(address)
+	if r.cache != nil {
+		if code, ok := r.cache.get(key); ok {
+			return code, nil
+		}
+	}
+	var hexCode string
+	if err := r.call(context.Background(), "eth_getCode", []interface{}{address, "latest"}, &hexCode); err != nil {
+		return nil, err
+	}
+	code, err := decodeHex(hexCode)
+	// Contract code never changes, but accounts can deploy code later or
+	// delegate to another contract (EIP-7702, prefix 0xef0100).
+	if err == nil && r.cache != nil && len(code) > 0 && !bytes.HasPrefix(code, []byte{0xef, 0x01, 0x00}) {
+		r.cache.put(key, code)
+	}
+	return code, err
+}
+
+// Call runs a read-only contract call and returns the raw return data.
+func (r *RPCClient) Call(to string, data []byte) ([]byte, error) {
+	return r.CallContext(context.Background(), to, data)
+}
+
+// CallContext is Call bounded by ctx.
+func (r *RPCClient) CallContext(ctx context.Context, to string, data []byte) ([]byte, error) {
+	var ret string
+	msg := map[string]string{"to": to, "data": "0x" + hex.EncodeToString(data)}
+	if err := r.call(ctx, "eth_call", []interface{}{msg, "latest"}, &ret); err != nil {
+		return nil, err
+	}
+	return decodeHex(ret)
+}
+
+// cachedCall is Call answered from the cache when possible. deps are the
+// namehashes or labelhashes the result depends on.
+func (r *RPCClient) cachedCall(ctx context.Context, to string, data []byte, deps ...[]byte) ([]byte, error) {
+	if r.cache == nil {
+		return r.CallContext(ctx, to, data)
+	}
+	key := "call:" + strings.ToLower(to) + ":" + hex.EncodeToString(data)
+	if ret, ok := r.cache.get(key); ok {
+		return ret, nil
+	}
+	ret, err := r.CallContext(ctx, to, data)
+	if err != nil {
+		return nil, err
+	}
+	var hexDeps []string
+	for _, dep := range deps {
+		hexDeps = append(hexDeps, Hex(dep))
+	}
+	r.cache.put(key, ret, hexDeps...)
+	return ret, nil
+}
+
+// selector returns the 4-byte ABI function selector for a signature such
+// as "getThreshold()".
+func selector(signature string) []byte {
+	return Keccak256([]byte(signature))[:4]
+}
+
+func decodeHex(s string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex %q: %v", s, err)
+	}
+	return b, nil
+}
+
+// ENSRecord returns the owner and resolver of name in the ENS registry;
+// both are empty when the name does not exist.
+func (r *RPCClient) ENSRecord(name string) (owner, resolver string, err error) {
+	return r.ENSRecordContext(context.Background(), name)
+}
+
+// ENSRecordContext is ENSRecord bounded by ctx.
+func (r *RPCClient) ENSRecordContext(ctx context.Context, name string) (owner, resolver string, err error) {
+	node := NameHash(name)
+	ret, err := r.cachedCall(ctx, ENSRegistry, append(selector("owner(bytes32)"), node...), node)
+	if err != nil {
+		return "", "", err
+	}
+	if owner = wordAddress(ret); owner == "" {
+		return "", "", nil
+	}
+	ret, err = r.cachedCall(ctx, ENSRegistry, append(selector("resolver(bytes32)"), node...), node)
+	if err != nil {
+		return owner, "", err
+	}
+	return owner, wordAddress(ret), nil
+}
+
+// wordAddress decodes an ABI-encoded address return value, returning ""
+// for the zero address.
+func wordAddress(ret []byte) string {
+	if len(ret) != 32 {
+		return ""
+	}
+	for _, b := range ret[12:] {
+		if b != 0 {
+			return Hex(ret[12:])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rpcServer answers eth_blockNumber with block, or fails with status.
+func rpcServer(status int, block string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": block})
+	}))
+}
+
+func TestRPCRotation(t *testing.T) {
+	limited := rpcServer(http.StatusTooManyRequests, "")
+	defer limited.Close()
+	good := rpcServer(http.StatusOK, "0x10")
+	defer good.Close()
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	r := NewRPCClient(limited.URL + "/v3/secret-key, " + good.URL)
+	r.now = func() time.Time { return now }
+
+	if n, err := r.BlockNumber(); err != nil || n != 16 {
+		t.Fatalf("BlockNumber = %d, %v", n, err)
+	}
+	if r.LastEndpoint() != good.URL {
+		t.Errorf("served by %q, want %q", r.LastEndpoint(), good.URL)
+	}
+	if order := r.order(); order[0] != good.URL {
+		t.Errorf("rate-limited endpoint should rest, order = %v", order)
+	}
+
+	now = now.Add(rpcRest)
+	if order := r.order(); order[0] != limited.URL+"/v3/secret-key" {
+		t.Errorf("rested endpoint should be retried first, order = %v", order)
+	}
+}
+
+func TestRPCErrors(t *testing.T) {
+	down := rpcServer(http.StatusBadGateway, "")
+	defer down.Close()
+	reverted := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "error": {"code": 3, "message": "execution reverted"}}`))
+	}))
+	defer reverted.Close()
+	good := rpcServer(http.StatusOK, "0x10")
+	defer good.Close()
+
+	// Contract errors are answers, not endpoint failures.
+	if _, err := NewRPCClient(reverted.URL + "," + good.URL).BlockNumber(); err == nil || !strings.Contains(err.Error(), "execution reverted") {
+		t.Errorf("err = %v, want the revert", err)
+	}
+
+	_, err := NewRPCClient(down.URL).BlockNumber()
+	if err == nil || err.Error() != "eth_blockNumber returned HTTP 502" {
+		t.Errorf("single endpoint err = %v", err)
+	}
+	_, err = NewRPCClient(down.URL + "," + down.URL + "/x").BlockNumber()
+	if err == nil || !strings.HasPrefix(err.Error(), "all 2 RPC endpoints failed") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestRPCHealth(t *testing.T) {
+	ahead := rpcServer(http.StatusOK, "0x64")
+	defer ahead.Close()
+	behind := rpcServer(http.StatusOK, "0x50")
+	defer behind.Close()
+	down := rpcServer(http.StatusInternalServerError, "")
+	defer down.Close()
+
+	r := NewRPCClient(strings.Join([]string{behind.URL, ahead.URL, down.URL}, ","))
+	health := r.Health()
+	if len(health) != 3 {
+		t.Fatalf("health = %+v", health)
+	}
+	if h := health[0]; h.Healthy || h.Lag != 20 || h.Error != "20 blocks behind" {
+		t.Errorf("lagging endpoint = %+v", h)
+	}
+	if h := health[1]; !h.Healthy || h.Block != 100 {
+		t.Errorf("healthy endpoint = %+v", h)
+	}
+	if h := health[2]; h.Healthy || h.Failures != 1 || !strings.Contains(h.Error, "HTTP 500") {
+		t.Errorf("down endpoint = %+v", h)
+	}
+	if order := r.order(); order[0] != ahead.URL {
+		t.Errorf("order after health check = %v", order)
+	}
+}
+
+func TestEndpointLabel(t *testing.T) {
+	tests := map[string]string{
+		"https://mainnet.infura.io/v3/abc123": "https://mainnet.infura.io/…",
+		"https://ethereum-rpc.publicnode.com": "https://ethereum-rpc.publicnode.com",
+		"https://rpc.example.com/?apikey=abc": "https://rpc.example.com/…",
+		"http://127.0.0.1:8545/":              "http://127.0.0.1:8545",
+	}
+	for raw, want := range tests {
+		if got := EndpointLabel(raw); got != want {
+			t.Errorf("EndpointLabel(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ENSHistory is the registration, renewal and ownership timeline of a .eth
+// name from the ENS subgraph.
+type ENSHistory struct {
+	Name            string     `json:"name"`
+	FirstRegistered *time.Time `json:"first_registered,omitempty"`
+	Expiry          *time.Time `json:"expiry,omitempty"`
+	Events          []ENSEvent `json:"events,omitempty"`
+	Owners          []string   `json:"owners,omitempty"`
+	Renewals        int        `json:"renewals"`
+	CheckedAt       time.Time  `json:"checked_at"`
+	Error           string     `json:"error,omitempty"`
+}
+
+// ENSEvent is one entry in an ENSHistory timeline. The subgraph only
+// records block numbers for events, not timestamps.
+type ENSEvent struct {
+	Type   string     `json:"type"`
+	Block  int64      `json:"block"`
+	TxHash string     `json:"tx_hash"`
+	Owner  string     `json:"owner,omitempty"`
+	Expiry *time.Time `json:"expiry,omitempty"`
+}
+
+// ENS event types.
+const (
+	ENSRegistered  = "registered"
+	ENSRenewed     = "renewed"
+	ENSTransferred = "transferred"
+	ENSWrapped     = "wrapped"
+	ENSUnwrapped   = "unwrapped"
+)
+
+const ensHistoryQuery = `query($id: String!) {
+  domain(id: $id) {
+    name
+    createdAt
+    registration {
+      registrationDate
+      expiryDate
+      events {
+        __typename blockNumber transactionID
+        ... on NameRegistered { registrant { id } expiryDate }
+        ... on NameRenewed { expiryDate }
+        ... on NameTransferred { newOwner { id } }
+      }
+    }
+    events {
+      __typename blockNumber transactionID
+      ... on NameWrapped { owner { id } expiryDate }
+      ... on NameUnwrapped { owner { id } }
+      ... on WrappedTransfer { owner { id } }
+    }
+  }
+}`
+
+// SubgraphClient queries an ENS subgraph GraphQL endpoint.
+type SubgraphClient struct {
+	url    string
+	client *http.Client
+}
+
+// NewSubgraphClient returns a client for the ENS subgraph at url, e.g. a
+// The Graph gateway URL including its API key.
+func NewSubgraphClient(url string) *SubgraphClient {
+	return &SubgraphClient{
+		url:    url,
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Endpoint returns the subgraph URL.
+func (s *SubgraphClient) Endpoint() string {
+	return s.url
+}
+
+type subgraphEvent struct {
+	Type          string `json:"__typename"`
+	BlockNumber   int64  `json:"blockNumber"`
+	TransactionID string `json:"transactionID"`
+	ExpiryDate    string `json:"expiryDate"`
+	Registrant    *struct {
+		ID string `json:"id"`
+	} `json:"registrant"`
+	NewOwner *struct {
+		ID string `json:"id"`
+	} `json:"newOwner"`
+	Owner *struct {
+		ID string `json:"id"`
+	} `json:"owner"`
+}
+
+// History returns the timeline of name. A name the subgraph does not know
+// returns an empty history.
+func (s *SubgraphClient) History(name string) (*ENSHistory, error) {
+	history := &ENSHistory{
+		Name:      name,
+		CheckedAt: time.Now(),
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"query":     ensHistoryQuery,
+		"variables": map[string]string{"id": Hex(NameHash(name))},
+	})
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		history.Error = fmt.Sprintf("ENS subgraph query failed: %v", err)
+		return history, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		history.Error = fmt.Sprintf("ENS subgraph returned HTTP %d", resp.StatusCode)
+		return history, nil
+	}
+
+	var reply struct {
+		Data struct {
+			Domain *struct {
+				CreatedAt    string `json:"createdAt"`
+				Registration *struct {
+					RegistrationDate string          `json:"registrationDate"`
+					ExpiryDate       string          `json:"expiryDate"`
+					Events           []subgraphEvent `json:"events"`
+				} `json:"registration"`
+				Events []subgraphEvent `json:"events"`
+			} `json:"domain"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		history.Error = fmt.Sprintf("invalid ENS subgraph response: %v", err)
+		return history, nil
+	}
+	if len(reply.Errors) > 0 {
+		history.Error = "ENS subgraph: " + reply.Errors[0].Message
+		return history, nil
+	}
+
+	domain := reply.Data.Domain
+	if domain == nil {
+		return history, nil
+	}
+	history.FirstRegistered = unixTime(domain.CreatedAt)
+	events := domain.Events
+	if reg := domain.Registration; reg != nil {
+		if t := unixTime(reg.RegistrationDate); t != nil && (history.FirstRegistered == nil || t.Before(*history.FirstRegistered)) {
+			history.FirstRegistered = t
+		}
+		history.Expiry = unixTime(reg.ExpiryDate)
+		events = append(events, reg.Events...)
+	}
+
+	for _, e := range events {
+		event := ENSEvent{Block: e.BlockNumber, TxHash: e.TransactionID, Expiry: unixTime(e.ExpiryDate)}
+		switch e.Type {
+		case "NameRegistered":
+			event.Type = ENSRegistered
+			if e.Registrant != nil {
+				event.Owner = e.Registrant.ID
+			}
+		case "NameRenewed":
+			event.Type = ENSRenewed
+			history.Renewals++
+		case "NameTransferred":
+			event.Type = ENSTransferred
+			if e.NewOwner != nil {
+				event.Owner = e.NewOwner.ID
+			}
+		case "NameWrapped", "NameUnwrapped", "WrappedTransfer":
+			event.Type = map[string]string{
+				"NameWrapped":     ENSWrapped,
+				"NameUnwrapped":   ENSUnwrapped,
+				"WrappedTransfer": ENSTransferred,
+			}[e.Type]
+			if e.Owner != nil {
+				event.Owner = e.Owner.ID
+			}
+		default:
+			continue
+		}
+		history.Events = append(history.Events, event)
+	}
+	sort.SliceStable(history.Events, func(i, j int) bool {
+		return history.Events[i].Block < history.Events[j].Block
+	})
+
+	for _, e := range history.Events {
+		if e.Owner == "" || (len(history.Owners) > 0 && history.Owners[len(history.Owners)-1] == e.Owner) {
+			continue
+		}
+		history.Owners = append(history.Owners, e.Owner)
+	}
+	return history, nil
+}
+
+// unixTime parses a subgraph BigInt timestamp in seconds.
+func unixTime(s string) *time.Time {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return nil
+	}
+	t := time.Unix(n, 0).UTC()
+	return &t
+}
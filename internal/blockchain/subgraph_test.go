@@ -0,0 +1,62 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSubgraphClient_History(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables map[string]string `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Variables["id"] != Hex(NameHash("vault.eth")) {
+			w.Write([]byte(`{"data": {"domain": null}}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"domain": {
+			"createdAt": "1500000000",
+			"registration": {
+				"registrationDate": "1560000000",
+				"expiryDate": "1900000000",
+				"events": [
+					{"__typename": "NameRenewed", "blockNumber": 300, "transactionID": "0x3", "expiryDate": "1900000000"},
+					{"__typename": "NameRegistered", "blockNumber": 100, "transactionID": "0x1", "registrant": {"id": "0xaaa"}, "expiryDate": "1600000000"},
+					{"__typename": "NameTransferred", "blockNumber": 200, "transactionID": "0x2", "newOwner": {"id": "0xbbb"}}
+				]
+			},
+			"events": [
+				{"__typename": "NameWrapped", "blockNumber": 400, "transactionID": "0x4", "owner": {"id": "0xbbb"}, "expiryDate": "1900000000"}
+			]
+		}}}`))
+	}))
+	defer server.Close()
+
+	history, err := NewSubgraphClient(server.URL).History("vault.eth")
+	if err != nil || history.Error != "" {
+		t.Fatalf("unexpected error: %v %s", err, history.Error)
+	}
+	if history.FirstRegistered == nil || history.FirstRegistered.Unix() != 1500000000 {
+		t.Errorf("expected the earlier createdAt as first registration, got %v", history.FirstRegistered)
+	}
+	if history.Renewals != 1 || len(history.Events) != 4 {
+		t.Fatalf("unexpected timeline: %+v", history)
+	}
+	order := []string{ENSRegistered, ENSTransferred, ENSRenewed, ENSWrapped}
+	for i, e := range history.Events {
+		if e.Type != order[i] {
+			t.Errorf("event %d = %s, want %s", i, e.Type, order[i])
+		}
+	}
+	if len(history.Owners) != 2 || history.Owners[0] != "0xaaa" || history.Owners[1] != "0xbbb" {
+		t.Errorf("unexpected owners: %v", history.Owners)
+	}
+
+	unknown, err := NewSubgraphClient(server.URL).History("unknown.eth")
+	if err != nil || unknown.Error != "" || len(unknown.Events) != 0 {
+		t.Errorf("expected an empty history, got %+v, %v", unknown, err)
+	}
+}
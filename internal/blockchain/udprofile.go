@@ -0,0 +1,148 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DefaultUDProfileAPI is the Unstoppable Domains public profile API, which
+// serves display names, verified social accounts and humanity checks.
+const DefaultUDProfileAPI = "https://api.unstoppabledomains.com/profile/public"
+
+// UDProfile is the profile of an Unstoppable Domains name, assembled from
+// its records, reverse resolution and public profile.
+type UDProfile struct {
+	DisplayName string `json:"display_name,omitempty"`
+	// Addresses maps currency tickers (with the token standard for
+	// multi-chain tokens, e.g. "USDT (ERC20)") to payment addresses.
+	Addresses map[string]string `json:"addresses,omitempty"`
+	IPFS      string            `json:"ipfs,omitempty"`
+	Redirect  string            `json:"redirect_url,omitempty"`
+	// Socials maps platforms to handles; VerifiedSocials lists the ones
+	// the owner proved control of.
+	Socials         map[string]string `json:"socials,omitempty"`
+	VerifiedSocials []string          `json:"verified_socials,omitempty"`
+	HumanityCheck   *bool             `json:"humanity_check,omitempty"`
+	// Reverse is whether the owner's address reverse-resolves to this
+	// name; PrimaryName is the name it resolves to otherwise.
+	Reverse     bool   `json:"reverse"`
+	PrimaryName string `json:"primary_name,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// newUDProfile sorts a name's records into payment addresses, website and
+// social handles.
+func newUDProfile(records map[string]string) *UDProfile {
+	p := &UDProfile{Addresses: map[string]string{}, Socials: map[string]string{}}
+	for key, value := range records {
+		if value == "" {
+			continue
+		}
+		parts := strings.Split(key, ".")
+		switch {
+		case len(parts) == 3 && parts[0] == "crypto" && parts[2] == "address":
+			p.Addresses[parts[1]] = value
+		case len(parts) == 5 && parts[0] == "crypto" && parts[2] == "version" && parts[4] == "address":
+			p.Addresses[fmt.Sprintf("%s (%s)", parts[1], parts[3])] = value
+		case key == "ipfs.html.value" || (key == "dweb.ipfs.hash" && p.IPFS == ""):
+			p.IPFS = value
+		case key == "browser.redirect_url":
+			p.Redirect = value
+		case len(parts) == 3 && parts[0] == "social" && parts[2] == "username":
+			p.Socials[parts[1]] = value
+		}
+	}
+	return p
+}
+
+// udReverse returns the name address reverse-resolves to, or "" when it
+// has no reverse record.
+func (c *Checker) udReverse(ctx context.Context, address string) (string, error) {
+	var body struct {
+		Meta struct {
+			Domain string `json:"domain"`
+		} `json:"meta"`
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.udAPI+"/reverse/"+url.PathEscape(address), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.udKey)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("reverse lookup failed: %v", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", nil
+	default:
+		return "", fmt.Errorf("reverse lookup returned HTTP %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid reverse lookup response: %v", err)
+	}
+	return body.Meta.Domain, nil
+}
+
+// loadPublicProfile adds the display name, verified social accounts and
+// humanity check from the public profile API.
+func (c *Checker) loadPublicProfile(ctx context.Context, domain string, p *UDProfile) error {
+	u := c.udProfileAPI + "/" + url.PathEscape(domain) + "?fields=profile,socialAccounts,humanityCheck"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("profile lookup failed: %v", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("profile lookup returned HTTP %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Profile struct {
+			DisplayName string `json:"displayName"`
+		} `json:"profile"`
+		SocialAccounts map[string]struct {
+			Location string `json:"location"`
+			Verified bool   `json:"verified"`
+			Public   bool   `json:"public"`
+		} `json:"socialAccounts"`
+		HumanityCheck *struct {
+			Verified bool `json:"verified"`
+		} `json:"humanityCheck"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("invalid profile response: %v", err)
+	}
+	p.DisplayName = body.Profile.DisplayName
+	for platform, account := range body.SocialAccounts {
+		if !account.Public || account.Location == "" {
+			continue
+		}
+		if _, ok := p.Socials[platform]; !ok {
+			p.Socials[platform] = account.Location
+		}
+		if account.Verified {
+			p.VerifiedSocials = append(p.VerifiedSocials, platform)
+		}
+	}
+	sort.Strings(p.VerifiedSocials)
+	if body.HumanityCheck != nil {
+		p.HumanityCheck = boolPtr(body.HumanityCheck.Verified)
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package brand
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Monitor matches newly registered domains from one or more feeds against
+// brand keywords. Names already seen in an earlier poll are not reported
+// again, so a feed can be polled repeatedly.
+type Monitor struct {
+	matcher *Matcher
+	client  *http.Client
+	seen    map[string]bool
+}
+
+type Result struct {
+	Keywords  []string     `json:"keywords"`
+	Feeds     []FeedStatus `json:"feeds"`
+	Scanned   int          `json:"scanned"`
+	Hits      []Hit        `json:"hits"`
+	CheckedAt time.Time    `json:"checked_at"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// FeedStatus reports how many new names a feed contributed.
+type FeedStatus struct {
+	Source string `json:"source"`
+	Names  int    `json:"names"`
+	Error  string `json:"error,omitempty"`
+}
+
+func NewMonitor(matcher *Matcher) *Monitor {
+	return &Monitor{
+		matcher: matcher,
+		client: &http.Client{
+			Timeout: 5 * time.Minute,
+		},
+		seen: make(map[string]bool),
+	}
+}
+
+// Feed is a source of newly registered domains: a URL or file listing one
+// domain per line or a zone file, plain or gzip. With a Baseline (an older
+// copy of the same zone) only names added since the baseline are used.
+type Feed struct {
+	Source   string `json:"source"`
+	Baseline string `json:"baseline,omitempty"`
+}
+
+func (f Feed) String() string {
+	if f.Baseline != "" {
+		return f.Baseline + " → " + f.Source
+	}
+	return f.Source
+}
+
+// Poll reads every feed and matches the names not seen before.
+func (m *Monitor) Poll(feeds []Feed) *Result {
+	result := &Result{
+		Keywords:  m.matcher.Keywords(),
+		CheckedAt: time.Now(),
+	}
+
+	var fresh []string
+	failed := 0
+	for _, feed := range feeds {
+		status := FeedStatus{Source: feed.String()}
+		names, err := m.feedNames(feed)
+		if err != nil {
+			status.Error = err.Error()
+			failed++
+		}
+		for _, name := range names {
+			if !m.seen[name] {
+				m.seen[name] = true
+				fresh = append(fresh, name)
+				status.Names++
+			}
+		}
+		result.Feeds = append(result.Feeds, status)
+	}
+	if failed > 0 && failed == len(feeds) {
+		result.Error = "no feed could be read"
+	}
+
+	result.Scanned = len(fresh)
+	result.Hits = m.matcher.MatchAll(fresh)
+	return result
+}
+
+func (m *Monitor) feedNames(feed Feed) ([]string, error) {
+	after, err := m.load(feed.Source)
+	if err != nil || feed.Baseline == "" {
+		return after, err
+	}
+	before, err := m.load(feed.Baseline)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(before, after), nil
+}
+
+func (m *Monitor) load(source string) ([]string, error) {
+	var r io.Reader
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		resp, err := m.client.Get(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch feed: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("feed returned HTTP %d", resp.StatusCode)
+		}
+		r = resp.Body
+	} else {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open feed: %v", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	buffered := bufio.NewReader(r)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress feed: %v", err)
+		}
+		defer gz.Close()
+		return ReadNames(gz)
+	}
+	return ReadNames(buffered)
+}
+
+// ReadNames reads domain names from a newline-separated list or a DNS zone
+// file. For zone files the owner name of each record is used, relative
+// names are completed with $ORIGIN, and duplicates are dropped.
+func ReadNames(r io.Reader) ([]string, error) {
+	seen := make(map[string]bool)
+	var names []string
+	origin := ""
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, ';'); i >= 0 {
+			line = line[:i]
+		}
+		if line == "" || line[0] == ' ' || line[0] == '\t' {
+			// Continuation lines repeat the previous owner.
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if strings.EqualFold(fields[0], "$ORIGIN") && len(fields) > 1 {
+			origin = strings.ToLower(strings.TrimSuffix(fields[1], "."))
+			continue
+		}
+		if strings.HasPrefix(fields[0], "$") || fields[0] == "@" {
+			continue
+		}
+
+		name := strings.ToLower(fields[0])
+		if strings.HasSuffix(name, ".") {
+			name = strings.TrimSuffix(name, ".")
+		} else if origin != "" && len(fields) > 1 {
+			name += "." + origin
+		}
+		if !strings.Contains(name, ".") || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names, scanner.Err()
+}
+
+// Diff returns the names in after that are not in before, sorted.
+func Diff(before, after []string) []string {
+	old := make(map[string]bool, len(before))
+	for _, name := range before {
+		old[name] = true
+	}
+	var added []string
+	for _, name := range after {
+		if !old[name] {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+	return added
+}
+
+// Alert posts the hits of a poll as JSON to a webhook.
+func Alert(webhook string, result *Result) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send alert: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package brand
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"d3-domain-tool/internal/suffix"
+)
+
+// Hit is a newly registered domain that resembles a brand keyword.
+type Hit struct {
+	Domain  string `json:"domain"`
+	Keyword string `json:"keyword"`
+	// Rule is the permutation rule that matched: exact, contains,
+	// hyphenated, homoglyph or typo.
+	Rule   string `json:"rule"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Matcher compares domain labels against brand keywords.
+type Matcher struct {
+	keywords    []string
+	maxDistance int
+}
+
+// NewMatcher returns a matcher for the given keywords. maxDistance is the
+// largest edit distance reported as a typo (0 disables typo matching).
+func NewMatcher(keywords []string, maxDistance int) *Matcher {
+	m := &Matcher{maxDistance: maxDistance}
+	for _, k := range keywords {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			m.keywords = append(m.keywords, k)
+		}
+	}
+	return m
+}
+
+// Keywords returns the normalized keywords.
+func (m *Matcher) Keywords() []string {
+	return m.keywords
+}
+
+// homoglyphs maps characters and sequences commonly substituted in
+// lookalike domains to the letter they imitate.
+var homoglyphs = strings.NewReplacer(
+	"rn", "m", "vv", "w", "cl", "d",
+	"0", "o", "1", "l", "3", "e", "4", "a", "5", "s", "7", "t", "8", "b", "9", "g",
+)
+
+// Match returns the first rule under which domain resembles a keyword, or
+// nil. Rules are tried from the most to the least specific.
+func (m *Matcher) Match(domain string) *Hit {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	label := suffix.Label(domain)
+	if label == "" {
+		return nil
+	}
+	flat := strings.ReplaceAll(label, "-", "")
+	lookalike := homoglyphs.Replace(flat)
+
+	for _, k := range m.keywords {
+		switch {
+		case label == k:
+			return &Hit{Domain: domain, Keyword: k, Rule: "exact"}
+		case strings.Contains(label, k):
+			return &Hit{Domain: domain, Keyword: k, Rule: "contains"}
+		case strings.Contains(flat, k):
+			return &Hit{Domain: domain, Keyword: k, Rule: "hyphenated"}
+		case strings.Contains(lookalike, k):
+			return &Hit{Domain: domain, Keyword: k, Rule: "homoglyph", Detail: label + " reads as " + lookalike}
+		}
+	}
+
+	if m.maxDistance <= 0 {
+		return nil
+	}
+	for _, k := range m.keywords {
+		// Very short keywords are within a small edit distance of
+		// almost everything.
+		if len(k) < 4 {
+			continue
+		}
+		if d := distance(flat, k); d <= m.maxDistance {
+			return &Hit{Domain: domain, Keyword: k, Rule: "typo", Detail: editDetail(d)}
+		}
+	}
+	return nil
+}
+
+// MatchAll returns the hits among domains, sorted by domain.
+func (m *Matcher) MatchAll(domains []string) []Hit {
+	var hits []Hit
+	for _, d := range domains {
+		if hit := m.Match(d); hit != nil {
+			hits = append(hits, *hit)
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Domain < hits[j].Domain })
+	return hits
+}
+
+func editDetail(d int) string {
+	if d == 1 {
+		return "1 edit away"
+	}
+	return fmt.Sprintf("%d edits away", d)
+}
+
+// distance is the Damerau-Levenshtein (optimal string alignment) distance,
+// so a transposition of two adjacent letters counts as one edit.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
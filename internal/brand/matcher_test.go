@@ -0,0 +1,80 @@
+package brand
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	m := NewMatcher([]string{"Acme", "paypal"}, 1)
+	tests := []struct {
+		domain string
+		rule   string
+	}{
+		{"acme.com", "exact"},
+		{"acme-login.net", "contains"},
+		{"ac-me.shop", "hyphenated"},
+		{"paypa1-secure.com", "homoglyph"},
+		{"paypl.co.uk", "typo"},
+		{"papyal.com", "typo"},
+		{"acne.com", "typo"},
+		{"acorn.com", ""},
+		{"example.com", ""},
+	}
+	for _, tt := range tests {
+		hit := m.Match(tt.domain)
+		got := ""
+		if hit != nil {
+			got = hit.Rule
+		}
+		if got != tt.rule {
+			t.Errorf("Match(%q) rule = %q, want %q", tt.domain, got, tt.rule)
+		}
+	}
+}
+
+func TestReadNames_ZoneFile(t *testing.T) {
+	zone := `$ORIGIN com.
+$TTL 172800
+@ IN SOA a.gtld-servers.net. nstld.verisign-grs.com. 1 2 3 4 5
+acme-pay NS ns1.example.net.
+acme-pay NS ns2.example.net.
+	NS ns3.example.net.
+other.org. NS ns1.other.org. ; absolute owner
+`
+	names, err := ReadNames(strings.NewReader(zone))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(names, ",") != "acme-pay.com,other.org" {
+		t.Errorf("unexpected names: %v", names)
+	}
+}
+
+func TestPoll_BaselineAndSeen(t *testing.T) {
+	dir := t.TempDir()
+	baseline := filepath.Join(dir, "old.txt")
+	current := filepath.Join(dir, "new.txt")
+	os.WriteFile(baseline, []byte("acme.com\nexample.com\n"), 0644)
+	os.WriteFile(current, []byte("acme.com\nexample.com\nacme-support.net\nunrelated.io\n"), 0644)
+
+	m := NewMonitor(NewMatcher([]string{"acme"}, 1))
+	feeds := []Feed{{Source: current, Baseline: baseline}}
+
+	result := m.Poll(feeds)
+	if result.Scanned != 2 || len(result.Hits) != 1 || result.Hits[0].Domain != "acme-support.net" {
+		t.Fatalf("unexpected first poll: %+v", result)
+	}
+
+	result = m.Poll(feeds)
+	if result.Scanned != 0 || len(result.Hits) != 0 {
+		t.Errorf("expected names seen earlier to be skipped, got %+v", result)
+	}
+
+	result = m.Poll([]Feed{{Source: filepath.Join(dir, "missing.txt")}})
+	if result.Error == "" || result.Feeds[0].Error == "" {
+		t.Errorf("expected a feed error, got %+v", result)
+	}
+}
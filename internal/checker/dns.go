@@ -1,38 +1,90 @@
 package checker
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"net"
+	"sort"
 	"strings"
 	"time"
+
+	"d3-domain-tool/internal/suffix"
 )
 
 type DNSChecker struct {
-	timeout time.Duration
+	timeout  time.Duration
+	resolver *Resolver
+	// serverFor returns a resolver querying the nameserver at ip directly.
+	serverFor func(ip string) *Resolver
+	verbose   bool
+	ttlReport bool
 }
 
 type DNSResult struct {
-	Available    bool              `json:"available"`
-	TLD          string            `json:"tld"`
-	HasRecords   bool              `json:"has_records"`
-	RecordTypes  []string          `json:"record_types"`
-	CheckedAt    time.Time         `json:"checked_at"`
-	Error        string            `json:"error,omitempty"`
+	Available  bool   `json:"available"`
+	TLD        string `json:"tld"`
+	HasRecords bool   `json:"has_records"`
+	// NXDomain is set when the resolver answered that the name does not
+	// exist at all, as opposed to existing without A, MX, NS or TXT records.
+	NXDomain    bool       `json:"nxdomain,omitempty"`
+	RecordTypes []string   `json:"record_types"`
+	CheckedAt   time.Time  `json:"checked_at"`
+	Answers     []Record   `json:"answers,omitempty"`
+	TTLReport   *TTLReport `json:"ttl_report,omitempty"`
+	// Warnings are data quality issues that leave the result usable, such
+	// as a suspected wildcard.
+	Warnings []string `json:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty"`
 }
 
 func NewDNSChecker() *DNSChecker {
 	return &DNSChecker{
-		timeout: 5 * time.Second,
+		timeout:  5 * time.Second,
+		resolver: NewResolver(),
+		serverFor: func(ip string) *Resolver {
+			return NewResolverFor(ip).NonRecursive()
+		},
 	}
 }
 
+// SetVerbose enables capturing the raw answers (values and TTLs) for each
+// record type in DNSResult.Answers.
+func (c *DNSChecker) SetVerbose(verbose bool) {
+	c.verbose = verbose
+}
+
+// SetTTLReport enables the TTL / change-readiness report.
+func (c *DNSChecker) SetTTLReport(enabled bool) {
+	c.ttlReport = enabled
+}
+
+// verboseTypes are the record types captured in verbose mode.
+var verboseTypes = []string{"A", "AAAA", "CNAME", "MX", "NS", "TXT"}
+
+// SetTimeout bounds each lookup of a check.
+func (c *DNSChecker) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+	c.resolver.timeout = timeout
+}
+
 func (c *DNSChecker) Check(domain string) (*DNSResult, error) {
+	return c.CheckContext(context.Background(), domain)
+}
+
+// CheckContext is Check with the lookups bounded by ctx as well as the
+// checker's timeout, which applies to each lookup.
+func (c *DNSChecker) CheckContext(ctx context.Context, domain string) (*DNSResult, error) {
 	result := &DNSResult{
 		TLD:       extractTLD(domain),
 		CheckedAt: time.Now(),
 	}
+	resolver := net.DefaultResolver
 
 	// Check for A records
-	aRecords, err := net.LookupHost(domain)
+	lookup, cancel := context.WithTimeout(ctx, c.timeout)
+	aRecords, err := resolver.LookupHost(lookup, domain)
+	cancel()
 	if err == nil && len(aRecords) > 0 {
 		result.HasRecords = true
 		result.RecordTypes = append(result.RecordTypes, "A")
@@ -40,7 +92,9 @@ func (c *DNSChecker) Check(domain string) (*DNSResult, error) {
 	}
 
 	// Check for MX records
-	mxRecords, err := net.LookupMX(domain)
+	lookup, cancel = context.WithTimeout(ctx, c.timeout)
+	mxRecords, err := resolver.LookupMX(lookup, domain)
+	cancel()
 	if err == nil && len(mxRecords) > 0 {
 		result.HasRecords = true
 		result.RecordTypes = append(result.RecordTypes, "MX")
@@ -48,7 +102,9 @@ func (c *DNSChecker) Check(domain string) (*DNSResult, error) {
 	}
 
 	// Check for NS records
-	nsRecords, err := net.LookupNS(domain)
+	lookup, cancel = context.WithTimeout(ctx, c.timeout)
+	nsRecords, err := resolver.LookupNS(lookup, domain)
+	cancel()
 	if err == nil && len(nsRecords) > 0 {
 		result.HasRecords = true
 		result.RecordTypes = append(result.RecordTypes, "NS")
@@ -56,7 +112,9 @@ func (c *DNSChecker) Check(domain string) (*DNSResult, error) {
 	}
 
 	// Check for TXT records
-	txtRecords, err := net.LookupTXT(domain)
+	lookup, cancel = context.WithTimeout(ctx, c.timeout)
+	txtRecords, err := resolver.LookupTXT(lookup, domain)
+	cancel()
 	if err == nil && len(txtRecords) > 0 {
 		result.HasRecords = true
 		result.RecordTypes = append(result.RecordTypes, "TXT")
@@ -66,15 +124,158 @@ func (c *DNSChecker) Check(domain string) (*DNSResult, error) {
 	// If no records found, likely available
 	if !result.HasRecords {
 		result.Available = true
+		if resp, err := c.resolver.Query(domain, "SOA"); err == nil && resp.Rcode == "NXDOMAIN" {
+			result.NXDomain = true
+		}
+	} else if c.wildcard(domain) {
+		result.Warnings = append(result.Warnings, "wildcard DNS suspected: a made-up name next to this one resolves too, so its records do not prove it is registered")
+	}
+
+	if c.verbose || c.ttlReport {
+		answers := c.Answers(domain)
+		if c.verbose {
+			result.Answers = answers
+		}
+		if c.ttlReport {
+			result.TTLReport = AnalyzeTTLs(answers)
+		}
 	}
 
 	return result, nil
 }
 
+// wildcard reports whether a made-up sibling of domain resolves, as under a
+// parent zone that answers for every name.
+func (c *DNSChecker) wildcard(domain string) bool {
+	i := strings.Index(domain, ".")
+	if i < 0 {
+		return false
+	}
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	resp, err := c.resolver.Query("d3-"+hex.EncodeToString(buf)+domain[i:], "A")
+	if err != nil {
+		return false
+	}
+	for _, rec := range resp.Answers {
+		if rec.Type == "A" || rec.Type == "CNAME" {
+			return true
+		}
+	}
+	return false
+}
+
+// extractTLD returns the registry suffix of domain, e.g. ".co.uk".
 func extractTLD(domain string) string {
-	parts := strings.Split(domain, ".")
-	if len(parts) < 2 {
+	if !strings.Contains(domain, ".") {
 		return ""
 	}
-	return "." + parts[len(parts)-1]
-}
\ No newline at end of file
+	return "." + suffix.Public(domain)
+}
+
+// Answers queries each verbose record type directly so TTLs and MX
+// priorities are available. The queries go to one of the zone's
+// authoritative nameservers, since a recursive resolver returns cached
+// records with their TTLs counted down; when none answers the resolver is
+// used instead. Failed queries are skipped.
+func (c *DNSChecker) Answers(domain string) []Record {
+	auth := c.authoritative(domain)
+	var answers []Record
+	for _, rrType := range verboseTypes {
+		var resp *Response
+		var err error
+		if auth != nil {
+			resp, err = auth.Query(domain, rrType)
+		}
+		if auth == nil || err != nil {
+			resp, err = c.resolver.Query(domain, rrType)
+		}
+		if err != nil {
+			continue
+		}
+		var typed []Record
+		for _, rec := range resp.Answers {
+			if rec.Type == rrType {
+				typed = append(typed, rec)
+			}
+		}
+		// Resolvers rotate record sets; sort so repeated runs match.
+		sort.Slice(typed, func(i, j int) bool {
+			if typed[i].Priority != typed[j].Priority {
+				return typed[i].Priority < typed[j].Priority
+			}
+			return typed[i].Value < typed[j].Value
+		})
+		answers = append(answers, typed...)
+	}
+	return answers
+}
+
+// authoritative returns a resolver for one of the nameservers of the zone
+// holding domain, or nil if none can be found.
+func (c *DNSChecker) authoritative(domain string) *Resolver {
+	zones := []string{domain}
+	if apex := suffix.Registrable(domain); apex != domain {
+		zones = append(zones, apex)
+	}
+	for _, zone := range zones {
+		resp, err := c.resolver.Query(zone, "NS")
+		if err != nil {
+			continue
+		}
+		for _, ns := range resp.Answers {
+			if ns.Type != "NS" {
+				continue
+			}
+			addrs, err := c.resolver.Query(ns.Value, "A")
+			if err != nil {
+				continue
+			}
+			for _, rec := range addrs.Answers {
+				if rec.Type == "A" {
+					r := c.serverFor(rec.Value)
+					r.timeout = c.timeout
+					return r
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Server returns the nameserver used for raw queries.
+func (c *DNSChecker) Server() string {
+	return c.resolver.Server()
+}
+
+// ResultFromAnswers rebuilds a DNS result from previously captured raw
+// answers, for offline re-analysis. A domain with no A/AAAA/CNAME, MX, NS or
+// TXT answers is considered available, matching Check.
+func ResultFromAnswers(domain string, answers []Record, ttlReport bool) *DNSResult {
+	result := &DNSResult{
+		TLD:     extractTLD(domain),
+		Answers: answers,
+	}
+
+	present := make(map[string]bool)
+	for _, rec := range answers {
+		switch rec.Type {
+		case "A", "AAAA", "CNAME":
+			present["A"] = true
+		default:
+			present[rec.Type] = true
+		}
+	}
+	for _, rrType := range []string{"A", "MX", "NS", "TXT"} {
+		if present[rrType] {
+			result.HasRecords = true
+			result.RecordTypes = append(result.RecordTypes, rrType)
+		}
+	}
+	result.Available = !result.HasRecords
+
+	if ttlReport {
+		result.TTLReport = AnalyzeTTLs(answers)
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package checker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestDNSChecker_Wildcard(t *testing.T) {
+	// Every name under .ws resolves; under .com only example.com does.
+	addr := serveDNS(t, func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		name := q.Name.String()
+		if q.Type != dnsmessage.TypeA || (!strings.HasSuffix(name, ".ws.") && name != "example.com.") {
+			return dnsmessage.RCodeNameError, nil
+		}
+		return dnsmessage.RCodeSuccess, []dnsmessage.Resource{{
+			Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 300},
+			Body:   &dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}},
+		}}
+	})
+	c := NewDNSChecker()
+	c.resolver = NewResolverFor(addr)
+
+	if !c.wildcard("example.ws") {
+		t.Error("wildcard under .ws not detected")
+	}
+	if c.wildcard("example.com") {
+		t.Error("wildcard reported for example.com")
+	}
+}
+
+func TestDNSChecker_AnswersFromAuthoritative(t *testing.T) {
+	record := func(q dnsmessage.Question, ttl uint32) []dnsmessage.Resource {
+		return []dnsmessage.Resource{{
+			Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: ttl},
+			Body:   &dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}},
+		}}
+	}
+	// The recursive resolver has had example.com cached for most of its
+	// hour-long TTL.
+	recursive := serveDNS(t, func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		switch {
+		case q.Type == dnsmessage.TypeNS && q.Name.String() == "example.com.":
+			ns, _ := dnsmessage.NewName("ns1.example.net.")
+			return dnsmessage.RCodeSuccess, []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeNS, Class: dnsmessage.ClassINET, TTL: 3600},
+				Body:   &dnsmessage.NSResource{NS: ns},
+			}}
+		case q.Type == dnsmessage.TypeA && q.Name.String() == "ns1.example.net.":
+			return dnsmessage.RCodeSuccess, record(q, 3600)
+		case q.Type == dnsmessage.TypeA:
+			return dnsmessage.RCodeSuccess, record(q, 42)
+		}
+		return dnsmessage.RCodeSuccess, nil
+	})
+	authoritative := serveDNS(t, func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		if q.Type == dnsmessage.TypeA {
+			return dnsmessage.RCodeSuccess, record(q, 3600)
+		}
+		return dnsmessage.RCodeSuccess, nil
+	})
+
+	c := NewDNSChecker()
+	c.resolver = NewResolverFor(recursive)
+	var asked string
+	c.serverFor = func(ip string) *Resolver {
+		asked = ip
+		return NewResolverFor(authoritative)
+	}
+
+	report := AnalyzeTTLs(c.Answers("example.com"))
+	if asked != "192.0.2.1" {
+		t.Errorf("expected ns1.example.net at 192.0.2.1 to be queried, got %q", asked)
+	}
+	if report == nil || report.MaxTTL != 3600 {
+		t.Fatalf("expected the zone's TTL of 3600, got %+v", report)
+	}
+
+	// Without a reachable nameserver the resolver's answers are used.
+	c.serverFor = func(string) *Resolver { return NewResolverFor("127.0.0.1:1") }
+	c.SetTimeout(200 * time.Millisecond)
+	if report := AnalyzeTTLs(c.Answers("example.com")); report == nil || report.Records[0] != (RecordTTL{Type: "A", TTL: 42}) {
+		t.Errorf("expected the resolver's TTL as a fallback, got %+v", report)
+	}
+}
@@ -0,0 +1,367 @@
+package checker
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Record is a single resource record returned by a raw DNS query.
+type Record struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	TTL      uint32 `json:"ttl"`
+	Value    string `json:"value"`
+	Priority uint16 `json:"priority,omitempty"`
+}
+
+// Response is the parsed answer to a raw DNS query.
+type Response struct {
+	Rcode         string   `json:"rcode"`
+	Authoritative bool     `json:"authoritative"`
+	Answers       []Record `json:"answers,omitempty"`
+	Authority     []Record `json:"authority,omitempty"`
+	// SubnetScope is the EDNS Client Subnet scope prefix returned for a
+	// query sent WithClientSubnet, or -1 when the server did not echo the
+	// option. A scope above 0 means the answer was tailored to the subnet.
+	SubnetScope int `json:"subnet_scope"`
+}
+
+// Resolver sends DNS queries directly to a nameserver so that details the
+// net package hides (TTLs, rcodes, authority data) are available.
+type Resolver struct {
+	server      string
+	timeout     time.Duration
+	noRecursion bool
+	forceTCP    bool
+	subnet      *netip.Prefix
+}
+
+// NewResolver returns a resolver using the first nameserver from
+// /etc/resolv.conf, falling back to a public resolver.
+func NewResolver() *Resolver {
+	return &Resolver{
+		server:  systemNameserver(),
+		timeout: 5 * time.Second,
+	}
+}
+
+// NewResolverFor returns a resolver that queries the given server
+// ("host" or "host:port").
+func NewResolverFor(server string) *Resolver {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	return &Resolver{
+		server:  server,
+		timeout: 5 * time.Second,
+	}
+}
+
+// NonRecursive returns a copy of the resolver that clears the RD bit, for
+// querying authoritative servers directly.
+func (r *Resolver) NonRecursive() *Resolver {
+	c := *r
+	c.noRecursion = true
+	return &c
+}
+
+// OverTCP returns a copy of the resolver that always uses TCP.
+func (r *Resolver) OverTCP() *Resolver {
+	c := *r
+	c.forceTCP = true
+	return &c
+}
+
+// WithClientSubnet returns a copy of the resolver that sends an EDNS Client
+// Subnet option (RFC 7871), asking the server to answer as if the query
+// came from that network.
+func (r *Resolver) WithClientSubnet(subnet netip.Prefix) *Resolver {
+	c := *r
+	c.subnet = &subnet
+	return &c
+}
+
+// Server returns the nameserver address queries are sent to.
+func (r *Resolver) Server() string {
+	return r.server
+}
+
+var recordTypes = map[string]dnsmessage.Type{
+	"A":      dnsmessage.TypeA,
+	"AAAA":   dnsmessage.TypeAAAA,
+	"CNAME":  dnsmessage.TypeCNAME,
+	"MX":     dnsmessage.TypeMX,
+	"NS":     dnsmessage.TypeNS,
+	"SOA":    dnsmessage.TypeSOA,
+	"TXT":    dnsmessage.TypeTXT,
+	"SRV":    dnsmessage.TypeSRV,
+	"PTR":    dnsmessage.TypePTR,
+	"CAA":    dnsmessage.Type(257),
+	"DS":     dnsmessage.Type(43),
+	"DNSKEY": dnsmessage.Type(48),
+}
+
+// Query asks the resolver for records of the given type (e.g. "A", "MX").
+func (r *Resolver) Query(name, rrType string) (*Response, error) {
+	qtype, ok := recordTypes[strings.ToUpper(rrType)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported record type: %s", rrType)
+	}
+
+	fqdn, err := dnsmessage.NewName(dnsFQDN(name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid name %q: %v", name, err)
+	}
+
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:               uint16(rand.Intn(1 << 16)),
+			RecursionDesired: !r.noRecursion,
+		},
+		Questions: []dnsmessage.Question{{
+			Name:  fqdn,
+			Type:  qtype,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	if r.subnet != nil {
+		opt, err := clientSubnetOPT(*r.subnet)
+		if err != nil {
+			return nil, err
+		}
+		msg.Additionals = append(msg.Additionals, opt)
+	}
+	packed, err := msg.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %v", err)
+	}
+
+	reply, err := r.exchange(packed)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed dnsmessage.Message
+	if err := parsed.Unpack(reply); err != nil {
+		return nil, fmt.Errorf("failed to parse DNS response: %v", err)
+	}
+	if parsed.Header.ID != msg.Header.ID {
+		return nil, fmt.Errorf("DNS response ID mismatch")
+	}
+
+	resp := &Response{
+		Rcode:         rcodeName(parsed.Header.RCode),
+		Authoritative: parsed.Header.Authoritative,
+		SubnetScope:   subnetScope(parsed.Additionals),
+	}
+	for _, rr := range parsed.Answers {
+		resp.Answers = append(resp.Answers, toRecord(rr))
+	}
+	for _, rr := range parsed.Authorities {
+		resp.Authority = append(resp.Authority, toRecord(rr))
+	}
+	return resp, nil
+}
+
+// ednsClientSubnet is the EDNS option code for Client Subnet.
+const ednsClientSubnet = 8
+
+func clientSubnetOPT(subnet netip.Prefix) (dnsmessage.Resource, error) {
+	subnet = subnet.Masked()
+	family := uint16(1)
+	if subnet.Addr().Is6() {
+		family = 2
+	}
+	addr := subnet.Addr().AsSlice()[:(subnet.Bits()+7)/8]
+
+	data := make([]byte, 4, 4+len(addr))
+	binary.BigEndian.PutUint16(data, family)
+	data[2] = byte(subnet.Bits())
+	data = append(data, addr...)
+
+	var opt dnsmessage.Resource
+	opt.Header.Name = dnsmessage.MustNewName(".")
+	if err := opt.Header.SetEDNS0(4096, dnsmessage.RCodeSuccess, false); err != nil {
+		return opt, fmt.Errorf("failed to build EDNS option: %v", err)
+	}
+	opt.Body = &dnsmessage.OPTResource{Options: []dnsmessage.Option{{Code: ednsClientSubnet, Data: data}}}
+	return opt, nil
+}
+
+func subnetScope(additionals []dnsmessage.Resource) int {
+	for _, rr := range additionals {
+		opt, ok := rr.Body.(*dnsmessage.OPTResource)
+		if !ok {
+			continue
+		}
+		for _, o := range opt.Options {
+			if o.Code == ednsClientSubnet && len(o.Data) >= 4 {
+				return int(o.Data[3])
+			}
+		}
+	}
+	return -1
+}
+
+func (r *Resolver) exchange(query []byte) ([]byte, error) {
+	if r.forceTCP {
+		return r.exchangeTCP(query)
+	}
+
+	conn, err := net.DialTimeout("udp", r.server, r.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach resolver %s: %v", r.server, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(r.timeout))
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, fmt.Errorf("failed to send DNS query: %v", err)
+	}
+
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DNS response: %v", err)
+	}
+
+	// Retry over TCP when the answer was truncated.
+	if n > 2 && buf[2]&0x02 != 0 {
+		return r.exchangeTCP(query)
+	}
+	return buf[:n], nil
+}
+
+func (r *Resolver) exchangeTCP(query []byte) ([]byte, error) {
+	conn, err := net.DialTimeout("tcp", r.server, r.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach resolver %s: %v", r.server, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(r.timeout))
+
+	framed := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(framed, uint16(len(query)))
+	copy(framed[2:], query)
+	if _, err := conn.Write(framed); err != nil {
+		return nil, fmt.Errorf("failed to send DNS query: %v", err)
+	}
+
+	var length [2]byte
+	if _, err := readFull(conn, length[:]); err != nil {
+		return nil, fmt.Errorf("failed to read DNS response: %v", err)
+	}
+	reply := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := readFull(conn, reply); err != nil {
+		return nil, fmt.Errorf("failed to read DNS response: %v", err)
+	}
+	return reply, nil
+}
+
+func readFull(conn net.Conn, buf []byte) (int, error) {
+	read := 0
+	for read < len(buf) {
+		n, err := conn.Read(buf[read:])
+		read += n
+		if err != nil {
+			return read, err
+		}
+	}
+	return read, nil
+}
+
+func toRecord(rr dnsmessage.Resource) Record {
+	rec := Record{
+		Name: strings.TrimSuffix(rr.Header.Name.String(), "."),
+		Type: typeName(rr.Header.Type),
+		TTL:  rr.Header.TTL,
+	}
+
+	switch body := rr.Body.(type) {
+	case *dnsmessage.AResource:
+		rec.Value = net.IP(body.A[:]).String()
+	case *dnsmessage.AAAAResource:
+		rec.Value = net.IP(body.AAAA[:]).String()
+	case *dnsmessage.CNAMEResource:
+		rec.Value = strings.TrimSuffix(body.CNAME.String(), ".")
+	case *dnsmessage.MXResource:
+		rec.Value = strings.TrimSuffix(body.MX.String(), ".")
+		rec.Priority = body.Pref
+	case *dnsmessage.NSResource:
+		rec.Value = strings.TrimSuffix(body.NS.String(), ".")
+	case *dnsmessage.PTRResource:
+		rec.Value = strings.TrimSuffix(body.PTR.String(), ".")
+	case *dnsmessage.TXTResource:
+		rec.Value = strings.Join(body.TXT, "")
+	case *dnsmessage.SRVResource:
+		rec.Value = fmt.Sprintf("%d %d %s", body.Weight, body.Port, strings.TrimSuffix(body.Target.String(), "."))
+		rec.Priority = body.Priority
+	case *dnsmessage.SOAResource:
+		rec.Value = fmt.Sprintf("%s %s %d %d %d %d %d",
+			strings.TrimSuffix(body.NS.String(), "."),
+			strings.TrimSuffix(body.MBox.String(), "."),
+			body.Serial, body.Refresh, body.Retry, body.Expire, body.MinTTL)
+	case *dnsmessage.UnknownResource:
+		rec.Value = hex.EncodeToString(body.Data)
+	}
+	return rec
+}
+
+func typeName(t dnsmessage.Type) string {
+	for name, known := range recordTypes {
+		if known == t {
+			return name
+		}
+	}
+	return strings.TrimPrefix(t.String(), "Type")
+}
+
+func rcodeName(rc dnsmessage.RCode) string {
+	switch rc {
+	case dnsmessage.RCodeSuccess:
+		return "NOERROR"
+	case dnsmessage.RCodeNameError:
+		return "NXDOMAIN"
+	case dnsmessage.RCodeServerFailure:
+		return "SERVFAIL"
+	case dnsmessage.RCodeRefused:
+		return "REFUSED"
+	case dnsmessage.RCodeFormatError:
+		return "FORMERR"
+	case dnsmessage.RCodeNotImplemented:
+		return "NOTIMP"
+	}
+	return strings.TrimPrefix(rc.String(), "RCode")
+}
+
+func dnsFQDN(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+func systemNameserver() string {
+	f, err := os.Open("/etc/resolv.conf")
+	if err == nil {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) >= 2 && fields[0] == "nameserver" {
+				return net.JoinHostPort(fields[1], "53")
+			}
+		}
+	}
+	return "8.8.8.8:53"
+}
@@ -0,0 +1,173 @@
+package checker
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// serveDNS starts a UDP server answering every query with the resources
+// returned by answer. It returns the server address.
+func serveDNS(t *testing.T, answer func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource)) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) == 0 {
+				continue
+			}
+			rcode, answers := answer(query.Questions[0])
+			reply := dnsmessage.Message{
+				Header: dnsmessage.Header{
+					ID:       query.Header.ID,
+					Response: true,
+					RCode:    rcode,
+				},
+				Questions: query.Questions,
+				Answers:   answers,
+			}
+			packed, err := reply.Pack()
+			if err != nil {
+				continue
+			}
+			conn.WriteTo(packed, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestResolver_Query(t *testing.T) {
+	addr := serveDNS(t, func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		hdr := dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: 300}
+		switch q.Type {
+		case dnsmessage.TypeA:
+			hdr.Type = dnsmessage.TypeA
+			return dnsmessage.RCodeSuccess, []dnsmessage.Resource{{
+				Header: hdr,
+				Body:   &dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}},
+			}}
+		case dnsmessage.TypeMX:
+			hdr.Type = dnsmessage.TypeMX
+			return dnsmessage.RCodeSuccess, []dnsmessage.Resource{{
+				Header: hdr,
+				Body:   &dnsmessage.MXResource{Pref: 10, MX: dnsmessage.MustNewName("mail.example.com.")},
+			}}
+		}
+		return dnsmessage.RCodeNameError, nil
+	})
+
+	r := NewResolverFor(addr)
+
+	resp, err := r.Query("example.com", "A")
+	if err != nil {
+		t.Fatalf("A query: %v", err)
+	}
+	if len(resp.Answers) != 1 || resp.Answers[0].Value != "192.0.2.1" || resp.Answers[0].TTL != 300 {
+		t.Errorf("unexpected A answers: %+v", resp.Answers)
+	}
+
+	resp, err = r.Query("example.com", "MX")
+	if err != nil {
+		t.Fatalf("MX query: %v", err)
+	}
+	if len(resp.Answers) != 1 || resp.Answers[0].Value != "mail.example.com" || resp.Answers[0].Priority != 10 {
+		t.Errorf("unexpected MX answers: %+v", resp.Answers)
+	}
+
+	resp, err = r.Query("example.com", "TXT")
+	if err != nil {
+		t.Fatalf("TXT query: %v", err)
+	}
+	if resp.Rcode != "NXDOMAIN" {
+		t.Errorf("expected NXDOMAIN, got %s", resp.Rcode)
+	}
+
+	if _, err := r.Query("example.com", "BOGUS"); err == nil {
+		t.Error("expected error for unsupported record type")
+	}
+}
+
+func TestAnalyzeTTLs(t *testing.T) {
+	if AnalyzeTTLs(nil) != nil {
+		t.Error("expected nil report for no answers")
+	}
+
+	report := AnalyzeTTLs([]Record{
+		{Type: "A", TTL: 300},
+		{Type: "A", TTL: 120},
+		{Type: "MX", TTL: 86400},
+	})
+	if report.Ready {
+		t.Error("expected report not to be ready with a 1 day MX TTL")
+	}
+	if report.MaxTTL != 86400 {
+		t.Errorf("expected max TTL 86400, got %d", report.MaxTTL)
+	}
+	if report.SafeCutoverWindow != "24h0m0s" {
+		t.Errorf("unexpected cutover window %q", report.SafeCutoverWindow)
+	}
+	if len(report.Records) != 2 || report.Records[0].TTL != 300 || report.Records[1].Flag != "very high" {
+		t.Errorf("unexpected records: %+v", report.Records)
+	}
+
+	if !AnalyzeTTLs([]Record{{Type: "A", TTL: 60}}).Ready {
+		t.Error("expected low TTLs to be ready")
+	}
+}
+
+func TestResultFromAnswers(t *testing.T) {
+	result := ResultFromAnswers("example.com", []Record{
+		{Name: "example.com", Type: "AAAA", TTL: 300, Value: "2001:db8::1"},
+		{Name: "example.com", Type: "NS", TTL: 86400, Value: "ns1.example.net"},
+	}, true)
+
+	if result.Available || !result.HasRecords {
+		t.Errorf("expected a registered domain, got %+v", result)
+	}
+	if len(result.RecordTypes) != 2 || result.RecordTypes[0] != "A" || result.RecordTypes[1] != "NS" {
+		t.Errorf("unexpected record types: %v", result.RecordTypes)
+	}
+	if result.TTLReport == nil {
+		t.Error("expected TTL report")
+	}
+
+	if empty := ResultFromAnswers("example.com", nil, false); !empty.Available {
+		t.Error("expected a domain without answers to be available")
+	}
+}
+
+func TestClientSubnetOPT(t *testing.T) {
+	opt, err := clientSubnetOPT(netip.MustParsePrefix("80.128.7.9/24"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data := opt.Body.(*dnsmessage.OPTResource).Options[0].Data
+	// family 1, source /24, scope 0, then the three network bytes.
+	want := []byte{0, 1, 24, 0, 80, 128, 7}
+	if string(data) != string(want) {
+		t.Errorf("ECS option = %v, want %v", data, want)
+	}
+
+	echoed := dnsmessage.Resource{Body: &dnsmessage.OPTResource{Options: []dnsmessage.Option{{Code: 8, Data: []byte{0, 1, 24, 20, 80, 128, 7}}}}}
+	if scope := subnetScope([]dnsmessage.Resource{echoed}); scope != 20 {
+		t.Errorf("subnetScope = %d, want 20", scope)
+	}
+	if scope := subnetScope(nil); scope != -1 {
+		t.Errorf("subnetScope without OPT = %d, want -1", scope)
+	}
+}
@@ -0,0 +1,90 @@
+package checker
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TTL thresholds used to flag records that would slow down a migration.
+const (
+	HighTTL     = 3600  // 1 hour
+	VeryHighTTL = 86400 // 1 day
+
+	// RecommendedCutoverTTL is the TTL records should be lowered to before
+	// re-pointing a domain.
+	RecommendedCutoverTTL = 300
+)
+
+// TTLReport summarizes how quickly changes to a domain's key records will
+// propagate through resolver caches.
+type TTLReport struct {
+	Records []RecordTTL `json:"records"`
+	// MaxTTL is the highest TTL among the key records, in seconds.
+	MaxTTL uint32 `json:"max_ttl"`
+	// SafeCutoverWindow is how long to wait after lowering TTLs before
+	// changing records, so every cached copy has expired.
+	SafeCutoverWindow string   `json:"safe_cutover_window"`
+	Ready             bool     `json:"ready"`
+	Recommendations   []string `json:"recommendations,omitempty"`
+}
+
+// RecordTTL is the TTL observed for one record type.
+type RecordTTL struct {
+	Type string `json:"type"`
+	TTL  uint32 `json:"ttl"`
+	Flag string `json:"flag,omitempty"`
+}
+
+// AnalyzeTTLs builds a TTL report from captured answers. It returns nil if
+// there are no answers to analyze.
+func AnalyzeTTLs(answers []Record) *TTLReport {
+	if len(answers) == 0 {
+		return nil
+	}
+
+	// Use the highest TTL seen for each record type.
+	byType := make(map[string]uint32)
+	for _, rec := range answers {
+		if ttl, seen := byType[rec.Type]; !seen || rec.TTL > ttl {
+			byType[rec.Type] = rec.TTL
+		}
+	}
+
+	report := &TTLReport{Ready: true}
+	for rrType, ttl := range byType {
+		entry := RecordTTL{Type: rrType, TTL: ttl}
+		switch {
+		case ttl >= VeryHighTTL:
+			entry.Flag = "very high"
+		case ttl >= HighTTL:
+			entry.Flag = "high"
+		}
+		if ttl > RecommendedCutoverTTL {
+			report.Ready = false
+			report.Recommendations = append(report.Recommendations,
+				fmt.Sprintf("Lower %s TTL from %s to %ds before cutover", rrType, formatTTL(ttl), RecommendedCutoverTTL))
+		}
+		if ttl > report.MaxTTL {
+			report.MaxTTL = ttl
+		}
+		report.Records = append(report.Records, entry)
+	}
+
+	sort.Slice(report.Records, func(i, j int) bool {
+		return report.Records[i].Type < report.Records[j].Type
+	})
+	sort.Strings(report.Recommendations)
+
+	report.SafeCutoverWindow = formatTTL(report.MaxTTL)
+	if !report.Ready {
+		report.Recommendations = append(report.Recommendations,
+			fmt.Sprintf("After lowering TTLs, wait at least %s before re-pointing records", report.SafeCutoverWindow))
+	}
+
+	return report
+}
+
+func formatTTL(ttl uint32) string {
+	return (time.Duration(ttl) * time.Second).String()
+}
@@ -0,0 +1,79 @@
+package cloud
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"d3-domain-tool/internal/iac"
+)
+
+// AzureDNS lists the public DNS zones of an Azure subscription. The token
+// comes from AZURE_ACCESS_TOKEN or `az account get-access-token`; the
+// subscription from AZURE_SUBSCRIPTION_ID or the az CLI's current account.
+type AzureDNS struct {
+	endpoint     string
+	token        func() (string, error)
+	subscription func() (string, error)
+}
+
+func NewAzureDNS() *AzureDNS {
+	return &AzureDNS{
+		endpoint: "https://management.azure.com",
+		token: func() (string, error) {
+			return cliToken("AZURE_ACCESS_TOKEN", "az", "account", "get-access-token", "--query", "accessToken", "-o", "tsv")
+		},
+		subscription: azureSubscription,
+	}
+}
+
+func (a *AzureDNS) Name() string { return "azure" }
+
+func (a *AzureDNS) ListZones() ([]iac.Zone, error) {
+	token, err := a.token()
+	if err != nil {
+		return nil, err
+	}
+	subscription, err := a.subscription()
+	if err != nil {
+		return nil, err
+	}
+
+	var zones []iac.Zone
+	u := a.endpoint + "/subscriptions/" + subscription + "/providers/Microsoft.Network/dnszones?api-version=2018-05-01"
+	for u != "" {
+		var page struct {
+			Value []struct {
+				Name       string `json:"name"`
+				Properties struct {
+					NameServers []string `json:"nameServers"`
+					ZoneType    string   `json:"zoneType"`
+				} `json:"properties"`
+			} `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := getJSON(u, token, &page); err != nil {
+			return nil, fmt.Errorf("DNS zones list failed: %v", err)
+		}
+		for _, z := range page.Value {
+			if strings.EqualFold(z.Properties.ZoneType, "Private") {
+				continue
+			}
+			zones = append(zones, iac.Zone{Name: zoneName(z.Name), NameServers: z.Properties.NameServers, Source: a.Name()})
+		}
+		u = page.NextLink
+	}
+	return zones, nil
+}
+
+func azureSubscription() (string, error) {
+	if s := os.Getenv("AZURE_SUBSCRIPTION_ID"); s != "" {
+		return s, nil
+	}
+	out, err := exec.Command("az", "account", "show", "--query", "id", "-o", "tsv").Output()
+	if s := strings.TrimSpace(string(out)); err == nil && s != "" {
+		return s, nil
+	}
+	return "", fmt.Errorf("no Azure subscription: set AZURE_SUBSCRIPTION_ID")
+}
@@ -0,0 +1,96 @@
+// Package cloud lists hosted DNS zones from cloud providers (Route 53,
+// Google Cloud DNS, Azure DNS, Cloudflare) with read-only API calls, using the
+// credentials the providers' own CLIs and SDKs use.
+package cloud
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/iac"
+)
+
+// Lister lists the public hosted zones of one provider account.
+type Lister interface {
+	Name() string
+	ListZones() ([]iac.Zone, error)
+}
+
+// ParseProviders parses a comma-separated provider list: route53 (or aws),
+// clouddns (or gcp), azure, cloudflare.
+func ParseProviders(spec string) ([]Lister, error) {
+	var listers []Lister
+	for _, name := range strings.Split(spec, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "":
+			continue
+		case "route53", "aws":
+			listers = append(listers, NewRoute53())
+		case "clouddns", "gcp", "google":
+			listers = append(listers, NewCloudDNS())
+		case "azure", "azuredns":
+			listers = append(listers, NewAzureDNS())
+		case "cloudflare":
+			listers = append(listers, NewCloudflare(""))
+		default:
+			return nil, fmt.Errorf("unknown DNS provider %q (want route53, clouddns, azure or cloudflare)", name)
+		}
+	}
+	return listers, nil
+}
+
+// Domains returns the sorted, de-duplicated zone names of all providers.
+// Zones below another listed zone (delegated subzones) are dropped.
+func Domains(listers []Lister) ([]string, error) {
+	seen := map[string]bool{}
+	for _, l := range listers {
+		zones, err := l.ListZones()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", l.Name(), err)
+		}
+		for _, z := range zones {
+			seen[z.Name] = true
+		}
+	}
+
+	var domains []string
+	for name := range seen {
+		sub := false
+		for parent := name; strings.Contains(parent, "."); {
+			_, parent, _ = strings.Cut(parent, ".")
+			if seen[parent] {
+				sub = true
+				break
+			}
+		}
+		if !sub {
+			domains = append(domains, name)
+		}
+	}
+	sort.Strings(domains)
+	return domains, nil
+}
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// cliToken returns the bearer token in env, or asks the provider CLI for
+// one.
+func cliToken(env string, cli ...string) (string, error) {
+	if token := os.Getenv(env); token != "" {
+		return token, nil
+	}
+	out, err := exec.Command(cli[0], cli[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("no %s set and `%s` failed: %v", env, strings.Join(cli, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func zoneName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+}
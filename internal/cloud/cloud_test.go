@@ -0,0 +1,185 @@
+package cloud
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/iac"
+)
+
+type staticLister []iac.Zone
+
+func (s staticLister) Name() string                   { return "static" }
+func (s staticLister) ListZones() ([]iac.Zone, error) { return s, nil }
+
+func TestDomains(t *testing.T) {
+	domains, err := Domains([]Lister{
+		staticLister{{Name: "example.com"}, {Name: "dev.example.com"}},
+		staticLister{{Name: "example.org"}, {Name: "example.com"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(domains, ",") != "example.com,example.org" {
+		t.Errorf("unexpected domains %v", domains)
+	}
+}
+
+func TestParseProviders(t *testing.T) {
+	listers, err := ParseProviders("route53, gcp,azure,cloudflare")
+	if err != nil || len(listers) != 4 {
+		t.Fatalf("unexpected result %v, %v", listers, err)
+	}
+	if _, err := ParseProviders("digitalocean"); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
+
+func TestRoute53(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240102/us-east-1/route53/aws4_request") {
+			t.Errorf("unexpected Authorization %q", auth)
+		}
+		if r.URL.Query().Get("marker") == "" {
+			w.Write([]byte(`<ListHostedZonesResponse><HostedZones>
+<HostedZone><Name>example.com.</Name><Config><PrivateZone>false</PrivateZone></Config></HostedZone>
+<HostedZone><Name>internal.example.com.</Name><Config><PrivateZone>true</PrivateZone></Config></HostedZone>
+</HostedZones><IsTruncated>true</IsTruncated><NextMarker>Z2</NextMarker></ListHostedZonesResponse>`))
+			return
+		}
+		w.Write([]byte(`<ListHostedZonesResponse><HostedZones>
+<HostedZone><Name>Example.NET.</Name></HostedZone>
+</HostedZones><IsTruncated>false</IsTruncated></ListHostedZonesResponse>`))
+	}))
+	defer srv.Close()
+
+	r := &Route53{endpoint: srv.URL, now: func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }}
+	zones, err := r.ListZones()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zones) != 2 || zones[0].Name != "example.com" || zones[1].Name != "example.net" {
+		t.Errorf("unexpected zones %+v", zones)
+	}
+}
+
+func TestSignV4(t *testing.T) {
+	// get-vanilla from the AWS SigV4 test suite.
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	creds := &awsCredentials{accessKey: "AKIDEXAMPLE", secretKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signV4(req, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("unexpected Authorization\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestCloudDNSAndAzure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/projects/demo/managedZones"):
+			w.Write([]byte(`{"managedZones": [
+				{"dnsName": "example.io.", "nameServers": ["ns-cloud-a1.googledomains.com."], "visibility": "public"},
+				{"dnsName": "corp.internal.", "visibility": "private"}]}`))
+		case strings.HasPrefix(r.URL.Path, "/subscriptions/sub/"):
+			w.Write([]byte(`{"value": [
+				{"name": "example.dev", "properties": {"nameServers": ["ns1-01.azure-dns.com."], "zoneType": "Public"}},
+				{"name": "vnet.local", "properties": {"zoneType": "Private"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	token := func() (string, error) { return "token", nil }
+	g := &CloudDNS{endpoint: srv.URL, token: token, project: func() (string, error) { return "demo", nil }}
+	zones, err := g.ListZones()
+	if err != nil || len(zones) != 1 || zones[0].Name != "example.io" || len(zones[0].NameServers) != 1 {
+		t.Errorf("unexpected Cloud DNS zones %+v, %v", zones, err)
+	}
+
+	a := &AzureDNS{endpoint: srv.URL, token: token, subscription: func() (string, error) { return "sub", nil }}
+	zones, err = a.ListZones()
+	if err != nil || len(zones) != 1 || zones[0].Name != "example.dev" {
+		t.Errorf("unexpected Azure zones %+v, %v", zones, err)
+	}
+}
+
+func TestCloudflareAccount(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer cf-token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"success": false, "errors": [{"code": 9109, "message": "Invalid access token"}]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/zones":
+			if r.URL.Query().Get("page") == "1" {
+				w.Write([]byte(`{"success": true, "result": [
+					{"id": "z1", "name": "example.com", "status": "active", "name_servers": ["ada.ns.cloudflare.com", "bob.ns.cloudflare.com"], "account": {"id": "acc"}, "plan": {"name": "Free Website"}},
+					{"id": "z2", "name": "example.net", "status": "pending", "name_servers": ["ada.ns.cloudflare.com"], "account": {"id": "acc"}}],
+					"result_info": {"page": 1, "total_pages": 2}}`))
+				return
+			}
+			w.Write([]byte(`{"success": true, "result": [
+				{"id": "z3", "name": "example.org", "status": "moved", "account": {"id": "acc"}}],
+				"result_info": {"page": 2, "total_pages": 2}}`))
+		case "/accounts/acc/registrar/domains":
+			w.Write([]byte(`{"success": true, "result": [
+				{"name": "example.com", "current_registrar": "Cloudflare", "expires_at": "2027-03-01T00:00:00Z", "locked": true, "auto_renew": true}]}`))
+		case "/zones/z1/dns_records":
+			w.Write([]byte(`{"success": true, "result": [
+				{"type": "A", "name": "example.com", "content": "192.0.2.1", "ttl": 1, "proxied": true},
+				{"type": "MX", "name": "example.com", "content": "mx.example.com", "ttl": 300}]}`))
+		case "/zones/z2/dns_records", "/zones/z3/dns_records":
+			w.Write([]byte(`{"success": true, "result": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"success": false, "errors": [{"code": 7003, "message": "No route"}]}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := &Cloudflare{endpoint: srv.URL, token: "cf-token"}
+	report, err := c.Account()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Zones) != 3 || report.Active != 1 || report.Pending != 1 || report.Moved != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	com := report.Zones[0]
+	if com.Registration == nil || !com.Registration.Locked || com.Registration.ExpiresAt == nil || len(com.Records) != 2 || !com.Records[0].Proxied {
+		t.Errorf("unexpected zone %+v", com)
+	}
+	if len(com.Notes) != 0 || len(report.Zones[1].Notes) != 1 || len(report.Zones[2].Notes) != 1 {
+		t.Errorf("unexpected notes %v / %v / %v", com.Notes, report.Zones[1].Notes, report.Zones[2].Notes)
+	}
+
+	com.CheckDelegation([]string{"ADA.NS.CLOUDFLARE.COM", "bob.ns.cloudflare.com."})
+	if len(com.Notes) != 0 {
+		t.Errorf("matching delegation noted: %v", com.Notes)
+	}
+	com.CheckDelegation([]string{"ns1.registrar-servers.com"})
+	if len(com.Notes) != 1 {
+		t.Errorf("expected a delegation note, got %v", com.Notes)
+	}
+
+	c.token = "wrong"
+	if _, err := c.Account(); err == nil || !strings.Contains(err.Error(), "Invalid access token") {
+		t.Errorf("expected the API error, got %v", err)
+	}
+}
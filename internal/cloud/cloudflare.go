@@ -0,0 +1,303 @@
+package cloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/iac"
+	"d3-domain-tool/internal/policy"
+)
+
+// Cloudflare zone statuses that need attention.
+const (
+	// ZonePending means the zone was added but the registrar still
+	// delegates the domain to other nameservers.
+	ZonePending = "pending"
+	// ZoneMoved means the domain's nameservers no longer point to
+	// Cloudflare; the zone is deleted after a grace period.
+	ZoneMoved = "moved"
+)
+
+// Cloudflare reads the zones, Cloudflare Registrar registrations and DNS
+// records of an account with an API token (Zone:Read, DNS:Read and, for
+// registrar data, Account Registrar:Read).
+type Cloudflare struct {
+	endpoint string
+	token    string
+}
+
+// NewCloudflare creates a client for token, or for CLOUDFLARE_API_TOKEN
+// when token is empty.
+func NewCloudflare(token string) *Cloudflare {
+	if token == "" {
+		token = os.Getenv("CLOUDFLARE_API_TOKEN")
+	}
+	return &Cloudflare{endpoint: "https://api.cloudflare.com/client/v4", token: token}
+}
+
+func (c *Cloudflare) Name() string { return "cloudflare" }
+
+// CloudflareZone is a zone of the account with its registration and
+// records.
+type CloudflareZone struct {
+	ID                  string                  `json:"id"`
+	Name                string                  `json:"name"`
+	Status              string                  `json:"status"`
+	Paused              bool                    `json:"paused,omitempty"`
+	Plan                string                  `json:"plan,omitempty"`
+	Account             string                  `json:"account,omitempty"`
+	NameServers         []string                `json:"name_servers,omitempty"`
+	OriginalNameServers []string                `json:"original_name_servers,omitempty"`
+	OriginalRegistrar   string                  `json:"original_registrar,omitempty"`
+	Registration        *CloudflareRegistration `json:"registration,omitempty"`
+	Records             []DNSRecord             `json:"records,omitempty"`
+	RecordsError        string                  `json:"records_error,omitempty"`
+	Notes               []string                `json:"notes,omitempty"`
+	Analysis            *analyzer.Result        `json:"analysis,omitempty"`
+}
+
+// CloudflareRegistration is a domain registered through Cloudflare
+// Registrar.
+type CloudflareRegistration struct {
+	CurrentRegistrar string     `json:"current_registrar,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	Locked           bool       `json:"locked"`
+	AutoRenew        bool       `json:"auto_renew"`
+	RegistryStatuses string     `json:"registry_statuses,omitempty"`
+}
+
+// DNSRecord is one record of a zone.
+type DNSRecord struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	TTL     int    `json:"ttl"`
+	Proxied bool   `json:"proxied,omitempty"`
+}
+
+// CloudflareReport is the inventory of one API token's zones.
+type CloudflareReport struct {
+	Zones         []*CloudflareZone `json:"zones"`
+	Active        int               `json:"active"`
+	Pending       int               `json:"pending"`
+	Moved         int               `json:"moved"`
+	RegistrarNote string            `json:"registrar_note,omitempty"`
+	Policy        *policy.Report    `json:"policy,omitempty"`
+	CheckedAt     time.Time         `json:"checked_at"`
+}
+
+// ListZones returns the account's zones for -zones-from; moved zones are
+// included since the domains are usually still owned.
+func (c *Cloudflare) ListZones() ([]iac.Zone, error) {
+	zones, err := c.zones()
+	if err != nil {
+		return nil, err
+	}
+	var out []iac.Zone
+	for _, z := range zones {
+		out = append(out, iac.Zone{Name: z.Name, NameServers: z.NameServers, Source: c.Name()})
+	}
+	return out, nil
+}
+
+// Account fetches every zone with its DNS records and, where the token
+// allows it, its Cloudflare Registrar registration. Pending and moved zones
+// get a note explaining what is wrong.
+func (c *Cloudflare) Account() (*CloudflareReport, error) {
+	zones, err := c.zones()
+	if err != nil {
+		return nil, err
+	}
+	report := &CloudflareReport{Zones: zones, CheckedAt: time.Now()}
+
+	registrations := map[string]*CloudflareRegistration{}
+	accounts := map[string]bool{}
+	for _, z := range zones {
+		if z.Account == "" || accounts[z.Account] {
+			continue
+		}
+		accounts[z.Account] = true
+		regs, err := c.registrations(z.Account)
+		if err != nil {
+			report.RegistrarNote = "registrar data unavailable: " + err.Error()
+			continue
+		}
+		for name, reg := range regs {
+			registrations[name] = reg
+		}
+	}
+
+	for _, z := range zones {
+		z.Registration = registrations[z.Name]
+		if z.Records, err = c.records(z.ID); err != nil {
+			z.RecordsError = err.Error()
+		}
+		switch z.Status {
+		case ZonePending:
+			report.Pending++
+			z.Notes = append(z.Notes, fmt.Sprintf("not active: set the nameservers at the registrar to %s", strings.Join(z.NameServers, ", ")))
+		case ZoneMoved:
+			report.Moved++
+			z.Notes = append(z.Notes, "nameservers no longer point to Cloudflare; the zone will be deleted and its records stop being served")
+		case "active":
+			report.Active++
+		default:
+			z.Notes = append(z.Notes, "zone status is "+z.Status)
+		}
+		if z.Paused {
+			z.Notes = append(z.Notes, "paused: traffic bypasses Cloudflare")
+		}
+	}
+	return report, nil
+}
+
+// CheckDelegation notes when an active zone's registry delegation
+// (nameServers, e.g. from WHOIS) does not point to the zone's Cloudflare
+// nameservers, i.e. the zone is about to become moved.
+func (z *CloudflareZone) CheckDelegation(nameServers []string) {
+	if z.Status != "active" || len(nameServers) == 0 || len(z.NameServers) == 0 {
+		return
+	}
+	assigned := map[string]bool{}
+	for _, ns := range z.NameServers {
+		assigned[zoneName(ns)] = true
+	}
+	for _, ns := range nameServers {
+		if !assigned[zoneName(ns)] {
+			z.Notes = append(z.Notes, fmt.Sprintf("registry delegates to %s instead of the Cloudflare nameservers", strings.ToLower(strings.Join(nameServers, ", "))))
+			return
+		}
+	}
+}
+
+type cfResponse struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Result     json.RawMessage `json:"result"`
+	ResultInfo struct {
+		Page       int `json:"page"`
+		TotalPages int `json:"total_pages"`
+	} `json:"result_info"`
+}
+
+// get fetches every page of a list endpoint, calling add with each page's
+// result.
+func (c *Cloudflare) get(path string, add func(json.RawMessage) error) error {
+	if c.token == "" {
+		return fmt.Errorf("no Cloudflare API token: set CLOUDFLARE_API_TOKEN or -token")
+	}
+	for page := 1; ; page++ {
+		q := url.Values{"page": {fmt.Sprint(page)}, "per_page": {"50"}}
+		req, err := http.NewRequest(http.MethodGet, c.endpoint+path+"?"+q.Encode(), nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token)
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		var body cfResponse
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("invalid response from %s (HTTP %d): %v", path, resp.StatusCode, err)
+		}
+		if !body.Success {
+			var msgs []string
+			for _, e := range body.Errors {
+				msgs = append(msgs, fmt.Sprintf("%d %s", e.Code, e.Message))
+			}
+			return fmt.Errorf("%s: HTTP %d: %s", path, resp.StatusCode, strings.Join(msgs, "; "))
+		}
+		if err := add(body.Result); err != nil {
+			return fmt.Errorf("invalid result from %s: %v", path, err)
+		}
+		if body.ResultInfo.TotalPages <= page {
+			return nil
+		}
+	}
+}
+
+func (c *Cloudflare) zones() ([]*CloudflareZone, error) {
+	var zones []*CloudflareZone
+	err := c.get("/zones", func(raw json.RawMessage) error {
+		var page []struct {
+			ID                  string   `json:"id"`
+			Name                string   `json:"name"`
+			Status              string   `json:"status"`
+			Paused              bool     `json:"paused"`
+			NameServers         []string `json:"name_servers"`
+			OriginalNameServers []string `json:"original_name_servers"`
+			OriginalRegistrar   string   `json:"original_registrar"`
+			Plan                struct {
+				Name string `json:"name"`
+			} `json:"plan"`
+			Account struct {
+				ID string `json:"id"`
+			} `json:"account"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		for _, z := range page {
+			zones = append(zones, &CloudflareZone{
+				ID:                  z.ID,
+				Name:                zoneName(z.Name),
+				Status:              z.Status,
+				Paused:              z.Paused,
+				Plan:                z.Plan.Name,
+				Account:             z.Account.ID,
+				NameServers:         z.NameServers,
+				OriginalNameServers: z.OriginalNameServers,
+				OriginalRegistrar:   z.OriginalRegistrar,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Cloudflare zones: %v", err)
+	}
+	return zones, nil
+}
+
+func (c *Cloudflare) registrations(account string) (map[string]*CloudflareRegistration, error) {
+	regs := map[string]*CloudflareRegistration{}
+	err := c.get("/accounts/"+url.PathEscape(account)+"/registrar/domains", func(raw json.RawMessage) error {
+		var page []struct {
+			Name string `json:"name"`
+			CloudflareRegistration
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		for i := range page {
+			regs[zoneName(page[i].Name)] = &page[i].CloudflareRegistration
+		}
+		return nil
+	})
+	return regs, err
+}
+
+func (c *Cloudflare) records(zoneID string) ([]DNSRecord, error) {
+	var records []DNSRecord
+	err := c.get("/zones/"+url.PathEscape(zoneID)+"/dns_records", func(raw json.RawMessage) error {
+		var page []DNSRecord
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		records = append(records, page...)
+		return nil
+	})
+	return records, err
+}
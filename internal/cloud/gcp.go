@@ -0,0 +1,107 @@
+package cloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"d3-domain-tool/internal/iac"
+)
+
+// CloudDNS lists the public managed zones of a Google Cloud project. The
+// token comes from GOOGLE_OAUTH_ACCESS_TOKEN or `gcloud auth
+// print-access-token`; the project from GOOGLE_CLOUD_PROJECT or the gcloud
+// configuration.
+type CloudDNS struct {
+	endpoint string
+	token    func() (string, error)
+	project  func() (string, error)
+}
+
+func NewCloudDNS() *CloudDNS {
+	return &CloudDNS{
+		endpoint: "https://dns.googleapis.com/dns/v1",
+		token: func() (string, error) {
+			return cliToken("GOOGLE_OAUTH_ACCESS_TOKEN", "gcloud", "auth", "print-access-token")
+		},
+		project: gcpProject,
+	}
+}
+
+func (c *CloudDNS) Name() string { return "clouddns" }
+
+func (c *CloudDNS) ListZones() ([]iac.Zone, error) {
+	token, err := c.token()
+	if err != nil {
+		return nil, err
+	}
+	project, err := c.project()
+	if err != nil {
+		return nil, err
+	}
+
+	var zones []iac.Zone
+	pageToken := ""
+	for {
+		u := c.endpoint + "/projects/" + url.PathEscape(project) + "/managedZones"
+		if pageToken != "" {
+			u += "?pageToken=" + url.QueryEscape(pageToken)
+		}
+		var page struct {
+			ManagedZones []struct {
+				DNSName     string   `json:"dnsName"`
+				NameServers []string `json:"nameServers"`
+				Visibility  string   `json:"visibility"`
+			} `json:"managedZones"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := getJSON(u, token, &page); err != nil {
+			return nil, fmt.Errorf("managedZones.list failed: %v", err)
+		}
+		for _, z := range page.ManagedZones {
+			if z.Visibility == "private" {
+				continue
+			}
+			zones = append(zones, iac.Zone{Name: zoneName(z.DNSName), NameServers: z.NameServers, Source: c.Name()})
+		}
+		if page.NextPageToken == "" {
+			return zones, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+func gcpProject() (string, error) {
+	for _, env := range []string{"GOOGLE_CLOUD_PROJECT", "CLOUDSDK_CORE_PROJECT"} {
+		if p := os.Getenv(env); p != "" {
+			return p, nil
+		}
+	}
+	out, err := exec.Command("gcloud", "config", "get-value", "project").Output()
+	if p := strings.TrimSpace(string(out)); err == nil && p != "" {
+		return p, nil
+	}
+	return "", fmt.Errorf("no Google Cloud project: set GOOGLE_CLOUD_PROJECT")
+}
+
+// getJSON GETs u with a bearer token and decodes the JSON response.
+func getJSON(u, token string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
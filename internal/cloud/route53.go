@@ -0,0 +1,199 @@
+package cloud
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/iac"
+)
+
+// Route53 lists hosted zones with the AWS credentials from the environment
+// (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN) or the
+// shared credentials file (AWS_PROFILE, default "default").
+type Route53 struct {
+	endpoint string
+	now      func() time.Time
+}
+
+func NewRoute53() *Route53 {
+	return &Route53{endpoint: "https://route53.amazonaws.com", now: time.Now}
+}
+
+func (r *Route53) Name() string { return "route53" }
+
+type awsCredentials struct {
+	accessKey, secretKey, sessionToken string
+}
+
+func (r *Route53) ListZones() ([]iac.Zone, error) {
+	creds, err := loadAWSCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	var zones []iac.Zone
+	marker := ""
+	for {
+		u := r.endpoint + "/2013-04-01/hostedzone"
+		if marker != "" {
+			u += "?marker=" + url.QueryEscape(marker)
+		}
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		signV4(req, creds, "us-east-1", "route53", r.now().UTC())
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("ListHostedZones failed: %v", err)
+		}
+		var page struct {
+			HostedZones []struct {
+				Name   string `xml:"Name"`
+				Config struct {
+					PrivateZone bool `xml:"PrivateZone"`
+				} `xml:"Config"`
+			} `xml:"HostedZones>HostedZone"`
+			IsTruncated bool   `xml:"IsTruncated"`
+			NextMarker  string `xml:"NextMarker"`
+			Message     string `xml:"Error>Message"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("ListHostedZones returned HTTP %d: %s", resp.StatusCode, page.Message)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid ListHostedZones response: %v", err)
+		}
+
+		for _, z := range page.HostedZones {
+			if !z.Config.PrivateZone {
+				zones = append(zones, iac.Zone{Name: zoneName(z.Name), Source: r.Name()})
+			}
+		}
+		if !page.IsTruncated || page.NextMarker == "" {
+			return zones, nil
+		}
+		marker = page.NextMarker
+	}
+}
+
+func loadAWSCredentials() (*awsCredentials, error) {
+	if key := os.Getenv("AWS_ACCESS_KEY_ID"); key != "" {
+		return &awsCredentials{
+			accessKey:    key,
+			secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("no AWS credentials: %v", err)
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("no AWS credentials in the environment or %s", path)
+	}
+	defer f.Close()
+
+	creds := &awsCredentials{}
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section != profile {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "aws_access_key_id":
+			creds.accessKey = strings.TrimSpace(value)
+		case "aws_secret_access_key":
+			creds.secretKey = strings.TrimSpace(value)
+		case "aws_session_token":
+			creds.sessionToken = strings.TrimSpace(value)
+		}
+	}
+	if creds.accessKey == "" || creds.secretKey == "" {
+		return nil, fmt.Errorf("no credentials for AWS profile %q in %s", profile, path)
+	}
+	return creds, nil
+}
+
+// signV4 adds an AWS Signature Version 4 Authorization header to a
+// bodiless request.
+func signV4(req *http.Request, creds *awsCredentials, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex("")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.sessionToken)
+	}
+
+	signed := []string{"host", "x-amz-date"}
+	headers := "host:" + req.URL.Host + "\nx-amz-date:" + amzDate + "\n"
+	if creds.sessionToken != "" {
+		signed = append(signed, "x-amz-security-token")
+		headers += "x-amz-security-token:" + creds.sessionToken + "\n"
+	}
+	signedHeaders := strings.Join(signed, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonical := strings.Join([]string{
+		req.Method, path, req.URL.Query().Encode(), headers, signedHeaders, payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex(canonical)
+
+	key := hmacSHA256([]byte("AWS4"+creds.secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,168 @@
+// Package collateral estimates how much can be borrowed against a
+// tokenized domain on lending platforms.
+package collateral
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/doma"
+	"d3-domain-tool/internal/valuation"
+)
+
+// DOMALending is the platform name used for DOMA Lending's live terms.
+const DOMALending = "DOMA Lending"
+
+// Sources of platform terms.
+const (
+	SourceConfigured = "configured"
+	SourceLive       = "live"
+)
+
+// confidenceHaircut discounts the estimate by how reliable it is, as a
+// lender's appraisal would.
+var confidenceHaircut = map[string]float64{
+	"high":   1.0,
+	"medium": 0.85,
+	"low":    0.7,
+}
+
+// Platform is a lending platform's terms for domain collateral.
+type Platform struct {
+	Name string `json:"name"`
+	// LTV is the maximum loan-to-value ratio, e.g. 0.4.
+	LTV                  float64 `json:"ltv"`
+	LiquidationThreshold float64 `json:"liquidation_threshold,omitempty"`
+	APR                  float64 `json:"apr,omitempty"`
+	Source               string  `json:"source"`
+}
+
+// Quote is what one platform would lend against the domain.
+type Quote struct {
+	Platform
+	MaxBorrow int `json:"max_borrow"`
+	// LiquidationValue is the collateral value at which a maximum loan
+	// would be liquidated.
+	LiquidationValue int `json:"liquidation_value,omitempty"`
+	YearlyInterest   int `json:"yearly_interest,omitempty"`
+}
+
+// Report holds the quotes for one domain.
+type Report struct {
+	Domain          string    `json:"domain"`
+	EstimatedValue  int       `json:"estimated_value"`
+	Confidence      string    `json:"confidence,omitempty"`
+	CollateralValue int       `json:"collateral_value"`
+	Quotes          []Quote   `json:"quotes"`
+	Notes           []string  `json:"notes,omitempty"`
+	CheckedAt       time.Time `json:"checked_at"`
+}
+
+// ParsePlatforms parses "name=ltv[/apr],..." platform terms, e.g.
+// "NFTfi=0.3/0.15,Arcade=0.25".
+func ParsePlatforms(spec string) ([]Platform, error) {
+	var platforms []Platform
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, terms, ok := strings.Cut(item, "=")
+		ltvStr, aprStr, hasAPR := strings.Cut(terms, "/")
+		ltv, err := strconv.ParseFloat(ltvStr, 64)
+		if !ok || name == "" || err != nil || ltv <= 0 || ltv > 1 {
+			return nil, fmt.Errorf("invalid platform %q: expected name=ltv[/apr] with 0 < ltv <= 1", item)
+		}
+		p := Platform{Name: strings.TrimSpace(name), LTV: ltv, Source: SourceConfigured}
+		if hasAPR {
+			if p.APR, err = strconv.ParseFloat(aprStr, 64); err != nil || p.APR < 0 {
+				return nil, fmt.Errorf("invalid APR in %q", item)
+			}
+		}
+		platforms = append(platforms, p)
+	}
+	return platforms, nil
+}
+
+type Calculator struct {
+	platforms []Platform
+	valuator  *valuation.Engine
+	lending   func() (*doma.LendingParams, error)
+	tokenized func(domain string) (*doma.Result, error)
+}
+
+// NewCalculator quotes the given platforms. When domaClient is non-nil,
+// DOMA Lending's live terms are added and the domain's tokenization is
+// checked with it.
+func NewCalculator(platforms []Platform, domaClient *doma.Client) *Calculator {
+	c := &Calculator{platforms: platforms, valuator: valuation.NewEngine()}
+	if domaClient != nil {
+		c.lending = domaClient.LendingParameters
+		c.tokenized = domaClient.CheckDomain
+	}
+	return c
+}
+
+// Calculate quotes every platform for domain. A value of 0 uses the
+// valuation engine's estimate.
+func (c *Calculator) Calculate(domain string, value int) (*Report, error) {
+	report := &Report{Domain: domain, CheckedAt: time.Now()}
+
+	haircut := 1.0
+	if value > 0 {
+		report.EstimatedValue = value
+		report.Notes = append(report.Notes, "using the given value")
+	} else {
+		estimate := c.valuator.Evaluate(domain)
+		report.EstimatedValue = estimate.EstimatedValue
+		report.Confidence = estimate.Confidence
+		if h, ok := confidenceHaircut[estimate.Confidence]; ok {
+			haircut = h
+		}
+		if haircut < 1 {
+			report.Notes = append(report.Notes, fmt.Sprintf("%s-confidence estimate discounted %.0f%%", estimate.Confidence, (1-haircut)*100))
+		}
+	}
+	report.CollateralValue = int(float64(report.EstimatedValue) * haircut)
+
+	platforms := append([]Platform(nil), c.platforms...)
+	if c.lending != nil {
+		params, err := c.lending()
+		if err != nil {
+			report.Notes = append(report.Notes, "DOMA Lending terms unavailable: "+err.Error())
+		} else {
+			platforms = append(platforms, Platform{
+				Name:                 DOMALending,
+				LTV:                  params.MaxLTV,
+				LiquidationThreshold: params.LiquidationThreshold,
+				APR:                  params.APR,
+				Source:               SourceLive,
+			})
+		}
+	}
+	if c.tokenized != nil {
+		if status, err := c.tokenized(domain); err == nil && status.Status != doma.StatusTokenized {
+			report.Notes = append(report.Notes, "domain is not known to be tokenized; lenders require the domain as an on-chain token")
+		}
+	}
+	if len(platforms) == 0 {
+		return nil, fmt.Errorf("no lending platforms configured: use -ltv=name=ltv or a DOMA API key")
+	}
+
+	for _, p := range platforms {
+		q := Quote{Platform: p, MaxBorrow: int(math.Floor(float64(report.CollateralValue) * p.LTV))}
+		if p.LiquidationThreshold > 0 {
+			q.LiquidationValue = int(math.Ceil(float64(q.MaxBorrow) / p.LiquidationThreshold))
+		}
+		q.YearlyInterest = int(math.Round(float64(q.MaxBorrow) * p.APR))
+		report.Quotes = append(report.Quotes, q)
+	}
+	sort.SliceStable(report.Quotes, func(i, j int) bool {
+		return report.Quotes[i].MaxBorrow > report.Quotes[j].MaxBorrow
+	})
+	return report, nil
+}
@@ -0,0 +1,76 @@
+package collateral
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"d3-domain-tool/internal/doma"
+)
+
+func TestParsePlatforms(t *testing.T) {
+	platforms, err := ParsePlatforms("NFTfi=0.3/0.15, Arcade=0.25")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(platforms) != 2 || platforms[0].LTV != 0.3 || platforms[0].APR != 0.15 || platforms[1].Name != "Arcade" {
+		t.Errorf("unexpected platforms %+v", platforms)
+	}
+	for _, bad := range []string{"NFTfi", "NFTfi=1.5", "NFTfi=0.3/x"} {
+		if _, err := ParsePlatforms(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/lending/parameters" || r.Header.Get("Api-Key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"max_ltv": 0.5, "liquidation_threshold": 0.8, "interest_rate_apr": 0.1}`))
+	}))
+	defer api.Close()
+
+	client := doma.NewClient()
+	client.SetEndpoint(api.URL)
+	client.SetAPIKey("key")
+
+	platforms, _ := ParsePlatforms("NFTfi=0.3/0.15")
+	report, err := NewCalculator(platforms, client).Calculate("example.com", 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.CollateralValue != 10000 || len(report.Quotes) != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	live := report.Quotes[0]
+	if live.Name != DOMALending || live.Source != SourceLive || live.MaxBorrow != 5000 || live.LiquidationValue != 6250 || live.YearlyInterest != 500 {
+		t.Errorf("unexpected DOMA Lending quote %+v", live)
+	}
+	if q := report.Quotes[1]; q.MaxBorrow != 3000 || q.YearlyInterest != 450 {
+		t.Errorf("unexpected NFTfi quote %+v", q)
+	}
+
+	client.SetAPIKey("wrong")
+	report, err = NewCalculator(platforms, client).Calculate("example.com", 10000)
+	if err != nil || len(report.Quotes) != 1 {
+		t.Errorf("expected the configured quote only, got %+v, %v", report, err)
+	}
+
+	if _, err := NewCalculator(nil, nil).Calculate("example.com", 100); err == nil {
+		t.Error("expected an error without platforms")
+	}
+}
+
+func TestCalculateEstimate(t *testing.T) {
+	platforms, _ := ParsePlatforms("NFTfi=0.5")
+	report, err := NewCalculator(platforms, nil).Calculate("vault.eth", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.EstimatedValue == 0 || report.Confidence == "" || report.Quotes[0].MaxBorrow != report.CollateralValue/2 {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
@@ -0,0 +1,206 @@
+package compare
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/findings"
+)
+
+// Change kinds.
+const (
+	KindAdded   = "added"
+	KindRemoved = "removed"
+	KindChanged = "changed"
+)
+
+// Change is one difference between two results. Path uses the JSON field
+// names of analyzer.Result joined with dots, e.g. "whois_data.registrar".
+type Change struct {
+	Path string      `json:"path"`
+	Kind string      `json:"kind"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+	// Severity is how much the change matters to the domain's owner, on
+	// the scale of findings.
+	Severity findings.Severity `json:"severity"`
+}
+
+// ignoredFields change on every run and are never reported.
+var ignoredFields = map[string]bool{
+	"checked_at": true,
+	"timestamp":  true,
+	"raw_data":   true,
+}
+
+// severityRules map path prefixes to severities; the longest match wins.
+var severityRules = map[string]findings.Severity{
+	"dns_availability.available":  findings.Critical,
+	"whois_data.available":        findings.Critical,
+	"blockchain_data.available":   findings.Critical,
+	"blockchain_data.owner":       findings.Critical,
+	"doma_data.doma_record.owner": findings.Critical,
+	"whois_data.registrar":        findings.High,
+	"whois_data.name_servers":     findings.High,
+	"whois_data.expiry_date":      findings.High,
+	"whois_data.status":           findings.High,
+	"blockchain_data.resolver":    findings.High,
+	"blockchain_data.records":     findings.High,
+	"dns_provider":                findings.High,
+	"dangling_records":            findings.High,
+	"dns_availability":            findings.Medium,
+	"email_security":              findings.Medium,
+	"web_security":                findings.Medium,
+	"hsts_preload":                findings.Medium,
+	"mail_probe":                  findings.Medium,
+	"port_scan":                   findings.Medium,
+	"status":                      findings.Medium,
+	"section_errors":              findings.Low,
+	"whois_data":                  findings.Low,
+	"doma_data":                   findings.Low,
+	"valuation_data":              findings.Low,
+}
+
+// VerdictPaths are the fields analyzer.Result.Verdict is decided from.
+var VerdictPaths = []string{
+	"blockchain_data.available",
+	"alt_root.available",
+	"alt_root.registered",
+	"whois_data.available",
+	"whois_data.error",
+	"rdap.registered",
+	"rdap.error",
+	"dns_availability.available",
+	"dns_availability.error",
+}
+
+// Touching returns the severity of the most severe of changes that
+// touches one of paths, and whether any does. A change touches a path when
+// it is at or beneath it, or is a section above it (or the whole result)
+// that was added or removed; such a change is rated as one of the path.
+func Touching(changes []Change, paths ...string) (findings.Severity, bool) {
+	found := false
+	var most findings.Severity
+	for _, c := range changes {
+		for _, p := range paths {
+			severity := c.Severity
+			switch {
+			case c.Path == p || strings.HasPrefix(c.Path, p+"."):
+			case c.Path == "" || strings.HasPrefix(p, c.Path+"."):
+				severity = SeverityFor(p)
+			default:
+				continue
+			}
+			if !found || severity > most {
+				most = severity
+			}
+			found = true
+		}
+	}
+	return most, found
+}
+
+// Diff returns the differences between two results of the same domain,
+// ordered by path.
+func Diff(old, new *analyzer.Result) []Change {
+	var changes []Change
+	walk("", toTree(old), toTree(new), &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// toTree converts a result to its generic JSON form so every field,
+// including ones added later, is compared by its public name.
+func toTree(r *analyzer.Result) interface{} {
+	if r == nil {
+		return nil
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil
+	}
+	var tree interface{}
+	json.Unmarshal(data, &tree)
+	return tree
+}
+
+func walk(path string, old, new interface{}, changes *[]Change) {
+	oldMap, oldIsMap := old.(map[string]interface{})
+	newMap, newIsMap := new.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		keys := make(map[string]bool)
+		for k := range oldMap {
+			keys[k] = true
+		}
+		for k := range newMap {
+			keys[k] = true
+		}
+		for k := range keys {
+			if ignoredFields[k] {
+				continue
+			}
+			walk(join(path, k), oldMap[k], newMap[k], changes)
+		}
+		return
+	}
+
+	old, new = prune(old), prune(new)
+	if reflect.DeepEqual(old, new) {
+		return
+	}
+
+	change := Change{Path: path, Old: old, New: new, Severity: SeverityFor(path)}
+	switch {
+	case old == nil:
+		change.Kind = KindAdded
+	case new == nil:
+		change.Kind = KindRemoved
+	default:
+		change.Kind = KindChanged
+	}
+	*changes = append(*changes, change)
+}
+
+// prune drops ignored fields from a value reported in a change.
+func prune(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			if !ignoredFields[k] {
+				out[k] = prune(child)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			out[i] = prune(child)
+		}
+		return out
+	}
+	return v
+}
+
+// SeverityFor returns the severity assigned to changes at path.
+func SeverityFor(path string) findings.Severity {
+	best, severity := -1, findings.Info
+	for prefix, s := range severityRules {
+		if (path == prefix || strings.HasPrefix(path, prefix+".")) && len(prefix) > best {
+			best, severity = len(prefix), s
+		}
+	}
+	return severity
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
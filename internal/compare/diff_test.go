@@ -0,0 +1,151 @@
+package compare
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/checker"
+	"d3-domain-tool/internal/doma"
+	"d3-domain-tool/internal/findings"
+	"d3-domain-tool/internal/whois"
+)
+
+func TestDiff(t *testing.T) {
+	old := &analyzer.Result{
+		Domain:          "example.com",
+		Timestamp:       time.Now().Add(-time.Hour),
+		DNSAvailability: &checker.DNSResult{Available: false, CheckedAt: time.Now().Add(-time.Hour)},
+		WhoisData: &whois.Result{
+			Registrar:   "Old Registrar",
+			NameServers: []string{"ns1.example.net"},
+			CheckedAt:   time.Now().Add(-time.Hour),
+		},
+		Status: analyzer.StatusComplete,
+	}
+	new := &analyzer.Result{
+		Domain:          "example.com",
+		Timestamp:       time.Now(),
+		DNSAvailability: &checker.DNSResult{Available: true, CheckedAt: time.Now()},
+		WhoisData: &whois.Result{
+			Registrar:   "New Registrar",
+			NameServers: []string{"ns1.example.net"},
+			CheckedAt:   time.Now(),
+		},
+		Status: analyzer.StatusComplete,
+	}
+
+	changes := Diff(old, new)
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes (timestamps ignored), got %+v", changes)
+	}
+
+	if c := changes[0]; c.Path != "dns_availability.available" || c.Kind != KindChanged || c.Severity != findings.Critical {
+		t.Errorf("unexpected availability change: %+v", c)
+	}
+	if c := changes[1]; c.Path != "whois_data.registrar" || c.Old != "Old Registrar" || c.New != "New Registrar" || c.Severity != findings.High {
+		t.Errorf("unexpected registrar change: %+v", c)
+	}
+
+	if changes := Diff(old, old); len(changes) != 0 {
+		t.Errorf("expected no changes for identical results, got %+v", changes)
+	}
+}
+
+func TestTouching(t *testing.T) {
+	changes := []Change{
+		{Path: "whois_data", Kind: KindAdded, Severity: findings.Low},
+		{Path: "doma_data.is_tokenized", Kind: KindChanged, Severity: findings.Low},
+	}
+	if s, ok := Touching(changes, "whois_data.expiry_date"); !ok || s != findings.High {
+		t.Errorf("added section: %v, %v; want high", s, ok)
+	}
+	if s, ok := Touching(changes, "doma_data.is_tokenized", "whois_data.available"); !ok || s != findings.Critical {
+		t.Errorf("two fields: %v, %v; want critical", s, ok)
+	}
+	if _, ok := Touching(changes, "blockchain_data.expiry_date"); ok {
+		t.Error("an untouched field was reported")
+	}
+	if _, ok := Touching(Diff(nil, &analyzer.Result{Domain: "example.com"}), "doma_data.is_tokenized"); !ok {
+		t.Error("a first result must touch every field")
+	}
+}
+
+func TestDiff_AddedSection(t *testing.T) {
+	old := &analyzer.Result{Domain: "example.com"}
+	new := &analyzer.Result{Domain: "example.com", WhoisData: &whois.Result{Registrar: "Example Registrar"}}
+
+	changes := Diff(old, new)
+	if len(changes) != 1 || changes[0].Path != "whois_data" || changes[0].Kind != KindAdded {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+	if _, ok := changes[0].New.(map[string]interface{})["checked_at"]; ok {
+		t.Error("ignored fields should be pruned from added sections")
+	}
+}
+
+func TestSeverityFor(t *testing.T) {
+	tests := map[string]findings.Severity{
+		"whois_data.registrar":        findings.High,
+		"whois_data.updated_date":     findings.Low,
+		"blockchain_data.records.ETH": findings.High,
+		"valuation_data.confidence":   findings.Low,
+		"domain":                      findings.Info,
+	}
+	for path, want := range tests {
+		if got := SeverityFor(path); got != want {
+			t.Errorf("SeverityFor(%q) = %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestDiff_DomaOwner(t *testing.T) {
+	old := &analyzer.Result{Domain: "example.com", DomaData: &doma.Result{DomaRecord: &doma.DomaRecord{Owner: "0xaaaa"}}}
+	new := &analyzer.Result{Domain: "example.com", DomaData: &doma.Result{DomaRecord: &doma.DomaRecord{Owner: "0xbbbb"}}}
+
+	changes := Diff(old, new)
+	if len(changes) != 1 || changes[0].Path != "doma_data.doma_record.owner" || changes[0].Severity != findings.Critical {
+		t.Errorf("expected a critical owner change, got %+v", changes)
+	}
+}
+
+// TestSeverityRules checks that every rule names a field of
+// analyzer.Result, so a renamed field cannot silently drop its rule.
+func TestSeverityRules(t *testing.T) {
+	for path := range severityRules {
+		if !hasPath(reflect.TypeOf(analyzer.Result{}), strings.Split(path, ".")) {
+			t.Errorf("severity rule %q matches no field of analyzer.Result", path)
+		}
+	}
+}
+
+// hasPath reports whether the JSON form of typ has the field path.
+func hasPath(typ reflect.Type, path []string) bool {
+	for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	if len(path) == 0 {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.Map:
+		return hasPath(typ.Elem(), path[1:])
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if f.Anonymous && name == "" {
+				if hasPath(f.Type, path) {
+					return true
+				}
+				continue
+			}
+			if name == path[0] {
+				return hasPath(f.Type, path[1:])
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package compare
+
+import (
+	"sort"
+
+	"d3-domain-tool/internal/analyzer"
+)
+
+// Candidate is one name in a side-by-side comparison.
+type Candidate struct {
+	Rank           int              `json:"rank"`
+	Domain         string           `json:"domain"`
+	Verdict        string           `json:"verdict"`
+	EstimatedValue int              `json:"estimated_value"`
+	Result         *analyzer.Result `json:"result"`
+}
+
+// Rank orders results by estimated value, highest first. Names without a
+// valuation come last; ties keep the order the names were given in.
+func Rank(results []*analyzer.Result) []Candidate {
+	candidates := make([]Candidate, len(results))
+	for i, r := range results {
+		candidates[i] = Candidate{Domain: r.Domain, Verdict: r.Verdict(), Result: r}
+		if r.ValuationData != nil {
+			candidates[i].EstimatedValue = r.ValuationData.EstimatedValue
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		vi, vj := candidates[i].Result.ValuationData, candidates[j].Result.ValuationData
+		if (vi == nil) != (vj == nil) {
+			return vj == nil
+		}
+		return candidates[i].EstimatedValue > candidates[j].EstimatedValue
+	})
+	for i := range candidates {
+		candidates[i].Rank = i + 1
+	}
+	return candidates
+}
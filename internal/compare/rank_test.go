@@ -0,0 +1,35 @@
+package compare
+
+import (
+	"testing"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/valuation"
+)
+
+func TestRank(t *testing.T) {
+	results := []*analyzer.Result{
+		{Domain: "unvalued.io"},
+		{Domain: "cheap.io", ValuationData: &valuation.Result{EstimatedValue: 500}},
+		{Domain: "best.com", ValuationData: &valuation.Result{EstimatedValue: 9000}},
+		{Domain: "also-cheap.io", ValuationData: &valuation.Result{EstimatedValue: 500}},
+	}
+
+	ranked := Rank(results)
+	var order []string
+	for i, c := range ranked {
+		if c.Rank != i+1 {
+			t.Errorf("%s: rank %d at position %d", c.Domain, c.Rank, i)
+		}
+		order = append(order, c.Domain)
+	}
+	want := []string{"best.com", "cheap.io", "also-cheap.io", "unvalued.io"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+	if ranked[0].EstimatedValue != 9000 || ranked[0].Result != results[2] {
+		t.Errorf("top candidate: %+v", ranked[0])
+	}
+}
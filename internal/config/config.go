@@ -0,0 +1,228 @@
+// Package config loads the user's configuration file.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/schedule"
+)
+
+// FileName is the configuration file looked up in the home directory. It
+// is JSON rather than YAML so the tool keeps to the standard library; a
+// YAML file is refused instead of being ignored.
+const FileName = ".d3-domain-tool.json"
+
+// Config is the contents of the configuration file.
+type Config struct {
+	// Defaults are flag values for the main analysis, keyed by flag name
+	// without the dash, e.g. "format" or "eth-rpc". Flags given on the
+	// command line win.
+	Defaults map[string]string `json:"defaults,omitempty"`
+	// Commands are flag values per subcommand, e.g.
+	// {"collateral": {"doma-api-key": "..."}}.
+	Commands map[string]map[string]string `json:"commands,omitempty"`
+
+	// WatchWallets are addresses whose ENS, Unstoppable Domains and DOMA
+	// names are added to every portfolio run.
+	WatchWallets []WatchWallet `json:"watch_wallets,omitempty"`
+	// Monitor lists the domains the monitor command checks, each on its
+	// own schedule.
+	Monitor []MonitorEntry `json:"monitor,omitempty"`
+	// TLDs override analysis settings for the domains under a TLD, keyed
+	// by the TLD without its leading dot, e.g. "br" or "co.uk".
+	TLDs map[string]TLDConfig `json:"tlds,omitempty"`
+}
+
+// TLDConfig overrides analysis settings for the domains under one TLD.
+type TLDConfig struct {
+	// WhoisTimeout bounds each WHOIS query, e.g. "30s" for a slow
+	// registry.
+	WhoisTimeout string `json:"whois_timeout,omitempty"`
+	// Skip names analysis modules to leave out, e.g. ["whois"].
+	Skip []string `json:"skip,omitempty"`
+	// RDAPURL is the RDAP base URL to query instead of the server IANA's
+	// bootstrap registry lists.
+	RDAPURL string `json:"rdap_url,omitempty"`
+}
+
+// Timeout returns the parsed WhoisTimeout, 0 when unset.
+func (t TLDConfig) Timeout() time.Duration {
+	d, _ := time.ParseDuration(t.WhoisTimeout)
+	return d
+}
+
+// MonitorEntry schedules a domain for the monitor command. Schedule is a
+// cron expression, a descriptor such as @daily or an interval such as
+// */15m.
+type MonitorEntry struct {
+	Domain   string `json:"domain"`
+	Schedule string `json:"schedule"`
+}
+
+// WatchWallet is a watch-only address. No keys are stored or needed.
+type WatchWallet struct {
+	Address string `json:"address"`
+	Label   string `json:"label,omitempty"`
+}
+
+// PathFromArgs finds a -config (or --config) option in command line
+// arguments. It returns the path, whether it was given, and the arguments
+// without it.
+func PathFromArgs(args []string) (path string, given bool, rest []string) {
+	path = DefaultPath()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		switch {
+		case strings.HasPrefix(name, "config="):
+			path, given = strings.TrimPrefix(name, "config="), true
+		case name == "config" && arg != name && i+1 < len(args):
+			path, given = args[i+1], true
+			i++
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return path, given, rest
+}
+
+// Apply sets the flags in values on fs, so that parsing the command line
+// afterwards overrides them.
+func Apply(fs *flag.FlagSet, values map[string]string) error {
+	for _, name := range sortedKeys(values) {
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("config: %s has no option %q", fs.Name(), name)
+		}
+		if err := fs.Set(name, values[name]); err != nil {
+			return fmt.Errorf("config: %s: invalid value %q for %s: %v", fs.Name(), values[name], name, err)
+		}
+	}
+	return nil
+}
+
+// Args returns values as command line arguments, for subcommands that
+// parse their own flags: prepended to the real arguments, they act as
+// defaults because later flags win.
+func Args(values map[string]string) []string {
+	var args []string
+	for _, name := range sortedKeys(values) {
+		args = append(args, "-"+name+"="+values[name])
+	}
+	return args
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DefaultPath returns ~/.d3-domain-tool.json, or "" when the home
+// directory is unknown.
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, FileName)
+}
+
+// Load reads the configuration at path. A missing file is an error only
+// when required is set, so the default location may be absent, unless a
+// YAML file sits in its place.
+func Load(path string, required bool) (*Config, error) {
+	cfg := &Config{}
+	if path == "" {
+		return cfg, nil
+	}
+	if isYAML(path) {
+		return nil, fmt.Errorf("%s: YAML configuration is not supported; write it as JSON", path)
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !required {
+		for _, ext := range []string{".yaml", ".yml"} {
+			yaml := strings.TrimSuffix(path, filepath.Ext(path)) + ext
+			if _, err := os.Stat(yaml); err == nil {
+				return nil, fmt.Errorf("%s: YAML configuration is not supported; write it as JSON to %s", yaml, path)
+			}
+		}
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	for i, w := range cfg.WatchWallets {
+		address := strings.ToLower(strings.TrimSpace(w.Address))
+		if len(address) != 42 || !strings.HasPrefix(address, "0x") {
+			return nil, fmt.Errorf("%s: watch wallet %q is not an Ethereum address", path, w.Address)
+		}
+		cfg.WatchWallets[i].Address = address
+	}
+	for _, m := range cfg.Monitor {
+		if m.Domain == "" {
+			return nil, fmt.Errorf("%s: monitor entry without a domain", path)
+		}
+		if _, err := schedule.Parse(m.Schedule); err != nil {
+			return nil, fmt.Errorf("%s: monitor %s: %v", path, m.Domain, err)
+		}
+	}
+	if len(cfg.TLDs) > 0 {
+		tlds := make(map[string]TLDConfig, len(cfg.TLDs))
+		for name, t := range cfg.TLDs {
+			tld := strings.ToLower(strings.Trim(strings.TrimSpace(name), "."))
+			if tld == "" {
+				return nil, fmt.Errorf("%s: tlds: empty TLD", path)
+			}
+			if t.WhoisTimeout != "" {
+				if d, err := time.ParseDuration(t.WhoisTimeout); err != nil || d <= 0 {
+					return nil, fmt.Errorf("%s: tlds: %s: whois_timeout %q is not a positive duration such as 30s", path, tld, t.WhoisTimeout)
+				}
+			}
+			if t.RDAPURL != "" {
+				if u, err := url.Parse(t.RDAPURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return nil, fmt.Errorf("%s: tlds: %s: rdap_url must be an absolute http or https URL", path, tld)
+				}
+			}
+			tlds[tld] = t
+		}
+		cfg.TLDs = tlds
+	}
+	return cfg, nil
+}
+
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// Save writes cfg to path, readable only by the user since it may hold
+// API keys.
+func Save(path string, cfg *Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+	os.WriteFile(path, []byte(`{"watch_wallets": [{"address": " 0xD8dA6BF26964aF9D7eEd9e03E53415D37aA96045 ", "label": "vault"}]}`), 0644)
+
+	cfg, err := Load(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.WatchWallets) != 1 || cfg.WatchWallets[0].Address != "0xd8da6bf26964af9d7eed9e03e53415d37aa96045" || cfg.WatchWallets[0].Label != "vault" {
+		t.Errorf("wallets = %+v", cfg.WatchWallets)
+	}
+
+	missing := filepath.Join(dir, "missing.json")
+	if cfg, err := Load(missing, false); err != nil || len(cfg.WatchWallets) != 0 {
+		t.Errorf("optional missing file: %+v, %v", cfg, err)
+	}
+	if _, err := Load(missing, true); err == nil {
+		t.Error("required missing file: expected an error")
+	}
+
+	// A YAML file is refused rather than silently ignored.
+	yaml := filepath.Join(dir, "missing.yaml")
+	os.WriteFile(yaml, []byte("defaults:\n  format: json\n"), 0644)
+	if _, err := Load(missing, false); err == nil || !strings.Contains(err.Error(), "YAML") {
+		t.Errorf("YAML next to a missing file: err = %v", err)
+	}
+	if _, err := Load(yaml, true); err == nil || !strings.Contains(err.Error(), "YAML") {
+		t.Errorf("YAML file: err = %v", err)
+	}
+}
+
+func TestLoadTLDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	os.WriteFile(path, []byte(`{"tlds": {".BR": {"whois_timeout": "30s"}, "eth": {"skip": ["whois"]}}}`), 0644)
+
+	cfg, err := Load(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if br, ok := cfg.TLDs["br"]; !ok || br.Timeout() != 30*time.Second {
+		t.Errorf("tlds = %+v", cfg.TLDs)
+	}
+	if eth := cfg.TLDs["eth"]; eth.Timeout() != 0 || !reflect.DeepEqual(eth.Skip, []string{"whois"}) {
+		t.Errorf("eth = %+v", eth)
+	}
+}
+
+func TestSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	cfg := &Config{
+		Defaults: map[string]string{"format": "json"},
+		Monitor:  []MonitorEntry{{Domain: "example.com", Schedule: "@daily"}},
+	}
+	if err := Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v", info.Mode().Perm())
+	}
+	loaded, err := Load(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, cfg) {
+		t.Errorf("loaded %+v, saved %+v", loaded, cfg)
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":  `{"wallets": []}`,
+		"bad address":  `{"watch_wallets": [{"address": "vitalik.eth"}]}`,
+		"bad schedule": `{"monitor": [{"domain": "example.com", "schedule": "sometimes"}]}`,
+		"bad timeout":  `{"tlds": {"br": {"whois_timeout": "30"}}}`,
+		"bad rdap url": `{"tlds": {"xyz": {"rdap_url": "rdap.example"}}}`,
+	}
+	for name, content := range tests {
+		path := filepath.Join(t.TempDir(), FileName)
+		os.WriteFile(path, []byte(content), 0644)
+		if _, err := Load(path, true); err == nil || !strings.Contains(err.Error(), path) {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+}
+
+func TestPathFromArgs(t *testing.T) {
+	tests := []struct {
+		args  []string
+		path  string
+		given bool
+		rest  []string
+	}{
+		{[]string{"-format=json", "example.com"}, DefaultPath(), false, []string{"-format=json", "example.com"}},
+		{[]string{"-config=/etc/d3.json", "example.com"}, "/etc/d3.json", true, []string{"example.com"}},
+		{[]string{"--config", "d3.json", "-value=5"}, "d3.json", true, []string{"-value=5"}},
+		{[]string{"--", "-config=x"}, DefaultPath(), false, []string{"--", "-config=x"}},
+	}
+	for _, tt := range tests {
+		path, given, rest := PathFromArgs(tt.args)
+		if path != tt.path || given != tt.given || !reflect.DeepEqual(rest, tt.rest) {
+			t.Errorf("PathFromArgs(%q) = %q, %v, %q", tt.args, path, given, rest)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	fs := flag.NewFlagSet("d3-domain-tool", flag.ContinueOnError)
+	format := fs.String("format", "table", "")
+	lease := fs.Bool("lease", false, "")
+
+	if err := Apply(fs, map[string]string{"format": "json", "lease": "true"}); err != nil {
+		t.Fatal(err)
+	}
+	// The command line still wins.
+	fs.Parse([]string{"-format=csv"})
+	if *format != "csv" || !*lease {
+		t.Errorf("format %q, lease %v", *format, *lease)
+	}
+
+	if err := Apply(fs, map[string]string{"colour": "red"}); err == nil || !strings.Contains(err.Error(), `no option "colour"`) {
+		t.Errorf("unknown option: err = %v", err)
+	}
+	if err := Apply(fs, map[string]string{"lease": "maybe"}); err == nil {
+		t.Error("invalid value: expected an error")
+	}
+
+	if got := Args(map[string]string{"value": "5", "ltv": "NFTfi=0.3"}); !reflect.DeepEqual(got, []string{"-ltv=NFTfi=0.3", "-value=5"}) {
+		t.Errorf("Args = %q", got)
+	}
+}
@@ -0,0 +1,218 @@
+package dangling
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/checker"
+	"d3-domain-tool/internal/email"
+	"d3-domain-tool/internal/suffix"
+)
+
+// Detector looks for DNS records that point at hosts which no longer exist.
+// A dangling reference to a name that someone else can claim (an expired
+// domain, a deleted cloud bucket) is a takeover risk.
+type Detector struct {
+	lookup func(name, rrType string) (*checker.Response, error)
+	// registered reports whether the registrable domain of a host is
+	// registered.
+	registered func(host string) bool
+}
+
+type Result struct {
+	Checked   int       `json:"checked"`
+	Findings  []Finding `json:"findings,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Finding is one dangling reference.
+type Finding struct {
+	RecordType  string `json:"record_type"`
+	Target      string `json:"target"`
+	Reason      string `json:"reason"`
+	Takeover    bool   `json:"takeover_risk"`
+	Provider    string `json:"provider,omitempty"`
+	Remediation string `json:"remediation"`
+}
+
+// cloudSuffixes maps hostnames of cloud services whose resources can be
+// re-created by anyone once deleted.
+var cloudSuffixes = map[string]string{
+	".s3.amazonaws.com":       "AWS S3",
+	".elasticbeanstalk.com":   "AWS Elastic Beanstalk",
+	".cloudfront.net":         "AWS CloudFront",
+	".azurewebsites.net":      "Azure App Service",
+	".cloudapp.net":           "Azure Cloud Services",
+	".cloudapp.azure.com":     "Azure VM",
+	".blob.core.windows.net":  "Azure Blob Storage",
+	".trafficmanager.net":     "Azure Traffic Manager",
+	".azureedge.net":          "Azure CDN",
+	".herokuapp.com":          "Heroku",
+	".herokudns.com":          "Heroku",
+	".github.io":              "GitHub Pages",
+	".netlify.app":            "Netlify",
+	".vercel.app":             "Vercel",
+	".pantheonsite.io":        "Pantheon",
+	".myshopify.com":          "Shopify",
+	".ghost.io":               "Ghost",
+	".storage.googleapis.com": "Google Cloud Storage",
+	".appspot.com":            "Google App Engine",
+	".zendesk.com":            "Zendesk",
+	".wordpress.com":          "WordPress.com",
+	".surge.sh":               "Surge",
+	".fly.dev":                "Fly.io",
+}
+
+func NewDetector() *Detector {
+	d := &Detector{lookup: checker.NewResolver().Query}
+	d.registered = d.delegated
+	return d
+}
+
+func (d *Detector) Detect(domain string) (*Result, error) {
+	result := &Result{
+		CheckedAt: time.Now(),
+	}
+
+	d.checkTargets(domain, "CNAME", result)
+	d.checkTargets(domain, "MX", result)
+	d.checkTargets(domain, "NS", result)
+	d.checkSPF(domain, result)
+
+	return result, nil
+}
+
+// checkTargets resolves every target of the domain's records of rrType.
+func (d *Detector) checkTargets(domain, rrType string, result *Result) {
+	resp, err := d.lookup(domain, rrType)
+	if err != nil {
+		return
+	}
+	for _, rec := range resp.Answers {
+		if rec.Type != rrType || rec.Value == "" || rec.Value == "." {
+			continue
+		}
+		result.Checked++
+		if finding := d.checkHost(rrType, rec.Value); finding != nil {
+			result.Findings = append(result.Findings, *finding)
+		}
+	}
+}
+
+func (d *Detector) checkSPF(domain string, result *Result) {
+	resp, err := d.lookup(domain, "TXT")
+	if err != nil {
+		return
+	}
+	for _, rec := range resp.Answers {
+		spf := email.ParseSPF(rec.Value)
+		if spf == nil {
+			continue
+		}
+		for _, include := range spf.Includes() {
+			result.Checked++
+			if finding := d.checkSPFInclude(include); finding != nil {
+				result.Findings = append(result.Findings, *finding)
+			}
+		}
+	}
+}
+
+// checkHost reports a finding if host does not resolve.
+func (d *Detector) checkHost(rrType, host string) *Finding {
+	if d.exists(host) {
+		return nil
+	}
+
+	finding := &Finding{
+		RecordType: rrType,
+		Target:     host,
+		Reason:     "target does not resolve (NXDOMAIN)",
+	}
+
+	if provider := cloudProvider(host); provider != "" {
+		finding.Provider = provider
+		finding.Takeover = true
+		finding.Reason = fmt.Sprintf("points to an unclaimed %s resource", provider)
+	} else if !d.registered(host) {
+		finding.Takeover = true
+		finding.Reason = "target's domain is not registered"
+	}
+
+	switch rrType {
+	case "CNAME":
+		finding.Remediation = "Remove the CNAME or re-create the resource it points to before someone else claims it"
+	case "MX":
+		finding.Remediation = "Remove the MX record or point it at a working mail host; mail to this domain is bouncing"
+	case "NS":
+		finding.Remediation = "Remove the lame delegation at the registrar; an attacker who registers the nameserver domain controls this zone"
+	}
+	return finding
+}
+
+func (d *Detector) checkSPFInclude(include string) *Finding {
+	resp, err := d.lookup(include, "TXT")
+	if err == nil && resp.Rcode != "NXDOMAIN" {
+		for _, rec := range resp.Answers {
+			if email.IsSPF(rec.Value) {
+				return nil
+			}
+		}
+	}
+
+	finding := &Finding{
+		RecordType:  "SPF",
+		Target:      include,
+		Reason:      "included domain has no SPF record (causes permerror)",
+		Remediation: "Remove the include: from the SPF record or restore the referenced policy",
+	}
+	if !d.registered(include) {
+		finding.Takeover = true
+		finding.Reason = "included domain is not registered; whoever registers it can authorize senders for this domain"
+	}
+	return finding
+}
+
+// exists reports whether host resolves to anything. Transport errors are
+// treated as existing to avoid false positives.
+func (d *Detector) exists(host string) bool {
+	resp, err := d.lookup(host, "A")
+	if err != nil {
+		return true
+	}
+	return resp.Rcode != "NXDOMAIN"
+}
+
+// delegated reports whether the registrable domain of host has a
+// delegation.
+func (d *Detector) delegated(host string) bool {
+	apex := suffix.Registrable(host)
+	resp, err := d.lookup(apex, "NS")
+	if err != nil {
+		return true
+	}
+	return resp.Rcode != "NXDOMAIN"
+}
+
+// cloudProvider returns the service host is a name under, matching whole
+// labels so that github.io.attacker.com is not GitHub Pages.
+func cloudProvider(host string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for s, provider := range cloudSuffixes {
+		if strings.HasSuffix(host, s) {
+			return provider
+		}
+	}
+	// S3 website endpoints carry the region in or after their label:
+	// bucket.s3-website-us-east-1.amazonaws.com, bucket.s3-website.eu-west-1.amazonaws.com.
+	if strings.HasSuffix(host, ".amazonaws.com") {
+		for _, label := range strings.Split(host, ".")[1:] {
+			if label == "s3-website" || strings.HasPrefix(label, "s3-website-") {
+				return "AWS S3 website"
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package dangling
+
+import (
+	"strings"
+	"testing"
+
+	"d3-domain-tool/internal/checker"
+	"d3-domain-tool/internal/suffix"
+)
+
+// fakeDNS answers from records keyed by name; names without records are
+// NXDOMAIN.
+func fakeDNS(records map[string][]checker.Record) func(name, rrType string) (*checker.Response, error) {
+	return func(name, rrType string) (*checker.Response, error) {
+		recs, ok := records[strings.TrimSuffix(name, ".")]
+		if !ok {
+			return &checker.Response{Rcode: "NXDOMAIN"}, nil
+		}
+		var answers []checker.Record
+		for _, rec := range recs {
+			if rec.Type == rrType {
+				answers = append(answers, rec)
+			}
+		}
+		return &checker.Response{Rcode: "NOERROR", Answers: answers}, nil
+	}
+}
+
+func TestDetect(t *testing.T) {
+	d := &Detector{
+		lookup: fakeDNS(map[string][]checker.Record{
+			"example.com": {
+				{Type: "CNAME", Value: "www.expired-brand.com."},
+				{Type: "MX", Value: "mx.gone-mail.net."},
+				{Type: "NS", Value: "ns1.example-dns.net."},
+				{Type: "TXT", Value: "v=spf1 include:_spf.live.com include:spf.lapsed.org -all"},
+			},
+			"ns1.example-dns.net": {{Type: "A", Value: "192.0.2.53"}},
+			"_spf.live.com":       {{Type: "TXT", Value: "v=spf1 ip4:192.0.2.0/24 -all"}},
+		}),
+		registered: func(host string) bool {
+			switch suffix.Registrable(host) {
+			case "expired-brand.com", "lapsed.org":
+				return false
+			}
+			return true
+		},
+	}
+
+	result, err := d.Detect("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Checked != 5 {
+		t.Errorf("expected 5 references checked, got %d", result.Checked)
+	}
+	byType := map[string]Finding{}
+	for _, f := range result.Findings {
+		byType[f.RecordType] = f
+	}
+	if len(result.Findings) != 3 {
+		t.Fatalf("expected CNAME, MX and SPF findings, got %+v", result.Findings)
+	}
+	if f := byType["CNAME"]; f.Target != "www.expired-brand.com." || !f.Takeover || f.Reason != "target's domain is not registered" {
+		t.Errorf("unexpected CNAME finding %+v", f)
+	}
+	if f := byType["MX"]; f.Target != "mx.gone-mail.net." || f.Takeover || f.Reason != "target does not resolve (NXDOMAIN)" {
+		t.Errorf("unexpected MX finding %+v", f)
+	}
+	if f := byType["SPF"]; f.Target != "spf.lapsed.org" || !f.Takeover {
+		t.Errorf("unexpected SPF finding %+v", f)
+	}
+}
+
+func TestCloudProvider(t *testing.T) {
+	tests := map[string]string{
+		"brand.github.io.":                        "GitHub Pages",
+		"assets.s3.amazonaws.com":                 "AWS S3",
+		"site.s3-website-us-east-1.amazonaws.com": "AWS S3 website",
+		"site.s3-website.eu-west-1.amazonaws.com": "AWS S3 website",
+		"brand.github.io.attacker.com":            "",
+		"notgithub.io":                            "",
+		"s3-website.example.com":                  "",
+		"app.herokuapp.com.example.net":           "",
+	}
+	for host, want := range tests {
+		if got := cloudProvider(host); got != want {
+			t.Errorf("cloudProvider(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestDelegated(t *testing.T) {
+	d := &Detector{lookup: fakeDNS(map[string][]checker.Record{
+		"brand.co.uk": {{Type: "NS", Value: "ns1.brand.co.uk."}},
+	})}
+	if !d.delegated("mx.brand.co.uk.") {
+		t.Error("expected mx.brand.co.uk to be checked under brand.co.uk, which is delegated")
+	}
+	if d.delegated("mx.lapsed.co.uk.") {
+		t.Error("expected lapsed.co.uk to be reported as not delegated")
+	}
+}
@@ -0,0 +1,404 @@
+package dnsaudit
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/checker"
+)
+
+// Status is the outcome of one test.
+type Status string
+
+const (
+	Pass Status = "pass"
+	Warn Status = "warn"
+	Fail Status = "fail"
+)
+
+// Auditor runs a battery of delegation, SOA, connectivity and syntax tests
+// against a zone, in the spirit of Zonemaster.
+type Auditor struct {
+	resolver *checker.Resolver
+	// serverFor returns a resolver that queries one authoritative server.
+	serverFor func(ip string) *checker.Resolver
+}
+
+type Report struct {
+	Domain    string    `json:"domain"`
+	Tests     []Test    `json:"tests"`
+	Passed    int       `json:"passed"`
+	Warnings  int       `json:"warnings"`
+	Failures  int       `json:"failures"`
+	CheckedAt time.Time `json:"checked_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Test is one check in the battery.
+type Test struct {
+	ID          string `json:"id"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	Status      Status `json:"status"`
+	Message     string `json:"message,omitempty"`
+}
+
+func NewAuditor() *Auditor {
+	return &Auditor{
+		resolver: checker.NewResolver(),
+		serverFor: func(ip string) *checker.Resolver {
+			return checker.NewResolverFor(ip).NonRecursive()
+		},
+	}
+}
+
+// nameserver is an authoritative server with its resolved addresses.
+type nameserver struct {
+	host string
+	ips  []string
+}
+
+func (a *Auditor) Audit(domain string) (*Report, error) {
+	report := &Report{
+		Domain:    domain,
+		CheckedAt: time.Now(),
+	}
+
+	report.add("SYNTAX-01", "syntax", "Domain name is a valid hostname", syntaxStatus(domain))
+
+	nsResp, err := a.resolver.Query(domain, "NS")
+	if err != nil {
+		report.Error = fmt.Sprintf("NS lookup failed: %v", err)
+		return report, nil
+	}
+	var childNS []string
+	for _, rec := range nsResp.Answers {
+		if rec.Type == "NS" {
+			childNS = append(childNS, strings.ToLower(rec.Value))
+		}
+	}
+	sort.Strings(childNS)
+
+	if len(childNS) == 0 {
+		report.add("DELEG-01", "delegation", "Zone has at least two nameservers",
+			result(Fail, "no NS records found (rcode %s)", nsResp.Rcode))
+		report.tally()
+		return report, nil
+	}
+
+	switch {
+	case len(childNS) >= 2:
+		report.add("DELEG-01", "delegation", "Zone has at least two nameservers", result(Pass, "%d nameservers", len(childNS)))
+	default:
+		report.add("DELEG-01", "delegation", "Zone has at least two nameservers", result(Warn, "only one nameserver: %s", childNS[0]))
+	}
+
+	var badSyntax []string
+	for _, ns := range childNS {
+		if syntaxStatus(ns).status != Pass {
+			badSyntax = append(badSyntax, ns)
+		}
+	}
+	report.add("SYNTAX-02", "syntax", "Nameserver names are valid hostnames", listResult(badSyntax, Fail, "invalid names: %s"))
+
+	servers := a.resolveNameservers(childNS)
+	var unresolved []string
+	for _, ns := range servers {
+		if len(ns.ips) == 0 {
+			unresolved = append(unresolved, ns.host)
+		}
+	}
+	report.add("DELEG-02", "delegation", "Nameserver names resolve to addresses", listResult(unresolved, Fail, "no address for: %s"))
+
+	report.add("DELEG-03", "delegation", "Parent and child NS sets match", a.parentMatch(domain, childNS))
+	report.add("DELEG-04", "delegation", "Nameservers are in more than one network", networkDiversity(servers))
+
+	a.connectivityTests(domain, servers, report)
+	a.soaTests(domain, servers, report)
+
+	report.tally()
+	return report, nil
+}
+
+func (r *Report) add(id, category, description string, outcome testResult) {
+	r.Tests = append(r.Tests, Test{
+		ID:          id,
+		Category:    category,
+		Description: description,
+		Status:      outcome.status,
+		Message:     outcome.message,
+	})
+}
+
+func (r *Report) tally() {
+	r.Passed, r.Warnings, r.Failures = 0, 0, 0
+	for _, t := range r.Tests {
+		switch t.Status {
+		case Pass:
+			r.Passed++
+		case Warn:
+			r.Warnings++
+		case Fail:
+			r.Failures++
+		}
+	}
+}
+
+type testResult struct {
+	status  Status
+	message string
+}
+
+func result(status Status, format string, args ...interface{}) testResult {
+	return testResult{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+// listResult passes when items is empty and otherwise reports them.
+func listResult(items []string, status Status, format string) testResult {
+	if len(items) == 0 {
+		return testResult{status: Pass}
+	}
+	return result(status, format, strings.Join(items, ", "))
+}
+
+func (a *Auditor) resolveNameservers(hosts []string) []nameserver {
+	var servers []nameserver
+	for _, host := range hosts {
+		ns := nameserver{host: host}
+		for _, rrType := range []string{"A", "AAAA"} {
+			resp, err := a.resolver.Query(host, rrType)
+			if err != nil {
+				continue
+			}
+			for _, rec := range resp.Answers {
+				if rec.Type == rrType {
+					ns.ips = append(ns.ips, rec.Value)
+				}
+			}
+		}
+		servers = append(servers, ns)
+	}
+	return servers
+}
+
+// parentMatch asks a TLD server for the delegation and compares it with the
+// NS set the zone itself publishes.
+func (a *Auditor) parentMatch(domain string, childNS []string) testResult {
+	_, parent, ok := strings.Cut(domain, ".")
+	if !ok {
+		return result(Warn, "%s has no parent zone", domain)
+	}
+
+	tldResp, err := a.resolver.Query(parent, "NS")
+	if err != nil || len(tldResp.Answers) == 0 {
+		return result(Warn, "could not find nameservers for parent zone %s", parent)
+	}
+
+	for _, rec := range tldResp.Answers {
+		if rec.Type != "NS" {
+			continue
+		}
+		addrs := a.resolveNameservers([]string{rec.Value})
+		if len(addrs[0].ips) == 0 {
+			continue
+		}
+		resp, err := a.serverFor(addrs[0].ips[0]).Query(domain, "NS")
+		if err != nil {
+			continue
+		}
+
+		var parentNS []string
+		for _, r := range append(resp.Authority, resp.Answers...) {
+			if r.Type == "NS" && strings.EqualFold(r.Name, domain) {
+				parentNS = append(parentNS, strings.ToLower(r.Value))
+			}
+		}
+		sort.Strings(parentNS)
+		if len(parentNS) == 0 {
+			return result(Fail, "parent zone has no delegation for %s", domain)
+		}
+		if strings.Join(parentNS, ",") != strings.Join(childNS, ",") {
+			return result(Fail, "parent lists [%s], zone lists [%s]", strings.Join(parentNS, ", "), strings.Join(childNS, ", "))
+		}
+		return testResult{status: Pass}
+	}
+
+	return result(Warn, "no parent nameserver answered")
+}
+
+// networkDiversity checks that nameserver addresses span more than one
+// IPv4 /24 (or IPv6 /48), so one network outage cannot take the zone down.
+func networkDiversity(servers []nameserver) testResult {
+	networks := make(map[string]bool)
+	for _, ns := range servers {
+		for _, ip := range ns.ips {
+			networks[networkOf(ip)] = true
+		}
+	}
+	switch len(networks) {
+	case 0:
+		return result(Fail, "no nameserver addresses")
+	case 1:
+		return result(Warn, "all nameservers share one network")
+	default:
+		return result(Pass, "%d distinct networks", len(networks))
+	}
+}
+
+func networkOf(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ip
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return parsed.Mask(net.CIDRMask(48, 128)).String()
+}
+
+func (a *Auditor) connectivityTests(domain string, servers []nameserver, report *Report) {
+	var noUDP, noTCP, notAuth []string
+	for _, ns := range servers {
+		for _, ip := range ns.ips {
+			label := ns.host + " (" + ip + ")"
+			server := a.serverFor(ip)
+
+			resp, err := server.Query(domain, "SOA")
+			if err != nil {
+				noUDP = append(noUDP, label)
+			} else if !resp.Authoritative {
+				notAuth = append(notAuth, label)
+			}
+
+			if _, err := server.OverTCP().Query(domain, "SOA"); err != nil {
+				noTCP = append(noTCP, label)
+			}
+		}
+	}
+
+	report.add("CONN-01", "connectivity", "All nameservers answer over UDP", listResult(noUDP, Fail, "no UDP answer from: %s"))
+	report.add("CONN-02", "connectivity", "All nameservers answer over TCP", listResult(noTCP, Fail, "no TCP answer from: %s"))
+	report.add("CONN-03", "connectivity", "All nameservers answer authoritatively", listResult(notAuth, Fail, "lame delegation (no AA flag): %s"))
+}
+
+// soaRecord holds the parsed SOA fields.
+type soaRecord struct {
+	mname, rname                            string
+	serial, refresh, retry, expire, minimum uint64
+}
+
+func parseSOA(value string) (*soaRecord, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 7 {
+		return nil, fmt.Errorf("malformed SOA %q", value)
+	}
+	nums := make([]uint64, 5)
+	for i := range nums {
+		n, err := strconv.ParseUint(fields[2+i], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("malformed SOA %q", value)
+		}
+		nums[i] = n
+	}
+	return &soaRecord{
+		mname: fields[0], rname: fields[1],
+		serial: nums[0], refresh: nums[1], retry: nums[2], expire: nums[3], minimum: nums[4],
+	}, nil
+}
+
+func (a *Auditor) soaTests(domain string, servers []nameserver, report *Report) {
+	resp, err := a.resolver.Query(domain, "SOA")
+	var soa *soaRecord
+	if err == nil {
+		for _, rec := range resp.Answers {
+			if rec.Type == "SOA" {
+				soa, _ = parseSOA(rec.Value)
+			}
+		}
+	}
+	if soa == nil {
+		report.add("SOA-01", "soa", "Zone has an SOA record", result(Fail, "no SOA record"))
+		return
+	}
+	report.add("SOA-01", "soa", "Zone has an SOA record", testResult{status: Pass})
+
+	serials := make(map[uint64][]string)
+	for _, ns := range servers {
+		for _, ip := range ns.ips {
+			resp, err := a.serverFor(ip).Query(domain, "SOA")
+			if err != nil {
+				continue
+			}
+			for _, rec := range resp.Answers {
+				if s, err := parseSOA(rec.Value); err == nil && rec.Type == "SOA" {
+					serials[s.serial] = append(serials[s.serial], ns.host)
+				}
+			}
+		}
+	}
+	if len(serials) > 1 {
+		var parts []string
+		for serial, hosts := range serials {
+			parts = append(parts, fmt.Sprintf("%d on %s", serial, strings.Join(hosts, ", ")))
+		}
+		sort.Strings(parts)
+		report.add("SOA-02", "soa", "SOA serial is consistent across nameservers", result(Fail, "serials differ: %s", strings.Join(parts, "; ")))
+	} else {
+		report.add("SOA-02", "soa", "SOA serial is consistent across nameservers", result(Pass, "serial %d", soa.serial))
+	}
+
+	// Ranges follow RFC 1912 and RIPE-203 recommendations.
+	report.add("SOA-03", "soa", "SOA refresh is between 20 minutes and 12 hours", rangeResult(soa.refresh, 1200, 43200))
+	if soa.retry >= soa.refresh {
+		report.add("SOA-04", "soa", "SOA retry is lower than refresh", result(Warn, "retry %d >= refresh %d", soa.retry, soa.refresh))
+	} else {
+		report.add("SOA-04", "soa", "SOA retry is lower than refresh", testResult{status: Pass})
+	}
+	report.add("SOA-05", "soa", "SOA expire is between 1 and 4 weeks", rangeResult(soa.expire, 604800, 2419200))
+	report.add("SOA-06", "soa", "SOA minimum (negative TTL) is between 5 minutes and 1 day", rangeResult(soa.minimum, 300, 86400))
+
+	// The first label of RNAME is the mailbox's local part, which may hold
+	// characters a hostname cannot, e.g. dns_admin.
+	rname := soa.rname
+	_, mailDomain, _ := strings.Cut(rname, ".")
+	if syntaxStatus(mailDomain).status == Pass && strings.Count(rname, ".") >= 2 {
+		report.add("SYNTAX-03", "syntax", "SOA RNAME is a valid mailbox", testResult{status: Pass})
+	} else {
+		report.add("SYNTAX-03", "syntax", "SOA RNAME is a valid mailbox", result(Warn, "unusual RNAME %q", rname))
+	}
+}
+
+func rangeResult(value, min, max uint64) testResult {
+	if value < min || value > max {
+		return result(Warn, "%d is outside %d–%d", value, min, max)
+	}
+	return result(Pass, "%d", value)
+}
+
+// syntaxStatus checks LDH hostname rules (RFC 1123) and lengths. An
+// underscore is only allowed to start a label, as in the service labels of
+// SRV and DKIM names (_sip._tcp, _domainkey, _dmarc).
+func syntaxStatus(name string) testResult {
+	name = strings.TrimSuffix(name, ".")
+	if len(name) == 0 || len(name) > 253 {
+		return result(Fail, "name length %d is invalid", len(name))
+	}
+	for _, label := range strings.Split(name, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return result(Fail, "label %q has invalid length", label)
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return result(Fail, "label %q starts or ends with a hyphen", label)
+		}
+		for i, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' && i == 0) {
+				return result(Fail, "label %q contains invalid character %q", label, r)
+			}
+		}
+	}
+	return testResult{status: Pass}
+}
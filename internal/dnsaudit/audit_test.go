@@ -0,0 +1,53 @@
+package dnsaudit
+
+import (
+	"testing"
+)
+
+func TestParseSOA(t *testing.T) {
+	soa, err := parseSOA("ns1.example.com hostmaster.example.com 2024010101 7200 3600 1209600 3600")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if soa.serial != 2024010101 || soa.refresh != 7200 || soa.minimum != 3600 {
+		t.Errorf("unexpected SOA: %+v", soa)
+	}
+
+	if _, err := parseSOA("ns1.example.com hostmaster.example.com"); err == nil {
+		t.Error("expected error for truncated SOA")
+	}
+}
+
+func TestSyntaxStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status Status
+	}{
+		{"example.com", Pass},
+		{"_dmarc.example.com", Pass},
+		{"_sip._tcp.example.com", Pass},
+		{"s1._domainkey.example.com", Pass},
+		{"my_host.example.com", Fail},
+		{"host_.example.com", Fail},
+		{"-bad.example.com", Fail},
+		{"bad..example.com", Fail},
+		{"sp ace.example.com", Fail},
+	}
+	for _, tt := range tests {
+		if got := syntaxStatus(tt.name).status; got != tt.status {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.status, got)
+		}
+	}
+}
+
+func TestNetworkDiversity(t *testing.T) {
+	same := []nameserver{{host: "a", ips: []string{"192.0.2.1"}}, {host: "b", ips: []string{"192.0.2.2"}}}
+	if got := networkDiversity(same).status; got != Warn {
+		t.Errorf("expected warn for one /24, got %s", got)
+	}
+
+	diverse := []nameserver{{host: "a", ips: []string{"192.0.2.1"}}, {host: "b", ips: []string{"198.51.100.1"}}}
+	if got := networkDiversity(diverse).status; got != Pass {
+		t.Errorf("expected pass for two networks, got %s", got)
+	}
+}
@@ -1,39 +1,69 @@
 package doma
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
 	"time"
+
+	"d3-domain-tool/internal/blockchain"
+)
+
+// Tokenization statuses.
+const (
+	StatusTokenized    = "tokenized"
+	StatusNotTokenized = "not_tokenized"
+	// StatusUnknown means no DOMA data source is configured, so nothing is
+	// known about the domain's tokenization.
+	StatusUnknown = "unknown"
 )
 
+// notConfigured is the note on results of a client without a data source.
+const notConfigured = "unknown — DOMA data source not configured (-doma-api-key)"
+
+// Source looks up the DOMA state of a domain. It returns the record fields
+// of Result (IsTokenized, DomaRecord, TokenRights, ...); CheckDomain fills
+// in the rest. It gives up when ctx is done.
+type Source func(ctx context.Context, domain string) (*Result, error)
+
 type Client struct {
 	httpClient *http.Client
 	baseURL    string
 	timeout    time.Duration
+	explorers  blockchain.Explorers
+	source     Source
+	apiKey     string
 }
 
 type Result struct {
 	Domain            string                 `json:"domain"`
-	IsTokenized       bool                   `json:"is_tokenized"`
+	Status            string                 `json:"status"`
+	IsTokenized       *bool                  `json:"is_tokenized"`
+	Note              string                 `json:"note,omitempty"`
 	TokenizationChain string                 `json:"tokenization_chain,omitempty"`
 	DomaRecord        *DomaRecord            `json:"doma_record,omitempty"`
 	TokenRights       *TokenRights           `json:"token_rights,omitempty"`
 	DeFiStatus        *DeFiStatus            `json:"defi_status,omitempty"`
 	CrossChainData    map[string]interface{} `json:"cross_chain_data,omitempty"`
+	Links             []blockchain.Link      `json:"explorer_links,omitempty"`
 	CheckedAt         time.Time              `json:"checked_at"`
 	Error             string                 `json:"error,omitempty"`
 }
 
 type DomaRecord struct {
-	TokenId          string            `json:"token_id"`
-	Owner            string            `json:"owner"`
-	Resolver         string            `json:"resolver"`
-	Records          map[string]string `json:"records"`
-	RegistrationDate *time.Time        `json:"registration_date"`
-	ExpirationDate   *time.Time        `json:"expiration_date"`
-	LastUpdated      *time.Time        `json:"last_updated"`
-	SyncStatus       string            `json:"sync_status"`
+	TokenId          string                `json:"token_id"`
+	Owner            string                `json:"owner"`
+	Resolver         string                `json:"resolver"`
+	Records          map[string]string     `json:"records"`
+	RegistrationDate *time.Time            `json:"registration_date"`
+	ExpirationDate   *time.Time            `json:"expiration_date"`
+	LastUpdated      *time.Time            `json:"last_updated"`
+	SyncStatus       string                `json:"sync_status"`
+	OwnerInfo        *blockchain.OwnerInfo `json:"owner_info,omitempty"`
 }
 
 type TokenRights struct {
@@ -58,193 +88,130 @@ func NewClient() *Client {
 		httpClient: &http.Client{
 			Timeout: 15 * time.Second,
 		},
-		baseURL: "https://api.doma.xyz",
-		timeout: 15 * time.Second,
+		baseURL:   "https://api.doma.xyz",
+		timeout:   15 * time.Second,
+		explorers: blockchain.DefaultExplorers,
 	}
 }
 
-func (c *Client) CheckDomain(domain string) (*Result, error) {
-	result := &Result{
-		Domain:         domain,
-		CheckedAt:      time.Now(),
-		CrossChainData: make(map[string]interface{}),
-	}
-
-	// Check if domain is tokenized on DOMA Protocol
-	tokenized, err := c.isTokenized(domain)
-	if err != nil {
-		result.Error = err.Error()
-		return result, nil
-	}
-
-	result.IsTokenized = tokenized
+// SetSource configures where tokenization data comes from. Without a
+// source every domain's status is unknown; no status is ever guessed.
+func (c *Client) SetSource(source Source) {
+	c.source = source
+}
 
-	if tokenized {
-		// Get detailed DOMA record data
-		record, err := c.getDomaRecord(domain)
-		if err == nil {
-			result.DomaRecord = record
-		}
+// Configured reports whether a data source is set.
+func (c *Client) Configured() bool {
+	return c.source != nil
+}
 
-		// Get token rights information
-		rights, err := c.getTokenRights(domain)
-		if err == nil {
-			result.TokenRights = rights
+// APISource returns a Source that looks domains up in the DOMA API at the
+// client's endpoint, with the key set by SetAPIKey. A name the API does not
+// know is not tokenized.
+func (c *Client) APISource() Source {
+	return func(ctx context.Context, domain string) (*Result, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v1/domains/"+url.PathEscape(domain), nil)
+		if err != nil {
+			return nil, err
 		}
-
-		// Get DeFi status
-		defiStatus, err := c.getDeFiStatus(domain)
-		if err == nil {
-			result.DeFiStatus = defiStatus
+		req.Header.Set("Api-Key", c.apiKey)
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("DOMA lookup failed: %v", err)
 		}
-
-		// Get cross-chain data
-		crossChain, err := c.getCrossChainData(domain)
-		if err == nil {
-			result.CrossChainData = crossChain
+		defer resp.Body.Close()
+
+		var result Result
+		switch resp.StatusCode {
+		case http.StatusOK:
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				return nil, fmt.Errorf("invalid DOMA response: %v", err)
+			}
+		case http.StatusNotFound:
+			no := false
+			result.IsTokenized = &no
+		default:
+			return nil, fmt.Errorf("DOMA lookup returned HTTP %d", resp.StatusCode)
 		}
-
-		// Determine tokenization chain
-		result.TokenizationChain = c.getTokenizationChain(domain)
-	}
-
-	return result, nil
-}
-
-func (c *Client) isTokenized(domain string) (bool, error) {
-	// In a real implementation, this would call the DOMA API
-	// For now, simulate based on domain characteristics
-
-	// Check if it's a traditional domain that could be tokenized
-	if strings.Contains(domain, ".com") || strings.Contains(domain, ".net") ||
-		strings.Contains(domain, ".org") || strings.Contains(domain, ".io") {
-		// Simulate some domains being tokenized
-		return c.simulateTokenizationStatus(domain), nil
-	}
-
-	// Blockchain domains might also be tokenized through DOMA
-	if strings.Contains(domain, ".eth") || strings.Contains(domain, ".crypto") {
-		return c.simulateTokenizationStatus(domain), nil
+		return &result, nil
 	}
-
-	return false, nil
-}
-
-func (c *Client) getDomaRecord(domain string) (*DomaRecord, error) {
-	// Simulate DOMA Record data
-	now := time.Now()
-	expiry := now.AddDate(1, 0, 0)        // 1 year from now
-	registration := now.AddDate(-1, 0, 0) // 1 year ago
-
-	return &DomaRecord{
-		TokenId:  c.generateTokenId(domain),
-		Owner:    "0x" + strings.Repeat("1", 40),
-		Resolver: "0x" + strings.Repeat("2", 40),
-		Records: map[string]string{
-			"A":    "192.168.1.1",
-			"AAAA": "2001:db8::1",
-			"TXT":  "v=spf1 include:_spf.google.com ~all",
-			"ETH":  "0x" + strings.Repeat("3", 40),
-			"BTC":  "bc1" + strings.Repeat("4", 39),
-		},
-		RegistrationDate: &registration,
-		ExpirationDate:   &expiry,
-		LastUpdated:      &now,
-		SyncStatus:       "synced",
-	}, nil
 }
 
-func (c *Client) getTokenRights(domain string) (*TokenRights, error) {
-	// Simulate token rights data
-	return &TokenRights{
-		Total:     1000,
-		Available: 750,
-		Locked:    250,
-		RightsBreakdown: map[string]interface{}{
-			"ownership":  500,
-			"revenue":    300,
-			"governance": 150,
-			"utility":    50,
-		},
-		FractionalOwners: []string{
-			"0x" + strings.Repeat("a", 40),
-			"0x" + strings.Repeat("b", 40),
-			"0x" + strings.Repeat("c", 40),
-		},
-	}, nil
+// SetExplorers overrides the block explorers used for links; chains not in
+// e keep their default explorer.
+func (c *Client) SetExplorers(e blockchain.Explorers) {
+	c.explorers = e.Merge()
 }
 
-func (c *Client) getDeFiStatus(domain string) (*DeFiStatus, error) {
-	// Simulate DeFi integration status
-	return &DeFiStatus{
-		IsCollateral:    true,
-		LendingPlatform: "DOMA Lending",
-		CollateralValue: 50000.0,
-		BorrowedAmount:  30000.0,
-		YieldGeneration: true,
-		StakingRewards:  125.50,
-	}, nil
+// SetTimeout bounds each DOMA API request.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+	c.httpClient.Timeout = timeout
 }
 
-func (c *Client) getCrossChainData(domain string) (map[string]interface{}, error) {
-	// Simulate cross-chain presence data
-	return map[string]interface{}{
-		"ethereum": map[string]interface{}{
-			"contract_address": "0x" + strings.Repeat("e", 40),
-			"token_id":         c.generateTokenId(domain),
-			"last_update":      time.Now().Unix(),
-		},
-		"polygon": map[string]interface{}{
-			"contract_address": "0x" + strings.Repeat("f", 40),
-			"bridged":          true,
-			"bridge_fee":       0.01,
-		},
-		"arbitrum": map[string]interface{}{
-			"contract_address": "0x" + strings.Repeat("d", 40),
-			"layer2_benefits":  true,
-			"gas_savings":      "95%",
-		},
-	}, nil
+// Endpoint returns the DOMA API base URL.
+func (c *Client) Endpoint() string {
+	return c.baseURL
 }
 
-func (c *Client) getTokenizationChain(domain string) string {
-	// Determine primary tokenization chain
-	// In practice, this would come from the API response
-	return "ethereum"
+func (c *Client) CheckDomain(domain string) (*Result, error) {
+	return c.CheckDomainContext(context.Background(), domain)
 }
 
-func (c *Client) simulateTokenizationStatus(domain string) bool {
-	// Simulate tokenization - premium/short domains more likely to be tokenized
-	domainPart := strings.Split(domain, ".")[0]
-
-	// Short domains (3 chars or less) are likely tokenized
-	if len(domainPart) <= 3 {
-		return true
+// CheckDomainContext is CheckDomain with the source bounded by ctx.
+func (c *Client) CheckDomainContext(ctx context.Context, domain string) (*Result, error) {
+	if c.source == nil {
+		return &Result{Domain: domain, Status: StatusUnknown, Note: notConfigured, CheckedAt: time.Now()}, nil
 	}
 
-	// Common/premium domains might be tokenized
-	premiumDomains := []string{"crypto", "defi", "nft", "web3", "blockchain", "ethereum", "bitcoin"}
-	for _, premium := range premiumDomains {
-		if strings.Contains(strings.ToLower(domainPart), premium) {
-			return true
-		}
+	result, err := c.source(ctx, domain)
+	if err != nil {
+		return &Result{Domain: domain, Status: StatusUnknown, Error: err.Error(), CheckedAt: time.Now()}, nil
 	}
-
-	// Dictionary words might be tokenized
-	if len(domainPart) >= 4 && len(domainPart) <= 8 {
-		return len(domainPart)%2 == 0 // Simulate 50% chance for medium length domains
+	result.Domain = domain
+	result.CheckedAt = time.Now()
+	switch {
+	case result.IsTokenized == nil:
+		result.Status = StatusUnknown
+	case *result.IsTokenized:
+		result.Status = StatusTokenized
+		result.Links = c.explorerLinks(result)
+	default:
+		result.Status = StatusNotTokenized
 	}
-
-	return false
+	return result, nil
 }
 
-func (c *Client) generateTokenId(domain string) string {
-	// Generate a simulated token ID based on domain
-	hash := fmt.Sprintf("%x", []byte(domain))
-	if len(hash) > 20 {
-		hash = hash[:20]
+// explorerLinks links the token owner, and the token contract on each chain
+// it is deployed to, to that chain's block explorer.
+func (c *Client) explorerLinks(result *Result) []blockchain.Link {
+	var links []blockchain.Link
+	if record := result.DomaRecord; record != nil {
+		links = append(links, c.explorers.AddressLink(result.TokenizationChain, "owner", record.Owner)...)
+	}
+
+	chains := make([]string, 0, len(result.CrossChainData))
+	for chain := range result.CrossChainData {
+		chains = append(chains, chain)
+	}
+	sort.Strings(chains)
+	for _, chain := range chains {
+		data, _ := result.CrossChainData[chain].(map[string]interface{})
+		contract, _ := data["contract_address"].(string)
+		if contract == "" {
+			continue
+		}
+		tokenID, _ := data["token_id"].(string)
+		if tokenID == "" {
+			links = append(links, c.explorers.AddressLink(chain, chain+" contract", contract)...)
+			continue
+		}
+		for _, link := range c.explorers.TokenLinks(chain, contract, tokenID) {
+			link.Label = chain + " " + link.Label
+			links = append(links, link)
+		}
 	}
-	return hash
+	return links
 }
 
 // Helper function to check if domain could be eligible for DOMA tokenization
@@ -0,0 +1,88 @@
+package doma
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckDomainWithoutSource(t *testing.T) {
+	result, err := NewClient().CheckDomain("abc.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != StatusUnknown || result.IsTokenized != nil || result.DomaRecord != nil || result.Note == "" {
+		t.Errorf("expected an unknown result, got %+v", result)
+	}
+}
+
+func TestCheckDomainWithSource(t *testing.T) {
+	yes, no := true, false
+	c := NewClient()
+	c.SetSource(func(ctx context.Context, domain string) (*Result, error) {
+		switch domain {
+		case "tokenized.com":
+			return &Result{IsTokenized: &yes, TokenizationChain: "ethereum", DomaRecord: &DomaRecord{Owner: "0x" + fmt.Sprintf("%040d", 1)}}, nil
+		case "plain.com":
+			return &Result{IsTokenized: &no}, nil
+		}
+		return nil, fmt.Errorf("DOMA API returned HTTP 503")
+	})
+
+	tests := map[string]string{"tokenized.com": StatusTokenized, "plain.com": StatusNotTokenized, "down.com": StatusUnknown}
+	for domain, want := range tests {
+		result, err := c.CheckDomain(domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status != want || result.Domain != domain {
+			t.Errorf("%s: status %s, want %s", domain, result.Status, want)
+		}
+	}
+	if result, _ := c.CheckDomain("tokenized.com"); len(result.Links) != 1 {
+		t.Errorf("expected an owner link, got %+v", result.Links)
+	}
+	if result, _ := c.CheckDomain("down.com"); result.Error == "" {
+		t.Error("expected the source error to be reported")
+	}
+}
+
+func TestAPISource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Api-Key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/domains/tokenized.com":
+			fmt.Fprint(w, `{"is_tokenized": true, "tokenization_chain": "ethereum", "doma_record": {"token_id": "7", "owner": "0x0000000000000000000000000000000000000001"}}`)
+		case "/v1/domains/plain.com":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient()
+	c.SetEndpoint(srv.URL)
+	c.SetAPIKey("key")
+	c.SetSource(c.APISource())
+
+	tests := map[string]string{"tokenized.com": StatusTokenized, "plain.com": StatusNotTokenized, "down.com": StatusUnknown}
+	for domain, want := range tests {
+		result, _ := c.CheckDomain(domain)
+		if result.Status != want {
+			t.Errorf("%s: status %s, want %s (%+v)", domain, result.Status, want, result)
+		}
+	}
+	if result, _ := c.CheckDomain("tokenized.com"); result.DomaRecord == nil || result.DomaRecord.TokenId != "7" {
+		t.Errorf("expected the DOMA record, got %+v", result)
+	}
+	if result, _ := c.CheckDomain("down.com"); !strings.Contains(result.Error, "HTTP 503") {
+		t.Errorf("expected the HTTP error, got %q", result.Error)
+	}
+}
@@ -0,0 +1,58 @@
+package doma
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// LendingParams are DOMA Lending's current loan terms for tokenized
+// domains.
+type LendingParams struct {
+	MaxLTV               float64   `json:"max_ltv"`
+	LiquidationThreshold float64   `json:"liquidation_threshold,omitempty"`
+	APR                  float64   `json:"interest_rate_apr,omitempty"`
+	MinLoan              float64   `json:"min_loan,omitempty"`
+	UpdatedAt            time.Time `json:"updated_at,omitempty"`
+}
+
+// SetAPIKey configures the DOMA API key used for lending parameters.
+func (c *Client) SetAPIKey(key string) {
+	c.apiKey = key
+}
+
+// SetEndpoint overrides the DOMA API base URL.
+func (c *Client) SetEndpoint(url string) {
+	c.baseURL = url
+}
+
+// LendingParameters fetches DOMA Lending's live loan terms. It needs an API
+// key.
+func (c *Client) LendingParameters() (*LendingParams, error) {
+	if c.apiKey == "" {
+		return nil, fmt.Errorf("DOMA API key not configured")
+	}
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/v1/lending/parameters", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Api-Key", c.apiKey)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("DOMA lending parameters request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DOMA lending parameters returned HTTP %d", resp.StatusCode)
+	}
+
+	var params LendingParams
+	if err := json.NewDecoder(resp.Body).Decode(&params); err != nil {
+		return nil, fmt.Errorf("invalid DOMA lending parameters: %v", err)
+	}
+	if params.MaxLTV <= 0 || params.MaxLTV > 1 {
+		return nil, fmt.Errorf("invalid DOMA lending parameters: max_ltv %v", params.MaxLTV)
+	}
+	return &params, nil
+}
@@ -0,0 +1,152 @@
+package email
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"d3-domain-tool/internal/suffix"
+)
+
+// DMARCAnalysis describes the domain's DMARC policy and, when it is missing
+// or weak, a record that could replace it.
+type DMARCAnalysis struct {
+	Present bool   `json:"present"`
+	Record  string `json:"record,omitempty"`
+	// Domain is where Record was found: the domain itself or, when it has
+	// none of its own, its organizational domain (RFC 7489 section 6.6.3).
+	Domain            string            `json:"domain,omitempty"`
+	Tags              map[string]string `json:"tags,omitempty"`
+	Policy            string            `json:"policy,omitempty"`
+	SubdomainPolicy   string            `json:"subdomain_policy,omitempty"`
+	Issues            []string          `json:"issues,omitempty"`
+	RecommendedRecord string            `json:"recommended_record,omitempty"`
+	// TempError is the DNS failure that left the policy unknown.
+	TempError string `json:"temperror,omitempty"`
+}
+
+// ParseDMARC parses a v=DMARC1 record into its tags. It returns nil if txt
+// is not a DMARC record.
+func ParseDMARC(txt string) map[string]string {
+	if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(txt)), "v=dmarc1") {
+		return nil
+	}
+	tags := make(map[string]string)
+	for _, part := range strings.Split(txt, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		tags[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return tags
+}
+
+// AnalyzeDMARC fetches _dmarc.<domain>, falling back to the record of the
+// organizational domain as receivers do, and evaluates the policy.
+func (a *Auditor) AnalyzeDMARC(domain string) *DMARCAnalysis {
+	analysis := &DMARCAnalysis{}
+
+	owners := []string{domain}
+	if org := suffix.Registrable(domain); org != strings.ToLower(strings.TrimSuffix(domain, ".")) {
+		owners = append(owners, org)
+	}
+	for _, owner := range owners {
+		txts, err := a.txtRecords("_dmarc." + owner)
+		if err != nil {
+			analysis.TempError = fmt.Sprintf("DMARC lookup of _dmarc.%s failed: %v", owner, err)
+			return analysis
+		}
+		for _, txt := range txts {
+			if tags := ParseDMARC(txt); tags != nil {
+				analysis.Present = true
+				analysis.Record = txt
+				analysis.Domain = owner
+				analysis.Tags = tags
+				break
+			}
+		}
+		if analysis.Present {
+			break
+		}
+	}
+
+	if !analysis.Present {
+		analysis.Issues = append(analysis.Issues, "No DMARC record; receivers have no policy for mail failing SPF/DKIM")
+		analysis.RecommendedRecord = RecommendDMARC(domain, nil)
+		return analysis
+	}
+
+	analysis.Policy = strings.ToLower(analysis.Tags["p"])
+	analysis.SubdomainPolicy = strings.ToLower(analysis.Tags["sp"])
+	effectiveSP := analysis.SubdomainPolicy
+	if effectiveSP == "" {
+		effectiveSP = analysis.Policy
+	}
+
+	// An inherited record applies its subdomain policy to this domain.
+	applied, tag := analysis.Policy, "p"
+	if analysis.Domain != owners[0] && analysis.SubdomainPolicy != "" {
+		applied, tag = analysis.SubdomainPolicy, "sp"
+	}
+
+	weak := false
+	switch applied {
+	case "none":
+		weak = true
+		analysis.Issues = append(analysis.Issues, tag+"=none only monitors; spoofed mail is still delivered")
+	case "quarantine", "reject":
+	default:
+		weak = true
+		analysis.Issues = append(analysis.Issues, fmt.Sprintf("Invalid or missing p= tag (%q)", analysis.Tags["p"]))
+	}
+
+	if effectiveSP == "none" && applied != "none" {
+		weak = true
+		analysis.Issues = append(analysis.Issues, "sp=none leaves subdomains unprotected; attackers can spoof any subdomain")
+	}
+
+	if pct, ok := analysis.Tags["pct"]; ok {
+		if n, err := strconv.Atoi(pct); err == nil && n < 100 {
+			weak = true
+			analysis.Issues = append(analysis.Issues, fmt.Sprintf("pct=%d applies the policy to only part of failing mail", n))
+		}
+	}
+
+	if analysis.Tags["rua"] == "" {
+		weak = true
+		analysis.Issues = append(analysis.Issues, "No rua= address; you receive no aggregate reports to see who sends as you")
+	}
+
+	if weak {
+		analysis.RecommendedRecord = RecommendDMARC(analysis.Domain, analysis.Tags)
+	}
+	return analysis
+}
+
+// RecommendDMARC builds a record that enforces quarantine for the domain and
+// its subdomains, keeping existing report addresses when present.
+func RecommendDMARC(domain string, existing map[string]string) string {
+	policy := "quarantine"
+	if existing != nil && strings.EqualFold(existing["p"], "reject") {
+		policy = "reject"
+	}
+
+	rua := "mailto:dmarc-reports@" + domain
+	if existing != nil && existing["rua"] != "" {
+		rua = existing["rua"]
+	}
+
+	parts := []string{
+		"v=DMARC1",
+		"p=" + policy,
+		"sp=" + policy,
+		"pct=100",
+		"rua=" + rua,
+	}
+	if existing != nil && existing["ruf"] != "" {
+		parts = append(parts, "ruf="+existing["ruf"])
+	}
+	parts = append(parts, "adkim=r", "aspf=r")
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,236 @@
+package email
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Prober connects to a domain's MX hosts to check whether mail
+// infrastructure is alive. It never sends mail: the conversation stops after
+// EHLO (and STARTTLS, when offered).
+type Prober struct {
+	timeout  time.Duration
+	ports    []int
+	maxHosts int
+	heloName string
+	lookupMX func(name string) ([]*net.MX, error)
+}
+
+type ProbeResult struct {
+	MXHosts   []string   `json:"mx_hosts"`
+	Alive     bool       `json:"alive"`
+	Endpoints []Endpoint `json:"endpoints,omitempty"`
+	// Note says why nothing was probed when the domain publishes no MX
+	// records or a null MX; that is a finding, not a failure.
+	Note      string    `json:"note,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Endpoint is the outcome of probing one MX host on one port.
+type Endpoint struct {
+	Host       string   `json:"host"`
+	Port       int      `json:"port"`
+	Reachable  bool     `json:"reachable"`
+	Banner     string   `json:"banner,omitempty"`
+	Extensions []string `json:"extensions,omitempty"`
+	StartTLS   bool     `json:"starttls"`
+	TLSVersion string   `json:"tls_version,omitempty"`
+	CertIssuer string   `json:"cert_issuer,omitempty"`
+	CertValid  bool     `json:"cert_valid"`
+	Error      string   `json:"error,omitempty"`
+}
+
+func NewProber() *Prober {
+	return &Prober{
+		timeout:  8 * time.Second,
+		ports:    []int{25, 465, 587},
+		maxHosts: 3,
+		heloName: "d3-domain-tool.local",
+		lookupMX: net.LookupMX,
+	}
+}
+
+// Ports returns the SMTP ports checked on each MX host.
+func (p *Prober) Ports() []int {
+	return p.ports
+}
+
+func (p *Prober) Probe(domain string) (*ProbeResult, error) {
+	result := &ProbeResult{
+		CheckedAt: time.Now(),
+	}
+
+	mxRecords, err := p.lookupMX(domain)
+	var dnsErr *net.DNSError
+	if err != nil && !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+		result.Error = fmt.Sprintf("MX lookup failed: %v", err)
+		return result, nil
+	}
+	if len(mxRecords) == 0 {
+		result.Note = "no MX records found"
+		return result, nil
+	}
+	// A null MX (RFC 7505) declares that the domain accepts no mail.
+	if len(mxRecords) == 1 && strings.TrimSuffix(mxRecords[0].Host, ".") == "" {
+		result.Note = "null MX: the domain accepts no mail"
+		return result, nil
+	}
+
+	// The resolver shuffles hosts of equal preference; order them by name so
+	// repeated runs match.
+	sort.Slice(mxRecords, func(i, j int) bool {
+		if mxRecords[i].Pref != mxRecords[j].Pref {
+			return mxRecords[i].Pref < mxRecords[j].Pref
+		}
+		return mxRecords[i].Host < mxRecords[j].Host
+	})
+	for _, mx := range mxRecords {
+		result.MXHosts = append(result.MXHosts, strings.TrimSuffix(mx.Host, "."))
+	}
+
+	hosts := result.MXHosts
+	if len(hosts) > p.maxHosts {
+		hosts = hosts[:p.maxHosts]
+	}
+
+	for _, host := range hosts {
+		for _, port := range p.ports {
+			ep := p.probeEndpoint(host, port)
+			if ep.Reachable && ep.Banner != "" {
+				result.Alive = true
+			}
+			result.Endpoints = append(result.Endpoints, ep)
+		}
+	}
+
+	return result, nil
+}
+
+func (p *Prober) probeEndpoint(host string, port int) Endpoint {
+	ep := Endpoint{Host: host, Port: port}
+	addr := net.JoinHostPort(host, fmt.Sprint(port))
+
+	conn, err := net.DialTimeout("tcp", addr, p.timeout)
+	if err != nil {
+		ep.Error = err.Error()
+		return ep
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(p.timeout))
+	ep.Reachable = true
+
+	// Port 465 speaks TLS from the first byte (SMTPS).
+	if port == 465 {
+		tlsConn := tls.Client(conn, probeTLSConfig(host))
+		if err := tlsConn.Handshake(); err != nil {
+			ep.Error = fmt.Sprintf("TLS handshake failed: %v", err)
+			return ep
+		}
+		recordTLS(&ep, tlsConn, host)
+		conn = tlsConn
+	}
+
+	text := textproto.NewConn(conn)
+	_, banner, err := text.ReadResponse(220)
+	if err != nil {
+		ep.Error = fmt.Sprintf("no SMTP greeting: %v", err)
+		return ep
+	}
+	ep.Banner = firstLine(banner)
+
+	extensions, err := ehlo(text, p.heloName)
+	if err != nil {
+		ep.Error = err.Error()
+		return ep
+	}
+	ep.Extensions = extensions
+
+	for _, ext := range extensions {
+		if strings.EqualFold(ext, "STARTTLS") {
+			ep.StartTLS = true
+		}
+	}
+
+	if ep.StartTLS && port != 465 {
+		if err := text.PrintfLine("STARTTLS"); err == nil {
+			if _, _, err := text.ReadResponse(220); err == nil {
+				tlsConn := tls.Client(conn, probeTLSConfig(host))
+				if err := tlsConn.Handshake(); err != nil {
+					ep.Error = fmt.Sprintf("STARTTLS handshake failed: %v", err)
+					return ep
+				}
+				recordTLS(&ep, tlsConn, host)
+				text = textproto.NewConn(tlsConn)
+			}
+		}
+	}
+
+	text.PrintfLine("QUIT")
+	return ep
+}
+
+func ehlo(text *textproto.Conn, name string) ([]string, error) {
+	if err := text.PrintfLine("EHLO %s", name); err != nil {
+		return nil, fmt.Errorf("failed to send EHLO: %v", err)
+	}
+	_, msg, err := text.ReadResponse(250)
+	if err != nil {
+		return nil, fmt.Errorf("EHLO rejected: %v", err)
+	}
+
+	// The first line is the server's greeting; the rest are extensions.
+	lines := strings.Split(msg, "\n")
+	var extensions []string
+	for _, line := range lines[1:] {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			extensions = append(extensions, strings.ToUpper(fields[0]))
+		}
+	}
+	return extensions, nil
+}
+
+// probeTLSConfig skips verification during the handshake so that hosts with
+// self-signed or mismatched certificates can still be inspected; validity is
+// checked separately in recordTLS.
+func probeTLSConfig(host string) *tls.Config {
+	return &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: true,
+	}
+}
+
+func recordTLS(ep *Endpoint, conn *tls.Conn, host string) {
+	state := conn.ConnectionState()
+	ep.TLSVersion = tls.VersionName(state.Version)
+	if len(state.PeerCertificates) == 0 {
+		return
+	}
+
+	cert := state.PeerCertificates[0]
+	ep.CertIssuer = cert.Issuer.CommonName
+
+	intermediates := x509.NewCertPool()
+	for _, c := range state.PeerCertificates[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err := cert.Verify(x509.VerifyOptions{
+		DNSName:       host,
+		Intermediates: intermediates,
+	})
+	ep.CertValid = err == nil
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,126 @@
+package email
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP serves one connection on a local port with serve and returns a
+// prober whose only MX host and port point at it.
+func fakeSMTP(t *testing.T, serve func(conn net.Conn, r *bufio.Reader)) *Prober {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		serve(conn, bufio.NewReader(conn))
+	}()
+
+	p := NewProber()
+	p.timeout = 200 * time.Millisecond
+	p.ports = []int{ln.Addr().(*net.TCPAddr).Port}
+	p.lookupMX = func(name string) ([]*net.MX, error) {
+		return []*net.MX{{Host: "127.0.0.1.", Pref: 10}}, nil
+	}
+	return p
+}
+
+func probeOne(t *testing.T, p *Prober) (*ProbeResult, Endpoint) {
+	t.Helper()
+	result, err := p.Probe("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Endpoints) != 1 {
+		t.Fatalf("expected one endpoint, got %+v", result)
+	}
+	return result, result.Endpoints[0]
+}
+
+func TestProbe_NoSTARTTLS(t *testing.T) {
+	p := fakeSMTP(t, func(conn net.Conn, r *bufio.Reader) {
+		conn.Write([]byte("220 mx.example.com ESMTP ready\r\n"))
+		if line, _ := r.ReadString('\n'); !strings.HasPrefix(line, "EHLO ") {
+			t.Errorf("expected EHLO, got %q", line)
+			return
+		}
+		conn.Write([]byte("250-mx.example.com\r\n250-SIZE 10240000\r\n250 8BITMIME\r\n"))
+		if line, _ := r.ReadString('\n'); line != "QUIT\r\n" {
+			t.Errorf("expected QUIT without STARTTLS, got %q", line)
+		}
+	})
+
+	result, ep := probeOne(t, p)
+	if !result.Alive || len(result.MXHosts) != 1 || result.MXHosts[0] != "127.0.0.1" {
+		t.Errorf("expected a live MX, got %+v", result)
+	}
+	if !ep.Reachable || ep.Banner != "mx.example.com ESMTP ready" || ep.StartTLS || ep.TLSVersion != "" || ep.Error != "" {
+		t.Errorf("unexpected endpoint %+v", ep)
+	}
+	if len(ep.Extensions) != 2 || ep.Extensions[0] != "SIZE" || ep.Extensions[1] != "8BITMIME" {
+		t.Errorf("unexpected extensions %v", ep.Extensions)
+	}
+}
+
+func TestProbe_RejectingBanner(t *testing.T) {
+	p := fakeSMTP(t, func(conn net.Conn, r *bufio.Reader) {
+		conn.Write([]byte("554 5.7.1 No SMTP service here\r\n"))
+	})
+
+	result, ep := probeOne(t, p)
+	if result.Alive {
+		t.Error("a 5xx greeting is not a live mail server")
+	}
+	if !ep.Reachable || ep.Banner != "" || !strings.Contains(ep.Error, "no SMTP greeting") || !strings.Contains(ep.Error, "554") {
+		t.Errorf("unexpected endpoint %+v", ep)
+	}
+}
+
+func TestProbe_Timeout(t *testing.T) {
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	p := fakeSMTP(t, func(conn net.Conn, r *bufio.Reader) {
+		<-done
+	})
+
+	start := time.Now()
+	result, ep := probeOne(t, p)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("probe took %v despite the timeout", elapsed)
+	}
+	if result.Alive || !ep.Reachable || !strings.Contains(ep.Error, "timeout") {
+		t.Errorf("expected a greeting timeout, got %+v", ep)
+	}
+}
+
+func TestProbe_NoMX(t *testing.T) {
+	p := NewProber()
+	p.lookupMX = func(name string) ([]*net.MX, error) {
+		switch name {
+		case "nomail.example":
+			return []*net.MX{{Host: ".", Pref: 0}}, nil
+		case "down.example":
+			return nil, &net.DNSError{Err: "i/o timeout", Name: name, IsTimeout: true}
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+
+	for _, domain := range []string{"nomail.example", "nothing.example"} {
+		if result, _ := p.Probe(domain); result.Error != "" || result.Note == "" || len(result.Endpoints) != 0 {
+			t.Errorf("%s: expected no MX to be a note, not an error: %+v", domain, result)
+		}
+	}
+	if result, _ := p.Probe("down.example"); !strings.Contains(result.Error, "MX lookup failed") || result.Note != "" {
+		t.Errorf("expected a lookup failure, got %+v", result)
+	}
+}
@@ -0,0 +1,226 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// MaxSPFLookups is the RFC 7208 limit on DNS-querying mechanisms.
+const MaxSPFLookups = 10
+
+// maxVoidLookups is the RFC 7208 recommended limit on lookups that return
+// no records.
+const maxVoidLookups = 2
+
+// Auditor evaluates a domain's email authentication records.
+type Auditor struct {
+	lookupTXT func(name string) ([]string, error)
+}
+
+// SecurityResult is the email security section of a report.
+type SecurityResult struct {
+	SPF       *SPFAnalysis   `json:"spf,omitempty"`
+	DMARC     *DMARCAnalysis `json:"dmarc,omitempty"`
+	CheckedAt time.Time      `json:"checked_at"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// SPFAnalysis is the outcome of recursively evaluating a domain's SPF policy.
+type SPFAnalysis struct {
+	Present     bool   `json:"present"`
+	Record      string `json:"record,omitempty"`
+	LookupCount int    `json:"lookup_count"`
+	VoidLookups int    `json:"void_lookups"`
+	AllPolicy   string `json:"all_policy,omitempty"`
+	PermError   bool   `json:"permerror"`
+	// TempError is the DNS failure that left the evaluation incomplete.
+	TempError string   `json:"temperror,omitempty"`
+	Flattened []string `json:"flattened,omitempty"`
+	Issues    []string `json:"issues,omitempty"`
+}
+
+func NewAuditor() *Auditor {
+	return &Auditor{
+		lookupTXT: net.LookupTXT,
+	}
+}
+
+func (a *Auditor) Audit(domain string) (*SecurityResult, error) {
+	result := &SecurityResult{
+		CheckedAt: time.Now(),
+	}
+
+	result.SPF = a.AnalyzeSPF(domain)
+	result.DMARC = a.AnalyzeDMARC(domain)
+	var failures []string
+	for _, msg := range []string{result.SPF.TempError, result.DMARC.TempError} {
+		if msg != "" {
+			failures = append(failures, msg)
+		}
+	}
+	result.Error = strings.Join(failures, "; ")
+
+	return result, nil
+}
+
+// txtRecords returns the TXT records at name, none when the name does not
+// exist or has none. Any other failure is a temporary DNS error.
+func (a *Auditor) txtRecords(name string) ([]string, error) {
+	txts, err := a.lookupTXT(name)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return nil, nil
+	}
+	return txts, err
+}
+
+// AnalyzeSPF fetches the domain's SPF record and walks every include and
+// redirect, counting DNS lookups against the 10-lookup limit and collecting
+// the ip4/ip6 ranges a flattened record would need.
+func (a *Auditor) AnalyzeSPF(domain string) *SPFAnalysis {
+	analysis := &SPFAnalysis{}
+
+	records, err := a.spfRecords(domain)
+	if err != nil {
+		analysis.TempError = fmt.Sprintf("SPF lookup of %s failed: %v", domain, err)
+		return analysis
+	}
+	if len(records) == 0 {
+		analysis.Issues = append(analysis.Issues, "No SPF record published; anyone can spoof mail from this domain")
+		return analysis
+	}
+	if len(records) > 1 {
+		analysis.PermError = true
+		analysis.Issues = append(analysis.Issues, fmt.Sprintf("%d SPF records published; receivers treat this as permerror", len(records)))
+	}
+
+	analysis.Present = true
+	analysis.Record = records[0].Raw
+
+	walker := &spfWalker{auditor: a, analysis: analysis, seen: map[string]bool{strings.ToLower(domain): true}}
+	walker.walk(records[0], 0)
+
+	if analysis.LookupCount > MaxSPFLookups {
+		analysis.PermError = true
+		analysis.Issues = append(analysis.Issues, fmt.Sprintf("%d DNS lookups exceeds the limit of %d (permerror); flatten includes into ip4/ip6 ranges", analysis.LookupCount, MaxSPFLookups))
+	}
+	if analysis.VoidLookups > maxVoidLookups {
+		analysis.PermError = true
+		analysis.Issues = append(analysis.Issues, fmt.Sprintf("%d void lookups exceeds the limit of %d", analysis.VoidLookups, maxVoidLookups))
+	}
+
+	switch analysis.AllPolicy {
+	case "+all":
+		analysis.Issues = append(analysis.Issues, "+all authorizes every server on the internet to send as this domain")
+	case "?all":
+		analysis.Issues = append(analysis.Issues, "?all is neutral and provides no protection")
+	case "":
+		analysis.Issues = append(analysis.Issues, "No all mechanism; unlisted senders get a neutral result")
+	}
+
+	return analysis
+}
+
+func (a *Auditor) spfRecords(domain string) ([]*SPFRecord, error) {
+	txts, err := a.txtRecords(domain)
+	if err != nil {
+		return nil, err
+	}
+	var records []*SPFRecord
+	for _, txt := range txts {
+		if spf := ParseSPF(txt); spf != nil {
+			records = append(records, spf)
+		}
+	}
+	return records, nil
+}
+
+type spfWalker struct {
+	auditor  *Auditor
+	analysis *SPFAnalysis
+	seen     map[string]bool // domains on the current include path
+}
+
+// maxSPFDepth stops runaway recursion on pathological records.
+const maxSPFDepth = 10
+
+func (w *spfWalker) walk(record *SPFRecord, depth int) {
+	for _, m := range record.Mechanisms {
+		switch m.Name {
+		case "include":
+			w.analysis.LookupCount++
+			w.follow(m.Value, depth, "include")
+		case "a", "mx", "exists":
+			w.analysis.LookupCount++
+		case "ptr":
+			w.analysis.LookupCount++
+			w.issue("ptr mechanism is deprecated and slow")
+		case "ip4", "ip6":
+			w.analysis.Flattened = append(w.analysis.Flattened, m.Name+":"+m.Value)
+		case "all":
+			if depth == 0 {
+				w.analysis.AllPolicy = m.Qualifier + "all"
+			}
+		}
+	}
+
+	if record.Redirect != "" {
+		w.analysis.LookupCount++
+		w.follow(record.Redirect, depth, "redirect")
+	}
+}
+
+func (w *spfWalker) follow(domain string, depth int, via string) {
+	key := strings.ToLower(domain)
+	if w.seen[key] {
+		w.analysis.PermError = true
+		w.issue(fmt.Sprintf("SPF loop via %s:%s", via, domain))
+		return
+	}
+	if depth >= maxSPFDepth {
+		w.issue("SPF include chain is too deep")
+		return
+	}
+
+	records, err := w.auditor.spfRecords(domain)
+	if err != nil {
+		// A temperror anywhere makes the whole evaluation one.
+		if w.analysis.TempError == "" {
+			w.analysis.TempError = fmt.Sprintf("SPF lookup of %s:%s failed: %v", via, domain, err)
+		}
+		return
+	}
+	if len(records) == 0 {
+		w.analysis.VoidLookups++
+		w.analysis.PermError = true
+		w.issue(fmt.Sprintf("%s:%s has no SPF record (permerror)", via, domain))
+		return
+	}
+
+	// A redirect replaces the all policy of the record that used it.
+	if via == "redirect" && depth == 0 {
+		for _, m := range records[0].Mechanisms {
+			if m.Name == "all" {
+				w.analysis.AllPolicy = m.Qualifier + "all"
+			}
+		}
+	}
+	w.seen[key] = true
+	w.walk(records[0], depth+1)
+
+	// Only the current include path counts as a loop; the same domain may
+	// legitimately be included from two branches.
+	delete(w.seen, key)
+}
+
+func (w *spfWalker) issue(msg string) {
+	for _, existing := range w.analysis.Issues {
+		if existing == msg {
+			return
+		}
+	}
+	w.analysis.Issues = append(w.analysis.Issues, msg)
+}
@@ -0,0 +1,168 @@
+package email
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func fakeTXT(records map[string][]string) func(string) ([]string, error) {
+	return func(name string) ([]string, error) {
+		if txt, ok := records[name]; ok {
+			return txt, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+}
+
+func TestParseSPF(t *testing.T) {
+	spf := ParseSPF("v=spf1 ip4:192.0.2.0/24 mx/24 include:_spf.example.net ~all redirect=other.example")
+	if spf == nil {
+		t.Fatal("expected SPF record")
+	}
+	if len(spf.Mechanisms) != 4 {
+		t.Fatalf("expected 4 mechanisms, got %+v", spf.Mechanisms)
+	}
+	if spf.Mechanisms[1].Name != "mx" || spf.Mechanisms[3].Qualifier != "~" {
+		t.Errorf("unexpected mechanisms: %+v", spf.Mechanisms)
+	}
+	if got := spf.Includes(); len(got) != 2 || got[0] != "_spf.example.net" || got[1] != "other.example" {
+		t.Errorf("unexpected includes: %v", got)
+	}
+
+	cidr := ParseSPF("v=spf1 mx:mail.example.com/24 a:web.example.com/28//64 a/24 -all")
+	if m := cidr.Mechanisms; m[0].Value != "mail.example.com" || m[0].CIDR != "/24" ||
+		m[1].Value != "web.example.com" || m[1].CIDR != "/28//64" || m[2].Name != "a" || m[2].CIDR != "/24" {
+		t.Errorf("expected CIDR suffixes split from the domains, got %+v", m)
+	}
+
+	if ParseSPF("google-site-verification=abc") != nil {
+		t.Error("expected nil for non-SPF record")
+	}
+}
+
+func TestAnalyzeSPF(t *testing.T) {
+	a := &Auditor{lookupTXT: fakeTXT(map[string][]string{
+		"example.com":     {"v=spf1 include:a.example include:b.example -all"},
+		"a.example":       {"v=spf1 ip4:192.0.2.1 include:c.example ~all"},
+		"b.example":       {"v=spf1 a mx ~all"},
+		"c.example":       {"v=spf1 ip6:2001:db8::/32 ~all"},
+		"loop.example":    {"v=spf1 include:loop.example -all"},
+		"open.example":    {"v=spf1 +all"},
+		"twice.example":   {"v=spf1 -all", "v=spf1 ~all"},
+		"missing.example": {"v=spf1 include:gone.example -all"},
+		"tenplus.example": {"v=spf1 " + strings.Repeat("a ", 11) + "-all"},
+		// diamond.example reaches shared.example through two branches;
+		// ping.example and pong.example include each other.
+		"diamond.example": {"v=spf1 include:left.example include:right.example -all"},
+		"left.example":    {"v=spf1 include:shared.example ~all"},
+		"right.example":   {"v=spf1 include:shared.example ~all"},
+		"shared.example":  {"v=spf1 ip4:198.51.100.0/24 ~all"},
+		"ping.example":    {"v=spf1 include:pong.example -all"},
+		"pong.example":    {"v=spf1 include:ping.example -all"},
+	})}
+
+	spf := a.AnalyzeSPF("example.com")
+	if !spf.Present || spf.PermError {
+		t.Fatalf("expected valid SPF, got %+v", spf)
+	}
+	if spf.LookupCount != 5 {
+		t.Errorf("expected 5 lookups, got %d", spf.LookupCount)
+	}
+	if spf.AllPolicy != "-all" {
+		t.Errorf("expected -all, got %s", spf.AllPolicy)
+	}
+	if len(spf.Flattened) != 2 {
+		t.Errorf("expected 2 flattened ranges, got %v", spf.Flattened)
+	}
+
+	for _, domain := range []string{"loop.example", "twice.example", "missing.example", "tenplus.example"} {
+		if !a.AnalyzeSPF(domain).PermError {
+			t.Errorf("%s: expected permerror", domain)
+		}
+	}
+
+	if diamond := a.AnalyzeSPF("diamond.example"); diamond.PermError || len(diamond.Issues) != 0 {
+		t.Errorf("an include reached from two branches is not a loop, got %+v", diamond)
+	}
+	if cycle := a.AnalyzeSPF("ping.example"); !cycle.PermError || len(cycle.Issues) == 0 || !strings.Contains(cycle.Issues[0], "SPF loop via include:ping.example") {
+		t.Errorf("expected an SPF loop, got %+v", cycle)
+	}
+
+	if open := a.AnalyzeSPF("open.example"); open.AllPolicy != "+all" || len(open.Issues) == 0 {
+		t.Errorf("expected +all issue, got %+v", open)
+	}
+
+	if none := a.AnalyzeSPF("nothing.example"); none.Present {
+		t.Error("expected no SPF record")
+	}
+
+	// A failed lookup is a temperror, not a missing record or include.
+	lookup := a.lookupTXT
+	a.lookupTXT = func(name string) ([]string, error) {
+		if name == "down.example" {
+			return nil, &net.DNSError{Err: "i/o timeout", Name: name, IsTimeout: true}
+		}
+		return lookup(name)
+	}
+	if down := a.AnalyzeSPF("down.example"); down.TempError == "" || len(down.Issues) != 0 {
+		t.Errorf("expected a temperror for the domain's own record, got %+v", down)
+	}
+	a.lookupTXT = func(name string) ([]string, error) {
+		if name == "flaky.example" {
+			return nil, &net.DNSError{Err: "i/o timeout", Name: name, IsTimeout: true}
+		}
+		if name == "uses-flaky.example" {
+			return []string{"v=spf1 include:flaky.example -all"}, nil
+		}
+		return lookup(name)
+	}
+	if flaky := a.AnalyzeSPF("uses-flaky.example"); flaky.TempError == "" || flaky.PermError || flaky.VoidLookups != 0 {
+		t.Errorf("expected a temperror for the include, got %+v", flaky)
+	}
+}
+
+func TestAnalyzeDMARC(t *testing.T) {
+	a := &Auditor{lookupTXT: fakeTXT(map[string][]string{
+		"_dmarc.strict.example":  {"v=DMARC1; p=reject; rua=mailto:d@strict.example"},
+		"_dmarc.monitor.example": {"v=DMARC1; p=none; rua=mailto:d@monitor.example"},
+		"_dmarc.subgap.example":  {"v=DMARC1; p=reject; sp=none; rua=mailto:d@subgap.example"},
+	})}
+
+	if strict := a.AnalyzeDMARC("strict.example"); len(strict.Issues) != 0 || strict.RecommendedRecord != "" {
+		t.Errorf("expected no issues, got %+v", strict)
+	}
+
+	monitor := a.AnalyzeDMARC("monitor.example")
+	if monitor.Policy != "none" || !strings.Contains(monitor.RecommendedRecord, "p=quarantine") ||
+		!strings.Contains(monitor.RecommendedRecord, "rua=mailto:d@monitor.example") {
+		t.Errorf("unexpected recommendation: %+v", monitor)
+	}
+
+	subgap := a.AnalyzeDMARC("subgap.example")
+	if len(subgap.Issues) != 1 || !strings.Contains(subgap.RecommendedRecord, "sp=reject") {
+		t.Errorf("expected subdomain gap, got %+v", subgap)
+	}
+
+	missing := a.AnalyzeDMARC("missing.example")
+	if missing.Present || !strings.Contains(missing.RecommendedRecord, "rua=mailto:dmarc-reports@missing.example") {
+		t.Errorf("unexpected missing result: %+v", missing)
+	}
+
+	// Subdomains without a record of their own get the organizational
+	// domain's, under its subdomain policy.
+	if mail := a.AnalyzeDMARC("mail.strict.example"); !mail.Present || mail.Domain != "strict.example" || len(mail.Issues) != 0 {
+		t.Errorf("expected strict.example's policy to apply, got %+v", mail)
+	}
+	if mail := a.AnalyzeDMARC("mail.subgap.example"); !mail.Present || mail.Domain != "subgap.example" || len(mail.Issues) == 0 || !strings.HasPrefix(mail.Issues[0], "sp=none") {
+		t.Errorf("expected subgap.example's sp=none to apply, got %+v", mail)
+	}
+
+	failing := &Auditor{lookupTXT: func(name string) ([]string, error) {
+		return nil, &net.DNSError{Err: "server misbehaving", Name: name, IsTemporary: true}
+	}}
+	result, _ := failing.Audit("strict.example")
+	if d := result.DMARC; d.Present || d.TempError == "" || d.RecommendedRecord != "" || result.Error == "" {
+		t.Errorf("expected a DNS failure to be a temperror, not a missing record: %+v", d)
+	}
+}
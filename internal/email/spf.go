@@ -0,0 +1,86 @@
+package email
+
+import (
+	"strings"
+)
+
+// SPFRecord is a parsed v=spf1 TXT record.
+type SPFRecord struct {
+	Raw        string      `json:"raw"`
+	Mechanisms []Mechanism `json:"mechanisms"`
+	Redirect   string      `json:"redirect,omitempty"`
+}
+
+// Mechanism is a single SPF term such as "include:_spf.google.com" or "-all".
+type Mechanism struct {
+	Qualifier string `json:"qualifier"`
+	Name      string `json:"name"`
+	Value     string `json:"value,omitempty"`
+	// CIDR is the prefix length suffix of an a or mx mechanism, such as
+	// "/24" or "//64", which is not part of its domain.
+	CIDR string `json:"cidr,omitempty"`
+}
+
+// IsSPF reports whether a TXT record is an SPF policy.
+func IsSPF(txt string) bool {
+	lower := strings.ToLower(strings.TrimSpace(txt))
+	return lower == "v=spf1" || strings.HasPrefix(lower, "v=spf1 ")
+}
+
+// ParseSPF parses an SPF record. It returns nil if txt is not SPF.
+func ParseSPF(txt string) *SPFRecord {
+	if !IsSPF(txt) {
+		return nil
+	}
+
+	record := &SPFRecord{Raw: txt}
+	for _, term := range strings.Fields(txt)[1:] {
+		lower := strings.ToLower(term)
+		if strings.HasPrefix(lower, "redirect=") {
+			record.Redirect = term[len("redirect="):]
+			continue
+		}
+		if strings.Contains(lower, "=") {
+			// Unknown modifier (e.g. exp=); not a mechanism.
+			continue
+		}
+
+		m := Mechanism{Qualifier: "+"}
+		if strings.ContainsAny(term[:1], "+-~?") {
+			m.Qualifier = term[:1]
+			term = term[1:]
+		}
+		name, value, _ := strings.Cut(term, ":")
+		if value == "" {
+			// a/24 and mx/24 carry a CIDR suffix rather than a domain.
+			if i := strings.Index(name, "/"); i >= 0 {
+				name, m.CIDR = name[:i], name[i:]
+			}
+		}
+		m.Name = strings.ToLower(name)
+		if m.Name == "a" || m.Name == "mx" {
+			// So do a:host/24 and mx:host/24 after theirs.
+			if i := strings.Index(value, "/"); i >= 0 {
+				value, m.CIDR = value[:i], value[i:]
+			}
+		}
+		m.Value = value
+		record.Mechanisms = append(record.Mechanisms, m)
+	}
+	return record
+}
+
+// Includes returns the domains referenced by include: mechanisms and the
+// redirect= modifier.
+func (r *SPFRecord) Includes() []string {
+	var domains []string
+	for _, m := range r.Mechanisms {
+		if m.Name == "include" && m.Value != "" {
+			domains = append(domains, m.Value)
+		}
+	}
+	if r.Redirect != "" {
+		domains = append(domains, r.Redirect)
+	}
+	return domains
+}
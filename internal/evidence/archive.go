@@ -0,0 +1,91 @@
+package evidence
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Archive is an evidence bundle opened for offline re-analysis.
+type Archive struct {
+	Manifest Manifest
+	files    map[string][]byte
+}
+
+// Open reads an evidence bundle and checks every artifact against the
+// hashes in its manifest. A bundle holding files its manifest does not
+// list is rejected.
+func Open(path string) (*Archive, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open evidence bundle: %v", err)
+	}
+	defer zr.Close()
+
+	archive := &Archive{files: make(map[string][]byte)}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", f.Name, err)
+		}
+		if _, dup := archive.files[f.Name]; dup {
+			return nil, fmt.Errorf("%s holds %s twice", path, f.Name)
+		}
+		archive.files[f.Name] = data
+	}
+
+	manifest, ok := archive.files[ManifestName]
+	if !ok {
+		return nil, fmt.Errorf("%s is not an evidence bundle (no %s)", path, ManifestName)
+	}
+	if err := json.Unmarshal(manifest, &archive.Manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", ManifestName, err)
+	}
+	delete(archive.files, ManifestName)
+	listed := make(map[string]bool, len(archive.Manifest.Artifacts))
+	for _, entry := range archive.Manifest.Artifacts {
+		listed[entry.Name] = true
+		data, ok := archive.files[entry.Name]
+		if !ok {
+			return nil, fmt.Errorf("artifact %s listed in the manifest is missing", entry.Name)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != entry.SHA256 {
+			return nil, fmt.Errorf("artifact %s does not match its manifest hash", entry.Name)
+		}
+	}
+	for name := range archive.files {
+		if !listed[name] {
+			return nil, fmt.Errorf("%s holds %s, which its manifest does not list", path, name)
+		}
+	}
+
+	return archive, nil
+}
+
+// Domains returns the domains with a captured report, in capture order.
+func (a *Archive) Domains() []string {
+	var domains []string
+	for _, entry := range a.Manifest.Artifacts {
+		if domain, ok := strings.CutSuffix(entry.Name, "/report.json"); ok {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// File returns an artifact listed in the manifest by its path inside the
+// bundle.
+func (a *Archive) File(name string) ([]byte, bool) {
+	data, ok := a.files[name]
+	return data, ok
+}
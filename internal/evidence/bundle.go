@@ -0,0 +1,189 @@
+package evidence
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ManifestName is the index written at the root of every bundle.
+const ManifestName = "manifest.json"
+
+// Bundle collects the raw artifacts of a run (WHOIS text, DNS answers, HTTP
+// exchanges) so they can be archived next to the parsed report.
+type Bundle struct {
+	mu        sync.Mutex
+	files     []file
+	httpSeq   int
+	createdAt time.Time
+}
+
+type file struct {
+	name       string
+	data       []byte
+	capturedAt time.Time
+}
+
+// Manifest lists every artifact in a bundle with its hash.
+type Manifest struct {
+	CreatedAt time.Time `json:"created_at"`
+	Tool      string    `json:"tool"`
+	Artifacts []Entry   `json:"artifacts"`
+}
+
+// Entry describes one archived artifact.
+type Entry struct {
+	Name       string    `json:"name"`
+	Size       int       `json:"size"`
+	SHA256     string    `json:"sha256"`
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+func New() *Bundle {
+	return &Bundle{
+		createdAt: time.Now().UTC(),
+	}
+}
+
+type scopeKey struct{}
+
+// WithScope returns a copy of ctx under which artifacts, including the
+// HTTP exchanges of requests made with it, are filed in the directory
+// scope (normally the domain being analyzed). Analyses running side by
+// side each keep their own scope.
+func WithScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, scopeKey{}, sanitize(scope))
+}
+
+func scopeOf(ctx context.Context) string {
+	scope, _ := ctx.Value(scopeKey{}).(string)
+	return scope
+}
+
+// Add stores an artifact under ctx's scope.
+func (b *Bundle) Add(ctx context.Context, name string, data []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.add(scopeOf(ctx), name, data)
+}
+
+func (b *Bundle) add(scope, name string, data []byte) {
+	if scope != "" {
+		name = scope + "/" + name
+	}
+	b.files = append(b.files, file{name: name, data: data, capturedAt: time.Now().UTC()})
+}
+
+// AddJSON stores v as indented JSON under ctx's scope.
+func (b *Bundle) AddJSON(ctx context.Context, name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %v", name, err)
+	}
+	b.Add(ctx, name, data)
+	return nil
+}
+
+// Transport wraps base so that every HTTP request and response passing
+// through it is added to the bundle.
+func (b *Bundle) Transport(base http.RoundTripper) http.RoundTripper {
+	return &recordingTransport{bundle: b, base: base}
+}
+
+type recordingTransport struct {
+	bundle *Bundle
+	base   http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	dump, _ := httputil.DumpRequestOut(req, true)
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		dump = append(dump, []byte("\n\n# error: "+err.Error()+"\n")...)
+	} else if respDump, dumpErr := httputil.DumpResponse(resp, true); dumpErr == nil {
+		dump = append(dump, []byte("\n\n")...)
+		dump = append(dump, respDump...)
+	}
+
+	b := t.bundle
+	b.mu.Lock()
+	b.httpSeq++
+	b.add(scopeOf(req.Context()), fmt.Sprintf("http/%03d-%s.txt", b.httpSeq, sanitize(req.URL.Host)), dump)
+	b.mu.Unlock()
+
+	return resp, err
+}
+
+// WriteZip writes the bundle and its manifest to a timestamped zip file in
+// dir and returns the file's path.
+func (b *Bundle) WriteZip(dir string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create evidence directory: %v", err)
+	}
+	path := filepath.Join(dir, "d3-evidence-"+b.createdAt.Format("20060102-150405")+".zip")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create evidence bundle: %v", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	manifest := Manifest{CreatedAt: b.createdAt, Tool: "d3-domain-tool"}
+	for _, fl := range b.files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: fl.name, Method: zip.Deflate, Modified: fl.capturedAt})
+		if err != nil {
+			return "", fmt.Errorf("failed to add %s: %v", fl.name, err)
+		}
+		if _, err := w.Write(fl.data); err != nil {
+			return "", fmt.Errorf("failed to write %s: %v", fl.name, err)
+		}
+		sum := sha256.Sum256(fl.data)
+		manifest.Artifacts = append(manifest.Artifacts, Entry{
+			Name:       fl.name,
+			Size:       len(fl.data),
+			SHA256:     hex.EncodeToString(sum[:]),
+			CapturedAt: fl.capturedAt,
+		})
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: ManifestName, Method: zip.Deflate, Modified: time.Now().UTC()})
+	if err != nil {
+		return "", fmt.Errorf("failed to add manifest: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return "", fmt.Errorf("failed to write manifest: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("failed to finish evidence bundle: %v", err)
+	}
+	return path, nil
+}
+
+// sanitize keeps names safe for use as zip path components.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+}
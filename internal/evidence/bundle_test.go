@@ -0,0 +1,127 @@
+package evidence
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBundle_WriteZip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"owner":"0xabc"}`)
+	}))
+	defer srv.Close()
+
+	b := New()
+	ctx := WithScope(context.Background(), "example.com")
+	b.Add(ctx, "whois.txt", []byte("Registrar: Example Registrar\n"))
+
+	client := &http.Client{Transport: b.Transport(http.DefaultTransport)}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/lookup", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != `{"owner":"0xabc"}` {
+		t.Errorf("recording must not consume the response body, got %q", body)
+	}
+
+	path, err := b.WriteZip(t.TempDir())
+	if err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("failed to open bundle: %v", err)
+	}
+	defer zr.Close()
+
+	contents := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		contents[f.Name] = string(data)
+	}
+
+	if contents["example.com/whois.txt"] == "" {
+		t.Error("expected whois.txt under the domain scope")
+	}
+	var httpDump string
+	for name, data := range contents {
+		if strings.HasPrefix(name, "example.com/http/001-") {
+			httpDump = data
+		}
+	}
+	if !strings.Contains(httpDump, "GET /lookup") || !strings.Contains(httpDump, `{"owner":"0xabc"}`) {
+		t.Errorf("expected request and response in HTTP dump, got %q", httpDump)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal([]byte(contents[ManifestName]), &manifest); err != nil {
+		t.Fatalf("bad manifest: %v", err)
+	}
+	if len(manifest.Artifacts) != 2 || manifest.Artifacts[0].SHA256 == "" {
+		t.Errorf("unexpected manifest: %+v", manifest)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	b := New()
+	ctx := WithScope(context.Background(), "example.com")
+	b.Add(ctx, "whois.txt", []byte("Registrar: Example Registrar\n"))
+	b.Add(ctx, "report.json", []byte(`{"domain":"example.com"}`))
+	path, err := b.WriteZip(t.TempDir())
+	if err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	archive, err := Open(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	if domains := archive.Domains(); len(domains) != 1 || domains[0] != "example.com" {
+		t.Errorf("unexpected domains: %v", domains)
+	}
+	if data, ok := archive.File("example.com/whois.txt"); !ok || !strings.Contains(string(data), "Example Registrar") {
+		t.Errorf("expected whois.txt, got %q", data)
+	}
+	if _, ok := archive.File(ManifestName); ok {
+		t.Error("the manifest is not an artifact")
+	}
+
+	// A file slipped into the bundle after it was written is rejected.
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := filepath.Join(t.TempDir(), "tampered.zip")
+	out, err := os.Create(tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(out)
+	for _, f := range zr.File {
+		if err := zw.Copy(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w, _ := zw.Create("example.com/rdap.json")
+	w.Write([]byte(`{"registrar":"Forged"}`))
+	zw.Close()
+	out.Close()
+	zr.Close()
+	if _, err := Open(tampered); err == nil || !strings.Contains(err.Error(), "example.com/rdap.json") {
+		t.Errorf("expected the unlisted file to be rejected, got %v", err)
+	}
+}
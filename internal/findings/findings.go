@@ -0,0 +1,166 @@
+// Package findings defines the issues an analysis reports, one per problem
+// found, and the scheme that scores how severe each is.
+//
+// Severities follow what the issue exposes the domain's owner to:
+//
+//   - critical: the domain is lost or actively abused now (seized, listed
+//     as a botnet C2, a dangling record open to subdomain takeover)
+//   - high: it is exposed to abuse or about to stop working (registration
+//     or certificate expired, or expiring within 7 days; listed in a threat
+//     feed; a sensitive port exposed)
+//   - medium: a weakness an attacker can use or an outage in the making
+//     (dangling record, SPF/DMARC issue, UDRP dispute, registration or
+//     certificate expiring within 30 days)
+//   - low: hardening and hygiene (registration expiring within 60 days,
+//     missing security headers, HSTS preload or IPv6 readiness issues)
+//   - info: no issue with the domain itself (a check failed, a data
+//     quality warning)
+package findings
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity ranks findings from Info to Critical.
+type Severity int
+
+// Severities, in increasing order.
+const (
+	Info Severity = iota
+	Low
+	Medium
+	High
+	Critical
+)
+
+var severityNames = []string{"info", "low", "medium", "high", "critical"}
+
+func (s Severity) String() string {
+	if s < Info || s > Critical {
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+// ParseSeverity reads a severity name: info, low, medium, high or critical.
+func ParseSeverity(name string) (Severity, error) {
+	for i, n := range severityNames {
+		if strings.EqualFold(strings.TrimSpace(name), n) {
+			return Severity(i), nil
+		}
+	}
+	return Info, fmt.Errorf("unknown severity %q (expected %s)", name, strings.Join(severityNames, ", "))
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	parsed, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// Kinds of finding.
+const (
+	DomainSeized         = "domain-seized"
+	DomainMaybeSeized    = "domain-maybe-seized"
+	ThreatC2             = "threat-c2"
+	ThreatListed         = "threat-listed"
+	RegistrationExpired  = "registration-expired"
+	RegistrationExpiring = "registration-expiring"
+	CertificateExpired   = "certificate-expired"
+	CertificateExpiring  = "certificate-expiring"
+	UDRPDispute          = "udrp-dispute"
+	DanglingTakeover     = "dangling-takeover"
+	DanglingRecord       = "dangling-record"
+	PortExposed          = "port-exposed"
+	WebSecurity          = "web-security"
+	HSTSPreload          = "hsts-preload"
+	SPFIssue             = "spf-issue"
+	DMARCIssue           = "dmarc-issue"
+	IPv6Issue            = "ipv6-issue"
+	CheckFailed          = "check-failed"
+	DataWarning          = "data-warning"
+)
+
+// severities scores each kind of finding. Expiring registrations and
+// certificates are scored by Expiring instead.
+var severities = map[string]Severity{
+	DomainSeized:        Critical,
+	ThreatC2:            Critical,
+	DanglingTakeover:    Critical,
+	ThreatListed:        High,
+	RegistrationExpired: High,
+	CertificateExpired:  High,
+	PortExposed:         High,
+	DomainMaybeSeized:   Medium,
+	DanglingRecord:      Medium,
+	SPFIssue:            Medium,
+	DMARCIssue:          Medium,
+	UDRPDispute:         Medium,
+	WebSecurity:         Low,
+	HSTSPreload:         Low,
+	IPv6Issue:           Low,
+	CheckFailed:         Info,
+	DataWarning:         Info,
+}
+
+// Finding is one issue of a domain.
+type Finding struct {
+	// Module is the section that found the issue, as named in
+	// analyzer.Modules.
+	Module string `json:"module"`
+	// ID identifies the kind of issue, e.g. dangling-takeover; SIEM rules
+	// and baselines key on it.
+	ID       string   `json:"id"`
+	Severity Severity `json:"severity"`
+	// Name is a short description of the kind of issue.
+	Name string `json:"name"`
+	// Detail describes this occurrence.
+	Detail string `json:"detail,omitempty"`
+}
+
+// New returns a finding of kind id scored by the scheme.
+func New(module, id, name, detail string) Finding {
+	return Finding{Module: module, ID: id, Severity: severities[id], Name: name, Detail: detail}
+}
+
+// Expiring scores something that expires in days: high within 7 days,
+// medium within 30 and low beyond.
+func Expiring(days int) Severity {
+	switch {
+	case days <= 7:
+		return High
+	case days <= 30:
+		return Medium
+	}
+	return Low
+}
+
+// Max returns the highest severity of list, and false when it is empty.
+func Max(list []Finding) (Severity, bool) {
+	max := Info
+	for _, f := range list {
+		if f.Severity > max {
+			max = f.Severity
+		}
+	}
+	return max, len(list) > 0
+}
+
+// AtLeast returns the findings of list that are at least min.
+func AtLeast(list []Finding, min Severity) []Finding {
+	var out []Finding
+	for _, f := range list {
+		if f.Severity >= min {
+			out = append(out, f)
+		}
+	}
+	return out
+}
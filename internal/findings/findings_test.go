@@ -0,0 +1,57 @@
+package findings
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseSeverity(t *testing.T) {
+	for name, want := range map[string]Severity{"info": Info, "LOW": Low, " medium": Medium, "high": High, "critical": Critical} {
+		if got, err := ParseSeverity(name); err != nil || got != want {
+			t.Errorf("ParseSeverity(%q) = %v, %v", name, got, err)
+		}
+	}
+	if _, err := ParseSeverity("severe"); err == nil {
+		t.Error("accepted an unknown severity")
+	}
+}
+
+func TestScoring(t *testing.T) {
+	if f := New("seizure", DomainSeized, "Domain seized", ""); f.Severity != Critical {
+		t.Errorf("seized = %v", f.Severity)
+	}
+	if f := New("dns", CheckFailed, "Check failed", "timeout"); f.Severity != Info {
+		t.Errorf("check failed = %v", f.Severity)
+	}
+	for days, want := range map[int]Severity{3: High, 7: High, 8: Medium, 30: Medium, 45: Low} {
+		if got := Expiring(days); got != want {
+			t.Errorf("Expiring(%d) = %v, want %v", days, got, want)
+		}
+	}
+
+	list := []Finding{New("ipv6", IPv6Issue, "", ""), New("port_scan", PortExposed, "", ""), New("email_security", SPFIssue, "", "")}
+	if max, ok := Max(list); !ok || max != High {
+		t.Errorf("max = %v, %v", max, ok)
+	}
+	if _, ok := Max(nil); ok {
+		t.Error("max of no findings")
+	}
+	if got := AtLeast(list, Medium); len(got) != 2 || got[0].ID != PortExposed {
+		t.Errorf("at least medium = %+v", got)
+	}
+}
+
+func TestSeverityJSON(t *testing.T) {
+	data, err := json.Marshal(New("port_scan", PortExposed, "Sensitive port exposed", "1.2.3.4:22 (ssh)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"module":"port_scan","id":"port-exposed","severity":"high","name":"Sensitive port exposed","detail":"1.2.3.4:22 (ssh)"}`
+	if string(data) != want {
+		t.Errorf("json = %s", data)
+	}
+	var f Finding
+	if err := json.Unmarshal(data, &f); err != nil || f.Severity != High {
+		t.Errorf("unmarshal = %+v, %v", f, err)
+	}
+}
@@ -0,0 +1,186 @@
+package geodns
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/checker"
+)
+
+// DefaultECSResolver honours EDNS Client Subnet, so one resolver can
+// simulate queries from many networks.
+const DefaultECSResolver = "8.8.8.8:53"
+
+// Vantage is a point of view to query from: either a client subnet sent to
+// an ECS-aware resolver, or a remote resolver located in that region.
+type Vantage struct {
+	Name     string `json:"name"`
+	Subnet   string `json:"subnet,omitempty"`
+	Resolver string `json:"resolver"`
+}
+
+// DefaultVantages are subnets of large access networks on each continent.
+var DefaultVantages = []Vantage{
+	{Name: "us", Subnet: "73.0.0.0/24"},
+	{Name: "br", Subnet: "177.0.0.0/24"},
+	{Name: "de", Subnet: "80.128.0.0/24"},
+	{Name: "in", Subnet: "49.32.0.0/24"},
+	{Name: "jp", Subnet: "153.128.0.0/24"},
+	{Name: "au", Subnet: "101.160.0.0/24"},
+}
+
+// Detector compares a domain's answers across vantage points.
+type Detector struct {
+	vantages []Vantage
+	query    func(v Vantage, name, rrType string) (*checker.Response, error)
+}
+
+type Result struct {
+	Vantages        []VantageAnswer `json:"vantages"`
+	GeoDNS          bool            `json:"geo_dns"`
+	DistinctAnswers int             `json:"distinct_answers"`
+	Tailored        bool            `json:"ecs_tailored"`
+	Notes           []string        `json:"notes,omitempty"`
+	CheckedAt       time.Time       `json:"checked_at"`
+	Error           string          `json:"error,omitempty"`
+}
+
+// VantageAnswer is what one vantage point resolved.
+type VantageAnswer struct {
+	Vantage
+	Addresses   []string `json:"addresses,omitempty"`
+	CNAMEs      []string `json:"cnames,omitempty"`
+	SubnetScope int      `json:"subnet_scope"`
+	Error       string   `json:"error,omitempty"`
+}
+
+func NewDetector() *Detector {
+	return &Detector{
+		vantages: DefaultVantages,
+		query:    queryVantage,
+	}
+}
+
+// SetVantages replaces the default vantage points.
+func (d *Detector) SetVantages(vantages []Vantage) {
+	if len(vantages) > 0 {
+		d.vantages = vantages
+	}
+}
+
+// Vantages returns the configured vantage points with resolvers filled in.
+func (d *Detector) Vantages() []Vantage {
+	out := make([]Vantage, len(d.vantages))
+	for i, v := range d.vantages {
+		if v.Resolver == "" {
+			v.Resolver = DefaultECSResolver
+		}
+		out[i] = v
+	}
+	return out
+}
+
+// ParseVantages parses "name=subnet" and "name=@resolver[:port]" items
+// separated by commas.
+func ParseVantages(spec string) ([]Vantage, error) {
+	var vantages []Vantage
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("invalid vantage %q: expected name=subnet or name=@resolver", item)
+		}
+		if resolver, ok := strings.CutPrefix(value, "@"); ok {
+			vantages = append(vantages, Vantage{Name: name, Resolver: resolver})
+			continue
+		}
+		if _, err := netip.ParsePrefix(value); err != nil {
+			return nil, fmt.Errorf("invalid subnet in vantage %q: %v", item, err)
+		}
+		vantages = append(vantages, Vantage{Name: name, Subnet: value})
+	}
+	return vantages, nil
+}
+
+func (d *Detector) Detect(domain string) (*Result, error) {
+	result := &Result{
+		CheckedAt: time.Now(),
+	}
+
+	distinct := make(map[string]bool)
+	for _, v := range d.Vantages() {
+		answer := VantageAnswer{Vantage: v, SubnetScope: -1}
+		for _, rrType := range []string{"A", "AAAA"} {
+			resp, err := d.query(v, domain, rrType)
+			if err != nil {
+				answer.Error = err.Error()
+				break
+			}
+			if resp.SubnetScope > answer.SubnetScope {
+				answer.SubnetScope = resp.SubnetScope
+			}
+			for _, rec := range resp.Answers {
+				switch rec.Type {
+				case "A", "AAAA":
+					answer.Addresses = append(answer.Addresses, rec.Value)
+				case "CNAME":
+					answer.CNAMEs = appendUnique(answer.CNAMEs, rec.Value)
+				}
+			}
+		}
+		sort.Strings(answer.Addresses)
+
+		if answer.Error == "" {
+			distinct[strings.Join(answer.Addresses, ",")] = true
+			if answer.SubnetScope > 0 {
+				result.Tailored = true
+			}
+		}
+		result.Vantages = append(result.Vantages, answer)
+	}
+
+	if len(distinct) == 0 {
+		result.Error = "no vantage point could resolve the domain"
+		return result, nil
+	}
+
+	result.DistinctAnswers = len(distinct)
+	result.GeoDNS = len(distinct) > 1
+	switch {
+	case result.GeoDNS:
+		result.Notes = append(result.Notes, fmt.Sprintf("%d different answer sets across %d vantage points; traffic is routed by location (GeoDNS or CDN steering)", len(distinct), len(result.Vantages)))
+	case result.Tailored:
+		result.Notes = append(result.Notes, "Authoritative servers use EDNS Client Subnet but returned the same answers everywhere")
+	default:
+		result.Notes = append(result.Notes, "Same answers from every vantage point; any geographic routing happens via anycast, not DNS")
+	}
+
+	return result, nil
+}
+
+func queryVantage(v Vantage, name, rrType string) (*checker.Response, error) {
+	r := checker.NewResolverFor(v.Resolver)
+	if v.Subnet != "" {
+		prefix, err := netip.ParsePrefix(v.Subnet)
+		if err != nil {
+			return nil, err
+		}
+		r = r.WithClientSubnet(prefix)
+	}
+	return r.Query(name, rrType)
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, existing := range list {
+		if existing == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
@@ -0,0 +1,49 @@
+package geodns
+
+import (
+	"testing"
+
+	"d3-domain-tool/internal/checker"
+)
+
+func TestDetect(t *testing.T) {
+	answers := map[string]string{
+		"us": "192.0.2.10",
+		"de": "198.51.100.10",
+		"jp": "192.0.2.10",
+	}
+
+	d := NewDetector()
+	d.SetVantages([]Vantage{{Name: "us", Subnet: "73.0.0.0/24"}, {Name: "de", Subnet: "80.128.0.0/24"}, {Name: "jp", Resolver: "192.0.2.53"}})
+	d.query = func(v Vantage, name, rrType string) (*checker.Response, error) {
+		resp := &checker.Response{Rcode: "NOERROR", SubnetScope: 24}
+		if rrType == "A" {
+			resp.Answers = []checker.Record{{Name: name, Type: "A", Value: answers[v.Name]}}
+		}
+		return resp, nil
+	}
+
+	result, err := d.Detect("example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.GeoDNS || result.DistinctAnswers != 2 || !result.Tailored {
+		t.Errorf("expected geo-dependent answers, got %+v", result)
+	}
+	if result.Vantages[0].Resolver != DefaultECSResolver || result.Vantages[2].Resolver != "192.0.2.53" {
+		t.Errorf("unexpected resolvers: %+v", result.Vantages)
+	}
+}
+
+func TestParseVantages(t *testing.T) {
+	vantages, err := ParseVantages("eu=80.128.0.0/24, asia=@203.0.113.53:53")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(vantages) != 2 || vantages[0].Subnet != "80.128.0.0/24" || vantages[1].Resolver != "203.0.113.53:53" {
+		t.Errorf("unexpected vantages: %+v", vantages)
+	}
+	if _, err := ParseVantages("eu=not-a-subnet"); err == nil {
+		t.Error("expected error for invalid subnet")
+	}
+}
@@ -0,0 +1,322 @@
+// Package graphql executes a subset of GraphQL (https://spec.graphql.org)
+// queries: a single query operation with variables, aliases, nested
+// selections and the @include and @skip directives. Resolvers return plain Go values; nested selections pick
+// fields out of their JSON form by JSON key, so any struct the tool
+// already outputs can be queried without a hand-written schema.
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Query is a parsed query operation.
+type Query struct {
+	Name   string
+	Fields []*Field
+	vars   []variableDef
+}
+
+// Field is a selected field with its arguments and sub-selections.
+type Field struct {
+	Alias  string
+	Name   string
+	Fields []*Field
+	args   map[string]interface{}
+	// directives decide, once variables are known, whether the field is
+	// selected.
+	directives []directive
+}
+
+// directive is an @include or @skip on a field; cond is its if argument.
+type directive struct {
+	name string
+	cond interface{}
+}
+
+// Key is the name the field's value is returned under.
+func (f *Field) Key() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// Resolver answers a root field given its arguments, with variables
+// already substituted.
+type Resolver func(args map[string]interface{}) (interface{}, error)
+
+// Schema maps root field names to their resolvers.
+type Schema map[string]Resolver
+
+// Request is the body of a GraphQL HTTP POST.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Error is a GraphQL error entry.
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// Response is a GraphQL response. Data is nil when the query could not be
+// executed at all.
+type Response struct {
+	Data   *Object `json:"data,omitempty"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// Execute parses and runs req against schema. Root fields are resolved in
+// order; a failing resolver yields null for its field and an error entry,
+// the other fields are still returned.
+func Execute(schema Schema, req Request) *Response {
+	q, err := Parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	if req.OperationName != "" && req.OperationName != q.Name {
+		return &Response{Errors: []Error{{Message: fmt.Sprintf("unknown operation %q", req.OperationName)}}}
+	}
+	vars, err := q.variables(req.Variables)
+	if err != nil {
+		return &Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	fields, err := prune(q.Fields, vars)
+	if err != nil {
+		return &Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	for _, f := range fields {
+		if _, ok := schema[f.Name]; !ok && f.Name != "__typename" {
+			return &Response{Errors: []Error{{Message: fmt.Sprintf("unknown field %q on Query; available: %s", f.Name, schema.names())}}}
+		}
+	}
+
+	resp := &Response{Data: &Object{}}
+	for _, f := range fields {
+		if f.Name == "__typename" {
+			resp.Data.Set(f.Key(), "Query")
+			continue
+		}
+		args, err := substitute(f.args, vars)
+		if err != nil {
+			resp.Data.Set(f.Key(), nil)
+			resp.Errors = append(resp.Errors, Error{Message: err.Error(), Path: []interface{}{f.Key()}})
+			continue
+		}
+		value, err := schema[f.Name](args.(map[string]interface{}))
+		if err != nil {
+			resp.Data.Set(f.Key(), nil)
+			resp.Errors = append(resp.Errors, Error{Message: err.Error(), Path: []interface{}{f.Key()}})
+			continue
+		}
+		projected, err := Select(value, f.Fields)
+		if err != nil {
+			resp.Data.Set(f.Key(), nil)
+			resp.Errors = append(resp.Errors, Error{Message: err.Error(), Path: []interface{}{f.Key()}})
+			continue
+		}
+		resp.Data.Set(f.Key(), projected)
+	}
+	return resp
+}
+
+func (s Schema) names() string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// variables combines the supplied values with the operation's defaults.
+func (q *Query) variables(given map[string]interface{}) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+	for _, def := range q.vars {
+		value, ok := given[def.name]
+		if !ok && def.hasValue {
+			value, ok = def.fallback, true
+		}
+		if def.nonNull && (!ok || value == nil) {
+			return nil, fmt.Errorf("variable $%s is required", def.name)
+		}
+		vars[def.name] = value
+	}
+	return vars, nil
+}
+
+// prune returns fields without those their @include or @skip directives
+// leave out. A field whose whole selection is left out selects nothing
+// rather than everything.
+func prune(fields []*Field, vars map[string]interface{}) ([]*Field, error) {
+	kept := []*Field{}
+	for _, f := range fields {
+		selected := true
+		for _, d := range f.directives {
+			cond, err := substitute(d.cond, vars)
+			if err != nil {
+				return nil, fmt.Errorf("@%s: %v", d.name, err)
+			}
+			b, ok := cond.(bool)
+			if !ok {
+				return nil, fmt.Errorf("@%s on %s: if must be a boolean", d.name, f.Key())
+			}
+			if b == (d.name == "skip") {
+				selected = false
+			}
+		}
+		if !selected {
+			continue
+		}
+		if f.Fields != nil {
+			sub, err := prune(f.Fields, vars)
+			if err != nil {
+				return nil, err
+			}
+			pruned := *f
+			pruned.Fields = sub
+			f = &pruned
+		}
+		kept = append(kept, f)
+	}
+	return kept, nil
+}
+
+// substitute replaces variable references in an argument value.
+func substitute(value interface{}, vars map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case variable:
+		val, ok := vars[string(v)]
+		if !ok {
+			return nil, fmt.Errorf("variable $%s is not defined", v)
+		}
+		return val, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			s, err := substitute(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = s
+		}
+		return out, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			s, err := substitute(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = s
+		}
+		return out, nil
+	}
+	return value, nil
+}
+
+// Select converts v to its JSON form and keeps only the selected fields;
+// nil fields keep the whole value. Lists are selected element by element.
+// Fields absent from the value are null; selecting into a scalar is an
+// error.
+func Select(v interface{}, fields []*Field) (interface{}, error) {
+	if fields == nil {
+		return v, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return project(doc, fields)
+}
+
+func project(v interface{}, fields []*Field) (interface{}, error) {
+	// Values without a selection, including objects, are returned whole,
+	// which keeps free-form maps such as records queryable.
+	if fields == nil {
+		return v, nil
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			p, err := project(item, fields)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = p
+		}
+		return out, nil
+	case map[string]interface{}:
+		obj := &Object{}
+		for _, f := range fields {
+			if f.Name == "__typename" {
+				obj.Set(f.Key(), "Object")
+				continue
+			}
+			p, err := project(v[f.Name], f.Fields)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", f.Name, err)
+			}
+			obj.Set(f.Key(), p)
+		}
+		return obj, nil
+	}
+	return nil, fmt.Errorf("cannot select fields of a %T value", v)
+}
+
+// Object is a JSON object that keeps its keys in selection order, as
+// GraphQL responses do.
+type Object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// Set adds or replaces key.
+func (o *Object) Set(key string, value interface{}) {
+	if o.values == nil {
+		o.values = map[string]interface{}{}
+	}
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// Get returns the value of key.
+func (o *Object) Get(key string) interface{} {
+	return o.values[key]
+}
+
+// MarshalJSON writes the keys in the order they were set.
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		b.Write(k)
+		b.WriteByte(':')
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
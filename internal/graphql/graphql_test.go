@@ -0,0 +1,148 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type whois struct {
+	Registrar string  `json:"registrar"`
+	Expiry    *string `json:"expiry_date,omitempty"`
+}
+
+type result struct {
+	Domain string            `json:"domain"`
+	Whois  *whois            `json:"whois_data"`
+	Tags   []string          `json:"tags"`
+	Extra  map[string]string `json:"extra"`
+}
+
+func testSchema() Schema {
+	expiry := "2030-01-01T00:00:00Z"
+	lookup := func(domain string) (*result, error) {
+		if domain == "bad.com" {
+			return nil, fmt.Errorf("lookup failed")
+		}
+		return &result{
+			Domain: domain,
+			Whois:  &whois{Registrar: "Example Registrar", Expiry: &expiry},
+			Tags:   []string{"short"},
+			Extra:  map[string]string{"k": "v"},
+		}, nil
+	}
+	return Schema{
+		"analyze": func(args map[string]interface{}) (interface{}, error) {
+			domain, _ := args["domain"].(string)
+			return lookup(domain)
+		},
+		"portfolio": func(args map[string]interface{}) (interface{}, error) {
+			var results []*result
+			for _, d := range args["domains"].([]interface{}) {
+				r, err := lookup(d.(string))
+				if err != nil {
+					return nil, err
+				}
+				results = append(results, r)
+			}
+			return results, nil
+		},
+	}
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		want string
+	}{
+		{
+			"selection",
+			Request{Query: `{ analyze(domain: "a.com") { domain whois_data { expiry_date } } }`},
+			`{"data":{"analyze":{"domain":"a.com","whois_data":{"expiry_date":"2030-01-01T00:00:00Z"}}}}`,
+		},
+		{
+			"aliases and lists",
+			Request{Query: `{ p: portfolio(domains: ["a.com", "b.eth"]) { name: domain tags } }`},
+			`{"data":{"p":[{"name":"a.com","tags":["short"]},{"name":"b.eth","tags":["short"]}]}}`,
+		},
+		{
+			"variables and comments",
+			Request{
+				Query:     "query Names($ds: [String!]!, $one: String = \"c.com\") {\n  # two root fields\n  portfolio(domains: $ds) { domain }\n  analyze(domain: $one) { domain }\n}",
+				Variables: map[string]interface{}{"ds": []interface{}{"a.com"}},
+			},
+			`{"data":{"portfolio":[{"domain":"a.com"}],"analyze":{"domain":"c.com"}}}`,
+		},
+		{
+			"missing field and whole object",
+			Request{Query: `{ analyze(domain: "a.com") { nope extra } }`},
+			`{"data":{"analyze":{"nope":null,"extra":{"k":"v"}}}}`,
+		},
+		{
+			"resolver error",
+			Request{Query: `{ ok: analyze(domain: "a.com") { domain } bad: analyze(domain: "bad.com") { domain } }`},
+			`{"data":{"ok":{"domain":"a.com"},"bad":null},"errors":[{"message":"lookup failed","path":["bad"]}]}`,
+		},
+		{
+			"include and skip",
+			Request{
+				Query:     `query($whois: Boolean!) { analyze(domain: "a.com") { domain @skip(if: true) whois_data @include(if: $whois) { registrar } tags @include(if: false) } }`,
+				Variables: map[string]interface{}{"whois": true},
+			},
+			`{"data":{"analyze":{"whois_data":{"registrar":"Example Registrar"}}}}`,
+		},
+		{
+			"whole selection skipped",
+			Request{
+				Query: `query($whois: Boolean = false) { analyze(domain: "a.com") { whois_data { registrar @include(if: $whois) } } }`,
+			},
+			`{"data":{"analyze":{"whois_data":{}}}}`,
+		},
+		{
+			"scalar selection",
+			Request{Query: `{ analyze(domain: "a.com") { domain { x } } }`},
+			`{"data":{"analyze":null},"errors":[{"message":"domain: cannot select fields of a string value","path":["analyze"]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		got, err := json.Marshal(Execute(testSchema(), tt.req))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteErrors(t *testing.T) {
+	tests := []struct {
+		req  Request
+		want string
+	}{
+		{Request{Query: `{ analyze(domain: "a.com") { domain }`}, `expected "}"`},
+		{Request{Query: `mutation { analyze }`}, "mutation operations are not supported"},
+		{Request{Query: `{ ...Frag }`}, "fragments are not supported"},
+		{Request{Query: `{ whois }`}, `unknown field "whois" on Query; available: analyze, portfolio`},
+		{Request{Query: `query($d: String!) { analyze(domain: $d) { domain } }`}, "variable $d is required"},
+		{Request{Query: `query A { analyze(domain: "a.com") { domain } }`, OperationName: "B"}, `unknown operation "B"`},
+		{Request{Query: "{ analyze(domain: \"a.com) }"}, "unterminated string"},
+		{Request{Query: `{ analyze(domain: "a.com") @defer { domain } }`}, "directive @defer is not supported"},
+		{Request{Query: `{ analyze(domain: "a.com") @include(if: "yes") { domain } }`}, "if must be a boolean"},
+	}
+	for _, tt := range tests {
+		resp := Execute(testSchema(), tt.req)
+		if resp.Data != nil || len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tt.want) {
+			t.Errorf("%q: expected error containing %q, got %+v", tt.req.Query, tt.want, resp)
+		}
+	}
+}
+
+func TestSyntaxErrorPosition(t *testing.T) {
+	_, err := Parse("{\n  analyze(domain: ) }")
+	if err == nil || err.Error() != "syntax error at line 2, column 19: expected value" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
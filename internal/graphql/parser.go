@@ -0,0 +1,420 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenType int
+
+const (
+	tEOF tokenType = iota
+	tName
+	tVariable
+	tString
+	tNumber
+	tPunct
+)
+
+type token struct {
+	typ  tokenType
+	text string
+	pos  int
+}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "..."):
+			return nil, syntaxError(src, i, "fragments are not supported")
+		case strings.IndexByte("{}()[]:!=$@", c) >= 0:
+			if c == '$' {
+				start := i
+				i++
+				for i < len(src) && isNameChar(src[i]) {
+					i++
+				}
+				if i == start+1 {
+					return nil, syntaxError(src, start, "expected variable name after $")
+				}
+				tokens = append(tokens, token{typ: tVariable, text: src[start+1 : i], pos: start})
+				continue
+			}
+			tokens = append(tokens, token{typ: tPunct, text: string(c), pos: i})
+			i++
+		case isNameStart(c):
+			start := i
+			for i < len(src) && isNameChar(src[i]) {
+				i++
+			}
+			tokens = append(tokens, token{typ: tName, text: src[start:i], pos: start})
+		case c == '-' || (c >= '0' && c <= '9'):
+			start := i
+			i++
+			for i < len(src) && strings.IndexByte("0123456789.eE+-", src[i]) >= 0 {
+				i++
+			}
+			tokens = append(tokens, token{typ: tNumber, text: src[start:i], pos: start})
+		case c == '"':
+			start := i
+			for i++; i < len(src) && src[i] != '"'; i++ {
+				if src[i] == '\\' {
+					i++
+				}
+				if i < len(src) && src[i] == '\n' {
+					break
+				}
+			}
+			if i >= len(src) || src[i] != '"' {
+				return nil, syntaxError(src, start, "unterminated string")
+			}
+			i++
+			s, err := strconv.Unquote(src[start:i])
+			if err != nil {
+				return nil, syntaxError(src, start, "invalid string")
+			}
+			tokens = append(tokens, token{typ: tString, text: s, pos: start})
+		default:
+			return nil, syntaxError(src, i, fmt.Sprintf("unexpected character %q", c))
+		}
+	}
+	return append(tokens, token{typ: tEOF, pos: len(src)}), nil
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
+
+// SyntaxError reports an invalid document and where it went wrong.
+type SyntaxError struct {
+	Offset int
+	Msg    string
+	line   int
+	column int
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at line %d, column %d: %s", e.line, e.column, e.Msg)
+}
+
+func syntaxError(src string, offset int, msg string) error {
+	line := 1 + strings.Count(src[:offset], "\n")
+	column := offset - strings.LastIndexByte(src[:offset], '\n')
+	return &SyntaxError{Offset: offset, Msg: msg, line: line, column: column}
+}
+
+// variable is a $name reference in an argument value.
+type variable string
+
+// variableDef declares an operation variable and its default.
+type variableDef struct {
+	name     string
+	nonNull  bool
+	fallback interface{}
+	hasValue bool
+}
+
+type parser struct {
+	src    string
+	tokens []token
+	pos    int
+}
+
+// Parse reads a document holding a single query operation, written either
+// as a bare selection set or as "query Name($var: Type) { ... }".
+// Fragments, directives other than @include and @skip, mutations and
+// subscriptions are not supported.
+func Parse(src string) (*Query, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: src, tokens: tokens}
+	q := &Query{}
+
+	if t := p.current(); t.typ == tName {
+		if t.text != "query" {
+			return nil, p.errorf("%s operations are not supported", t.text)
+		}
+		p.advance()
+		if p.current().typ == tName {
+			q.Name = p.advance().text
+		}
+		if p.is("(") {
+			if q.vars, err = p.parseVariableDefs(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if q.Fields, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	if p.current().typ != tEOF {
+		return nil, p.errorf("only one operation per document is supported")
+	}
+	return q, nil
+}
+
+func (p *parser) current() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.pos]
+	if t.typ != tEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) is(punct string) bool {
+	t := p.current()
+	return t.typ == tPunct && t.text == punct
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.is(punct) {
+		return p.errorf("expected %q", punct)
+	}
+	p.advance()
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return syntaxError(p.src, p.current().pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) parseVariableDefs() ([]variableDef, error) {
+	p.advance()
+	var defs []variableDef
+	for !p.is(")") {
+		t := p.current()
+		if t.typ != tVariable {
+			return nil, p.errorf("expected variable definition")
+		}
+		p.advance()
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		def := variableDef{name: t.text}
+		var err error
+		if def.nonNull, err = p.parseType(); err != nil {
+			return nil, err
+		}
+		if p.is("=") {
+			p.advance()
+			if def.fallback, err = p.parseValue(true); err != nil {
+				return nil, err
+			}
+			def.hasValue = true
+		}
+		defs = append(defs, def)
+	}
+	p.advance()
+	return defs, nil
+}
+
+// parseType skips a type reference such as [String!]! and reports whether
+// the outer type is non-null. Values are not checked against types.
+func (p *parser) parseType() (bool, error) {
+	if p.is("[") {
+		p.advance()
+		if _, err := p.parseType(); err != nil {
+			return false, err
+		}
+		if err := p.expect("]"); err != nil {
+			return false, err
+		}
+	} else if p.current().typ == tName {
+		p.advance()
+	} else {
+		return false, p.errorf("expected type")
+	}
+	if p.is("!") {
+		p.advance()
+		return true, nil
+	}
+	return false, nil
+}
+
+func (p *parser) parseSelectionSet() ([]*Field, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var fields []*Field
+	for !p.is("}") {
+		if p.current().typ == tEOF {
+			return nil, p.errorf("expected \"}\"")
+		}
+		f, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	p.advance()
+	if len(fields) == 0 {
+		return nil, p.errorf("empty selection set")
+	}
+	return fields, nil
+}
+
+func (p *parser) parseField() (*Field, error) {
+	if p.current().typ != tName {
+		return nil, p.errorf("expected field name")
+	}
+	f := &Field{Name: p.advance().text}
+	if p.is(":") {
+		p.advance()
+		if p.current().typ != tName {
+			return nil, p.errorf("expected field name after alias")
+		}
+		f.Alias, f.Name = f.Name, p.advance().text
+	}
+	if p.is("(") {
+		p.advance()
+		f.args = map[string]interface{}{}
+		for !p.is(")") {
+			if p.current().typ != tName {
+				return nil, p.errorf("expected argument name")
+			}
+			name := p.advance().text
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			value, err := p.parseValue(false)
+			if err != nil {
+				return nil, err
+			}
+			f.args[name] = value
+		}
+		p.advance()
+	}
+	for p.is("@") {
+		d, err := p.parseDirective()
+		if err != nil {
+			return nil, err
+		}
+		f.directives = append(f.directives, d)
+	}
+	if p.is("{") {
+		var err error
+		if f.Fields, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// parseDirective reads @include(if: ...) or @skip(if: ...).
+func (p *parser) parseDirective() (directive, error) {
+	p.advance()
+	t := p.current()
+	if t.typ != tName {
+		return directive{}, p.errorf("expected directive name")
+	}
+	if t.text != "include" && t.text != "skip" {
+		return directive{}, p.errorf("directive @%s is not supported; use @include or @skip", t.text)
+	}
+	p.advance()
+	d := directive{name: t.text}
+	if err := p.expect("("); err != nil {
+		return directive{}, err
+	}
+	if p.current().typ != tName || p.current().text != "if" {
+		return directive{}, p.errorf("@%s needs an if argument", d.name)
+	}
+	p.advance()
+	if err := p.expect(":"); err != nil {
+		return directive{}, err
+	}
+	var err error
+	if d.cond, err = p.parseValue(false); err != nil {
+		return directive{}, err
+	}
+	if err := p.expect(")"); err != nil {
+		return directive{}, err
+	}
+	return d, nil
+}
+
+func (p *parser) parseValue(constant bool) (interface{}, error) {
+	t := p.current()
+	switch t.typ {
+	case tVariable:
+		if constant {
+			return nil, p.errorf("variables are not allowed in default values")
+		}
+		p.advance()
+		return variable(t.text), nil
+	case tString:
+		p.advance()
+		return t.text, nil
+	case tNumber:
+		p.advance()
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, syntaxError(p.src, t.pos, fmt.Sprintf("invalid number %s", t.text))
+		}
+		return n, nil
+	case tName:
+		p.advance()
+		switch t.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		// Enum values are passed on as strings.
+		return t.text, nil
+	}
+	switch {
+	case p.is("["):
+		p.advance()
+		list := []interface{}{}
+		for !p.is("]") {
+			v, err := p.parseValue(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		p.advance()
+		return list, nil
+	case p.is("{"):
+		p.advance()
+		obj := map[string]interface{}{}
+		for !p.is("}") {
+			if p.current().typ != tName {
+				return nil, p.errorf("expected object field name")
+			}
+			name := p.advance().text
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			v, err := p.parseValue(constant)
+			if err != nil {
+				return nil, err
+			}
+			obj[name] = v
+		}
+		p.advance()
+		return obj, nil
+	}
+	return nil, p.errorf("expected value")
+}
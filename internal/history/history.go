@@ -0,0 +1,213 @@
+// Package history summarizes the recorded analyses of a domain and what
+// changed from one to the next: verdict, registrar, expiry, nameservers and
+// estimated value.
+package history
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/compare"
+)
+
+// Summarized fields.
+const (
+	FieldVerdict     = "verdict"
+	FieldRegistrar   = "registrar"
+	FieldExpiry      = "expiry"
+	FieldNameServers = "name_servers"
+	FieldValue       = "value"
+)
+
+// paths are the result paths compare.Diff is filtered to for each
+// summarized field.
+var paths = map[string][]string{
+	FieldVerdict:     compare.VerdictPaths,
+	FieldRegistrar:   {"whois_data.registrar", "whois_data.error"},
+	FieldExpiry:      {"whois_data.expiry_date", "whois_data.error"},
+	FieldNameServers: {"whois_data.name_servers", "whois_data.error", "dns_availability.answers"},
+	FieldValue:       {"valuation_data.estimated_value"},
+}
+
+// Change is a field that differs from the previous analysis.
+type Change struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+	// Detail quantifies the change, e.g. "+365 days", "+$1200" or
+	// "added ns3.example.net".
+	Detail string `json:"detail,omitempty"`
+}
+
+// Entry is one recorded analysis. Fields a lookup could not fill are empty
+// and never count as a change.
+type Entry struct {
+	Timestamp      time.Time  `json:"timestamp"`
+	Status         string     `json:"status"`
+	Verdict        string     `json:"verdict"`
+	Registrar      string     `json:"registrar,omitempty"`
+	Expiry         *time.Time `json:"expiry,omitempty"`
+	NameServers    []string   `json:"name_servers,omitempty"`
+	EstimatedValue *int       `json:"estimated_value,omitempty"`
+	Changes        []Change   `json:"changes,omitempty"`
+}
+
+// Build returns an entry per result, oldest first, each with its changes
+// against the last known value of every field. The first entry is the
+// baseline and has none.
+func Build(results []*analyzer.Result) []Entry {
+	entries := make([]Entry, 0, len(results))
+	var last Entry
+	for i, r := range results {
+		e := entryOf(r)
+		if i > 0 {
+			e.Changes = diff(last, e, compare.Diff(results[i-1], r))
+		}
+		entries = append(entries, e)
+		last = merge(last, e)
+	}
+	return entries
+}
+
+func entryOf(r *analyzer.Result) Entry {
+	e := Entry{Timestamp: r.Timestamp, Status: r.Status, Verdict: r.Verdict()}
+	if w := r.WhoisData; w != nil && w.Error == "" {
+		e.Registrar = w.Registrar
+		e.Expiry = w.ExpiryDate
+		e.NameServers = normalize(w.NameServers)
+	}
+	if len(e.NameServers) == 0 && r.DNSAvailability != nil {
+		var hosts []string
+		for _, rec := range r.DNSAvailability.Answers {
+			if rec.Type == "NS" {
+				hosts = append(hosts, rec.Value)
+			}
+		}
+		e.NameServers = normalize(hosts)
+	}
+	if v := r.ValuationData; v != nil {
+		value := v.EstimatedValue
+		e.EstimatedValue = &value
+	}
+	return e
+}
+
+// merge carries the last known value of each field over an entry that
+// lacks it, so a failed lookup is not reported as a change.
+func merge(last, e Entry) Entry {
+	if e.Verdict == analyzer.VerdictUnknown {
+		e.Verdict = last.Verdict
+	}
+	if e.Registrar == "" {
+		e.Registrar = last.Registrar
+	}
+	if e.Expiry == nil {
+		e.Expiry = last.Expiry
+	}
+	if len(e.NameServers) == 0 {
+		e.NameServers = last.NameServers
+	}
+	if e.EstimatedValue == nil {
+		e.EstimatedValue = last.EstimatedValue
+	}
+	return e
+}
+
+// diff reports the fields that compare.Diff found changed between two
+// consecutive results, with their values in the entries, which carry the
+// last known values: a field whose lookup failed in between is only
+// reported when its value came back different.
+func diff(last, e Entry, resultChanges []compare.Change) []Change {
+	touched := func(field string) bool {
+		_, ok := compare.Touching(resultChanges, paths[field]...)
+		return ok
+	}
+	var changes []Change
+	if touched(FieldVerdict) && last.Verdict != "" && last.Verdict != analyzer.VerdictUnknown &&
+		e.Verdict != analyzer.VerdictUnknown && e.Verdict != last.Verdict {
+		changes = append(changes, Change{Field: FieldVerdict, Old: last.Verdict, New: e.Verdict})
+	}
+	if touched(FieldRegistrar) && last.Registrar != "" && e.Registrar != "" && !strings.EqualFold(last.Registrar, e.Registrar) {
+		changes = append(changes, Change{Field: FieldRegistrar, Old: last.Registrar, New: e.Registrar})
+	}
+	if touched(FieldExpiry) && last.Expiry != nil && e.Expiry != nil && !last.Expiry.Equal(*e.Expiry) {
+		days := int(e.Expiry.Sub(*last.Expiry).Hours() / 24)
+		changes = append(changes, Change{
+			Field:  FieldExpiry,
+			Old:    last.Expiry.Format("2006-01-02"),
+			New:    e.Expiry.Format("2006-01-02"),
+			Detail: fmt.Sprintf("%+d days", days),
+		})
+	}
+	if touched(FieldNameServers) && len(last.NameServers) > 0 && len(e.NameServers) > 0 {
+		if added, removed := hostChanges(last.NameServers, e.NameServers); len(added)+len(removed) > 0 {
+			var detail []string
+			if len(added) > 0 {
+				detail = append(detail, "added "+strings.Join(added, ", "))
+			}
+			if len(removed) > 0 {
+				detail = append(detail, "removed "+strings.Join(removed, ", "))
+			}
+			changes = append(changes, Change{
+				Field:  FieldNameServers,
+				Old:    strings.Join(last.NameServers, ", "),
+				New:    strings.Join(e.NameServers, ", "),
+				Detail: strings.Join(detail, "; "),
+			})
+		}
+	}
+	if touched(FieldValue) && last.EstimatedValue != nil && e.EstimatedValue != nil && *last.EstimatedValue != *e.EstimatedValue {
+		delta := *e.EstimatedValue - *last.EstimatedValue
+		sign := "+"
+		if delta < 0 {
+			sign, delta = "-", -delta
+		}
+		changes = append(changes, Change{
+			Field:  FieldValue,
+			Old:    fmt.Sprintf("$%d", *last.EstimatedValue),
+			New:    fmt.Sprintf("$%d", *e.EstimatedValue),
+			Detail: fmt.Sprintf("%s$%d", sign, delta),
+		})
+	}
+	return changes
+}
+
+// normalize lowercases host names, drops the trailing dot and sorts them.
+func normalize(hosts []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, h := range hosts {
+		h = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(h)), ".")
+		if h != "" && !seen[h] {
+			seen[h] = true
+			out = append(out, h)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// hostChanges returns the hosts only in b and those only in a, for the
+// detail of a nameserver change.
+func hostChanges(a, b []string) (added, removed []string) {
+	inA := map[string]bool{}
+	for _, h := range a {
+		inA[h] = true
+	}
+	inB := map[string]bool{}
+	for _, h := range b {
+		inB[h] = true
+		if !inA[h] {
+			added = append(added, h)
+		}
+	}
+	for _, h := range a {
+		if !inB[h] {
+			removed = append(removed, h)
+		}
+	}
+	return added, removed
+}
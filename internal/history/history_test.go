@@ -0,0 +1,42 @@
+package history
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/valuation"
+	"d3-domain-tool/internal/whois"
+)
+
+func taken(registrar string, expiry time.Time, value int, ns ...string) *analyzer.Result {
+	return &analyzer.Result{
+		Domain:        "example.com",
+		WhoisData:     &whois.Result{Registrar: registrar, ExpiryDate: &expiry, NameServers: ns},
+		ValuationData: &valuation.Result{EstimatedValue: value},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	expiry := time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)
+	results := []*analyzer.Result{
+		taken("Registrar A", expiry, 1000, "NS1.example.net.", "ns2.example.net"),
+		// A failed WHOIS lookup reports nothing, not a change.
+		{Domain: "example.com", WhoisData: &whois.Result{Error: "timeout"}, ValuationData: &valuation.Result{EstimatedValue: 1000}},
+		taken("Registrar B", expiry.AddDate(1, 0, 0), 800, "ns1.example.net", "ns3.example.net"),
+	}
+	entries := Build(results)
+	if len(entries) != 3 || len(entries[0].Changes) != 0 || len(entries[1].Changes) != 0 {
+		t.Fatalf("entries = %+v", entries)
+	}
+	want := []Change{
+		{Field: FieldRegistrar, Old: "Registrar A", New: "Registrar B"},
+		{Field: FieldExpiry, Old: "2027-03-01", New: "2028-03-01", Detail: "+366 days"},
+		{Field: FieldNameServers, Old: "ns1.example.net, ns2.example.net", New: "ns1.example.net, ns3.example.net", Detail: "added ns3.example.net; removed ns2.example.net"},
+		{Field: FieldValue, Old: "$1000", New: "$800", Detail: "-$200"},
+	}
+	if !reflect.DeepEqual(entries[2].Changes, want) {
+		t.Errorf("changes = %+v", entries[2].Changes)
+	}
+}
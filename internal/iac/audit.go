@@ -0,0 +1,152 @@
+package iac
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/whois"
+)
+
+// Finding kinds.
+const (
+	// ZoneWithoutDomain is a hosted zone whose domain is not registered:
+	// anyone can register it and take the name over.
+	ZoneWithoutDomain = "zone-without-domain"
+	// DomainWithoutZone is a registered domain with no hosted zone in the
+	// inventory.
+	DomainWithoutZone = "domain-without-zone"
+	// NameserverMismatch is a zone whose assigned nameservers differ from
+	// the delegation in the registry.
+	NameserverMismatch = "nameserver-mismatch"
+	// LookupFailed means WHOIS could not be checked for the zone.
+	LookupFailed = "lookup-failed"
+)
+
+// Finding is one discrepancy between the inventory and registry data.
+type Finding struct {
+	Domain     string   `json:"domain"`
+	Kind       string   `json:"kind"`
+	Message    string   `json:"message"`
+	ZoneNS     []string `json:"zone_name_servers,omitempty"`
+	RegistryNS []string `json:"registry_name_servers,omitempty"`
+}
+
+// Report is the result of an inventory audit.
+type Report struct {
+	Zones     int       `json:"zones"`
+	Domains   int       `json:"domains"`
+	Matched   int       `json:"matched"`
+	Findings  []Finding `json:"findings"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+type Auditor struct {
+	whois func(domain string) (*whois.Result, error)
+}
+
+func NewAuditor() *Auditor {
+	return &Auditor{whois: whois.NewClient().Lookup}
+}
+
+// Audit checks each apex zone against WHOIS, and each registered domain
+// (from the inventory or given by the caller) for a matching zone. Zones
+// below a registered domain, such as dev.example.com, are delegated
+// subzones and are not looked up.
+func (a *Auditor) Audit(inv *Inventory, domains []string) *Report {
+	report := &Report{CheckedAt: time.Now()}
+
+	zones := map[string]bool{}
+	for _, z := range inv.Zones {
+		zones[z.Name] = true
+	}
+	registered := map[string]bool{}
+	for _, d := range append(append([]string{}, inv.Registered...), domains...) {
+		if d = normalize(d); d != "" {
+			registered[d] = true
+		}
+	}
+
+	for _, z := range inv.Zones {
+		if isSubzone(z.Name, zones, registered) {
+			continue
+		}
+		report.Zones++
+		result, err := a.whois(z.Name)
+		if err == nil && result.Error != "" {
+			err = fmt.Errorf("%s", result.Error)
+		}
+		switch {
+		case err != nil:
+			report.Findings = append(report.Findings, Finding{
+				Domain: z.Name, Kind: LookupFailed,
+				Message: fmt.Sprintf("WHOIS lookup failed: %v", err),
+			})
+		case result.Available:
+			report.Findings = append(report.Findings, Finding{
+				Domain: z.Name, Kind: ZoneWithoutDomain, ZoneNS: z.NameServers,
+				Message: "zone is hosted but the domain is not registered; anyone can register it and take over the zone's name",
+			})
+		case len(z.NameServers) > 0 && len(result.NameServers) > 0 && !sameServers(z.NameServers, result.NameServers):
+			report.Findings = append(report.Findings, Finding{
+				Domain: z.Name, Kind: NameserverMismatch,
+				ZoneNS: z.NameServers, RegistryNS: normalizeAll(result.NameServers),
+				Message: "registry delegation does not point at the zone's nameservers; the zone is not serving the domain",
+			})
+		default:
+			report.Matched++
+		}
+	}
+
+	var names []string
+	for d := range registered {
+		names = append(names, d)
+	}
+	sort.Strings(names)
+	report.Domains = len(names)
+	for _, d := range names {
+		if !zones[d] {
+			report.Findings = append(report.Findings, Finding{
+				Domain: d, Kind: DomainWithoutZone,
+				Message: "domain is registered but has no hosted zone in the inventory",
+			})
+		}
+	}
+	return report
+}
+
+// isSubzone reports whether zone sits below another zone or registered
+// domain in the inventory, or has more than two labels without either.
+func isSubzone(zone string, zones, registered map[string]bool) bool {
+	labels := strings.Split(zone, ".")
+	for i := 1; i < len(labels)-1; i++ {
+		parent := strings.Join(labels[i:], ".")
+		if zones[parent] || registered[parent] {
+			return true
+		}
+	}
+	return !registered[zone] && len(labels) > 2
+}
+
+func sameServers(a, b []string) bool {
+	a, b = normalizeAll(a), normalizeAll(b)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeAll(servers []string) []string {
+	out := make([]string, 0, len(servers))
+	for _, s := range servers {
+		out = append(out, normalize(s))
+	}
+	sort.Strings(out)
+	return out
+}
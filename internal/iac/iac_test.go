@@ -0,0 +1,65 @@
+package iac
+
+import (
+	"fmt"
+	"testing"
+
+	"d3-domain-tool/internal/whois"
+)
+
+func TestLoad(t *testing.T) {
+	inv, err := Load("testdata/terraform.tfstate", "testdata/example.org.zone")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]int{"example.com": 2, "dev.example.com": 1, "legacy-example.net": 1, "example.org": 2}
+	if len(inv.Zones) != len(want) {
+		t.Fatalf("expected %d zones, got %+v", len(want), inv.Zones)
+	}
+	for _, z := range inv.Zones {
+		if n, ok := want[z.Name]; !ok || len(z.NameServers) != n {
+			t.Errorf("unexpected zone %+v", z)
+		}
+	}
+	if len(inv.Registered) != 1 || inv.Registered[0] != "example-shop.com" {
+		t.Errorf("unexpected registered domains %v", inv.Registered)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	inv, err := Load("testdata/terraform.tfstate", "testdata/example.org.zone")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := &Auditor{whois: func(domain string) (*whois.Result, error) {
+		switch domain {
+		case "example.com":
+			return &whois.Result{NameServers: []string{"NS-2.AWSDNS-02.COM", "NS-1.AWSDNS-01.ORG"}}, nil
+		case "legacy-example.net":
+			return &whois.Result{Available: true}, nil
+		case "example.org":
+			return &whois.Result{NameServers: []string{"ns1.registrar-servers.com", "ns2.registrar-servers.com"}}, nil
+		}
+		return nil, fmt.Errorf("unexpected lookup of %s", domain)
+	}}
+
+	report := a.Audit(inv, []string{"example.com", "brand-example.io"})
+	if report.Zones != 3 || report.Matched != 1 || report.Domains != 3 {
+		t.Errorf("unexpected totals: %+v", report)
+	}
+	want := map[string]string{
+		"legacy-example.net": ZoneWithoutDomain,
+		"example.org":        NameserverMismatch,
+		"example-shop.com":   DomainWithoutZone,
+		"brand-example.io":   DomainWithoutZone,
+	}
+	if len(report.Findings) != len(want) {
+		t.Fatalf("expected %d findings, got %+v", len(want), report.Findings)
+	}
+	for _, f := range report.Findings {
+		if want[f.Domain] != f.Kind {
+			t.Errorf("%s: %s, want %s", f.Domain, f.Kind, want[f.Domain])
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Package iac cross-checks DNS zones declared in infrastructure-as-code
+// (Terraform state, cloud zone exports, zone files) against registry
+// WHOIS data.
+package iac
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Zone is a hosted DNS zone found in an inventory file.
+type Zone struct {
+	Name        string   `json:"name"`
+	NameServers []string `json:"name_servers,omitempty"`
+	Source      string   `json:"source"`
+}
+
+// Inventory is everything found in the inventory files: hosted zones and
+// registered domains (e.g. aws_route53domains_registered_domain).
+type Inventory struct {
+	Zones      []Zone   `json:"zones"`
+	Registered []string `json:"registered,omitempty"`
+}
+
+// zoneResources maps Terraform zone resource types to the attributes that
+// hold the zone name and its assigned nameservers.
+var zoneResources = map[string][2]string{
+	"aws_route53_zone":        {"name", "name_servers"},
+	"google_dns_managed_zone": {"dns_name", "name_servers"},
+	"azurerm_dns_zone":        {"name", "name_servers"},
+	"cloudflare_zone":         {"zone", "name_servers"},
+}
+
+// Load reads one or more inventory files. Supported formats are Terraform
+// state (v4), "aws route53 get-hosted-zone" and "list-hosted-zones" output,
+// "gcloud dns managed-zones list --format=json" output, and BIND zone
+// files.
+func Load(paths ...string) (*Inventory, error) {
+	inv := &Inventory{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read inventory: %v", err)
+		}
+		if err := inv.parse(path, data); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	inv.merge()
+	return inv, nil
+}
+
+func (inv *Inventory) parse(source string, data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return inv.parseZoneFile(source, data)
+	}
+
+	if trimmed[0] == '[' {
+		var zones []struct {
+			DNSName     string   `json:"dnsName"`
+			NameServers []string `json:"nameServers"`
+		}
+		if err := json.Unmarshal(trimmed, &zones); err != nil {
+			return fmt.Errorf("unrecognized JSON: %v", err)
+		}
+		for _, z := range zones {
+			inv.addZone(z.DNSName, z.NameServers, source)
+		}
+		return nil
+	}
+
+	var doc struct {
+		Resources []struct {
+			Type      string `json:"type"`
+			Instances []struct {
+				Attributes map[string]interface{} `json:"attributes"`
+			} `json:"instances"`
+		} `json:"resources"`
+		HostedZone *struct {
+			Name string `json:"Name"`
+		} `json:"HostedZone"`
+		DelegationSet *struct {
+			NameServers []string `json:"NameServers"`
+		} `json:"DelegationSet"`
+		HostedZones []struct {
+			Name   string `json:"Name"`
+			Config struct {
+				PrivateZone bool `json:"PrivateZone"`
+			} `json:"Config"`
+		} `json:"HostedZones"`
+	}
+	if err := json.Unmarshal(trimmed, &doc); err != nil {
+		return fmt.Errorf("unrecognized JSON: %v", err)
+	}
+
+	switch {
+	case doc.Resources != nil:
+		for _, r := range doc.Resources {
+			attrs, isZone := zoneResources[r.Type]
+			for _, inst := range r.Instances {
+				if isZone {
+					if private, _ := inst.Attributes["vpc"].([]interface{}); len(private) > 0 {
+						continue
+					}
+					name, _ := inst.Attributes[attrs[0]].(string)
+					inv.addZone(name, stringList(inst.Attributes[attrs[1]]), source)
+				}
+				if r.Type == "aws_route53domains_registered_domain" {
+					if name, _ := inst.Attributes["domain_name"].(string); name != "" {
+						inv.Registered = append(inv.Registered, normalize(name))
+					}
+				}
+			}
+		}
+	case doc.HostedZone != nil:
+		var servers []string
+		if doc.DelegationSet != nil {
+			servers = doc.DelegationSet.NameServers
+		}
+		inv.addZone(doc.HostedZone.Name, servers, source)
+	case doc.HostedZones != nil:
+		for _, z := range doc.HostedZones {
+			if !z.Config.PrivateZone {
+				inv.addZone(z.Name, nil, source)
+			}
+		}
+	default:
+		return fmt.Errorf("no Terraform resources or hosted zones found")
+	}
+	return nil
+}
+
+// parseZoneFile reads the apex NS records of a BIND zone file.
+func (inv *Inventory) parseZoneFile(source string, data []byte) error {
+	origin, owner := "", ""
+	var servers []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, ";"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if strings.EqualFold(fields[0], "$ORIGIN") && len(fields) > 1 {
+			if origin == "" {
+				origin = normalize(fields[1])
+			}
+			continue
+		}
+		if strings.HasPrefix(fields[0], "$") {
+			continue
+		}
+
+		// A line starting with whitespace continues the previous owner.
+		if line[0] != ' ' && line[0] != '\t' {
+			owner = fields[0]
+			fields = fields[1:]
+		}
+		for i, f := range fields {
+			if !strings.EqualFold(f, "NS") || i+1 >= len(fields) {
+				continue
+			}
+			name := owner
+			if name == "@" {
+				name = origin
+			}
+			if origin == "" && strings.HasSuffix(name, ".") {
+				origin = normalize(name)
+			}
+			if normalize(name) == origin {
+				servers = append(servers, fields[i+1])
+			}
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if origin == "" {
+		return fmt.Errorf("not a zone file: no $ORIGIN or absolute owner name")
+	}
+	inv.addZone(origin, servers, source)
+	return nil
+}
+
+func (inv *Inventory) addZone(name string, servers []string, source string) {
+	if name = normalize(name); name == "" {
+		return
+	}
+	zone := Zone{Name: name, Source: source}
+	for _, s := range servers {
+		zone.NameServers = append(zone.NameServers, normalize(s))
+	}
+	inv.Zones = append(inv.Zones, zone)
+}
+
+// merge combines duplicate zones, e.g. a zone present in both Terraform
+// state and a zone export, keeping the first nameserver set found.
+func (inv *Inventory) merge() {
+	byName := map[string]int{}
+	var zones []Zone
+	for _, z := range inv.Zones {
+		if i, ok := byName[z.Name]; ok {
+			if len(zones[i].NameServers) == 0 {
+				zones[i].NameServers = z.NameServers
+			}
+			zones[i].Source += ", " + z.Source
+			continue
+		}
+		byName[z.Name] = len(zones)
+		zones = append(zones, z)
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].Name < zones[j].Name })
+	inv.Zones = zones
+}
+
+func stringList(v interface{}) []string {
+	items, _ := v.([]interface{})
+	var out []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+}
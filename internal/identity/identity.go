@@ -0,0 +1,324 @@
+// Package identity builds a namespace coverage report for a brand name:
+// which of its DNS domains, blockchain names and social handles are
+// secured, still available, or held by someone else.
+package identity
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+)
+
+// Namespace kinds.
+const (
+	KindDNS        = "dns"
+	KindBlockchain = "blockchain"
+	KindSocial     = "social"
+)
+
+// Coverage statuses.
+const (
+	// StatusSecured is taken and the owner matches one of the identifiers
+	// given with SetOwners.
+	StatusSecured = "secured"
+	// StatusThirdParty is taken by an owner that matches none of them.
+	StatusThirdParty = "third-party"
+	// StatusTaken is taken but the owner cannot be compared: no
+	// identifiers were given or the owner is hidden.
+	StatusTaken = "taken"
+	// StatusMissing is still available.
+	StatusMissing = "missing"
+	// StatusUnknown means the lookup failed.
+	StatusUnknown = "unknown"
+)
+
+// DefaultTLDs are the DNS TLDs checked for a name.
+var DefaultTLDs = []string{"com", "net", "org", "io"}
+
+// DefaultWeb3 are the blockchain TLDs checked for a name.
+var DefaultWeb3 = []string{"eth", "crypto", "sol"}
+
+// Platform is a social network whose profile URL for a handle is URL with
+// %s replaced by the handle. A 404 means the handle is free.
+type Platform struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// DefaultPlatforms are checked for social handles.
+var DefaultPlatforms = []Platform{
+	{Name: "GitHub", URL: "https://github.com/%s"},
+	{Name: "GitLab", URL: "https://gitlab.com/%s"},
+	{Name: "Reddit", URL: "https://www.reddit.com/user/%s/about.json"},
+	{Name: "YouTube", URL: "https://www.youtube.com/@%s"},
+}
+
+// DefaultSNSAPI resolves Solana Name Service (.sol) names to their owner.
+const DefaultSNSAPI = "https://sns-sdk-proxy.bonfida.workers.dev/resolve"
+
+// ParsePlatforms parses "name=url-with-%s,..." social platform overrides.
+func ParsePlatforms(spec string) ([]Platform, error) {
+	var platforms []Platform
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, template, ok := strings.Cut(item, "=")
+		if !ok || name == "" || !strings.Contains(template, "%s") {
+			return nil, fmt.Errorf("invalid platform %q: expected name=url containing %%s", item)
+		}
+		platforms = append(platforms, Platform{Name: name, URL: template})
+	}
+	return platforms, nil
+}
+
+// Entry is the coverage of one namespace.
+type Entry struct {
+	Kind   string `json:"kind"`
+	Target string `json:"target"`
+	Status string `json:"status"`
+	Owner  string `json:"owner,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the namespace coverage of a name.
+type Report struct {
+	Name       string    `json:"name"`
+	Entries    []Entry   `json:"entries"`
+	Secured    int       `json:"secured"`
+	Missing    int       `json:"missing"`
+	ThirdParty int       `json:"third_party"`
+	Taken      int       `json:"taken"`
+	Unknown    int       `json:"unknown"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+type Checker struct {
+	analyze   func(domain string) (*analyzer.Result, error)
+	client    *http.Client
+	snsAPI    string
+	tlds      []string
+	web3      []string
+	platforms []Platform
+	owners    []string
+}
+
+func NewChecker(a *analyzer.Analyzer) *Checker {
+	return &Checker{
+		analyze:   a.AnalyzeDomain,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		snsAPI:    DefaultSNSAPI,
+		tlds:      DefaultTLDs,
+		web3:      DefaultWeb3,
+		platforms: DefaultPlatforms,
+	}
+}
+
+// SetTLDs replaces the DNS TLDs; an empty list keeps the defaults.
+func (c *Checker) SetTLDs(tlds []string) {
+	if len(tlds) > 0 {
+		c.tlds = tlds
+	}
+}
+
+// SetWeb3 replaces the blockchain TLDs; an empty list keeps the defaults.
+func (c *Checker) SetWeb3(tlds []string) {
+	if len(tlds) > 0 {
+		c.web3 = tlds
+	}
+}
+
+// SetPlatforms replaces the social platforms; an empty list keeps the
+// defaults.
+func (c *Checker) SetPlatforms(platforms []Platform) {
+	if len(platforms) > 0 {
+		c.platforms = platforms
+	}
+}
+
+// SetOwners sets the identifiers that mark an asset as the brand's own:
+// wallet addresses, and organization names, email domains or websites
+// matched against WHOIS records and social profiles.
+func (c *Checker) SetOwners(owners []string) {
+	c.owners = nil
+	for _, owner := range owners {
+		if owner = strings.ToLower(strings.TrimSpace(owner)); owner != "" {
+			c.owners = append(c.owners, owner)
+		}
+	}
+}
+
+// Check reports the coverage of name across all configured namespaces.
+func (c *Checker) Check(name string) (*Report, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || strings.ContainsAny(name, ". ") {
+		return nil, fmt.Errorf("expected a bare name such as \"acme\", got %q", name)
+	}
+
+	var entries []Entry
+	for _, tld := range c.tlds {
+		entries = append(entries, Entry{Kind: KindDNS, Target: name + "." + strings.TrimPrefix(tld, ".")})
+	}
+	for _, tld := range c.web3 {
+		entries = append(entries, Entry{Kind: KindBlockchain, Target: name + "." + strings.TrimPrefix(tld, ".")})
+	}
+	for _, p := range c.platforms {
+		entries = append(entries, Entry{Kind: KindSocial, Target: p.Name, URL: fmt.Sprintf(p.URL, name)})
+	}
+
+	var wg sync.WaitGroup
+	for i := range entries {
+		wg.Add(1)
+		go func(e *Entry) {
+			defer wg.Done()
+			c.check(e)
+		}(&entries[i])
+	}
+	wg.Wait()
+
+	report := &Report{Name: name, Entries: entries, CheckedAt: time.Now()}
+	for _, e := range entries {
+		switch e.Status {
+		case StatusSecured:
+			report.Secured++
+		case StatusMissing:
+			report.Missing++
+		case StatusThirdParty:
+			report.ThirdParty++
+		case StatusTaken:
+			report.Taken++
+		default:
+			report.Unknown++
+		}
+	}
+	return report, nil
+}
+
+func (c *Checker) check(e *Entry) {
+	switch {
+	case e.Kind == KindSocial:
+		c.checkProfile(e)
+	case strings.HasSuffix(e.Target, ".sol"):
+		c.checkSNS(e)
+	default:
+		c.checkDomain(e)
+	}
+}
+
+func (c *Checker) checkDomain(e *Entry) {
+	result, err := c.analyze(e.Target)
+	if err != nil {
+		e.Status, e.Error = StatusUnknown, err.Error()
+		return
+	}
+
+	switch result.Verdict() {
+	case analyzer.VerdictAvailable:
+		e.Status = StatusMissing
+		return
+	case analyzer.VerdictUnknown:
+		e.Status = StatusUnknown
+		if len(result.SectionErrors) > 0 {
+			e.Error = result.SectionErrors[0].Error
+		}
+		return
+	}
+
+	switch {
+	case result.BlockchainData != nil:
+		e.Owner = result.BlockchainData.Owner
+		e.Status = c.classify(e.Owner, e.Owner != "")
+	case result.WhoisData != nil:
+		raw := result.WhoisData.RawData
+		e.Status = c.classify(raw, registrant.MatchString(raw) && !hiddenRegistrant.MatchString(raw))
+	default:
+		e.Status = c.classify("", false)
+	}
+}
+
+func (c *Checker) checkSNS(e *Entry) {
+	resp, err := c.client.Get(c.snsAPI + "/" + strings.TrimSuffix(e.Target, ".sol"))
+	if err != nil {
+		e.Status, e.Error = StatusUnknown, fmt.Sprintf("SNS lookup failed: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		S      string `json:"s"`
+		Result string `json:"result"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		e.Status, e.Error = StatusUnknown, fmt.Sprintf("SNS lookup returned HTTP %d", resp.StatusCode)
+		return
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		e.Status, e.Error = StatusUnknown, fmt.Sprintf("invalid SNS response: %v", err)
+		return
+	}
+	if body.S != "ok" || body.Result == "" {
+		e.Status = StatusMissing
+		return
+	}
+	e.Owner = body.Result
+	e.Status = c.classify(body.Result, true)
+}
+
+func (c *Checker) checkProfile(e *Entry) {
+	req, err := http.NewRequest(http.MethodGet, e.URL, nil)
+	if err != nil {
+		e.Status, e.Error = StatusUnknown, err.Error()
+		return
+	}
+	req.Header.Set("User-Agent", "d3-domain-tool")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		e.Status, e.Error = StatusUnknown, err.Error()
+		return
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		e.Status = StatusMissing
+	case resp.StatusCode == http.StatusOK:
+		page, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		e.Status = c.classify(string(page), true)
+	default:
+		e.Status, e.Error = StatusUnknown, fmt.Sprintf("HTTP %d", resp.StatusCode)
+	}
+}
+
+var (
+	registrant       = regexp.MustCompile(`(?im)^\s*registrant[^:\n]*:\s*\S`)
+	hiddenRegistrant = regexp.MustCompile(`(?im)^\s*registrant (name|organization)\s*:.*(redacted|privacy|withheld|not disclosed|data protected)`)
+)
+
+// classify decides the status of a taken asset from evidence about its
+// owner: an address, raw WHOIS text or a profile page. known reports
+// whether the evidence actually identifies the owner, so that a mismatch
+// means a third party rather than a hidden owner.
+func (c *Checker) classify(evidence string, known bool) string {
+	if len(c.owners) == 0 {
+		return StatusTaken
+	}
+	lower := strings.ToLower(evidence)
+	for _, owner := range c.owners {
+		if strings.Contains(lower, owner) {
+			return StatusSecured
+		}
+	}
+	if !known {
+		return StatusTaken
+	}
+	return StatusThirdParty
+}
@@ -0,0 +1,86 @@
+package identity
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/blockchain"
+	"d3-domain-tool/internal/whois"
+)
+
+func TestCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sns/acme":
+			w.Write([]byte(`{"s": "ok", "result": "AcmeSoLaNaPubKey"}`))
+		case "/github/acme":
+			w.Write([]byte(`<html>Acme Inc · https://acme.com</html>`))
+		case "/reddit/acme":
+			w.Write([]byte(`{"data": {"name": "acme", "subreddit": {"public_description": "not the company"}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	results := map[string]*analyzer.Result{
+		"acme.com": {WhoisData: &whois.Result{RawData: "Registrant Organization: Acme Inc\n"}},
+		"acme.net": {WhoisData: &whois.Result{RawData: "Registrant Organization: Cybersquat LLC\n"}},
+		"acme.org": {WhoisData: &whois.Result{RawData: "Registrant Name: REDACTED FOR PRIVACY\n"}},
+		"acme.io":  {WhoisData: &whois.Result{Available: true}},
+		"acme.eth": {BlockchainData: &blockchain.Result{Available: new(bool), Owner: "0xAbC"}},
+	}
+	c := &Checker{
+		analyze: func(domain string) (*analyzer.Result, error) {
+			if r, ok := results[domain]; ok {
+				return r, nil
+			}
+			return nil, fmt.Errorf("lookup failed")
+		},
+		client: server.Client(),
+		snsAPI: server.URL + "/sns",
+		tlds:   DefaultTLDs,
+		web3:   []string{"eth", "sol", "crypto"},
+		platforms: []Platform{
+			{Name: "GitHub", URL: server.URL + "/github/%s"},
+			{Name: "Reddit", URL: server.URL + "/reddit/%s"},
+			{Name: "GitLab", URL: server.URL + "/gitlab/%s"},
+		},
+	}
+	c.SetOwners([]string{"Acme Inc", "0xabc"})
+
+	report, err := c.Check("Acme")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{
+		"acme.com":    StatusSecured,
+		"acme.net":    StatusThirdParty,
+		"acme.org":    StatusTaken,
+		"acme.io":     StatusMissing,
+		"acme.eth":    StatusSecured,
+		"acme.sol":    StatusThirdParty,
+		"acme.crypto": StatusUnknown,
+		"GitHub":      StatusSecured,
+		"Reddit":      StatusThirdParty,
+		"GitLab":      StatusMissing,
+	}
+	if len(report.Entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(report.Entries))
+	}
+	for _, e := range report.Entries {
+		if e.Status != want[e.Target] {
+			t.Errorf("%s: status %s, want %s", e.Target, e.Status, want[e.Target])
+		}
+	}
+	if report.Secured != 3 || report.Missing != 2 || report.ThirdParty != 3 || report.Taken != 1 || report.Unknown != 1 {
+		t.Errorf("unexpected summary: %+v", report)
+	}
+
+	if _, err := c.Check("acme.com"); err == nil {
+		t.Error("expected an error for a name with a TLD")
+	}
+}
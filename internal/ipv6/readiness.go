@@ -0,0 +1,212 @@
+package ipv6
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/checker"
+)
+
+// Checker grades how usable a domain is from an IPv6-only network: the
+// website, the nameservers that serve the zone and the mail exchangers all
+// need AAAA records, and those addresses need to answer.
+type Checker struct {
+	query   func(name, rrType string) (*checker.Response, error)
+	dial    func(network, address string, timeout time.Duration) (net.Conn, error)
+	hasIPv6 func() bool
+	timeout time.Duration
+}
+
+type Result struct {
+	Web          []Host    `json:"web"`
+	Nameservers  []Host    `json:"nameservers"`
+	MailServers  []Host    `json:"mail_servers"`
+	Connectivity bool      `json:"local_ipv6_connectivity"`
+	Score        int       `json:"score"`
+	Grade        string    `json:"grade"`
+	Issues       []string  `json:"issues,omitempty"`
+	CheckedAt    time.Time `json:"checked_at"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// Host is the IPv6 status of one name. Reachable is only set when the
+// local machine has IPv6 connectivity to test with.
+type Host struct {
+	Name      string   `json:"name"`
+	IPv6      []string `json:"ipv6,omitempty"`
+	Reachable *bool    `json:"reachable,omitempty"`
+}
+
+// Ready reports whether the host has an AAAA record that was not found to
+// be unreachable.
+func (h Host) Ready() bool {
+	return len(h.IPv6) > 0 && (h.Reachable == nil || *h.Reachable)
+}
+
+func NewChecker() *Checker {
+	resolver := checker.NewResolver()
+	return &Checker{
+		query:   resolver.Query,
+		dial:    net.DialTimeout,
+		hasIPv6: localIPv6,
+		timeout: 3 * time.Second,
+	}
+}
+
+// localIPv6 reports whether there is a route to the IPv6 internet. Dialing
+// UDP sends nothing; it only fails when no route exists.
+func localIPv6() bool {
+	conn, err := net.Dial("udp6", "[2001:4860:4860::8888]:53")
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+func (c *Checker) Check(domain string) (*Result, error) {
+	result := &Result{
+		CheckedAt:    time.Now(),
+		Connectivity: c.hasIPv6(),
+	}
+
+	for _, name := range []string{domain, "www." + domain} {
+		host := c.host(name, "443", result.Connectivity)
+		if name != domain && len(host.IPv6) == 0 && !c.exists(name) {
+			continue
+		}
+		result.Web = append(result.Web, host)
+	}
+
+	nsHosts, err := c.targets(domain, "NS")
+	if err != nil {
+		result.Error = fmt.Sprintf("NS lookup failed: %v", err)
+		return result, nil
+	}
+	for _, ns := range nsHosts {
+		result.Nameservers = append(result.Nameservers, c.host(ns, "53", result.Connectivity))
+	}
+
+	mxHosts, err := c.targets(domain, "MX")
+	if err != nil {
+		result.Error = fmt.Sprintf("MX lookup failed: %v", err)
+		return result, nil
+	}
+	for _, mx := range mxHosts {
+		result.MailServers = append(result.MailServers, c.host(mx, "25", result.Connectivity))
+	}
+
+	score(result)
+	return result, nil
+}
+
+// host looks up the AAAA records of name and, when probe is set, tries a
+// TCP connection to port on the first address.
+func (c *Checker) host(name, port string, probe bool) Host {
+	host := Host{Name: name}
+	resp, err := c.query(name, "AAAA")
+	if err != nil {
+		return host
+	}
+	for _, rec := range resp.Answers {
+		if rec.Type == "AAAA" {
+			host.IPv6 = append(host.IPv6, rec.Value)
+		}
+	}
+	sort.Strings(host.IPv6)
+
+	if probe && len(host.IPv6) > 0 {
+		reachable := false
+		if conn, err := c.dial("tcp6", net.JoinHostPort(host.IPv6[0], port), c.timeout); err == nil {
+			conn.Close()
+			reachable = true
+		}
+		host.Reachable = &reachable
+	}
+	return host
+}
+
+func (c *Checker) exists(name string) bool {
+	resp, err := c.query(name, "A")
+	return err == nil && len(resp.Answers) > 0
+}
+
+// targets returns the host names the domain's NS or MX records point to.
+func (c *Checker) targets(domain, rrType string) ([]string, error) {
+	resp, err := c.query(domain, rrType)
+	if err != nil {
+		return nil, err
+	}
+	var hosts []string
+	for _, rec := range resp.Answers {
+		if rec.Type != rrType || rec.Value == "" || rec.Value == "." {
+			continue
+		}
+		hosts = append(hosts, strings.TrimSuffix(rec.Value, "."))
+	}
+	sort.Strings(hosts)
+	return hosts, nil
+}
+
+// score awards 30 points for the website, 35 for the nameservers and 35
+// for mail, with half credit when only some hosts are ready.
+func score(result *Result) {
+	result.Score += part(result, "Website", result.Web, 30)
+	result.Score += part(result, "Nameservers", result.Nameservers, 35)
+	if len(result.MailServers) == 0 {
+		result.Score += 35
+	} else {
+		result.Score += part(result, "Mail servers", result.MailServers, 35)
+	}
+	result.Grade = grade(result.Score)
+
+	if !result.Connectivity {
+		result.Issues = append(result.Issues, "No local IPv6 connectivity; AAAA records were checked but not connected to")
+	}
+}
+
+func part(result *Result, label string, hosts []Host, points int) int {
+	var ready, missing, unreachable []string
+	for _, h := range hosts {
+		switch {
+		case h.Ready():
+			ready = append(ready, h.Name)
+		case len(h.IPv6) == 0:
+			missing = append(missing, h.Name)
+		default:
+			unreachable = append(unreachable, h.Name)
+		}
+	}
+	if len(missing) > 0 {
+		result.Issues = append(result.Issues, fmt.Sprintf("%s without AAAA: %s", label, strings.Join(missing, ", ")))
+	}
+	if len(unreachable) > 0 {
+		result.Issues = append(result.Issues, fmt.Sprintf("%s unreachable over IPv6: %s", label, strings.Join(unreachable, ", ")))
+	}
+
+	switch {
+	case len(hosts) == 0 || len(ready) == 0:
+		return 0
+	case len(ready) == len(hosts):
+		return points
+	default:
+		return points / 2
+	}
+}
+
+func grade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 75:
+		return "B"
+	case score >= 50:
+		return "C"
+	case score >= 25:
+		return "D"
+	}
+	return "F"
+}
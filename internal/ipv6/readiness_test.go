@@ -0,0 +1,86 @@
+package ipv6
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/checker"
+)
+
+func fakeChecker(records map[string][]checker.Record, connectivity bool, reachable map[string]bool) *Checker {
+	return &Checker{
+		query: func(name, rrType string) (*checker.Response, error) {
+			resp := &checker.Response{Rcode: "NOERROR"}
+			for _, rec := range records[name] {
+				if rec.Type == rrType {
+					resp.Answers = append(resp.Answers, rec)
+				}
+			}
+			return resp, nil
+		},
+		dial: func(network, address string, timeout time.Duration) (net.Conn, error) {
+			host, _, _ := net.SplitHostPort(address)
+			if reachable[host] {
+				client, server := net.Pipe()
+				server.Close()
+				return client, nil
+			}
+			return nil, errors.New("connection refused")
+		},
+		hasIPv6: func() bool { return connectivity },
+	}
+}
+
+func TestCheck_FullyReady(t *testing.T) {
+	c := fakeChecker(map[string][]checker.Record{
+		"example.com": {
+			{Type: "AAAA", Value: "2001:db8::1"},
+			{Type: "NS", Value: "ns1.example.net."},
+			{Type: "MX", Value: "mx.example.net."},
+		},
+		"ns1.example.net": {{Type: "AAAA", Value: "2001:db8::53"}},
+		"mx.example.net":  {{Type: "AAAA", Value: "2001:db8::25"}},
+	}, false, nil)
+
+	result, err := c.Check("example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Score != 100 || result.Grade != "A" {
+		t.Errorf("expected 100/A, got %d/%s (issues: %v)", result.Score, result.Grade, result.Issues)
+	}
+	if len(result.Web) != 1 {
+		t.Errorf("expected www to be skipped when it does not exist, got %+v", result.Web)
+	}
+}
+
+func TestCheck_PartialAndUnreachable(t *testing.T) {
+	c := fakeChecker(map[string][]checker.Record{
+		"example.com": {
+			{Type: "A", Value: "192.0.2.1"},
+			{Type: "NS", Value: "ns1.example.net."},
+			{Type: "NS", Value: "ns2.example.net."},
+			{Type: "MX", Value: "mx.example.net."},
+		},
+		"www.example.com": {{Type: "AAAA", Value: "2001:db8::80"}},
+		"ns1.example.net": {{Type: "AAAA", Value: "2001:db8::53"}},
+		"ns2.example.net": {{Type: "A", Value: "192.0.2.53"}},
+		"mx.example.net":  {{Type: "AAAA", Value: "2001:db8::25"}},
+	}, true, map[string]bool{"2001:db8::80": true, "2001:db8::53": true})
+
+	result, _ := c.Check("example.com")
+
+	// web: www only (15), NS: one of two (17), MX: unreachable (0)
+	if result.Score != 32 || result.Grade != "D" {
+		t.Errorf("expected 32/D, got %d/%s", result.Score, result.Grade)
+	}
+	mx := result.MailServers[0]
+	if mx.Reachable == nil || *mx.Reachable {
+		t.Errorf("expected mail server to be marked unreachable, got %+v", mx)
+	}
+	if len(result.Issues) != 3 {
+		t.Errorf("expected 3 issues, got %v", result.Issues)
+	}
+}
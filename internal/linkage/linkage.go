@@ -0,0 +1,235 @@
+// Package linkage detects traditional DNS domains that have an on-chain
+// counterpart: names imported into ENS through DNSSEC, either gaslessly via
+// an "ENS1" TXT record or claimed in the ENS registry, and names resolvable
+// through Unstoppable Domains.
+package linkage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/blockchain"
+)
+
+// DefaultUDAPI is the Unstoppable Domains Resolution API.
+const DefaultUDAPI = blockchain.DefaultUDResolutionAPI
+
+// ENS import methods.
+const (
+	MethodGasless = "dnssec-gasless"
+	MethodOnchain = "dnssec-onchain"
+)
+
+type Checker struct {
+	lookupTXT func(name string) ([]string, error)
+	rpc       *blockchain.RPCClient
+	udAPI     string
+	udKey     string
+	client    *http.Client
+}
+
+type Result struct {
+	Linked    bool      `json:"linked"`
+	ENS       *ENSLink  `json:"ens,omitempty"`
+	UD        *UDLink   `json:"unstoppable,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// ENSLink describes how a DNS name is reachable in ENS.
+type ENSLink struct {
+	Methods []string `json:"methods"`
+	// OffchainResolver is the resolver named by the ENS1 TXT record.
+	OffchainResolver string `json:"offchain_resolver,omitempty"`
+	// ClaimAddress is the address in the _ens TXT record that may claim the
+	// name in the ENS registry.
+	ClaimAddress     string `json:"claim_address,omitempty"`
+	RegistryOwner    string `json:"registry_owner,omitempty"`
+	RegistryResolver string `json:"registry_resolver,omitempty"`
+}
+
+// UDLink is the Unstoppable Domains record for a DNS name.
+type UDLink struct {
+	Owner    string            `json:"owner,omitempty"`
+	Resolver string            `json:"resolver,omitempty"`
+	Records  map[string]string `json:"records,omitempty"`
+	// ReverseName is the UD name the ENS claim or registry owner address
+	// reverse-resolves to.
+	ReverseName string `json:"reverse_name,omitempty"`
+}
+
+func NewChecker() *Checker {
+	return &Checker{
+		lookupTXT: net.LookupTXT,
+		udAPI:     DefaultUDAPI,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SetRPC enables ENS registry lookups through an Ethereum JSON-RPC client.
+func (c *Checker) SetRPC(rpc *blockchain.RPCClient) {
+	c.rpc = rpc
+}
+
+// SetUDKey enables Unstoppable Domains lookups with a Resolution API key.
+func (c *Checker) SetUDKey(key string) {
+	c.udKey = key
+}
+
+// RPCEndpoint returns the JSON-RPC URL, or "" when registry lookups are
+// disabled.
+func (c *Checker) RPCEndpoint() string {
+	if c.rpc == nil {
+		return ""
+	}
+	return c.rpc.Endpoint()
+}
+
+// UDEndpoint returns the Resolution API URL, or "" when no key is set.
+func (c *Checker) UDEndpoint() string {
+	if c.udKey == "" {
+		return ""
+	}
+	return c.udAPI
+}
+
+func (c *Checker) Check(domain string) (*Result, error) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	result := &Result{CheckedAt: time.Now()}
+
+	var errs []string
+	ens, err := c.checkENS(domain)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	result.ENS = ens
+
+	if c.udKey != "" {
+		ud, err := c.checkUD(domain, ens)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		result.UD = ud
+	}
+
+	result.Linked = (result.ENS != nil && len(result.ENS.Methods) > 0) ||
+		(result.UD != nil && result.UD.Owner != "")
+	result.Error = strings.Join(errs, "; ")
+	return result, nil
+}
+
+func (c *Checker) checkENS(domain string) (*ENSLink, error) {
+	link := &ENSLink{}
+
+	// Gasless DNSSEC names point ENS at an offchain resolver with
+	// "ENS1 <resolver> [data]" on the name itself.
+	txts, _ := c.lookupTXT(domain)
+	for _, txt := range txts {
+		fields := strings.Fields(txt)
+		if len(fields) >= 2 && fields[0] == "ENS1" {
+			link.OffchainResolver = fields[1]
+			link.Methods = append(link.Methods, MethodGasless)
+			break
+		}
+	}
+
+	// On-chain imports prove "a=<address>" at _ens.<domain> via DNSSEC.
+	txts, _ = c.lookupTXT("_ens." + domain)
+	for _, txt := range txts {
+		if addr, ok := strings.CutPrefix(strings.TrimSpace(txt), "a="); ok && strings.HasPrefix(addr, "0x") {
+			link.ClaimAddress = strings.ToLower(addr)
+			break
+		}
+	}
+
+	var err error
+	if c.rpc != nil {
+		link.RegistryOwner, link.RegistryResolver, err = c.rpc.ENSRecord(domain)
+		if err != nil {
+			err = fmt.Errorf("ENS registry lookup failed: %v", err)
+		}
+		if link.RegistryOwner != "" {
+			link.Methods = append(link.Methods, MethodOnchain)
+		}
+	}
+
+	if len(link.Methods) == 0 && link.ClaimAddress == "" {
+		return nil, err
+	}
+	return link, err
+}
+
+func (c *Checker) checkUD(domain string, ens *ENSLink) (*UDLink, error) {
+	link := &UDLink{}
+
+	var body struct {
+		Meta struct {
+			Owner    string `json:"owner"`
+			Resolver string `json:"resolver"`
+			Domain   string `json:"domain"`
+		} `json:"meta"`
+		Records map[string]string `json:"records"`
+	}
+	found, err := c.udGet("/domains/"+url.PathEscape(domain), &body)
+	if err != nil {
+		return nil, err
+	}
+	if found && body.Meta.Owner != "" && !isZeroAddress(body.Meta.Owner) {
+		link.Owner = body.Meta.Owner
+		link.Resolver = body.Meta.Resolver
+		link.Records = body.Records
+	}
+
+	if ens != nil {
+		address := ens.ClaimAddress
+		if address == "" {
+			address = ens.RegistryOwner
+		}
+		if address != "" {
+			body.Meta.Domain = ""
+			if found, err := c.udGet("/reverse/"+address, &body); err != nil {
+				return link, err
+			} else if found {
+				link.ReverseName = body.Meta.Domain
+			}
+		}
+	}
+
+	if link.Owner == "" && link.ReverseName == "" {
+		return nil, nil
+	}
+	return link, nil
+}
+
+func (c *Checker) udGet(path string, out interface{}) (bool, error) {
+	req, err := http.NewRequest(http.MethodGet, c.udAPI+path, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.udKey)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("Unstoppable Domains lookup failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("Unstoppable Domains lookup returned HTTP %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("invalid Unstoppable Domains response: %v", err)
+	}
+	return true, nil
+}
+
+func isZeroAddress(address string) bool {
+	return strings.Trim(strings.TrimPrefix(address, "0x"), "0") == ""
+}
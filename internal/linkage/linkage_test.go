@@ -0,0 +1,80 @@
+package linkage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func fakeTXT(records map[string][]string) func(string) ([]string, error) {
+	return func(name string) ([]string, error) {
+		return records[name], nil
+	}
+}
+
+func TestCheck_ENSGasless(t *testing.T) {
+	c := NewChecker()
+	c.lookupTXT = fakeTXT(map[string][]string{
+		"example.com":      {"v=spf1 -all", "ENS1 dnsname.ens.eth 0x1234"},
+		"_ens.example.com": {"a=0xABCDEF0000000000000000000000000000000001"},
+	})
+
+	result, err := c.Check("Example.com.")
+	if err != nil || result.Error != "" {
+		t.Fatalf("unexpected error: %v %s", err, result.Error)
+	}
+	if !result.Linked || result.ENS == nil {
+		t.Fatalf("expected an ENS link, got %+v", result)
+	}
+	if len(result.ENS.Methods) != 1 || result.ENS.Methods[0] != MethodGasless || result.ENS.OffchainResolver != "dnsname.ens.eth" {
+		t.Errorf("unexpected ENS link: %+v", result.ENS)
+	}
+	if result.ENS.ClaimAddress != "0xabcdef0000000000000000000000000000000001" {
+		t.Errorf("unexpected claim address %q", result.ENS.ClaimAddress)
+	}
+}
+
+func TestCheck_NotLinked(t *testing.T) {
+	c := NewChecker()
+	c.lookupTXT = fakeTXT(map[string][]string{"example.com": {"v=spf1 -all"}})
+
+	result, _ := c.Check("example.com")
+	if result.Linked || result.ENS != nil || result.UD != nil {
+		t.Errorf("expected no linkage, got %+v", result)
+	}
+}
+
+func TestCheck_UnstoppableDomains(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/domains/example.com":
+			w.Write([]byte(`{"meta": {"domain": "example.com", "owner": "0x00000000000000000000000000000000000000aa"},
+				"records": {"crypto.ETH.address": "0x00000000000000000000000000000000000000aa"}}`))
+		case "/reverse/0xabc":
+			w.Write([]byte(`{"meta": {"domain": "example.crypto"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := NewChecker()
+	c.udAPI = server.URL
+	c.SetUDKey("secret")
+	c.lookupTXT = fakeTXT(map[string][]string{"_ens.example.com": {"a=0xabc"}})
+
+	result, err := c.Check("example.com")
+	if err != nil || result.Error != "" {
+		t.Fatalf("unexpected error: %v %s", err, result.Error)
+	}
+	if !result.Linked || result.UD == nil || result.UD.Owner == "" || result.UD.ReverseName != "example.crypto" {
+		t.Errorf("unexpected UD link: %+v", result.UD)
+	}
+	if len(result.UD.Records) != 1 {
+		t.Errorf("expected UD records, got %v", result.UD.Records)
+	}
+}
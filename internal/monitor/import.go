@@ -0,0 +1,177 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/portfolio"
+	"d3-domain-tool/internal/schedule"
+)
+
+// Imported is a domain read from another service's watchlist, with the
+// schedule the list gave it, if it gave one this tool understands.
+type Imported struct {
+	Domain   string
+	Schedule string
+}
+
+// domainColumns and scheduleColumns are the CSV headers of the domain and
+// the check frequency in the exports of DNSWatch and of registrars'
+// domain monitoring, compared without case, spaces, dashes or underscores.
+var (
+	domainColumns   = []string{"domain", "domainname", "domains", "name", "hostname", "host", "fqdn"}
+	scheduleColumns = []string{"schedule", "interval", "checkinterval", "frequency", "checkfrequency"}
+)
+
+// frequencies are the words exports use for a check frequency.
+var frequencies = map[string]string{
+	"hourly":  "@hourly",
+	"daily":   "@daily",
+	"weekly":  "@weekly",
+	"monthly": "@monthly",
+}
+
+// LoadWatchlist reads the watchlist at source, a file or an http(s) URL,
+// with ReadWatchlist.
+func LoadWatchlist(source string) ([]Imported, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		client := &http.Client{Timeout: 30 * time.Second}
+		resp, err := client.Get(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch watchlist: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("watchlist %s returned HTTP %d", source, resp.StatusCode)
+		}
+		return ReadWatchlist(resp.Body)
+	}
+	f, err := os.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadWatchlist(f)
+}
+
+// ReadWatchlist reads the domains of a watchlist exported by another
+// monitoring service: a CSV whose header names a domain column, such as
+// DNSWatch's or a registrar's domain monitoring export, or a plain list
+// of one domain per line. Duplicates are dropped.
+func ReadWatchlist(r io.Reader) ([]Imported, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	var header string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if header = strings.TrimSpace(scanner.Text()); header != "" {
+			break
+		}
+	}
+	domainCol, scheduleCol, comma := columns(header)
+	if domainCol < 0 {
+		domains, err := portfolio.ReadDomains(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		list := make([]Imported, len(domains))
+		for i, d := range domains {
+			list[i] = Imported{Domain: d}
+		}
+		return list, nil
+	}
+
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading watchlist CSV: %v", err)
+	}
+	seen := map[string]bool{}
+	var list []Imported
+	for _, rec := range records[1:] {
+		if domainCol >= len(rec) {
+			continue
+		}
+		domain := analyzer.Canonicalize(rec[domainCol])
+		if domain == "" || !strings.Contains(domain, ".") || seen[domain] {
+			continue
+		}
+		seen[domain] = true
+		e := Imported{Domain: domain}
+		if scheduleCol >= 0 && scheduleCol < len(rec) {
+			e.Schedule = scheduleOf(rec[scheduleCol])
+		}
+		list = append(list, e)
+	}
+	return list, nil
+}
+
+// columns finds the domain and schedule columns of a CSV header line and
+// its delimiter. The domain column is -1 when the line is no such header.
+func columns(header string) (domainCol, scheduleCol int, comma rune) {
+	domainCol, scheduleCol, comma = -1, -1, ','
+	for _, c := range []rune{',', ';', '\t'} {
+		if strings.ContainsRune(header, c) {
+			comma = c
+			break
+		}
+	}
+	for i, name := range strings.Split(header, string(comma)) {
+		name = strings.NewReplacer(" ", "", "_", "", "-", "", `"`, "").Replace(strings.ToLower(name))
+		switch {
+		case domainCol < 0 && contains(domainColumns, name):
+			domainCol = i
+		case scheduleCol < 0 && contains(scheduleColumns, name):
+			scheduleCol = i
+		}
+	}
+	return domainCol, scheduleCol, comma
+}
+
+// scheduleOf converts a check frequency of an export into a schedule:
+// one this tool parses as is, a word such as "daily", or a duration such
+// as "15m" or "6 hours". It returns "" for anything else.
+func scheduleOf(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if s, ok := frequencies[value]; ok {
+		return s
+	}
+	if _, err := schedule.Parse(value); err == nil {
+		return value
+	}
+	fields := strings.Fields(value)
+	if len(fields) == 2 {
+		units := map[string]string{"minute": "m", "minutes": "m", "min": "m", "mins": "m", "hour": "h", "hours": "h"}
+		if u, ok := units[fields[1]]; ok {
+			value = fields[0] + u
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= time.Minute {
+		return "@every " + value
+	}
+	return ""
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package monitor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadWatchlist(t *testing.T) {
+	for name, tc := range map[string]struct {
+		input string
+		want  []Imported
+	}{
+		"dnswatch csv": {
+			"\ufeffDomain,Record Type,Check Interval\nExample.com,A,15 minutes\nexample.org,MX,daily\nexample.com,NS,1h\nnot a domain,A,\n",
+			[]Imported{{"example.com", "@every 15m"}, {"example.org", "@daily"}},
+		},
+		"registrar export": {
+			"\"Domain Name\";\"Expiration Date\";\"Frequency\"\n\"acme.io\";\"2027-01-01\";\"whenever\"\n\"acme.co\";\"2027-02-01\";\"*/30m\"\n",
+			[]Imported{{"acme.io", ""}, {"acme.co", "*/30m"}},
+		},
+		"plain list": {
+			"# portfolio\nexample.com\nhttps://www.example.net/\nexample.com\n",
+			[]Imported{{"example.com", ""}, {"example.net", ""}},
+		},
+	} {
+		got, err := ReadWatchlist(strings.NewReader(tc.input))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %+v, want %+v", name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Package monitor runs the watch checks of many domains, each on its own
+// schedule, for the long-running monitor command.
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	"d3-domain-tool/internal/schedule"
+	"d3-domain-tool/internal/watch"
+)
+
+// Poller checks domains and reports what changed since the last check;
+// *watch.Watcher is one.
+type Poller interface {
+	Poll(domains []string) *watch.Result
+}
+
+// Entry is a domain and when to check it.
+type Entry struct {
+	Domain   string
+	Schedule schedule.Schedule
+}
+
+// Monitor keeps the next run time of every domain.
+type Monitor struct {
+	poller Poller
+	jobs   []job
+	now    func() time.Time
+}
+
+type job struct {
+	domain   string
+	schedule schedule.Schedule
+	next     time.Time
+}
+
+// New returns a monitor for entries. Every domain is due at once, so the
+// first poll covers all of them.
+func New(poller Poller, entries []Entry) *Monitor {
+	m := &Monitor{poller: poller, now: time.Now}
+	for _, e := range entries {
+		m.jobs = append(m.jobs, job{domain: e.Domain, schedule: e.Schedule})
+	}
+	return m
+}
+
+// Next returns when the next domain is due.
+func (m *Monitor) Next() time.Time {
+	var next time.Time
+	for _, j := range m.jobs {
+		if next.IsZero() || j.next.Before(next) {
+			next = j.next
+		}
+	}
+	return next
+}
+
+// Poll checks the domains that are due, in one poll, and schedules their
+// next run. It returns nil when none is due.
+func (m *Monitor) Poll() *watch.Result {
+	now := m.now()
+	var due []string
+	for i := range m.jobs {
+		if j := &m.jobs[i]; !j.next.After(now) {
+			due = append(due, j.domain)
+			j.next = j.schedule.Next(now)
+		}
+	}
+	if len(due) == 0 {
+		return nil
+	}
+	return m.poller.Poll(due)
+}
+
+// Run polls each domain on its schedule and passes every poll to handle
+// until ctx is done.
+func (m *Monitor) Run(ctx context.Context, handle func(*watch.Result)) error {
+	if len(m.jobs) == 0 {
+		return nil
+	}
+	for {
+		if result := m.Poll(); result != nil {
+			handle(result)
+		}
+		timer := time.NewTimer(time.Until(m.Next()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// LoadState reads the states saved with SaveState. A missing file is an
+// empty state.
+func LoadState(path string) ([]watch.State, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var states []watch.State
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// SaveState writes the last known states so a restarted monitor reports
+// what changed while it was down instead of starting a new baseline.
+func SaveState(path string, states []watch.State) error {
+	sort.Slice(states, func(i, j int) bool { return states[i].Domain < states[j].Domain })
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/schedule"
+	"d3-domain-tool/internal/watch"
+)
+
+type fakePoller struct {
+	polls [][]string
+}
+
+func (p *fakePoller) Poll(domains []string) *watch.Result {
+	p.polls = append(p.polls, domains)
+	return &watch.Result{Poll: len(p.polls)}
+}
+
+func TestPoll(t *testing.T) {
+	daily, _ := schedule.Parse("@daily")
+	quarter, _ := schedule.Parse("*/15m")
+	poller := &fakePoller{}
+	m := New(poller, []Entry{{"a.com", daily}, {"b.com", quarter}})
+	now := time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	m.Poll()
+	if want := now.Add(15 * time.Minute); !m.Next().Equal(want) {
+		t.Errorf("next = %v, want %v", m.Next(), want)
+	}
+	now = now.Add(5 * time.Minute)
+	if m.Poll() != nil {
+		t.Error("polled before anything was due")
+	}
+	now = now.Add(10 * time.Minute)
+	m.Poll()
+	now = time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	m.Poll()
+
+	want := [][]string{{"a.com", "b.com"}, {"b.com"}, {"a.com", "b.com"}}
+	if !reflect.DeepEqual(poller.polls, want) {
+		t.Errorf("polls = %v, want %v", poller.polls, want)
+	}
+}
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if states, err := LoadState(path); err != nil || states != nil {
+		t.Fatalf("missing state: %v, %v", states, err)
+	}
+	saved := []watch.State{
+		{Domain: "b.com", Availability: "taken", Tokenization: "unknown"},
+		{Domain: "a.com", Availability: "available", Tokenization: "unknown"},
+	}
+	if err := SaveState(path, saved); err != nil {
+		t.Fatal(err)
+	}
+	states, err := LoadState(path)
+	if err != nil || len(states) != 2 || states[0].Domain != "a.com" || states[1].Availability != "taken" {
+		t.Errorf("states = %+v, %v", states, err)
+	}
+}
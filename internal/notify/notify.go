@@ -0,0 +1,155 @@
+// Package notify POSTs the analysis of a watched domain to webhooks when
+// its availability, expiry or tokenization changes. Each body is signed
+// with HMAC-SHA256, so receivers such as Zapier, n8n or internal alerting
+// can check it came from this tool.
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/watch"
+)
+
+// Headers of each notification.
+const (
+	// SignatureHeader holds "sha256=" and the hex HMAC-SHA256 of the body
+	// keyed with the secret.
+	SignatureHeader = "X-D3-Signature"
+	// EventHeader holds the event name, e.g. EventChange.
+	EventHeader = "X-D3-Event"
+)
+
+// EventChange is the event of a domain whose watched state changed.
+const EventChange = "domain.changed"
+
+// attempts is how often a notification is POSTed to a webhook before it
+// is given up on.
+const attempts = 3
+
+// Event is the body POSTed to each webhook.
+type Event struct {
+	Event   string         `json:"event"`
+	Domain  string         `json:"domain"`
+	Changes []watch.Change `json:"changes"`
+	// Dropped is set when the domain went from taken to available.
+	Dropped bool             `json:"dropped,omitempty"`
+	Result  *analyzer.Result `json:"result"`
+	SentAt  time.Time        `json:"sent_at"`
+}
+
+// Notifier sends the events of watch polls to webhooks.
+type Notifier struct {
+	urls       []string
+	secret     []byte
+	client     *http.Client
+	now        func() time.Time
+	retryDelay time.Duration
+}
+
+// New returns a notifier POSTing to urls, signing with secret. An empty
+// secret sends unsigned notifications.
+func New(urls []string, secret string) (*Notifier, error) {
+	for _, u := range urls {
+		if p, err := url.Parse(u); err != nil || (p.Scheme != "http" && p.Scheme != "https") || p.Host == "" {
+			return nil, fmt.Errorf("invalid webhook URL %q: must be an absolute http or https URL", u)
+		}
+	}
+	return &Notifier{
+		urls:       urls,
+		secret:     []byte(secret),
+		client:     &http.Client{Timeout: 10 * time.Second},
+		now:        time.Now,
+		retryDelay: time.Second,
+	}, nil
+}
+
+// Notify sends an event for each domain that changed in result to every
+// webhook. A domain without an analysis in result is skipped.
+func (n *Notifier) Notify(result *watch.Result) error {
+	var events []Event
+	index := map[string]int{}
+	for _, c := range result.Changes {
+		i, ok := index[c.Domain]
+		if !ok {
+			r := result.Analysis(c.Domain)
+			if r == nil {
+				continue
+			}
+			i = len(events)
+			index[c.Domain] = i
+			events = append(events, Event{Event: EventChange, Domain: c.Domain, Result: r})
+		}
+		events[i].Changes = append(events[i].Changes, c)
+		events[i].Dropped = events[i].Dropped || c.Dropped
+	}
+
+	var errs []error
+	for _, e := range events {
+		e.SentAt = n.now().UTC()
+		body, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		for _, u := range n.urls {
+			if err := n.deliver(u, e.Event, body); err != nil {
+				errs = append(errs, fmt.Errorf("notify %s: %s: %v", u, e.Domain, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliver POSTs body to webhook, retrying failed attempts.
+func (n *Notifier) deliver(webhook, event string, body []byte) error {
+	for attempt := 1; ; attempt++ {
+		err := n.post(webhook, event, body)
+		if err == nil || attempt == attempts {
+			return err
+		}
+		time.Sleep(time.Duration(attempt) * n.retryDelay)
+	}
+}
+
+func (n *Notifier) post(webhook, event string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	if len(n.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(n.secret, body))
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the SignatureHeader value of body.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the SignatureHeader value of body,
+// comparing in constant time.
+func Verify(secret, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
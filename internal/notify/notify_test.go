@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/watch"
+	"d3-domain-tool/internal/whois"
+)
+
+func TestNotify(t *testing.T) {
+	var mu sync.Mutex
+	var events []Event
+	failures := 1
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !Verify([]byte("s3cret"), body, r.Header.Get(SignatureHeader)) {
+			t.Errorf("bad signature %q", r.Header.Get(SignatureHeader))
+		}
+		if r.Header.Get(EventHeader) != EventChange {
+			t.Errorf("%s = %q", EventHeader, r.Header.Get(EventHeader))
+		}
+		var e Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			t.Error(err)
+		}
+		events = append(events, e)
+	}))
+	defer ts.Close()
+
+	n, err := New([]string{ts.URL}, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.retryDelay = time.Millisecond
+
+	// a.com is dropped; b.com's analysis failed, so it has nothing to send.
+	available := false
+	w := watch.NewWatcherFunc(func(domain string) (*analyzer.Result, error) {
+		if domain == "b.com" {
+			return nil, errors.New("timeout")
+		}
+		return &analyzer.Result{Domain: domain, WhoisData: &whois.Result{Available: available}}, nil
+	})
+	w.Poll([]string{"a.com", "b.com"})
+	available = true
+	result := w.Poll([]string{"a.com", "b.com"})
+	result.Changes = append(result.Changes, watch.Change{Domain: "b.com", Field: watch.FieldExpiry})
+
+	if err := n.Notify(result); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1: %+v", len(events), events)
+	}
+	e := events[0]
+	if e.Domain != "a.com" || !e.Dropped || len(e.Changes) != 1 || e.Result == nil || e.Result.Domain != "a.com" {
+		t.Errorf("unexpected event %+v", e)
+	}
+}
+
+func TestNewRejectsURLs(t *testing.T) {
+	for _, u := range []string{"example.com/hook", "ftp://example.com/", "http://"} {
+		if _, err := New([]string{u}, ""); err == nil {
+			t.Errorf("New(%q) succeeded", u)
+		}
+	}
+}
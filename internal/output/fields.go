@@ -0,0 +1,234 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"d3-domain-tool/internal/analyzer"
+)
+
+// fieldAliases are short names for the top-level report sections.
+var fieldAliases = map[string]string{
+	"dns":        "dns_availability",
+	"blockchain": "blockchain_data",
+	"doma":       "doma_data",
+	"whois":      "whois_data",
+	"valuation":  "valuation_data",
+}
+
+// DefaultCSVFields are the columns written by -format=csv without -fields.
+var DefaultCSVFields = []string{
+	"domain", "verdict", "whois.registrar", "whois.expiry_date",
+	"valuation.estimated_value", "valuation.cost_ratio", "doma.is_tokenized",
+}
+
+// Field is one selected value of a result.
+type Field struct {
+	Name  string
+	Value interface{}
+}
+
+// ParseFields splits a -fields value into dotted paths.
+func ParseFields(spec string) []string {
+	var fields []string
+	for _, f := range strings.Split(spec, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// SelectFields resolves dotted JSON paths against a result. The first
+// segment may use a section alias (whois, valuation, ...) and "verdict"
+// selects the overall availability. Missing values are nil.
+func SelectFields(result *analyzer.Result, paths []string) ([]Field, error) {
+	tree, err := resultTree(result)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]Field, 0, len(paths))
+	for _, path := range paths {
+		var node interface{} = tree
+		for i, key := range strings.Split(path, ".") {
+			if i == 0 {
+				if alias, ok := fieldAliases[key]; ok {
+					key = alias
+				}
+			}
+			m, ok := node.(map[string]interface{})
+			if !ok {
+				node = nil
+				break
+			}
+			node = m[key]
+		}
+		fields = append(fields, Field{Name: path, Value: node})
+	}
+	return fields, nil
+}
+
+// resultTree returns the result as generic JSON with the verdict added.
+func resultTree(result *analyzer.Result) (map[string]interface{}, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	tree["verdict"] = result.Verdict()
+	if usage := result.Usage(); usage != "" {
+		tree["usage"] = usage
+	}
+	return tree, nil
+}
+
+// fieldsJSON encodes selected fields as an object that keeps the
+// requested order.
+func fieldsJSON(fields []Field) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		name, _ := json.Marshal(f.Name)
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteString(":")
+		buf.Write(value)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+// fieldText renders a value for table and CSV cells.
+func fieldText(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = fieldText(item)
+		}
+		return strings.Join(parts, " ")
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// Payload returns what is printed for a result: the selected fields when
+// -fields is set, otherwise the full result, then the -query result.
+func (f *Formatter) Payload(result *analyzer.Result) (interface{}, error) {
+	var payload interface{} = result
+	if len(f.fields) > 0 {
+		fields, err := SelectFields(result, f.fields)
+		if err != nil {
+			return nil, err
+		}
+		if payload, err = fieldsJSON(fields); err != nil {
+			return nil, err
+		}
+	}
+	if f.query == nil {
+		return payload, nil
+	}
+	if len(f.fields) == 0 {
+		// Let queries use "verdict" like -fields does.
+		tree, err := resultTree(result)
+		if err != nil {
+			return nil, err
+		}
+		payload = tree
+	}
+	v, err := f.query.Search(payload)
+	if err != nil {
+		return nil, fmt.Errorf("query %q: %v", f.query, err)
+	}
+	return v, nil
+}
+
+func (f *Formatter) displayQuery(result *analyzer.Result) error {
+	v, err := f.Payload(result)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(f.out)
+	if f.format != "jsonl" {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(v)
+}
+
+func (f *Formatter) displayFields(result *analyzer.Result) error {
+	paths := f.fields
+	if len(paths) == 0 {
+		paths = DefaultCSVFields
+	}
+	fields, err := SelectFields(result, paths)
+	if err != nil {
+		return err
+	}
+
+	switch f.format {
+	case "json":
+		data, err := fieldsJSON(fields)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		json.Indent(&buf, data, "", "  ")
+		buf.WriteString("\n")
+		_, err = buf.WriteTo(f.out)
+		return err
+	case "jsonl":
+		data, err := fieldsJSON(fields)
+		if err != nil {
+			return err
+		}
+		_, err = f.out.Write(append(data, '\n'))
+		return err
+	case "csv":
+		var head bytes.Buffer
+		hw := csv.NewWriter(&head)
+		hw.Write(paths)
+		hw.Flush()
+		f.preamble = head.Bytes()
+		w := csv.NewWriter(f.out)
+		row := make([]string, len(fields))
+		for i, field := range fields {
+			row[i] = fieldText(field.Value)
+		}
+		w.Write(row)
+		w.Flush()
+		return w.Error()
+	case "table":
+		w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+		for _, field := range fields {
+			fmt.Fprintf(w, "%s:\t%s\n", field.Name, fieldText(field.Value))
+		}
+		fmt.Fprintf(w, "\n")
+		return w.Flush()
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
@@ -0,0 +1,92 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/valuation"
+	"d3-domain-tool/internal/whois"
+)
+
+func TestSelectFields(t *testing.T) {
+	expiry := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	result := &analyzer.Result{
+		Domain:        "example.com",
+		WhoisData:     &whois.Result{Registrar: "Example Registrar", ExpiryDate: &expiry},
+		ValuationData: &valuation.Result{EstimatedValue: 1500000},
+	}
+
+	fields, err := SelectFields(result, []string{"domain", "verdict", "whois.expiry_date", "valuation.estimated_value", "whois.missing.deeper"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"example.com", "taken", "2030-01-02T00:00:00Z", "1500000", ""}
+	for i, f := range fields {
+		if got := fieldText(f.Value); got != want[i] {
+			t.Errorf("%s = %q, want %q", f.Name, got, want[i])
+		}
+	}
+
+	data, err := fieldsJSON(fields[:2])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != `{"domain":"example.com","verdict":"taken"}` {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+	if !json.Valid(data) {
+		t.Error("expected valid JSON")
+	}
+}
+
+func TestGridCell(t *testing.T) {
+	tests := []struct {
+		field Field
+		want  string
+	}{
+		{Field{"valuation.estimated_value", 1234567.0}, "$1,234,567"},
+		{Field{"valuation.estimated_value", 999.0}, "$999"},
+		{Field{"whois.expiry_date", "2030-01-02T00:00:00Z"}, "2030-01-02"},
+		{Field{"doma.is_tokenized", true}, "yes"},
+		{Field{"whois.registrar", nil}, ""},
+	}
+	for _, tt := range tests {
+		if got := gridCell(tt.field); got != tt.want {
+			t.Errorf("gridCell(%v) = %q, want %q", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFormatter("jsonl")
+	f.SetOutput(&buf)
+	for _, d := range []string{"a.com", "b.com"} {
+		if err := f.Display(&analyzer.Result{Domain: d}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.SetFields([]string{"domain", "verdict"})
+	if err := f.Display(&analyzer.Result{Domain: "c.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected one line per result:\n%s", buf.String())
+	}
+	for i, line := range lines[:2] {
+		var r analyzer.Result
+		if err := json.Unmarshal([]byte(line), &r); err != nil || r.Domain != string(rune('a'+i))+".com" {
+			t.Errorf("line %d = %s (%v)", i, line, err)
+		}
+	}
+	if lines[2] != `{"domain":"c.com","verdict":"unknown"}` {
+		t.Errorf("fields line = %s", lines[2])
+	}
+}
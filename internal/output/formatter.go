@@ -1,44 +1,180 @@
 package output
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
+	"sync"
 	"text/tabwriter"
+	"time"
 
 	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/audit"
+	"d3-domain-tool/internal/blockchain"
+	"d3-domain-tool/internal/brand"
+	"d3-domain-tool/internal/cloud"
+	"d3-domain-tool/internal/collateral"
+	"d3-domain-tool/internal/compare"
+	"d3-domain-tool/internal/dnsaudit"
+	"d3-domain-tool/internal/doma"
+	"d3-domain-tool/internal/findings"
+	"d3-domain-tool/internal/history"
+	"d3-domain-tool/internal/iac"
+	"d3-domain-tool/internal/identity"
+	"d3-domain-tool/internal/ipv6"
+	"d3-domain-tool/internal/policy"
+	"d3-domain-tool/internal/portfolio"
+	"d3-domain-tool/internal/provider"
+	"d3-domain-tool/internal/query"
+	"d3-domain-tool/internal/rawarchive"
+	"d3-domain-tool/internal/renewal"
+	"d3-domain-tool/internal/signing"
+	"d3-domain-tool/internal/subdomains"
+	"d3-domain-tool/internal/suggest"
+	"d3-domain-tool/internal/valuation"
+	"d3-domain-tool/internal/version"
+	"d3-domain-tool/internal/watch"
 )
 
+// Formatter renders reports in one output format. It is safe for
+// concurrent use: each report is rendered into its own buffer and written
+// to the output whole, so reports never interleave.
 type Formatter struct {
 	format string
+	fields []string
+	query  *query.Expression
+	w      *writer
+
+	// out and preamble are set on the copy that renders a report: out is
+	// its buffer, and preamble is what the output must start with before
+	// the first report, such as the CSV header or the HTML head.
+	out      io.Writer
+	preamble []byte
+}
+
+// writer serializes the reports of a Formatter onto its output.
+type writer struct {
+	mu sync.Mutex
+	// out is nil for stdout, looked up at each write so output follows
+	// os.Stdout when it is redirected, e.g. into a pager.
+	out   io.Writer
+	begun bool
+}
+
+// output returns where to write; the caller holds mu.
+func (w *writer) output() io.Writer {
+	if w.out == nil {
+		return os.Stdout
+	}
+	return w.out
 }
 
 func NewFormatter(format string) *Formatter {
 	return &Formatter{
 		format: format,
+		w:      &writer{},
+	}
+}
+
+// SetOutput writes reports to w instead of stdout.
+func (f *Formatter) SetOutput(w io.Writer) {
+	f.w.mu.Lock()
+	defer f.w.mu.Unlock()
+	f.w.out = w
+}
+
+// Render returns result rendered as Display would write it to a fresh
+// output, without writing it.
+func (f *Formatter) Render(result *analyzer.Result) ([]byte, error) {
+	c, data, err := f.render(func(c *Formatter) error { return c.display(result) })
+	return append(c.preamble, data...), err
+}
+
+// render runs fn on a copy of f that writes into a buffer, so any number
+// of reports can be rendered at once.
+func (f *Formatter) render(fn func(c *Formatter) error) (*Formatter, []byte, error) {
+	var buf bytes.Buffer
+	c := &Formatter{format: f.format, fields: f.fields, query: f.query, out: &buf}
+	err := fn(c)
+	return c, buf.Bytes(), err
+}
+
+// emit renders a report and writes it to the output in a single write,
+// after the preamble if it is the first. What was rendered is written
+// even when rendering fails part way, as a report written directly would
+// have been.
+func (f *Formatter) emit(fn func(c *Formatter) error) error {
+	c, data, err := f.render(fn)
+	f.w.mu.Lock()
+	defer f.w.mu.Unlock()
+	if !f.w.begun && len(c.preamble) > 0 {
+		data = append(c.preamble, data...)
+		f.w.begun = true
+	}
+	if len(data) > 0 {
+		if _, werr := f.w.output().Write(data); err == nil {
+			err = werr
+		}
 	}
+	return err
+}
+
+// SetFields restricts report output to the given dotted JSON paths
+// (see SelectFields).
+func (f *Formatter) SetFields(fields []string) {
+	f.fields = fields
+}
+
+// SetQuery sets a JMESPath expression that is evaluated against each
+// report; its result is printed as JSON instead of the report.
+func (f *Formatter) SetQuery(expr *query.Expression) {
+	f.query = expr
 }
 
 func (f *Formatter) Display(result *analyzer.Result) error {
+	return f.emit(func(c *Formatter) error { return c.display(result) })
+}
+
+func (f *Formatter) display(result *analyzer.Result) error {
+	if f.query != nil {
+		return f.displayQuery(result)
+	}
+	if len(f.fields) > 0 || f.format == "csv" {
+		return f.displayFields(result)
+	}
 	switch f.format {
 	case "json":
 		return f.displayJSON(result)
+	case "jsonl":
+		return json.NewEncoder(f.out).Encode(result)
 	case "table":
 		return f.displayTable(result)
+	case "html":
+		return f.htmlSection(func() error { return f.displayTable(result) })
+	case "stix":
+		return f.displaySTIX(result)
+	case "cef":
+		return f.displayCEF(result)
+	case "leef":
+		return f.displayLEEF(result)
 	default:
 		return fmt.Errorf("unsupported format: %s", f.format)
 	}
 }
 
 func (f *Formatter) displayJSON(result *analyzer.Result) error {
-	encoder := json.NewEncoder(os.Stdout)
+	encoder := json.NewEncoder(f.out)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(result)
 }
 
 func (f *Formatter) displayTable(result *analyzer.Result) error {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
 
 	// Header
 	fmt.Fprintf(w, "\n🔍 D3 DOMAIN ANALYSIS REPORT\n")
@@ -46,7 +182,60 @@ func (f *Formatter) displayTable(result *analyzer.Result) error {
 
 	// Basic Info
 	fmt.Fprintf(w, "Domain:\t%s\n", result.Domain)
-	fmt.Fprintf(w, "Analyzed:\t%s\n\n", result.Timestamp.Format("2006-01-02 15:04:05 MST"))
+	if result.Input != "" {
+		fmt.Fprintf(w, "Input:\t%s\n", result.Input)
+	}
+	fmt.Fprintf(w, "Analyzed:\t%s\n", result.Timestamp.Format("2006-01-02 15:04:05 MST"))
+	if result.Degraded() {
+		fmt.Fprintf(w, "Result:\t⚠️ Degraded (%d check(s) failed)\n", len(result.SectionErrors))
+		for _, se := range result.SectionErrors {
+			fmt.Fprintf(w, "  %s:\t%s\n", se.Section, se.Error)
+		}
+	}
+	if len(result.Warnings) > 0 {
+		fmt.Fprintf(w, "Warnings:\t%d data quality issue(s)\n", len(result.Warnings))
+		for _, sw := range result.Warnings {
+			fmt.Fprintf(w, "  %s:\t%s\n", sw.Section, sw.Warning)
+		}
+	}
+	// Failed checks and warnings are listed above; the other findings
+	// follow by severity.
+	if issues := findings.AtLeast(result.Findings, findings.Low); len(issues) > 0 {
+		sort.SliceStable(issues, func(i, j int) bool { return issues[i].Severity > issues[j].Severity })
+		fmt.Fprintf(w, "Findings:\t%d issue(s), highest %s\n", len(issues), issues[0].Severity)
+		for _, fd := range issues {
+			line := fd.Name
+			if fd.Detail != "" {
+				line += ": " + fd.Detail
+			}
+			fmt.Fprintf(w, "  [%s] %s:\t%s\n", fd.Severity, fd.Module, line)
+		}
+	}
+	if len(result.Suppressed) > 0 {
+		fmt.Fprintf(w, "Suppressed:\t%d finding(s) accepted by the baseline\n", len(result.Suppressed))
+	}
+	if len(result.SkippedChecks) > 0 {
+		fmt.Fprintf(w, "Skipped:\t%d check(s) not needed\n", len(result.SkippedChecks))
+		for _, sc := range result.SkippedChecks {
+			fmt.Fprintf(w, "  %s:\t%s\n", sc.Module, sc.Reason)
+		}
+	}
+	if len(result.Freshness) > 0 {
+		cached := 0
+		for _, fr := range result.Freshness {
+			if fr.Source == analyzer.SourceCache {
+				cached++
+			}
+		}
+		fmt.Fprintf(w, "Freshness:\t%d live, %d from cache\n", len(result.Freshness)-cached, cached)
+		for _, fr := range result.Freshness {
+			if fr.Source == analyzer.SourceCache {
+				fmt.Fprintf(w, "  %s:\tcached %s ago (checked %s)\n", fr.Section,
+					ago(fr.Age(result.Timestamp)), fr.CheckedAt.Format("2006-01-02 15:04 MST"))
+			}
+		}
+	}
+	fmt.Fprintf(w, "\n")
 
 	// DNS Availability Section
 	if result.DNSAvailability != nil {
@@ -67,6 +256,37 @@ func (f *Formatter) displayTable(result *analyzer.Result) error {
 		if result.DNSAvailability.Error != "" {
 			fmt.Fprintf(w, "Error:\t%s\n", result.DNSAvailability.Error)
 		}
+
+		if len(result.DNSAvailability.Answers) > 0 {
+			fmt.Fprintf(w, "\nAnswers:\n")
+			for _, rec := range result.DNSAvailability.Answers {
+				value := rec.Value
+				if rec.Type == "MX" {
+					value = fmt.Sprintf("%d %s", rec.Priority, rec.Value)
+				}
+				fmt.Fprintf(w, "  %s\t%s\t(TTL %ds)\n", rec.Type, value, rec.TTL)
+			}
+		}
+
+		if report := result.DNSAvailability.TTLReport; report != nil {
+			fmt.Fprintf(w, "\nChange Readiness:\n")
+			for _, rec := range report.Records {
+				flag := ""
+				if rec.Flag != "" {
+					flag = fmt.Sprintf(" ⚠️ %s", rec.Flag)
+				}
+				fmt.Fprintf(w, "  %s TTL:\t%ds%s\n", rec.Type, rec.TTL, flag)
+			}
+			readyIcon := "❌"
+			if report.Ready {
+				readyIcon = "✅"
+			}
+			fmt.Fprintf(w, "  Ready to Cut Over:\t%s\n", readyIcon)
+			fmt.Fprintf(w, "  Safe Cutover Window:\t%s\n", report.SafeCutoverWindow)
+			for _, rec := range report.Recommendations {
+				fmt.Fprintf(w, "  •\t%s\n", rec)
+			}
+		}
 		fmt.Fprintf(w, "\n")
 	}
 
@@ -75,13 +295,17 @@ func (f *Formatter) displayTable(result *analyzer.Result) error {
 		fmt.Fprintf(w, "🔶 DOMA PROTOCOL INTEGRATION\n")
 		fmt.Fprintf(w, "───────────────────────────\n")
 
-		tokenizedIcon := "❌"
-		if result.DomaData.IsTokenized {
+		tokenizedIcon := "❔ Unknown"
+		switch result.DomaData.Status {
+		case doma.StatusTokenized:
 			tokenizedIcon = "✅"
+		case doma.StatusNotTokenized:
+			tokenizedIcon = "❌"
 		}
 		fmt.Fprintf(w, "Tokenized:\t%s\n", tokenizedIcon)
 
-		if result.DomaData.IsTokenized {
+		switch result.DomaData.Status {
+		case doma.StatusTokenized:
 			if result.DomaData.TokenizationChain != "" {
 				fmt.Fprintf(w, "Chain:\t%s\n", result.DomaData.TokenizationChain)
 			}
@@ -91,6 +315,7 @@ func (f *Formatter) displayTable(result *analyzer.Result) error {
 				record := result.DomaData.DomaRecord
 				fmt.Fprintf(w, "Token ID:\t%s\n", record.TokenId)
 				fmt.Fprintf(w, "Owner:\t%s\n", record.Owner)
+				displayOwnerInfo(w, record.OwnerInfo)
 
 				if record.RegistrationDate != nil {
 					fmt.Fprintf(w, "Registered:\t%s\n", record.RegistrationDate.Format("2006-01-02"))
@@ -103,6 +328,10 @@ func (f *Formatter) displayTable(result *analyzer.Result) error {
 				fmt.Fprintf(w, "Sync Status:\t%s\n", record.SyncStatus)
 			}
 
+			for _, link := range result.DomaData.Links {
+				fmt.Fprintf(w, "Explorer (%s):\t%s\n", link.Label, link.URL)
+			}
+
 			// Token Rights Information
 			if result.DomaData.TokenRights != nil {
 				rights := result.DomaData.TokenRights
@@ -147,14 +376,18 @@ func (f *Formatter) displayTable(result *analyzer.Result) error {
 			// Cross-Chain Data
 			if len(result.DomaData.CrossChainData) > 0 {
 				fmt.Fprintf(w, "\n🌐 Cross-Chain Presence:\n")
-				for chain := range result.DomaData.CrossChainData {
+				for _, chain := range sortedKeys(result.DomaData.CrossChainData) {
 					fmt.Fprintf(w, "  %s:\t✅ Deployed\n", strings.Title(chain))
 				}
 			}
-		} else {
+		case doma.StatusNotTokenized:
 			// Check eligibility for non-tokenized domains
 			fmt.Fprintf(w, "Eligibility:\t⚠️ Not currently tokenized\n")
 			fmt.Fprintf(w, "Note:\tTraditional domains can be tokenized via DOMA Protocol\n")
+		default:
+			if result.DomaData.Note != "" {
+				fmt.Fprintf(w, "Note:\t%s\n", result.DomaData.Note)
+			}
 		}
 
 		if result.DomaData.Error != "" {
@@ -163,36 +396,155 @@ func (f *Formatter) displayTable(result *analyzer.Result) error {
 		fmt.Fprintf(w, "\n")
 	}
 
+	// GeoDNS Section
+	if geo := result.GeoDNS; geo != nil {
+		fmt.Fprintf(w, "🌍 GEODNS / SPLIT-HORIZON\n")
+		fmt.Fprintf(w, "─────────────────────────\n")
+
+		geoIcon := "❌ No"
+		if geo.GeoDNS {
+			geoIcon = "✅ Yes"
+		}
+		fmt.Fprintf(w, "Location-Dependent:\t%s (%d distinct answer set(s))\n", geoIcon, geo.DistinctAnswers)
+		for _, v := range geo.Vantages {
+			from := v.Resolver
+			if v.Subnet != "" {
+				from = v.Subnet + " via " + v.Resolver
+			}
+			answer := strings.Join(v.Addresses, ", ")
+			if v.Error != "" {
+				answer = "error: " + v.Error
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", v.Name, from, answer)
+		}
+		for _, note := range geo.Notes {
+			fmt.Fprintf(w, "•\t%s\n", note)
+		}
+		if geo.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", geo.Error)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// Alternative Root Section
+	if alt := result.AltRoot; alt != nil {
+		fmt.Fprintf(w, "🧭 ALTERNATIVE ROOT (%s)\n", strings.ToUpper(alt.Namespace))
+		fmt.Fprintf(w, "──────────────────────────────\n")
+
+		switch {
+		case alt.Registered:
+			fmt.Fprintf(w, "Status:\t❌ Taken\n")
+		case alt.Available:
+			fmt.Fprintf(w, "Status:\t✅ Available\n")
+		default:
+			fmt.Fprintf(w, "Status:\t❓ Unknown\n")
+		}
+		fmt.Fprintf(w, "Namespace:\t%s (alt-root, not in the ICANN root)\n", alt.Namespace)
+		if alt.Resolver != "" {
+			fmt.Fprintf(w, "Resolver:\t%s\n", alt.Resolver)
+		}
+		for _, rec := range alt.Records {
+			fmt.Fprintf(w, "  %s\t%s\n", rec.Type, rec.Value)
+		}
+		fmt.Fprintf(w, "⚠️ Caveat:\t%s\n", alt.Caveat)
+		if alt.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", alt.Error)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
 	// Blockchain Section
 	if result.BlockchainData != nil {
 		fmt.Fprintf(w, "⛓️ BLOCKCHAIN DATA\n")
 		fmt.Fprintf(w, "──────────────────\n")
 
-		status := "❌ Taken"
-		if result.BlockchainData.Available {
-			status = "✅ Available"
+		status := "❔ Unknown"
+		if available := result.BlockchainData.Available; available != nil {
+			status = "❌ Taken"
+			if *available {
+				status = "✅ Available"
+			}
 		}
 		fmt.Fprintf(w, "Status:\t%s\n", status)
+		if result.BlockchainData.Note != "" {
+			fmt.Fprintf(w, "Note:\t%s\n", result.BlockchainData.Note)
+		}
 		fmt.Fprintf(w, "Type:\t%s\n", result.BlockchainData.Type)
 
 		if result.BlockchainData.Owner != "" {
 			fmt.Fprintf(w, "Owner:\t%s\n", result.BlockchainData.Owner)
+			displayOwnerInfo(w, result.BlockchainData.OwnerInfo)
 		}
 
 		if result.BlockchainData.Resolver != "" {
 			fmt.Fprintf(w, "Resolver:\t%s\n", result.BlockchainData.Resolver)
 		}
+		if result.BlockchainData.RPCEndpoint != "" {
+			fmt.Fprintf(w, "RPC Endpoint:\t%s\n", result.BlockchainData.RPCEndpoint)
+		}
 
-		if len(result.BlockchainData.Records) > 0 {
+		if profile := result.BlockchainData.Profile; profile != nil {
+			displayUDProfile(w, profile)
+		} else if len(result.BlockchainData.Records) > 0 {
 			fmt.Fprintf(w, "Records:\n")
-			for key, value := range result.BlockchainData.Records {
-				fmt.Fprintf(w, "  %s:\t%s\n", key, value)
+			for _, key := range sortedKeys(result.BlockchainData.Records) {
+				fmt.Fprintf(w, "  %s:\t%s\n", key, result.BlockchainData.Records[key])
 			}
 		}
 
 		if result.BlockchainData.ExpiryDate != nil {
 			fmt.Fprintf(w, "Expires:\t%s\n", result.BlockchainData.ExpiryDate.Format("2006-01-02"))
 		}
+
+		for _, link := range result.BlockchainData.Links {
+			fmt.Fprintf(w, "Explorer (%s):\t%s\n", link.Label, link.URL)
+		}
+
+		if token := result.BlockchainData.Token; token != nil {
+			fmt.Fprintf(w, "Token:\t%s on %s\n", token.Standard, token.Chain)
+			fmt.Fprintf(w, "  Contract:\t%s\n", token.Contract)
+			fmt.Fprintf(w, "  Token ID:\t%s\n", token.TokenID)
+			fmt.Fprintf(w, "  Metadata:\t%s\n", token.MetadataURI)
+			if token.Image != "" {
+				imageIcon := "✅"
+				if !token.ImageOK {
+					imageIcon = "❌ " + token.ImageError
+				}
+				image := token.Image
+				if strings.HasPrefix(image, "data:") && len(image) > 40 {
+					image = image[:40] + "…"
+				}
+				fmt.Fprintf(w, "  Image:\t%s %s\n", image, imageIcon)
+			}
+			for _, key := range sortedKeys(token.Attributes) {
+				fmt.Fprintf(w, "  %s:\t%s\n", key, token.Attributes[key])
+			}
+			for _, link := range token.Links {
+				fmt.Fprintf(w, "  Explorer (%s):\t%s\n", link.Label, link.URL)
+			}
+		}
+
+		if result.BlockchainData.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", result.BlockchainData.Error)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// RDAP stands in for WHOIS when it confirmed an NXDOMAIN is unregistered
+	if result.RDAP != nil && result.WhoisData == nil {
+		fmt.Fprintf(w, "📋 RDAP\n")
+		fmt.Fprintf(w, "───────\n")
+		status := "✅ Not registered"
+		if result.RDAP.Registered {
+			status = "❌ Registered"
+		}
+		if result.RDAP.Error != "" {
+			status = "⚠️ " + result.RDAP.Error
+		}
+		fmt.Fprintf(w, "Status:\t%s\n", status)
+		if result.RDAP.Server != "" {
+			fmt.Fprintf(w, "Server:\t%s\n", result.RDAP.Server)
+		}
 		fmt.Fprintf(w, "\n")
 	}
 
@@ -237,55 +589,1855 @@ func (f *Formatter) displayTable(result *analyzer.Result) error {
 		fmt.Fprintf(w, "\n")
 	}
 
-	// Valuation Section
-	if result.ValuationData != nil {
-		fmt.Fprintf(w, "💰 DOMAIN VALUATION\n")
+	// ENS History Section
+	if h := result.ENSHistory; h != nil {
+		fmt.Fprintf(w, "🕰️ ENS HISTORY\n")
+		fmt.Fprintf(w, "──────────────\n")
+
+		if h.FirstRegistered != nil {
+			age := time.Since(*h.FirstRegistered).Hours() / (24 * 365.25)
+			fmt.Fprintf(w, "First Registered:\t%s (%.1f years)\n", h.FirstRegistered.Format("2006-01-02"), age)
+		}
+		if h.Expiry != nil {
+			fmt.Fprintf(w, "Expires:\t%s\n", h.Expiry.Format("2006-01-02"))
+		}
+		fmt.Fprintf(w, "Renewals:\t%d\n", h.Renewals)
+		if len(h.Owners) > 0 {
+			fmt.Fprintf(w, "Owners:\t%s\n", strings.Join(h.Owners, " → "))
+		}
+		if len(h.Events) > 0 {
+			fmt.Fprintf(w, "Timeline:\n")
+		}
+		for _, e := range h.Events {
+			detail := e.Owner
+			if e.Expiry != nil {
+				if detail != "" {
+					detail += ", "
+				}
+				detail += "expires " + e.Expiry.Format("2006-01-02")
+			}
+			fmt.Fprintf(w, "  Block %d\t%s %s\n", e.Block, e.Type, detail)
+		}
+		if h.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", h.Error)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// Blockchain Linkage Section
+	if links := result.ChainLinks; links != nil {
+		fmt.Fprintf(w, "🔗 BLOCKCHAIN LINKAGE\n")
+		fmt.Fprintf(w, "─────────────────────\n")
+
+		linkedIcon := "❌ No on-chain counterpart found"
+		if links.Linked {
+			linkedIcon = "✅ Linked"
+		}
+		fmt.Fprintf(w, "Status:\t%s\n", linkedIcon)
+		if ens := links.ENS; ens != nil {
+			methods := strings.Join(ens.Methods, ", ")
+			if methods == "" {
+				methods = "not imported"
+			}
+			fmt.Fprintf(w, "ENS:\t%s\n", methods)
+			if ens.OffchainResolver != "" {
+				fmt.Fprintf(w, "  Offchain Resolver:\t%s\n", ens.OffchainResolver)
+			}
+			if ens.ClaimAddress != "" {
+				fmt.Fprintf(w, "  Claim Address (_ens):\t%s\n", ens.ClaimAddress)
+			}
+			if ens.RegistryOwner != "" {
+				fmt.Fprintf(w, "  Registry Owner:\t%s\n", ens.RegistryOwner)
+			}
+			if ens.RegistryResolver != "" {
+				fmt.Fprintf(w, "  Registry Resolver:\t%s\n", ens.RegistryResolver)
+			}
+		}
+		if ud := links.UD; ud != nil {
+			if ud.Owner != "" {
+				fmt.Fprintf(w, "Unstoppable Domains:\t%s\n", ud.Owner)
+				for _, key := range sortedKeys(ud.Records) {
+					fmt.Fprintf(w, "  %s:\t%s\n", key, ud.Records[key])
+				}
+			}
+			if ud.ReverseName != "" {
+				fmt.Fprintf(w, "UD Reverse Name:\t%s\n", ud.ReverseName)
+			}
+		}
+		if links.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", links.Error)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// WHOIS History Section
+	if h := result.WhoisHistory; h != nil {
+		fmt.Fprintf(w, "📜 WHOIS HISTORY\n")
+		fmt.Fprintf(w, "────────────────\n")
+
+		fmt.Fprintf(w, "Snapshots:\t%d (%s)\n", len(h.Snapshots), h.Provider)
+		changeIcon := "✅"
+		if h.OwnershipChanges > 0 {
+			changeIcon = "⚠️"
+		}
+		fmt.Fprintf(w, "Ownership Changes:\t%s %d\n", changeIcon, h.OwnershipChanges)
+		fmt.Fprintf(w, "Registrar Changes:\t%d\n", h.RegistrarChanges)
+		if len(h.Registrants) > 0 {
+			fmt.Fprintf(w, "Registrants:\t%s\n", strings.Join(h.Registrants, " → "))
+		}
+		if len(h.Registrars) > 0 {
+			fmt.Fprintf(w, "Registrars:\t%s\n", strings.Join(h.Registrars, " → "))
+		}
+		if h.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", h.Error)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// UDRP Section
+	if disputes := result.UDRP; disputes != nil {
+		fmt.Fprintf(w, "⚖️ UDRP / DISPUTE HISTORY\n")
+		fmt.Fprintf(w, "─────────────────────────\n")
+
+		historyIcon := "✅ None found"
+		if disputes.DisputeHistory {
+			historyIcon = fmt.Sprintf("⚠️ %d case(s)", len(disputes.Cases))
+		}
+		fmt.Fprintf(w, "Disputes (%q):\t%s\n", disputes.Label, historyIcon)
+		for _, c := range disputes.Cases {
+			match := "similar"
+			if c.Exact {
+				match = "this domain"
+			}
+			fmt.Fprintf(w, "  %s %s\t%s (%s)\n", c.Provider, c.ID, strings.Join(c.Domains, ", "), match)
+		}
+		for _, src := range disputes.Sources {
+			if src.Error != "" {
+				fmt.Fprintf(w, "%s:\t⚠️ %s\n", src.Name, src.Error)
+			}
+		}
+		if disputes.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", disputes.Error)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// Seizure Section
+	if result.Seizure != nil {
+		fmt.Fprintf(w, "🚨 SEIZURE\n")
+		fmt.Fprintf(w, "──────────\n")
+
+		if result.Seizure.Seized {
+			fmt.Fprintf(w, "Status:\t🚨 Seized by law enforcement\n")
+		} else if result.Seizure.Possible {
+			fmt.Fprintf(w, "Status:\t⚠️  Possibly seized (banner without an agency)\n")
+		} else {
+			fmt.Fprintf(w, "Status:\t✅ No sign of seizure\n")
+		}
+		for _, evidence := range result.Seizure.Evidence {
+			fmt.Fprintf(w, "  Evidence:\t%s\n", evidence)
+		}
+		if result.Seizure.Seized {
+			fmt.Fprintf(w, "Note:\tA seized domain cannot be bought, transferred or renewed\n")
+		}
+		if result.Seizure.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", result.Seizure.Error)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// Threat Intel Section
+	if intel := result.ThreatIntel; intel != nil {
+		fmt.Fprintf(w, "☣️ THREAT INTEL\n")
+		fmt.Fprintf(w, "───────────────\n")
+
+		switch {
+		case intel.C2:
+			fmt.Fprintf(w, "Status:\t🚨 Listed as command and control\n")
+		case intel.Listed:
+			fmt.Fprintf(w, "Status:\t🚨 Listed as malicious\n")
+		case intel.Error == "":
+			fmt.Fprintf(w, "Status:\t✅ Not listed\n")
+		}
+		for _, m := range intel.Matches {
+			line := m.Threat
+			if m.Malware != "" {
+				line += " (" + m.Malware + ")"
+			}
+			if m.Description != "" {
+				line += ": " + m.Description
+			}
+			fmt.Fprintf(w, "%s:\t%s\n", m.Feed, line)
+			if m.FirstSeen != "" {
+				fmt.Fprintf(w, "  First Seen:\t%s\n", m.FirstSeen)
+			}
+			if len(m.Tags) > 0 {
+				fmt.Fprintf(w, "  Tags:\t%s\n", strings.Join(m.Tags, ", "))
+			}
+			if m.Reference != "" {
+				fmt.Fprintf(w, "  Reference:\t%s\n", m.Reference)
+			}
+		}
+		for _, feed := range intel.Feeds {
+			if feed.Error != "" {
+				fmt.Fprintf(w, "Note:\t%s not searched: %s\n", feed.Name, feed.Error)
+			}
+		}
+		if intel.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", intel.Error)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// DNS Provider Section
+	if result.DNSProvider != nil {
+		fmt.Fprintf(w, "🏢 DNS PROVIDER\n")
+		fmt.Fprintf(w, "───────────────\n")
+
+		for _, p := range result.DNSProvider.Providers {
+			fmt.Fprintf(w, "%s:\t%s (%s)\n", p.Name, strings.Join(p.Nameservers, ", "), p.Category)
+		}
+
+		multiIcon := "❌"
+		if result.DNSProvider.MultiProvider {
+			multiIcon = "✅"
+		}
+		fmt.Fprintf(w, "Multi-Provider:\t%s\n", multiIcon)
+		switch result.DNSProvider.Usage {
+		case provider.UsageParked:
+			fmt.Fprintf(w, "Usage:\t🅿️ Parked (delegated to a parking service)\n")
+		case provider.UsageSeized:
+			fmt.Fprintf(w, "Usage:\t🚨 Seized (delegated to a law-enforcement seizure nameserver)\n")
+		case provider.UsageSinkholed:
+			fmt.Fprintf(w, "Usage:\t🚫 Sinkholed (delegated to a security sinkhole)\n")
+		case provider.UsageRegistrarDefault:
+			fmt.Fprintf(w, "Usage:\tRegistrar default nameservers\n")
+		case provider.UsageActive:
+			fmt.Fprintf(w, "Usage:\tActively used\n")
+		}
+		if result.DNSProvider.VendorLock != "" {
+			fmt.Fprintf(w, "Vendor Lock:\t⚠️ %s\n", result.DNSProvider.VendorLock)
+		}
+		for _, ns := range result.DNSProvider.Details {
+			anycast := ""
+			if ns.Anycast {
+				anycast = " [anycast]"
+			}
+			fmt.Fprintf(w, "  %s:\t%s %s%s\n", ns.Host, strings.Join(ns.IPs, ", "), strings.Join(ns.ASNs, ", "), anycast)
+		}
+		if r := result.DNSProvider.Resilience; r != nil {
+			fmt.Fprintf(w, "Resilience:\t%s (%d/100), anycast: %s\n", r.Grade, r.Score, r.Anycast)
+			for _, factor := range r.Factors {
+				fmt.Fprintf(w, "  •\t%s\n", factor)
+			}
+		}
+
+		if result.DNSProvider.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", result.DNSProvider.Error)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// IPv6 Readiness Section
+	if v6 := result.IPv6; v6 != nil {
+		fmt.Fprintf(w, "🌐 IPV6 READINESS\n")
+		fmt.Fprintf(w, "─────────────────\n")
+
+		fmt.Fprintf(w, "Grade:\t%s (%d/100)\n", v6.Grade, v6.Score)
+		for _, group := range []struct {
+			label string
+			hosts []ipv6.Host
+		}{{"Web", v6.Web}, {"Nameserver", v6.Nameservers}, {"Mail", v6.MailServers}} {
+			for _, h := range group.hosts {
+				status := "❌ no AAAA"
+				switch {
+				case h.Reachable != nil && !*h.Reachable:
+					status = "⚠️ unreachable " + strings.Join(h.IPv6, ", ")
+				case len(h.IPv6) > 0:
+					status = "✅ " + strings.Join(h.IPv6, ", ")
+				}
+				fmt.Fprintf(w, "%s %s:\t%s\n", group.label, h.Name, status)
+			}
+		}
+		for _, issue := range v6.Issues {
+			fmt.Fprintf(w, "•\t%s\n", issue)
+		}
+		if v6.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", v6.Error)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// Mail Probe Section
+	if result.MailProbe != nil {
+		fmt.Fprintf(w, "📬 MAIL SERVER PROBE\n")
+		fmt.Fprintf(w, "────────────────────\n")
+
+		status := "❌ No live mail server"
+		if result.MailProbe.Alive {
+			status = "✅ Alive"
+		} else if result.MailProbe.Note != "" {
+			status = "⚪ " + result.MailProbe.Note
+		}
+		fmt.Fprintf(w, "Status:\t%s\n", status)
+
+		if len(result.MailProbe.MXHosts) > 0 {
+			fmt.Fprintf(w, "MX Hosts:\t%s\n", strings.Join(result.MailProbe.MXHosts, ", "))
+		}
+
+		for _, ep := range result.MailProbe.Endpoints {
+			if !ep.Reachable {
+				fmt.Fprintf(w, "  %s:%d\t❌ unreachable\n", ep.Host, ep.Port)
+				continue
+			}
+			tlsInfo := "no TLS"
+			if ep.TLSVersion != "" {
+				tlsInfo = ep.TLSVersion
+				if !ep.CertValid {
+					tlsInfo += " (invalid cert)"
+				}
+			}
+			fmt.Fprintf(w, "  %s:%d\t✅ %s\n", ep.Host, ep.Port, tlsInfo)
+			if ep.Banner != "" {
+				fmt.Fprintf(w, "    Banner:\t%s\n", ep.Banner)
+			}
+			if ep.Error != "" {
+				fmt.Fprintf(w, "    Error:\t%s\n", ep.Error)
+			}
+		}
+
+		if result.MailProbe.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", result.MailProbe.Error)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// Port Scan Section
+	if result.PortScan != nil {
+		fmt.Fprintf(w, "🚪 SERVICE EXPOSURE\n")
 		fmt.Fprintf(w, "───────────────────\n")
 
-		fmt.Fprintf(w, "Estimated Value:\t$%d %s\n",
-			result.ValuationData.EstimatedValue,
-			result.ValuationData.Currency)
+		for _, target := range result.PortScan.Targets {
+			var open []string
+			for _, p := range target.Ports {
+				if p.Open {
+					open = append(open, fmt.Sprintf("%d/%s", p.Port, p.Service))
+				}
+			}
+			if len(open) == 0 {
+				fmt.Fprintf(w, "%s:\tno open ports\n", target.IP)
+			} else {
+				fmt.Fprintf(w, "%s:\t%s\n", target.IP, strings.Join(open, ", "))
+			}
+		}
 
-		confidence := result.ValuationData.Confidence
-		confidenceIcon := "🟡"
-		switch confidence {
-		case "high":
-			confidenceIcon = "🟢"
-		case "low":
-			confidenceIcon = "🔴"
+		if result.PortScan.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", result.PortScan.Error)
 		}
-		fmt.Fprintf(w, "Confidence:\t%s %s\n", confidenceIcon, strings.Title(confidence))
+		fmt.Fprintf(w, "\n")
+	}
 
-		fmt.Fprintf(w, "Reasoning:\t%s\n", result.ValuationData.Reasoning)
+	// Web Security Section
+	if result.WebSecurity != nil {
+		web := result.WebSecurity
+		fmt.Fprintf(w, "🔒 WEB SECURITY\n")
+		fmt.Fprintf(w, "───────────────\n")
 
-		fmt.Fprintf(w, "\nValuation Factors:\n")
-		factors := result.ValuationData.Factors
-		fmt.Fprintf(w, "  Length:\t%d chars (Score: %.1f/10)\n", factors.Length, factors.LengthScore)
-		fmt.Fprintf(w, "  Character Quality:\t%.1f/5\n", factors.CharacterScore)
-		fmt.Fprintf(w, "  Word Value:\t%.1f/10\n", factors.WordScore)
-		fmt.Fprintf(w, "  TLD Value:\t%.1f/5\n", factors.TLDScore)
+		if web.Grade != "" {
+			fmt.Fprintf(w, "Grade:\t%s\n", web.Grade)
+		}
 
-		brandableIcon := "❌"
-		if factors.Brandable {
-			brandableIcon = "✅"
+		if len(web.RedirectChain) > 0 {
+			fmt.Fprintf(w, "Redirect Chain:\n")
+			for _, hop := range web.RedirectChain {
+				fmt.Fprintf(w, "  %d\t%s\n", hop.StatusCode, hop.URL)
+			}
 		}
-		fmt.Fprintf(w, "  Brandable:\t%s\n", brandableIcon)
 
-		pronounceableIcon := "❌"
-		if factors.Pronounceable {
-			pronounceableIcon = "✅"
+		httpsIcon := "❌"
+		if web.UpgradesToHTTPS {
+			httpsIcon = "✅"
+		}
+		fmt.Fprintf(w, "HTTP → HTTPS:\t%s\n", httpsIcon)
+		if cert := web.Certificate; cert != nil {
+			fmt.Fprintf(w, "Certificate:\t%s, expires %s\n", cert.Issuer, cert.NotAfter.Format("2006-01-02"))
 		}
-		fmt.Fprintf(w, "  Pronounceable:\t%s\n", pronounceableIcon)
 
-		if factors.HasNumbers {
-			fmt.Fprintf(w, "  Contains Numbers:\t❌ (reduces value)\n")
+		if web.HSTS != nil {
+			fmt.Fprintf(w, "HSTS:\tmax-age=%d", web.HSTS.MaxAge)
+			if web.HSTS.IncludeSubDomains {
+				fmt.Fprintf(w, "; includeSubDomains")
+			}
+			if web.HSTS.Preload {
+				fmt.Fprintf(w, "; preload")
+			}
+			fmt.Fprintf(w, "\n")
 		}
 
-		if factors.HasHyphens {
-			fmt.Fprintf(w, "  Contains Hyphens:\t❌ (reduces value)\n")
+		for _, finding := range web.Findings {
+			fmt.Fprintf(w, "  ⚠️\t%s\n", finding)
+		}
+
+		if web.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", web.Error)
 		}
+		fmt.Fprintf(w, "\n")
 	}
 
-	fmt.Fprintf(w, "\n")
-	return w.Flush()
+	// HSTS Preload Section
+	if result.HSTSPreload != nil {
+		preload := result.HSTSPreload
+		fmt.Fprintf(w, "📌 HSTS PRELOAD\n")
+		fmt.Fprintf(w, "───────────────\n")
+
+		fmt.Fprintf(w, "List Status:\t%s\n", preload.Status)
+
+		eligibleIcon := "❌"
+		if preload.Eligible {
+			eligibleIcon = "✅"
+		}
+		fmt.Fprintf(w, "Eligible:\t%s\n", eligibleIcon)
+		for _, problem := range preload.Problems {
+			fmt.Fprintf(w, "  ⚠️\t%s\n", problem)
+		}
+
+		if preload.Note != "" {
+			fmt.Fprintf(w, "Note:\t%s\n", preload.Note)
+		}
+		if preload.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", preload.Error)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// Email Security Section
+	if result.EmailSecurity != nil {
+		fmt.Fprintf(w, "✉️ EMAIL SECURITY\n")
+		fmt.Fprintf(w, "────────────────\n")
+
+		if spf := result.EmailSecurity.SPF; spf != nil {
+			if spf.Present {
+				fmt.Fprintf(w, "SPF:\t%s\n", spf.Record)
+				lookupIcon := "✅"
+				if spf.LookupCount > 10 {
+					lookupIcon = "❌"
+				}
+				fmt.Fprintf(w, "  DNS Lookups:\t%s %d/10\n", lookupIcon, spf.LookupCount)
+				if spf.AllPolicy != "" {
+					fmt.Fprintf(w, "  Policy:\t%s\n", spf.AllPolicy)
+				}
+				if spf.PermError {
+					fmt.Fprintf(w, "  Result:\t❌ permerror\n")
+				} else if spf.TempError != "" {
+					fmt.Fprintf(w, "  Result:\t⚠️ temperror\n")
+				}
+				if len(spf.Flattened) > 0 {
+					fmt.Fprintf(w, "  IP Ranges:\t%d (after flattening)\n", len(spf.Flattened))
+				}
+			} else if spf.TempError != "" {
+				fmt.Fprintf(w, "SPF:\t⚠️ Unknown (temperror)\n")
+			} else {
+				fmt.Fprintf(w, "SPF:\t❌ Missing\n")
+			}
+			for _, issue := range spf.Issues {
+				fmt.Fprintf(w, "  ⚠️\t%s\n", issue)
+			}
+		}
+
+		if dmarc := result.EmailSecurity.DMARC; dmarc != nil {
+			switch {
+			case dmarc.TempError != "":
+				fmt.Fprintf(w, "DMARC:\t⚠️ Unknown (temperror)\n")
+			case dmarc.Present && dmarc.Domain != "" && dmarc.Domain != result.Domain:
+				fmt.Fprintf(w, "DMARC:\t%s (from %s)\n", dmarc.Record, dmarc.Domain)
+			case dmarc.Present:
+				fmt.Fprintf(w, "DMARC:\t%s\n", dmarc.Record)
+			default:
+				fmt.Fprintf(w, "DMARC:\t❌ Missing\n")
+			}
+			for _, issue := range dmarc.Issues {
+				fmt.Fprintf(w, "  ⚠️\t%s\n", issue)
+			}
+			if dmarc.RecommendedRecord != "" {
+				fmt.Fprintf(w, "  Recommended:\t_dmarc TXT \"%s\"\n", dmarc.RecommendedRecord)
+			}
+		}
+
+		if result.EmailSecurity.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", result.EmailSecurity.Error)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// Dangling Records Section
+	if result.DanglingRecords != nil {
+		fmt.Fprintf(w, "🪝 DANGLING RECORDS\n")
+		fmt.Fprintf(w, "───────────────────\n")
+
+		fmt.Fprintf(w, "Targets Checked:\t%d\n", result.DanglingRecords.Checked)
+		if len(result.DanglingRecords.Findings) == 0 {
+			fmt.Fprintf(w, "Findings:\t✅ None\n")
+		}
+		for _, finding := range result.DanglingRecords.Findings {
+			icon := "⚠️"
+			if finding.Takeover {
+				icon = "🚨"
+			}
+			fmt.Fprintf(w, "%s %s\t%s\n", icon, finding.RecordType, finding.Target)
+			fmt.Fprintf(w, "  Reason:\t%s\n", finding.Reason)
+			fmt.Fprintf(w, "  Fix:\t%s\n", finding.Remediation)
+		}
+
+		if result.DanglingRecords.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", result.DanglingRecords.Error)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// Valuation Section
+	if result.ValuationData != nil {
+		fmt.Fprintf(w, "💰 DOMAIN VALUATION\n")
+		fmt.Fprintf(w, "───────────────────\n")
+
+		fmt.Fprintf(w, "Estimated Value:\t$%d %s\n",
+			result.ValuationData.EstimatedValue,
+			result.ValuationData.Currency)
+
+		confidence := result.ValuationData.Confidence
+		confidenceIcon := "🟡"
+		switch confidence {
+		case "high":
+			confidenceIcon = "🟢"
+		case "low":
+			confidenceIcon = "🔴"
+		}
+		fmt.Fprintf(w, "Confidence:\t%s %s\n", confidenceIcon, strings.Title(confidence))
+
+		fmt.Fprintf(w, "Reasoning:\t%s\n", result.ValuationData.Reasoning)
+
+		if cost := result.ValuationData.RenewalCost; cost != nil {
+			line := fmt.Sprintf("$%.2f/year", *cost)
+			if ratio := result.ValuationData.CostRatio; ratio != nil {
+				line += fmt.Sprintf(" (%.1f%% of value)", *ratio*100)
+			}
+			if result.ValuationData.Underwater {
+				line += " ⚠️ Underwater: costs more to renew than it is worth"
+			}
+			fmt.Fprintf(w, "Carrying Cost:\t%s\n", line)
+		}
+
+		if n := result.ValuationData.Negotiation; n != nil {
+			walkAway := "Walk Away Above"
+			if n.Side == valuation.Seller {
+				walkAway = "Walk Away Below"
+			}
+			fmt.Fprintf(w, "\nNegotiation (%s):\n", n.Side)
+			fmt.Fprintf(w, "  Opening Offer:\t$%d\n", n.Opening)
+			fmt.Fprintf(w, "  Target Price:\t$%d\n", n.Target)
+			fmt.Fprintf(w, "  %s:\t$%d\n", walkAway, n.WalkAway)
+		}
+
+		if lease := result.ValuationData.Lease; lease != nil {
+			fmt.Fprintf(w, "\nLease (%.1f%% cap rate):\n", lease.CapRate*100)
+			fmt.Fprintf(w, "  Monthly Lease:\t$%d ($%d/year)\n", lease.Monthly, lease.Annual)
+			for _, plan := range lease.RentToOwn {
+				fmt.Fprintf(w, "  Rent-to-Own %d mo:\t$%d/month, $%d total (+$%d)\n", plan.Months, plan.Monthly, plan.Total, plan.Premium)
+			}
+		}
+
+		fmt.Fprintf(w, "\nValuation Factors:\n")
+		factors := result.ValuationData.Factors
+		fmt.Fprintf(w, "  Length:\t%d chars (Score: %.1f/10)\n", factors.Length, factors.LengthScore)
+		fmt.Fprintf(w, "  Character Quality:\t%.1f/5\n", factors.CharacterScore)
+		fmt.Fprintf(w, "  Word Value:\t%.1f/10\n", factors.WordScore)
+		fmt.Fprintf(w, "  TLD Value:\t%.1f/5\n", factors.TLDScore)
+
+		brandableIcon := "❌"
+		if factors.Brandable {
+			brandableIcon = "✅"
+		}
+		fmt.Fprintf(w, "  Brandable:\t%s\n", brandableIcon)
+
+		pronounceableIcon := "❌"
+		if factors.Pronounceable {
+			pronounceableIcon = "✅"
+		}
+		fmt.Fprintf(w, "  Pronounceable:\t%s\n", pronounceableIcon)
+
+		if factors.HasNumbers {
+			fmt.Fprintf(w, "  Contains Numbers:\t❌ (reduces value)\n")
+		}
+
+		if factors.HasHyphens {
+			fmt.Fprintf(w, "  Contains Hyphens:\t❌ (reduces value)\n")
+		}
+	}
+
+	fmt.Fprintf(w, "\n")
+	return w.Flush()
+}
+
+// DisplayPortfolio renders the summary of a multi-domain run.
+func (f *Formatter) DisplayPortfolio(summary *portfolio.Summary) error {
+	return f.emit(func(c *Formatter) error { return c.displayPortfolio(summary) })
+}
+
+func (f *Formatter) displayPortfolio(summary *portfolio.Summary) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]*portfolio.Summary{"portfolio": summary})
+	case "table":
+		return f.displayPortfolioTable(summary)
+	case "html":
+		return f.htmlSection(func() error { return f.displayPortfolioTable(summary) })
+	case "csv", "jsonl", "stix", "cef", "leef":
+		// A CSV stream holds one row per domain, a JSON Lines stream one
+		// object per domain, a STIX stream one bundle per domain and a
+		// SIEM stream events, nothing else.
+		return nil
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+// DisplayRunStats renders the statistics of a multi-domain run: a JSON
+// object, or one summary line (on stderr for CSV and the other streams, to
+// keep them clean).
+func (f *Formatter) DisplayRunStats(stats *portfolio.Stats) error {
+	return f.emit(func(c *Formatter) error { return c.displayRunStats(stats) })
+}
+
+func (f *Formatter) displayRunStats(stats *portfolio.Stats) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]*portfolio.Stats{"run_stats": stats})
+	case "table":
+		fmt.Fprintf(f.out, "⏱️ Run: %s\n\n", stats.Line())
+		return nil
+	case "html":
+		return f.htmlSection(func() error { return f.displayRunStats(stats) })
+	case "csv", "jsonl", "stix", "cef", "leef":
+		fmt.Fprintf(os.Stderr, "Run: %s\n", stats.Line())
+		return nil
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+func (f *Formatter) displayPortfolioTable(summary *portfolio.Summary) error {
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\n📊 PORTFOLIO SUMMARY\n")
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+	fmt.Fprintf(w, "Domains:\t%d\n", summary.Domains)
+
+	if len(summary.DNSProviders) > 0 {
+		fmt.Fprintf(w, "\nDNS Providers:\n")
+		for _, c := range summary.DNSProviders {
+			fmt.Fprintf(w, "  %s:\t%d (%.0f%%)\n", c.Name, c.Count, c.Share*100)
+		}
+	}
+
+	if len(summary.Registrars) > 0 {
+		fmt.Fprintf(w, "\nRegistrars:\n")
+		for _, c := range summary.Registrars {
+			fmt.Fprintf(w, "  %s:\t%d (%.0f%%)\n", c.Name, c.Count, c.Share*100)
+		}
+	}
+
+	if len(summary.Unlocked) > 0 {
+		fmt.Fprintf(w, "\nNo Transfer Lock:\t%s\n", strings.Join(summary.Unlocked, ", "))
+	}
+	if len(summary.Disputed) > 0 {
+		fmt.Fprintf(w, "UDRP Disputes:\t%s\n", strings.Join(summary.Disputed, ", "))
+	}
+	if len(summary.Underwater) > 0 {
+		fmt.Fprintf(w, "Underwater:\t%s\n", strings.Join(summary.Underwater, ", "))
+	}
+	if summary.RenewalCost > 0 {
+		fmt.Fprintf(w, "Yearly Renewals:\t$%.2f\n", summary.RenewalCost)
+	}
+
+	if len(summary.ExpiringSoon) > 0 {
+		fmt.Fprintf(w, "\nExpiring Soon:\n")
+		for _, e := range summary.ExpiringSoon {
+			if e.Expired {
+				fmt.Fprintf(w, "  %s:\t%s (expired)\n", e.Domain, e.ExpiryDate.Format("2006-01-02"))
+				continue
+			}
+			fmt.Fprintf(w, "  %s:\t%s (%d days)\n", e.Domain, e.ExpiryDate.Format("2006-01-02"), e.DaysLeft)
+		}
+	}
+
+	if len(summary.Risks) > 0 {
+		fmt.Fprintf(w, "\nRisks:\n")
+		for _, risk := range summary.Risks {
+			fmt.Fprintf(w, "  ⚠️\t%s\n", risk)
+		}
+	}
+
+	fmt.Fprintf(w, "\n")
+	return w.Flush()
+}
+
+// DisplayDNSAudit renders the results of a DNS compliance test run.
+func (f *Formatter) DisplayDNSAudit(report *dnsaudit.Report) error {
+	return f.emit(func(c *Formatter) error { return c.displayDNSAudit(report) })
+}
+
+func (f *Formatter) displayDNSAudit(report *dnsaudit.Report) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "table":
+		return f.displayDNSAuditTable(report)
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+func (f *Formatter) displayDNSAuditTable(report *dnsaudit.Report) error {
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\n🧪 DNS COMPLIANCE AUDIT\n")
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+	fmt.Fprintf(w, "Domain:\t%s\n", report.Domain)
+	fmt.Fprintf(w, "Checked:\t%s\n\n", report.CheckedAt.Format("2006-01-02 15:04:05 MST"))
+
+	category := ""
+	for _, t := range report.Tests {
+		if t.Category != category {
+			category = t.Category
+			fmt.Fprintf(w, "%s\n", strings.ToUpper(category))
+		}
+		icon := "✅"
+		switch t.Status {
+		case dnsaudit.Warn:
+			icon = "⚠️"
+		case dnsaudit.Fail:
+			icon = "❌"
+		}
+		fmt.Fprintf(w, "  %s %s\t%s\n", icon, t.ID, t.Description)
+		if t.Message != "" && t.Status != dnsaudit.Pass {
+			fmt.Fprintf(w, "     \t%s\n", t.Message)
+		}
+	}
+
+	fmt.Fprintf(w, "\nSummary:\t%d passed, %d warnings, %d failures\n", report.Passed, report.Warnings, report.Failures)
+	if report.Error != "" {
+		fmt.Fprintf(w, "Error:\t%s\n", report.Error)
+	}
+
+	fmt.Fprintf(w, "\n")
+	return w.Flush()
+}
+
+// DisplayIdentity renders a namespace coverage report.
+func (f *Formatter) DisplayIdentity(report *identity.Report) error {
+	return f.emit(func(c *Formatter) error { return c.displayIdentity(report) })
+}
+
+func (f *Formatter) displayIdentity(report *identity.Report) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "table":
+		return f.displayIdentityTable(report)
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+func (f *Formatter) displayIdentityTable(report *identity.Report) error {
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\n🪪 NAMESPACE COVERAGE: %s\n", report.Name)
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+
+	icons := map[string]string{
+		identity.StatusSecured:    "✅ secured",
+		identity.StatusMissing:    "🟡 missing",
+		identity.StatusThirdParty: "❌ third-party",
+		identity.StatusTaken:      "⚪ taken",
+		identity.StatusUnknown:    "❔ unknown",
+	}
+	kind := ""
+	for _, e := range report.Entries {
+		if e.Kind != kind {
+			kind = e.Kind
+			fmt.Fprintf(w, "%s\n", strings.ToUpper(kind))
+		}
+		detail := e.Owner
+		if e.Kind == identity.KindSocial {
+			detail = e.URL
+		}
+		if e.Error != "" {
+			detail = e.Error
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", e.Target, icons[e.Status], detail)
+	}
+
+	fmt.Fprintf(w, "\nSummary:\t%d secured, %d missing, %d third-party, %d taken (owner not compared), %d unknown\n",
+		report.Secured, report.Missing, report.ThirdParty, report.Taken, report.Unknown)
+	fmt.Fprintf(w, "\n")
+	return w.Flush()
+}
+
+// DisplayIACAudit renders an infrastructure-as-code inventory audit.
+func (f *Formatter) DisplayIACAudit(report *iac.Report) error {
+	return f.emit(func(c *Formatter) error { return c.displayIACAudit(report) })
+}
+
+func (f *Formatter) displayIACAudit(report *iac.Report) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "table":
+		return f.displayIACAuditTable(report)
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+func (f *Formatter) displayIACAuditTable(report *iac.Report) error {
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\n🏗️ IAC INVENTORY AUDIT\n")
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+	fmt.Fprintf(w, "Zones Checked:\t%d\n", report.Zones)
+	fmt.Fprintf(w, "Registered Domains:\t%d\n", report.Domains)
+	fmt.Fprintf(w, "Matching Zones:\t%d\n\n", report.Matched)
+
+	if len(report.Findings) == 0 {
+		fmt.Fprintf(w, "✅ Inventory matches the registries\n\n")
+		return w.Flush()
+	}
+	for _, finding := range report.Findings {
+		icon := "❌"
+		if finding.Kind == iac.LookupFailed || finding.Kind == iac.DomainWithoutZone {
+			icon = "⚠️"
+		}
+		fmt.Fprintf(w, "%s %s\t%s\n", icon, finding.Domain, finding.Kind)
+		fmt.Fprintf(w, "   \t%s\n", finding.Message)
+		if finding.Kind == iac.NameserverMismatch {
+			fmt.Fprintf(w, "   Zone:\t%s\n", strings.Join(finding.ZoneNS, ", "))
+			fmt.Fprintf(w, "   Registry:\t%s\n", strings.Join(finding.RegistryNS, ", "))
+		}
+	}
+	fmt.Fprintf(w, "\n")
+	return w.Flush()
+}
+
+// DisplayCloudflare renders the zones of a Cloudflare account with their
+// analysis verdicts and, when checked, policy findings.
+func (f *Formatter) DisplayCloudflare(report *cloud.CloudflareReport) error {
+	return f.emit(func(c *Formatter) error { return c.displayCloudflare(report) })
+}
+
+func (f *Formatter) displayCloudflare(report *cloud.CloudflareReport) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "table":
+		return f.displayCloudflareTable(report)
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+func (f *Formatter) displayCloudflareTable(report *cloud.CloudflareReport) error {
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\n☁️ CLOUDFLARE ACCOUNT\n")
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+	fmt.Fprintf(w, "Zones:\t%d (%d active, %d pending, %d moved)\n", len(report.Zones), report.Active, report.Pending, report.Moved)
+	if report.RegistrarNote != "" {
+		fmt.Fprintf(w, "Registrar:\t⚠️ %s\n", report.RegistrarNote)
+	}
+	fmt.Fprintf(w, "\n")
+
+	fmt.Fprintf(w, "ZONE\tSTATUS\tREGISTRAR\tEXPIRES\tRECORDS\tVERDICT\tVALUE\n")
+	for _, z := range report.Zones {
+		status := "✅ " + z.Status
+		if z.Status != "active" || z.Paused {
+			status = "⚠️ " + z.Status
+		}
+		registrar, expires := "-", "-"
+		if z.Registration != nil {
+			registrar = "Cloudflare"
+			if z.Registration.ExpiresAt != nil {
+				expires = z.Registration.ExpiresAt.Format("2006-01-02")
+			}
+		} else if z.Analysis != nil && z.Analysis.WhoisData != nil {
+			if z.Analysis.WhoisData.Registrar != "" {
+				registrar = z.Analysis.WhoisData.Registrar
+			}
+			if z.Analysis.WhoisData.ExpiryDate != nil {
+				expires = z.Analysis.WhoisData.ExpiryDate.Format("2006-01-02")
+			}
+		}
+		records := fmt.Sprint(len(z.Records))
+		if z.RecordsError != "" {
+			records = "?"
+		}
+		verdict, value := "-", "-"
+		if z.Analysis != nil {
+			verdict = z.Analysis.Verdict()
+			if z.Analysis.ValuationData != nil {
+				value = fmt.Sprintf("$%d", z.Analysis.ValuationData.EstimatedValue)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", z.Name, status, registrar, expires, records, verdict, value)
+	}
+
+	var notes bool
+	for _, z := range report.Zones {
+		for _, note := range z.Notes {
+			if !notes {
+				fmt.Fprintf(w, "\nAttention:\n")
+				notes = true
+			}
+			fmt.Fprintf(w, "  ⚠️ %s\t%s\n", z.Name, note)
+		}
+		if z.RecordsError != "" {
+			fmt.Fprintf(w, "  Error: %s\t%s\n", z.Name, z.RecordsError)
+		}
+	}
+	fmt.Fprintf(w, "\n")
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if report.Policy != nil {
+		return f.displayPolicyTable(report.Policy)
+	}
+	return nil
+}
+
+// DisplayCollateral renders borrowing quotes for a domain.
+func (f *Formatter) DisplayCollateral(report *collateral.Report) error {
+	return f.emit(func(c *Formatter) error { return c.displayCollateral(report) })
+}
+
+func (f *Formatter) displayCollateral(report *collateral.Report) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "table":
+		return f.displayCollateralTable(report)
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+func (f *Formatter) displayCollateralTable(report *collateral.Report) error {
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\n🏦 COLLATERAL: %s\n", report.Domain)
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+	if report.Confidence != "" {
+		fmt.Fprintf(w, "Estimated Value:\t$%d (%s confidence)\n", report.EstimatedValue, report.Confidence)
+	} else {
+		fmt.Fprintf(w, "Value:\t$%d\n", report.EstimatedValue)
+	}
+	fmt.Fprintf(w, "Collateral Value:\t$%d\n\n", report.CollateralValue)
+
+	fmt.Fprintf(w, "PLATFORM\tLTV\tMAX BORROW\tAPR\tINTEREST/YEAR\tLIQUIDATION AT\tTERMS\n")
+	for _, q := range report.Quotes {
+		apr, interest, liquidation := "-", "-", "-"
+		if q.APR > 0 {
+			apr = fmt.Sprintf("%.1f%%", q.APR*100)
+			interest = fmt.Sprintf("$%d", q.YearlyInterest)
+		}
+		if q.LiquidationValue > 0 {
+			liquidation = fmt.Sprintf("$%d", q.LiquidationValue)
+		}
+		fmt.Fprintf(w, "%s\t%.0f%%\t$%d\t%s\t%s\t%s\t%s\n", q.Name, q.LTV*100, q.MaxBorrow, apr, interest, liquidation, q.Source)
+	}
+
+	for _, note := range report.Notes {
+		fmt.Fprintf(w, "\nNote:\t%s", note)
+	}
+	fmt.Fprintf(w, "\n\n")
+	return w.Flush()
+}
+
+// DisplayRenewals renders an ENS renewal plan.
+func (f *Formatter) DisplayRenewals(report *renewal.Report) error {
+	return f.emit(func(c *Formatter) error { return c.displayRenewals(report) })
+}
+
+func (f *Formatter) displayRenewals(report *renewal.Report) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "table":
+		return f.displayRenewalsTable(report)
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+func (f *Formatter) displayRenewalsTable(report *renewal.Report) error {
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\n⛽ ENS RENEWALS\n")
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+	fmt.Fprintf(w, "Gas Now:\t%.2f gwei base + %.2f gwei priority\n", report.BaseFee, report.PriorityFee)
+	if report.ETHUSD > 0 {
+		fmt.Fprintf(w, "ETH/USD:\t$%.2f\n", report.ETHUSD)
+	}
+	fmt.Fprintf(w, "History:\t%s (%d blocks)\n", report.History, report.Samples)
+
+	fmt.Fprintf(w, "\n🕒 Cheapest Hours (UTC):\n")
+	fmt.Fprintf(w, "─────────────────────────────\n")
+	for _, win := range report.Windows {
+		fmt.Fprintf(w, "  %02d:00-%02d:00\t%.2f gwei\t%.0f%% below now\n", win.Hour, (win.Hour+1)%24, win.BaseFee, win.Savings*100)
+	}
+
+	fmt.Fprintf(w, "\nNAME\tEXPIRES\tDAYS\tFEE\tGAS NOW\tGAS IN WINDOW\tTOTAL\tADVICE\n")
+	for _, n := range report.Names {
+		if n.Error != "" {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t-\t-\tError: %s\n", n.Name, n.Error)
+			continue
+		}
+		total := "-"
+		if n.TotalUSD != nil {
+			total = fmt.Sprintf("$%.2f", *n.TotalUSD)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t$%.0f\t%.5f ETH\t%.5f ETH\t%s\t%s\n",
+			n.Name, n.Expires.Format("2006-01-02"), n.DaysLeft, n.FeeUSD, n.GasETH, n.WindowGasETH, total, n.Advice)
+	}
+
+	for _, note := range report.Notes {
+		fmt.Fprintf(w, "\nNote:\t%s", note)
+	}
+	fmt.Fprintf(w, "\n\n")
+	return w.Flush()
+}
+
+// DisplayRenewalBatch renders a summary of an ENS bulk renewal.
+func (f *Formatter) DisplayRenewalBatch(batch *renewal.Batch) error {
+	return f.emit(func(c *Formatter) error { return c.displayRenewalBatch(batch) })
+}
+
+func (f *Formatter) displayRenewalBatch(batch *renewal.Batch) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(batch)
+	case "table":
+		return f.displayRenewalBatchTable(batch)
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+func (f *Formatter) displayRenewalBatchTable(batch *renewal.Batch) error {
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\n📦 ENS BULK RENEWAL\n")
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+	if len(batch.Names) == 0 {
+		fmt.Fprintf(w, "No names expire within %d days.\n", batch.Within)
+	} else {
+		fmt.Fprintf(w, "Contract:\t%s\n", batch.Contract)
+		fmt.Fprintf(w, "Duration:\t%d year(s)\n", batch.Years)
+		fmt.Fprintf(w, "Value:\t%.6f ETH (fee + 5%%, excess refunded)\n\n", batch.ValueETH)
+		fmt.Fprintf(w, "NAME\tEXPIRES\tDAYS\n")
+		for _, n := range batch.Names {
+			fmt.Fprintf(w, "%s\t%s\t%d\n", n.Name, n.Expires.Format("2006-01-02"), n.DaysLeft)
+		}
+	}
+
+	if len(batch.Skipped) > 0 {
+		fmt.Fprintf(w, "\nSkipped:\n")
+		for _, n := range batch.Skipped {
+			if n.Error != "" {
+				fmt.Fprintf(w, "  %s\tError: %s\n", n.Name, n.Error)
+			} else {
+				fmt.Fprintf(w, "  %s\texpires in %d days\n", n.Name, n.DaysLeft)
+			}
+		}
+	}
+	fmt.Fprintf(w, "\n")
+	return w.Flush()
+}
+
+// DisplayRPCHealth renders the health of RPC endpoints.
+func (f *Formatter) DisplayRPCHealth(health []blockchain.EndpointHealth) error {
+	return f.emit(func(c *Formatter) error { return c.displayRPCHealth(health) })
+}
+
+func (f *Formatter) displayRPCHealth(health []blockchain.EndpointHealth) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string][]blockchain.EndpointHealth{"endpoints": health})
+	case "table":
+		w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "\n🩺 RPC HEALTH\n")
+		fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+		fmt.Fprintf(w, "ENDPOINT\tSTATUS\tBLOCK\tLAG\tLATENCY\n")
+		for _, h := range health {
+			status := "✅ Healthy"
+			if !h.Healthy {
+				status = "❌ " + h.Error
+			}
+			block := "-"
+			if h.Block > 0 {
+				block = fmt.Sprint(h.Block)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%dms\n", h.Endpoint, status, block, h.Lag, h.LatencyMS)
+		}
+		fmt.Fprintf(w, "\n")
+		return w.Flush()
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+// DisplayPolicy renders the findings of a policy run.
+func (f *Formatter) DisplayPolicy(report *policy.Report) error {
+	return f.emit(func(c *Formatter) error { return c.displayPolicy(report) })
+}
+
+func (f *Formatter) displayPolicy(report *policy.Report) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "table":
+		return f.displayPolicyTable(report)
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+func (f *Formatter) displayPolicyTable(report *policy.Report) error {
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\n📏 DOMAIN POLICY: %s\n", report.Policy)
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+
+	domain := ""
+	for _, finding := range report.Findings {
+		if finding.Domain != domain {
+			if domain != "" {
+				fmt.Fprintf(w, "\n")
+			}
+			domain = finding.Domain
+			fmt.Fprintf(w, "%s\n", domain)
+		}
+		icon := "✅"
+		switch finding.Status {
+		case policy.Fail:
+			icon = "❌"
+		case policy.Skip:
+			icon = "⚠️"
+		}
+		fmt.Fprintf(w, "  %s %s\t%s\n", icon, finding.Rule, finding.Message)
+	}
+
+	fmt.Fprintf(w, "\nSummary:\t%d passed, %d failed, %d skipped\n", report.Passed, report.Failed, report.Skipped)
+	fmt.Fprintf(w, "\n")
+	return w.Flush()
+}
+
+// DisplaySubdomains renders the results of a subdomain enumeration.
+func (f *Formatter) DisplaySubdomains(result *subdomains.Result) error {
+	return f.emit(func(c *Formatter) error { return c.displaySubdomains(result) })
+}
+
+func (f *Formatter) displaySubdomains(result *subdomains.Result) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case "table":
+		return f.displaySubdomainsTable(result)
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+func (f *Formatter) displaySubdomainsTable(result *subdomains.Result) error {
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\n🌿 SUBDOMAINS\n")
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+	fmt.Fprintf(w, "Domain:\t%s\n", result.Domain)
+	fmt.Fprintf(w, "Candidates Tried:\t%d (%d from CT logs)\n", result.Tried, result.CTNames)
+	if result.Wildcard {
+		fmt.Fprintf(w, "Wildcard DNS:\t⚠️ Yes (matching answers filtered)\n")
+	}
+	fmt.Fprintf(w, "\n")
+
+	for _, s := range result.Found {
+		fmt.Fprintf(w, "%s\t%s\t[%s]\n", s.Name, strings.Join(s.IPs, ", "), s.Source)
+	}
+	fmt.Fprintf(w, "\nFound:\t%d\n", len(result.Found))
+
+	if result.Error != "" {
+		fmt.Fprintf(w, "Error:\t%s\n", result.Error)
+	}
+
+	fmt.Fprintf(w, "\n")
+	return w.Flush()
+}
+
+// DisplayBrandHits renders one poll of the brand monitor.
+func (f *Formatter) DisplayBrandHits(result *brand.Result) error {
+	return f.emit(func(c *Formatter) error { return c.displayBrandHits(result) })
+}
+
+func (f *Formatter) displayBrandHits(result *brand.Result) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case "table":
+		return f.displayBrandHitsTable(result)
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+func (f *Formatter) displayBrandHitsTable(result *brand.Result) error {
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\n🛡️ BRAND MONITOR (%s)\n", result.CheckedAt.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+	fmt.Fprintf(w, "Keywords:\t%s\n", strings.Join(result.Keywords, ", "))
+	for _, feed := range result.Feeds {
+		status := fmt.Sprintf("%d new name(s)", feed.Names)
+		if feed.Error != "" {
+			status = "⚠️ " + feed.Error
+		}
+		fmt.Fprintf(w, "Feed %s:\t%s\n", feed.Source, status)
+	}
+	fmt.Fprintf(w, "\n")
+
+	for _, hit := range result.Hits {
+		detail := hit.Rule
+		if hit.Detail != "" {
+			detail += ", " + hit.Detail
+		}
+		fmt.Fprintf(w, "🚨 %s\t%s\t(%s)\n", hit.Domain, hit.Keyword, detail)
+	}
+	fmt.Fprintf(w, "\nHits:\t%d of %d new name(s)\n", len(result.Hits), result.Scanned)
+
+	if result.Error != "" {
+		fmt.Fprintf(w, "Error:\t%s\n", result.Error)
+	}
+
+	fmt.Fprintf(w, "\n")
+	return w.Flush()
+}
+
+// DisplayWatch writes one poll of the watch command. The first poll lists
+// every domain's state; later polls only the changes.
+func (f *Formatter) DisplayWatch(result *watch.Result) error {
+	return f.emit(func(c *Formatter) error { return c.displayWatch(result) })
+}
+
+func (f *Formatter) displayWatch(result *watch.Result) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case "table":
+		return f.displayWatchTable(result)
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+func (f *Formatter) displayWatchTable(result *watch.Result) error {
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\n👀 WATCH poll %d (%s)\n", result.Poll, result.CheckedAt.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+
+	if result.Poll == 1 {
+		fmt.Fprintf(w, "Domain\tAvailability\tExpiry\tTokenization\n")
+		for _, s := range result.States {
+			expiry := "-"
+			if s.Expiry != nil {
+				expiry = s.Expiry.Format("2006-01-02")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Domain, s.Availability, expiry, s.Tokenization)
+		}
+	} else if len(result.Changes) == 0 {
+		fmt.Fprintf(w, "No changes in %d domain(s)\n", len(result.States))
+	}
+	for _, c := range result.Changes {
+		icon := "🔄"
+		if c.Dropped {
+			icon = "🚨"
+		}
+		fmt.Fprintf(w, "%s %s\t%s\t%s → %s\n", icon, c.Domain, c.Field, c.Old, c.New)
+	}
+	if dropped := result.Dropped(); len(dropped) > 0 {
+		fmt.Fprintf(w, "\nDropped:\t%s\n", strings.Join(dropped, ", "))
+	}
+	for _, s := range result.States {
+		if s.Error != "" {
+			fmt.Fprintf(w, "Note:\t%s: %s\n", s.Domain, s.Error)
+		}
+	}
+
+	fmt.Fprintf(w, "\n")
+	return w.Flush()
+}
+
+// DisplayHistory writes the recorded analyses of a domain, oldest first,
+// with what changed in each. total is the number of analyses on record,
+// which may exceed len(entries) when they were filtered.
+func (f *Formatter) DisplayHistory(domain string, total int, entries []history.Entry) error {
+	return f.emit(func(c *Formatter) error { return c.displayHistory(domain, total, entries) })
+}
+
+func (f *Formatter) displayHistory(domain string, total int, entries []history.Entry) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		if entries == nil {
+			entries = []history.Entry{}
+		}
+		return encoder.Encode(struct {
+			Domain   string          `json:"domain"`
+			Total    int             `json:"total"`
+			Analyses []history.Entry `json:"analyses"`
+		}{domain, total, entries})
+	case "table":
+		return f.displayHistoryTable(domain, total, entries)
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+func (f *Formatter) displayHistoryTable(domain string, total int, entries []history.Entry) error {
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\n📜 HISTORY of %s\n", domain)
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+
+	if total == 0 {
+		fmt.Fprintf(w, "No analyses on record; runs with -cache-dir keep them\n\n")
+		return w.Flush()
+	}
+	fmt.Fprintf(w, "Analyzed\tVerdict\tRegistrar\tExpiry\tValue\tChanges\n")
+	for _, e := range entries {
+		registrar, expiry, value := "-", "-", "-"
+		if e.Registrar != "" {
+			registrar = e.Registrar
+		}
+		if e.Expiry != nil {
+			expiry = e.Expiry.Format("2006-01-02")
+		}
+		if e.EstimatedValue != nil {
+			value = fmt.Sprintf("$%d", *e.EstimatedValue)
+		}
+		changes := "-"
+		if len(e.Changes) > 0 {
+			changes = fmt.Sprintf("%d", len(e.Changes))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Timestamp.Local().Format("2006-01-02 15:04"), e.Verdict, registrar, expiry, value, changes)
+	}
+
+	first := true
+	for _, e := range entries {
+		for _, c := range e.Changes {
+			if first {
+				fmt.Fprintf(w, "\nChanges:\n")
+				first = false
+			}
+			detail := ""
+			if c.Detail != "" {
+				detail = " (" + c.Detail + ")"
+			}
+			fmt.Fprintf(w, "🔄 %s\t%s\t%s → %s%s\n", e.Timestamp.Local().Format("2006-01-02 15:04"), c.Field, c.Old, c.New, detail)
+		}
+	}
+	if len(entries) < total {
+		fmt.Fprintf(w, "\nNote:\t%d of %d analyses shown\n", len(entries), total)
+	}
+
+	fmt.Fprintf(w, "\n")
+	return w.Flush()
+}
+
+// DisplayComparison writes candidates side by side, one column per name in
+// rank order. JSON is the ranked array.
+func (f *Formatter) DisplayComparison(candidates []compare.Candidate) error {
+	return f.emit(func(c *Formatter) error { return c.displayComparison(candidates) })
+}
+
+func (f *Formatter) displayComparison(candidates []compare.Candidate) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(candidates)
+	case "table":
+		return f.displayComparisonTable(candidates)
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+func (f *Formatter) displayComparisonTable(candidates []compare.Candidate) error {
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\n⚖️  DOMAIN COMPARISON (%d names, ranked by estimated value)\n", len(candidates))
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+
+	row := func(label string, cell func(c compare.Candidate) string) {
+		fmt.Fprintf(w, "%s:", label)
+		for _, c := range candidates {
+			fmt.Fprintf(w, "\t%s", cell(c))
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	row("Rank", func(c compare.Candidate) string { return fmt.Sprintf("#%d", c.Rank) })
+	row("Domain", func(c compare.Candidate) string { return c.Domain })
+	row("Verdict", func(c compare.Candidate) string {
+		switch c.Verdict {
+		case analyzer.VerdictAvailable:
+			return "✅ available"
+		case analyzer.VerdictTaken:
+			if usage := c.Result.Usage(); usage != "" {
+				return "❌ taken (" + usage + ")"
+			}
+			return "❌ taken"
+		}
+		return "❓ unknown"
+	})
+	row("Estimated Value", func(c compare.Candidate) string {
+		if c.Result.ValuationData == nil {
+			return "-"
+		}
+		return fmt.Sprintf("$%d", c.EstimatedValue)
+	})
+	row("Confidence", func(c compare.Candidate) string {
+		if c.Result.ValuationData == nil {
+			return "-"
+		}
+		return c.Result.ValuationData.Confidence
+	})
+	row("Carrying Cost", func(c compare.Candidate) string {
+		v := c.Result.ValuationData
+		if v == nil || v.RenewalCost == nil {
+			return "-"
+		}
+		cost := fmt.Sprintf("$%.2f/yr", *v.RenewalCost)
+		if v.Underwater {
+			cost += " ⚠️"
+		}
+		return cost
+	})
+	row("Length", func(c compare.Candidate) string {
+		if c.Result.ValuationData == nil {
+			return "-"
+		}
+		return fmt.Sprint(c.Result.ValuationData.Factors.Length)
+	})
+	row("Registrar", func(c compare.Candidate) string {
+		if c.Result.WhoisData == nil || c.Result.WhoisData.Registrar == "" {
+			return "-"
+		}
+		return c.Result.WhoisData.Registrar
+	})
+	row("Expires", func(c compare.Candidate) string {
+		if c.Result.WhoisData == nil || c.Result.WhoisData.ExpiryDate == nil {
+			return "-"
+		}
+		return c.Result.WhoisData.ExpiryDate.Format("2006-01-02")
+	})
+	row("Tokenized", func(c compare.Candidate) string {
+		if d := c.Result.DomaData; d != nil && d.IsTokenized != nil {
+			return fmt.Sprint(*d.IsTokenized)
+		}
+		return "unknown"
+	})
+
+	noted := false
+	for _, c := range candidates {
+		if !c.Result.Degraded() {
+			continue
+		}
+		if !noted {
+			fmt.Fprintf(w, "\n")
+			noted = true
+		}
+		fmt.Fprintf(w, "Note:\t%s is degraded (%d check(s) failed); its column may be incomplete\n", c.Domain, len(c.Result.SectionErrors))
+	}
+
+	fmt.Fprintf(w, "\n")
+	return w.Flush()
+}
+
+// DisplayAuditExport writes the selected entries of an audit log. CSV
+// holds one row per entry; the verification result goes to stderr.
+func (f *Formatter) DisplayAuditExport(export *audit.Export) error {
+	return f.emit(func(c *Formatter) error { return c.displayAuditExport(export) })
+}
+
+func (f *Formatter) displayAuditExport(export *audit.Export) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(export)
+	case "csv":
+		w := csv.NewWriter(f.out)
+		w.Write([]string{"time", "actor", "backend", "server", "query", "domain", "status", "error", "hash"})
+		for _, e := range export.Entries {
+			status := ""
+			if e.Status != 0 {
+				status = fmt.Sprint(e.Status)
+			}
+			w.Write([]string{e.Time.Format(time.RFC3339), e.Actor, e.Backend, e.Server, e.Query, e.Domain, status, e.Error, e.Hash})
+		}
+		w.Flush()
+		if export.Verified {
+			fmt.Fprintf(os.Stderr, "Audit log verified: %d entries\n", export.Total)
+		}
+		return w.Error()
+	case "table":
+		return f.displayAuditExportTable(export)
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+func (f *Formatter) displayAuditExportTable(export *audit.Export) error {
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\n📜 AUDIT LOG %s\n", export.Log)
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+	if export.Verified {
+		fmt.Fprintf(w, "Integrity:\t✅ hash chain intact (%d entries)\n", export.Total)
+	} else {
+		fmt.Fprintf(w, "Integrity:\t❌ %s\n", export.VerifyError)
+	}
+	fmt.Fprintf(w, "Selected:\t%d entries\n\n", len(export.Entries))
+
+	if len(export.Entries) > 0 {
+		fmt.Fprintf(w, "Time\tActor\tBackend\tServer\tDomain\tResult\n")
+	}
+	for _, e := range export.Entries {
+		result := "ok"
+		switch {
+		case e.Error != "":
+			result = e.Error
+		case e.Status != 0:
+			result = fmt.Sprintf("HTTP %d", e.Status)
+		}
+		domain := e.Domain
+		if domain == "" {
+			domain = e.Query
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Time.Format("2006-01-02 15:04:05"), e.Actor, e.Backend, e.Server, domain, result)
+	}
+
+	fmt.Fprintf(w, "\n")
+	return w.Flush()
+}
+
+// DisplayRawArchive writes the archived responses of a domain and, for
+// those parsed again, what the parser reads from them.
+func (f *Formatter) DisplayRawArchive(domain string, entries []rawarchive.Entry) error {
+	return f.emit(func(c *Formatter) error { return c.displayRawArchive(domain, entries) })
+}
+
+func (f *Formatter) displayRawArchive(domain string, entries []rawarchive.Entry) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	case "table":
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\n🗄️  RAW ARCHIVE %s\n", domain)
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+	if len(entries) == 0 {
+		fmt.Fprintf(w, "No archived responses.\n\n")
+		return w.Flush()
+	}
+	fmt.Fprintf(w, "Time\tProtocol\tServer\tSize\tRegistrar\tExpires\n")
+	for _, e := range entries {
+		registrar, expires := "-", "-"
+		if p := e.Parsed; p != nil {
+			switch {
+			case p.Error != "":
+				registrar = p.Error
+			case p.Available:
+				registrar = "(available)"
+			case p.Registrar != "":
+				registrar = p.Registrar
+			}
+			if p.ExpiryDate != nil {
+				expires = p.ExpiryDate.Format("2006-01-02")
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d B\t%s\t%s\n", e.Time.Format("2006-01-02 15:04:05"), e.Protocol, e.Server, e.Size, registrar, expires)
+	}
+	fmt.Fprintf(w, "\n")
+	return w.Flush()
+}
+
+// DisplaySigned writes a signed envelope. Signed output is always JSON.
+func (f *Formatter) DisplaySigned(env *signing.Envelope) error {
+	return f.emit(func(c *Formatter) error { return c.displaySigned(env) })
+}
+
+func (f *Formatter) displaySigned(env *signing.Envelope) error {
+	encoder := json.NewEncoder(f.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(env)
+}
+
+// displayOwnerInfo shows what kind of account owns a domain token.
+func displayUDProfile(w *tabwriter.Writer, p *blockchain.UDProfile) {
+	if p.DisplayName != "" {
+		fmt.Fprintf(w, "Display Name:\t%s\n", p.DisplayName)
+	}
+	reverse := "✅ owner's primary name"
+	if !p.Reverse {
+		reverse = "❌ not set"
+		if p.PrimaryName != "" {
+			reverse = "❌ owner resolves to " + p.PrimaryName
+		}
+	}
+	fmt.Fprintf(w, "Reverse Resolution:\t%s\n", reverse)
+	if p.HumanityCheck != nil {
+		humanity := "❌ Not verified"
+		if *p.HumanityCheck {
+			humanity = "✅ Verified"
+		}
+		fmt.Fprintf(w, "Humanity Check:\t%s\n", humanity)
+	}
+	if len(p.Addresses) > 0 {
+		fmt.Fprintf(w, "Addresses:\n")
+		for _, ticker := range sortedKeys(p.Addresses) {
+			fmt.Fprintf(w, "  %s:\t%s\n", ticker, p.Addresses[ticker])
+		}
+	}
+	if p.IPFS != "" {
+		fmt.Fprintf(w, "Website (IPFS):\t%s\n", p.IPFS)
+	}
+	if p.Redirect != "" {
+		fmt.Fprintf(w, "Redirect:\t%s\n", p.Redirect)
+	}
+	if len(p.Socials) > 0 {
+		verified := map[string]bool{}
+		for _, platform := range p.VerifiedSocials {
+			verified[platform] = true
+		}
+		fmt.Fprintf(w, "Socials:\n")
+		for _, platform := range sortedKeys(p.Socials) {
+			mark := ""
+			if verified[platform] {
+				mark = " ✅"
+			}
+			fmt.Fprintf(w, "  %s:\t%s%s\n", platform, p.Socials[platform], mark)
+		}
+	}
+	if p.Error != "" {
+		fmt.Fprintf(w, "Profile Error:\t%s\n", p.Error)
+	}
+}
+
+func displayOwnerInfo(w *tabwriter.Writer, info *blockchain.OwnerInfo) {
+	if info == nil {
+		return
+	}
+	if info.Error != "" {
+		fmt.Fprintf(w, "Owner Type:\tunknown (%s)\n", info.Error)
+		return
+	}
+	kind := map[string]string{
+		blockchain.OwnerEOA:         "👤 Account (EOA)",
+		blockchain.OwnerSafe:        "🔐 Safe multi-sig",
+		blockchain.OwnerRegistrar:   "🏛️ Registrar contract",
+		blockchain.OwnerMarketplace: "🏪 Marketplace escrow",
+		blockchain.OwnerContract:    "📜 Contract",
+	}[info.Kind]
+	if info.Kind == blockchain.OwnerSafe && len(info.Signers) > 0 {
+		kind += fmt.Sprintf(" (%d of %d)", info.Threshold, len(info.Signers))
+	} else if info.Name != "" && info.Kind != blockchain.OwnerSafe {
+		kind += " (" + info.Name + ")"
+	}
+	fmt.Fprintf(w, "Owner Type:\t%s\n", kind)
+	if info.Note != "" {
+		fmt.Fprintf(w, "  Note:\t%s\n", info.Note)
+	}
+}
+
+// sortedKeys returns a map's keys in order so table output is stable
+// between runs.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DisplayPlan renders the external calls a dry run would make.
+func (f *Formatter) DisplayPlan(plan *analyzer.Plan) error {
+	return f.emit(func(c *Formatter) error { return c.displayPlan(plan) })
+}
+
+func (f *Formatter) displayPlan(plan *analyzer.Plan) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(plan)
+	case "jsonl":
+		return json.NewEncoder(f.out).Encode(plan)
+	case "table":
+		return f.displayPlanTable(plan)
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+func (f *Formatter) displayPlanTable(plan *analyzer.Plan) error {
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\n📋 DRY RUN: %s\n", plan.Domain)
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+	fmt.Fprintf(w, "Section\tProtocol\tTarget\tPurpose\n")
+	for _, c := range plan.Calls {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.Section, c.Protocol, c.Target, c.Purpose)
+	}
+	fmt.Fprintf(w, "\nNo requests were made.\n\n")
+	return w.Flush()
+}
+
+// DisplaySuggestions renders ranked name suggestions for keyword.
+func (f *Formatter) DisplaySuggestions(keyword string, suggestions []suggest.Suggestion) error {
+	return f.emit(func(c *Formatter) error { return c.displaySuggestions(keyword, suggestions) })
+}
+
+func (f *Formatter) displaySuggestions(keyword string, suggestions []suggest.Suggestion) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{"keyword": keyword, "suggestions": suggestions})
+	case "table":
+		w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "\n💡 SUGGESTIONS: %s\n", keyword)
+		fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+		fmt.Fprintf(w, "RANK\tDOMAIN\tVERDICT\tVALUE\tCONFIDENCE\tFROM\n")
+		for _, s := range suggestions {
+			verdict := "❓ unknown"
+			switch s.Verdict {
+			case analyzer.VerdictAvailable:
+				verdict = "✅ available"
+			case analyzer.VerdictTaken:
+				verdict = "❌ taken"
+			}
+			if s.Error != "" {
+				verdict = "❓ " + s.Error
+			}
+			value, confidence := "-", "-"
+			if s.Result != nil && s.Result.ValuationData != nil {
+				value = fmt.Sprintf("$%d", s.EstimatedValue)
+				confidence = s.Confidence
+			}
+			fmt.Fprintf(w, "#%d\t%s\t%s\t%s\t%s\t%s\n", s.Rank, s.Domain, verdict, value, confidence, s.Source)
+		}
+		if len(suggestions) == 0 {
+			fmt.Fprintf(w, "No suggestions.\n")
+		}
+		fmt.Fprintf(w, "\n")
+		return w.Flush()
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+// DisplayVersion renders the build metadata and support matrix.
+func (f *Formatter) DisplayVersion(info version.Info) error {
+	return f.emit(func(c *Formatter) error { return c.displayVersion(info) })
+}
+
+func (f *Formatter) displayVersion(info version.Info) error {
+	switch f.format {
+	case "json":
+		encoder := json.NewEncoder(f.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	case "table":
+		w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "\nD3 Domain Analysis Tool %s\n", info.Version)
+		fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+		if info.Commit != "" {
+			commit := info.Commit
+			if info.Modified {
+				commit += " (modified)"
+			}
+			fmt.Fprintf(w, "Commit:\t%s\n", commit)
+		}
+		if info.Date != "" {
+			fmt.Fprintf(w, "Built:\t%s\n", info.Date)
+		}
+		fmt.Fprintf(w, "Go:\t%s\n", info.GoVersion)
+		fmt.Fprintf(w, "Platform:\t%s\n", info.Platform)
+		fmt.Fprintf(w, "\n")
+		for _, feature := range info.Features {
+			fmt.Fprintf(w, "%s:\t%s\n", feature.Name, strings.Join(feature.Items, ", "))
+		}
+		fmt.Fprintf(w, "\n")
+		return w.Flush()
+	default:
+		return fmt.Errorf("unsupported format: %s", f.format)
+	}
+}
+
+// ago renders a section age coarsely: 45s, 12m, 2h, 3d.
+func ago(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
 }
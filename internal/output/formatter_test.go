@@ -0,0 +1,89 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"d3-domain-tool/internal/analyzer"
+)
+
+func TestDisplayConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFormatter("csv")
+	f.SetOutput(&buf)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := f.Display(&analyzer.Result{Domain: fmt.Sprintf("d%d.com", i)}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 51 || strings.Join(rows[0], ",") != strings.Join(DefaultCSVFields, ",") {
+		t.Fatalf("expected the header once, then a row per result:\n%v", rows)
+	}
+	seen := map[string]bool{}
+	for _, row := range rows[1:] {
+		seen[row[0]] = true
+	}
+	if len(seen) != 50 {
+		t.Errorf("got %d distinct domains, want 50", len(seen))
+	}
+}
+
+func TestRender(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFormatter("csv")
+	f.SetOutput(&buf)
+	data, err := f.Render(&analyzer.Result{Domain: "a.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Render wrote to the output: %q", buf.String())
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[1], "a.com,") {
+		t.Errorf("Render = %q, want the header and a row", data)
+	}
+}
+
+// TestDisplayFollowsStdout checks that a formatter built before a pager
+// takes over os.Stdout writes into the pager.
+func TestDisplayFollowsStdout(t *testing.T) {
+	f := NewFormatter("json")
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	paged := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		paged <- data
+	}()
+	err = f.Display(&analyzer.Result{Domain: "paged.com"})
+	os.Stdout = stdout
+	w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := <-paged; !bytes.Contains(data, []byte(`"paged.com"`)) {
+		t.Errorf("the pager got %q", data)
+	}
+}
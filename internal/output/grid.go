@@ -0,0 +1,116 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"d3-domain-tool/internal/analyzer"
+)
+
+// gridHeaders name the default summary columns (DefaultCSVFields).
+var gridHeaders = map[string]string{
+	"domain":                    "DOMAIN",
+	"verdict":                   "VERDICT",
+	"whois.registrar":           "REGISTRAR",
+	"whois.expiry_date":         "EXPIRY",
+	"valuation.estimated_value": "VALUE",
+	"valuation.cost_ratio":      "COST/VALUE",
+	"doma.is_tokenized":         "TOKENIZED",
+}
+
+// DisplayGrid renders one line per domain for multi-domain table runs.
+// The columns are the -fields paths, or DefaultCSVFields. Cells are plain
+// text so the columns stay aligned.
+func (f *Formatter) DisplayGrid(results []*analyzer.Result) error {
+	return f.emit(func(c *Formatter) error { return c.displayGrid(results) })
+}
+
+func (f *Formatter) displayGrid(results []*analyzer.Result) error {
+	paths := f.fields
+	if len(paths) == 0 {
+		paths = DefaultCSVFields
+	}
+
+	w := tabwriter.NewWriter(f.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\n🔍 D3 DOMAIN ANALYSIS (%d domains)\n", len(results))
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+
+	headers := make([]string, len(paths))
+	for i, p := range paths {
+		if h, ok := gridHeaders[p]; ok {
+			headers[i] = h
+		} else {
+			headers[i] = strings.ToUpper(p)
+		}
+	}
+	fmt.Fprintf(w, "%s\n", strings.Join(headers, "\t"))
+
+	degraded := 0
+	for _, result := range results {
+		fields, err := SelectFields(result, paths)
+		if err != nil {
+			return err
+		}
+		cells := make([]string, len(fields))
+		for i, field := range fields {
+			cells[i] = gridCell(field)
+			if cells[i] == "" {
+				cells[i] = "-"
+			}
+		}
+		if result.Degraded() {
+			cells[0] += " (!)"
+			degraded++
+		}
+		fmt.Fprintf(w, "%s\n", strings.Join(cells, "\t"))
+	}
+
+	if degraded > 0 {
+		fmt.Fprintf(w, "\n(!) %d result(s) degraded; use -detail=<domain> to see the failed checks\n", degraded)
+	}
+	fmt.Fprintf(w, "\n")
+	return w.Flush()
+}
+
+func gridCell(field Field) string {
+	switch field.Name {
+	case "whois.expiry_date":
+		if s, ok := field.Value.(string); ok && len(s) >= 10 {
+			return s[:10]
+		}
+	case "valuation.estimated_value":
+		if n, ok := field.Value.(float64); ok {
+			return "$" + thousands(int64(n))
+		}
+	case "valuation.cost_ratio":
+		if n, ok := field.Value.(float64); ok {
+			if n > 1 {
+				return fmt.Sprintf("%.0f%% underwater", n*100)
+			}
+			return fmt.Sprintf("%.1f%%", n*100)
+		}
+	case "doma.is_tokenized":
+		if b, ok := field.Value.(bool); ok {
+			if b {
+				return "yes"
+			}
+			return "no"
+		}
+	}
+	return fieldText(field.Value)
+}
+
+// thousands formats n with comma separators.
+func thousands(n int64) string {
+	s := fmt.Sprintf("%d", n)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const htmlHead = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>D3 Domain Analysis Report</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #1f2328; }
+pre { font-family: ui-monospace, Menlo, Consolas, monospace; background: #f6f8fa; padding: 1em; border-radius: 6px; overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>D3 Domain Analysis Report</h1>
+`
+
+// htmlSection renders a table report and writes it to the HTML document
+// as a preformatted block, so the page shows what a terminal would.
+func (f *Formatter) htmlSection(render func() error) error {
+	out := f.out
+	var buf bytes.Buffer
+	f.out, f.format = &buf, "table"
+	err := render()
+	f.out, f.format = out, "html"
+	if err != nil {
+		return err
+	}
+	f.preamble = htmlStart()
+	_, err = fmt.Fprintf(f.out, "<section>\n<pre>%s</pre>\n</section>\n", html.EscapeString(strings.Trim(buf.String(), "\n")))
+	return err
+}
+
+// htmlStart is the start of the HTML document, written before its first
+// section.
+func htmlStart() []byte {
+	return []byte(htmlHead + fmt.Sprintf("<p>Generated %s</p>\n", time.Now().Format("2006-01-02 15:04:05 MST")))
+}
+
+// Close completes the output: for the html format it ends the document.
+// Other formats need no closing.
+func (f *Formatter) Close() error {
+	if f.format != "html" {
+		return nil
+	}
+	f.w.mu.Lock()
+	defer f.w.mu.Unlock()
+	var doc []byte
+	if !f.w.begun {
+		f.w.begun = true
+		doc = htmlStart()
+	}
+	_, err := f.w.output().Write(append(doc, "</body>\n</html>\n"...))
+	return err
+}
+
+// FormatFor infers the output format from a file name's extension.
+func FormatFor(path string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json", true
+	case ".jsonl", ".ndjson":
+		return "jsonl", true
+	case ".csv":
+		return "csv", true
+	case ".html", ".htm":
+		return "html", true
+	case ".txt":
+		return "table", true
+	case ".stix":
+		return "stix", true
+	case ".cef":
+		return "cef", true
+	case ".leef":
+		return "leef", true
+	}
+	return "", false
+}
@@ -0,0 +1,44 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"d3-domain-tool/internal/analyzer"
+)
+
+func TestHTML(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFormatter("html")
+	f.SetOutput(&buf)
+	if err := f.Display(&analyzer.Result{Domain: "<script>.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	if !strings.HasPrefix(page, "<!DOCTYPE html>") || !strings.HasSuffix(page, "</html>\n") {
+		t.Errorf("not a complete document:\n%s", page)
+	}
+	if !strings.Contains(page, "D3 DOMAIN ANALYSIS REPORT") || !strings.Contains(page, "&lt;script&gt;.com") {
+		t.Errorf("report missing or unescaped:\n%s", page)
+	}
+}
+
+func TestFormatFor(t *testing.T) {
+	for path, want := range map[string]string{
+		"report.json": "json",
+		"out/r.CSV":   "csv",
+		"report.html": "html",
+		"report.txt":  "table",
+		"report.stix": "stix",
+		"r.ndjson":    "jsonl",
+		"report":      "",
+	} {
+		if got, _ := FormatFor(path); got != want {
+			t.Errorf("FormatFor(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
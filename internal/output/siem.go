@@ -0,0 +1,119 @@
+package output
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/findings"
+	"d3-domain-tool/internal/version"
+)
+
+const (
+	siemVendor  = "D3"
+	siemProduct = "Domain Analysis Tool"
+)
+
+// cefSeverity maps the findings severities onto the CEF scale, 0 to 10.
+var cefSeverity = map[findings.Severity]int{
+	findings.Info:     1,
+	findings.Low:      3,
+	findings.Medium:   5,
+	findings.High:     8,
+	findings.Critical: 10,
+}
+
+// siemEvent is one line of SIEM output.
+type siemEvent struct {
+	findings.Finding
+	// cef is the CEF severity; the analysis event is 0.
+	cef int
+}
+
+// siemEvents returns one event per finding, after an analysis event that
+// carries the verdict, so domains without findings still show up.
+func siemEvents(result *analyzer.Result) []siemEvent {
+	summary := []string{"verdict " + result.Verdict()}
+	if w := result.WhoisData; w != nil {
+		if w.Registrar != "" {
+			summary = append(summary, "registrar "+w.Registrar)
+		}
+		if w.ExpiryDate != nil {
+			summary = append(summary, "expires "+w.ExpiryDate.Format("2006-01-02"))
+		}
+	}
+	if v := result.ValuationData; v != nil && v.EstimatedValue > 0 {
+		summary = append(summary, fmt.Sprintf("value $%d", v.EstimatedValue))
+	}
+	events := []siemEvent{{Finding: findings.Finding{
+		Module: "analysis",
+		ID:     "domain-analyzed",
+		Name:   "Domain analyzed",
+		Detail: strings.Join(summary, ", "),
+	}}}
+	for _, f := range result.Findings {
+		events = append(events, siemEvent{Finding: f, cef: cefSeverity[f.Severity]})
+	}
+	return events
+}
+
+func eventTime(result *analyzer.Result) time.Time {
+	if result.Timestamp.IsZero() {
+		return time.Now()
+	}
+	return result.Timestamp
+}
+
+// displayCEF writes ArcSight Common Event Format lines, one per event.
+func (f *Formatter) displayCEF(result *analyzer.Result) error {
+	rt := strconv.FormatInt(eventTime(result).UnixMilli(), 10)
+	ver := cefHeader(version.Get().Version)
+	for _, e := range siemEvents(result) {
+		_, err := fmt.Fprintf(f.out, "CEF:0|%s|%s|%s|%s|%s|%d|rt=%s dhost=%s cat=%s msg=%s\n",
+			siemVendor, siemProduct, ver, cefHeader(e.ID), cefHeader(e.Name), e.cef,
+			rt, cefValue(result.Domain), cefValue(e.Module), cefValue(e.Detail))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// displayLEEF writes IBM QRadar Log Event Extended Format 1.0 lines, one
+// per event, with tab-separated attributes.
+func (f *Formatter) displayLEEF(result *analyzer.Result) error {
+	devTime := eventTime(result).UTC().Format("Jan 02 2006 15:04:05")
+	ver := leefValue(version.Get().Version)
+	for _, e := range siemEvents(result) {
+		sev := e.cef
+		if sev < 1 {
+			// LEEF severities start at 1.
+			sev = 1
+		}
+		_, err := fmt.Fprintf(f.out, "LEEF:1.0|%s|%s|%s|%s|devTime=%s\tsev=%d\tcat=%s\tdomain=%s\tname=%s\tmsg=%s\n",
+			siemVendor, siemProduct, ver, leefValue(e.ID),
+			devTime, sev, leefValue(e.Module), leefValue(result.Domain), leefValue(e.Name), leefValue(e.Detail))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cefHeader escapes a CEF header field: backslashes and pipes.
+func cefHeader(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "\n", " ", "\r", " ").Replace(s)
+}
+
+// cefValue escapes a CEF extension value: backslashes, equals signs and
+// line breaks.
+func cefValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "=", `\=`, "\n", `\n`, "\r", `\r`).Replace(s)
+}
+
+// leefValue keeps a value on one line and out of the delimiters.
+func leefValue(s string) string {
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ", "|", "/").Replace(s)
+}
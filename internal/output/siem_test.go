@@ -0,0 +1,53 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/findings"
+)
+
+func TestSIEM(t *testing.T) {
+	result := &analyzer.Result{
+		Domain:    "example.com",
+		Timestamp: time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC),
+		Findings: []findings.Finding{
+			findings.New("seizure", findings.DomainSeized, "Domain seized by law enforcement", "NS ns1.seized.gov; banner a=b|c"),
+		},
+	}
+
+	var buf bytes.Buffer
+	f := NewFormatter("cef")
+	f.SetOutput(&buf)
+	if err := f.Display(result); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("CEF lines:\n%s", buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "CEF:0|D3|Domain Analysis Tool|") || !strings.Contains(lines[0], "|domain-analyzed|Domain analyzed|0|rt=1791972000000 dhost=example.com cat=analysis ") {
+		t.Errorf("analysis event = %s", lines[0])
+	}
+	if !strings.Contains(lines[1], "|domain-seized|Domain seized by law enforcement|10|") || !strings.HasSuffix(lines[1], `msg=NS ns1.seized.gov; banner a\=b|c`) {
+		t.Errorf("finding event = %s", lines[1])
+	}
+
+	buf.Reset()
+	f = NewFormatter("leef")
+	f.SetOutput(&buf)
+	if err := f.Display(result); err != nil {
+		t.Fatal(err)
+	}
+	lines = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("LEEF lines:\n%s", buf.String())
+	}
+	want := "|domain-seized|devTime=Oct 14 2026 10:00:00\tsev=10\tcat=seizure\tdomain=example.com\tname=Domain seized by law enforcement\tmsg=NS ns1.seized.gov; banner a=b/c"
+	if !strings.HasPrefix(lines[1], "LEEF:1.0|D3|Domain Analysis Tool|") || !strings.HasSuffix(lines[1], want) {
+		t.Errorf("LEEF event = %q", lines[1])
+	}
+}
@@ -0,0 +1,232 @@
+package output
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+)
+
+// stixNamespace is the UUIDv5 namespace STIX 2.1 defines for
+// deterministic cyber-observable identifiers,
+// 00abedb4-aa42-466c-9c01-fed23315a9b7.
+var stixNamespace = [16]byte{0x00, 0xab, 0xed, 0xb4, 0xaa, 0x42, 0x46, 0x6c, 0x9c, 0x01, 0xfe, 0xd2, 0x33, 0x15, 0xa9, 0xb7}
+
+// stixToolName names the identity that creates the relationships.
+const stixToolName = "D3 Domain Analysis Tool"
+
+// stixObject holds the properties of every STIX object the export uses:
+// identity, domain-name, ipv4-addr, ipv6-addr, x509-certificate and
+// relationship.
+type stixObject struct {
+	Type             string            `json:"type"`
+	SpecVersion      string            `json:"spec_version"`
+	ID               string            `json:"id"`
+	Created          string            `json:"created,omitempty"`
+	Modified         string            `json:"modified,omitempty"`
+	CreatedByRef     string            `json:"created_by_ref,omitempty"`
+	Name             string            `json:"name,omitempty"`
+	IdentityClass    string            `json:"identity_class,omitempty"`
+	Value            string            `json:"value,omitempty"`
+	Hashes           map[string]string `json:"hashes,omitempty"`
+	SerialNumber     string            `json:"serial_number,omitempty"`
+	Issuer           string            `json:"issuer,omitempty"`
+	Subject          string            `json:"subject,omitempty"`
+	NotBefore        string            `json:"validity_not_before,omitempty"`
+	NotAfter         string            `json:"validity_not_after,omitempty"`
+	Extensions       *stixX509Ext      `json:"x509_v3_extensions,omitempty"`
+	RelationshipType string            `json:"relationship_type,omitempty"`
+	Description      string            `json:"description,omitempty"`
+	SourceRef        string            `json:"source_ref,omitempty"`
+	TargetRef        string            `json:"target_ref,omitempty"`
+}
+
+type stixX509Ext struct {
+	SubjectAltName string `json:"subject_alternative_name,omitempty"`
+}
+
+type stixBundle struct {
+	Type    string        `json:"type"`
+	ID      string        `json:"id"`
+	Objects []*stixObject `json:"objects"`
+}
+
+// stixBuilder collects the objects of one bundle, each once.
+type stixBuilder struct {
+	created  string
+	identity string
+	objects  []*stixObject
+	seen     map[string]bool
+}
+
+// STIXBundle converts a result into a STIX 2.1 bundle: the domain, the
+// addresses and names it resolves to, its nameservers and mail exchangers
+// and its TLS certificate as cyber observables, linked by relationships.
+// Observable IDs are deterministic, so exports of the same domain merge
+// in a TI platform.
+func STIXBundle(result *analyzer.Result) ([]byte, error) {
+	b := &stixBuilder{
+		created: stixTime(result.Timestamp),
+		seen:    map[string]bool{},
+	}
+	identity := &stixObject{
+		Type:          "identity",
+		ID:            "identity--" + uuid5(stixToolName),
+		Name:          stixToolName,
+		IdentityClass: "system",
+		Created:       b.created,
+		Modified:      b.created,
+	}
+	b.identity = identity.ID
+	b.add(identity)
+
+	domain := b.observable("domain-name", result.Domain)
+	if dns := result.DNSAvailability; dns != nil {
+		for _, rec := range dns.Answers {
+			owner := domain
+			if name := strings.TrimSuffix(strings.ToLower(rec.Name), "."); name != "" && name != result.Domain {
+				owner = b.observable("domain-name", name)
+			}
+			value := strings.TrimSuffix(strings.ToLower(rec.Value), ".")
+			switch rec.Type {
+			case "A", "AAAA":
+				b.relate(owner, "resolves-to", b.address(value), "")
+			case "CNAME":
+				b.relate(owner, "resolves-to", b.observable("domain-name", value), "")
+			case "NS":
+				b.relate(owner, "related-to", b.observable("domain-name", value), "Authoritative nameserver")
+			case "MX":
+				b.relate(owner, "related-to", b.observable("domain-name", value), "Mail exchanger")
+			}
+		}
+	}
+	if whois := result.WhoisData; whois != nil {
+		for _, ns := range whois.NameServers {
+			ns = strings.TrimSuffix(strings.ToLower(ns), ".")
+			b.relate(domain, "related-to", b.observable("domain-name", ns), "Authoritative nameserver")
+		}
+	}
+	if p := result.DNSProvider; p != nil {
+		for _, ns := range p.Details {
+			host := b.observable("domain-name", strings.TrimSuffix(strings.ToLower(ns.Host), "."))
+			b.relate(domain, "related-to", host, "Authoritative nameserver")
+			for _, ip := range ns.IPs {
+				b.relate(host, "resolves-to", b.address(ip), "")
+			}
+		}
+	}
+	if web := result.WebSecurity; web != nil && web.Certificate != nil {
+		cert := web.Certificate
+		obj := &stixObject{
+			Type:         "x509-certificate",
+			Hashes:       map[string]string{"SHA-256": cert.SHA256},
+			SerialNumber: cert.SerialNumber,
+			Issuer:       cert.Issuer,
+			Subject:      cert.Subject,
+			NotBefore:    stixTime(cert.NotBefore),
+			NotAfter:     stixTime(cert.NotAfter),
+		}
+		if len(cert.DNSNames) > 0 {
+			obj.Extensions = &stixX509Ext{SubjectAltName: "DNS:" + strings.Join(cert.DNSNames, ", DNS:")}
+		}
+		obj.ID = "x509-certificate--" + uuid5(canonical(map[string]interface{}{"hashes": obj.Hashes}))
+		b.add(obj)
+		b.relate(domain, "related-to", obj.ID, "TLS certificate served by the domain")
+	}
+
+	return json.MarshalIndent(stixBundle{Type: "bundle", ID: "bundle--" + uuid4(), Objects: b.objects}, "", "  ")
+}
+
+func (b *stixBuilder) add(obj *stixObject) {
+	if b.seen[obj.ID] {
+		return
+	}
+	b.seen[obj.ID] = true
+	obj.SpecVersion = "2.1"
+	b.objects = append(b.objects, obj)
+}
+
+// observable adds a cyber observable identified by its value and returns
+// its ID.
+func (b *stixBuilder) observable(typ, value string) string {
+	id := typ + "--" + uuid5(canonical(map[string]interface{}{"value": value}))
+	b.add(&stixObject{Type: typ, ID: id, Value: value})
+	return id
+}
+
+// address adds an ipv4-addr or ipv6-addr observable.
+func (b *stixBuilder) address(ip string) string {
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		return b.observable("ipv6-addr", ip)
+	}
+	return b.observable("ipv4-addr", ip)
+}
+
+// relate adds a relationship; the same link is added once.
+func (b *stixBuilder) relate(source, typ, target, description string) {
+	b.add(&stixObject{
+		Type:             "relationship",
+		ID:               "relationship--" + uuid5(source+" "+typ+" "+target),
+		Created:          b.created,
+		Modified:         b.created,
+		CreatedByRef:     b.identity,
+		RelationshipType: typ,
+		Description:      description,
+		SourceRef:        source,
+		TargetRef:        target,
+	})
+}
+
+func (f *Formatter) displaySTIX(result *analyzer.Result) error {
+	data, err := STIXBundle(result)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f.out, "%s\n", data)
+	return err
+}
+
+// canonical serializes ID-contributing properties the way STIX requires
+// for deterministic IDs: JSON with sorted keys and no whitespace, which is
+// what encoding/json writes for a map.
+func canonical(props map[string]interface{}) string {
+	data, _ := json.Marshal(props)
+	return string(data)
+}
+
+// stixTime formats t as a STIX timestamp, UTC with milliseconds.
+func stixTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// uuid5 returns the name-based UUID of name in the STIX namespace.
+func uuid5(name string) string {
+	h := sha1.New()
+	h.Write(stixNamespace[:])
+	h.Write([]byte(name))
+	sum := h.Sum(nil)
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return formatUUID(sum[:16])
+}
+
+// uuid4 returns a random UUID.
+func uuid4() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b)
+}
+
+func formatUUID(b []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,75 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/checker"
+	"d3-domain-tool/internal/webaudit"
+	"d3-domain-tool/internal/whois"
+)
+
+func TestSTIXBundle(t *testing.T) {
+	result := &analyzer.Result{
+		Domain:    "example.com",
+		Timestamp: time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC),
+		DNSAvailability: &checker.DNSResult{Answers: []checker.Record{
+			{Name: "example.com.", Type: "A", Value: "93.184.215.14"},
+			{Name: "example.com.", Type: "AAAA", Value: "2606:2800:21f:cb07:6820:80da:af6b:8b2c"},
+			{Name: "example.com.", Type: "NS", Value: "a.iana-servers.net."},
+		}},
+		WhoisData: &whois.Result{NameServers: []string{"A.IANA-SERVERS.NET", "b.iana-servers.net"}},
+		WebSecurity: &webaudit.Result{Certificate: &webaudit.Certificate{
+			Subject:  "CN=example.com",
+			Issuer:   "CN=Test CA",
+			SHA256:   "ab",
+			DNSNames: []string{"example.com", "www.example.com"},
+		}},
+	}
+	data, err := STIXBundle(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bundle struct {
+		Type    string
+		Objects []map[string]interface{}
+	}
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		t.Fatal(err)
+	}
+	if bundle.Type != "bundle" {
+		t.Fatalf("type = %q", bundle.Type)
+	}
+
+	types := map[string]int{}
+	links := map[string]bool{}
+	for _, obj := range bundle.Objects {
+		types[obj["type"].(string)]++
+		if obj["spec_version"] != "2.1" {
+			t.Errorf("%v has no spec_version", obj["id"])
+		}
+		if obj["type"] == "relationship" {
+			links[obj["relationship_type"].(string)+" "+obj["target_ref"].(string)[:9]] = true
+		}
+	}
+	// The domain and both nameservers; the NS answer and the WHOIS
+	// nameserver are the same object.
+	want := map[string]int{"identity": 1, "domain-name": 3, "ipv4-addr": 1, "ipv6-addr": 1, "x509-certificate": 1, "relationship": 5}
+	for typ, n := range want {
+		if types[typ] != n {
+			t.Errorf("%d %s objects, want %d", types[typ], typ, n)
+		}
+	}
+	for _, link := range []string{"resolves-to ipv4-addr", "resolves-to ipv6-addr", "related-to domain-na", "related-to x509-cert"} {
+		if !links[link] {
+			t.Errorf("missing relationship %q", link)
+		}
+	}
+
+	// The deterministic ID of the STIX 2.1 specification's UUIDv5 scheme.
+	if id := bundle.Objects[1]["id"]; id != "domain-name--bedb4899-d24b-5401-bc86-8f6b4cc18ec7" {
+		t.Errorf("domain id = %v", id)
+	}
+}
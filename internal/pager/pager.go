@@ -0,0 +1,75 @@
+// Package pager sends long terminal output through the user's pager, the
+// way git does.
+package pager
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// DefaultPager is used when $PAGER is unset. With LESS=FRX (set unless the
+// user has their own LESS) less exits immediately when the output fits on
+// one screen, keeps colours and leaves the output on the terminal.
+const DefaultPager = "less"
+
+// Start redirects os.Stdout into a pager process when stdout is a terminal.
+// The returned function flushes the output, waits for the pager to exit
+// and restores os.Stdout; it is a no-op when no pager was started.
+func Start() func() {
+	command := pagerCommand()
+	if command == "" || !isTerminal(os.Stdout) {
+		return func() {}
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return func() {}
+	}
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = r
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	if err := cmd.Start(); err != nil {
+		r.Close()
+		w.Close()
+		return func() {}
+	}
+	r.Close()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	return func() {
+		os.Stdout = stdout
+		w.Close()
+		cmd.Wait()
+	}
+}
+
+// pagerCommand returns the pager to run, or "" when paging is disabled
+// with PAGER=cat, an empty PAGER or a dumb terminal.
+func pagerCommand() string {
+	if os.Getenv("TERM") == "dumb" {
+		return ""
+	}
+	command, ok := os.LookupEnv("PAGER")
+	if !ok {
+		if _, err := exec.LookPath(DefaultPager); err != nil {
+			return ""
+		}
+		return DefaultPager
+	}
+	if command = strings.TrimSpace(command); command == "cat" {
+		return ""
+	}
+	return command
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,45 @@
+package pager
+
+import (
+	"os"
+	"testing"
+)
+
+func TestPagerCommand(t *testing.T) {
+	t.Setenv("TERM", "xterm")
+
+	t.Setenv("PAGER", "more -s")
+	if got := pagerCommand(); got != "more -s" {
+		t.Errorf("expected $PAGER to be used, got %q", got)
+	}
+
+	t.Setenv("PAGER", "cat")
+	if got := pagerCommand(); got != "" {
+		t.Errorf("expected PAGER=cat to disable paging, got %q", got)
+	}
+
+	t.Setenv("PAGER", "less")
+	t.Setenv("TERM", "dumb")
+	if got := pagerCommand(); got != "" {
+		t.Errorf("expected a dumb terminal to disable paging, got %q", got)
+	}
+}
+
+func TestStart_NotATerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	stop := Start()
+	if os.Stdout != w {
+		t.Error("expected no pager when stdout is a pipe")
+	}
+	stop()
+}
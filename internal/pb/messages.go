@@ -0,0 +1,189 @@
+package pb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/findings"
+)
+
+// MarshalResult encodes r as a Result message.
+func MarshalResult(r *analyzer.Result) ([]byte, error) {
+	var b buffer
+	if err := result(&b, r); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalDelivery encodes a Delivery message: the result of the index-th
+// of total domains, or why it has none.
+func MarshalDelivery(index, total int, domain string, r *analyzer.Result, errMsg string) ([]byte, error) {
+	var b buffer
+	b.int64(1, int64(index))
+	b.int64(2, int64(total))
+	b.string(3, domain)
+	if r != nil {
+		var sub buffer
+		if err := result(&sub, r); err != nil {
+			return nil, err
+		}
+		b.bytes(4, sub)
+	}
+	b.string(5, errMsg)
+	return b, nil
+}
+
+func result(b *buffer, r *analyzer.Result) error {
+	full, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	b.string(1, r.Domain)
+	b.string(2, r.Input)
+	b.timestamp(3, r.Timestamp)
+	b.string(4, r.Status)
+	b.string(5, r.Verdict())
+	if d := r.DNSAvailability; d != nil {
+		b.message(6, func(m *buffer) {
+			m.bool(1, d.Available)
+			m.string(2, d.TLD)
+			m.bool(3, d.HasRecords)
+			m.bool(4, d.NXDomain)
+			m.strings(5, d.RecordTypes)
+			m.timestamp(6, d.CheckedAt)
+			m.strings(7, d.Warnings)
+			m.string(8, d.Error)
+		})
+	}
+	if c := r.BlockchainData; c != nil {
+		b.message(7, func(m *buffer) {
+			m.optionalBool(1, c.Available)
+			m.string(2, c.Note)
+			m.string(3, c.Type)
+			m.string(4, c.Owner)
+			m.string(5, c.Resolver)
+			keys := make([]string, 0, len(c.Records))
+			for k := range c.Records {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				m.message(6, func(e *buffer) {
+					e.string(1, k)
+					e.string(2, c.Records[k])
+				})
+			}
+			m.optionalTimestamp(7, c.ExpiryDate)
+			m.timestamp(8, c.CheckedAt)
+			m.string(9, c.Error)
+		})
+	}
+	if d := r.DomaData; d != nil {
+		b.message(8, func(m *buffer) {
+			m.string(1, d.Status)
+			m.optionalBool(2, d.IsTokenized)
+			m.string(3, d.Note)
+			m.string(4, d.TokenizationChain)
+			m.timestamp(5, d.CheckedAt)
+			m.string(6, d.Error)
+		})
+	}
+	if w := r.WhoisData; w != nil {
+		b.message(9, func(m *buffer) {
+			m.bool(1, w.Available)
+			m.string(2, w.Registrar)
+			m.optionalTimestamp(3, w.RegistrationDate)
+			m.optionalTimestamp(4, w.ExpiryDate)
+			m.strings(5, w.NameServers)
+			m.strings(6, w.Status)
+			m.optionalTimestamp(7, w.UpdatedDate)
+			m.timestamp(8, w.CheckedAt)
+			m.strings(9, w.Warnings)
+			m.string(10, w.Error)
+		})
+	}
+	if v := r.ValuationData; v != nil {
+		b.message(10, func(m *buffer) {
+			m.int64(1, int64(v.EstimatedValue))
+			m.string(2, v.Currency)
+			m.string(3, v.Confidence)
+			m.string(4, v.Reasoning)
+			m.optionalDouble(5, v.RenewalCost)
+			m.optionalDouble(6, v.CostRatio)
+			m.bool(7, v.Underwater)
+		})
+	}
+	for _, e := range r.SectionErrors {
+		b.message(11, func(m *buffer) {
+			m.string(1, e.Section)
+			m.string(2, e.Error)
+		})
+	}
+	for _, w := range r.Warnings {
+		b.message(12, func(m *buffer) {
+			m.string(1, w.Section)
+			m.string(2, w.Warning)
+		})
+	}
+	for _, f := range r.Findings {
+		b.message(13, finding(f))
+	}
+	for _, f := range r.Suppressed {
+		b.message(14, finding(f))
+	}
+	b.bytes(15, full)
+	return nil
+}
+
+func finding(f findings.Finding) func(*buffer) {
+	return func(m *buffer) {
+		m.string(1, f.Module)
+		m.string(2, f.ID)
+		m.int64(3, int64(f.Severity))
+		m.string(4, f.Name)
+		m.string(5, f.Detail)
+	}
+}
+
+// UnmarshalAnalyzeRequest decodes an AnalyzeRequest and returns its domain.
+func UnmarshalAnalyzeRequest(data []byte) (string, error) {
+	list, err := stringFields(data, 1)
+	if err != nil || len(list) == 0 {
+		return "", err
+	}
+	// The last value of a singular field wins.
+	return list[len(list)-1], nil
+}
+
+// UnmarshalAnalyzeStreamRequest decodes an AnalyzeStreamRequest and
+// returns its domains.
+func UnmarshalAnalyzeStreamRequest(data []byte) ([]string, error) {
+	return stringFields(data, 1)
+}
+
+// stringFields returns the values of string field num, ignoring other
+// fields as unknown.
+func stringFields(data []byte, num int) ([]string, error) {
+	list, err := fields(data)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for _, f := range list {
+		if f.num != num {
+			continue
+		}
+		if f.wire != wireBytes {
+			return nil, fmt.Errorf("field %d: wire type %d, want a string", num, f.wire)
+		}
+		if !utf8.Valid(f.data) {
+			return nil, fmt.Errorf("field %d: invalid UTF-8", num)
+		}
+		values = append(values, string(f.data))
+	}
+	return values, nil
+}
@@ -0,0 +1,101 @@
+package pb
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/blockchain"
+	"d3-domain-tool/internal/findings"
+	"d3-domain-tool/internal/whois"
+)
+
+// decode returns the fields of a message by number.
+func decode(t *testing.T, data []byte) map[int][]field {
+	t.Helper()
+	list, err := fields(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byNum := map[int][]field{}
+	for _, f := range list {
+		byNum[f.num] = append(byNum[f.num], f)
+	}
+	return byNum
+}
+
+func TestMarshalResult(t *testing.T) {
+	expiry := time.Date(2027, 3, 1, 12, 0, 0, 500, time.UTC)
+	available := false
+	r := &analyzer.Result{
+		Domain:         "example.com",
+		Status:         "ok",
+		WhoisData:      &whois.Result{Registrar: "Example Registrar", ExpiryDate: &expiry, NameServers: []string{"a.ns", "b.ns"}},
+		BlockchainData: &blockchain.Result{Available: &available, Records: map[string]string{"b": "2", "a": "1"}},
+		Findings:       []findings.Finding{findings.New("whois", findings.RegistrationExpired, "Registration expired", "")},
+	}
+	data, err := MarshalResult(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := decode(t, data)
+	if got := string(msg[1][0].data); got != "example.com" {
+		t.Errorf("domain = %q", got)
+	}
+	if got := string(msg[5][0].data); got != analyzer.VerdictTaken {
+		t.Errorf("verdict = %q", got)
+	}
+	if _, ok := msg[3]; ok {
+		t.Error("zero timestamp was encoded")
+	}
+
+	w := decode(t, msg[9][0].data)
+	if got := string(w[2][0].data); got != "Example Registrar" {
+		t.Errorf("registrar = %q", got)
+	}
+	if len(w[5]) != 2 {
+		t.Errorf("name servers = %v", w[5])
+	}
+	ts := decode(t, w[4][0].data)
+	if ts[1][0].varint != uint64(expiry.Unix()) || ts[2][0].varint != 500 {
+		t.Errorf("expiry = %v", ts)
+	}
+
+	c := decode(t, msg[7][0].data)
+	if f := c[1]; len(f) != 1 || f[0].varint != 0 {
+		t.Errorf("an optional false must be encoded: %v", f)
+	}
+	if entry := decode(t, c[6][0].data); string(entry[1][0].data) != "a" || string(entry[2][0].data) != "1" {
+		t.Errorf("records are not sorted by key: %v", entry)
+	}
+
+	f := decode(t, msg[13][0].data)
+	if got := findings.Severity(f[3][0].varint); got != findings.High {
+		t.Errorf("severity = %v", got)
+	}
+
+	var full analyzer.Result
+	if err := json.Unmarshal(msg[15][0].data, &full); err != nil || full.Domain != "example.com" {
+		t.Errorf("result_json = %s (%v)", msg[15][0].data, err)
+	}
+}
+
+func TestUnmarshalRequests(t *testing.T) {
+	var b buffer
+	b.varint(7, 42) // unknown fields are skipped
+	b.string(1, "a.com")
+	b.string(1, "b.com")
+
+	domain, err := UnmarshalAnalyzeRequest(b)
+	if err != nil || domain != "b.com" {
+		t.Errorf("UnmarshalAnalyzeRequest = %q, %v", domain, err)
+	}
+	domains, err := UnmarshalAnalyzeStreamRequest(b)
+	if err != nil || len(domains) != 2 || domains[0] != "a.com" {
+		t.Errorf("UnmarshalAnalyzeStreamRequest = %q, %v", domains, err)
+	}
+	if _, err := UnmarshalAnalyzeRequest(b[:len(b)-2]); err == nil {
+		t.Error("expected an error for a truncated message")
+	}
+}
@@ -0,0 +1,174 @@
+// Package pb encodes analysis results as the protocol buffer messages of
+// proto/d3/v1/analysis.proto, for the gRPC API. Only the messages the
+// service sends and receives are supported, so the encoding is written
+// out here rather than generated.
+package pb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// buffer appends fields to an encoded message. Following proto3, fields
+// holding their zero value are left out, except the optional ones.
+type buffer []byte
+
+func (b *buffer) tag(field, wire int) {
+	*b = binary.AppendUvarint(*b, uint64(field)<<3|uint64(wire))
+}
+
+func (b *buffer) varint(field int, v uint64) {
+	if v != 0 {
+		b.tag(field, wireVarint)
+		*b = binary.AppendUvarint(*b, v)
+	}
+}
+
+func (b *buffer) int64(field int, v int64) {
+	b.varint(field, uint64(v))
+}
+
+func (b *buffer) bool(field int, v bool) {
+	if v {
+		b.varint(field, 1)
+	}
+}
+
+// optionalBool encodes v when it is set, even to false.
+func (b *buffer) optionalBool(field int, v *bool) {
+	if v != nil {
+		b.tag(field, wireVarint)
+		if *v {
+			*b = append(*b, 1)
+		} else {
+			*b = append(*b, 0)
+		}
+	}
+}
+
+// optionalDouble encodes v when it is set, even to zero.
+func (b *buffer) optionalDouble(field int, v *float64) {
+	if v != nil {
+		b.tag(field, wireFixed64)
+		*b = binary.LittleEndian.AppendUint64(*b, math.Float64bits(*v))
+	}
+}
+
+func (b *buffer) bytes(field int, v []byte) {
+	if len(v) > 0 {
+		b.tag(field, wireBytes)
+		*b = binary.AppendUvarint(*b, uint64(len(v)))
+		*b = append(*b, v...)
+	}
+}
+
+func (b *buffer) string(field int, v string) {
+	b.bytes(field, []byte(v))
+}
+
+func (b *buffer) strings(field int, list []string) {
+	for _, v := range list {
+		// Elements of a repeated field are kept even when empty.
+		b.tag(field, wireBytes)
+		*b = binary.AppendUvarint(*b, uint64(len(v)))
+		*b = append(*b, v...)
+	}
+}
+
+// message encodes the submessage m writes; a nil m leaves it unset, while
+// an empty message is still encoded.
+func (b *buffer) message(field int, m func(*buffer)) {
+	if m == nil {
+		return
+	}
+	var sub buffer
+	m(&sub)
+	b.tag(field, wireBytes)
+	*b = binary.AppendUvarint(*b, uint64(len(sub)))
+	*b = append(*b, sub...)
+}
+
+// timestamp encodes t as a google.protobuf.Timestamp, leaving the field
+// unset for the zero time.
+func (b *buffer) timestamp(field int, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	b.message(field, func(m *buffer) {
+		m.int64(1, t.Unix())
+		m.int64(2, int64(t.Nanosecond()))
+	})
+}
+
+func (b *buffer) optionalTimestamp(field int, t *time.Time) {
+	if t != nil {
+		b.timestamp(field, *t)
+	}
+}
+
+var errTruncated = errors.New("truncated message")
+
+// field is one decoded field of a message.
+type field struct {
+	num  int
+	wire int
+	// varint holds a varint's value, data a length-delimited value.
+	varint uint64
+	data   []byte
+}
+
+// fields decodes the fields of a message in order. Fixed-size fields are
+// skipped, since no message decoded here has any.
+func fields(data []byte) ([]field, error) {
+	var list []field
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errTruncated
+		}
+		data = data[n:]
+		f := field{num: int(key >> 3), wire: int(key & 7)}
+		if f.num == 0 {
+			return nil, fmt.Errorf("invalid field number 0")
+		}
+		switch f.wire {
+		case wireVarint:
+			if f.varint, n = binary.Uvarint(data); n <= 0 {
+				return nil, errTruncated
+			}
+			data = data[n:]
+		case wireFixed64, wireFixed32:
+			size := 8
+			if f.wire == wireFixed32 {
+				size = 4
+			}
+			if len(data) < size {
+				return nil, errTruncated
+			}
+			data = data[size:]
+			continue
+		case wireBytes:
+			size, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < size {
+				return nil, errTruncated
+			}
+			f.data = data[n : n+int(size)]
+			data = data[n+int(size):]
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d", f.wire)
+		}
+		list = append(list, f)
+	}
+	return list, nil
+}
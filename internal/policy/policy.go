@@ -0,0 +1,238 @@
+// Package policy evaluates domains against an organization's acceptable
+// domain rules, e.g. as a CI gate for infrastructure-as-code provisioning.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/checker"
+	"d3-domain-tool/internal/whois"
+)
+
+// Policy is the set of rules a domain must satisfy. Zero values disable a
+// rule.
+type Policy struct {
+	Name string `json:"name"`
+	// AllowedTLDs lists the permitted TLDs, e.g. ["com", "io"].
+	AllowedTLDs []string `json:"allowed_tlds,omitempty"`
+	// NamePattern is a regular expression the registered label (the name
+	// without its TLD) must match, e.g. "^acme-[a-z0-9-]+$".
+	NamePattern string `json:"name_pattern,omitempty"`
+	// MaxLength is the longest permitted label.
+	MaxLength int `json:"max_length,omitempty"`
+	// RequireDNSSEC requires a DS record in the parent zone.
+	RequireDNSSEC bool `json:"require_dnssec,omitempty"`
+	// RequirePrivacy requires the WHOIS registrant to be redacted or
+	// behind a privacy service.
+	RequirePrivacy bool `json:"require_privacy,omitempty"`
+}
+
+// Load reads a JSON policy file.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %v", err)
+	}
+	var p Policy
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %v", path, err)
+	}
+	if p.Name == "" {
+		p.Name = path
+	}
+	return &p, nil
+}
+
+// Finding statuses.
+const (
+	Pass = "pass"
+	Fail = "fail"
+	// Skip means the rule could not be evaluated, e.g. a lookup failed or
+	// the registry does not publish the data.
+	Skip = "skip"
+)
+
+// Rule names.
+const (
+	RuleTLD     = "allowed-tld"
+	RuleName    = "naming-convention"
+	RuleLength  = "max-length"
+	RuleDNSSEC  = "dnssec"
+	RulePrivacy = "whois-privacy"
+)
+
+// Finding is the outcome of one rule for one domain.
+type Finding struct {
+	Domain  string `json:"domain"`
+	Rule    string `json:"rule"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// Report holds the findings of a policy run.
+type Report struct {
+	Policy    string    `json:"policy"`
+	Findings  []Finding `json:"findings"`
+	Passed    int       `json:"passed"`
+	Failed    int       `json:"failed"`
+	Skipped   int       `json:"skipped"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// OK reports whether no rule failed; with strict, skipped rules count as
+// failures too.
+func (r *Report) OK(strict bool) bool {
+	return r.Failed == 0 && (!strict || r.Skipped == 0)
+}
+
+type Evaluator struct {
+	policy  *Policy
+	pattern *regexp.Regexp
+	queryDS func(domain string) (*checker.Response, error)
+	whois   func(domain string) (*whois.Result, error)
+}
+
+func NewEvaluator(p *Policy) (*Evaluator, error) {
+	e := &Evaluator{
+		policy:  p,
+		queryDS: func(domain string) (*checker.Response, error) { return checker.NewResolver().Query(domain, "DS") },
+		whois:   whois.NewClient().Lookup,
+	}
+	if p.NamePattern != "" {
+		re, err := regexp.Compile(p.NamePattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name_pattern: %v", err)
+		}
+		e.pattern = re
+	}
+	return e, nil
+}
+
+// Evaluate checks every domain against the policy.
+func (e *Evaluator) Evaluate(domains []string) *Report {
+	report := &Report{Policy: e.policy.Name, CheckedAt: time.Now()}
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+		if domain == "" {
+			continue
+		}
+		for _, f := range e.evaluate(domain) {
+			switch f.Status {
+			case Pass:
+				report.Passed++
+			case Fail:
+				report.Failed++
+			default:
+				report.Skipped++
+			}
+			report.Findings = append(report.Findings, f)
+		}
+	}
+	return report
+}
+
+func (e *Evaluator) evaluate(domain string) []Finding {
+	var findings []Finding
+	add := func(rule, status, format string, args ...interface{}) {
+		findings = append(findings, Finding{Domain: domain, Rule: rule, Status: status, Message: fmt.Sprintf(format, args...)})
+	}
+
+	label, tld, _ := strings.Cut(domain, ".")
+
+	if len(e.policy.AllowedTLDs) > 0 {
+		allowed := false
+		for _, t := range e.policy.AllowedTLDs {
+			if strings.EqualFold(strings.TrimPrefix(t, "."), tld) {
+				allowed = true
+			}
+		}
+		if allowed {
+			add(RuleTLD, Pass, ".%s is allowed", tld)
+		} else {
+			add(RuleTLD, Fail, ".%s is not in the allowed TLDs (%s)", tld, strings.Join(e.policy.AllowedTLDs, ", "))
+		}
+	}
+
+	if e.pattern != nil {
+		if e.pattern.MatchString(label) {
+			add(RuleName, Pass, "%q matches %s", label, e.policy.NamePattern)
+		} else {
+			add(RuleName, Fail, "%q does not match %s", label, e.policy.NamePattern)
+		}
+	}
+
+	if e.policy.MaxLength > 0 {
+		if len(label) <= e.policy.MaxLength {
+			add(RuleLength, Pass, "%d characters", len(label))
+		} else {
+			add(RuleLength, Fail, "%d characters, limit is %d", len(label), e.policy.MaxLength)
+		}
+	}
+
+	if e.policy.RequireDNSSEC {
+		resp, err := e.queryDS(domain)
+		switch {
+		case err != nil:
+			add(RuleDNSSEC, Skip, "DS lookup failed: %v", err)
+		case hasType(resp.Answers, "DS"):
+			add(RuleDNSSEC, Pass, "DS record published in the .%s zone", tld)
+		default:
+			add(RuleDNSSEC, Fail, "no DS record; the domain is not DNSSEC-signed")
+		}
+	}
+
+	if e.policy.RequirePrivacy {
+		result, err := e.whois(domain)
+		if err == nil && result.Error != "" {
+			err = fmt.Errorf("%s", result.Error)
+		}
+		switch {
+		case err != nil:
+			add(RulePrivacy, Skip, "WHOIS lookup failed: %v", err)
+		case result.Available:
+			add(RulePrivacy, Skip, "domain is not registered")
+		default:
+			status, message := privacyStatus(result.RawData)
+			add(RulePrivacy, status, "%s", message)
+		}
+	}
+
+	return findings
+}
+
+func hasType(records []checker.Record, rrType string) bool {
+	for _, r := range records {
+		if r.Type == rrType {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	registrantLine = regexp.MustCompile(`(?im)^\s*registrant (name|organization|email)\s*:\s*(.*)$`)
+	privateValue   = regexp.MustCompile(`(?i)redacted|privacy|private|withheld|not disclosed|data protected|proxy|contact privacy|whoisguard|domains by proxy`)
+)
+
+// privacyStatus decides whether raw WHOIS hides the registrant.
+func privacyStatus(raw string) (string, string) {
+	lines := registrantLine.FindAllStringSubmatch(raw, -1)
+	if len(lines) == 0 {
+		return Skip, "the registry WHOIS does not include registrant data (thin registry); check the registrar's WHOIS"
+	}
+	for _, m := range lines {
+		value := strings.TrimSpace(m[2])
+		if value != "" && !privateValue.MatchString(value) {
+			return Fail, fmt.Sprintf("registrant %s is public: %s", strings.ToLower(m[1]), value)
+		}
+	}
+	return Pass, "registrant details are redacted"
+}
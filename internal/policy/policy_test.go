@@ -0,0 +1,92 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"d3-domain-tool/internal/checker"
+	"d3-domain-tool/internal/whois"
+)
+
+func TestEvaluate(t *testing.T) {
+	p := &Policy{
+		Name:           "acme",
+		AllowedTLDs:    []string{"com", ".io"},
+		NamePattern:    `^acme(-[a-z0-9]+)*$`,
+		MaxLength:      12,
+		RequireDNSSEC:  true,
+		RequirePrivacy: true,
+	}
+	e, err := NewEvaluator(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.queryDS = func(domain string) (*checker.Response, error) {
+		switch domain {
+		case "acme.com":
+			return &checker.Response{Answers: []checker.Record{{Name: domain, Type: "DS"}}}, nil
+		case "acme-shop.io":
+			return nil, fmt.Errorf("timeout")
+		}
+		return &checker.Response{}, nil
+	}
+	e.whois = func(domain string) (*whois.Result, error) {
+		switch domain {
+		case "acme.com":
+			return &whois.Result{RawData: "Registrant Name: REDACTED FOR PRIVACY\nRegistrant Organization: Domains By Proxy, LLC\n"}, nil
+		case "acme-shop.io":
+			return &whois.Result{RawData: "Registrant Organization: Acme Inc\n"}, nil
+		}
+		return &whois.Result{RawData: "Registrar: Example\n"}, nil
+	}
+
+	report := e.Evaluate([]string{"ACME.com.", "acme-shop.io", "shop-acme-extra.net"})
+	want := map[string]string{
+		"acme.com " + RuleTLD:                Pass,
+		"acme.com " + RuleName:               Pass,
+		"acme.com " + RuleLength:             Pass,
+		"acme.com " + RuleDNSSEC:             Pass,
+		"acme.com " + RulePrivacy:            Pass,
+		"acme-shop.io " + RuleTLD:            Pass,
+		"acme-shop.io " + RuleDNSSEC:         Skip,
+		"acme-shop.io " + RulePrivacy:        Fail,
+		"shop-acme-extra.net " + RuleTLD:     Fail,
+		"shop-acme-extra.net " + RuleName:    Fail,
+		"shop-acme-extra.net " + RuleLength:  Fail,
+		"shop-acme-extra.net " + RuleDNSSEC:  Fail,
+		"shop-acme-extra.net " + RulePrivacy: Skip,
+	}
+	for _, f := range report.Findings {
+		if status, ok := want[f.Domain+" "+f.Rule]; ok && status != f.Status {
+			t.Errorf("%s %s: %s (%s), want %s", f.Domain, f.Rule, f.Status, f.Message, status)
+		}
+	}
+	if len(report.Findings) != 15 || report.Failed != 5 || report.Skipped != 2 {
+		t.Errorf("unexpected totals: %d findings, %d failed, %d skipped", len(report.Findings), report.Failed, report.Skipped)
+	}
+	if report.OK(false) {
+		t.Error("expected the report to fail")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	os.WriteFile(good, []byte(`{"allowed_tlds": ["com"], "require_dnssec": true}`), 0644)
+	p, err := Load(good)
+	if err != nil || !p.RequireDNSSEC || p.Name != good {
+		t.Errorf("unexpected policy %+v, %v", p, err)
+	}
+
+	typo := filepath.Join(dir, "typo.json")
+	os.WriteFile(typo, []byte(`{"require_dnsec": true}`), 0644)
+	if _, err := Load(typo); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+
+	if _, err := NewEvaluator(&Policy{NamePattern: "("}); err == nil {
+		t.Error("expected an error for an invalid name_pattern")
+	}
+}
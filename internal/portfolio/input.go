@@ -0,0 +1,49 @@
+package portfolio
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"d3-domain-tool/internal/analyzer"
+)
+
+// ReadDomains reads a domain list with one domain per line. Blank lines,
+// "#" comments and a "domain" header are skipped; for CSV or TSV exports
+// only the first column is used. Domains are canonicalized (see
+// analyzer.Canonicalize), so pasted URLs work, and duplicates are
+// dropped, keeping the first occurrence.
+func ReadDomains(r io.Reader) ([]string, error) {
+	var domains []string
+	err := ScanDomains(r, func(domain string) {
+		domains = append(domains, domain)
+	})
+	return domains, err
+}
+
+// ScanDomains reads domains like ReadDomains, passing each to fn as soon
+// as its line is read, so a slow or endless input is processed as it
+// arrives.
+func ScanDomains(r io.Reader, fn func(domain string)) error {
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ';' || r == '\t' || r == ' '
+		})
+		if len(fields) == 0 {
+			continue
+		}
+		domain := analyzer.Canonicalize(strings.Trim(fields[0], `"'`))
+		if domain == "" || domain == "domain" || seen[domain] {
+			continue
+		}
+		seen[domain] = true
+		fn(domain)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,45 @@
+package portfolio
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadDomains(t *testing.T) {
+	input := `domain,price
+# registrar export
+Example.com,1200
+"vault.eth",50
+
+other.io	# trailing comment
+example.com
+`
+	domains, err := ReadDomains(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"example.com", "vault.eth", "other.io"}; !reflect.DeepEqual(domains, want) {
+		t.Errorf("domains = %v, want %v", domains, want)
+	}
+}
+
+func TestScanDomainsStreams(t *testing.T) {
+	r, w := io.Pipe()
+	got := make(chan string)
+	done := make(chan error)
+	go func() { done <- ScanDomains(r, func(d string) { got <- d }) }()
+
+	// Each domain arrives before the input ends.
+	for _, d := range []string{"a.com", "b.com"} {
+		io.WriteString(w, d+"\n")
+		if name := <-got; name != d {
+			t.Errorf("got %q, want %q", name, d)
+		}
+	}
+	w.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
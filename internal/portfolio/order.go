@@ -0,0 +1,74 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+)
+
+// SortKeys lists the orderings accepted by Sort.
+var SortKeys = []string{"input", "domain", "value", "expiry"}
+
+// Sort orders bulk results in place. "input" keeps the order domains were
+// given in; the other keys fall back to the domain name on ties so repeated
+// runs produce identical output.
+func Sort(results []*analyzer.Result, by string) error {
+	var less func(a, b *analyzer.Result) bool
+	switch by {
+	case "", "input":
+		return nil
+	case "domain":
+		less = func(a, b *analyzer.Result) bool { return false }
+	case "value":
+		less = func(a, b *analyzer.Result) bool { return estimatedValue(a) > estimatedValue(b) }
+	case "expiry":
+		less = func(a, b *analyzer.Result) bool {
+			ea, eb := expiryOf(a), expiryOf(b)
+			switch {
+			case ea == nil && eb == nil:
+				return false
+			case ea == nil || eb == nil:
+				// Domains without a known expiry go last.
+				return eb == nil
+			case !ea.Equal(*eb):
+				return ea.Before(*eb)
+			}
+			return false
+		}
+	default:
+		return fmt.Errorf("unknown sort key %q (expected one of %v)", by, SortKeys)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.Domain < b.Domain
+	})
+	return nil
+}
+
+func estimatedValue(r *analyzer.Result) int {
+	if r.ValuationData == nil {
+		return 0
+	}
+	return r.ValuationData.EstimatedValue
+}
+
+// expiryOf returns the registration expiry from WHOIS or, for blockchain
+// names, the on-chain record.
+func expiryOf(r *analyzer.Result) *time.Time {
+	if r.WhoisData != nil && r.WhoisData.ExpiryDate != nil {
+		return r.WhoisData.ExpiryDate
+	}
+	if r.BlockchainData != nil {
+		return r.BlockchainData.ExpiryDate
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package portfolio
+
+import (
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/valuation"
+	"d3-domain-tool/internal/whois"
+)
+
+func TestSort(t *testing.T) {
+	soon := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	later := soon.AddDate(1, 0, 0)
+	input := func() []*analyzer.Result {
+		return []*analyzer.Result{
+			{Domain: "c.com", ValuationData: &valuation.Result{EstimatedValue: 500}, WhoisData: &whois.Result{ExpiryDate: &later}},
+			{Domain: "a.com", ValuationData: &valuation.Result{EstimatedValue: 100}},
+			{Domain: "b.com", ValuationData: &valuation.Result{EstimatedValue: 500}, WhoisData: &whois.Result{ExpiryDate: &soon}},
+		}
+	}
+
+	tests := []struct {
+		by   string
+		want []string
+	}{
+		{"input", []string{"c.com", "a.com", "b.com"}},
+		{"domain", []string{"a.com", "b.com", "c.com"}},
+		{"value", []string{"b.com", "c.com", "a.com"}},
+		{"expiry", []string{"b.com", "c.com", "a.com"}},
+	}
+	for _, tt := range tests {
+		results := input()
+		if err := Sort(results, tt.by); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.by, err)
+		}
+		for i, r := range results {
+			if r.Domain != tt.want[i] {
+				t.Errorf("%s: position %d = %s, want %s", tt.by, i, r.Domain, tt.want[i])
+			}
+		}
+	}
+
+	if err := Sort(input(), "size"); err == nil {
+		t.Error("expected error for unknown sort key")
+	}
+}
@@ -0,0 +1,126 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/usage"
+)
+
+// Stats describes the health of one run: how many domains were processed,
+// their verdicts, which sub-checks failed and how long it took.
+type Stats struct {
+	Domains           int            `json:"domains"`
+	Available         int            `json:"available"`
+	Taken             int            `json:"taken"`
+	Unknown           int            `json:"unknown"`
+	Degraded          int            `json:"degraded"`
+	ErrorsByModule    map[string]int `json:"errors_by_module"`
+	TotalDurationMS   int64          `json:"total_duration_ms"`
+	AverageDurationMS int64          `json:"average_duration_ms"`
+	// Cache counters are reported once a cache is in use.
+	CacheHits    int      `json:"cache_hits,omitempty"`
+	CacheLookups int      `json:"cache_lookups,omitempty"`
+	CacheHitRate *float64 `json:"cache_hit_rate,omitempty"`
+	// Providers counts the calls to paid providers, reported once any was
+	// made.
+	Providers  map[string]usage.Usage `json:"providers,omitempty"`
+	StartedAt  time.Time              `json:"started_at"`
+	FinishedAt time.Time              `json:"finished_at"`
+
+	analysis time.Duration
+}
+
+// NewStats starts collecting statistics for a run.
+func NewStats() *Stats {
+	return &Stats{ErrorsByModule: map[string]int{}, StartedAt: time.Now()}
+}
+
+// Add counts one analyzed domain that took elapsed to analyze.
+func (s *Stats) Add(result *analyzer.Result, elapsed time.Duration) {
+	s.Domains++
+	s.analysis += elapsed
+	switch result.Verdict() {
+	case analyzer.VerdictAvailable:
+		s.Available++
+	case analyzer.VerdictTaken:
+		s.Taken++
+	default:
+		s.Unknown++
+	}
+	if result.Degraded() {
+		s.Degraded++
+	}
+	for _, se := range result.SectionErrors {
+		s.ErrorsByModule[se.Section]++
+	}
+}
+
+// AddCache counts cache lookups and how many of them were hits.
+func (s *Stats) AddCache(hits, lookups int) {
+	s.CacheHits += hits
+	s.CacheLookups += lookups
+}
+
+// AddUsage records the calls made to paid providers during the run.
+func (s *Stats) AddUsage(providers map[string]usage.Usage) {
+	if len(providers) > 0 {
+		s.Providers = providers
+	}
+}
+
+// Finish stamps the end of the run and computes the derived fields.
+func (s *Stats) Finish() *Stats {
+	s.FinishedAt = time.Now()
+	s.TotalDurationMS = s.FinishedAt.Sub(s.StartedAt).Milliseconds()
+	if s.Domains > 0 {
+		s.AverageDurationMS = (s.analysis / time.Duration(s.Domains)).Milliseconds()
+	}
+	if s.CacheLookups > 0 {
+		rate := float64(s.CacheHits) / float64(s.CacheLookups)
+		s.CacheHitRate = &rate
+	}
+	return s
+}
+
+// Line is a one-line human summary of the run.
+func (s *Stats) Line() string {
+	line := fmt.Sprintf("%d domains in %s (avg %s): %d available, %d taken, %d unknown, %d degraded",
+		s.Domains,
+		(time.Duration(s.TotalDurationMS) * time.Millisecond).Round(time.Millisecond),
+		(time.Duration(s.AverageDurationMS) * time.Millisecond).Round(time.Millisecond),
+		s.Available, s.Taken, s.Unknown, s.Degraded)
+	if len(s.ErrorsByModule) > 0 {
+		var modules []string
+		for m := range s.ErrorsByModule {
+			modules = append(modules, m)
+		}
+		sort.Strings(modules)
+		for i, m := range modules {
+			modules[i] = fmt.Sprintf("%s=%d", m, s.ErrorsByModule[m])
+		}
+		line += "; errors: " + strings.Join(modules, " ")
+	}
+	if s.CacheHitRate != nil {
+		line += fmt.Sprintf("; cache hit rate %.0f%%", *s.CacheHitRate*100)
+	}
+	if len(s.Providers) > 0 {
+		var calls []string
+		for _, p := range usage.Providers {
+			u, ok := s.Providers[p]
+			if !ok {
+				continue
+			}
+			call := fmt.Sprintf("%s=%d", p, u.Calls)
+			if u.Refused > 0 {
+				call += fmt.Sprintf(" (%d over budget)", u.Refused)
+			}
+			calls = append(calls, call)
+		}
+		line += "; paid calls: " + strings.Join(calls, " ")
+	}
+	return line
+}
@@ -0,0 +1,52 @@
+package portfolio
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/usage"
+	"d3-domain-tool/internal/whois"
+)
+
+func TestStats(t *testing.T) {
+	s := NewStats()
+	s.Add(&analyzer.Result{Domain: "a.com", WhoisData: &whois.Result{Available: true}}, 2*time.Second)
+	s.Add(&analyzer.Result{Domain: "b.com", WhoisData: &whois.Result{Registrar: "GoDaddy"}}, 4*time.Second)
+	s.Add(&analyzer.Result{Domain: "c.com", SectionErrors: []analyzer.SectionError{
+		{Section: "whois", Error: "timeout"}, {Section: "dns", Error: "SERVFAIL"},
+	}}, 0)
+	s.Finish()
+
+	if s.Domains != 3 || s.Available != 1 || s.Taken != 1 || s.Degraded != 1 {
+		t.Errorf("unexpected counts: %+v", s)
+	}
+	if s.ErrorsByModule["whois"] != 1 || s.ErrorsByModule["dns"] != 1 {
+		t.Errorf("unexpected errors by module: %v", s.ErrorsByModule)
+	}
+	if s.AverageDurationMS != 2000 {
+		t.Errorf("expected a 2s average, got %dms", s.AverageDurationMS)
+	}
+	if s.CacheHitRate != nil {
+		t.Errorf("expected no cache hit rate without lookups, got %v", *s.CacheHitRate)
+	}
+	if line := s.Line(); !strings.Contains(line, "3 domains") || !strings.HasSuffix(line, "errors: dns=1 whois=1") {
+		t.Errorf("unexpected summary line %q", line)
+	}
+}
+
+func TestStatsUsage(t *testing.T) {
+	s := NewStats()
+	s.AddUsage(map[string]usage.Usage{})
+	if s.Providers != nil {
+		t.Errorf("expected no providers without calls, got %v", s.Providers)
+	}
+	s.AddUsage(map[string]usage.Usage{
+		usage.WhoisXML: {Calls: 3},
+		usage.EthRPC:   {Calls: 10, Budget: 10, Refused: 2},
+	})
+	if line := s.Finish().Line(); !strings.HasSuffix(line, "paid calls: whoisxml=3 eth_rpc=10 (2 over budget)") {
+		t.Errorf("unexpected summary line %q", line)
+	}
+}
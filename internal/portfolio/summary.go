@@ -0,0 +1,203 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+)
+
+// expiryWarningDays is how far ahead expiring registrations are reported.
+const expiryWarningDays = 60
+
+// concentrationThreshold is the share of a portfolio on one provider above
+// which concentration risk is reported.
+const concentrationThreshold = 0.5
+
+// Summary aggregates results across a multi-domain run.
+type Summary struct {
+	Domains      int        `json:"domains"`
+	DNSProviders []Count    `json:"dns_providers,omitempty"`
+	Registrars   []Count    `json:"registrars,omitempty"`
+	Unlocked     []string   `json:"unlocked,omitempty"`
+	Disputed     []string   `json:"disputed,omitempty"`
+	Underwater   []string   `json:"underwater,omitempty"`
+	RenewalCost  float64    `json:"renewal_cost,omitempty"`
+	ExpiringSoon []Expiring `json:"expiring_soon,omitempty"`
+	Risks        []string   `json:"risks,omitempty"`
+}
+
+// Expiring is a registration that lapses within the warning window, or
+// already has: Expired is then set and DaysLeft is 0.
+type Expiring struct {
+	Domain     string    `json:"domain"`
+	ExpiryDate time.Time `json:"expiry_date"`
+	DaysLeft   int       `json:"days_left"`
+	Expired    bool      `json:"expired,omitempty"`
+}
+
+// Count is the number of domains sharing a value, with their names.
+type Count struct {
+	Name    string   `json:"name"`
+	Count   int      `json:"count"`
+	Share   float64  `json:"share"`
+	Domains []string `json:"domains"`
+}
+
+// Summarize builds the portfolio summary for a set of results.
+func Summarize(results []*analyzer.Result) *Summary {
+	return summarizeAt(results, time.Now())
+}
+
+func summarizeAt(results []*analyzer.Result, now time.Time) *Summary {
+	summary := &Summary{
+		Domains: len(results),
+	}
+
+	providers := newCounter()
+	for _, r := range results {
+		if r.DNSProvider == nil {
+			continue
+		}
+		for _, p := range r.DNSProvider.Providers {
+			providers.add(p.Name, r.Domain)
+		}
+	}
+	summary.DNSProviders = providers.counts(len(results))
+
+	registrars := newCounter()
+	for _, r := range results {
+		whois := r.WhoisData
+		if whois == nil || whois.Available {
+			continue
+		}
+		if whois.Registrar != "" {
+			registrars.add(whois.Registrar, r.Domain)
+		}
+		if len(whois.Status) > 0 && !transferLocked(whois.Status) {
+			summary.Unlocked = append(summary.Unlocked, r.Domain)
+		}
+		if whois.ExpiryDate != nil {
+			expiring := Expiring{Domain: r.Domain, ExpiryDate: *whois.ExpiryDate}
+			if whois.ExpiryDate.Before(now) {
+				expiring.Expired = true
+			} else {
+				expiring.DaysLeft = int(whois.ExpiryDate.Sub(now).Hours() / 24)
+			}
+			if expiring.DaysLeft <= expiryWarningDays {
+				summary.ExpiringSoon = append(summary.ExpiringSoon, expiring)
+			}
+		}
+	}
+	summary.Registrars = registrars.counts(len(results))
+	for _, r := range results {
+		if r.UDRP != nil && r.UDRP.DisputeHistory {
+			summary.Disputed = append(summary.Disputed, r.Domain)
+		}
+		// Carrying cost only counts names held, not ones still available.
+		if v := r.ValuationData; v != nil && v.RenewalCost != nil && r.Verdict() == analyzer.VerdictTaken {
+			summary.RenewalCost += *v.RenewalCost
+			if v.Underwater {
+				summary.Underwater = append(summary.Underwater, r.Domain)
+			}
+		}
+	}
+	sort.Slice(summary.ExpiringSoon, func(i, j int) bool {
+		a, b := summary.ExpiringSoon[i], summary.ExpiringSoon[j]
+		if !a.ExpiryDate.Equal(b.ExpiryDate) {
+			return a.ExpiryDate.Before(b.ExpiryDate)
+		}
+		return a.Domain < b.Domain
+	})
+
+	for _, c := range summary.DNSProviders {
+		if len(results) >= 2 && c.Share > concentrationThreshold {
+			summary.Risks = append(summary.Risks,
+				fmt.Sprintf("%.0f%% of domains depend on DNS provider %s", c.Share*100, c.Name))
+		}
+	}
+	for _, c := range summary.Registrars {
+		if len(results) >= 2 && c.Share > concentrationThreshold {
+			summary.Risks = append(summary.Risks,
+				fmt.Sprintf("%.0f%% of domains are held at registrar %s", c.Share*100, c.Name))
+		}
+	}
+	if len(summary.Unlocked) > 0 {
+		summary.Risks = append(summary.Risks,
+			fmt.Sprintf("%d domain(s) lack a transfer lock", len(summary.Unlocked)))
+	}
+	if len(summary.Disputed) > 0 {
+		summary.Risks = append(summary.Risks,
+			fmt.Sprintf("%d domain(s) have UDRP dispute history", len(summary.Disputed)))
+	}
+	if len(summary.Underwater) > 0 {
+		summary.Risks = append(summary.Risks,
+			fmt.Sprintf("%d domain(s) cost more to renew each year than they are worth", len(summary.Underwater)))
+	}
+	expired := 0
+	for _, e := range summary.ExpiringSoon {
+		if e.Expired {
+			expired++
+		}
+	}
+	if expired > 0 {
+		summary.Risks = append(summary.Risks,
+			fmt.Sprintf("%d domain(s) have expired", expired))
+	}
+	if soon := len(summary.ExpiringSoon) - expired; soon > 0 {
+		summary.Risks = append(summary.Risks,
+			fmt.Sprintf("%d domain(s) expire within %d days", soon, expiryWarningDays))
+	}
+
+	return summary
+}
+
+// transferLocked reports whether the EPP statuses include a transfer lock.
+func transferLocked(statuses []string) bool {
+	for _, s := range statuses {
+		lower := strings.ToLower(s)
+		if strings.Contains(lower, "clienttransferprohibited") || strings.Contains(lower, "servertransferprohibited") {
+			return true
+		}
+	}
+	return false
+}
+
+// counter tallies domains per key, keeping each domain once per key.
+type counter struct {
+	domains map[string][]string
+}
+
+func newCounter() *counter {
+	return &counter{domains: make(map[string][]string)}
+}
+
+func (c *counter) add(key, domain string) {
+	for _, d := range c.domains[key] {
+		if d == domain {
+			return
+		}
+	}
+	c.domains[key] = append(c.domains[key], domain)
+}
+
+// counts returns the tallies sorted by count (descending) then name.
+func (c *counter) counts(total int) []Count {
+	var out []Count
+	for name, domains := range c.domains {
+		share := 0.0
+		if total > 0 {
+			share = float64(len(domains)) / float64(total)
+		}
+		out = append(out, Count{Name: name, Count: len(domains), Share: share, Domains: domains})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
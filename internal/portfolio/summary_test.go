@@ -0,0 +1,72 @@
+package portfolio
+
+import (
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/valuation"
+	"d3-domain-tool/internal/whois"
+)
+
+func TestSummarize(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	soon := now.AddDate(0, 0, 30)
+	later := now.AddDate(1, 0, 0)
+	lapsed := now.AddDate(0, 0, -10)
+
+	results := []*analyzer.Result{
+		{Domain: "a.com", WhoisData: &whois.Result{Registrar: "GoDaddy", ExpiryDate: &soon, Status: []string{"clientTransferProhibited"}}},
+		{Domain: "b.com", WhoisData: &whois.Result{Registrar: "GoDaddy", ExpiryDate: &later, Status: []string{"ok"}}},
+		{Domain: "c.com", WhoisData: &whois.Result{Registrar: "Namecheap", ExpiryDate: &later, Status: []string{"clientTransferProhibited"}}},
+		{Domain: "d.com", WhoisData: &whois.Result{Available: true}},
+		{Domain: "e.com", WhoisData: &whois.Result{Registrar: "Gandi", ExpiryDate: &lapsed}},
+	}
+
+	summary := summarizeAt(results, now)
+
+	if summary.Domains != 5 {
+		t.Errorf("expected 5 domains, got %d", summary.Domains)
+	}
+	if len(summary.Registrars) != 3 || summary.Registrars[0].Name != "GoDaddy" || summary.Registrars[0].Count != 2 {
+		t.Errorf("unexpected registrars: %+v", summary.Registrars)
+	}
+	if len(summary.Unlocked) != 1 || summary.Unlocked[0] != "b.com" {
+		t.Errorf("unexpected unlocked list: %v", summary.Unlocked)
+	}
+	if len(summary.ExpiringSoon) != 2 || summary.ExpiringSoon[1].Domain != "a.com" || summary.ExpiringSoon[1].DaysLeft != 30 {
+		t.Fatalf("unexpected expiring list: %+v", summary.ExpiringSoon)
+	}
+	if e := summary.ExpiringSoon[0]; e.Domain != "e.com" || !e.Expired || e.DaysLeft != 0 {
+		t.Errorf("expected e.com to be reported as expired, got %+v", e)
+	}
+	if !containsRisk(summary.Risks, "1 domain(s) have expired") || !containsRisk(summary.Risks, "1 domain(s) expire within 60 days") {
+		t.Errorf("unexpected risks: %v", summary.Risks)
+	}
+}
+
+func containsRisk(risks []string, want string) bool {
+	for _, risk := range risks {
+		if risk == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSummarizeCarryingCost(t *testing.T) {
+	cost := 50.0
+	results := []*analyzer.Result{
+		{Domain: "a.io", WhoisData: &whois.Result{Registrar: "Gandi"}, ValuationData: &valuation.Result{EstimatedValue: 20, RenewalCost: &cost, Underwater: true}},
+		{Domain: "b.io", WhoisData: &whois.Result{Registrar: "Gandi"}, ValuationData: &valuation.Result{EstimatedValue: 5000, RenewalCost: &cost}},
+		{Domain: "c.io", WhoisData: &whois.Result{Available: true}, ValuationData: &valuation.Result{EstimatedValue: 20, RenewalCost: &cost, Underwater: true}},
+	}
+
+	summary := summarizeAt(results, time.Now())
+	if len(summary.Underwater) != 1 || summary.Underwater[0] != "a.io" {
+		t.Errorf("unexpected underwater list: %v", summary.Underwater)
+	}
+	if summary.RenewalCost != 100 {
+		t.Errorf("expected $100 of yearly renewals for the two held names, got %v", summary.RenewalCost)
+	}
+}
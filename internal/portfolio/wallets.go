@@ -0,0 +1,75 @@
+package portfolio
+
+import (
+	"fmt"
+
+	"d3-domain-tool/internal/config"
+)
+
+// WalletSource lists the names one registry holds for an address. Names
+// is nil when the registry's data source is not configured; Unavailable
+// then says why.
+type WalletSource struct {
+	Registry    string
+	Names       func(address string) ([]string, error)
+	Unavailable string
+}
+
+// Holding is a name held by a watch-only wallet.
+type Holding struct {
+	Name     string `json:"name"`
+	Registry string `json:"registry"`
+	Wallet   string `json:"wallet"`
+	Label    string `json:"label,omitempty"`
+}
+
+// WalletSync is the result of syncing watch-only wallets.
+type WalletSync struct {
+	Wallets  int       `json:"wallets"`
+	Holdings []Holding `json:"holdings"`
+	Warnings []string  `json:"warnings,omitempty"`
+}
+
+// SyncWallets lists the current holdings of every wallet in each source.
+// A failing source is reported as a warning so the rest of the portfolio
+// still syncs.
+func SyncWallets(wallets []config.WatchWallet, sources []WalletSource) *WalletSync {
+	sync := &WalletSync{Wallets: len(wallets)}
+	for _, source := range sources {
+		if source.Names == nil {
+			sync.Warnings = append(sync.Warnings, fmt.Sprintf("%s holdings not synced: %s", source.Registry, source.Unavailable))
+			continue
+		}
+		for _, w := range wallets {
+			names, err := source.Names(w.Address)
+			if err != nil {
+				sync.Warnings = append(sync.Warnings, fmt.Sprintf("%s holdings of %s: %v", source.Registry, walletName(w), err))
+				continue
+			}
+			for _, name := range names {
+				sync.Holdings = append(sync.Holdings, Holding{Name: name, Registry: source.Registry, Wallet: w.Address, Label: w.Label})
+			}
+		}
+	}
+	return sync
+}
+
+// Names returns the held names in sync order without duplicates.
+func (s *WalletSync) Names() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, h := range s.Holdings {
+		if !seen[h.Name] {
+			seen[h.Name] = true
+			names = append(names, h.Name)
+		}
+	}
+	return names
+}
+
+func walletName(w config.WatchWallet) string {
+	if w.Label != "" {
+		return fmt.Sprintf("%s (%s)", w.Label, w.Address)
+	}
+	return w.Address
+}
@@ -0,0 +1,40 @@
+package portfolio
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"d3-domain-tool/internal/config"
+)
+
+func TestSyncWallets(t *testing.T) {
+	wallets := []config.WatchWallet{{Address: "0xaaa", Label: "vault"}, {Address: "0xbbb"}}
+	sources := []WalletSource{
+		{Registry: "ENS", Names: func(address string) ([]string, error) {
+			if address == "0xbbb" {
+				return nil, fmt.Errorf("HTTP 503")
+			}
+			return []string{"vault.eth", "alpha.eth"}, nil
+		}},
+		{Registry: "Unstoppable Domains", Names: func(address string) ([]string, error) {
+			return []string{"vault.crypto", "vault.eth"}, nil
+		}},
+		{Registry: "DOMA", Unavailable: "data source not configured"},
+	}
+
+	sync := SyncWallets(wallets, sources)
+	if sync.Wallets != 2 || len(sync.Holdings) != 6 {
+		t.Fatalf("sync = %+v", sync)
+	}
+	if h := sync.Holdings[0]; h.Label != "vault" || h.Registry != "ENS" || h.Wallet != "0xaaa" {
+		t.Errorf("holding = %+v", h)
+	}
+	if want := []string{"vault.eth", "alpha.eth", "vault.crypto"}; !reflect.DeepEqual(sync.Names(), want) {
+		t.Errorf("names = %v, want %v", sync.Names(), want)
+	}
+	if len(sync.Warnings) != 2 || !strings.Contains(sync.Warnings[0], "0xbbb: HTTP 503") || !strings.HasPrefix(sync.Warnings[1], "DOMA holdings not synced") {
+		t.Errorf("warnings = %q", sync.Warnings)
+	}
+}
@@ -0,0 +1,118 @@
+package portscan
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultPorts is the curated set of ports checked by a quick scan.
+var DefaultPorts = []int{22, 25, 80, 443, 8080}
+
+var serviceNames = map[int]string{
+	22:   "ssh",
+	25:   "smtp",
+	80:   "http",
+	443:  "https",
+	8080: "http-alt",
+}
+
+// Scanner performs TCP connect checks against a domain's A records.
+type Scanner struct {
+	timeout time.Duration
+	ports   []int
+	maxIPs  int
+}
+
+type Result struct {
+	Targets   []Target  `json:"targets"`
+	Exposed   []string  `json:"exposed,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Target holds the port states for one resolved address.
+type Target struct {
+	IP    string       `json:"ip"`
+	Ports []PortStatus `json:"ports"`
+}
+
+type PortStatus struct {
+	Port    int    `json:"port"`
+	Service string `json:"service"`
+	Open    bool   `json:"open"`
+}
+
+func NewScanner() *Scanner {
+	return &Scanner{
+		timeout: 2 * time.Second,
+		ports:   DefaultPorts,
+		maxIPs:  4,
+	}
+}
+
+func (s *Scanner) Scan(domain string) (*Result, error) {
+	result := &Result{
+		CheckedAt: time.Now(),
+	}
+
+	ips, err := net.LookupIP(domain)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to resolve A records: %v", err)
+		return result, nil
+	}
+
+	var v4 []string
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			v4 = append(v4, ip.String())
+		}
+	}
+	if len(v4) == 0 {
+		result.Error = "no A records to scan"
+		return result, nil
+	}
+	sort.Strings(v4)
+	if len(v4) > s.maxIPs {
+		v4 = v4[:s.maxIPs]
+	}
+
+	for _, ip := range v4 {
+		target := s.scanIP(ip)
+		for _, p := range target.Ports {
+			if p.Open {
+				result.Exposed = append(result.Exposed, fmt.Sprintf("%s:%d (%s)", ip, p.Port, p.Service))
+			}
+		}
+		result.Targets = append(result.Targets, target)
+	}
+
+	return result, nil
+}
+
+func (s *Scanner) scanIP(ip string) Target {
+	target := Target{
+		IP:    ip,
+		Ports: make([]PortStatus, len(s.ports)),
+	}
+
+	var wg sync.WaitGroup
+	for i, port := range s.ports {
+		wg.Add(1)
+		go func(i, port int) {
+			defer wg.Done()
+			status := PortStatus{Port: port, Service: serviceNames[port]}
+			conn, err := net.DialTimeout("tcp", net.JoinHostPort(ip, fmt.Sprint(port)), s.timeout)
+			if err == nil {
+				status.Open = true
+				conn.Close()
+			}
+			target.Ports[i] = status
+		}(i, port)
+	}
+	wg.Wait()
+
+	return target
+}
@@ -0,0 +1,41 @@
+package portscan
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestScan(t *testing.T) {
+	open, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer open.Close()
+	// A port that was just free is closed once its listener is gone.
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+	openPort := open.Addr().(*net.TCPAddr).Port
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+
+	s := NewScanner()
+	s.timeout = time.Second
+	s.ports = []int{openPort, closedPort}
+	result, err := s.Scan("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Error != "" || len(result.Targets) != 1 || result.Targets[0].IP != "127.0.0.1" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	ports := result.Targets[0].Ports
+	if len(ports) != 2 || ports[0].Port != openPort || !ports[0].Open || ports[1].Port != closedPort || ports[1].Open {
+		t.Errorf("unexpected port states %+v", ports)
+	}
+	if len(result.Exposed) != 1 {
+		t.Errorf("expected only the listening port exposed, got %v", result.Exposed)
+	}
+}
@@ -0,0 +1,199 @@
+package provider
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Provider categories.
+const (
+	CategoryManaged   = "managed"
+	CategoryRegistrar = "registrar default"
+	CategoryHosting   = "hosting"
+	CategoryUnknown   = "self-hosted/unknown"
+)
+
+// signature maps a nameserver hostname suffix to the operator behind it.
+// A suffix starting with a dot matches on a label boundary, so
+// ns.cloudflare.com.evil.example is not Cloudflare's; one without, such as
+// awsdns-, matches the start of any label.
+type signature struct {
+	suffix   string
+	name     string
+	category string
+}
+
+// matches reports whether the nameserver ns carries the signature.
+func (s signature) matches(ns string) bool {
+	if strings.HasPrefix(s.suffix, ".") {
+		return strings.HasSuffix(ns, s.suffix)
+	}
+	for _, label := range strings.Split(ns, ".") {
+		if strings.HasPrefix(label, s.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+var signatures = []signature{
+	{".ns.cloudflare.com", "Cloudflare", CategoryManaged},
+	{"awsdns-", "Amazon Route 53", CategoryManaged},
+	{".nsone.net", "NS1", CategoryManaged},
+	{".azure-dns.com", "Azure DNS", CategoryManaged},
+	{".azure-dns.net", "Azure DNS", CategoryManaged},
+	{".azure-dns.org", "Azure DNS", CategoryManaged},
+	{".azure-dns.info", "Azure DNS", CategoryManaged},
+	{".googledomains.com", "Google Cloud DNS", CategoryManaged},
+	{".google.com", "Google", CategoryManaged},
+	{".ultradns.net", "UltraDNS", CategoryManaged},
+	{".ultradns.com", "UltraDNS", CategoryManaged},
+	{".ultradns.org", "UltraDNS", CategoryManaged},
+	{".ultradns.biz", "UltraDNS", CategoryManaged},
+	{".dynect.net", "Oracle Dyn", CategoryManaged},
+	{".akam.net", "Akamai Edge DNS", CategoryManaged},
+	{".dnsimple.com", "DNSimple", CategoryManaged},
+	{".dnsmadeeasy.com", "DNS Made Easy", CategoryManaged},
+	{".constellix.com", "Constellix", CategoryManaged},
+	{".vercel-dns.com", "Vercel", CategoryHosting},
+	{".nsone-netlify.net", "Netlify", CategoryHosting},
+	{".digitalocean.com", "DigitalOcean", CategoryHosting},
+	{".linode.com", "Linode", CategoryHosting},
+	{".hetzner.com", "Hetzner", CategoryHosting},
+	{".wixdns.net", "Wix", CategoryHosting},
+	{".squarespacedns.com", "Squarespace", CategoryHosting},
+	{".domaincontrol.com", "GoDaddy", CategoryRegistrar},
+	{".registrar-servers.com", "Namecheap", CategoryRegistrar},
+	{".name.com", "Name.com", CategoryRegistrar},
+	{".gandi.net", "Gandi", CategoryRegistrar},
+	{".ovh.net", "OVH", CategoryRegistrar},
+	{".hover.com", "Hover", CategoryRegistrar},
+	{".porkbun.com", "Porkbun", CategoryRegistrar},
+	{".dynadot.com", "Dynadot", CategoryRegistrar},
+	{".namebrightdns.com", "NameBright", CategoryRegistrar},
+	{".ionos.com", "IONOS", CategoryRegistrar},
+	{".ui-dns.com", "IONOS", CategoryRegistrar},
+	{".worldnic.com", "Network Solutions", CategoryRegistrar},
+	{".markmonitor.com", "MarkMonitor", CategoryRegistrar},
+	{".csc.com", "CSC", CategoryRegistrar},
+}
+
+// Classifier maps a domain's nameservers to DNS providers.
+type Classifier struct {
+	lookupNS  func(name string) ([]*net.NS, error)
+	lookupIP  func(host string) ([]net.IP, error)
+	lookupTXT func(name string) ([]string, error)
+}
+
+type Result struct {
+	Nameservers   []string   `json:"nameservers"`
+	Providers     []Provider `json:"providers"`
+	MultiProvider bool       `json:"multi_provider"`
+	VendorLock    string     `json:"vendor_lock,omitempty"`
+	// Usage is how the NS set says the domain is used: active, parked,
+	// sinkholed or registrar default.
+	Usage      string           `json:"usage,omitempty"`
+	Details    []NameserverInfo `json:"nameserver_details,omitempty"`
+	Resilience *Resilience      `json:"resilience,omitempty"`
+	CheckedAt  time.Time        `json:"checked_at"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// Provider is one DNS operator serving the domain.
+type Provider struct {
+	Name        string   `json:"name"`
+	Category    string   `json:"category"`
+	Nameservers []string `json:"nameservers"`
+}
+
+func NewClassifier() *Classifier {
+	return &Classifier{
+		lookupNS:  net.LookupNS,
+		lookupIP:  net.LookupIP,
+		lookupTXT: net.LookupTXT,
+	}
+}
+
+func (c *Classifier) Classify(domain string) (*Result, error) {
+	result := &Result{
+		CheckedAt: time.Now(),
+	}
+
+	records, err := c.lookupNS(domain)
+	if err != nil || len(records) == 0 {
+		result.Error = "no NS records found"
+		return result, nil
+	}
+
+	for _, ns := range records {
+		result.Nameservers = append(result.Nameservers, strings.TrimSuffix(strings.ToLower(ns.Host), "."))
+	}
+	sort.Strings(result.Nameservers)
+
+	result.Providers = Group(domain, result.Nameservers)
+	result.MultiProvider = len(result.Providers) > 1
+	result.Usage = ClassifyUsage(result.Providers)
+	result.Details = c.inspect(domain, result.Nameservers)
+	result.Resilience = ScoreResilience(result.Details, result.MultiProvider)
+
+	if !result.MultiProvider {
+		p := result.Providers[0]
+		switch p.Category {
+		case CategoryParking, CategorySinkhole, CategorySeizure:
+			// Nobody depends on a parked or sinkholed domain's DNS.
+		case CategoryRegistrar:
+			result.VendorLock = "DNS is hosted on the registrar's default nameservers; moving registrars also moves DNS"
+		default:
+			result.VendorLock = "Single DNS provider; an outage at " + p.Name + " takes the domain offline"
+		}
+	}
+
+	return result, nil
+}
+
+// Identify returns the provider name and category for one nameserver.
+// Nameservers under the domain itself are reported as self-hosted.
+func Identify(domain, nameserver string) (string, string) {
+	ns := strings.ToLower(strings.TrimSuffix(nameserver, "."))
+	for _, list := range [][]signature{reputation, signatures} {
+		for _, sig := range list {
+			if sig.matches(ns) {
+				return sig.name, sig.category
+			}
+		}
+	}
+	if domain != "" && strings.HasSuffix(ns, "."+domain) {
+		return "Self-hosted", CategoryUnknown
+	}
+	return operatorDomain(ns), CategoryUnknown
+}
+
+// Group classifies nameservers and groups them by provider, in order of
+// first appearance.
+func Group(domain string, nameservers []string) []Provider {
+	var providers []Provider
+	index := make(map[string]int)
+	for _, ns := range nameservers {
+		name, category := Identify(domain, ns)
+		i, ok := index[name]
+		if !ok {
+			i = len(providers)
+			index[name] = i
+			providers = append(providers, Provider{Name: name, Category: category})
+		}
+		providers[i].Nameservers = append(providers[i].Nameservers, ns)
+	}
+	return providers
+}
+
+// operatorDomain falls back to the nameserver's parent domain as the
+// provider name.
+func operatorDomain(ns string) string {
+	labels := strings.Split(ns, ".")
+	if len(labels) <= 2 {
+		return ns
+	}
+	return strings.Join(labels[len(labels)-2:], ".")
+}
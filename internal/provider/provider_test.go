@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		ns       string
+		name     string
+		category string
+	}{
+		{"ada.ns.cloudflare.com.", "Cloudflare", CategoryManaged},
+		{"ns-123.awsdns-45.org", "Amazon Route 53", CategoryManaged},
+		{"ns1.domaincontrol.com", "GoDaddy", CategoryRegistrar},
+		{"dns1.registrar-servers.com", "Namecheap", CategoryRegistrar},
+		{"ns1.example.com", "Self-hosted", CategoryUnknown},
+		{"ns1.someisp.net", "someisp.net", CategoryUnknown},
+		// Signatures match on label boundaries, not anywhere in the name.
+		{"ada.ns.cloudflare.com.evil.net", "evil.net", CategoryUnknown},
+		{"ns1.notdomaincontrol.com", "notdomaincontrol.com", CategoryUnknown},
+		{"ns1.myawsdns-45.org", "myawsdns-45.org", CategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		name, category := Identify("example.com", tt.ns)
+		if name != tt.name || category != tt.category {
+			t.Errorf("%s: expected %s/%s, got %s/%s", tt.ns, tt.name, tt.category, name, category)
+		}
+	}
+}
+
+func TestGroup(t *testing.T) {
+	providers := Group("example.com", []string{
+		"ada.ns.cloudflare.com",
+		"bob.ns.cloudflare.com",
+		"ns1.p01.nsone.net",
+	})
+	if len(providers) != 2 {
+		t.Fatalf("expected 2 providers, got %+v", providers)
+	}
+	if providers[0].Name != "Cloudflare" || len(providers[0].Nameservers) != 2 {
+		t.Errorf("unexpected first provider: %+v", providers[0])
+	}
+}
+
+func TestClassify_Resilience(t *testing.T) {
+	c := &Classifier{
+		lookupNS: func(name string) ([]*net.NS, error) {
+			return []*net.NS{{Host: "ada.ns.cloudflare.com."}, {Host: "ns1.example.com."}}, nil
+		},
+		lookupIP: func(host string) ([]net.IP, error) {
+			if host == "ada.ns.cloudflare.com" {
+				return []net.IP{net.ParseIP("173.245.58.1")}, nil
+			}
+			return []net.IP{net.ParseIP("192.0.2.53")}, nil
+		},
+		lookupTXT: func(name string) ([]string, error) {
+			if name == "1.58.245.173.origin.asn.cymru.com" {
+				return []string{"13335 | 173.245.58.0/24 | US | arin | 2014-03-28"}, nil
+			}
+			return []string{"64500 | 192.0.2.0/24 | US | arin | 2000-01-01"}, nil
+		},
+	}
+
+	result, err := c.Classify("example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Details) != 2 || !result.Details[0].Anycast || result.Details[0].ASNs[0] != "AS13335" {
+		t.Fatalf("unexpected nameserver details: %+v", result.Details)
+	}
+
+	r := result.Resilience
+	// partial anycast 15 + two nameservers 15 + two /24s 15 + two ASNs 15 + multi-provider 20
+	if r.Anycast != "partial" || r.Score != 80 || r.Grade != "A" {
+		t.Errorf("unexpected resilience: %+v", r)
+	}
+}
+
+func TestScoreResilience_SingleServer(t *testing.T) {
+	r := ScoreResilience([]NameserverInfo{{Host: "ns1.example.com", IPs: []string{"192.0.2.53"}}}, false)
+	if r.Score != 0 || r.Grade != "F" || r.Anycast != "none" {
+		t.Errorf("unexpected resilience: %+v", r)
+	}
+}
+
+func TestClassifyUsage(t *testing.T) {
+	tests := []struct {
+		nameservers []string
+		usage       string
+	}{
+		{[]string{"ns1.sedoparking.com", "ns2.sedoparking.com"}, UsageParked},
+		{[]string{"ns1.bodis.com", "ada.ns.cloudflare.com"}, UsageParked},
+		{[]string{"ns1.microsoftinternetsafety.net", "ns1.bodis.com"}, UsageSinkholed},
+		{[]string{"ns1.sinkhole.example.net"}, UsageSinkholed},
+		{[]string{"ns1.fbi.seized.gov", "ns1.microsoftinternetsafety.net"}, UsageSeized},
+		{[]string{"ns1.domaincontrol.com", "ns2.domaincontrol.com"}, UsageRegistrarDefault},
+		{[]string{"ada.ns.cloudflare.com", "ns1.domaincontrol.com"}, UsageActive},
+		{[]string{"ns1.example.com"}, UsageActive},
+	}
+	for _, tt := range tests {
+		if got := ClassifyUsage(Group("example.com", tt.nameservers)); got != tt.usage {
+			t.Errorf("%v: usage %q, want %q", tt.nameservers, got, tt.usage)
+		}
+	}
+	if ClassifyUsage(nil) != "" {
+		t.Error("usage reported without nameservers")
+	}
+}
@@ -0,0 +1,75 @@
+package provider
+
+// Categories of nameservers that say how a domain is used rather than who
+// hosts it.
+const (
+	CategoryParking  = "parking"
+	CategorySinkhole = "sinkhole"
+	CategorySeizure  = "seizure"
+)
+
+// Usage classes of a domain's NS set.
+const (
+	UsageActive           = "active"
+	UsageParked           = "parked"
+	UsageSinkholed        = "sinkholed"
+	UsageSeized           = "seized"
+	UsageRegistrarDefault = "registrar default"
+)
+
+// reputation lists nameservers of parking services, which serve ads or a
+// for-sale page on every domain delegated to them, and of sinkholes, which
+// security teams and law enforcement point malicious domains at, and of
+// law-enforcement seizures. It is checked before the provider signatures.
+var reputation = []signature{
+	{".seized.gov", "US law-enforcement seizure", CategorySeizure},
+	{".sedoparking.com", "Sedo Parking", CategoryParking},
+	{".parkingcrew.net", "ParkingCrew", CategoryParking},
+	{".bodis.com", "Bodis", CategoryParking},
+	{".above.com", "Above.com", CategoryParking},
+	{".parklogic.com", "ParkLogic", CategoryParking},
+	{".dan.com", "Dan.com", CategoryParking},
+	{".afternic.com", "Afternic", CategoryParking},
+	{".uniregistrymarket.link", "Uniregistry Market", CategoryParking},
+	{".undeveloped.com", "Undeveloped", CategoryParking},
+	{".hugedomains.com", "HugeDomains", CategoryParking},
+	{".microsoftinternetsafety.net", "Microsoft DCU sinkhole", CategorySinkhole},
+	{".cwgsh.com", "Conficker Working Group sinkhole", CategorySinkhole},
+	{".cwgsh.net", "Conficker Working Group sinkhole", CategorySinkhole},
+	{".cwgsh.org", "Conficker Working Group sinkhole", CategorySinkhole},
+	{".shadowserver.org", "Shadowserver sinkhole", CategorySinkhole},
+	{"sinkhole", "Sinkhole", CategorySinkhole},
+}
+
+// ClassifyUsage tells from a domain's providers how it is used: seized when
+// any nameserver is a law-enforcement seizure server, sinkholed when any is
+// a sinkhole, parked when any belongs to a parking
+// service, registrar default when all are the registrar's default
+// nameservers, and active otherwise. It is "" without providers.
+func ClassifyUsage(providers []Provider) string {
+	if len(providers) == 0 {
+		return ""
+	}
+	for _, p := range providers {
+		if p.Category == CategorySeizure {
+			return UsageSeized
+		}
+	}
+	usage := UsageRegistrarDefault
+	parked := false
+	for _, p := range providers {
+		switch p.Category {
+		case CategorySinkhole:
+			return UsageSinkholed
+		case CategoryParking:
+			parked = true
+		case CategoryRegistrar:
+		default:
+			usage = UsageActive
+		}
+	}
+	if parked {
+		return UsageParked
+	}
+	return usage
+}
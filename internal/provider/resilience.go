@@ -0,0 +1,187 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// anycastProviders are operators known to serve their nameservers from
+// anycast networks.
+var anycastProviders = map[string]bool{
+	"Cloudflare":       true,
+	"Amazon Route 53":  true,
+	"NS1":              true,
+	"Azure DNS":        true,
+	"Google Cloud DNS": true,
+	"Google":           true,
+	"UltraDNS":         true,
+	"Oracle Dyn":       true,
+	"Akamai Edge DNS":  true,
+	"DNSimple":         true,
+	"DNS Made Easy":    true,
+	"Constellix":       true,
+	"Vercel":           true,
+	"Netlify":          true,
+	"GoDaddy":          true,
+}
+
+// NameserverInfo describes one nameserver's network placement.
+type NameserverInfo struct {
+	Host     string   `json:"host"`
+	Provider string   `json:"provider"`
+	IPs      []string `json:"ips,omitempty"`
+	ASNs     []string `json:"asns,omitempty"`
+	Anycast  bool     `json:"anycast"`
+}
+
+// Resilience summarizes how well the DNS setup survives outages and
+// attacks, as a 0-100 infrastructure-quality score.
+type Resilience struct {
+	Score    int      `json:"score"`
+	Grade    string   `json:"grade"`
+	Anycast  string   `json:"anycast"`
+	Prefixes int      `json:"ipv4_prefixes"`
+	ASNs     int      `json:"asns"`
+	Factors  []string `json:"factors"`
+}
+
+// IsAnycast reports whether a provider is known to run anycast nameservers.
+func IsAnycast(provider string) bool {
+	return anycastProviders[provider]
+}
+
+// inspect resolves each nameserver and looks up the origin ASN of its
+// addresses.
+func (c *Classifier) inspect(domain string, nameservers []string) []NameserverInfo {
+	infos := make([]NameserverInfo, 0, len(nameservers))
+	for _, ns := range nameservers {
+		name, _ := Identify(domain, ns)
+		info := NameserverInfo{Host: ns, Provider: name, Anycast: IsAnycast(name)}
+		if ips, err := c.lookupIP(ns); err == nil {
+			for _, ip := range ips {
+				info.IPs = append(info.IPs, ip.String())
+				if asn := c.originASN(ip); asn != "" {
+					info.ASNs = appendUnique(info.ASNs, asn)
+				}
+			}
+			sort.Strings(info.IPs)
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// originASN uses Team Cymru's IP-to-ASN DNS service. Only IPv4 is looked up.
+func (c *Classifier) originASN(ip net.IP) string {
+	v4 := ip.To4()
+	if v4 == nil {
+		return ""
+	}
+	name := fmt.Sprintf("%d.%d.%d.%d.origin.asn.cymru.com", v4[3], v4[2], v4[1], v4[0])
+	txts, err := c.lookupTXT(name)
+	if err != nil || len(txts) == 0 {
+		return ""
+	}
+	// "13335 | 173.245.58.0/24 | US | arin | 2014-03-28"
+	asn := strings.TrimSpace(strings.SplitN(txts[0], "|", 2)[0])
+	if fields := strings.Fields(asn); len(fields) > 0 {
+		return "AS" + fields[0]
+	}
+	return ""
+}
+
+// ScoreResilience grades a nameserver set.
+func ScoreResilience(infos []NameserverInfo, multiProvider bool) *Resilience {
+	r := &Resilience{}
+
+	anycast := 0
+	prefixes := make(map[string]bool)
+	asns := make(map[string]bool)
+	for _, info := range infos {
+		if info.Anycast {
+			anycast++
+		}
+		for _, ip := range info.IPs {
+			if v4 := net.ParseIP(ip).To4(); v4 != nil {
+				prefixes[fmt.Sprintf("%d.%d.%d", v4[0], v4[1], v4[2])] = true
+			}
+		}
+		for _, asn := range info.ASNs {
+			asns[asn] = true
+		}
+	}
+	r.Prefixes = len(prefixes)
+	r.ASNs = len(asns)
+
+	switch {
+	case len(infos) > 0 && anycast == len(infos):
+		r.Anycast = "all"
+		r.Score += 30
+		r.Factors = append(r.Factors, "All nameservers are anycast (+30)")
+	case anycast > 0:
+		r.Anycast = "partial"
+		r.Score += 15
+		r.Factors = append(r.Factors, fmt.Sprintf("%d of %d nameservers are anycast (+15)", anycast, len(infos)))
+	default:
+		r.Anycast = "none"
+		r.Factors = append(r.Factors, "No anycast nameservers; each is a single point of presence")
+	}
+
+	switch {
+	case len(infos) >= 4:
+		r.Score += 20
+		r.Factors = append(r.Factors, fmt.Sprintf("%d nameservers (+20)", len(infos)))
+	case len(infos) >= 2:
+		r.Score += 15
+		r.Factors = append(r.Factors, fmt.Sprintf("%d nameservers (+15)", len(infos)))
+	default:
+		r.Factors = append(r.Factors, "Fewer than 2 nameservers (RFC 1035 requires at least 2)")
+	}
+
+	if r.Prefixes >= 2 {
+		r.Score += 15
+		r.Factors = append(r.Factors, fmt.Sprintf("Nameservers span %d IPv4 /24 networks (+15)", r.Prefixes))
+	} else if len(prefixes) == 1 {
+		r.Factors = append(r.Factors, "All nameservers share one /24 network")
+	}
+
+	if r.ASNs >= 2 {
+		r.Score += 15
+		r.Factors = append(r.Factors, fmt.Sprintf("Nameservers are announced by %d ASNs (+15)", r.ASNs))
+	} else if r.ASNs == 1 {
+		r.Factors = append(r.Factors, "All nameservers are in one autonomous system")
+	}
+
+	if multiProvider {
+		r.Score += 20
+		r.Factors = append(r.Factors, "Multiple DNS providers (+20)")
+	}
+
+	r.Grade = grade(r.Score)
+	return r
+}
+
+func grade(score int) string {
+	switch {
+	case score >= 80:
+		return "A"
+	case score >= 65:
+		return "B"
+	case score >= 50:
+		return "C"
+	case score >= 35:
+		return "D"
+	}
+	return "F"
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, existing := range list {
+		if existing == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
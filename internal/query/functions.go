@@ -0,0 +1,485 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type function struct {
+	arity int
+	fn    func(args []interface{}) (interface{}, error)
+}
+
+// functions are the JMESPath built-ins; arity is the number of arguments,
+// or -1 for variadic. They are registered in init because map and the
+// *_by functions call back into eval.
+var functions map[string]function
+
+func init() {
+	functions = map[string]function{
+		"abs":         {1, fnAbs},
+		"avg":         {1, fnAvg},
+		"ceil":        {1, fnCeil},
+		"contains":    {2, fnContains},
+		"ends_with":   {2, fnEndsWith},
+		"floor":       {1, fnFloor},
+		"join":        {2, fnJoin},
+		"keys":        {1, fnKeys},
+		"length":      {1, fnLength},
+		"map":         {2, fnMap},
+		"max":         {1, fnMax},
+		"max_by":      {2, fnMaxBy},
+		"merge":       {-1, fnMerge},
+		"min":         {1, fnMin},
+		"min_by":      {2, fnMinBy},
+		"not_null":    {-1, fnNotNull},
+		"reverse":     {1, fnReverse},
+		"sort":        {1, fnSort},
+		"sort_by":     {2, fnSortBy},
+		"starts_with": {2, fnStartsWith},
+		"sum":         {1, fnSum},
+		"to_array":    {1, fnToArray},
+		"to_number":   {1, fnToNumber},
+		"to_string":   {1, fnToString},
+		"type":        {1, fnType},
+		"values":      {1, fnValues},
+	}
+}
+
+func call(name string, args []interface{}) (interface{}, error) {
+	f, ok := functions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function: %s()", name)
+	}
+	if f.arity >= 0 && len(args) != f.arity {
+		return nil, fmt.Errorf("%s() takes %d argument(s), got %d", name, f.arity, len(args))
+	}
+	if f.arity < 0 && len(args) == 0 {
+		return nil, fmt.Errorf("%s() takes at least 1 argument", name)
+	}
+	v, err := f.fn(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s(): %v", name, err)
+	}
+	return v, nil
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case expref:
+		return "expref"
+	}
+	return "unknown"
+}
+
+func invalidType(v interface{}, want string) error {
+	return fmt.Errorf("expected %s, got %s", want, typeName(v))
+}
+
+func number(v interface{}) (float64, error) {
+	n, ok := v.(float64)
+	if !ok {
+		return 0, invalidType(v, "number")
+	}
+	return n, nil
+}
+
+func array(v interface{}) ([]interface{}, error) {
+	a, ok := v.([]interface{})
+	if !ok {
+		return nil, invalidType(v, "array")
+	}
+	return a, nil
+}
+
+func str(v interface{}) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", invalidType(v, "string")
+	}
+	return s, nil
+}
+
+func fnAbs(args []interface{}) (interface{}, error) {
+	n, err := number(args[0])
+	return math.Abs(n), err
+}
+
+func fnCeil(args []interface{}) (interface{}, error) {
+	n, err := number(args[0])
+	return math.Ceil(n), err
+}
+
+func fnFloor(args []interface{}) (interface{}, error) {
+	n, err := number(args[0])
+	return math.Floor(n), err
+}
+
+func fnSum(args []interface{}) (interface{}, error) {
+	list, err := array(args[0])
+	if err != nil {
+		return nil, err
+	}
+	total := 0.0
+	for _, item := range list {
+		n, err := number(item)
+		if err != nil {
+			return nil, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+func fnAvg(args []interface{}) (interface{}, error) {
+	list, err := array(args[0])
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	total, err := fnSum(args)
+	if err != nil {
+		return nil, err
+	}
+	return total.(float64) / float64(len(list)), nil
+}
+
+func fnContains(args []interface{}) (interface{}, error) {
+	switch subject := args[0].(type) {
+	case string:
+		s, err := str(args[1])
+		if err != nil {
+			return false, nil
+		}
+		return strings.Contains(subject, s), nil
+	case []interface{}:
+		for _, item := range subject {
+			if compare(tEQ, item, args[1]) == true {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return nil, invalidType(args[0], "array or string")
+}
+
+func fnStartsWith(args []interface{}) (interface{}, error) {
+	s, err := str(args[0])
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := str(args[1])
+	return strings.HasPrefix(s, prefix), err
+}
+
+func fnEndsWith(args []interface{}) (interface{}, error) {
+	s, err := str(args[0])
+	if err != nil {
+		return nil, err
+	}
+	suffix, err := str(args[1])
+	return strings.HasSuffix(s, suffix), err
+}
+
+func fnJoin(args []interface{}) (interface{}, error) {
+	sep, err := str(args[0])
+	if err != nil {
+		return nil, err
+	}
+	list, err := array(args[1])
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]string, len(list))
+	for i, item := range list {
+		if parts[i], err = str(item); err != nil {
+			return nil, err
+		}
+	}
+	return strings.Join(parts, sep), nil
+}
+
+func fnKeys(args []interface{}) (interface{}, error) {
+	m, ok := args[0].(map[string]interface{})
+	if !ok {
+		return nil, invalidType(args[0], "object")
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]interface{}, len(keys))
+	for i, k := range keys {
+		out[i] = k
+	}
+	return out, nil
+}
+
+func fnValues(args []interface{}) (interface{}, error) {
+	keys, err := fnKeys(args)
+	if err != nil {
+		return nil, err
+	}
+	m := args[0].(map[string]interface{})
+	out := make([]interface{}, 0, len(m))
+	for _, k := range keys.([]interface{}) {
+		out = append(out, m[k.(string)])
+	}
+	return out, nil
+}
+
+func fnLength(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case string:
+		return float64(len([]rune(v))), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	}
+	return nil, invalidType(args[0], "string, array or object")
+}
+
+func fnMap(args []interface{}) (interface{}, error) {
+	ref, ok := args[0].(expref)
+	if !ok {
+		return nil, invalidType(args[0], "expref")
+	}
+	list, err := array(args[1])
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, len(list))
+	for i, item := range list {
+		if out[i], err = eval(ref.ast, item); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// less orders two numbers or two strings.
+func less(a, b interface{}) (bool, error) {
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		if !ok {
+			return false, invalidType(b, "number")
+		}
+		return x < y, nil
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			return false, invalidType(b, "string")
+		}
+		return x < y, nil
+	}
+	return false, invalidType(a, "number or string")
+}
+
+// keyed evaluates an optional key expression for every element.
+func keyed(list []interface{}, ref *expref) ([]interface{}, error) {
+	if ref == nil {
+		return list, nil
+	}
+	keys := make([]interface{}, len(list))
+	for i, item := range list {
+		k, err := eval(ref.ast, item)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = k
+	}
+	return keys, nil
+}
+
+func sortList(list []interface{}, ref *expref) ([]interface{}, error) {
+	keys, err := keyed(list, ref)
+	if err != nil {
+		return nil, err
+	}
+	idx := make([]int, len(list))
+	for i := range idx {
+		idx[i] = i
+	}
+	var sortErr error
+	sort.SliceStable(idx, func(i, j int) bool {
+		l, err := less(keys[idx[i]], keys[idx[j]])
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+		return l
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+	out := make([]interface{}, len(list))
+	for i, j := range idx {
+		out[i] = list[j]
+	}
+	return out, nil
+}
+
+func fnSort(args []interface{}) (interface{}, error) {
+	list, err := array(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return sortList(list, nil)
+}
+
+func fnSortBy(args []interface{}) (interface{}, error) {
+	list, err := array(args[0])
+	if err != nil {
+		return nil, err
+	}
+	ref, ok := args[1].(expref)
+	if !ok {
+		return nil, invalidType(args[1], "expref")
+	}
+	return sortList(list, &ref)
+}
+
+func extreme(list []interface{}, ref *expref, max bool) (interface{}, error) {
+	if len(list) == 0 {
+		return nil, nil
+	}
+	sorted, err := sortList(list, ref)
+	if err != nil {
+		return nil, err
+	}
+	if max {
+		return sorted[len(sorted)-1], nil
+	}
+	return sorted[0], nil
+}
+
+func fnMax(args []interface{}) (interface{}, error) {
+	list, err := array(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return extreme(list, nil, true)
+}
+
+func fnMin(args []interface{}) (interface{}, error) {
+	list, err := array(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return extreme(list, nil, false)
+}
+
+func fnMaxBy(args []interface{}) (interface{}, error) {
+	list, err := array(args[0])
+	if err != nil {
+		return nil, err
+	}
+	ref, ok := args[1].(expref)
+	if !ok {
+		return nil, invalidType(args[1], "expref")
+	}
+	return extreme(list, &ref, true)
+}
+
+func fnMinBy(args []interface{}) (interface{}, error) {
+	list, err := array(args[0])
+	if err != nil {
+		return nil, err
+	}
+	ref, ok := args[1].(expref)
+	if !ok {
+		return nil, invalidType(args[1], "expref")
+	}
+	return extreme(list, &ref, false)
+}
+
+func fnMerge(args []interface{}) (interface{}, error) {
+	out := make(map[string]interface{})
+	for _, arg := range args {
+		m, ok := arg.(map[string]interface{})
+		if !ok {
+			return nil, invalidType(arg, "object")
+		}
+		for k, v := range m {
+			out[k] = v
+		}
+	}
+	return out, nil
+}
+
+func fnNotNull(args []interface{}) (interface{}, error) {
+	for _, arg := range args {
+		if arg != nil {
+			return arg, nil
+		}
+	}
+	return nil, nil
+}
+
+func fnReverse(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case string:
+		r := []rune(v)
+		for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+			r[i], r[j] = r[j], r[i]
+		}
+		return string(r), nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[len(v)-1-i] = item
+		}
+		return out, nil
+	}
+	return nil, invalidType(args[0], "array or string")
+}
+
+func fnToArray(args []interface{}) (interface{}, error) {
+	if list, ok := args[0].([]interface{}); ok {
+		return list, nil
+	}
+	return []interface{}{args[0]}, nil
+}
+
+func fnToNumber(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case float64:
+		return v, nil
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, nil
+		}
+		return n, nil
+	}
+	return nil, nil
+}
+
+func fnToString(args []interface{}) (interface{}, error) {
+	if s, ok := args[0].(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(args[0])
+	return string(data), err
+}
+
+func fnType(args []interface{}) (interface{}, error) {
+	return typeName(args[0]), nil
+}
@@ -0,0 +1,236 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenType int
+
+const (
+	tEOF tokenType = iota
+	tIdentifier
+	tQuotedIdentifier
+	tNumber
+	tLiteral
+	tDot
+	tStar
+	tComma
+	tColon
+	tLParen
+	tRParen
+	tLBrace
+	tRBrace
+	tLBracket
+	tRBracket
+	tFilter
+	tFlatten
+	tPipe
+	tOr
+	tAnd
+	tNot
+	tExpref
+	tCurrent
+	tEQ
+	tNE
+	tLT
+	tLTE
+	tGT
+	tGTE
+)
+
+type token struct {
+	typ   tokenType
+	text  string
+	value interface{} // literals
+	num   int
+	pos   int
+}
+
+// bindingPowers drive the Pratt parser; they follow the JMESPath
+// reference implementation.
+var bindingPowers = map[tokenType]int{
+	tPipe:     1,
+	tOr:       2,
+	tAnd:      3,
+	tEQ:       5,
+	tNE:       5,
+	tLT:       5,
+	tLTE:      5,
+	tGT:       5,
+	tGTE:      5,
+	tFlatten:  9,
+	tStar:     20,
+	tFilter:   21,
+	tDot:      40,
+	tNot:      45,
+	tLBrace:   50,
+	tLBracket: 55,
+	tLParen:   60,
+}
+
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	i := 0
+	emit := func(typ tokenType, text string, width int) {
+		tokens = append(tokens, token{typ: typ, text: text, pos: i})
+		i += width
+	}
+
+	for i < len(expr) {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isIdentStart(c):
+			start := i
+			for i < len(expr) && isIdentChar(expr[i]) {
+				i++
+			}
+			tokens = append(tokens, token{typ: tIdentifier, text: expr[start:i], pos: start})
+		case c == '-' || (c >= '0' && c <= '9'):
+			start := i
+			i++
+			for i < len(expr) && expr[i] >= '0' && expr[i] <= '9' {
+				i++
+			}
+			n, err := strconv.Atoi(expr[start:i])
+			if err != nil {
+				return nil, syntaxError(expr, start, "invalid number")
+			}
+			tokens = append(tokens, token{typ: tNumber, text: expr[start:i], num: n, pos: start})
+		case c == '"':
+			end, err := closing(expr, i, '"')
+			if err != nil {
+				return nil, err
+			}
+			var name string
+			if err := json.Unmarshal([]byte(expr[i:end+1]), &name); err != nil {
+				return nil, syntaxError(expr, i, "invalid quoted identifier")
+			}
+			tokens = append(tokens, token{typ: tQuotedIdentifier, text: name, pos: i})
+			i = end + 1
+		case c == '\'':
+			end, err := closing(expr, i, '\'')
+			if err != nil {
+				return nil, err
+			}
+			raw := strings.ReplaceAll(expr[i+1:end], `\'`, `'`)
+			tokens = append(tokens, token{typ: tLiteral, text: raw, value: raw, pos: i})
+			i = end + 1
+		case c == '`':
+			end, err := closing(expr, i, '`')
+			if err != nil {
+				return nil, err
+			}
+			raw := strings.ReplaceAll(expr[i+1:end], "\\`", "`")
+			var value interface{}
+			if err := json.Unmarshal([]byte(raw), &value); err != nil {
+				return nil, syntaxError(expr, i, "invalid JSON literal")
+			}
+			tokens = append(tokens, token{typ: tLiteral, text: raw, value: value, pos: i})
+			i = end + 1
+		case c == '[':
+			switch next(expr, i) {
+			case '?':
+				emit(tFilter, "[?", 2)
+			case ']':
+				emit(tFlatten, "[]", 2)
+			default:
+				emit(tLBracket, "[", 1)
+			}
+		case c == '|':
+			if next(expr, i) == '|' {
+				emit(tOr, "||", 2)
+			} else {
+				emit(tPipe, "|", 1)
+			}
+		case c == '&':
+			if next(expr, i) == '&' {
+				emit(tAnd, "&&", 2)
+			} else {
+				emit(tExpref, "&", 1)
+			}
+		case c == '!':
+			if next(expr, i) == '=' {
+				emit(tNE, "!=", 2)
+			} else {
+				emit(tNot, "!", 1)
+			}
+		case c == '=':
+			if next(expr, i) != '=' {
+				return nil, syntaxError(expr, i, "expected ==")
+			}
+			emit(tEQ, "==", 2)
+		case c == '<':
+			if next(expr, i) == '=' {
+				emit(tLTE, "<=", 2)
+			} else {
+				emit(tLT, "<", 1)
+			}
+		case c == '>':
+			if next(expr, i) == '=' {
+				emit(tGTE, ">=", 2)
+			} else {
+				emit(tGT, ">", 1)
+			}
+		default:
+			typ, ok := map[byte]tokenType{
+				'.': tDot, '*': tStar, ',': tComma, ':': tColon, '(': tLParen,
+				')': tRParen, '{': tLBrace, '}': tRBrace, ']': tRBracket, '@': tCurrent,
+			}[c]
+			if !ok {
+				return nil, syntaxError(expr, i, fmt.Sprintf("unexpected character %q", c))
+			}
+			emit(typ, string(c), 1)
+		}
+	}
+	tokens = append(tokens, token{typ: tEOF, pos: len(expr)})
+	return tokens, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+func next(expr string, i int) byte {
+	if i+1 < len(expr) {
+		return expr[i+1]
+	}
+	return 0
+}
+
+// closing returns the index of the unescaped delimiter closing the one at
+// start.
+func closing(expr string, start int, delim byte) (int, error) {
+	for i := start + 1; i < len(expr); i++ {
+		switch expr[i] {
+		case '\\':
+			i++
+		case delim:
+			return i, nil
+		}
+	}
+	return 0, syntaxError(expr, start, fmt.Sprintf("unterminated %c", delim))
+}
+
+// SyntaxError reports an invalid expression and where it went wrong.
+type SyntaxError struct {
+	Expression string
+	Offset     int
+	Msg        string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid query at offset %d: %s\n  %s\n  %s^", e.Offset, e.Msg, e.Expression, strings.Repeat(" ", e.Offset))
+}
+
+func syntaxError(expr string, offset int, msg string) error {
+	return &SyntaxError{Expression: expr, Offset: offset, Msg: msg}
+}
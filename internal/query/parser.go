@@ -0,0 +1,390 @@
+package query
+
+import "fmt"
+
+type nodeType int
+
+const (
+	nIdentity nodeType = iota
+	nCurrent
+	nField
+	nLiteral
+	nSubexpression
+	nIndex
+	nSlice
+	nIndexExpression
+	nProjection
+	nValueProjection
+	nFilterProjection
+	nFlatten
+	nPipe
+	nOr
+	nAnd
+	nNot
+	nComparator
+	nMultiSelectList
+	nMultiSelectHash
+	nKeyValue
+	nFunction
+	nExpref
+)
+
+type node struct {
+	typ      nodeType
+	value    interface{} // field name, literal, index, slice bounds, function name, key
+	op       tokenType   // comparator
+	children []*node
+}
+
+type parser struct {
+	expr   string
+	tokens []token
+	pos    int
+}
+
+func parse(expr string) (*node, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{expr: expr, tokens: tokens}
+	ast, err := p.parseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.current().typ != tEOF {
+		return nil, p.errorf("unexpected %q", p.current().text)
+	}
+	return ast, nil
+}
+
+func (p *parser) current() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) lookahead(n int) tokenType {
+	if p.pos+n < len(p.tokens) {
+		return p.tokens[p.pos+n].typ
+	}
+	return tEOF
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.pos]
+	if t.typ != tEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(typ tokenType, what string) error {
+	if p.current().typ != typ {
+		return p.errorf("expected %s", what)
+	}
+	p.advance()
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return syntaxError(p.expr, p.current().pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) parseExpression(bp int) (*node, error) {
+	left, err := p.nud(p.advance())
+	if err != nil {
+		return nil, err
+	}
+	for bp < bindingPowers[p.current().typ] {
+		if left, err = p.led(p.advance(), left); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) nud(t token) (*node, error) {
+	identity := &node{typ: nIdentity}
+	switch t.typ {
+	case tLiteral:
+		return &node{typ: nLiteral, value: t.value}, nil
+	case tIdentifier:
+		return &node{typ: nField, value: t.text}, nil
+	case tQuotedIdentifier:
+		if p.current().typ == tLParen {
+			return nil, p.errorf("quoted identifiers cannot be function names")
+		}
+		return &node{typ: nField, value: t.text}, nil
+	case tStar:
+		right, err := p.parseProjectionRHS(bindingPowers[tStar])
+		if err != nil {
+			return nil, err
+		}
+		return &node{typ: nValueProjection, children: []*node{identity, right}}, nil
+	case tFilter:
+		return p.parseFilter(identity)
+	case tLBrace:
+		return p.parseMultiSelectHash()
+	case tFlatten:
+		right, err := p.parseProjectionRHS(bindingPowers[tFlatten])
+		if err != nil {
+			return nil, err
+		}
+		flat := &node{typ: nFlatten, children: []*node{identity}}
+		return &node{typ: nProjection, children: []*node{flat, right}}, nil
+	case tLBracket:
+		switch {
+		case p.current().typ == tNumber || p.current().typ == tColon:
+			index, err := p.parseIndexExpression()
+			if err != nil {
+				return nil, err
+			}
+			return p.projectIfSlice(identity, index)
+		case p.current().typ == tStar && p.lookahead(1) == tRBracket:
+			p.advance()
+			p.advance()
+			right, err := p.parseProjectionRHS(bindingPowers[tStar])
+			if err != nil {
+				return nil, err
+			}
+			return &node{typ: nProjection, children: []*node{identity, right}}, nil
+		}
+		return p.parseMultiSelectList()
+	case tCurrent:
+		return &node{typ: nCurrent}, nil
+	case tExpref:
+		expr, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		return &node{typ: nExpref, children: []*node{expr}}, nil
+	case tNot:
+		expr, err := p.parseExpression(bindingPowers[tNot])
+		if err != nil {
+			return nil, err
+		}
+		return &node{typ: nNot, children: []*node{expr}}, nil
+	case tLParen:
+		expr, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tRParen, ")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	case tEOF:
+		return nil, syntaxError(p.expr, t.pos, "unexpected end of expression")
+	}
+	return nil, syntaxError(p.expr, t.pos, fmt.Sprintf("unexpected %q", t.text))
+}
+
+func (p *parser) led(t token, left *node) (*node, error) {
+	switch t.typ {
+	case tDot:
+		if p.current().typ != tStar {
+			right, err := p.parseDotRHS(bindingPowers[tDot])
+			if err != nil {
+				return nil, err
+			}
+			return &node{typ: nSubexpression, children: []*node{left, right}}, nil
+		}
+		p.advance()
+		right, err := p.parseProjectionRHS(bindingPowers[tDot])
+		if err != nil {
+			return nil, err
+		}
+		return &node{typ: nValueProjection, children: []*node{left, right}}, nil
+	case tPipe, tOr, tAnd:
+		right, err := p.parseExpression(bindingPowers[t.typ])
+		if err != nil {
+			return nil, err
+		}
+		typ := map[tokenType]nodeType{tPipe: nPipe, tOr: nOr, tAnd: nAnd}[t.typ]
+		return &node{typ: typ, children: []*node{left, right}}, nil
+	case tLParen:
+		if left.typ != nField {
+			return nil, syntaxError(p.expr, t.pos, "invalid function call")
+		}
+		fn := &node{typ: nFunction, value: left.value}
+		for p.current().typ != tRParen {
+			arg, err := p.parseExpression(0)
+			if err != nil {
+				return nil, err
+			}
+			fn.children = append(fn.children, arg)
+			if p.current().typ == tComma {
+				p.advance()
+			} else if p.current().typ != tRParen {
+				return nil, p.errorf("expected , or )")
+			}
+		}
+		p.advance()
+		return fn, nil
+	case tFilter:
+		return p.parseFilter(left)
+	case tFlatten:
+		right, err := p.parseProjectionRHS(bindingPowers[tFlatten])
+		if err != nil {
+			return nil, err
+		}
+		flat := &node{typ: nFlatten, children: []*node{left}}
+		return &node{typ: nProjection, children: []*node{flat, right}}, nil
+	case tEQ, tNE, tLT, tLTE, tGT, tGTE:
+		right, err := p.parseExpression(bindingPowers[t.typ])
+		if err != nil {
+			return nil, err
+		}
+		return &node{typ: nComparator, op: t.typ, children: []*node{left, right}}, nil
+	case tLBracket:
+		if p.current().typ == tNumber || p.current().typ == tColon {
+			index, err := p.parseIndexExpression()
+			if err != nil {
+				return nil, err
+			}
+			return p.projectIfSlice(left, index)
+		}
+		if err := p.expect(tStar, "index, slice or *"); err != nil {
+			return nil, err
+		}
+		if err := p.expect(tRBracket, "]"); err != nil {
+			return nil, err
+		}
+		right, err := p.parseProjectionRHS(bindingPowers[tStar])
+		if err != nil {
+			return nil, err
+		}
+		return &node{typ: nProjection, children: []*node{left, right}}, nil
+	}
+	return nil, syntaxError(p.expr, t.pos, fmt.Sprintf("unexpected %q", t.text))
+}
+
+func (p *parser) parseIndexExpression() (*node, error) {
+	if p.lookahead(0) == tColon || p.lookahead(1) == tColon {
+		return p.parseSlice()
+	}
+	index := p.advance().num
+	if err := p.expect(tRBracket, "]"); err != nil {
+		return nil, err
+	}
+	return &node{typ: nIndex, value: index}, nil
+}
+
+func (p *parser) parseSlice() (*node, error) {
+	var parts [3]*int
+	i := 0
+	for p.current().typ != tRBracket && i < 3 {
+		switch p.current().typ {
+		case tColon:
+			i++
+		case tNumber:
+			n := p.current().num
+			parts[i] = &n
+		default:
+			return nil, p.errorf("expected number or : in slice")
+		}
+		p.advance()
+	}
+	if err := p.expect(tRBracket, "]"); err != nil {
+		return nil, err
+	}
+	return &node{typ: nSlice, value: parts}, nil
+}
+
+func (p *parser) projectIfSlice(left, index *node) (*node, error) {
+	expr := &node{typ: nIndexExpression, children: []*node{left, index}}
+	if index.typ != nSlice {
+		return expr, nil
+	}
+	right, err := p.parseProjectionRHS(bindingPowers[tStar])
+	if err != nil {
+		return nil, err
+	}
+	return &node{typ: nProjection, children: []*node{expr, right}}, nil
+}
+
+func (p *parser) parseFilter(left *node) (*node, error) {
+	cond, err := p.parseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(tRBracket, "]"); err != nil {
+		return nil, err
+	}
+	right := &node{typ: nIdentity}
+	if p.current().typ != tFlatten {
+		if right, err = p.parseProjectionRHS(bindingPowers[tFilter]); err != nil {
+			return nil, err
+		}
+	}
+	return &node{typ: nFilterProjection, children: []*node{left, right, cond}}, nil
+}
+
+func (p *parser) parseProjectionRHS(bp int) (*node, error) {
+	switch {
+	case bindingPowers[p.current().typ] < 10:
+		return &node{typ: nIdentity}, nil
+	case p.current().typ == tLBracket, p.current().typ == tFilter:
+		return p.parseExpression(bp)
+	case p.current().typ == tDot:
+		p.advance()
+		return p.parseDotRHS(bp)
+	}
+	return nil, p.errorf("unexpected %q after projection", p.current().text)
+}
+
+func (p *parser) parseDotRHS(bp int) (*node, error) {
+	switch p.current().typ {
+	case tIdentifier, tQuotedIdentifier, tStar:
+		return p.parseExpression(bp)
+	case tLBracket:
+		p.advance()
+		return p.parseMultiSelectList()
+	case tLBrace:
+		p.advance()
+		return p.parseMultiSelectHash()
+	}
+	return nil, p.errorf("expected identifier, [ or { after .")
+}
+
+func (p *parser) parseMultiSelectList() (*node, error) {
+	list := &node{typ: nMultiSelectList}
+	for {
+		expr, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		list.children = append(list.children, expr)
+		if p.current().typ == tRBracket {
+			p.advance()
+			return list, nil
+		}
+		if err := p.expect(tComma, ", or ]"); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseMultiSelectHash() (*node, error) {
+	hash := &node{typ: nMultiSelectHash}
+	for {
+		key := p.advance()
+		if key.typ != tIdentifier && key.typ != tQuotedIdentifier {
+			return nil, syntaxError(p.expr, key.pos, "expected key name")
+		}
+		if err := p.expect(tColon, ":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		hash.children = append(hash.children, &node{typ: nKeyValue, value: key.text, children: []*node{value}})
+		if p.current().typ == tRBrace {
+			p.advance()
+			return hash, nil
+		}
+		if err := p.expect(tComma, ", or }"); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,325 @@
+// Package query evaluates JMESPath expressions (https://jmespath.org)
+// against JSON data.
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Expression is a compiled JMESPath expression.
+type Expression struct {
+	source string
+	ast    *node
+}
+
+// Compile parses a JMESPath expression.
+func Compile(expr string) (*Expression, error) {
+	ast, err := parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &Expression{source: expr, ast: ast}, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Search evaluates the expression against v, which is first converted to
+// its JSON form.
+func (e *Expression) Search(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return eval(e.ast, doc)
+}
+
+// expref is the value of an &expression argument.
+type expref struct {
+	ast *node
+}
+
+func eval(n *node, value interface{}) (interface{}, error) {
+	switch n.typ {
+	case nIdentity, nCurrent:
+		return value, nil
+	case nLiteral:
+		return n.value, nil
+	case nField:
+		if m, ok := value.(map[string]interface{}); ok {
+			return m[n.value.(string)], nil
+		}
+		return nil, nil
+	case nSubexpression, nIndexExpression:
+		left, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		return eval(n.children[1], left)
+	case nIndex:
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		i := n.value.(int)
+		if i < 0 {
+			i += len(list)
+		}
+		if i < 0 || i >= len(list) {
+			return nil, nil
+		}
+		return list[i], nil
+	case nSlice:
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		return slice(list, n.value.([3]*int))
+	case nProjection:
+		left, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		list, ok := left.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		return project(list, n.children[1])
+	case nValueProjection:
+		left, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := left.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, len(keys))
+		for i, k := range keys {
+			values[i] = m[k]
+		}
+		return project(values, n.children[1])
+	case nFilterProjection:
+		left, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		list, ok := left.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		var kept []interface{}
+		for _, item := range list {
+			cond, err := eval(n.children[2], item)
+			if err != nil {
+				return nil, err
+			}
+			if truthy(cond) {
+				kept = append(kept, item)
+			}
+		}
+		return project(kept, n.children[1])
+	case nFlatten:
+		left, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		list, ok := left.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		flat := []interface{}{}
+		for _, item := range list {
+			if inner, ok := item.([]interface{}); ok {
+				flat = append(flat, inner...)
+			} else {
+				flat = append(flat, item)
+			}
+		}
+		return flat, nil
+	case nPipe:
+		left, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		return eval(n.children[1], left)
+	case nOr:
+		left, err := eval(n.children[0], value)
+		if err != nil || truthy(left) {
+			return left, err
+		}
+		return eval(n.children[1], value)
+	case nAnd:
+		left, err := eval(n.children[0], value)
+		if err != nil || !truthy(left) {
+			return left, err
+		}
+		return eval(n.children[1], value)
+	case nNot:
+		v, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		return !truthy(v), nil
+	case nComparator:
+		left, err := eval(n.children[0], value)
+		if err != nil {
+			return nil, err
+		}
+		right, err := eval(n.children[1], value)
+		if err != nil {
+			return nil, err
+		}
+		return compare(n.op, left, right), nil
+	case nMultiSelectList:
+		if value == nil {
+			return nil, nil
+		}
+		list := make([]interface{}, len(n.children))
+		for i, child := range n.children {
+			v, err := eval(child, value)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = v
+		}
+		return list, nil
+	case nMultiSelectHash:
+		if value == nil {
+			return nil, nil
+		}
+		hash := make(map[string]interface{}, len(n.children))
+		for _, kv := range n.children {
+			v, err := eval(kv.children[0], value)
+			if err != nil {
+				return nil, err
+			}
+			hash[kv.value.(string)] = v
+		}
+		return hash, nil
+	case nExpref:
+		return expref{ast: n.children[0]}, nil
+	case nFunction:
+		args := make([]interface{}, len(n.children))
+		for i, child := range n.children {
+			v, err := eval(child, value)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v
+		}
+		return call(n.value.(string), args)
+	}
+	return nil, fmt.Errorf("unsupported expression")
+}
+
+func project(list []interface{}, right *node) (interface{}, error) {
+	out := []interface{}{}
+	for _, item := range list {
+		v, err := eval(right, item)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func slice(list []interface{}, parts [3]*int) (interface{}, error) {
+	step := 1
+	if parts[2] != nil {
+		step = *parts[2]
+	}
+	if step == 0 {
+		return nil, fmt.Errorf("slice step cannot be 0")
+	}
+	n := len(list)
+	bound := func(p *int, def int) int {
+		if p == nil {
+			return def
+		}
+		i := *p
+		if i < 0 {
+			i += n
+		}
+		lo, hi := 0, n
+		if step < 0 {
+			lo, hi = -1, n-1
+		}
+		if i < lo {
+			return lo
+		}
+		if i > hi {
+			return hi
+		}
+		return i
+	}
+
+	out := []interface{}{}
+	if step > 0 {
+		for i := bound(parts[0], 0); i < bound(parts[1], n); i += step {
+			out = append(out, list[i])
+		}
+	} else {
+		for i := bound(parts[0], n-1); i > bound(parts[1], -1); i += step {
+			out = append(out, list[i])
+		}
+	}
+	return out, nil
+}
+
+// truthy implements JMESPath truthiness: false, null and empty strings,
+// arrays and objects are false.
+func truthy(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+func compare(op tokenType, left, right interface{}) interface{} {
+	switch op {
+	case tEQ:
+		return reflect.DeepEqual(left, right)
+	case tNE:
+		return !reflect.DeepEqual(left, right)
+	}
+	a, ok1 := left.(float64)
+	b, ok2 := right.(float64)
+	if !ok1 || !ok2 {
+		return nil
+	}
+	switch op {
+	case tLT:
+		return a < b
+	case tLTE:
+		return a <= b
+	case tGT:
+		return a > b
+	}
+	return a >= b
+}
@@ -0,0 +1,96 @@
+package query
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const doc = `{
+  "domain": "example.com",
+  "whois_data": {"registrar": "Example Registrar", "name_servers": ["a.iana-servers.net", "b.iana-servers.net"]},
+  "valuation_data": {"estimated_value": 1500, "factors": {"length": 7, "brandable": true}},
+  "section_errors": [
+    {"section": "whois", "error": "timeout"},
+    {"section": "doma", "error": "unavailable"}
+  ],
+  "nested": [[1, 2], [3], 4],
+  "empty": []
+}`
+
+func TestSearch(t *testing.T) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(doc), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"domain", `"example.com"`},
+		{"whois_data.name_servers", `["a.iana-servers.net","b.iana-servers.net"]`},
+		{"whois_data.name_servers[0]", `"a.iana-servers.net"`},
+		{"whois_data.name_servers[-1]", `"b.iana-servers.net"`},
+		{"whois_data.missing.deeper", `null`},
+		{"section_errors[*].section", `["whois","doma"]`},
+		{"section_errors[?section == 'doma'].error | [0]", `"unavailable"`},
+		{"valuation_data.estimated_value > `1000`", `true`},
+		{"valuation_data.factors.*", `[true,7]`},
+		{"nested[]", `[1,2,3,4]`},
+		{"nested[:2]", `[[1,2],[3]]`},
+		{"nested[::-1]", `[4,[3],[1,2]]`},
+		{"{domain: domain, value: valuation_data.estimated_value}", `{"domain":"example.com","value":1500}`},
+		{"[domain, whois_data.registrar]", `["example.com","Example Registrar"]`},
+		{"length(section_errors)", `2`},
+		{"join(', ', whois_data.name_servers)", `"a.iana-servers.net, b.iana-servers.net"`},
+		{"sort_by(section_errors, &section)[0].section", `"doma"`},
+		{"contains(keys(@), 'domain') && !empty", `true`},
+		{"empty || 'fallback'", `"fallback"`},
+		{`"whois_data".registrar`, `"Example Registrar"`},
+		{"max(map(&length(@), whois_data.name_servers))", `18`},
+	}
+	for _, tt := range tests {
+		expr, err := Compile(tt.expr)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.expr, err)
+			continue
+		}
+		got, err := expr.Search(data)
+		if err != nil {
+			t.Errorf("Search(%q): %v", tt.expr, err)
+			continue
+		}
+		out, _ := json.Marshal(got)
+		if string(out) != tt.want {
+			t.Errorf("Search(%q) = %s, want %s", tt.expr, out, tt.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expr := range []string{"", "foo.", "foo[", "a = b", "foo[?bar", "`{bad`", "{a b}"} {
+		if _, err := Compile(expr); err == nil {
+			t.Errorf("Compile(%q): expected an error", expr)
+		}
+	}
+
+	_, err := Compile("whois_data.[")
+	if err == nil || !strings.Contains(err.Error(), "offset") {
+		t.Errorf("expected the error to point at an offset, got %v", err)
+	}
+}
+
+func TestFunctionErrors(t *testing.T) {
+	expr, err := Compile("length(`5`)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := expr.Search(nil); err == nil {
+		t.Error("expected a type error")
+	}
+	expr, _ = Compile("nope(@)")
+	if _, err := expr.Search(nil); err == nil {
+		t.Error("expected an unknown function error")
+	}
+}
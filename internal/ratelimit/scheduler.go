@@ -0,0 +1,216 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rule is a token bucket: Rate requests per second on average, with bursts
+// of up to Burst requests.
+type Rule struct {
+	Rate  float64
+	Burst int
+}
+
+// patternRule applies to every host matching a prefix or suffix.
+type patternRule struct {
+	prefix string
+	suffix string
+	rule   Rule
+}
+
+// defaultPatterns are conservative limits for providers known to throttle
+// or ban aggressive clients.
+var defaultPatterns = []patternRule{
+	{prefix: "whois.", rule: Rule{Rate: 1, Burst: 2}},
+	{suffix: ".whois-servers.net", rule: Rule{Rate: 1, Burst: 2}},
+	{prefix: "rdap.", rule: Rule{Rate: 2, Burst: 4}},
+	{suffix: "crt.sh", rule: Rule{Rate: 0.5, Burst: 1}},
+	{suffix: "hstspreload.org", rule: Rule{Rate: 1, Burst: 2}},
+	{suffix: ".infura.io", rule: Rule{Rate: 10, Burst: 10}},
+	{suffix: ".alchemy.com", rule: Rule{Rate: 10, Burst: 10}},
+	{suffix: "api.doma.xyz", rule: Rule{Rate: 5, Burst: 5}},
+}
+
+// DefaultRule applies to hosts without a more specific rule.
+var DefaultRule = Rule{Rate: 5, Burst: 5}
+
+// Scheduler keeps one token bucket per target host so that every part of a
+// run shares the same budget for each provider.
+type Scheduler struct {
+	mu       sync.Mutex
+	def      Rule
+	exact    map[string]Rule
+	patterns []patternRule
+	buckets  map[string]*bucket
+	now      func() time.Time
+}
+
+type bucket struct {
+	rule   Rule
+	tokens float64
+	last   time.Time
+}
+
+// Default is the scheduler shared by all clients in the process.
+var Default = New()
+
+// New returns a scheduler with the built-in per-provider limits.
+func New() *Scheduler {
+	return &Scheduler{
+		def:      DefaultRule,
+		exact:    make(map[string]Rule),
+		patterns: defaultPatterns,
+		buckets:  make(map[string]*bucket),
+		now:      time.Now,
+	}
+}
+
+// Set overrides the rule for one host, or for all otherwise unmatched hosts
+// when host is "default". A Rate of 0 disables limiting.
+func (s *Scheduler) Set(host string, rule Rule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rule.Burst < 1 {
+		rule.Burst = 1
+	}
+	if host == "default" {
+		s.def = rule
+	} else {
+		s.exact[strings.ToLower(host)] = rule
+	}
+	s.buckets = make(map[string]*bucket)
+}
+
+// Wait blocks until a request to host is allowed. It gives up when ctx is
+// done, returning ctx's error and handing the token back.
+func (s *Scheduler) Wait(ctx context.Context, host string) error {
+	d := s.reserve(host)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		s.release(host)
+		return ctx.Err()
+	}
+}
+
+// release returns a token reserve took for host that was not used.
+func (s *Scheduler) release(host string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b, ok := s.buckets[normalize(host)]; ok {
+		b.tokens++
+	}
+}
+
+// reserve takes a token for host and returns how long the caller must wait
+// before using it. Concurrent callers queue behind each other.
+func (s *Scheduler) reserve(host string) time.Duration {
+	host = normalize(host)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[host]
+	now := s.now()
+	if !ok {
+		rule := s.ruleFor(host)
+		b = &bucket{rule: rule, tokens: float64(rule.Burst), last: now}
+		s.buckets[host] = b
+	}
+	if b.rule.Rate <= 0 {
+		return 0
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rule.Rate
+	if b.tokens > float64(b.rule.Burst) {
+		b.tokens = float64(b.rule.Burst)
+	}
+	b.last = now
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rule.Rate * float64(time.Second))
+}
+
+func (s *Scheduler) ruleFor(host string) Rule {
+	if rule, ok := s.exact[host]; ok {
+		return rule
+	}
+	for _, p := range s.patterns {
+		if (p.prefix != "" && strings.HasPrefix(host, p.prefix)) || (p.suffix != "" && strings.HasSuffix(host, p.suffix)) {
+			return p.rule
+		}
+	}
+	return s.def
+}
+
+// Transport wraps base so every HTTP request waits for its host's bucket.
+func (s *Scheduler) Transport(base http.RoundTripper) http.RoundTripper {
+	return &pacedTransport{scheduler: s, base: base}
+}
+
+type pacedTransport struct {
+	scheduler *Scheduler
+	base      http.RoundTripper
+}
+
+func (t *pacedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.scheduler.Wait(req.Context(), req.URL.Host); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// ParseRules parses overrides of the form "host=rate[/burst],...", where
+// host may be "default".
+func ParseRules(spec string) (map[string]Rule, error) {
+	rules := make(map[string]Rule)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		host, value, ok := strings.Cut(item, "=")
+		if !ok || host == "" {
+			return nil, fmt.Errorf("invalid rate limit %q: expected host=rate[/burst]", item)
+		}
+		rateStr, burstStr, hasBurst := strings.Cut(value, "/")
+		rate, err := strconv.ParseFloat(rateStr, 64)
+		if err != nil || rate < 0 {
+			return nil, fmt.Errorf("invalid rate in %q", item)
+		}
+		burst := int(rate)
+		if burst < 1 {
+			burst = 1
+		}
+		if hasBurst {
+			if burst, err = strconv.Atoi(burstStr); err != nil || burst < 1 {
+				return nil, fmt.Errorf("invalid burst in %q", item)
+			}
+		}
+		rules[strings.ToLower(host)] = Rule{Rate: rate, Burst: burst}
+	}
+	return rules, nil
+}
+
+func normalize(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestScheduler_Reserve(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := New()
+	s.now = func() time.Time { return now }
+
+	// WHOIS servers allow a burst of 2 at 1 request per second.
+	if d := s.reserve("whois.verisign-grs.com:43"); d != 0 {
+		t.Errorf("first request should not wait, got %v", d)
+	}
+	if d := s.reserve("whois.verisign-grs.com"); d != 0 {
+		t.Errorf("second request is within the burst, got %v", d)
+	}
+	if d := s.reserve("whois.verisign-grs.com"); d != time.Second {
+		t.Errorf("third request should wait 1s, got %v", d)
+	}
+	if d := s.reserve("whois.verisign-grs.com"); d != 2*time.Second {
+		t.Errorf("fourth request should queue behind the third, got %v", d)
+	}
+
+	// Other hosts have their own buckets.
+	if d := s.reserve("whois.pir.org"); d != 0 {
+		t.Errorf("separate host should not wait, got %v", d)
+	}
+
+	now = now.Add(10 * time.Second)
+	if d := s.reserve("whois.verisign-grs.com"); d != 0 {
+		t.Errorf("bucket should refill over time, got %v", d)
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules("whois.nic.io=0.5/1, default=20")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rules["whois.nic.io"] != (Rule{Rate: 0.5, Burst: 1}) {
+		t.Errorf("unexpected rule: %+v", rules["whois.nic.io"])
+	}
+	if rules["default"] != (Rule{Rate: 20, Burst: 20}) {
+		t.Errorf("unexpected default rule: %+v", rules["default"])
+	}
+
+	if _, err := ParseRules("whois.nic.io"); err == nil {
+		t.Error("expected error for missing rate")
+	}
+}
+
+func TestScheduler_WaitCanceled(t *testing.T) {
+	s := New()
+	s.Set("slow.example", Rule{Rate: 0.001, Burst: 1})
+	if err := s.Wait(context.Background(), "slow.example"); err != nil {
+		t.Fatalf("first request is within the burst, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := s.Wait(ctx, "slow.example"); err != context.DeadlineExceeded {
+		t.Errorf("expected the deadline to end the wait, got %v", err)
+	}
+	if waited := time.Since(start); waited > time.Second {
+		t.Errorf("wait outlived its context: %v", waited)
+	}
+	if d := s.reserve("slow.example"); d > 1001*time.Second {
+		t.Errorf("expected the canceled wait's token back, got a %v wait", d)
+	}
+}
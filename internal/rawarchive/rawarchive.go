@@ -0,0 +1,291 @@
+// Package rawarchive keeps every raw WHOIS and RDAP response on disk,
+// gzip-compressed, one file per response under a directory per domain.
+// Responses older than the retention period are pruned, so the archive
+// becomes a private historical WHOIS dataset of one's own queries that
+// later parser versions can read again.
+package rawarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"d3-domain-tool/internal/whois"
+)
+
+// Protocols.
+const (
+	WHOIS = "whois"
+	RDAP  = "rdap"
+)
+
+// timeFormat names the files, so they sort by the time of the response.
+const timeFormat = "20060102T150405.000000000Z"
+
+// maxRDAPBody bounds how much of an RDAP response is archived.
+const maxRDAPBody = 4 << 20
+
+// Archive is a directory of archived responses.
+type Archive struct {
+	dir       string
+	retention time.Duration
+	now       func() time.Time
+	mu        sync.Mutex
+	// Warn, when set, receives the errors of archiving a response, which
+	// do not fail the query that got it.
+	Warn func(error)
+}
+
+// Entry is one archived response.
+type Entry struct {
+	Time     time.Time `json:"time"`
+	Domain   string    `json:"domain"`
+	Protocol string    `json:"protocol"`
+	// Server is the WHOIS server or RDAP base URL that answered.
+	Server string `json:"server"`
+	Size   int64  `json:"size"`
+	Path   string `json:"path"`
+	// Parsed is the response parsed again, when the reader asks for it.
+	Parsed *whois.Result `json:"parsed,omitempty"`
+}
+
+// Open opens the archive in dir, creating it if needed. Responses older
+// than retention are pruned; 0 keeps them forever.
+func Open(dir string, retention time.Duration) (*Archive, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Archive{dir: dir, retention: retention, now: time.Now}, nil
+}
+
+// Add archives body, the response of server about domain.
+func (a *Archive) Add(protocol, server, domain string, body []byte) error {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	if !safeName(domain) {
+		return fmt.Errorf("raw archive: invalid domain %q", domain)
+	}
+	now := a.now().UTC()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	// The gzip header keeps who answered, so no index file is needed.
+	zw.Name = domain
+	zw.Comment = server
+	zw.ModTime = now
+	if _, err := zw.Write(body); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	dir := filepath.Join(a.dir, domain)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	name := filepath.Join(dir, now.Format(timeFormat)+"-"+protocol+".gz")
+	return os.WriteFile(name, buf.Bytes(), 0600)
+}
+
+// WHOIS wraps a port-43 query function so each response is archived.
+func (a *Archive) WHOIS(next whois.QueryFunc) whois.QueryFunc {
+	return func(ctx context.Context, server, query string) (string, error) {
+		body, err := next(ctx, server, query)
+		if err == nil {
+			a.warn(a.Add(WHOIS, server, whois.QueryDomain(query), []byte(body)))
+		}
+		return body, err
+	}
+}
+
+// Transport wraps base so the response of each RDAP domain query is
+// archived, up to maxRDAPBody bytes of it, once the caller has read it.
+// RDAP queries are recognized by their Accept header and their /domain/
+// path; other requests pass through untouched.
+func (a *Archive) Transport(base http.RoundTripper) http.RoundTripper {
+	return &transport{archive: a, base: base}
+}
+
+type transport struct {
+	archive *Archive
+	base    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	i := strings.LastIndex(req.URL.Path, "/domain/")
+	if err != nil || i < 0 || !strings.Contains(req.Header.Get("Accept"), "application/rdap+json") {
+		return resp, err
+	}
+	server := req.URL.Scheme + "://" + req.URL.Host + req.URL.Path[:i+1]
+	domain := req.URL.Path[i+len("/domain/"):]
+	resp.Body = &teeBody{ReadCloser: resp.Body, done: func(body []byte) {
+		t.archive.warn(t.archive.Add(RDAP, server, domain, body))
+	}}
+	return resp, nil
+}
+
+// teeBody passes a response body through unchanged and hands the first
+// maxRDAPBody bytes read to done at EOF or Close, whichever comes first.
+type teeBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	done func(body []byte)
+	once sync.Once
+}
+
+func (b *teeBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if room := maxRDAPBody - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(n, room)])
+	}
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *teeBody) Close() error {
+	b.finish()
+	return b.ReadCloser.Close()
+}
+
+func (b *teeBody) finish() {
+	b.once.Do(func() { b.done(b.buf.Bytes()) })
+}
+
+func (a *Archive) warn(err error) {
+	if err != nil && a.Warn != nil {
+		a.Warn(err)
+	}
+}
+
+// List returns the archived responses of domain, oldest first.
+func (a *Archive) List(domain string) ([]Entry, error) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	if !safeName(domain) {
+		return nil, fmt.Errorf("invalid domain %q", domain)
+	}
+	files, err := os.ReadDir(filepath.Join(a.dir, domain))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, f := range files {
+		t, protocol, ok := parseName(f.Name())
+		if !ok {
+			continue
+		}
+		e := Entry{Time: t, Domain: domain, Protocol: protocol, Path: filepath.Join(a.dir, domain, f.Name())}
+		if e.Server, e.Size, err = header(e.Path); err != nil {
+			return nil, fmt.Errorf("%s: %v", e.Path, err)
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	return entries, nil
+}
+
+// Read returns the response archived as e.
+func Read(e Entry) ([]byte, error) {
+	f, err := os.Open(e.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(zr)
+}
+
+// Prune removes the responses older than the retention period, and the
+// directories it leaves empty, and returns how many it removed.
+func (a *Archive) Prune() (int, error) {
+	if a.retention <= 0 {
+		return 0, nil
+	}
+	cutoff := a.now().Add(-a.retention)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	domains, err := os.ReadDir(a.dir)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, d := range domains {
+		if !d.IsDir() {
+			continue
+		}
+		dir := filepath.Join(a.dir, d.Name())
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			return removed, err
+		}
+		kept := len(files)
+		for _, f := range files {
+			if t, _, ok := parseName(f.Name()); ok && t.Before(cutoff) {
+				if err := os.Remove(filepath.Join(dir, f.Name())); err != nil {
+					return removed, err
+				}
+				removed++
+				kept--
+			}
+		}
+		if kept == 0 {
+			os.Remove(dir)
+		}
+	}
+	return removed, nil
+}
+
+// parseName reads the time and protocol from an archived file's name.
+func parseName(name string) (time.Time, string, bool) {
+	base, ok := strings.CutSuffix(name, ".gz")
+	if !ok {
+		return time.Time{}, "", false
+	}
+	stamp, protocol, ok := strings.Cut(base, "-")
+	if !ok {
+		return time.Time{}, "", false
+	}
+	t, err := time.Parse(timeFormat, stamp)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return t, protocol, true
+}
+
+// header returns the server recorded in an archived file and the size of
+// the response, read without decompressing it in full.
+func header(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return "", 0, err
+	}
+	size, err := io.Copy(io.Discard, zr)
+	return zr.Comment, size, err
+}
+
+func safeName(s string) bool {
+	return s != "" && s != "." && s != ".." && !strings.ContainsAny(s, `/\`) && !strings.HasPrefix(s, ".")
+}
@@ -0,0 +1,126 @@
+package rawarchive
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchive(t *testing.T) {
+	dir := t.TempDir()
+	a, err := Open(dir, 90*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	query := a.WHOIS(func(ctx context.Context, server, query string) (string, error) {
+		return "Registrar: Example Inc.\n", nil
+	})
+	if _, err := query(context.Background(), "whois.verisign-grs.com", "domain Example.COM"); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":["active"]}`))
+	}))
+	defer ts.Close()
+	client := &http.Client{Transport: a.Transport(http.DefaultTransport)}
+	now = now.Add(time.Hour)
+	req, _ := http.NewRequest("GET", ts.URL+"/rdap/domain/example.com", nil)
+	req.Header.Set("Accept", "application/rdap+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != `{"status":["active"]}` {
+		t.Errorf("the archived RDAP body reached the caller as %q", body)
+	}
+	// Other requests pass through unarchived.
+	if resp, err := client.Get(ts.URL + "/api/domain/example.com"); err == nil {
+		resp.Body.Close()
+	}
+
+	entries, err := a.List("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+	}
+	if e := entries[0]; e.Protocol != WHOIS || e.Server != "whois.verisign-grs.com" || !e.Time.Equal(now.Add(-time.Hour)) {
+		t.Errorf("WHOIS entry = %+v", e)
+	}
+	if e := entries[1]; e.Protocol != RDAP || e.Server != ts.URL+"/rdap/" || e.Size != int64(len(body)) {
+		t.Errorf("RDAP entry = %+v", e)
+	}
+	raw, err := Read(entries[0])
+	if err != nil || string(raw) != "Registrar: Example Inc.\n" {
+		t.Errorf("Read = %q, %v", raw, err)
+	}
+
+	now = now.Add(90*24*time.Hour - time.Minute)
+	if n, err := a.Prune(); err != nil || n != 1 {
+		t.Errorf("Prune = %d, %v; want 1", n, err)
+	}
+	now = now.Add(time.Hour)
+	if n, err := a.Prune(); err != nil || n != 1 {
+		t.Errorf("Prune = %d, %v; want 1", n, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "example.com")); !os.IsNotExist(err) {
+		t.Error("Prune kept the emptied domain directory")
+	}
+}
+
+func TestArchiveRejectsPaths(t *testing.T) {
+	a, err := Open(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, domain := range []string{"", "..", "../etc", "a/b"} {
+		if err := a.Add(WHOIS, "whois.example", domain, nil); err == nil {
+			t.Errorf("Add(%q) succeeded", domain)
+		}
+	}
+}
+
+func TestArchiveCapsRDAPBody(t *testing.T) {
+	a, err := Open(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	large := strings.Repeat("x", maxRDAPBody+1024)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(large))
+	}))
+	defer ts.Close()
+	client := &http.Client{Transport: a.Transport(http.DefaultTransport)}
+	req, _ := http.NewRequest("GET", ts.URL+"/domain/example.com", nil)
+	req.Header.Set("Accept", "application/rdap+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if len(body) != len(large) {
+		t.Errorf("the caller got %d bytes, want all %d", len(body), len(large))
+	}
+
+	entries, err := a.List("example.com")
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("List = %+v, %v", entries, err)
+	}
+	if entries[0].Size != maxRDAPBody {
+		t.Errorf("archived %d bytes, want %d", entries[0].Size, maxRDAPBody)
+	}
+}
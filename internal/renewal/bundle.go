@@ -0,0 +1,142 @@
+package renewal
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/blockchain"
+)
+
+// priceBufferPercent is added to the quoted fee so the transaction still pays
+// enough if the ETH/USD oracle moves before it executes; the bulk
+// renewal contract refunds the excess.
+const priceBufferPercent = 5
+
+// SafeBundle is a Safe Transaction Builder batch file.
+type SafeBundle struct {
+	Version      string            `json:"version"`
+	ChainID      string            `json:"chainId"`
+	CreatedAt    int64             `json:"createdAt"`
+	Meta         SafeMeta          `json:"meta"`
+	Transactions []SafeTransaction `json:"transactions"`
+}
+
+// SafeMeta describes a SafeBundle.
+type SafeMeta struct {
+	Name                   string `json:"name"`
+	Description            string `json:"description"`
+	TxBuilderVersion       string `json:"txBuilderVersion"`
+	CreatedFromSafeAddress string `json:"createdFromSafeAddress"`
+}
+
+// SafeTransaction is one call in a SafeBundle.
+type SafeTransaction struct {
+	To                   string      `json:"to"`
+	Value                string      `json:"value"`
+	Data                 string      `json:"data"`
+	ContractMethod       interface{} `json:"contractMethod"`
+	ContractInputsValues interface{} `json:"contractInputsValues"`
+}
+
+// Batch is an unsigned bulk renewal of the names expiring soon.
+type Batch struct {
+	Within   int    `json:"within_days"`
+	Years    int    `json:"years"`
+	Contract string `json:"contract"`
+	Names    []Name `json:"names"`
+	// Skipped lists names that are not due, past their grace period or
+	// could not be checked.
+	Skipped []Name `json:"skipped,omitempty"`
+	// Value is the fee quote plus a buffer, in wei; the contract refunds
+	// what is not needed.
+	Value     string      `json:"value_wei"`
+	ValueETH  float64     `json:"value_eth"`
+	Bundle    *SafeBundle `json:"-"`
+	CheckedAt time.Time   `json:"checked_at"`
+}
+
+// Batch builds, but never signs, a bulk renewal of every name that expires
+// within the given number of days, for years years. safe is the address
+// recorded in the bundle's metadata and may be empty.
+func (a *Advisor) Batch(names []string, within, years int, safe string) (*Batch, error) {
+	if years < 1 {
+		return nil, fmt.Errorf("renewal duration must be at least one year")
+	}
+	batch := &Batch{
+		Within:    within,
+		Years:     years,
+		Contract:  blockchain.ENSBulkRenewal,
+		CheckedAt: a.now(),
+	}
+
+	var labels []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.ToLower(name)
+		// A name listed twice would be paid for twice.
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		n := Name{Name: name}
+		expiry, err := a.expiry(name)
+		switch {
+		case err != nil:
+			n.Error = err.Error()
+		case expiry == nil:
+			n.Error = "not registered"
+		default:
+			n.Expires = expiry
+			n.DaysLeft = int(expiry.Sub(batch.CheckedAt).Hours() / 24)
+			// renew reverts for a released name, and with it the whole
+			// batch.
+			if expiry.AddDate(0, 0, GraceDays).Before(batch.CheckedAt) {
+				n.Error = fmt.Sprintf("expired more than %d days ago: past the grace period, it can only be registered again", GraceDays)
+			}
+		}
+		if n.Error != "" || n.DaysLeft > within {
+			batch.Skipped = append(batch.Skipped, n)
+			continue
+		}
+		batch.Names = append(batch.Names, n)
+	}
+	if len(batch.Names) == 0 {
+		return batch, nil
+	}
+	sort.SliceStable(batch.Names, func(i, j int) bool {
+		return batch.Names[i].DaysLeft < batch.Names[j].DaysLeft
+	})
+	for _, n := range batch.Names {
+		labels = append(labels, strings.TrimSuffix(n.Name, ".eth"))
+	}
+
+	duration := big.NewInt(int64(years) * 365 * 24 * 60 * 60)
+	price, err := a.rentPrice(labels, duration)
+	if err != nil {
+		return nil, fmt.Errorf("rent price: %v", err)
+	}
+	value := new(big.Int).Div(new(big.Int).Mul(price, big.NewInt(100+priceBufferPercent)), big.NewInt(100))
+	batch.Value = value.String()
+	batch.ValueETH, _ = new(big.Float).Quo(new(big.Float).SetInt(value), big.NewFloat(1e18)).Float64()
+
+	batch.Bundle = &SafeBundle{
+		Version:   "1.0",
+		ChainID:   "1",
+		CreatedAt: batch.CheckedAt.UnixMilli(),
+		Meta: SafeMeta{
+			Name:                   fmt.Sprintf("Renew %d ENS names", len(labels)),
+			Description:            fmt.Sprintf("Renew %s for %d year(s)", strings.Join(labels, ", "), years),
+			TxBuilderVersion:       "1.16.5",
+			CreatedFromSafeAddress: safe,
+		},
+		Transactions: []SafeTransaction{{
+			To:    blockchain.ENSBulkRenewal,
+			Value: batch.Value,
+			Data:  blockchain.Hex(blockchain.EncodeRenewAll(labels, duration)),
+		}},
+	}
+	return batch, nil
+}
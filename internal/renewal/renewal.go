@@ -0,0 +1,233 @@
+// Package renewal suggests cheap times to renew ENS names from the base
+// fee history of an Ethereum node and estimates what each renewal costs.
+package renewal
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/blockchain"
+	"d3-domain-tool/internal/valuation"
+)
+
+// RenewGas is the typical gas used by a single ETHRegistrarController
+// renew call.
+const RenewGas = 50000
+
+// DefaultHistory is how far back base fees are sampled.
+const DefaultHistory = 7 * 24 * time.Hour
+
+// UrgentDays is how close to expiry a name must be renewed right away
+// rather than waiting for a cheap window.
+const UrgentDays = 14
+
+// GraceDays is how long after expiry an ENS name can still be renewed by
+// its owner; after that it is released and can only be registered again.
+const GraceDays = 90
+
+// windowCount is how many of the cheapest hours are suggested.
+const windowCount = 3
+
+// Window is one hour of the day (UTC) and its average base fee.
+type Window struct {
+	Hour    int     `json:"hour_utc"`
+	BaseFee float64 `json:"avg_base_fee_gwei"`
+	// Savings is the gas saved compared with renewing now, as a fraction.
+	Savings float64 `json:"savings"`
+}
+
+// Name is the renewal estimate for one portfolio name.
+type Name struct {
+	Name     string     `json:"name"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	DaysLeft int        `json:"days_left"`
+	FeeUSD   float64    `json:"fee_usd"`
+	// GasETH is the gas cost of renewing now; WindowGasETH in the
+	// cheapest window.
+	GasETH       float64  `json:"gas_eth"`
+	WindowGasETH float64  `json:"window_gas_eth"`
+	TotalUSD     *float64 `json:"total_usd,omitempty"`
+	Advice       string   `json:"advice"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// Report is the renewal plan for a portfolio.
+type Report struct {
+	BaseFee     float64   `json:"base_fee_gwei"`
+	PriorityFee float64   `json:"priority_fee_gwei"`
+	ETHUSD      float64   `json:"eth_usd,omitempty"`
+	History     string    `json:"history"`
+	Samples     int       `json:"samples"`
+	Windows     []Window  `json:"windows"`
+	Names       []Name    `json:"names"`
+	Notes       []string  `json:"notes,omitempty"`
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+// Advisor builds renewal plans.
+type Advisor struct {
+	history  time.Duration
+	ethUSD   float64
+	engine   *valuation.Engine
+	now      func() time.Time
+	baseFees func(period time.Duration) ([]blockchain.GasSample, error)
+	priority func() (float64, error)
+	price    func() (float64, error)
+	expiry   func(name string) (*time.Time, error)
+	// rentPrice quotes the fee in wei for renewing labels for a duration
+	// in seconds.
+	rentPrice func(labels []string, duration *big.Int) (*big.Int, error)
+}
+
+// NewAdvisor returns an advisor reading gas prices, the ETH/USD price and
+// expiries from rpc.
+func NewAdvisor(rpc *blockchain.RPCClient) *Advisor {
+	return &Advisor{
+		history:   DefaultHistory,
+		engine:    valuation.NewEngine(),
+		now:       time.Now,
+		baseFees:  rpc.BaseFees,
+		priority:  rpc.MaxPriorityFee,
+		price:     rpc.ETHPrice,
+		expiry:    rpc.ENSExpiry,
+		rentPrice: rpc.BulkRentPrice,
+	}
+}
+
+// SetHistory sets how far back base fees are sampled.
+func (a *Advisor) SetHistory(d time.Duration) {
+	if d > 0 {
+		a.history = d
+	}
+}
+
+// SetETHPrice fixes the ETH/USD price instead of reading the Chainlink
+// feed.
+func (a *Advisor) SetETHPrice(usd float64) {
+	a.ethUSD = usd
+}
+
+// Plan estimates renewal costs for names and suggests the cheapest hours
+// of the day to renew them.
+func (a *Advisor) Plan(names []string) (*Report, error) {
+	samples, err := a.baseFees(a.history)
+	if err != nil {
+		return nil, fmt.Errorf("gas history: %v", err)
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("gas history: no blocks returned")
+	}
+
+	report := &Report{
+		BaseFee:   samples[len(samples)-1].BaseFee,
+		History:   a.history.String(),
+		Samples:   len(samples),
+		CheckedAt: a.now(),
+	}
+	if tip, err := a.priority(); err == nil {
+		report.PriorityFee = tip
+	} else {
+		report.Notes = append(report.Notes, fmt.Sprintf("priority fee unavailable, assuming 0: %v", err))
+	}
+	report.ETHUSD = a.ethUSD
+	if report.ETHUSD == 0 {
+		if usd, err := a.price(); err == nil {
+			report.ETHUSD = usd
+		} else {
+			report.Notes = append(report.Notes, fmt.Sprintf("ETH/USD price unavailable; totals omitted: %v", err))
+		}
+	}
+	report.Windows = cheapestHours(samples, report.BaseFee)
+
+	cheapest := report.BaseFee
+	if len(report.Windows) > 0 {
+		cheapest = report.Windows[0].BaseFee
+	}
+	for _, name := range names {
+		report.Names = append(report.Names, a.planName(strings.ToLower(name), report, cheapest))
+	}
+	sort.SliceStable(report.Names, func(i, j int) bool {
+		return report.Names[i].DaysLeft < report.Names[j].DaysLeft
+	})
+	return report, nil
+}
+
+func (a *Advisor) planName(name string, report *Report, cheapest float64) Name {
+	n := Name{Name: name}
+	fee, _ := a.engine.RenewalCost(name)
+	n.FeeUSD = fee
+
+	expiry, err := a.expiry(name)
+	if err != nil {
+		n.Error = err.Error()
+		return n
+	}
+	if expiry == nil {
+		n.Error = "not registered"
+		return n
+	}
+	n.Expires = expiry
+	n.DaysLeft = int(expiry.Sub(report.CheckedAt).Hours() / 24)
+
+	n.GasETH = gasETH(report.BaseFee + report.PriorityFee)
+	n.WindowGasETH = gasETH(cheapest + report.PriorityFee)
+
+	switch {
+	case n.DaysLeft < 0:
+		n.Advice = fmt.Sprintf("renew now: expired, in the %d-day grace period", GraceDays)
+		n.WindowGasETH = n.GasETH
+	case n.DaysLeft <= UrgentDays:
+		n.Advice = fmt.Sprintf("renew now: expires in %d days", n.DaysLeft)
+		n.WindowGasETH = n.GasETH
+	case len(report.Windows) > 0:
+		n.Advice = fmt.Sprintf("renew around %02d:00 UTC before %s", report.Windows[0].Hour,
+			expiry.AddDate(0, 0, -UrgentDays).Format("2006-01-02"))
+	default:
+		n.Advice = "renew any time before expiry"
+	}
+	if report.ETHUSD > 0 {
+		total := n.FeeUSD + n.WindowGasETH*report.ETHUSD
+		n.TotalUSD = &total
+	}
+	return n
+}
+
+// cheapestHours averages base fees by hour of day and returns the
+// cheapest hours, with savings relative to current.
+func cheapestHours(samples []blockchain.GasSample, current float64) []Window {
+	var sum [24]float64
+	var count [24]int
+	for _, s := range samples {
+		h := s.Time.UTC().Hour()
+		sum[h] += s.BaseFee
+		count[h]++
+	}
+
+	var windows []Window
+	for h := 0; h < 24; h++ {
+		if count[h] == 0 {
+			continue
+		}
+		avg := sum[h] / float64(count[h])
+		w := Window{Hour: h, BaseFee: avg}
+		if current > 0 && avg < current {
+			w.Savings = 1 - avg/current
+		}
+		windows = append(windows, w)
+	}
+	sort.SliceStable(windows, func(i, j int) bool {
+		return windows[i].BaseFee < windows[j].BaseFee
+	})
+	if len(windows) > windowCount {
+		windows = windows[:windowCount]
+	}
+	return windows
+}
+
+// gasETH is the cost in ETH of a renewal at a gas price in gwei.
+func gasETH(gwei float64) float64 {
+	return RenewGas * gwei / 1e9
+}
@@ -0,0 +1,166 @@
+package renewal
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/blockchain"
+	"d3-domain-tool/internal/valuation"
+)
+
+func testAdvisor(now time.Time, expiries map[string]time.Time) *Advisor {
+	return &Advisor{
+		history: 48 * time.Hour,
+		engine:  valuation.NewEngine(),
+		now:     func() time.Time { return now },
+		baseFees: func(period time.Duration) ([]blockchain.GasSample, error) {
+			// Fees are 10 gwei at 03:00 UTC, 40 gwei otherwise and 50 now.
+			var samples []blockchain.GasSample
+			for t := now.Add(-period); t.Before(now); t = t.Add(time.Hour) {
+				fee := 40.0
+				if t.Hour() == 3 {
+					fee = 10
+				}
+				samples = append(samples, blockchain.GasSample{Time: t, BaseFee: fee})
+			}
+			return append(samples, blockchain.GasSample{Time: now, BaseFee: 50}), nil
+		},
+		priority: func() (float64, error) { return 0, nil },
+		price:    func() (float64, error) { return 2000, nil },
+		expiry: func(name string) (*time.Time, error) {
+			if t, ok := expiries[name]; ok {
+				return &t, nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+func TestPlan(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	a := testAdvisor(now, map[string]time.Time{
+		"vitalik.eth": now.AddDate(0, 6, 0),
+		"abc.eth":     now.AddDate(0, 0, 5),
+	})
+
+	report, err := a.Plan([]string{"vitalik.eth", "ABC.eth", "gone.eth"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.BaseFee != 50 || report.ETHUSD != 2000 {
+		t.Errorf("base fee %v, ETH/USD %v", report.BaseFee, report.ETHUSD)
+	}
+	if len(report.Windows) != windowCount || report.Windows[0].Hour != 3 || report.Windows[0].Savings != 0.8 {
+		t.Fatalf("windows = %+v", report.Windows)
+	}
+
+	names := map[string]Name{}
+	for _, n := range report.Names {
+		names[n.Name] = n
+	}
+	if n := names["gone.eth"]; n.Error != "not registered" {
+		t.Errorf("gone.eth = %+v", n)
+	}
+
+	urgent := names["abc.eth"]
+	if !strings.HasPrefix(urgent.Advice, "renew now") || urgent.FeeUSD != 640 {
+		t.Errorf("abc.eth = %+v", urgent)
+	}
+	if urgent.WindowGasETH != urgent.GasETH {
+		t.Errorf("urgent renewal should not wait: %+v", urgent)
+	}
+
+	later := names["vitalik.eth"]
+	if !strings.HasPrefix(later.Advice, "renew around 03:00 UTC") {
+		t.Errorf("advice = %q", later.Advice)
+	}
+	// 50,000 gas at 10 gwei is 0.0005 ETH, $1 at $2000.
+	if later.WindowGasETH != 0.0005 || later.TotalUSD == nil || *later.TotalUSD != 6 {
+		t.Errorf("vitalik.eth = %+v", later)
+	}
+}
+
+func TestPlanWithoutPrice(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	a := testAdvisor(now, map[string]time.Time{"vitalik.eth": now.AddDate(1, 0, 0)})
+	a.price = func() (float64, error) { return 0, fmt.Errorf("execution reverted") }
+
+	report, err := a.Plan([]string{"vitalik.eth"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Names[0].TotalUSD != nil || len(report.Notes) != 1 {
+		t.Errorf("report = %+v", report)
+	}
+
+	a.SetETHPrice(3000)
+	if report, _ = a.Plan([]string{"vitalik.eth"}); report.ETHUSD != 3000 {
+		t.Errorf("ETH/USD = %v, want the fixed price", report.ETHUSD)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	a := testAdvisor(now, map[string]time.Time{
+		"vitalik.eth": now.AddDate(0, 6, 0),
+		"abc.eth":     now.AddDate(0, 0, 20),
+		"old.eth":     now.AddDate(0, 0, -3),
+	})
+	var quoted []string
+	a.rentPrice = func(labels []string, duration *big.Int) (*big.Int, error) {
+		quoted = labels
+		return big.NewInt(1e18), nil
+	}
+
+	batch, err := a.Batch([]string{"vitalik.eth", "abc.eth", "old.eth", "gone.eth"}, 30, 1, "0xsafe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(quoted, ",") != "old,abc" || len(batch.Names) != 2 || len(batch.Skipped) != 2 {
+		t.Fatalf("quoted %v, names %+v, skipped %+v", quoted, batch.Names, batch.Skipped)
+	}
+	if batch.Value != "1050000000000000000" || batch.ValueETH != 1.05 {
+		t.Errorf("value = %s (%v ETH)", batch.Value, batch.ValueETH)
+	}
+
+	tx := batch.Bundle.Transactions[0]
+	want := blockchain.Hex(blockchain.EncodeRenewAll([]string{"old", "abc"}, big.NewInt(31536000)))
+	if tx.To != blockchain.ENSBulkRenewal || tx.Value != batch.Value || tx.Data != want {
+		t.Errorf("transaction = %+v", tx)
+	}
+	if batch.Bundle.Meta.CreatedFromSafeAddress != "0xsafe" || batch.Bundle.ChainID != "1" {
+		t.Errorf("meta = %+v", batch.Bundle.Meta)
+	}
+
+	if batch, err = a.Batch([]string{"vitalik.eth"}, 30, 1, ""); err != nil || batch.Bundle != nil {
+		t.Errorf("nothing due: bundle %+v, err %v", batch.Bundle, err)
+	}
+}
+
+func TestBatchSkipsReleasedAndRepeatedNames(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	a := testAdvisor(now, map[string]time.Time{
+		"abc.eth":      now.AddDate(0, 0, 20),
+		"grace.eth":    now.AddDate(0, 0, -GraceDays+1),
+		"released.eth": now.AddDate(0, 0, -GraceDays-1),
+	})
+	var quoted []string
+	a.rentPrice = func(labels []string, duration *big.Int) (*big.Int, error) {
+		quoted = labels
+		return big.NewInt(1e18), nil
+	}
+
+	batch, err := a.Batch([]string{"abc.eth", "released.eth", "ABC.eth", "grace.eth"}, 30, 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(quoted, ",") != "grace,abc" {
+		t.Errorf("quoted %v, want grace,abc", quoted)
+	}
+	if len(batch.Skipped) != 1 || batch.Skipped[0].Name != "released.eth" || !strings.Contains(batch.Skipped[0].Error, "grace period") {
+		t.Errorf("skipped %+v", batch.Skipped)
+	}
+}
@@ -0,0 +1,43 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// A Wednesday.
+	from := time.Date(2026, 10, 14, 10, 7, 30, 0, time.UTC)
+	tests := map[string]time.Time{
+		"*/15m":           from.Add(15 * time.Minute),
+		"@every 6h":       from.Add(6 * time.Hour),
+		"@hourly":         time.Date(2026, 10, 14, 11, 0, 0, 0, time.UTC),
+		"@daily":          time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC),
+		"@weekly":         time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		"@monthly":        time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		"*/15 * * * *":    time.Date(2026, 10, 14, 10, 15, 0, 0, time.UTC),
+		"30 6 * * 1-5":    time.Date(2026, 10, 15, 6, 30, 0, 0, time.UTC),
+		"0 9 * * 6,7":     time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":      time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		"0 12 1 * 3":      time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC),
+		"5-10/5 10 * * *": time.Date(2026, 10, 14, 10, 10, 0, 0, time.UTC),
+	}
+	for spec, want := range tests {
+		s, err := Parse(spec)
+		if err != nil {
+			t.Errorf("%s: %v", spec, err)
+			continue
+		}
+		if got := s.Next(from); !got.Equal(want) {
+			t.Errorf("%s: next = %v, want %v", spec, got, want)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for _, spec := range []string{"", "daily", "*/10s", "@every soon", "60 * * * *", "* * * *", "5-1 * * * *", "*/0 * * * *"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("%q accepted", spec)
+		}
+	}
+}
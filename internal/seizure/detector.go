@@ -0,0 +1,167 @@
+// Package seizure detects domains taken over by law enforcement: delegated
+// to a seizure nameserver or serving a seizure banner. A seized domain
+// cannot be bought, transferred or renewed by its registrant.
+package seizure
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/provider"
+)
+
+// maxBody bounds how much of the home page is searched for a banner.
+const maxBody = 256 << 10
+
+// bannerPhrases appear on the pages agencies put up on seized domains,
+// e.g. the FBI, DOJ, Europol and HSI banners. Parody and news pages use
+// them too, so a phrase alone only makes a seizure possible.
+var bannerPhrases = []string{
+	"this domain has been seized",
+	"this website has been seized",
+	"this domain name has been seized",
+	"this site has been seized",
+	"has been seized by the federal bureau of investigation",
+	"has been seized by the united states",
+	"pursuant to a seizure warrant",
+	"seized pursuant to",
+	"this domain is under the control of law enforcement",
+}
+
+// agencyMarkers name the agencies that put up seizure banners. A real
+// banner carries one in its title or its seal images.
+var agencyMarkers = []string{
+	"federal bureau of investigation",
+	"fbi",
+	"department of justice",
+	"justice.gov",
+	"homeland security",
+	"ice.gov",
+	"europol",
+	"eurojust",
+	"national crime agency",
+	"bundeskriminalamt",
+	"seized.gov",
+}
+
+var (
+	titlePattern = regexp.MustCompile(`<title[^>]*>([^<]*)</title>`)
+	imagePattern = regexp.MustCompile(`<img[^>]*>`)
+)
+
+// Detector looks for seizure nameservers and banners.
+type Detector struct {
+	client   *http.Client
+	lookupNS func(name string) ([]*net.NS, error)
+	// baseURL, when set, replaces http://domain in tests.
+	baseURL string
+}
+
+// Result tells whether a domain looks seized and why.
+type Result struct {
+	Seized bool `json:"seized"`
+	// Possible is set when the home page reads like a seizure banner but
+	// names no agency in its title or images.
+	Possible  bool      `json:"possible,omitempty"`
+	Evidence  []string  `json:"evidence,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// NewDetector returns a Detector using the system resolver and a 10s HTTP
+// timeout.
+func NewDetector() *Detector {
+	return &Detector{
+		client:   &http.Client{Timeout: 10 * time.Second},
+		lookupNS: net.LookupNS,
+	}
+}
+
+// Check looks at the domain's nameservers and the home page served over
+// plain HTTP, following redirects. A domain without a web server is not an
+// error; it only leaves the banner unchecked. A failed nameserver lookup is
+// reported in Result.Error.
+func (d *Detector) Check(domain string) (*Result, error) {
+	result := &Result{CheckedAt: time.Now()}
+
+	records, err := d.lookupNS(domain)
+	var dnsErr *net.DNSError
+	if err != nil && !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+		result.Error = fmt.Sprintf("nameserver lookup failed: %v", err)
+	}
+	if err == nil {
+		var hosts []string
+		for _, ns := range records {
+			hosts = append(hosts, strings.TrimSuffix(strings.ToLower(ns.Host), "."))
+		}
+		sort.Strings(hosts)
+		for _, host := range hosts {
+			if name, category := provider.Identify(domain, host); category == provider.CategorySeizure {
+				result.Evidence = append(result.Evidence, fmt.Sprintf("nameserver %s belongs to %s", host, name))
+			}
+		}
+	}
+
+	nameservers := len(result.Evidence) > 0
+	phrase, agency, url := d.banner(domain)
+	switch {
+	case phrase == "":
+	case agency != "":
+		result.Evidence = append(result.Evidence, fmt.Sprintf("%s shows a seizure banner (%q, %s)", url, phrase, agency))
+	default:
+		result.Evidence = append(result.Evidence, fmt.Sprintf("%s reads like a seizure banner but names no agency (%q)", url, phrase))
+	}
+	result.Seized = nameservers || agency != ""
+	result.Possible = !result.Seized && phrase != ""
+	return result, nil
+}
+
+// banner returns the seizure phrase the home page contains, the agency
+// its title or images name, and the URL it was found at. phrase is "" when
+// there is no banner, agency when no agency is named.
+func (d *Detector) banner(domain string) (phrase, agency, url string) {
+	url = "http://" + domain + "/"
+	if d.baseURL != "" {
+		url = d.baseURL + "/"
+	}
+	resp, err := d.client.Get(url)
+	if err != nil {
+		return "", "", ""
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	if err != nil {
+		return "", "", ""
+	}
+	page := strings.Join(strings.Fields(strings.ToLower(string(body))), " ")
+	for _, p := range bannerPhrases {
+		if strings.Contains(page, p) {
+			phrase = p
+			break
+		}
+	}
+	if phrase == "" {
+		return "", "", ""
+	}
+
+	var marked []string
+	if m := titlePattern.FindStringSubmatch(page); m != nil {
+		marked = append(marked, m[1])
+	}
+	marked = append(marked, imagePattern.FindAllString(page, -1)...)
+	for _, text := range marked {
+		for _, marker := range agencyMarkers {
+			if strings.Contains(text, marker) {
+				return phrase, marker, resp.Request.URL.String()
+			}
+		}
+	}
+	return phrase, "", resp.Request.URL.String()
+}
@@ -0,0 +1,71 @@
+package seizure
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, "/banner.html", http.StatusFound)
+			return
+		}
+		fmt.Fprint(w, `<html><img src="/fbi-seal.png"><h1>THIS DOMAIN HAS
+   BEEN SEIZED</h1></html>`)
+	}))
+	defer server.Close()
+
+	d := NewDetector()
+	d.baseURL = server.URL
+	d.lookupNS = func(string) ([]*net.NS, error) {
+		return []*net.NS{{Host: "ns2.fbi.seized.gov."}, {Host: "ns1.fbi.seized.gov."}}, nil
+	}
+	result, _ := d.Check("market.example")
+	if !result.Seized || len(result.Evidence) != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if !strings.HasPrefix(result.Evidence[0], "nameserver ns1.fbi.seized.gov") || !strings.Contains(result.Evidence[2], "/banner.html shows a seizure banner") {
+		t.Errorf("evidence = %q", result.Evidence)
+	}
+
+	clean := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html>Welcome</html>")
+	}))
+	defer clean.Close()
+	d.baseURL = clean.URL
+	d.lookupNS = func(string) ([]*net.NS, error) {
+		return []*net.NS{{Host: "ada.ns.cloudflare.com."}}, nil
+	}
+	if result, _ := d.Check("shop.example"); result.Seized || result.Possible || result.Error != "" {
+		t.Errorf("clean domain reported: %+v", result)
+	}
+
+	// Seizure wording without an agency's title or seal is only possible.
+	parody := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><title>My blog</title><p>Oops, this site has been seized by my cat.</p></html>")
+	}))
+	defer parody.Close()
+	d.baseURL = parody.URL
+	if result, _ := d.Check("blog.example"); result.Seized || !result.Possible || len(result.Evidence) != 1 {
+		t.Errorf("expected a possible seizure, got %+v", result)
+	}
+
+	d.baseURL = clean.URL
+	d.lookupNS = func(string) ([]*net.NS, error) {
+		return nil, &net.DNSError{Err: "i/o timeout", Name: "shop.example", IsTimeout: true}
+	}
+	if result, _ := d.Check("shop.example"); result.Seized || !strings.Contains(result.Error, "nameserver lookup failed") {
+		t.Errorf("expected the lookup failure to be reported, got %+v", result)
+	}
+	d.lookupNS = func(string) ([]*net.NS, error) {
+		return nil, &net.DNSError{Err: "no such host", Name: "shop.example", IsNotFound: true}
+	}
+	if result, _ := d.Check("shop.example"); result.Error != "" {
+		t.Errorf("a domain without nameservers is not an error, got %+v", result)
+	}
+}
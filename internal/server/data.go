@@ -0,0 +1,159 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/store"
+)
+
+// domainList is a watchlist or a portfolio.
+type domainList struct {
+	Domains []string `json:"domains"`
+}
+
+// portfolioList is the response of GET /v1/portfolios.
+type portfolioList struct {
+	Portfolios []string `json:"portfolios"`
+}
+
+var portfolioName = param{name: "name", in: "path", description: "Portfolio name", required: true}
+
+// storage returns the request's namespace, answering the request itself
+// when the server keeps no data.
+func (s *Server) storage(w http.ResponseWriter, r *http.Request) *store.Namespace {
+	ns := s.namespace(r)
+	if ns == nil {
+		writeError(w, http.StatusNotImplemented, "storage is not enabled; start the server with -data-dir")
+	}
+	return ns
+}
+
+// writable reports whether the caller may change stored data, answering
+// the request itself when it may not.
+func writable(w http.ResponseWriter, r *http.Request) bool {
+	if callerOf(r).readOnly {
+		writeError(w, http.StatusForbidden, "this API key is read-only")
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET")
+		return
+	}
+	domain := normalize(r.URL.Query().Get("domain"))
+	if domain == "" {
+		writeError(w, http.StatusBadRequest, "domain parameter is required")
+		return
+	}
+	ns := s.storage(w, r)
+	if ns == nil {
+		return
+	}
+	history, err := ns.History(domain)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if history == nil {
+		history = []*analyzer.Result{}
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+func (s *Server) handleWatchlist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
+		writeError(w, http.StatusMethodNotAllowed, "use GET or PUT")
+		return
+	}
+	ns := s.storage(w, r)
+	if ns == nil {
+		return
+	}
+	if r.Method == http.MethodPut {
+		if !writable(w, r) {
+			return
+		}
+		var list domainList
+		if err := json.NewDecoder(r.Body).Decode(&list); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+		if err := ns.SetWatchlist(list.Domains); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	domains, err := ns.Watchlist()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, domainList{Domains: domains})
+}
+
+func (s *Server) handlePortfolios(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET")
+		return
+	}
+	ns := s.storage(w, r)
+	if ns == nil {
+		return
+	}
+	names, err := ns.Portfolios()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, portfolioList{Portfolios: names})
+}
+
+func (s *Server) handlePortfolio(w http.ResponseWriter, r *http.Request) {
+	ns := s.storage(w, r)
+	if ns == nil {
+		return
+	}
+	name := r.PathValue("name")
+	var domains []string
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		domains, err = ns.Portfolio(name)
+	case http.MethodPut:
+		if !writable(w, r) {
+			return
+		}
+		var list domainList
+		if err := json.NewDecoder(r.Body).Decode(&list); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+		if err = ns.SavePortfolio(name, list.Domains); err == nil {
+			domains, err = ns.Portfolio(name)
+		}
+	case http.MethodDelete:
+		if !writable(w, r) {
+			return
+		}
+		if domains, err = ns.Portfolio(name); err == nil {
+			err = ns.DeletePortfolio(name)
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "use GET, PUT or DELETE")
+		return
+	}
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		writeError(w, http.StatusNotFound, "no portfolio named "+name)
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeJSON(w, http.StatusOK, domainList{Domains: domains})
+	}
+}
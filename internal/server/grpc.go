@@ -0,0 +1,188 @@
+package server
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"d3-domain-tool/internal/pb"
+)
+
+// gRPC methods of the DomainAnalysis service in
+// proto/d3/v1/analysis.proto, served at these paths.
+const (
+	grpcAnalyze       = "/d3.v1.DomainAnalysis/Analyze"
+	grpcAnalyzeStream = "/d3.v1.DomainAnalysis/AnalyzeStream"
+)
+
+// gRPC status codes.
+const (
+	grpcOK               = 0
+	grpcInvalidArgument  = 3
+	grpcPermissionDenied = 7
+	grpcUnimplemented    = 12
+	grpcInternal         = 13
+	grpcUnavailable      = 14
+)
+
+// maxGRPCMessage bounds a request message: a stream request of
+// MaxPortfolio long names fits with room to spare.
+const maxGRPCMessage = 1 << 20
+
+// grpcError is a call's failure as reported in the grpc-status and
+// grpc-message trailers.
+type grpcError struct {
+	code int
+	msg  string
+}
+
+func (e *grpcError) Error() string { return e.msg }
+
+func grpcErrorf(code int, format string, args ...interface{}) error {
+	return &grpcError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+// handleGRPC serves the DomainAnalysis gRPC service. Requests need HTTP/2,
+// which the server speaks over TLS, or in cleartext (h2c) when its handler
+// is wrapped as serve does.
+func (s *Server) handleGRPC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		writeError(w, http.StatusUnsupportedMediaType, "gRPC requests are POSTs of application/grpc")
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+	w.WriteHeader(http.StatusOK)
+
+	err := s.serveGRPC(w, r)
+	code, msg := grpcOK, ""
+	if err != nil {
+		code, msg = grpcInternal, err.Error()
+		var ge *grpcError
+		if errors.As(err, &ge) {
+			code = ge.code
+		}
+	}
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	if msg != "" {
+		w.Header().Set("Grpc-Message", grpcMessage(msg))
+	}
+}
+
+func (s *Server) serveGRPC(w http.ResponseWriter, r *http.Request) error {
+	req, err := readGRPCMessage(r.Body)
+	if err != nil {
+		return err
+	}
+	analyze := s.analyzeFor(r)
+	switch r.URL.Path {
+	case grpcAnalyze:
+		domain, err := pb.UnmarshalAnalyzeRequest(req)
+		if err != nil {
+			return grpcErrorf(grpcInvalidArgument, "invalid AnalyzeRequest: %v", err)
+		}
+		if domain = normalize(domain); domain == "" {
+			return grpcErrorf(grpcInvalidArgument, "domain is required")
+		}
+		result, err := analyze(domain)
+		if errors.Is(err, errReadOnly) {
+			return grpcErrorf(grpcPermissionDenied, "%v", err)
+		}
+		if err != nil {
+			return grpcErrorf(grpcUnavailable, "%v", err)
+		}
+		data, err := pb.MarshalResult(result)
+		if err != nil {
+			return err
+		}
+		return writeGRPCMessage(w, data)
+
+	case grpcAnalyzeStream:
+		list, err := pb.UnmarshalAnalyzeStreamRequest(req)
+		if err != nil {
+			return grpcErrorf(grpcInvalidArgument, "invalid AnalyzeStreamRequest: %v", err)
+		}
+		if len(list) == 0 {
+			return grpcErrorf(grpcInvalidArgument, "domains is required")
+		}
+		if len(list) > MaxPortfolio {
+			return grpcErrorf(grpcInvalidArgument, "at most %d domains per request", MaxPortfolio)
+		}
+		domains := make([]string, len(list))
+		for i, d := range list {
+			if domains[i] = normalize(d); domains[i] == "" {
+				return grpcErrorf(grpcInvalidArgument, "domains must be non-empty strings")
+			}
+		}
+		var mu sync.Mutex
+		var werr error
+		s.each(analyze, domains, func(d Delivery) {
+			data, err := pb.MarshalDelivery(d.Index, d.Total, d.Domain, d.Result, d.Error)
+			mu.Lock()
+			defer mu.Unlock()
+			if werr != nil {
+				return
+			}
+			if err != nil {
+				werr = err
+				return
+			}
+			werr = writeGRPCMessage(w, data)
+		})
+		return werr
+	}
+	return grpcErrorf(grpcUnimplemented, "unknown method %s", r.URL.Path)
+}
+
+// readGRPCMessage reads the single length-prefixed message of a unary or
+// server-streaming call.
+func readGRPCMessage(body io.Reader) ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(body, prefix[:]); err != nil {
+		return nil, grpcErrorf(grpcInvalidArgument, "reading request: %v", err)
+	}
+	if prefix[0] != 0 {
+		return nil, grpcErrorf(grpcUnimplemented, "compressed requests are not supported")
+	}
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if size > maxGRPCMessage {
+		return nil, grpcErrorf(grpcInvalidArgument, "request of %d bytes exceeds %d", size, maxGRPCMessage)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(body, data); err != nil {
+		return nil, grpcErrorf(grpcInvalidArgument, "reading request: %v", err)
+	}
+	return data, nil
+}
+
+// writeGRPCMessage writes an uncompressed length-prefixed message and
+// flushes it, so streamed results reach the client as they complete.
+func writeGRPCMessage(w http.ResponseWriter, data []byte) error {
+	frame := make([]byte, 5, 5+len(data))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	if _, err := w.Write(append(frame, data...)); err != nil {
+		return err
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// grpcMessage percent-encodes msg for the grpc-message trailer.
+func grpcMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		if c := msg[i]; c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// grpcCall makes a gRPC call with a request message holding strings as
+// field 1, and returns the response messages and the grpc-status trailer.
+func grpcCall(t *testing.T, ts *httptest.Server, method string, values ...string) ([][]byte, string) {
+	t.Helper()
+	var msg []byte
+	for _, v := range values {
+		msg = append(msg, 1<<3|2, byte(len(v)))
+		msg = append(msg, v...)
+	}
+	frame := make([]byte, 5)
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+method, bytes.NewReader(append(frame, msg...)))
+	req.Header.Set("Content-Type", "application/grpc+proto")
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Fatalf("got HTTP/%d.%d, want HTTP/2", resp.ProtoMajor, resp.ProtoMinor)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages [][]byte
+	for len(body) >= 5 {
+		size := binary.BigEndian.Uint32(body[1:5])
+		messages = append(messages, body[5:5+size])
+		body = body[5+size:]
+	}
+	return messages, resp.Trailer.Get("Grpc-Status")
+}
+
+func TestGRPC(t *testing.T) {
+	ts := httptest.NewUnstartedServer(newServer(fakeAnalyze).Handler())
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	messages, status := grpcCall(t, ts, grpcAnalyze, "Example.COM")
+	if status != "0" || len(messages) != 1 || !bytes.Contains(messages[0], []byte("example.com")) {
+		t.Errorf("Analyze = %q, status %s", messages, status)
+	}
+
+	messages, status = grpcCall(t, ts, grpcAnalyzeStream, "a.com", "fail.com")
+	if status != "0" || len(messages) != 2 {
+		t.Fatalf("AnalyzeStream = %q, status %s", messages, status)
+	}
+	if !bytes.Contains(bytes.Join(messages, nil), []byte("whois check failed")) {
+		t.Errorf("AnalyzeStream did not deliver the failure: %q", messages)
+	}
+
+	for method, want := range map[string]string{
+		grpcAnalyze:                  "3",
+		"/d3.v1.DomainAnalysis/Nope": "12",
+	} {
+		if _, status := grpcCall(t, ts, method); status != want {
+			t.Errorf("%s: status %s, want %s", method, status, want)
+		}
+	}
+	if _, status := grpcCall(t, ts, grpcAnalyze, "fail.com"); status != "14" {
+		t.Errorf("failed analysis: status %s, want 14", status)
+	}
+}
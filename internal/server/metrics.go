@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"d3-domain-tool/internal/usage"
+)
+
+// SetMeter reports the paid provider calls counted by m at /metrics.
+func (s *Server) SetMeter(m *usage.Meter) {
+	s.meter = m
+}
+
+// SetMetricsToken sets the key a Prometheus scraper reads /metrics with,
+// seeing every tenant's counters. A tenant's own API key only shows its
+// own.
+func (s *Server) SetMetricsToken(token string) {
+	s.metricsToken = token
+}
+
+type allTenantsContext struct{}
+
+// authenticateMetrics lets the metrics token through to every tenant's
+// counters and anyone else through authenticate.
+func (s *Server) authenticateMetrics(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.metricsToken != "" && subtle.ConstantTimeCompare([]byte(givenKey(r)), []byte(s.metricsToken)) == 1 {
+			next(w, r.WithContext(context.WithValue(r.Context(), allTenantsContext{}, true)))
+			return
+		}
+		s.authenticate(next)(w, r)
+	}
+}
+
+// countAnalysis counts a fresh analysis requested by tenant.
+func (s *Server) countAnalysis(tenant string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.analyses[tenant]++
+}
+
+// handleMetrics serves Prometheus counters: fresh analyses per tenant
+// and, with a meter, calls to paid providers. Provider calls cannot be
+// told apart by tenant, as concurrent analyses share the clients, so a
+// tenant's share is estimated from its analyses. A tenant's key only
+// shows that tenant's analyses, so tenants do not learn of each other.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintf(w, "# HELP d3_analyses_total Analyses that made fresh lookups, per tenant.\n# TYPE d3_analyses_total counter\n")
+	s.mu.Lock()
+	tenants := make([]string, 0, len(s.analyses))
+	all, _ := r.Context().Value(allTenantsContext{}).(bool)
+	for t := range s.analyses {
+		if all || t == callerOf(r).tenant {
+			tenants = append(tenants, t)
+		}
+	}
+	sort.Strings(tenants)
+	for _, t := range tenants {
+		fmt.Fprintf(w, "d3_analyses_total{tenant=%q} %d\n", t, s.analyses[t])
+	}
+	s.mu.Unlock()
+	if s.meter != nil {
+		s.meter.WritePrometheus(w)
+	}
+}
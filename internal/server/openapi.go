@@ -0,0 +1,272 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// swaggerUI is the version of the Swagger UI assets /docs loads from the
+// unpkg CDN.
+const swaggerUI = "5"
+
+// errorBody is the response of a request that could not be served.
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+type openAPIDoc struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       openAPIInfo                     `json:"info"`
+	Paths      map[string]map[string]operation `json:"paths"`
+	Security   []map[string][]string           `json:"security,omitempty"`
+	Components struct {
+		Schemas         map[string]*schema        `json:"schemas"`
+		SecuritySchemes map[string]securityScheme `json:"securitySchemes,omitempty"`
+	} `json:"components"`
+}
+
+type securityScheme struct {
+	Type string `json:"type"`
+	In   string `json:"in"`
+	Name string `json:"name"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type operation struct {
+	Summary     string              `json:"summary"`
+	Description string              `json:"description,omitempty"`
+	Parameters  []parameter         `json:"parameters,omitempty"`
+	RequestBody *requestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]response `json:"responses"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type response struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+// schema is the subset of the OpenAPI 3.0 schema object the generator
+// produces.
+type schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+}
+
+// openAPI returns the OpenAPI 3.0 document of the server's routes, with
+// response schemas derived from the result types' JSON encoding.
+func (s *Server) openAPI() *openAPIDoc {
+	doc := &openAPIDoc{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:       "D3 Domain Analysis API",
+			Description: "Availability, WHOIS, blockchain, DOMA tokenization and valuation of traditional and blockchain domains.",
+			Version:     "1",
+		},
+		Paths: map[string]map[string]operation{},
+	}
+	doc.Components.Schemas = map[string]*schema{}
+	gen := &schemaGen{components: doc.Components.Schemas}
+
+	for _, r := range s.routes() {
+		op := operation{
+			Summary:     r.summary,
+			Description: r.description,
+			Responses: map[string]response{
+				"200": {Description: "OK", Content: jsonContent(gen.of(reflect.TypeOf(r.result)))},
+				"400": {Description: "Invalid request", Content: jsonContent(gen.of(reflect.TypeOf(errorBody{})))},
+			},
+		}
+		for _, p := range r.params {
+			in := p.in
+			if in == "" {
+				in = "query"
+			}
+			op.Parameters = append(op.Parameters, parameter{
+				Name:        p.name,
+				In:          in,
+				Description: p.description,
+				Required:    p.required,
+				Schema:      &schema{Type: "string"},
+			})
+		}
+		if r.accepted != nil {
+			op.Responses["202"] = response{Description: "Accepted", Content: jsonContent(gen.of(reflect.TypeOf(r.accepted)))}
+		}
+		if r.body != nil {
+			op.RequestBody = &requestBody{Required: true, Content: jsonContent(gen.of(reflect.TypeOf(r.body)))}
+		}
+		if doc.Paths[r.path] == nil {
+			doc.Paths[r.path] = map[string]operation{}
+		}
+		doc.Paths[r.path][strings.ToLower(r.method)] = op
+	}
+
+	if len(s.keys) > 0 {
+		doc.Components.SecuritySchemes = map[string]securityScheme{
+			"apiKey": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+		}
+		doc.Security = []map[string][]string{{"apiKey": {}}}
+		for _, ops := range doc.Paths {
+			for method, op := range ops {
+				op.Responses["401"] = response{Description: "Missing or unknown API key", Content: jsonContent(gen.of(reflect.TypeOf(errorBody{})))}
+				ops[method] = op
+			}
+		}
+	}
+	return doc
+}
+
+func jsonContent(s *schema) map[string]mediaType {
+	return map[string]mediaType{"application/json": {Schema: s}}
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemaGen derives schemas from Go types the way encoding/json encodes
+// them. Named struct types become components referenced by $ref, which
+// also keeps recursive types finite.
+type schemaGen struct {
+	components map[string]*schema
+}
+
+func (g *schemaGen) of(t reflect.Type) *schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+	switch {
+	case t == timeType:
+		return &schema{Type: "string", Format: "date-time", Nullable: nullable}
+	case t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType):
+		// Custom encodings are not described.
+		return &schema{Nullable: nullable}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &schema{Type: "boolean", Nullable: nullable}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schema{Type: "integer", Nullable: nullable}
+	case reflect.Float32, reflect.Float64:
+		return &schema{Type: "number", Nullable: nullable}
+	case reflect.String:
+		return &schema{Type: "string", Nullable: nullable}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &schema{Type: "string", Format: "byte", Nullable: true}
+		}
+		return &schema{Type: "array", Items: g.of(t.Elem()), Nullable: true}
+	case reflect.Map:
+		return &schema{Type: "object", AdditionalProperties: g.of(t.Elem()), Nullable: true}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := strings.ReplaceAll(t.String(), "/", ".")
+		if _, ok := g.components[name]; !ok {
+			g.components[name] = nil // placeholder for recursive references
+			g.components[name] = g.object(t)
+		}
+		// $ref siblings are ignored in OpenAPI 3.0, so nullability of
+		// referenced objects is left implicit.
+		return &schema{Ref: "#/components/schemas/" + name}
+	}
+	// interface{} and anything else: any value.
+	return &schema{}
+}
+
+func (g *schemaGen) object(t reflect.Type) *schema {
+	s := &schema{Type: "object", Properties: map[string]*schema{}}
+	g.fields(t, s)
+	return s
+}
+
+// fields adds t's exported fields under their JSON names, flattening
+// embedded structs as encoding/json does.
+func (g *schemaGen) fields(t reflect.Type, s *schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, s)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = g.of(f.Type)
+	}
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.openAPI())
+}
+
+// handleDocs serves Swagger UI pointed at /openapi.json.
+func handleDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>D3 Domain Analysis API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUI + `/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@` + swaggerUI + `/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui", tryItOutEnabled: true});
+  </script>
+</body>
+</html>
+`))
+}
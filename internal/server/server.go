@@ -0,0 +1,372 @@
+// Package server exposes domain analysis over HTTP: a REST endpoint that
+// returns full results, a GraphQL endpoint that returns only the selected
+// fields and a gRPC service for typed, streaming clients.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/audit"
+	"d3-domain-tool/internal/graphql"
+	"d3-domain-tool/internal/store"
+	"d3-domain-tool/internal/usage"
+)
+
+// MaxPortfolio is the most domains one portfolio query may analyze.
+const MaxPortfolio = 1000
+
+// workers is how many domains of a portfolio are analyzed at once.
+const workers = 8
+
+// Server answers analysis requests.
+type Server struct {
+	analyze func(ctx context.Context, domain string) (*analyzer.Result, error)
+	// client POSTs results to bulk request callbacks.
+	client     *http.Client
+	retryDelay time.Duration
+	logf       func(format string, args ...interface{})
+	// privateCallbacks lets callbacks reach private, loopback and
+	// link-local addresses; lookupIP resolves callback hosts.
+	privateCallbacks bool
+	lookupIP         func(ctx context.Context, host string) ([]net.IPAddr, error)
+	// keys are the tenants' API keys; without any the server is open.
+	keys []apiKey
+	// store, when set, keeps each tenant's history, watchlist and
+	// portfolios.
+	store *store.Store
+	audit *audit.Log
+	meter *usage.Meter
+	// metricsToken, when set, reads every tenant's counters at /metrics.
+	metricsToken string
+
+	mu       sync.Mutex
+	analyses map[string]int
+}
+
+// New returns a server that analyzes domains with a.
+func New(a *analyzer.Analyzer) *Server {
+	return newServer(a.AnalyzeDomainContext)
+}
+
+func newServer(analyze func(ctx context.Context, domain string) (*analyzer.Result, error)) *Server {
+	s := &Server{
+		analyze:    analyze,
+		analyses:   map[string]int{},
+		retryDelay: 5 * time.Second,
+		lookupIP:   net.DefaultResolver.LookupIPAddr,
+		logf: func(format string, args ...interface{}) {
+			fmt.Fprintf(os.Stderr, format, args...)
+		},
+	}
+	s.client = s.callbackClient()
+	return s
+}
+
+// route is an API endpoint. The OpenAPI document is generated from the
+// same table the handler is built from, so the two cannot drift apart.
+type route struct {
+	method      string
+	path        string
+	summary     string
+	description string
+	params      []param
+	// body, result and accepted are zero values of the request type and
+	// of the 200 and 202 response types.
+	body     interface{}
+	result   interface{}
+	accepted interface{}
+	handler  http.HandlerFunc
+}
+
+// param is a query parameter, or a path parameter when in is "path".
+type param struct {
+	name        string
+	in          string
+	description string
+	required    bool
+}
+
+func (s *Server) routes() []route {
+	query := []param{
+		{name: "query", description: "GraphQL query", required: true},
+		{name: "variables", description: "Variables as a JSON object"},
+		{name: "operationName", description: "Name of the operation to run"},
+	}
+	return []route{
+		{
+			method:      http.MethodGet,
+			path:        "/v1/analyze",
+			summary:     "Analyze a domain",
+			description: "Runs the full analysis (DNS, WHOIS, blockchain, DOMA and valuation) of one domain.",
+			params:      []param{{name: "domain", description: "Domain or blockchain name, e.g. example.com or vitalik.eth", required: true}},
+			result:      analyzer.Result{},
+			handler:     s.handleAnalyze,
+		},
+		{
+			method:  http.MethodPost,
+			path:    "/v1/analyze/bulk",
+			summary: "Analyze several domains",
+			description: fmt.Sprintf("Analyzes up to %d domains. Without callback_url the results are returned when all are done. "+
+				"With callback_url the request is accepted at once and each result is POSTed to that URL as it completes "+
+				"(retried %d times), e.g. to a Zapier or Make catch hook.", MaxPortfolio, deliveryAttempts),
+			body:     bulkRequest{},
+			result:   []Delivery{},
+			accepted: bulkAccepted{},
+			handler:  s.handleBulk,
+		},
+		{
+			method:  http.MethodPost,
+			path:    "/graphql",
+			summary: "Run a GraphQL query",
+			description: "Returns only the selected fields. Root fields are analyze(domain: String!) and " +
+				"portfolio(domains: [String!]!); nested fields use the JSON names of the /v1/analyze result.",
+			body:    graphql.Request{},
+			result:  graphql.Response{},
+			handler: s.handleGraphQL,
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/graphql",
+			summary: "Run a GraphQL query given in the URL",
+			params:  query,
+			result:  graphql.Response{},
+			handler: s.handleGraphQL,
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/v1/history",
+			summary:     "List past analyses of a domain",
+			description: "Returns the tenant's recorded results of a domain, oldest first. Requires -data-dir.",
+			params:      []param{{name: "domain", description: "Domain or blockchain name", required: true}},
+			result:      []*analyzer.Result{},
+			handler:     s.handleHistory,
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/watchlist",
+			summary: "Get the watchlist",
+			result:  domainList{},
+			handler: s.handleWatchlist,
+		},
+		{
+			method:  http.MethodPut,
+			path:    "/v1/watchlist",
+			summary: "Replace the watchlist",
+			body:    domainList{},
+			result:  domainList{},
+			handler: s.handleWatchlist,
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/portfolios",
+			summary: "List portfolios",
+			result:  portfolioList{},
+			handler: s.handlePortfolios,
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/portfolios/{name}",
+			summary: "Get a portfolio",
+			params:  []param{portfolioName},
+			result:  domainList{},
+			handler: s.handlePortfolio,
+		},
+		{
+			method:  http.MethodPut,
+			path:    "/v1/portfolios/{name}",
+			summary: "Create or replace a portfolio",
+			params:  []param{portfolioName},
+			body:    domainList{},
+			result:  domainList{},
+			handler: s.handlePortfolio,
+		},
+		{
+			method:  http.MethodDelete,
+			path:    "/v1/portfolios/{name}",
+			summary: "Delete a portfolio",
+			params:  []param{portfolioName},
+			result:  domainList{},
+			handler: s.handlePortfolio,
+		},
+	}
+}
+
+// Handler serves the routes, the DomainAnalysis gRPC service, the OpenAPI
+// document at /openapi.json, an interactive explorer at /docs and
+// Prometheus metrics at /metrics. All but the OpenAPI document and the
+// explorer require an API key.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	registered := map[string]bool{}
+	for _, r := range s.routes() {
+		// Handlers dispatch on the method themselves.
+		if !registered[r.path] {
+			mux.HandleFunc(r.path, s.authenticate(r.handler))
+			registered[r.path] = true
+		}
+	}
+	mux.HandleFunc("/d3.v1.DomainAnalysis/", s.authenticate(s.handleGRPC))
+	mux.HandleFunc("/openapi.json", s.handleOpenAPI)
+	mux.HandleFunc("/docs", handleDocs)
+	mux.HandleFunc("/metrics", s.authenticateMetrics(s.handleMetrics))
+	return mux
+}
+
+func (s *Server) handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET")
+		return
+	}
+	domain := normalize(r.URL.Query().Get("domain"))
+	if domain == "" {
+		writeError(w, http.StatusBadRequest, "domain parameter is required")
+		return
+	}
+	result, err := s.analyzeFor(r)(domain)
+	if errors.Is(err, errReadOnly) {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if vars := r.URL.Query().Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				writeError(w, http.StatusBadRequest, "invalid variables: "+err.Error())
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "use GET or POST")
+		return
+	}
+	if req.Query == "" {
+		writeError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	resp := graphql.Execute(s.schema(s.analyzeFor(r)), req)
+	status := http.StatusOK
+	if resp.Data == nil {
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, resp)
+}
+
+// schema is the GraphQL root: analyze(domain:) returns one result,
+// portfolio(domains:) a list in the order given. Selections use the JSON
+// field names of the REST result, e.g.
+//
+//	{ portfolio(domains: ["a.com", "b.eth"]) { domain whois_data { expiry_date } valuation_data { estimated_value } } }
+func (s *Server) schema(analyze func(domain string) (*analyzer.Result, error)) graphql.Schema {
+	return graphql.Schema{
+		"analyze": func(args map[string]interface{}) (interface{}, error) {
+			domain, _ := args["domain"].(string)
+			if domain = normalize(domain); domain == "" {
+				return nil, fmt.Errorf("analyze: domain argument is required")
+			}
+			return analyze(domain)
+		},
+		"portfolio": func(args map[string]interface{}) (interface{}, error) {
+			list, ok := args["domains"].([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("portfolio: domains argument must be a list")
+			}
+			if len(list) > MaxPortfolio {
+				return nil, fmt.Errorf("portfolio: at most %d domains per query", MaxPortfolio)
+			}
+			var domains []string
+			for _, item := range list {
+				domain, _ := item.(string)
+				if domain = normalize(domain); domain == "" {
+					return nil, fmt.Errorf("portfolio: domains must be non-empty strings")
+				}
+				domains = append(domains, domain)
+			}
+			return s.portfolio(analyze, domains)
+		},
+	}
+}
+
+// portfolio analyzes domains concurrently, keeping their order.
+func (s *Server) portfolio(analyze func(domain string) (*analyzer.Result, error), domains []string) ([]*analyzer.Result, error) {
+	deliveries := make([]Delivery, len(domains))
+	s.each(analyze, domains, func(d Delivery) {
+		deliveries[d.Index] = d
+	})
+	results := make([]*analyzer.Result, len(domains))
+	for i, d := range deliveries {
+		if d.Error != "" {
+			return nil, fmt.Errorf("%s: %s", d.Domain, d.Error)
+		}
+		results[i] = d.Result
+	}
+	return results, nil
+}
+
+// each analyzes domains with a pool of workers and calls done with each
+// result as it completes. done may be called concurrently.
+func (s *Server) each(analyze func(domain string) (*analyzer.Result, error), domains []string, done func(Delivery)) {
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(domains); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				d := Delivery{Index: i, Total: len(domains), Domain: domains[i]}
+				result, err := analyze(domains[i])
+				if err != nil {
+					d.Error = err.Error()
+				}
+				d.Result = result
+				done(d)
+			}
+		}()
+	}
+	for i := range domains {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+func normalize(domain string) string {
+	return analyzer.Canonicalize(domain)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorBody{Error: msg})
+}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/usage"
+	"d3-domain-tool/internal/valuation"
+)
+
+func fakeAnalyze(ctx context.Context, domain string) (*analyzer.Result, error) {
+	if domain == "fail.com" {
+		return nil, fmt.Errorf("whois check failed")
+	}
+	return &analyzer.Result{
+		Domain:        domain,
+		Status:        analyzer.StatusComplete,
+		ValuationData: &valuation.Result{EstimatedValue: len(domain) * 100},
+	}, nil
+}
+
+func testServer(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(newServer(fakeAnalyze).Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestAnalyze(t *testing.T) {
+	ts := testServer(t)
+
+	resp, err := http.Get(ts.URL + "/v1/analyze?domain=Example.COM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var result analyzer.Result
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || result.Domain != "example.com" || result.ValuationData.EstimatedValue != 1100 {
+		t.Errorf("unexpected response %d %+v", resp.StatusCode, result)
+	}
+
+	for path, want := range map[string]int{
+		"/v1/analyze":                 http.StatusBadRequest,
+		"/v1/analyze?domain=fail.com": http.StatusBadGateway,
+	} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("%s: got HTTP %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestGraphQL(t *testing.T) {
+	ts := testServer(t)
+
+	body := `{"query": "query($ds: [String!]!) { portfolio(domains: $ds) { domain valuation_data { estimated_value } } }", "variables": {"ds": ["a.com", "bb.eth"]}}`
+	resp, err := http.Post(ts.URL+"/graphql", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	want := `{"data":{"portfolio":[{"domain":"a.com","valuation_data":{"estimated_value":500}},{"domain":"bb.eth","valuation_data":{"estimated_value":600}}]}}`
+	if compact(t, got) != want {
+		t.Errorf("got %s\nwant %s", compact(t, got), want)
+	}
+
+	resp, err = http.Get(ts.URL + "/graphql?query=" + url.QueryEscape(`{ analyze(domain: "fail.com") { domain } }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	want = `{"data":{"analyze":null},"errors":[{"message":"whois check failed","path":["analyze"]}]}`
+	if resp.StatusCode != http.StatusOK || compact(t, got) != want {
+		t.Errorf("got HTTP %d %s\nwant %s", resp.StatusCode, compact(t, got), want)
+	}
+
+	resp, err = http.Post(ts.URL+"/graphql", "application/json", strings.NewReader(`{"query": "{ analyze }"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("missing argument: got HTTP %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post(ts.URL+"/graphql", "application/json", strings.NewReader(`{"query": "{ analyze("}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("syntax error: got HTTP %d", resp.StatusCode)
+	}
+}
+
+func compact(t *testing.T, data []byte) string {
+	t.Helper()
+	var b bytes.Buffer
+	if err := json.Compact(&b, data); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	return b.String()
+}
+
+func TestOpenAPI(t *testing.T) {
+	ts := testServer(t)
+
+	resp, err := http.Get(ts.URL + "/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var doc struct {
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]struct {
+					Ref    string `json:"$ref"`
+					Type   string `json:"type"`
+					Format string `json:"format"`
+				} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Paths["/v1/analyze"]["get"] == nil || doc.Paths["/graphql"]["post"] == nil || doc.Paths["/graphql"]["get"] == nil {
+		t.Errorf("missing operations in %v", doc.Paths)
+	}
+	result := doc.Components.Schemas["analyzer.Result"].Properties
+	if result["whois_data"].Ref != "#/components/schemas/whois.Result" || result["timestamp"].Format != "date-time" {
+		t.Errorf("unexpected Result schema %+v", result)
+	}
+	if doc.Components.Schemas["whois.Result"].Properties["expiry_date"].Type != "string" {
+		t.Error("whois.Result schema is missing expiry_date")
+	}
+
+	resp, err = http.Get(ts.URL + "/docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(page), `url: "/openapi.json"`) {
+		t.Errorf("docs page does not load the OpenAPI document:\n%s", page)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	s := newServer(fakeAnalyze)
+	s.SetMeter(usage.New())
+	s.SetTenants([]Tenant{{Name: "acme", Keys: []string{"k"}}, {Name: "globex", Keys: []string{"g"}}})
+	s.SetMetricsToken("scrape")
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	get := func(path, key string) (int, string) {
+		req, _ := http.NewRequest("GET", ts.URL+path, nil)
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	get("/v1/analyze?domain=a.com", "k")
+	get("/v1/analyze?domain=b.com", "g")
+
+	if status, _ := get("/metrics", ""); status != http.StatusUnauthorized {
+		t.Errorf("expected /metrics to need a key, got %d", status)
+	}
+	_, body := get("/metrics", "scrape")
+	for _, want := range []string{`d3_analyses_total{tenant="acme"} 1`, `d3_analyses_total{tenant="globex"} 1`, `d3_provider_calls_total{provider="whoisxml"} 0`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+	if _, body := get("/metrics", "k"); !strings.Contains(body, `tenant="acme"`) || strings.Contains(body, "globex") {
+		t.Errorf("expected a tenant to see only its own counters:\n%s", body)
+	}
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/audit"
+	"d3-domain-tool/internal/store"
+)
+
+// defaultTenant owns the data of a server without tenants.
+const defaultTenant = "default"
+
+// Tenant is a client of a shared deployment, such as one brand of an
+// agency. Requests made with its keys only see its own history, watchlist
+// and portfolios.
+type Tenant struct {
+	Name string   `json:"name"`
+	Keys []string `json:"keys,omitempty"`
+	// ReadOnlyKeys never trigger WHOIS, DNS or blockchain lookups: analyses
+	// made with them are answered from the tenant's history, so a client
+	// given one cannot use up the deployment's rate limits.
+	ReadOnlyKeys []string `json:"read_only_keys,omitempty"`
+}
+
+// LoadTenants reads a tenants file:
+//
+//	{"tenants": [{"name": "acme", "keys": ["..."], "read_only_keys": ["..."]}]}
+func LoadTenants(path string) ([]Tenant, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Tenants []Tenant `json:"tenants"`
+	}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(file.Tenants) == 0 {
+		return nil, fmt.Errorf("%s: no tenants defined", path)
+	}
+	return file.Tenants, nil
+}
+
+// apiKey maps a key to its caller.
+type apiKey struct {
+	key string
+	caller
+}
+
+// caller is who made a request.
+type caller struct {
+	tenant   string
+	readOnly bool
+}
+
+// errReadOnly is returned for an analysis a read-only key would need a
+// fresh lookup for.
+var errReadOnly = errors.New("this API key is read-only and there is no recorded analysis")
+
+// SetTenants requires one of the tenants' API keys on every API request,
+// given as X-API-Key or Authorization: Bearer. Without tenants the server
+// is open and all data belongs to one default tenant.
+func (s *Server) SetTenants(tenants []Tenant) error {
+	seen := map[string]bool{}
+	names := map[string]bool{}
+	var keys []apiKey
+	for _, t := range tenants {
+		if t.Name == "" || names[t.Name] {
+			return fmt.Errorf("tenant names must be unique and non-empty (%q)", t.Name)
+		}
+		names[t.Name] = true
+		if len(t.Keys)+len(t.ReadOnlyKeys) == 0 {
+			return fmt.Errorf("tenant %s has no API keys", t.Name)
+		}
+		for i, k := range append(append([]string{}, t.Keys...), t.ReadOnlyKeys...) {
+			if k == "" || seen[k] {
+				return fmt.Errorf("tenant %s: API keys must be unique and non-empty", t.Name)
+			}
+			seen[k] = true
+			keys = append(keys, apiKey{key: k, caller: caller{tenant: t.Name, readOnly: i >= len(t.Keys)}})
+		}
+	}
+	s.keys = keys
+	return nil
+}
+
+// SetStore keeps each tenant's analysis history, watchlist and
+// portfolios in st.
+func (s *Server) SetStore(st *store.Store) {
+	s.store = st
+}
+
+// SetAuditLog logs each analysis a caller requests under its tenant, so
+// the WHOIS and HTTP queries logged after it can be attributed.
+func (s *Server) SetAuditLog(l *audit.Log) {
+	s.audit = l
+}
+
+type callerContext struct{}
+
+// authenticate resolves the request's caller from its API key.
+func (s *Server) authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c := caller{tenant: defaultTenant}
+		if len(s.keys) > 0 {
+			given := givenKey(r)
+			c = caller{}
+			// Compare against every key in constant time.
+			for _, k := range s.keys {
+				if subtle.ConstantTimeCompare([]byte(given), []byte(k.key)) == 1 {
+					c = k.caller
+				}
+			}
+			if given == "" || c.tenant == "" {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(w, http.StatusUnauthorized, "a valid API key is required (X-API-Key or Authorization: Bearer)")
+				return
+			}
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), callerContext{}, c)))
+	}
+}
+
+// givenKey returns the API key the request carries, if any.
+func givenKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+func callerOf(r *http.Request) caller {
+	c, _ := r.Context().Value(callerContext{}).(caller)
+	return c
+}
+
+// namespace returns the storage of the request's tenant, or nil when the
+// server has no store.
+func (s *Server) namespace(r *http.Request) *store.Namespace {
+	if s.store == nil {
+		return nil
+	}
+	ns, err := s.store.Namespace(callerOf(r).tenant)
+	if err != nil {
+		return nil
+	}
+	return ns
+}
+
+// analyzeFor returns how the request analyzes a domain. Results of fresh
+// lookups are recorded in the tenant's history when the server has a
+// store; a read-only caller gets the latest recorded result instead. The
+// lookups are bounded by the request's context, under which the audit log
+// attributes them to the tenant.
+func (s *Server) analyzeFor(r *http.Request) func(domain string) (*analyzer.Result, error) {
+	ns := s.namespace(r)
+	if callerOf(r).readOnly {
+		return func(domain string) (*analyzer.Result, error) {
+			if ns == nil {
+				return nil, errReadOnly
+			}
+			history, err := ns.History(domain)
+			if err != nil {
+				return nil, err
+			}
+			if len(history) == 0 {
+				return nil, errReadOnly
+			}
+			return history[len(history)-1], nil
+		}
+	}
+	tenant := callerOf(r).tenant
+	ctx := audit.WithActor(r.Context(), tenant)
+	return func(domain string) (*analyzer.Result, error) {
+		s.countAnalysis(tenant)
+		if s.audit != nil {
+			if err := s.audit.Add(audit.Entry{Actor: tenant, Backend: audit.BackendAPI, Query: "analyze", Domain: domain}); err != nil {
+				s.logf("Warning: %v\n", err)
+			}
+		}
+		result, err := s.analyze(ctx, domain)
+		if err == nil && ns != nil {
+			if err := ns.Record(result); err != nil {
+				s.logf("tenant %s: recording %s: %v\n", ns.Tenant(), domain, err)
+			}
+		}
+		return result, err
+	}
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/audit"
+	"d3-domain-tool/internal/store"
+)
+
+func tenantServer(t *testing.T) *httptest.Server {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newServer(fakeAnalyze)
+	s.SetStore(st)
+	if err := s.SetTenants([]Tenant{
+		{Name: "acme", Keys: []string{"acme-key"}},
+		{Name: "globex", Keys: []string{"globex-key", "globex-key-2"}, ReadOnlyKeys: []string{"globex-ro"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func call(t *testing.T, method, url, key, body string, v interface{}) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestTenantAuth(t *testing.T) {
+	ts := tenantServer(t)
+
+	for key, want := range map[string]int{
+		"":           http.StatusUnauthorized,
+		"wrong":      http.StatusUnauthorized,
+		"acme-key":   http.StatusOK,
+		"globex-key": http.StatusOK,
+	} {
+		if got := call(t, "GET", ts.URL+"/v1/analyze?domain=a.com", key, "", nil); got != want {
+			t.Errorf("key %q: got HTTP %d, want %d", key, got, want)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", ts.URL+"/v1/watchlist", nil)
+	req.Header.Set("X-API-Key", "globex-key-2")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("X-API-Key: got HTTP %d", resp.StatusCode)
+	}
+
+	// The API documentation stays public.
+	if got := call(t, "GET", ts.URL+"/openapi.json", "", "", nil); got != http.StatusOK {
+		t.Errorf("openapi.json: got HTTP %d", got)
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	ts := tenantServer(t)
+
+	call(t, "GET", ts.URL+"/v1/analyze?domain=a.com", "acme-key", "", nil)
+	call(t, "PUT", ts.URL+"/v1/watchlist", "acme-key", `{"domains": ["A.com", "b.eth"]}`, nil)
+	call(t, "PUT", ts.URL+"/v1/portfolios/brands", "acme-key", `{"domains": ["a.com"]}`, nil)
+
+	var history []*analyzer.Result
+	call(t, "GET", ts.URL+"/v1/history?domain=a.com", "acme-key", "", &history)
+	if len(history) != 1 || history[0].Domain != "a.com" {
+		t.Errorf("acme history: %+v", history)
+	}
+	var list domainList
+	call(t, "GET", ts.URL+"/v1/watchlist", "acme-key", "", &list)
+	if strings.Join(list.Domains, ",") != "a.com,b.eth" {
+		t.Errorf("acme watchlist: %v", list.Domains)
+	}
+
+	// Another tenant sees none of it, even with the same names.
+	history = nil
+	call(t, "GET", ts.URL+"/v1/history?domain=a.com", "globex-key", "", &history)
+	if len(history) != 0 {
+		t.Errorf("globex sees acme's history: %+v", history)
+	}
+	list = domainList{}
+	call(t, "GET", ts.URL+"/v1/watchlist", "globex-key", "", &list)
+	if len(list.Domains) != 0 {
+		t.Errorf("globex sees acme's watchlist: %v", list.Domains)
+	}
+	var portfolios portfolioList
+	call(t, "GET", ts.URL+"/v1/portfolios", "globex-key", "", &portfolios)
+	if len(portfolios.Portfolios) != 0 {
+		t.Errorf("globex sees acme's portfolios: %v", portfolios.Portfolios)
+	}
+	if got := call(t, "GET", ts.URL+"/v1/portfolios/brands", "globex-key", "", nil); got != http.StatusNotFound {
+		t.Errorf("globex reading acme's portfolio: got HTTP %d", got)
+	}
+	if got := call(t, "DELETE", ts.URL+"/v1/portfolios/brands", "globex-key", "", nil); got != http.StatusNotFound {
+		t.Errorf("globex deleting acme's portfolio: got HTTP %d", got)
+	}
+	if got := call(t, "DELETE", ts.URL+"/v1/portfolios/brands", "acme-key", "", nil); got != http.StatusOK {
+		t.Errorf("acme deleting its portfolio: got HTTP %d", got)
+	}
+}
+
+func TestLoadTenants(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.json")
+	os.WriteFile(path, []byte(`{"tenants": [{"name": "acme", "keys": ["k1"]}]}`), 0600)
+	tenants, err := LoadTenants(path)
+	if err != nil || len(tenants) != 1 || tenants[0].Name != "acme" {
+		t.Fatalf("LoadTenants = %+v, %v", tenants, err)
+	}
+
+	s := newServer(fakeAnalyze)
+	if err := s.SetTenants([]Tenant{{Name: "a", Keys: []string{"k"}}, {Name: "b", Keys: []string{"k"}}}); err == nil {
+		t.Error("a key shared by two tenants was accepted")
+	}
+}
+
+func TestReadOnlyKeys(t *testing.T) {
+	analyzed := 0
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newServer(func(ctx context.Context, domain string) (*analyzer.Result, error) {
+		analyzed++
+		return fakeAnalyze(ctx, domain)
+	})
+	s.SetStore(st)
+	s.SetTenants([]Tenant{{Name: "acme", Keys: []string{"rw"}, ReadOnlyKeys: []string{"ro"}}})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	if got := call(t, "GET", ts.URL+"/v1/analyze?domain=a.com", "ro", "", nil); got != http.StatusForbidden {
+		t.Errorf("uncached analysis with a read-only key: got HTTP %d", got)
+	}
+	call(t, "GET", ts.URL+"/v1/analyze?domain=a.com", "rw", "", nil)
+	var result analyzer.Result
+	if got := call(t, "GET", ts.URL+"/v1/analyze?domain=a.com", "ro", "", &result); got != http.StatusOK || result.Domain != "a.com" {
+		t.Errorf("cached analysis with a read-only key: got HTTP %d %+v", got, result)
+	}
+	if analyzed != 1 {
+		t.Errorf("read-only key triggered lookups: %d analyses", analyzed)
+	}
+
+	var deliveries []Delivery
+	call(t, "POST", ts.URL+"/v1/analyze/bulk", "ro", `{"domains": ["a.com", "b.com"]}`, &deliveries)
+	if len(deliveries) != 2 || deliveries[0].Result == nil || deliveries[1].Error == "" || analyzed != 1 {
+		t.Errorf("bulk with a read-only key: %+v (%d analyses)", deliveries, analyzed)
+	}
+
+	if got := call(t, "PUT", ts.URL+"/v1/watchlist", "ro", `{"domains": ["a.com"]}`, nil); got != http.StatusForbidden {
+		t.Errorf("watchlist update with a read-only key: got HTTP %d", got)
+	}
+	if got := call(t, "GET", ts.URL+"/v1/history?domain=a.com", "ro", "", nil); got != http.StatusOK {
+		t.Errorf("history with a read-only key: got HTTP %d", got)
+	}
+}
+
+func TestAuditActor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := audit.Open(path, "operator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer backend.Close()
+	client := &http.Client{Transport: log.Transport(http.DefaultTransport)}
+
+	// The analysis queries a backend the way the analyzer's clients do.
+	s := newServer(func(ctx context.Context, domain string) (*analyzer.Result, error) {
+		req, _ := http.NewRequestWithContext(audit.WithDomain(ctx, domain), http.MethodGet, backend.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+		return fakeAnalyze(ctx, domain)
+	})
+	s.SetAuditLog(log)
+	s.SetTenants([]Tenant{{Name: "acme", Keys: []string{"acme-key"}}})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	call(t, "GET", ts.URL+"/v1/analyze?domain=a.com", "acme-key", "", nil)
+	log.Close()
+
+	entries, err := audit.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected the API and HTTP entries, got %+v", entries)
+	}
+	for _, e := range entries {
+		if e.Actor != "acme" || e.Domain != "a.com" {
+			t.Errorf("%s entry not attributed to tenant acme and a.com: %+v", e.Backend, e)
+		}
+	}
+}
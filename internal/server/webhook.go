@@ -0,0 +1,205 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+)
+
+// deliveryAttempts is how often a result is POSTed to a callback URL
+// before it is given up on.
+const deliveryAttempts = 3
+
+// bulkRequest is the body of POST /v1/analyze/bulk.
+type bulkRequest struct {
+	Domains []string `json:"domains"`
+	// CallbackURL, when set, makes the request asynchronous: each result
+	// is POSTed there as a Delivery as soon as it is complete.
+	CallbackURL string `json:"callback_url,omitempty"`
+}
+
+// bulkAccepted answers a bulk request with a callback URL.
+type bulkAccepted struct {
+	JobID       string `json:"job_id"`
+	Domains     int    `json:"domains"`
+	CallbackURL string `json:"callback_url"`
+}
+
+// Delivery is one completed analysis of a bulk request. It is the body
+// POSTed to the callback URL and an element of the synchronous response.
+type Delivery struct {
+	JobID  string           `json:"job_id,omitempty"`
+	Index  int              `json:"index"`
+	Total  int              `json:"total"`
+	Domain string           `json:"domain"`
+	Result *analyzer.Result `json:"result,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+func (s *Server) handleBulk(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+	var req bulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if len(req.Domains) == 0 {
+		writeError(w, http.StatusBadRequest, "domains is required")
+		return
+	}
+	if len(req.Domains) > MaxPortfolio {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("at most %d domains per request", MaxPortfolio))
+		return
+	}
+	domains := make([]string, len(req.Domains))
+	for i, d := range req.Domains {
+		if domains[i] = normalize(d); domains[i] == "" {
+			writeError(w, http.StatusBadRequest, "domains must be non-empty strings")
+			return
+		}
+	}
+
+	if req.CallbackURL == "" {
+		deliveries := make([]Delivery, len(domains))
+		s.each(s.analyzeFor(r), domains, func(d Delivery) {
+			deliveries[d.Index] = d
+		})
+		writeJSON(w, http.StatusOK, deliveries)
+		return
+	}
+
+	u, err := url.Parse(req.CallbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		writeError(w, http.StatusBadRequest, "callback_url must be an absolute http or https URL")
+		return
+	}
+	if err := s.checkCallback(r.Context(), u.Hostname()); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// The analyses outlive the request.
+	analyze := s.analyzeFor(r.WithContext(context.WithoutCancel(r.Context())))
+	job := newJobID()
+	go s.each(analyze, domains, func(d Delivery) {
+		d.JobID = job
+		if err := s.deliver(req.CallbackURL, d); err != nil {
+			s.logf("job %s: %s: %v\n", job, d.Domain, err)
+		}
+	})
+	writeJSON(w, http.StatusAccepted, bulkAccepted{JobID: job, Domains: len(domains), CallbackURL: req.CallbackURL})
+}
+
+// SetAllowPrivateCallbacks lets bulk request callbacks reach private,
+// loopback and link-local addresses, e.g. a receiver on the same host.
+// They are refused by default so a client cannot make the server POST to
+// internal services or cloud metadata endpoints.
+func (s *Server) SetAllowPrivateCallbacks(allow bool) {
+	s.privateCallbacks = allow
+}
+
+// checkCallback resolves a callback host and refuses it when one of its
+// addresses is private, loopback or link-local.
+func (s *Server) checkCallback(ctx context.Context, host string) error {
+	if s.privateCallbacks {
+		return nil
+	}
+	var addrs []net.IPAddr
+	if ip := net.ParseIP(host); ip != nil {
+		addrs = []net.IPAddr{{IP: ip}}
+	} else {
+		var err error
+		if addrs, err = s.lookupIP(ctx, host); err != nil {
+			return fmt.Errorf("callback_url host %s does not resolve: %v", host, err)
+		}
+	}
+	for _, a := range addrs {
+		if internal(a.IP) {
+			return fmt.Errorf("callback_url host %s resolves to %s, a private, loopback or link-local address", host, a.IP)
+		}
+	}
+	return nil
+}
+
+// callbackClient returns the client that POSTs to callbacks. The check of
+// handleBulk is repeated on every address it connects to, so a callback
+// host cannot reach an internal address by changing its DNS answer before
+// a delivery, and redirects are not followed.
+func (s *Server) callbackClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); !s.privateCallbacks && (ip == nil || internal(ip)) {
+				return fmt.Errorf("refusing to connect to %s, a private, loopback or link-local address", host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the callback host.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// internal reports whether ip is private, loopback, link-local or
+// unspecified.
+func internal(ip net.IP) bool {
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// deliver POSTs d to callback, retrying failed attempts.
+func (s *Server) deliver(callback string, d Delivery) error {
+	body, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		err = post(s.client, callback, body)
+		if err == nil || attempt == deliveryAttempts {
+			return err
+		}
+		time.Sleep(time.Duration(attempt) * s.retryDelay)
+	}
+}
+
+func post(client *http.Client, callback string, body []byte) error {
+	resp, err := client.Post(callback, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("callback failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("callback returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBulkSync(t *testing.T) {
+	ts := testServer(t)
+
+	resp, err := http.Post(ts.URL+"/v1/analyze/bulk", "application/json", strings.NewReader(`{"domains": ["a.com", "fail.com"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var deliveries []Delivery
+	if err := json.NewDecoder(resp.Body).Decode(&deliveries); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || len(deliveries) != 2 {
+		t.Fatalf("unexpected response %d %+v", resp.StatusCode, deliveries)
+	}
+	if d := deliveries[0]; d.Domain != "a.com" || d.Result == nil || d.Total != 2 {
+		t.Errorf("unexpected first delivery %+v", d)
+	}
+	if d := deliveries[1]; d.Domain != "fail.com" || d.Error != "whois check failed" || d.Result != nil {
+		t.Errorf("unexpected second delivery %+v", d)
+	}
+}
+
+func TestBulkCallback(t *testing.T) {
+	var mu sync.Mutex
+	var got []Delivery
+	failed := false
+	received := make(chan struct{}, 10)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var d Delivery
+		json.NewDecoder(r.Body).Decode(&d)
+		mu.Lock()
+		defer mu.Unlock()
+		// Fail the first attempt to exercise the retry.
+		if !failed {
+			failed = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		got = append(got, d)
+		received <- struct{}{}
+	}))
+	defer hook.Close()
+
+	s := newServer(fakeAnalyze)
+	s.retryDelay = time.Millisecond
+	s.SetAllowPrivateCallbacks(true)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	body := `{"domains": ["a.com", "b.eth"], "callback_url": "` + hook.URL + `"}`
+	resp, err := http.Post(ts.URL+"/v1/analyze/bulk", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var accepted bulkAccepted
+	json.NewDecoder(resp.Body).Decode(&accepted)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted || accepted.JobID == "" || accepted.Domains != 2 {
+		t.Fatalf("unexpected response %d %+v", resp.StatusCode, accepted)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-received:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for callbacks")
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	sort.Slice(got, func(i, j int) bool { return got[i].Index < got[j].Index })
+	if got[0].JobID != accepted.JobID || got[0].Domain != "a.com" || got[1].Domain != "b.eth" || got[1].Result.Domain != "b.eth" {
+		t.Errorf("unexpected deliveries %+v", got)
+	}
+
+	resp, err = http.Post(ts.URL+"/v1/analyze/bulk", "application/json", strings.NewReader(`{"domains": ["a.com"], "callback_url": "ftp://x"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid callback URL: got HTTP %d", resp.StatusCode)
+	}
+}
+
+func TestBulkCallbackPrivate(t *testing.T) {
+	s := newServer(fakeAnalyze)
+	s.lookupIP = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		switch host {
+		case "hooks.example.com":
+			return []net.IPAddr{{IP: net.ParseIP("203.0.113.10")}}, nil
+		case "internal.example.com":
+			return []net.IPAddr{{IP: net.ParseIP("203.0.113.10")}, {IP: net.ParseIP("10.0.0.5")}}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	post := func(callback string) int {
+		t.Helper()
+		body := `{"domains": ["a.com"], "callback_url": "` + callback + `"}`
+		resp, err := http.Post(ts.URL+"/v1/analyze/bulk", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	for _, callback := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://[::1]/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://192.168.1.1/hook",
+		"https://internal.example.com/hook",
+		"https://unknown.example.com/hook",
+	} {
+		if code := post(callback); code != http.StatusBadRequest {
+			t.Errorf("%s: got HTTP %d, want 400", callback, code)
+		}
+	}
+	if code := post("https://hooks.example.com/hook"); code != http.StatusAccepted {
+		t.Errorf("public callback: got HTTP %d, want 202", code)
+	}
+
+	s.SetAllowPrivateCallbacks(true)
+	if code := post("http://127.0.0.1:8080/hook"); code != http.StatusAccepted {
+		t.Errorf("allowed private callback: got HTTP %d, want 202", code)
+	}
+}
+
+func TestCallbackClient(t *testing.T) {
+	var hits sync.Map
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Store("target", true)
+	}))
+	defer target.Close()
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	s := newServer(fakeAnalyze)
+	s.retryDelay = time.Millisecond
+	// Connecting to a loopback address is refused however the host was
+	// checked before.
+	if err := s.deliver(target.URL, Delivery{Domain: "a.com"}); err == nil || !strings.Contains(err.Error(), "refusing to connect") {
+		t.Errorf("private delivery: got %v", err)
+	}
+	if _, ok := hits.Load("target"); ok {
+		t.Fatal("the callback reached a loopback address")
+	}
+
+	// Redirects are not followed, even where private callbacks are allowed.
+	s.SetAllowPrivateCallbacks(true)
+	if err := s.deliver(redirect.URL, Delivery{Domain: "a.com"}); err == nil || !strings.Contains(err.Error(), "HTTP 302") {
+		t.Errorf("redirected delivery: got %v", err)
+	}
+	if _, ok := hits.Load("target"); ok {
+		t.Error("the callback followed a redirect")
+	}
+}
@@ -0,0 +1,211 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Algorithm is the only signature scheme currently produced.
+const Algorithm = "ed25519"
+
+// Envelope wraps a JSON document with a detached signature and/or a trusted
+// timestamp over it. The signature covers the compacted payload and the
+// signing time, so the envelope can be re-indented without invalidating it.
+type Envelope struct {
+	Payload   json.RawMessage `json:"payload"`
+	Algorithm string          `json:"algorithm,omitempty"`
+	PublicKey string          `json:"public_key,omitempty"`
+	SignedAt  *time.Time      `json:"signed_at,omitempty"`
+	Signature string          `json:"signature,omitempty"`
+	Timestamp *Timestamp      `json:"timestamp,omitempty"`
+}
+
+// NewEnvelope wraps a JSON payload in an unsigned envelope.
+func NewEnvelope(payload []byte) (*Envelope, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, payload); err != nil {
+		return nil, fmt.Errorf("payload is not valid JSON: %v", err)
+	}
+	return &Envelope{Payload: json.RawMessage(compact.Bytes())}, nil
+}
+
+// LoadOrCreateKey reads a PEM-encoded ed25519 private key from path. When
+// the file does not exist a new key is generated and written there, with
+// the public key alongside it in path+".pub"; created reports whether that
+// happened.
+func LoadOrCreateKey(path string) (key ed25519.PrivateKey, created bool, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		key, err = generateKey(path)
+		return key, err == nil, err
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read signing key: %v", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, false, fmt.Errorf("signing key %s is not PEM encoded", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse signing key: %v", err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, false, fmt.Errorf("signing key %s is not an ed25519 key", path)
+	}
+	return key, false, nil
+}
+
+func generateKey(path string) (ed25519.PrivateKey, error) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %v", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signing key: %v", err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write signing key: %v", err)
+	}
+
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %v", err)
+	}
+	if err := os.WriteFile(path+".pub", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write public key: %v", err)
+	}
+	return key, nil
+}
+
+// LoadPublicKey reads a PEM-encoded ed25519 public key.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %v", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("public key %s is not PEM encoded", path)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %v", err)
+	}
+	pub, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is not an ed25519 key", path)
+	}
+	return pub, nil
+}
+
+// LoadCertPool reads the PEM certificates at path, such as a TSA's CA.
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificates: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s holds no PEM certificates", path)
+	}
+	return pool, nil
+}
+
+// Sign wraps a JSON payload in a signed envelope.
+func Sign(key ed25519.PrivateKey, payload []byte) (*Envelope, error) {
+	env, err := NewEnvelope(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	env.Algorithm = Algorithm
+	env.PublicKey = base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+	now := time.Now().UTC()
+	env.SignedAt = &now
+	env.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, env.message(env.Payload)))
+	return env, nil
+}
+
+// Verify checks the envelope's signature and timestamp, whichever are
+// present. When trusted is nil the embedded public key is used, which proves
+// the payload is unmodified but not who signed it; pass the signer's public
+// key to check both. Likewise a timestamp is only known to come from a real
+// TSA when tsaRoots holds the CA its certificate chains to.
+func Verify(env *Envelope, trusted ed25519.PublicKey, tsaRoots *x509.CertPool) error {
+	if env.Signature == "" && env.Timestamp == nil {
+		return fmt.Errorf("result is neither signed nor timestamped")
+	}
+	if env.Signature == "" {
+		if trusted != nil {
+			return fmt.Errorf("result is not signed")
+		}
+		return verifyTimestamp(env, tsaRoots)
+	}
+	if err := verifySignature(env, trusted); err != nil {
+		return err
+	}
+	if env.Timestamp != nil {
+		return verifyTimestamp(env, tsaRoots)
+	}
+	return nil
+}
+
+func verifySignature(env *Envelope, trusted ed25519.PublicKey) error {
+	if env.Algorithm != Algorithm {
+		return fmt.Errorf("unsupported signature algorithm: %q", env.Algorithm)
+	}
+
+	embedded, err := base64.StdEncoding.DecodeString(env.PublicKey)
+	if err != nil || len(embedded) != ed25519.PublicKeySize {
+		return fmt.Errorf("envelope has an invalid public key")
+	}
+	if trusted != nil && !bytes.Equal(trusted, embedded) {
+		return fmt.Errorf("signed with key %s, not the trusted key %s", Fingerprint(embedded), Fingerprint(trusted))
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(env.Signature)
+	if err != nil {
+		return fmt.Errorf("envelope has an invalid signature encoding")
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, env.Payload); err != nil {
+		return fmt.Errorf("payload is not valid JSON: %v", err)
+	}
+	if !ed25519.Verify(embedded, env.message(compact.Bytes()), sig) {
+		return fmt.Errorf("signature does not match; the payload or timestamp was modified")
+	}
+	return nil
+}
+
+// Fingerprint returns a short identifier for a public key.
+func Fingerprint(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// message is the byte string that is actually signed.
+func (e *Envelope) message(payload []byte) []byte {
+	var signedAt string
+	if e.SignedAt != nil {
+		signedAt = e.SignedAt.UTC().Format(time.RFC3339Nano)
+	}
+	msg := []byte(signedAt + "\n")
+	return append(msg, payload...)
+}
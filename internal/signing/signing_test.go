@@ -0,0 +1,60 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.key")
+
+	key, created, err := LoadOrCreateKey(path)
+	if err != nil || !created {
+		t.Fatalf("expected a new key, got created=%v err=%v", created, err)
+	}
+	again, created, err := LoadOrCreateKey(path)
+	if err != nil || created || !again.Equal(key) {
+		t.Fatalf("expected the stored key to be reloaded, got created=%v err=%v", created, err)
+	}
+	pub, err := LoadPublicKey(path + ".pub")
+	if err != nil {
+		t.Fatalf("failed to load public key: %v", err)
+	}
+
+	env, err := Sign(key, []byte(`{"domain": "example.com", "available": false}`))
+	if err != nil {
+		t.Fatalf("sign failed: %v", err)
+	}
+
+	// Re-indenting the envelope must not break the signature.
+	indented, _ := json.MarshalIndent(env, "", "  ")
+	var decoded Envelope
+	if err := json.Unmarshal(indented, &decoded); err != nil {
+		t.Fatalf("failed to decode envelope: %v", err)
+	}
+	if err := Verify(&decoded, pub, nil); err != nil {
+		t.Errorf("expected valid signature, got %v", err)
+	}
+
+	tampered := decoded
+	tampered.Payload = json.RawMessage(strings.Replace(string(decoded.Payload), "false", "true", 1))
+	if err := Verify(&tampered, nil, nil); err == nil {
+		t.Error("expected tampered payload to fail verification")
+	}
+
+	backdated := decoded
+	earlier := decoded.SignedAt.Add(-24 * time.Hour)
+	backdated.SignedAt = &earlier
+	if err := Verify(&backdated, nil, nil); err == nil {
+		t.Error("expected modified timestamp to fail verification")
+	}
+
+	other, _, _ := LoadOrCreateKey(filepath.Join(t.TempDir(), "other.key"))
+	if err := Verify(&decoded, other.Public().(ed25519.PublicKey), nil); err == nil {
+		t.Error("expected verification against a different trusted key to fail")
+	}
+}
@@ -0,0 +1,390 @@
+package signing
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+// Timestamp is an RFC 3161 token from a time-stamping authority over the
+// SHA-256 hash of an envelope's compacted payload.
+type Timestamp struct {
+	TSA           string    `json:"tsa"`
+	HashAlgorithm string    `json:"hash_algorithm"`
+	Time          time.Time `json:"time"`
+	// Nonce is the random number sent with the request, in decimal; the
+	// token must echo it.
+	Nonce string `json:"nonce,omitempty"`
+	Token string `json:"token"`
+}
+
+// TSAClient requests RFC 3161 timestamps.
+type TSAClient struct {
+	url        string
+	httpClient *http.Client
+}
+
+var (
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+
+	oidTSTInfo       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidRSAPSS        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}
+)
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	Nonce          *big.Int `asn1:"optional"`
+	CertReq        bool     `asn1:"optional"`
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString asn1.RawValue  `asn1:"optional"`
+	FailInfo     asn1.BitString `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status pkiStatusInfo
+	Token  asn1.RawValue `asn1:"optional"`
+}
+
+// The token is a CMS SignedData (RFC 5652) whose encapsulated content is
+// a TSTInfo, signed by the TSA over the signed attributes, which hold the
+// digest of the TSTInfo.
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo encapContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type signerInfo struct {
+	Version int
+	// SID is an IssuerAndSerialNumber, or a [0] SubjectKeyIdentifier.
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type issuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+type encapContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,optional,tag:0"`
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+	Accuracy       accuracy  `asn1:"optional"`
+	Ordering       bool      `asn1:"optional"`
+	Nonce          *big.Int  `asn1:"optional"`
+}
+
+type accuracy struct {
+	Seconds int `asn1:"optional"`
+	Millis  int `asn1:"optional,tag:0"`
+	Micros  int `asn1:"optional,tag:1"`
+}
+
+func NewTSAClient(url string) *TSAClient {
+	return &TSAClient{
+		url: url,
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+// Stamp obtains a timestamp token for the envelope's payload and attaches it.
+func (c *TSAClient) Stamp(env *Envelope) error {
+	digest, err := payloadDigest(env.Payload)
+	if err != nil {
+		return err
+	}
+
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	req, err := asn1.Marshal(timeStampReq{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+			HashedMessage: digest,
+		},
+		Nonce:   nonce,
+		CertReq: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build timestamp request: %v", err)
+	}
+
+	resp, err := c.httpClient.Post(c.url, "application/timestamp-query", bytes.NewReader(req))
+	if err != nil {
+		return fmt.Errorf("timestamp request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("TSA returned HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read timestamp response: %v", err)
+	}
+
+	var tsResp timeStampResp
+	if _, err := asn1.Unmarshal(body, &tsResp); err != nil {
+		return fmt.Errorf("failed to parse timestamp response: %v", err)
+	}
+	// 0 = granted, 1 = granted with modifications.
+	if tsResp.Status.Status > 1 || len(tsResp.Token.FullBytes) == 0 {
+		return fmt.Errorf("TSA rejected the request (status %d)", tsResp.Status.Status)
+	}
+
+	info, sd, err := parseToken(tsResp.Token.FullBytes)
+	if err != nil {
+		return err
+	}
+	if _, err := checkSignature(sd); err != nil {
+		return err
+	}
+	if !bytes.Equal(info.MessageImprint.HashedMessage, digest) {
+		return fmt.Errorf("TSA token does not cover the requested hash")
+	}
+	if info.Nonce == nil || info.Nonce.Cmp(nonce) != 0 {
+		return fmt.Errorf("TSA token does not carry the request nonce; it may be replayed")
+	}
+
+	env.Timestamp = &Timestamp{
+		TSA:           c.url,
+		HashAlgorithm: "sha256",
+		Time:          info.GenTime.UTC(),
+		Nonce:         nonce.String(),
+		Token:         base64.StdEncoding.EncodeToString(tsResp.Token.FullBytes),
+	}
+	return nil
+}
+
+// verifyTimestamp checks that the token covers the payload, carries the
+// recorded time and nonce and is signed by the certificate it embeds.
+// With roots that certificate must also chain to one of them and be issued
+// for time stamping; without, anyone could have made the token.
+func verifyTimestamp(env *Envelope, roots *x509.CertPool) error {
+	ts := env.Timestamp
+	token, err := base64.StdEncoding.DecodeString(ts.Token)
+	if err != nil {
+		return fmt.Errorf("timestamp token has an invalid encoding")
+	}
+	info, sd, err := parseToken(token)
+	if err != nil {
+		return err
+	}
+	cert, err := checkSignature(sd)
+	if err != nil {
+		return err
+	}
+	if roots != nil {
+		intermediates := x509.NewCertPool()
+		if certs, err := x509.ParseCertificates(sd.Certificates.Bytes); err == nil {
+			for _, c := range certs {
+				intermediates.AddCert(c)
+			}
+		}
+		_, err := cert.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   info.GenTime,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+		})
+		if err != nil {
+			return fmt.Errorf("TSA certificate %q is not trusted: %v", cert.Subject.CommonName, err)
+		}
+	}
+
+	digest, err := payloadDigest(env.Payload)
+	if err != nil {
+		return err
+	}
+	if !info.MessageImprint.HashAlgorithm.Algorithm.Equal(oidSHA256) || !bytes.Equal(info.MessageImprint.HashedMessage, digest) {
+		return fmt.Errorf("timestamp token does not match the payload; the payload was modified")
+	}
+	if !info.GenTime.Equal(ts.Time) {
+		return fmt.Errorf("timestamp time %s does not match the token (%s)", ts.Time.Format(time.RFC3339), info.GenTime.UTC().Format(time.RFC3339))
+	}
+	// Envelopes stamped before nonces were recorded have none to compare.
+	if ts.Nonce != "" && (info.Nonce == nil || info.Nonce.String() != ts.Nonce) {
+		return fmt.Errorf("timestamp nonce %s does not match the token", ts.Nonce)
+	}
+	return nil
+}
+
+func parseToken(der []byte) (*tstInfo, *signedData, error) {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(der, &ci); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse timestamp token: %v", err)
+	}
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse timestamp token: %v", err)
+	}
+	var info tstInfo
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent, &info); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse timestamp token info: %v", err)
+	}
+	return &info, &sd, nil
+}
+
+// checkSignature verifies the TSA's signature of a token with the signer
+// certificate embedded in it, which it returns.
+func checkSignature(sd *signedData) (*x509.Certificate, error) {
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) {
+		return nil, fmt.Errorf("timestamp token does not hold a TSTInfo")
+	}
+	if len(sd.SignerInfos) != 1 {
+		return nil, fmt.Errorf("timestamp token has %d signers, want 1", len(sd.SignerInfos))
+	}
+	si := sd.SignerInfos[0]
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the TSA certificates: %v", err)
+	}
+	cert := signerCert(si.SID, certs)
+	if cert == nil {
+		return nil, fmt.Errorf("timestamp token does not include the TSA certificate (request it with certReq)")
+	}
+
+	var hash crypto.Hash
+	switch alg := si.DigestAlgorithm.Algorithm; {
+	case alg.Equal(oidSHA256):
+		hash = crypto.SHA256
+	case alg.Equal(oidSHA384):
+		hash = crypto.SHA384
+	case alg.Equal(oidSHA512):
+		hash = crypto.SHA512
+	default:
+		return nil, fmt.Errorf("unsupported timestamp digest algorithm %v", alg)
+	}
+
+	// RFC 3161 tokens always sign attributes; the messageDigest attribute
+	// binds the signature to the TSTInfo.
+	if len(si.SignedAttrs.Bytes) == 0 {
+		return nil, fmt.Errorf("timestamp token has no signed attributes")
+	}
+	var messageDigest []byte
+	for rest := si.SignedAttrs.Bytes; len(rest) > 0; {
+		var attr attribute
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &attr); err != nil {
+			return nil, fmt.Errorf("failed to parse the signed attributes: %v", err)
+		}
+		if attr.Type.Equal(oidMessageDigest) {
+			if _, err := asn1.Unmarshal(attr.Values.Bytes, &messageDigest); err != nil {
+				return nil, fmt.Errorf("failed to parse the message digest: %v", err)
+			}
+		}
+	}
+	h := hash.New()
+	h.Write(sd.EncapContentInfo.EContent)
+	if messageDigest == nil || !bytes.Equal(messageDigest, h.Sum(nil)) {
+		return nil, fmt.Errorf("timestamp token signature does not cover its TSTInfo")
+	}
+
+	// The signature is over the DER of the attributes as a SET, not with
+	// their [0] tag.
+	signed := append([]byte{}, si.SignedAttrs.FullBytes...)
+	signed[0] = 0x31
+	if err := cert.CheckSignature(signatureAlgorithm(hash, cert.PublicKeyAlgorithm, si.SignatureAlgorithm.Algorithm), signed, si.Signature); err != nil {
+		return nil, fmt.Errorf("timestamp token signature is invalid: %v", err)
+	}
+	return cert, nil
+}
+
+// signerCert finds the certificate sid names among certs.
+func signerCert(sid asn1.RawValue, certs []*x509.Certificate) *x509.Certificate {
+	if sid.Class == asn1.ClassContextSpecific && sid.Tag == 0 {
+		for _, c := range certs {
+			if bytes.Equal(c.SubjectKeyId, sid.Bytes) {
+				return c
+			}
+		}
+		return nil
+	}
+	var ias issuerAndSerial
+	if _, err := asn1.Unmarshal(sid.FullBytes, &ias); err != nil {
+		return nil
+	}
+	for _, c := range certs {
+		if bytes.Equal(c.RawIssuer, ias.Issuer.FullBytes) && c.SerialNumber.Cmp(ias.Serial) == 0 {
+			return c
+		}
+	}
+	return nil
+}
+
+// signatureAlgorithm maps a CMS digest and the signer's key to the x509
+// algorithm checking the signature.
+func signatureAlgorithm(hash crypto.Hash, key x509.PublicKeyAlgorithm, sigAlg asn1.ObjectIdentifier) x509.SignatureAlgorithm {
+	pss := sigAlg.Equal(oidRSAPSS)
+	switch {
+	case key == x509.RSA && pss:
+		return map[crypto.Hash]x509.SignatureAlgorithm{crypto.SHA256: x509.SHA256WithRSAPSS, crypto.SHA384: x509.SHA384WithRSAPSS, crypto.SHA512: x509.SHA512WithRSAPSS}[hash]
+	case key == x509.RSA:
+		return map[crypto.Hash]x509.SignatureAlgorithm{crypto.SHA256: x509.SHA256WithRSA, crypto.SHA384: x509.SHA384WithRSA, crypto.SHA512: x509.SHA512WithRSA}[hash]
+	case key == x509.ECDSA:
+		return map[crypto.Hash]x509.SignatureAlgorithm{crypto.SHA256: x509.ECDSAWithSHA256, crypto.SHA384: x509.ECDSAWithSHA384, crypto.SHA512: x509.ECDSAWithSHA512}[hash]
+	case key == x509.Ed25519:
+		return x509.PureEd25519
+	}
+	return x509.UnknownSignatureAlgorithm
+}
+
+func payloadDigest(payload json.RawMessage) ([]byte, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, payload); err != nil {
+		return nil, fmt.Errorf("payload is not valid JSON: %v", err)
+	}
+	sum := sha256.Sum256(compact.Bytes())
+	return sum[:], nil
+}
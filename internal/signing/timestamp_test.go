@@ -0,0 +1,198 @@
+package signing
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testTSA is a time-stamping authority with its own CA.
+type testTSA struct {
+	key   *ecdsa.PrivateKey
+	cert  *x509.Certificate
+	roots *x509.CertPool
+}
+
+func newTestTSA(t *testing.T) *testTSA {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test TSA CA"},
+		NotBefore:             time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              time.Date(2036, 1, 1, 0, 0, 0, 0, time.UTC),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "Test TSA"},
+		NotBefore:    caTmpl.NotBefore,
+		NotAfter:     caTmpl.NotAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create TSA certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	return &testTSA{key: key, cert: cert, roots: roots}
+}
+
+// server answers timestamp requests with a token carrying the requested
+// imprint, a fixed time and the request nonce, unless dropNonce is set.
+// The token is signed with signer, which is the TSA's own key unless a
+// test forges it.
+func (a *testTSA) server(t *testing.T, genTime time.Time, dropNonce bool, signer *ecdsa.PrivateKey) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req timeStampReq
+		if _, err := asn1.Unmarshal(body, &req); err != nil {
+			t.Errorf("bad timestamp request: %v", err)
+			return
+		}
+		nonce := req.Nonce
+		if dropNonce {
+			nonce = nil
+		}
+
+		info := mustMarshal(t, tstInfo{
+			Version:        1,
+			Policy:         asn1.ObjectIdentifier{1, 2, 3},
+			MessageImprint: req.MessageImprint,
+			SerialNumber:   big.NewInt(42),
+			GenTime:        genTime,
+			Accuracy:       accuracy{Seconds: 1},
+			Nonce:          nonce,
+		})
+
+		digest := sha256.Sum256(info)
+		attrs := append(
+			mustMarshal(t, attribute{Type: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}, Values: set(mustMarshal(t, oidTSTInfo))}),
+			mustMarshal(t, attribute{Type: oidMessageDigest, Values: set(mustMarshal(t, digest[:]))})...)
+		signed := sha256.Sum256(mustMarshal(t, set(attrs)))
+		sig, err := ecdsa.SignASN1(rand.Reader, signer, signed[:])
+		if err != nil {
+			t.Errorf("failed to sign token: %v", err)
+			return
+		}
+
+		sd := mustMarshal(t, signedData{
+			Version:          3,
+			DigestAlgorithms: asn1.RawValue{FullBytes: []byte{0x31, 0x00}},
+			EncapContentInfo: encapContentInfo{EContentType: oidTSTInfo, EContent: info},
+			Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: a.cert.Raw},
+			SignerInfos: []signerInfo{{
+				Version:            1,
+				SID:                asn1.RawValue{FullBytes: mustMarshal(t, issuerAndSerial{Issuer: asn1.RawValue{FullBytes: a.cert.RawIssuer}, Serial: a.cert.SerialNumber})},
+				DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+				SignedAttrs:        asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: attrs},
+				SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}},
+				Signature:          sig,
+			}},
+		})
+		resp, _ := asn1.Marshal(timeStampResp{
+			Status: pkiStatusInfo{Status: 0},
+			Token: asn1.RawValue{FullBytes: mustMarshal(t, contentInfo{
+				ContentType: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2},
+				Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd},
+			})},
+		})
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		w.Write(resp)
+	}))
+}
+
+func set(content []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: content}
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	der, err := asn1.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	return der
+}
+
+func TestTSAClient_Stamp(t *testing.T) {
+	genTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tsa := newTestTSA(t)
+	srv := tsa.server(t, genTime, false, tsa.key)
+	defer srv.Close()
+
+	env, err := NewEnvelope([]byte(`{"domain": "example.com"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := NewTSAClient(srv.URL).Stamp(env); err != nil {
+		t.Fatalf("stamp failed: %v", err)
+	}
+	if env.Timestamp == nil || !env.Timestamp.Time.Equal(genTime) {
+		t.Fatalf("expected timestamp at %v, got %+v", genTime, env.Timestamp)
+	}
+
+	if err := Verify(env, nil, tsa.roots); err != nil {
+		t.Errorf("expected timestamp to verify, got %v", err)
+	}
+	if err := Verify(env, nil, nil); err != nil {
+		t.Errorf("expected timestamp signature to verify without roots, got %v", err)
+	}
+	if err := Verify(env, nil, newTestTSA(t).roots); err == nil || !strings.Contains(err.Error(), "not trusted") {
+		t.Errorf("expected a TSA certificate from another CA to be rejected, got %v", err)
+	}
+
+	tampered := *env
+	tampered.Payload = []byte(strings.Replace(string(env.Payload), "example", "exemple", 1))
+	if err := Verify(&tampered, nil, tsa.roots); err == nil {
+		t.Error("expected modified payload to fail timestamp verification")
+	}
+
+	if env.Timestamp.Nonce == "" {
+		t.Fatal("expected the request nonce to be recorded")
+	}
+	renonced := *env
+	ts := *env.Timestamp
+	ts.Nonce = "1"
+	renonced.Timestamp = &ts
+	if err := Verify(&renonced, nil, tsa.roots); err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Errorf("expected a nonce mismatch, got %v", err)
+	}
+
+	replayed := tsa.server(t, genTime, true, tsa.key)
+	defer replayed.Close()
+	env, _ = NewEnvelope([]byte(`{"domain": "example.com"}`))
+	if err := NewTSAClient(replayed.URL).Stamp(env); err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Errorf("expected a token without the nonce to be rejected, got %v", err)
+	}
+
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	forged := tsa.server(t, genTime, false, other)
+	defer forged.Close()
+	env, _ = NewEnvelope([]byte(`{"domain": "example.com"}`))
+	if err := NewTSAClient(forged.URL).Stamp(env); err == nil || !strings.Contains(err.Error(), "signature is invalid") {
+		t.Errorf("expected a token signed with another key to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+// Package store keeps analysis history, watchlists and portfolios on disk,
+// isolated per tenant: each tenant's data lives in its own directory and
+// is only reachable through that tenant's Namespace.
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"d3-domain-tool/internal/analyzer"
+)
+
+// ErrNotFound is returned for a portfolio that does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Store is a directory of tenant namespaces.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// Open opens the store in dir, creating it if needed.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Namespace is one tenant's view of the store.
+type Namespace struct {
+	store  *Store
+	tenant string
+	dir    string
+}
+
+// Namespace returns the data of tenant.
+func (s *Store) Namespace(tenant string) (*Namespace, error) {
+	if !safeName(tenant) {
+		return nil, fmt.Errorf("invalid tenant name %q", tenant)
+	}
+	return &Namespace{store: s, tenant: tenant, dir: filepath.Join(s.dir, tenant)}, nil
+}
+
+// Tenant is the name of the namespace's tenant.
+func (n *Namespace) Tenant() string {
+	return n.tenant
+}
+
+// Record appends an analysis result to the domain's history.
+func (n *Namespace) Record(result *analyzer.Result) error {
+	if !safeName(result.Domain) {
+		return fmt.Errorf("invalid domain %q", result.Domain)
+	}
+	line, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	n.store.mu.Lock()
+	defer n.store.mu.Unlock()
+	dir := filepath.Join(n.dir, "history")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, result.Domain+".jsonl"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// History returns the recorded results of domain, oldest first.
+func (n *Namespace) History(domain string) ([]*analyzer.Result, error) {
+	if !safeName(domain) {
+		return nil, fmt.Errorf("invalid domain %q", domain)
+	}
+	n.store.mu.Lock()
+	defer n.store.mu.Unlock()
+	f, err := os.Open(filepath.Join(n.dir, "history", domain+".jsonl"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var results []*analyzer.Result
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var r analyzer.Result
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("history of %s: %v", domain, err)
+		}
+		results = append(results, &r)
+	}
+	return results, scanner.Err()
+}
+
+// Watchlist returns the tenant's watched domains.
+func (n *Namespace) Watchlist() ([]string, error) {
+	var domains []string
+	err := n.read("watchlist.json", &domains)
+	if errors.Is(err, ErrNotFound) {
+		return []string{}, nil
+	}
+	return domains, err
+}
+
+// SetWatchlist replaces the tenant's watched domains.
+func (n *Namespace) SetWatchlist(domains []string) error {
+	return n.write("watchlist.json", normalize(domains))
+}
+
+// Portfolios returns the names of the tenant's portfolios, sorted.
+func (n *Namespace) Portfolios() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(n.dir, "portfolios"))
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, e := range entries {
+		if name := strings.TrimSuffix(e.Name(), ".json"); name != e.Name() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Portfolio returns the domains of the named portfolio.
+func (n *Namespace) Portfolio(name string) ([]string, error) {
+	if !safeName(name) {
+		return nil, fmt.Errorf("invalid portfolio name %q", name)
+	}
+	var domains []string
+	err := n.read(filepath.Join("portfolios", name+".json"), &domains)
+	return domains, err
+}
+
+// SavePortfolio creates or replaces the named portfolio.
+func (n *Namespace) SavePortfolio(name string, domains []string) error {
+	if !safeName(name) {
+		return fmt.Errorf("invalid portfolio name %q", name)
+	}
+	return n.write(filepath.Join("portfolios", name+".json"), normalize(domains))
+}
+
+// DeletePortfolio removes the named portfolio.
+func (n *Namespace) DeletePortfolio(name string) error {
+	if !safeName(name) {
+		return fmt.Errorf("invalid portfolio name %q", name)
+	}
+	n.store.mu.Lock()
+	defer n.store.mu.Unlock()
+	err := os.Remove(filepath.Join(n.dir, "portfolios", name+".json"))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (n *Namespace) read(name string, v interface{}) error {
+	n.store.mu.Lock()
+	defer n.store.mu.Unlock()
+	data, err := os.ReadFile(filepath.Join(n.dir, name))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// write replaces a file atomically, so readers never see half of it.
+func (n *Namespace) write(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	n.store.mu.Lock()
+	defer n.store.mu.Unlock()
+	path := filepath.Join(n.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// safeName reports whether s can be used as a file name inside a
+// namespace without escaping it.
+func safeName(s string) bool {
+	return s != "" && !strings.HasPrefix(s, ".") && !strings.ContainsAny(s, `/\`) && !strings.ContainsRune(s, 0)
+}
+
+// normalize lowercases, trims and dedupes domains, keeping their order.
+func normalize(domains []string) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d != "" && !seen[d] {
+			seen[d] = true
+			out = append(out, d)
+		}
+	}
+	return out
+}
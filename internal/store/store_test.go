@@ -0,0 +1,65 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"d3-domain-tool/internal/analyzer"
+)
+
+func TestNamespaces(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	acme, _ := s.Namespace("acme")
+	globex, _ := s.Namespace("globex")
+
+	for _, status := range []string{analyzer.StatusComplete, analyzer.StatusDegraded} {
+		if err := acme.Record(&analyzer.Result{Domain: "acme.com", Status: status}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history, err := acme.History("acme.com")
+	if err != nil || len(history) != 2 || history[1].Status != analyzer.StatusDegraded {
+		t.Errorf("unexpected history %+v, %v", history, err)
+	}
+	if history, _ := globex.History("acme.com"); len(history) != 0 {
+		t.Errorf("history leaked across tenants: %+v", history)
+	}
+
+	if err := acme.SetWatchlist([]string{"Acme.com", "acme.io", "acme.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := acme.Watchlist(); len(list) != 2 || list[0] != "acme.com" {
+		t.Errorf("unexpected watchlist %v", list)
+	}
+	if list, _ := globex.Watchlist(); len(list) != 0 {
+		t.Errorf("watchlist leaked across tenants: %v", list)
+	}
+
+	if err := acme.SavePortfolio("brands", []string{"acme.com", "acme.eth"}); err != nil {
+		t.Fatal(err)
+	}
+	if names, _ := acme.Portfolios(); len(names) != 1 || names[0] != "brands" {
+		t.Errorf("unexpected portfolios %v", names)
+	}
+	if _, err := globex.Portfolio("brands"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("portfolio leaked across tenants: %v", err)
+	}
+	if err := acme.DeletePortfolio("brands"); err != nil {
+		t.Fatal(err)
+	}
+	if err := acme.DeletePortfolio("brands"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	for _, bad := range []string{"", "..", "../globex", "a/b"} {
+		if _, err := s.Namespace(bad); err == nil {
+			t.Errorf("tenant %q: expected an error", bad)
+		}
+		if _, err := acme.Portfolio(bad); err == nil {
+			t.Errorf("portfolio %q: expected an error", bad)
+		}
+	}
+}
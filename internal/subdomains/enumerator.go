@@ -0,0 +1,298 @@
+package subdomains
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultWordlist is used when no wordlist file is given.
+var DefaultWordlist = []string{
+	"www", "mail", "webmail", "smtp", "imap", "pop", "mx", "ns1", "ns2",
+	"api", "app", "apps", "dev", "staging", "stage", "test", "qa", "uat",
+	"admin", "portal", "dashboard", "login", "sso", "auth", "id", "vpn",
+	"remote", "git", "gitlab", "jenkins", "ci", "jira", "wiki", "docs",
+	"blog", "shop", "store", "cdn", "static", "assets", "img", "media",
+	"beta", "demo", "status", "support", "help", "m", "mobile", "old",
+	"new", "legacy", "internal", "intranet", "files", "ftp", "db",
+	"grafana", "kibana", "monitor", "autodiscover", "cpanel", "owa",
+}
+
+// DefaultCTLogURL is the crt.sh search endpoint used for certificate
+// transparency lookups.
+const DefaultCTLogURL = "https://crt.sh/"
+
+// Enumerator discovers resolving subdomains of a domain by brute-forcing a
+// wordlist and merging names seen in certificate transparency logs.
+type Enumerator struct {
+	lookup     func(host string) ([]string, error)
+	cname      func(host string) (string, error)
+	httpClient *http.Client
+	ctLogURL   string
+	workers    int
+}
+
+type Result struct {
+	Domain    string      `json:"domain"`
+	Wildcard  bool        `json:"wildcard"`
+	Found     []Subdomain `json:"found"`
+	Tried     int         `json:"tried"`
+	CTNames   int         `json:"ct_names"`
+	CheckedAt time.Time   `json:"checked_at"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// Subdomain is one resolving name.
+type Subdomain struct {
+	Name   string   `json:"name"`
+	IPs    []string `json:"ips"`
+	Source string   `json:"source"`
+}
+
+// Options controls an enumeration run.
+type Options struct {
+	// Wordlist replaces DefaultWordlist when non-empty.
+	Wordlist []string
+	// Rate is the maximum number of DNS lookups per second (0 = unlimited).
+	Rate int
+	// SkipCT disables the certificate transparency lookup.
+	SkipCT bool
+}
+
+func NewEnumerator() *Enumerator {
+	return &Enumerator{
+		lookup: net.LookupHost,
+		cname:  net.LookupCNAME,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		ctLogURL: DefaultCTLogURL,
+		workers:  10,
+	}
+}
+
+// LoadWordlist reads one label per line, ignoring blanks and # comments.
+func LoadWordlist(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wordlist: %v", err)
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		words = append(words, strings.ToLower(word))
+	}
+	return words, scanner.Err()
+}
+
+func (e *Enumerator) Enumerate(domain string, opts Options) (*Result, error) {
+	result := &Result{
+		Domain:    domain,
+		CheckedAt: time.Now(),
+	}
+
+	// A wildcard record makes every name resolve; remember its answers so
+	// matching ones can be discarded.
+	wild := e.wildcard(domain)
+	result.Wildcard = len(wild.ips) > 0
+
+	candidates := make(map[string]string)
+	words := opts.Wordlist
+	if len(words) == 0 {
+		words = DefaultWordlist
+	}
+	for _, word := range words {
+		candidates[word+"."+domain] = "wordlist"
+	}
+
+	if !opts.SkipCT {
+		names, err := e.ctNames(domain)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		result.CTNames = len(names)
+		for _, name := range names {
+			if src, ok := candidates[name]; ok && src == "wordlist" {
+				candidates[name] = "wordlist+ct"
+			} else {
+				candidates[name] = "ct"
+			}
+		}
+	}
+
+	names := make([]string, 0, len(candidates))
+	for name := range candidates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result.Tried = len(names)
+
+	found := e.resolveAll(names, opts.Rate)
+	for _, name := range names {
+		ips, ok := found[name]
+		if !ok || e.isWildcard(name, ips, wild) {
+			continue
+		}
+		result.Found = append(result.Found, Subdomain{Name: name, IPs: ips, Source: candidates[name]})
+	}
+
+	return result, nil
+}
+
+// resolveAll looks names up with a bounded worker pool, pacing lookups to
+// rate per second when rate > 0.
+func (e *Enumerator) resolveAll(names []string, rate int) map[string][]string {
+	var (
+		mu    sync.Mutex
+		found = make(map[string][]string)
+		wg    sync.WaitGroup
+		jobs  = make(chan string)
+	)
+
+	var tick <-chan time.Time
+	if rate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for i := 0; i < e.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				ips, err := e.lookup(name)
+				if err != nil || len(ips) == 0 {
+					continue
+				}
+				sort.Strings(ips)
+				mu.Lock()
+				found[name] = ips
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, name := range names {
+		if tick != nil {
+			<-tick
+		}
+		jobs <- name
+	}
+	close(jobs)
+	wg.Wait()
+
+	return found
+}
+
+// wildcardProbes is how many random names are looked up to learn a
+// wildcard's answers; round-robin and geo-balanced wildcards give each a
+// different subset of their addresses.
+const wildcardProbes = 3
+
+// wildcardAnswers are the addresses and CNAME targets random names under a
+// domain resolved to.
+type wildcardAnswers struct {
+	ips    map[string]bool
+	cnames map[string]bool
+}
+
+func (e *Enumerator) wildcard(domain string) wildcardAnswers {
+	wild := wildcardAnswers{ips: make(map[string]bool), cnames: make(map[string]bool)}
+	for i := 0; i < wildcardProbes; i++ {
+		buf := make([]byte, 8)
+		rand.Read(buf)
+		probe := "d3-" + hex.EncodeToString(buf) + "." + domain
+		ips, err := e.lookup(probe)
+		if err != nil || len(ips) == 0 {
+			continue
+		}
+		for _, ip := range ips {
+			wild.ips[ip] = true
+		}
+		if target, ok := e.cnameTarget(probe); ok {
+			wild.cnames[target] = true
+		}
+	}
+	return wild
+}
+
+// isWildcard reports whether a name's answer could have come from the
+// wildcard: it shares an address with a probe, or is an alias of the same
+// target.
+func (e *Enumerator) isWildcard(name string, ips []string, wild wildcardAnswers) bool {
+	for _, ip := range ips {
+		if wild.ips[ip] {
+			return true
+		}
+	}
+	if len(wild.cnames) > 0 {
+		if target, ok := e.cnameTarget(name); ok && wild.cnames[target] {
+			return true
+		}
+	}
+	return false
+}
+
+// cnameTarget returns the canonical name host is an alias of, if any.
+func (e *Enumerator) cnameTarget(host string) (string, bool) {
+	target, err := e.cname(host)
+	target = strings.ToLower(strings.TrimSuffix(target, "."))
+	if err != nil || target == "" || target == strings.ToLower(host) {
+		return "", false
+	}
+	return target, true
+}
+
+// ctNames queries crt.sh for certificates issued under the domain and
+// returns the distinct, non-wildcard subdomain names they cover.
+func (e *Enumerator) ctNames(domain string) ([]string, error) {
+	endpoint := e.ctLogURL + "?q=" + url.QueryEscape("%."+domain) + "&output=json"
+	resp, err := e.httpClient.Get(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("certificate transparency lookup failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("certificate transparency lookup returned HTTP %d", resp.StatusCode)
+	}
+
+	var entries []struct {
+		NameValue string `json:"name_value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to parse certificate transparency response: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, entry := range entries {
+		for _, name := range strings.Split(entry.NameValue, "\n") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if strings.HasPrefix(name, "*.") || name == domain || !strings.HasSuffix(name, "."+domain) || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,102 @@
+package subdomains
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEnumerate(t *testing.T) {
+	ct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name_value":"vpn.example.com\n*.example.com"},{"name_value":"legacy-api.example.com"}]`)
+	}))
+	defer ct.Close()
+
+	records := map[string][]string{
+		"www.example.com":        {"192.0.2.1"},
+		"vpn.example.com":        {"192.0.2.2"},
+		"legacy-api.example.com": {"192.0.2.3"},
+	}
+
+	e := NewEnumerator()
+	e.ctLogURL = ct.URL + "/"
+	e.lookup = func(host string) ([]string, error) {
+		if ips, ok := records[host]; ok {
+			return ips, nil
+		}
+		return nil, fmt.Errorf("no such host")
+	}
+	e.cname = noCNAME
+
+	result, err := e.Enumerate("example.com", Options{Wordlist: []string{"www", "vpn", "mail"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Wildcard {
+		t.Error("did not expect wildcard")
+	}
+	if len(result.Found) != 3 {
+		t.Fatalf("expected 3 subdomains, got %+v", result.Found)
+	}
+	sources := map[string]string{}
+	for _, s := range result.Found {
+		sources[s.Name] = s.Source
+	}
+	if sources["vpn.example.com"] != "wordlist+ct" || sources["legacy-api.example.com"] != "ct" || sources["www.example.com"] != "wordlist" {
+		t.Errorf("unexpected sources: %v", sources)
+	}
+}
+
+func noCNAME(host string) (string, error) {
+	return host + ".", nil
+}
+
+func TestEnumerateWildcard(t *testing.T) {
+	// The wildcard rotates through three addresses, two per answer.
+	pool := []string{"192.0.2.97", "192.0.2.98", "192.0.2.99"}
+	var answers int
+	e := NewEnumerator()
+	e.lookup = func(host string) ([]string, error) {
+		if host == "real.example.com" {
+			return []string{"192.0.2.50"}, nil
+		}
+		if strings.HasSuffix(host, ".example.com") {
+			answers++
+			return []string{pool[answers%3], pool[(answers+1)%3]}, nil
+		}
+		return nil, fmt.Errorf("no such host")
+	}
+	e.cname = noCNAME
+
+	result, _ := e.Enumerate("example.com", Options{Wordlist: []string{"real", "bogus", "other", "more"}, SkipCT: true})
+	if !result.Wildcard {
+		t.Error("expected wildcard to be detected")
+	}
+	if len(result.Found) != 1 || result.Found[0].Name != "real.example.com" {
+		t.Errorf("expected only real.example.com, got %+v", result.Found)
+	}
+}
+
+func TestEnumerateWildcardCNAME(t *testing.T) {
+	// Every name is an alias of a load balancer whose addresses change
+	// between lookups; real.example.com points elsewhere.
+	var answers int
+	e := NewEnumerator()
+	e.lookup = func(host string) ([]string, error) {
+		answers++
+		return []string{fmt.Sprintf("198.51.100.%d", answers)}, nil
+	}
+	e.cname = func(host string) (string, error) {
+		if host == "real.example.com" {
+			return "app.hosting.example.net.", nil
+		}
+		return "lb.hosting.example.net.", nil
+	}
+
+	result, _ := e.Enumerate("example.com", Options{Wordlist: []string{"real", "bogus"}, SkipCT: true})
+	if len(result.Found) != 1 || result.Found[0].Name != "real.example.com" {
+		t.Errorf("expected only real.example.com, got %+v", result.Found)
+	}
+}
@@ -0,0 +1,53 @@
+// Package suffix splits domain names at the zone a registry sells names
+// under: the TLD, or a second-level zone such as co.uk or com.br.
+package suffix
+
+import (
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Public returns the registry suffix of domain, e.g. "co.uk" for
+// www.example.co.uk and "com" for example.com. Suffixes run by companies
+// rather than registries, such as github.io, are skipped: a name under
+// them has no WHOIS record of its own.
+func Public(domain string) string {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	for {
+		s, icann := publicsuffix.PublicSuffix(domain)
+		if icann || !strings.Contains(s, ".") {
+			return s
+		}
+		domain = s[strings.Index(s, ".")+1:]
+	}
+}
+
+// Registrable returns the name a registrant holds, e.g. example.co.uk for
+// www.example.co.uk, or domain itself when it is a suffix.
+func Registrable(domain string) string {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	s := Public(domain)
+	rest := strings.TrimSuffix(domain, "."+s)
+	if rest == domain {
+		return domain
+	}
+	return rest[strings.LastIndex(rest, ".")+1:] + "." + s
+}
+
+// Label returns the registrant-chosen label of domain: "example" for
+// example.com, www.example.com and example.co.uk.
+func Label(domain string) string {
+	r := Registrable(domain)
+	if i := strings.Index(r, "."); i >= 0 {
+		return r[:i]
+	}
+	return r
+}
+
+// IsPublic reports whether name is a registry suffix itself, such as
+// co.uk, rather than a name registered under one.
+func IsPublic(name string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	return Public(name) == name
+}
@@ -0,0 +1,32 @@
+package suffix
+
+import "testing"
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		domain, public, registrable, label string
+	}{
+		{"example.com", "com", "example.com", "example"},
+		{"www.example.com", "com", "example.com", "example"},
+		{"example.co.uk", "co.uk", "example.co.uk", "example"},
+		{"shop.example.com.br", "com.br", "example.com.br", "example"},
+		{"example.uk", "uk", "example.uk", "example"},
+		{"foo.bar.de", "de", "bar.de", "bar"},
+		// Company-run suffixes fall back to the registry's.
+		{"someone.github.io", "io", "github.io", "github"},
+	}
+	for _, tt := range tests {
+		if got := Public(tt.domain); got != tt.public {
+			t.Errorf("Public(%q) = %q, want %q", tt.domain, got, tt.public)
+		}
+		if got := Registrable(tt.domain); got != tt.registrable {
+			t.Errorf("Registrable(%q) = %q, want %q", tt.domain, got, tt.registrable)
+		}
+		if got := Label(tt.domain); got != tt.label {
+			t.Errorf("Label(%q) = %q, want %q", tt.domain, got, tt.label)
+		}
+	}
+	if !IsPublic("co.uk") || IsPublic("example.co.uk") {
+		t.Error("IsPublic misjudged co.uk")
+	}
+}
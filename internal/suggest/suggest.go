@@ -0,0 +1,209 @@
+// Package suggest generates candidate domain names from a keyword and ranks
+// them by availability and estimated value.
+package suggest
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"d3-domain-tool/internal/analyzer"
+)
+
+// Candidate sources.
+const (
+	SourceExact   = "exact"
+	SourcePrefix  = "prefix"
+	SourceSuffix  = "suffix"
+	SourceSynonym = "synonym"
+)
+
+// DefaultTLDs are spun for the keyword itself. Prefix, suffix and synonym
+// combinations use the first two.
+var DefaultTLDs = []string{"com", "io", "net", "org", "co", "app", "dev", "eth"}
+
+// Prefixes and Suffixes are the affixes startups commonly add to a taken
+// name.
+var (
+	Prefixes = []string{"get", "try", "use", "go", "my", "the"}
+	Suffixes = []string{"hq", "app", "labs", "hub", "ly", "now"}
+)
+
+// synonyms is a small thesaurus of words that show up in domain names.
+var synonyms = map[string][]string{
+	"fast":    {"quick", "rapid", "swift"},
+	"quick":   {"fast", "rapid", "swift"},
+	"shop":    {"store", "market", "mart"},
+	"store":   {"shop", "market", "mart"},
+	"market":  {"shop", "store", "bazaar"},
+	"pay":     {"cash", "wallet", "coin"},
+	"money":   {"cash", "fund", "coin"},
+	"cloud":   {"sky", "nimbus", "stack"},
+	"data":    {"info", "stats", "metrics"},
+	"code":    {"dev", "build", "stack"},
+	"smart":   {"clever", "bright", "wise"},
+	"home":    {"house", "nest", "dwell"},
+	"health":  {"care", "well", "vital"},
+	"food":    {"eat", "meal", "kitchen"},
+	"travel":  {"trip", "journey", "voyage"},
+	"green":   {"eco", "leaf", "earth"},
+	"game":    {"play", "arcade", "quest"},
+	"social":  {"crowd", "circle", "tribe"},
+	"secure":  {"safe", "guard", "shield"},
+	"finance": {"capital", "fund", "wealth"},
+	"learn":   {"study", "school", "academy"},
+	"job":     {"work", "career", "hire"},
+	"photo":   {"pic", "snap", "lens"},
+	"music":   {"tune", "sound", "beat"},
+	"news":    {"daily", "times", "press"},
+}
+
+// Options controls which candidates Generate makes.
+type Options struct {
+	// TLDs replaces DefaultTLDs.
+	TLDs []string
+	// Limit caps the number of candidates; 0 means 30.
+	Limit int
+}
+
+// Candidate is a generated name and how it was derived.
+type Candidate struct {
+	Domain string `json:"domain"`
+	Source string `json:"source"`
+}
+
+// Suggestion is a ranked candidate.
+type Suggestion struct {
+	Rank           int              `json:"rank"`
+	Domain         string           `json:"domain"`
+	Source         string           `json:"source"`
+	Verdict        string           `json:"verdict"`
+	EstimatedValue int              `json:"estimated_value"`
+	Confidence     string           `json:"confidence,omitempty"`
+	Error          string           `json:"error,omitempty"`
+	Result         *analyzer.Result `json:"result,omitempty"`
+}
+
+// Keyword reduces input to the characters a label can hold: "Fast Pay"
+// becomes "fastpay".
+func Keyword(input string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(input) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+// Generate returns candidate names for keyword: the keyword under every
+// TLD, then prefix and suffix combinations and synonyms under the first
+// two TLDs. Duplicates are dropped and the order is stable.
+func Generate(keyword string, opts Options) []Candidate {
+	keyword = Keyword(keyword)
+	if keyword == "" {
+		return nil
+	}
+	tlds := opts.TLDs
+	if len(tlds) == 0 {
+		tlds = DefaultTLDs
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 30
+	}
+	combo := tlds
+	if len(combo) > 2 {
+		combo = combo[:2]
+	}
+
+	var candidates []Candidate
+	seen := map[string]bool{}
+	add := func(label, tld, source string) {
+		domain := label + "." + strings.TrimPrefix(strings.ToLower(tld), ".")
+		if len(candidates) >= limit || seen[domain] || len(label) > 63 {
+			return
+		}
+		seen[domain] = true
+		candidates = append(candidates, Candidate{Domain: domain, Source: source})
+	}
+
+	for _, tld := range tlds {
+		add(keyword, tld, SourceExact)
+	}
+	// Interleave the affixes so a low limit still gets a mix.
+	for i := 0; i < len(Prefixes) || i < len(Suffixes); i++ {
+		for _, tld := range combo {
+			if i < len(Prefixes) {
+				add(Prefixes[i]+keyword, tld, SourcePrefix)
+			}
+			if i < len(Suffixes) && !strings.HasSuffix(keyword, Suffixes[i]) {
+				add(keyword+Suffixes[i], tld, SourceSuffix)
+			}
+		}
+	}
+	for _, word := range synonyms[keyword] {
+		for _, tld := range combo {
+			add(word, tld, SourceSynonym)
+		}
+	}
+	return candidates
+}
+
+// Analyze runs analyze on every candidate, workers at a time, and ranks
+// the results.
+func Analyze(candidates []Candidate, workers int, analyze func(domain string) (*analyzer.Result, error)) []Suggestion {
+	suggestions := make([]Suggestion, len(candidates))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(candidates); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				s := Suggestion{Domain: candidates[i].Domain, Source: candidates[i].Source, Verdict: analyzer.VerdictUnknown}
+				result, err := analyze(s.Domain)
+				if err != nil {
+					s.Error = err.Error()
+				} else {
+					s.Result = result
+					s.Verdict = result.Verdict()
+					if result.ValuationData != nil {
+						s.EstimatedValue = result.ValuationData.EstimatedValue
+						s.Confidence = result.ValuationData.Confidence
+					}
+				}
+				suggestions[i] = s
+			}
+		}()
+	}
+	for i := range candidates {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return Rank(suggestions)
+}
+
+// verdictOrder puts names that can be registered first.
+var verdictOrder = map[string]int{
+	analyzer.VerdictAvailable: 0,
+	analyzer.VerdictUnknown:   1,
+	analyzer.VerdictTaken:     2,
+}
+
+// Rank orders suggestions available first, then unknown, then taken, and
+// by estimated value within each; ties keep the generated order.
+func Rank(suggestions []Suggestion) []Suggestion {
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		oi, oj := verdictOrder[suggestions[i].Verdict], verdictOrder[suggestions[j].Verdict]
+		if oi != oj {
+			return oi < oj
+		}
+		return suggestions[i].EstimatedValue > suggestions[j].EstimatedValue
+	})
+	for i := range suggestions {
+		suggestions[i].Rank = i + 1
+	}
+	return suggestions
+}
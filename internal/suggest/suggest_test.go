@@ -0,0 +1,75 @@
+package suggest
+
+import (
+	"fmt"
+	"testing"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/valuation"
+	"d3-domain-tool/internal/whois"
+)
+
+func TestGenerate(t *testing.T) {
+	candidates := Generate("Fast Pay", Options{TLDs: []string{"com", ".io", "net"}, Limit: 100})
+	seen := map[string]string{}
+	for _, c := range candidates {
+		if _, dup := seen[c.Domain]; dup {
+			t.Errorf("duplicate candidate %s", c.Domain)
+		}
+		seen[c.Domain] = c.Source
+	}
+	for domain, source := range map[string]string{
+		"fastpay.com":    SourceExact,
+		"fastpay.io":     SourceExact,
+		"fastpay.net":    SourceExact,
+		"getfastpay.com": SourcePrefix,
+		"fastpayhq.io":   SourceSuffix,
+	} {
+		if seen[domain] != source {
+			t.Errorf("%s: source %q, want %q", domain, seen[domain], source)
+		}
+	}
+	if _, ok := seen["getfastpay.net"]; ok {
+		t.Error("affixes were combined with more than the first two TLDs")
+	}
+
+	candidates = Generate("shop", Options{TLDs: []string{"com"}, Limit: 100})
+	if last := candidates[len(candidates)-1]; last.Source != SourceSynonym || last.Domain != "mart.com" {
+		t.Errorf("last candidate = %+v, want the synonym mart.com", last)
+	}
+	if got := Generate("shop", Options{Limit: 5}); len(got) != 5 {
+		t.Errorf("limit 5 gave %d candidates", len(got))
+	}
+	if Generate("!!!", Options{}) != nil {
+		t.Error("candidates for an empty keyword")
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	taken := map[string]bool{"acme.com": true}
+	values := map[string]int{"acme.com": 9000, "acme.io": 1200, "getacme.com": 800, "acmehq.com": 1500}
+	analyze := func(domain string) (*analyzer.Result, error) {
+		if domain == "acme.net" {
+			return nil, fmt.Errorf("lookup failed")
+		}
+		return &analyzer.Result{
+			Domain:        domain,
+			WhoisData:     &whois.Result{Available: !taken[domain]},
+			ValuationData: &valuation.Result{EstimatedValue: values[domain], Confidence: "medium"},
+		}, nil
+	}
+	candidates := []Candidate{
+		{"acme.com", SourceExact}, {"acme.io", SourceExact}, {"acme.net", SourceExact},
+		{"getacme.com", SourcePrefix}, {"acmehq.com", SourceSuffix},
+	}
+	got := Analyze(candidates, 3, analyze)
+	want := []string{"acmehq.com", "acme.io", "getacme.com", "acme.net", "acme.com"}
+	for i, s := range got {
+		if s.Domain != want[i] || s.Rank != i+1 {
+			t.Errorf("#%d = %s (rank %d), want %s", i+1, s.Domain, s.Rank, want[i])
+		}
+	}
+	if got[3].Error == "" || got[3].Verdict != analyzer.VerdictUnknown {
+		t.Errorf("failed analysis = %+v", got[3])
+	}
+}
@@ -0,0 +1,248 @@
+package udrp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"d3-domain-tool/internal/suffix"
+)
+
+// Source is a public dispute-decision database searched by keyword. URL
+// contains one %s which is replaced with the query-escaped search term.
+type Source struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// CasePattern matches the provider's case numbers in the results page.
+	CasePattern *regexp.Regexp `json:"-"`
+	// CaseURL, when set, links a case number (one %s) to its decision.
+	CaseURL string `json:"-"`
+}
+
+// DefaultSources are the two largest UDRP providers. Their search pages
+// are public HTML; override them with SetSources if they move.
+var DefaultSources = []Source{
+	{
+		Name:        "WIPO",
+		URL:         "https://www.wipo.int/amc/en/domains/search/legacy/result.jsp?domain=%s",
+		CasePattern: regexp.MustCompile(`\b(D|DCO|DTV|DAU|DNL)\d{4}-\d{4}\b`),
+		CaseURL:     "https://www.wipo.int/amc/en/domains/search/case.jsp?case_id=%s",
+	},
+	{
+		Name:        "Forum (NAF)",
+		URL:         "https://www.adrforum.com/domain-dispute/search-decisions?keyword=%s",
+		CasePattern: regexp.MustCompile(`\bFA\d{6,8}\b`),
+	},
+}
+
+// Checker searches dispute databases for a domain's second-level label, so
+// disputes over the same name under other TLDs are found too.
+type Checker struct {
+	sources []Source
+	client  *http.Client
+}
+
+type Result struct {
+	Label          string         `json:"label"`
+	DisputeHistory bool           `json:"dispute_history"`
+	Cases          []Case         `json:"cases,omitempty"`
+	Sources        []SourceStatus `json:"sources"`
+	CheckedAt      time.Time      `json:"checked_at"`
+	Error          string         `json:"error,omitempty"`
+}
+
+// Case is one dispute that mentions the domain or a similar name.
+type Case struct {
+	Provider string   `json:"provider"`
+	ID       string   `json:"id"`
+	Domains  []string `json:"domains,omitempty"`
+	// Exact is true when the disputed names include the domain itself.
+	Exact bool   `json:"exact"`
+	URL   string `json:"url,omitempty"`
+}
+
+// SourceStatus records whether a source could be searched.
+type SourceStatus struct {
+	Name  string `json:"name"`
+	Cases int    `json:"cases"`
+	Error string `json:"error,omitempty"`
+}
+
+func NewChecker() *Checker {
+	return &Checker{
+		sources: DefaultSources,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// SetSources replaces the default dispute databases.
+func (c *Checker) SetSources(sources []Source) {
+	if len(sources) > 0 {
+		c.sources = sources
+	}
+}
+
+// Sources returns the configured dispute databases.
+func (c *Checker) Sources() []Source {
+	return c.sources
+}
+
+// ParseSources parses "name=url-template" items separated by commas. Case
+// numbers of the WIPO and Forum formats are recognised on any source.
+func ParseSources(spec string) ([]Source, error) {
+	var sources []Source
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, template, ok := strings.Cut(item, "=")
+		if !ok || name == "" || !strings.Contains(template, "%s") {
+			return nil, fmt.Errorf("invalid UDRP source %q: expected name=url containing %%s", item)
+		}
+		sources = append(sources, Source{Name: name, URL: template, CasePattern: anyCase})
+	}
+	return sources, nil
+}
+
+var anyCase = regexp.MustCompile(`\b((D|DCO|DTV|DAU|DNL)\d{4}-\d{4}|FA\d{6,8})\b`)
+
+func (c *Checker) Check(domain string) (*Result, error) {
+	return c.CheckContext(context.Background(), domain)
+}
+
+// CheckContext is Check with the searches bounded by ctx.
+func (c *Checker) CheckContext(ctx context.Context, domain string) (*Result, error) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	result := &Result{
+		Label:     label(domain),
+		CheckedAt: time.Now(),
+	}
+
+	failed := 0
+	for _, src := range c.sources {
+		status := SourceStatus{Name: src.Name}
+		cases, err := c.search(ctx, src, result.Label, domain)
+		if err != nil {
+			status.Error = err.Error()
+			failed++
+		}
+		status.Cases = len(cases)
+		result.Cases = append(result.Cases, cases...)
+		result.Sources = append(result.Sources, status)
+	}
+
+	sort.SliceStable(result.Cases, func(i, j int) bool {
+		return result.Cases[i].Exact && !result.Cases[j].Exact
+	})
+	result.DisputeHistory = len(result.Cases) > 0
+	if failed > 0 && failed == len(c.sources) {
+		result.Error = "no dispute database could be searched"
+	}
+
+	return result, nil
+}
+
+func (c *Checker) search(ctx context.Context, src Source, term, domain string) ([]Case, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(src.URL, url.QueryEscape(term)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search URL: %v", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("search returned HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search results: %v", err)
+	}
+
+	return extractCases(src, stripTags(string(body)), term, domain), nil
+}
+
+// caseWindow is how much text after a case number is searched for the
+// disputed domain names.
+const caseWindow = 400
+
+var domainPattern = regexp.MustCompile(`(?i)\b[a-z0-9][a-z0-9-]*(\.[a-z0-9-]+)*\.[a-z]{2,}\b`)
+
+// extractCases finds case numbers in the results text and keeps those whose
+// neighbouring domain names contain the searched label.
+func extractCases(src Source, text, term, domain string) []Case {
+	pattern := src.CasePattern
+	if pattern == nil {
+		pattern = anyCase
+	}
+
+	var cases []Case
+	seen := make(map[string]bool)
+	locs := pattern.FindAllStringIndex(text, -1)
+	for i, loc := range locs {
+		id := text[loc[0]:loc[1]]
+		if seen[id] {
+			continue
+		}
+		end := loc[1] + caseWindow
+		if i+1 < len(locs) && locs[i+1][0] < end {
+			end = locs[i+1][0]
+		}
+		if end > len(text) {
+			end = len(text)
+		}
+
+		c := Case{Provider: src.Name, ID: id}
+		for _, name := range domainPattern.FindAllString(text[loc[1]:end], -1) {
+			name = strings.ToLower(name)
+			if !strings.Contains(label(name), term) || containsString(c.Domains, name) {
+				continue
+			}
+			c.Domains = append(c.Domains, name)
+			if name == domain || strings.HasSuffix(domain, "."+name) {
+				c.Exact = true
+			}
+		}
+		if len(c.Domains) == 0 {
+			continue
+		}
+		if src.CaseURL != "" {
+			c.URL = fmt.Sprintf(src.CaseURL, id)
+		}
+		seen[id] = true
+		cases = append(cases, c)
+	}
+	return cases
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+func stripTags(html string) string {
+	return tagPattern.ReplaceAllString(html, " ")
+}
+
+// label returns the registrant-chosen label of a domain: "example" for
+// example.com, www.example.com and example.co.uk.
+func label(domain string) string {
+	return suffix.Label(domain)
+}
+
+func containsString(list []string, v string) bool {
+	for _, existing := range list {
+		if existing == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package udrp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+const wipoResults = `<table>
+<tr><td><a href="case.jsp?case_id=D2019-1234">D2019-1234</a></td><td>Example Corp v. John Doe</td><td>example.com</td><td>Transfer</td></tr>
+<tr><td><a href="case.jsp?case_id=D2021-0042">D2021-0042</a></td><td>Example Corp v. Jane Roe</td><td>example-shop.net<br>myexample.org</td><td>Transfer</td></tr>
+<tr><td>D2022-0007</td><td>Other v. Someone</td><td>unrelated.com</td><td>Denied</td></tr>
+</table>`
+
+func TestCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != "example" {
+			t.Errorf("expected the label to be searched, got %q", r.URL.RawQuery)
+		}
+		if r.URL.Path == "/down" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(wipoResults))
+	}))
+	defer server.Close()
+
+	c := NewChecker()
+	c.SetSources([]Source{
+		{Name: "WIPO", URL: server.URL + "/?q=%s", CasePattern: regexp.MustCompile(`\bD\d{4}-\d{4}\b`)},
+		{Name: "Forum", URL: server.URL + "/down?q=%s"},
+	})
+
+	result, err := c.Check("www.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.DisputeHistory || len(result.Cases) != 2 {
+		t.Fatalf("expected 2 related cases, got %+v", result.Cases)
+	}
+	if result.Cases[0].ID != "D2019-1234" || !result.Cases[0].Exact {
+		t.Errorf("expected exact match first, got %+v", result.Cases[0])
+	}
+	if got := result.Cases[1].Domains; len(got) != 2 || got[0] != "example-shop.net" {
+		t.Errorf("unexpected related domains: %v", got)
+	}
+	if result.Sources[1].Error == "" || result.Error != "" {
+		t.Errorf("expected only the failing source to report an error: %+v, %q", result.Sources, result.Error)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	tests := map[string]string{
+		"example.com":     "example",
+		"www.example.com": "example",
+		"example.co.uk":   "example",
+		"example.io":      "example",
+	}
+	for domain, want := range tests {
+		if got := label(domain); got != want {
+			t.Errorf("label(%q) = %q, want %q", domain, got, want)
+		}
+	}
+}
+
+func TestParseSources(t *testing.T) {
+	sources, err := ParseSources("mirror=https://udrp.example/search?q=%s")
+	if err != nil || len(sources) != 1 || sources[0].Name != "mirror" {
+		t.Fatalf("unexpected result: %+v, %v", sources, err)
+	}
+	if _, err := ParseSources("broken=https://udrp.example/"); err == nil {
+		t.Error("expected an error for a URL without a placeholder")
+	}
+}
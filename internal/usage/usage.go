@@ -0,0 +1,261 @@
+// Package usage counts the calls made to paid providers and enforces soft
+// budgets on them. A call over budget is refused before it leaves the
+// process, so the section that needed it degrades the way it would on a
+// provider outage while free sources (DNS, port-43 WHOIS, public
+// metadata) keep working.
+package usage
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Paid providers.
+const (
+	WhoisXML    = "whoisxml"
+	EthRPC      = "eth_rpc"
+	ENSSubgraph = "ens_subgraph"
+	Unstoppable = "unstoppable_domains"
+	Doma        = "doma"
+)
+
+// Providers lists the metered providers.
+var Providers = []string{WhoisXML, EthRPC, ENSSubgraph, Unstoppable, Doma}
+
+// defaultHosts recognizes the providers' public endpoints by host suffix.
+// Self-hosted RPC nodes and subgraphs are added with AddHosts.
+var defaultHosts = []struct {
+	suffix   string
+	provider string
+}{
+	{"whoisxmlapi.com", WhoisXML},
+	{".infura.io", EthRPC},
+	{".alchemy.com", EthRPC},
+	{".quiknode.pro", EthRPC},
+	{"thegraph.com", ENSSubgraph},
+	{"api.unstoppabledomains.com", Unstoppable},
+	{"api.doma.xyz", Doma},
+}
+
+// BudgetError is returned for a call refused because its provider's
+// budget is used up.
+type BudgetError struct {
+	Provider string
+	Budget   int
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("%s budget of %d calls exhausted", e.Provider, e.Budget)
+}
+
+// Usage is the consumption of one provider.
+type Usage struct {
+	Calls int `json:"calls"`
+	// Budget is the soft limit, 0 when unlimited.
+	Budget  int `json:"budget,omitempty"`
+	Refused int `json:"refused,omitempty"`
+}
+
+// Meter counts calls per provider. Totals only grow, as Prometheus
+// counters must; budgets apply to the calls of the current period, which
+// never ends unless SetPeriod is called.
+type Meter struct {
+	mu      sync.Mutex
+	hosts   map[string]string
+	budgets map[string]int
+	period  time.Duration
+	start   time.Time
+	now     func() time.Time
+	// window counts calls toward the budgets.
+	window  map[string]int
+	calls   map[string]int
+	refused map[string]int
+}
+
+// Default is the meter shared by all clients in the process.
+var Default = New()
+
+// New returns a meter without budgets.
+func New() *Meter {
+	m := &Meter{
+		hosts:   map[string]string{},
+		budgets: map[string]int{},
+		now:     time.Now,
+		window:  map[string]int{},
+		calls:   map[string]int{},
+		refused: map[string]int{},
+	}
+	m.start = m.now()
+	return m
+}
+
+// AddHosts attributes the hosts of urls (or bare hosts) to provider, e.g.
+// the -eth-rpc endpoints.
+func (m *Meter) AddHosts(provider string, urls ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range urls {
+		if host := hostOf(u); host != "" {
+			m.hosts[host] = provider
+		}
+	}
+}
+
+// SetBudgets sets the soft limit of calls per provider and period.
+func (m *Meter) SetBudgets(budgets map[string]int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.budgets = budgets
+}
+
+// SetPeriod makes the budgets apply per period, e.g. per day in serve
+// mode, instead of for the whole process. The first period starts now.
+func (m *Meter) SetPeriod(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.period = d
+	m.start = m.now()
+	m.window = map[string]int{}
+}
+
+// Provider returns the paid provider serving host, or "".
+func (m *Meter) Provider(host string) string {
+	host = hostOf(host)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.hosts[host]; ok {
+		return p
+	}
+	for _, h := range defaultHosts {
+		if host == strings.TrimPrefix(h.suffix, ".") || strings.HasSuffix(host, h.suffix) {
+			return h.provider
+		}
+	}
+	return ""
+}
+
+// take counts a call to provider, or refuses it when the budget is used
+// up.
+func (m *Meter) take(provider string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.period > 0 && m.now().Sub(m.start) >= m.period {
+		m.start = m.now()
+		m.window = map[string]int{}
+	}
+	if budget := m.budgets[provider]; budget > 0 && m.window[provider] >= budget {
+		m.refused[provider]++
+		return &BudgetError{Provider: provider, Budget: budget}
+	}
+	m.window[provider]++
+	m.calls[provider]++
+	return nil
+}
+
+// Snapshot returns the usage of every provider called so far.
+func (m *Meter) Snapshot() map[string]Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := map[string]Usage{}
+	for _, p := range Providers {
+		if m.calls[p]+m.refused[p] > 0 {
+			out[p] = Usage{Calls: m.calls[p], Budget: m.budgets[p], Refused: m.refused[p]}
+		}
+	}
+	return out
+}
+
+// Transport wraps base so every HTTP request to a paid provider is counted
+// and refused over budget.
+func (m *Meter) Transport(base http.RoundTripper) http.RoundTripper {
+	return &meteredTransport{meter: m, base: base}
+}
+
+type meteredTransport struct {
+	meter *Meter
+	base  http.RoundTripper
+}
+
+func (t *meteredTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if p := t.meter.Provider(req.URL.Host); p != "" {
+		if err := t.meter.take(p); err != nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// WritePrometheus writes the counters in the Prometheus text format.
+func (m *Meter) WritePrometheus(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	write := func(name, help string, counts map[string]int) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, p := range Providers {
+			fmt.Fprintf(w, "%s{provider=%q} %d\n", name, p, counts[p])
+		}
+	}
+	write("d3_provider_calls_total", "Calls made to paid providers.", m.calls)
+	write("d3_provider_budget_refusals_total", "Calls refused because the provider's budget was used up.", m.refused)
+
+	fmt.Fprintf(w, "# HELP d3_provider_budget Soft limit of calls per provider and period.\n# TYPE d3_provider_budget gauge\n")
+	for _, p := range Providers {
+		if budget := m.budgets[p]; budget > 0 {
+			fmt.Fprintf(w, "d3_provider_budget{provider=%q} %d\n", p, budget)
+		}
+	}
+}
+
+// ParseBudgets parses "provider=calls,...".
+func ParseBudgets(spec string) (map[string]int, error) {
+	budgets := map[string]int{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid budget %q: expected provider=calls", item)
+		}
+		known := false
+		for _, p := range Providers {
+			known = known || p == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown provider %q (providers: %s)", name, strings.Join(Providers, ", "))
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid budget in %q: expected a positive number of calls", item)
+		}
+		budgets[name] = n
+	}
+	return budgets, nil
+}
+
+func hostOf(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+	}
+	if i := strings.IndexAny(s, "/?#"); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		s = s[i+1:]
+	}
+	if h, _, err := net.SplitHostPort(s); err == nil {
+		s = h
+	}
+	return strings.ToLower(s)
+}
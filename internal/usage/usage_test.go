@@ -0,0 +1,90 @@
+package usage
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMeterBudgets(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	m := New()
+	m.AddHosts(EthRPC, ts.URL)
+	m.SetBudgets(map[string]int{EthRPC: 2})
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	m.SetPeriod(24 * time.Hour)
+	client := &http.Client{Transport: m.Transport(http.DefaultTransport)}
+
+	get := func() error {
+		resp, err := client.Get(ts.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		if err := get(); err != nil {
+			t.Fatalf("call %d within budget: %v", i+1, err)
+		}
+	}
+	var budgetErr *BudgetError
+	if err := get(); !errors.As(err, &budgetErr) || budgetErr.Provider != EthRPC {
+		t.Fatalf("call over budget: got %v", err)
+	}
+	if got := m.Snapshot()[EthRPC]; got != (Usage{Calls: 2, Budget: 2, Refused: 1}) {
+		t.Errorf("snapshot = %+v", got)
+	}
+
+	// A new period starts a new budget; totals keep growing.
+	now = now.Add(25 * time.Hour)
+	if err := get(); err != nil {
+		t.Errorf("call in the next period: %v", err)
+	}
+	var b strings.Builder
+	m.WritePrometheus(&b)
+	for _, want := range []string{
+		`d3_provider_calls_total{provider="eth_rpc"} 3`,
+		`d3_provider_budget_refusals_total{provider="eth_rpc"} 1`,
+		`d3_provider_budget{provider="eth_rpc"} 2`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics missing %q:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestProvider(t *testing.T) {
+	m := New()
+	m.AddHosts(ENSSubgraph, "http://graph.internal:8000/subgraphs/name/ens")
+	for host, want := range map[string]string{
+		"mainnet.infura.io":               EthRPC,
+		"eth-mainnet.g.alchemy.com":       EthRPC,
+		"whois-history.whoisxmlapi.com":   WhoisXML,
+		"api.unstoppabledomains.com":      Unstoppable,
+		"metadata.unstoppabledomains.com": "",
+		"graph.internal:8000":             ENSSubgraph,
+		"crt.sh":                          "",
+	} {
+		if got := m.Provider(host); got != want {
+			t.Errorf("Provider(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestParseBudgets(t *testing.T) {
+	budgets, err := ParseBudgets("whoisxml=100, eth_rpc=5000")
+	if err != nil || budgets[WhoisXML] != 100 || budgets[EthRPC] != 5000 {
+		t.Errorf("ParseBudgets = %v, %v", budgets, err)
+	}
+	for _, spec := range []string{"whoisxml", "nope=1", "doma=0", "doma=x"} {
+		if _, err := ParseBudgets(spec); err == nil {
+			t.Errorf("ParseBudgets(%q) accepted", spec)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package valuation
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ApplyAge adjusts a valuation for how long the name has been registered:
+// aged names carry history, backlinks and scarcity, so each full year adds
+// 5% up to a 50% premium.
+func (e *Engine) ApplyAge(r *Result, registered time.Time) {
+	if r == nil || registered.IsZero() {
+		return
+	}
+	years := time.Since(registered).Hours() / (24 * 365.25)
+	if years < 0 {
+		return
+	}
+	r.Factors.AgeYears = math.Round(years*10) / 10
+
+	premium := math.Min(math.Floor(years)*0.05, 0.5)
+	if premium == 0 {
+		return
+	}
+	r.EstimatedValue = int(math.Min(float64(r.EstimatedValue)*(1+premium), 1000000))
+	reason := fmt.Sprintf("Registered since %d (+%.0f%% age premium)", registered.Year(), premium*100)
+	if r.Reasoning == "" || r.Reasoning == "Standard domain name" {
+		r.Reasoning = reason
+	} else {
+		r.Reasoning += "; " + reason
+	}
+}
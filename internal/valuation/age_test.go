@@ -0,0 +1,32 @@
+package valuation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEngine_ApplyAge(t *testing.T) {
+	engine := NewEngine()
+
+	base := engine.Evaluate("vault.eth")
+	aged := engine.Evaluate("vault.eth")
+	engine.ApplyAge(aged, time.Now().AddDate(-6, -1, 0))
+	if want := int(float64(base.EstimatedValue) * 1.3); aged.EstimatedValue != want {
+		t.Errorf("expected a 30%% premium for a 6 year old name: got %d, want %d", aged.EstimatedValue, want)
+	}
+	if aged.Factors.AgeYears < 6 || aged.Reasoning == base.Reasoning {
+		t.Errorf("expected age in factors and reasoning, got %+v", aged)
+	}
+
+	veteran := engine.Evaluate("vault.eth")
+	engine.ApplyAge(veteran, time.Now().AddDate(-40, 0, 0))
+	if want := int(float64(base.EstimatedValue) * 1.5); veteran.EstimatedValue != want {
+		t.Errorf("expected the premium to be capped at 50%%: got %d, want %d", veteran.EstimatedValue, want)
+	}
+
+	fresh := engine.Evaluate("vault.eth")
+	engine.ApplyAge(fresh, time.Now().AddDate(0, -3, 0))
+	if fresh.EstimatedValue != base.EstimatedValue || fresh.Reasoning != base.Reasoning {
+		t.Errorf("expected no premium for a name under a year old, got %+v", fresh)
+	}
+}
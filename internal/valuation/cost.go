@@ -0,0 +1,112 @@
+package valuation
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultRenewalPrices are typical yearly retail renewal prices in USD per
+// TLD. Registrar pricing varies; override with SetRenewalPrices.
+var DefaultRenewalPrices = map[string]float64{
+	"com":  11,
+	"net":  14,
+	"org":  12,
+	"io":   50,
+	"co":   30,
+	"ai":   80,
+	"app":  16,
+	"dev":  14,
+	"tech": 50,
+	"xyz":  13,
+	"info": 20,
+	"biz":  20,
+	"me":   20,
+	"tv":   35,
+	"cc":   15,
+	"us":   10,
+	"uk":   9,
+	"de":   10,
+	"ca":   15,
+	// .eth is priced by label length; this is the 5+ character fee.
+	"eth": 5,
+	// Unstoppable Domains names are bought once and never renewed.
+	"crypto":  0,
+	"nft":     0,
+	"x":       0,
+	"wallet":  0,
+	"bitcoin": 0,
+	"dao":     0,
+	"888":     0,
+	"zil":     0,
+}
+
+// ensShortPrices are the yearly .eth fees for 3 and 4 character labels.
+var ensShortPrices = map[int]float64{3: 640, 4: 160}
+
+// ParseRenewalPrices parses "tld=price,..." overrides, e.g. "io=45,ai=70".
+func ParseRenewalPrices(spec string) (map[string]float64, error) {
+	prices := make(map[string]float64)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		tld, value, ok := strings.Cut(item, "=")
+		price, err := strconv.ParseFloat(value, 64)
+		if !ok || tld == "" || err != nil || price < 0 {
+			return nil, fmt.Errorf("invalid renewal price %q: expected tld=price", item)
+		}
+		prices[strings.ToLower(strings.TrimPrefix(tld, "."))] = price
+	}
+	return prices, nil
+}
+
+// SetRenewalPrices overrides the renewal prices of the given TLDs. An
+// "eth" override replaces the length-based .eth fees.
+func (e *Engine) SetRenewalPrices(prices map[string]float64) {
+	e.renewalPrices = prices
+}
+
+// RenewalCost returns the yearly renewal price of domain, and false when
+// its TLD is not in the price table.
+func (e *Engine) RenewalCost(domain string) (float64, bool) {
+	labels := strings.Split(strings.ToLower(domain), ".")
+	if len(labels) < 2 {
+		return 0, false
+	}
+	tld := labels[len(labels)-1]
+	if price, ok := e.renewalPrices[tld]; ok {
+		return price, true
+	}
+	price, ok := DefaultRenewalPrices[tld]
+	if tld == "eth" && len(labels) == 2 {
+		if short, found := ensShortPrices[utf8.RuneCountInString(labels[0])]; found {
+			price = short
+		}
+	}
+	return price, ok
+}
+
+// ApplyCarryingCost adds the yearly renewal cost as a fraction of the
+// estimated value and flags names that cost more to keep than they are
+// worth.
+func (e *Engine) ApplyCarryingCost(r *Result, domain string) {
+	if r == nil {
+		return
+	}
+	cost, ok := e.RenewalCost(domain)
+	if !ok {
+		return
+	}
+	r.RenewalCost = &cost
+	if r.EstimatedValue <= 0 {
+		r.Underwater = cost > 0
+		return
+	}
+	ratio := math.Round(cost/float64(r.EstimatedValue)*10000) / 10000
+	r.CostRatio = &ratio
+	r.Underwater = cost > float64(r.EstimatedValue)
+}
@@ -0,0 +1,54 @@
+package valuation
+
+import "testing"
+
+func TestEngine_ApplyCarryingCost(t *testing.T) {
+	engine := NewEngine()
+
+	tests := []struct {
+		domain     string
+		value      int
+		cost       float64
+		underwater bool
+	}{
+		{"example.com", 1100, 11, false},
+		{"example.com", 8, 11, true},
+		{"abc.eth", 500, 640, true},
+		{"abcd.eth", 1600, 160, false},
+		{"vault.crypto", 100, 0, false},
+	}
+	for _, tt := range tests {
+		r := &Result{EstimatedValue: tt.value}
+		engine.ApplyCarryingCost(r, tt.domain)
+		if r.RenewalCost == nil || *r.RenewalCost != tt.cost || r.Underwater != tt.underwater {
+			t.Errorf("%s worth $%d: got cost %v, underwater %v", tt.domain, tt.value, r.RenewalCost, r.Underwater)
+		}
+	}
+
+	r := &Result{EstimatedValue: 1100}
+	engine.ApplyCarryingCost(r, "example.com")
+	if r.CostRatio == nil || *r.CostRatio != 0.01 {
+		t.Errorf("expected a 1%% cost ratio, got %v", r.CostRatio)
+	}
+
+	unknown := &Result{EstimatedValue: 100}
+	engine.ApplyCarryingCost(unknown, "example.zz")
+	if unknown.RenewalCost != nil || unknown.CostRatio != nil || unknown.Underwater {
+		t.Errorf("expected no cost for a TLD without a price, got %+v", unknown)
+	}
+
+	prices, err := ParseRenewalPrices("io=45, .eth=8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetRenewalPrices(prices)
+	if cost, _ := engine.RenewalCost("abc.eth"); cost != 8 {
+		t.Errorf("expected the .eth override to replace length pricing, got %v", cost)
+	}
+	if cost, _ := engine.RenewalCost("example.io"); cost != 45 {
+		t.Errorf("expected the .io override, got %v", cost)
+	}
+	if _, err := ParseRenewalPrices("io"); err == nil {
+		t.Error("expected an error for a price without a value")
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"math"
 	"strings"
 	"unicode"
+
+	"d3-domain-tool/internal/suffix"
 )
 
 type Engine struct {
-	premiumWords []string
-	commonTLDs   map[string]float64
+	premiumWords  []string
+	commonTLDs    map[string]float64
+	renewalPrices map[string]float64
 }
 
 type Result struct {
@@ -17,6 +20,14 @@ type Result struct {
 	Confidence       string  `json:"confidence"`
 	Factors          Factors `json:"factors"`
 	Reasoning        string  `json:"reasoning"`
+	// RenewalCost is the yearly renewal price from the TLD price table and
+	// CostRatio that cost as a fraction of EstimatedValue. Underwater
+	// names cost more per year than they are worth.
+	RenewalCost      *float64 `json:"renewal_cost,omitempty"`
+	CostRatio        *float64 `json:"cost_ratio,omitempty"`
+	Underwater       bool     `json:"underwater,omitempty"`
+	Negotiation      *Negotiation `json:"negotiation,omitempty"`
+	Lease            *Lease       `json:"lease,omitempty"`
 }
 
 type Factors struct {
@@ -29,6 +40,7 @@ type Factors struct {
 	Brandable        bool    `json:"brandable"`
 	HasNumbers       bool    `json:"has_numbers"`
 	HasHyphens       bool    `json:"has_hyphens"`
+	AgeYears         float64 `json:"age_years,omitempty"`
 }
 
 func NewEngine() *Engine {
@@ -66,8 +78,10 @@ func (e *Engine) Evaluate(domain string) *Result {
 		}
 	}
 
-	name := parts[0]
-	tld := "." + parts[len(parts)-1]
+	// The name is the label registered under the suffix, so example.co.uk
+	// is valued as "example" under .co.uk.
+	name := suffix.Label(domain)
+	tld := "." + suffix.Public(domain)
 
 	factors := e.analyzeDomain(name, tld)
 	value := e.calculateValue(factors)
@@ -134,4 +134,18 @@ func TestIsAllLetters(t *testing.T) {
 			t.Errorf("For input %s, expected %v, got %v", tt.input, tt.expected, result)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestEvaluate_SecondLevelCCTLD(t *testing.T) {
+	engine := NewEngine()
+
+	// The registered label is valued, not the zone it sits under.
+	uk := engine.Evaluate("cloud.co.uk")
+	com := engine.Evaluate("cloud.com")
+	if uk.Factors.Length != com.Factors.Length || uk.Factors.WordScore != com.Factors.WordScore {
+		t.Errorf("cloud.co.uk factors %+v differ from cloud.com %+v", uk.Factors, com.Factors)
+	}
+	if sub := engine.Evaluate("shop.cloud.com.br"); sub.Factors.Length != 5 {
+		t.Errorf("shop.cloud.com.br length = %d, want the length of cloud", sub.Factors.Length)
+	}
+}
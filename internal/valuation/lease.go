@@ -0,0 +1,66 @@
+package valuation
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultCapRate is the yearly return on the estimated value a lessor
+// expects when no cap rate is configured.
+const DefaultCapRate = 0.10
+
+// rentToOwnTerms are the installment plans offered, in months.
+var rentToOwnTerms = []int{12, 24, 36}
+
+// Lease prices a domain for leasing and rent-to-own instead of outright
+// sale.
+type Lease struct {
+	CapRate   float64         `json:"cap_rate"`
+	Monthly   int             `json:"monthly_lease"`
+	Annual    int             `json:"annual_lease"`
+	RentToOwn []RentToOwnPlan `json:"rent_to_own"`
+}
+
+// RentToOwnPlan is an installment plan that transfers the domain after the
+// last payment. Payments amortize the estimated value at the cap rate, so
+// Premium is the financing cost over buying outright.
+type RentToOwnPlan struct {
+	Months  int `json:"months"`
+	Monthly int `json:"monthly_payment"`
+	Total   int `json:"total"`
+	Premium int `json:"premium"`
+}
+
+// ValidateCapRate rejects cap rates outside (0, 1].
+func ValidateCapRate(rate float64) error {
+	if rate <= 0 || rate > 1 {
+		return fmt.Errorf("invalid cap rate %v: expected a yearly rate such as 0.1", rate)
+	}
+	return nil
+}
+
+// ApplyLease adds lease pricing at capRate (yearly, e.g. 0.1 for 10%): a
+// monthly lease that yields the cap rate on the estimated value, and
+// rent-to-own plans that amortize the value at the same rate.
+func (e *Engine) ApplyLease(r *Result, capRate float64) {
+	if r == nil || capRate <= 0 || r.EstimatedValue <= 0 {
+		return
+	}
+	value := float64(r.EstimatedValue)
+	monthlyRate := capRate / 12
+
+	lease := &Lease{
+		CapRate: capRate,
+		Monthly: int(math.Max(math.Ceil(value*monthlyRate), 1)),
+	}
+	lease.Annual = lease.Monthly * 12
+
+	for _, months := range rentToOwnTerms {
+		payment := value * monthlyRate / (1 - math.Pow(1+monthlyRate, -float64(months)))
+		plan := RentToOwnPlan{Months: months, Monthly: int(math.Ceil(payment))}
+		plan.Total = plan.Monthly * months
+		plan.Premium = plan.Total - r.EstimatedValue
+		lease.RentToOwn = append(lease.RentToOwn, plan)
+	}
+	r.Lease = lease
+}
@@ -0,0 +1,33 @@
+package valuation
+
+import "testing"
+
+func TestEngine_ApplyLease(t *testing.T) {
+	engine := NewEngine()
+
+	r := &Result{EstimatedValue: 12000}
+	engine.ApplyLease(r, 0.12)
+	if r.Lease == nil || r.Lease.Monthly != 120 || r.Lease.Annual != 1440 {
+		t.Fatalf("unexpected lease %+v", r.Lease)
+	}
+	if len(r.Lease.RentToOwn) != 3 {
+		t.Fatalf("expected 3 rent-to-own plans, got %+v", r.Lease.RentToOwn)
+	}
+	// 12,000 amortized over 12 months at 1% a month is 1,066.19 a month.
+	if plan := r.Lease.RentToOwn[0]; plan.Months != 12 || plan.Monthly != 1067 || plan.Total != 12804 || plan.Premium != 804 {
+		t.Errorf("unexpected 12 month plan %+v", plan)
+	}
+	for i := 1; i < len(r.Lease.RentToOwn); i++ {
+		prev, plan := r.Lease.RentToOwn[i-1], r.Lease.RentToOwn[i]
+		if plan.Monthly >= prev.Monthly || plan.Premium <= prev.Premium {
+			t.Errorf("longer plans should cost less per month and more in total: %+v then %+v", prev, plan)
+		}
+	}
+
+	if err := ValidateCapRate(0); err == nil {
+		t.Error("expected an error for a zero cap rate")
+	}
+	if err := ValidateCapRate(8); err == nil {
+		t.Error("expected an error for a cap rate given in percent")
+	}
+}
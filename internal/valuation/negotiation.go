@@ -0,0 +1,80 @@
+package valuation
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Negotiation sides.
+const (
+	Buyer  = "buyer"
+	Seller = "seller"
+)
+
+// Negotiation holds price anchors for one side of a deal. For a buyer the
+// walk-away price is the most to pay; for a seller it is the least to
+// accept.
+type Negotiation struct {
+	Side     string `json:"side"`
+	Opening  int    `json:"opening_offer"`
+	Target   int    `json:"target_price"`
+	WalkAway int    `json:"walk_away_price"`
+}
+
+// negotiationSpread is how far the anchors move from the estimate for each
+// confidence level: the less certain the estimate, the wider the band.
+var negotiationSpread = map[string]float64{
+	"high":   0.2,
+	"medium": 0.35,
+	"low":    0.5,
+}
+
+// ParseSide validates a -negotiate value.
+func ParseSide(s string) (string, error) {
+	switch side := strings.ToLower(strings.TrimSpace(s)); side {
+	case "", Buyer, Seller:
+		return side, nil
+	}
+	return "", fmt.Errorf("invalid negotiation side %q: expected buyer or seller", s)
+}
+
+// ApplyNegotiation adds opening, target and walk-away prices for side,
+// derived from the estimate and its confidence. A buyer opens below the
+// estimate and walks away somewhat above it; a seller mirrors that.
+func (e *Engine) ApplyNegotiation(r *Result, side string) {
+	if r == nil || side == "" || r.EstimatedValue <= 0 {
+		return
+	}
+	spread, ok := negotiationSpread[r.Confidence]
+	if !ok {
+		spread = negotiationSpread["medium"]
+	}
+	value := float64(r.EstimatedValue)
+	sign := -1.0
+	if side == Seller {
+		sign = 1
+	}
+	r.Negotiation = &Negotiation{
+		Side:     side,
+		Opening:  roundPrice(value * (1 + sign*spread)),
+		Target:   roundPrice(value * (1 + sign*spread/3)),
+		WalkAway: roundPrice(value * (1 - sign*spread/2)),
+	}
+}
+
+// roundPrice rounds to a figure that reads naturally in an offer.
+func roundPrice(v float64) int {
+	step := 10.0
+	switch {
+	case v >= 100000:
+		step = 1000
+	case v >= 10000:
+		step = 500
+	case v >= 1000:
+		step = 50
+	case v < 100:
+		step = 1
+	}
+	return int(math.Max(math.Round(v/step)*step, 1))
+}
@@ -0,0 +1,29 @@
+package valuation
+
+import "testing"
+
+func TestEngine_ApplyNegotiation(t *testing.T) {
+	engine := NewEngine()
+
+	buyer := &Result{EstimatedValue: 10000, Confidence: "high"}
+	engine.ApplyNegotiation(buyer, Buyer)
+	if n := buyer.Negotiation; n == nil || n.Opening != 8000 || n.Target != 9350 || n.WalkAway != 11000 {
+		t.Errorf("unexpected buyer anchors %+v", buyer.Negotiation)
+	}
+
+	seller := &Result{EstimatedValue: 10000, Confidence: "low"}
+	engine.ApplyNegotiation(seller, Seller)
+	if n := seller.Negotiation; n == nil || n.Opening != 15000 || n.Target != 11500 || n.WalkAway != 7500 {
+		t.Errorf("unexpected seller anchors %+v", seller.Negotiation)
+	}
+
+	none := &Result{EstimatedValue: 10000, Confidence: "high"}
+	engine.ApplyNegotiation(none, "")
+	if none.Negotiation != nil {
+		t.Errorf("expected no anchors without a side, got %+v", none.Negotiation)
+	}
+
+	if _, err := ParseSide("broker"); err == nil {
+		t.Error("expected an error for an unknown side")
+	}
+}
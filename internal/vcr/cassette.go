@@ -0,0 +1,250 @@
+// Package vcr records WHOIS, DNS and HTTP traffic into fixture files
+// ("cassettes") and replays it later, so tests and demos run
+// deterministically without network access.
+package vcr
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"d3-domain-tool/internal/checker"
+)
+
+// Modes.
+const (
+	ModeRecord = "record"
+	ModeReplay = "replay"
+)
+
+// Interaction is one recorded exchange.
+type Interaction struct {
+	Kind       string      `json:"kind"`
+	Key        string      `json:"key"`
+	Status     int         `json:"status,omitempty"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+	Error      string      `json:"error,omitempty"`
+	RecordedAt time.Time   `json:"recorded_at"`
+}
+
+// Cassette holds recorded interactions. In replay mode each request is
+// answered from the interactions with the same key, in recording order;
+// the last one repeats once they are used up.
+type Cassette struct {
+	mode         string
+	Interactions []Interaction `json:"interactions"`
+
+	mu     sync.Mutex
+	played map[string]int
+}
+
+// NewRecorder returns an empty cassette that records traffic.
+func NewRecorder() *Cassette {
+	return &Cassette{mode: ModeRecord}
+}
+
+// Load reads a cassette for replay.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %v", err)
+	}
+	c := &Cassette{mode: ModeReplay}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// Mode returns ModeRecord or ModeReplay.
+func (c *Cassette) Mode() string {
+	return c.mode
+}
+
+// Save writes the recorded interactions to path, ordered by key so
+// re-recording produces small diffs.
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sort.SliceStable(c.Interactions, func(i, j int) bool {
+		return c.Interactions[i].Key < c.Interactions[j].Key
+	})
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func (c *Cassette) record(i Interaction) {
+	i.RecordedAt = time.Now().UTC()
+	c.mu.Lock()
+	c.Interactions = append(c.Interactions, i)
+	c.mu.Unlock()
+}
+
+func (c *Cassette) replay(kind, key string) (*Interaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var matches []*Interaction
+	for i := range c.Interactions {
+		if c.Interactions[i].Kind == kind && c.Interactions[i].Key == key {
+			matches = append(matches, &c.Interactions[i])
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("vcr: no recorded %s interaction for %s", kind, key)
+	}
+	if c.played == nil {
+		c.played = make(map[string]int)
+	}
+	n := c.played[kind+" "+key]
+	c.played[kind+" "+key]++
+	if n >= len(matches) {
+		n = len(matches) - 1
+	}
+	return matches[n], nil
+}
+
+// WHOIS wraps a port-43 query function so that it records into or replays
+// from the cassette.
+func (c *Cassette) WHOIS(next func(ctx context.Context, server, query string) (string, error)) func(ctx context.Context, server, query string) (string, error) {
+	return func(ctx context.Context, server, query string) (string, error) {
+		key := server + " " + query
+		if c.mode == ModeReplay {
+			i, err := c.replay("whois", key)
+			if err != nil {
+				return "", err
+			}
+			if i.Error != "" {
+				return "", fmt.Errorf("%s", i.Error)
+			}
+			return i.Body, nil
+		}
+
+		body, err := next(ctx, server, query)
+		i := Interaction{Kind: "whois", Key: key, Body: body}
+		if err != nil {
+			i.Error = err.Error()
+		}
+		c.record(i)
+		return body, err
+	}
+}
+
+// DNS wraps a function returning a domain's raw DNS answers so that it
+// records into or replays from the cassette.
+func (c *Cassette) DNS(next func(domain string) ([]checker.Record, error)) func(domain string) ([]checker.Record, error) {
+	return func(domain string) ([]checker.Record, error) {
+		if c.mode == ModeReplay {
+			i, err := c.replay("dns", domain)
+			if err != nil {
+				return nil, err
+			}
+			if i.Error != "" {
+				return nil, fmt.Errorf("%s", i.Error)
+			}
+			var answers []checker.Record
+			if err := json.Unmarshal([]byte(i.Body), &answers); err != nil {
+				return nil, fmt.Errorf("vcr: invalid dns interaction for %s: %v", domain, err)
+			}
+			return answers, nil
+		}
+
+		answers, err := next(domain)
+		i := Interaction{Kind: "dns", Key: domain}
+		if err != nil {
+			i.Error = err.Error()
+		} else {
+			body, _ := json.Marshal(answers)
+			i.Body = string(body)
+		}
+		c.record(i)
+		return answers, err
+	}
+}
+
+// Transport wraps base so that HTTP requests record into or replay from
+// the cassette. Requests are keyed by method, URL and a hash of the body.
+func (c *Cassette) Transport(base http.RoundTripper) http.RoundTripper {
+	return &transport{cassette: c, base: base}
+}
+
+type transport struct {
+	cassette *Cassette
+	base     http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := requestKey(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.cassette.mode == ModeReplay {
+		i, err := t.cassette.replay("http", key)
+		if err != nil {
+			return nil, err
+		}
+		if i.Error != "" {
+			return nil, fmt.Errorf("%s", i.Error)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Status, http.StatusText(i.Status)),
+			StatusCode:    i.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        i.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewBufferString(i.Body)),
+			ContentLength: int64(len(i.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.cassette.record(Interaction{Kind: "http", Key: key, Error: err.Error()})
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.cassette.record(Interaction{
+		Kind:   "http",
+		Key:    key,
+		Status: resp.StatusCode,
+		Header: resp.Header.Clone(),
+		Body:   string(body),
+	})
+	return resp, nil
+}
+
+func requestKey(req *http.Request) (string, error) {
+	key := req.Method + " " + req.URL.String()
+	if req.Body == nil || req.Body == http.NoBody {
+		return key, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	sum := sha256.Sum256(body)
+	return key + " body=" + hex.EncodeToString(sum[:8]), nil
+}
@@ -0,0 +1,83 @@
+package vcr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"d3-domain-tool/internal/checker"
+)
+
+func TestCassette_RecordReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"call": %d, "echo": %q}`, calls, body)
+	}))
+
+	recorder := NewRecorder()
+	client := &http.Client{Transport: recorder.Transport(http.DefaultTransport)}
+	var recorded []string
+	for _, payload := range []string{"a", "a", "b"} {
+		resp, err := client.Post(server.URL+"/api", "text/plain", strings.NewReader(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		recorded = append(recorded, string(body))
+	}
+	whois := recorder.WHOIS(func(ctx context.Context, server, query string) (string, error) {
+		return "Domain Name: " + query, nil
+	})
+	whois(context.Background(), "whois.example", "example.com")
+	dns := recorder.DNS(func(domain string) ([]checker.Record, error) {
+		return []checker.Record{{Type: "A", Value: "192.0.2.1", TTL: 300}}, nil
+	})
+	dns("example.com")
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := recorder.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	player, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if player.Mode() != ModeReplay || len(player.Interactions) != 5 {
+		t.Fatalf("unexpected cassette: mode %s, %d interactions", player.Mode(), len(player.Interactions))
+	}
+	client = &http.Client{Transport: player.Transport(http.DefaultTransport)}
+	for i, payload := range []string{"a", "a", "b"} {
+		resp, err := client.Post(server.URL+"/api", "text/plain", strings.NewReader(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != recorded[i] || resp.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request %d: replayed %s, recorded %s", i, body, recorded[i])
+		}
+	}
+
+	raw, err := player.WHOIS(nil)(context.Background(), "whois.example", "example.com")
+	if err != nil || raw != "Domain Name: example.com" {
+		t.Errorf("unexpected WHOIS replay: %q, %v", raw, err)
+	}
+	answers, err := player.DNS(nil)("example.com")
+	if err != nil || len(answers) != 1 || answers[0].Value != "192.0.2.1" || answers[0].TTL != 300 {
+		t.Errorf("unexpected DNS replay: %+v, %v", answers, err)
+	}
+	if _, err := client.Get(server.URL + "/other"); err == nil {
+		t.Error("expected an error for a request missing from the cassette")
+	}
+}
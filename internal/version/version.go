@@ -0,0 +1,106 @@
+// Package version holds the build metadata reported by the version command.
+// Release builds set it with the linker:
+//
+//	go build -ldflags "-X d3-domain-tool/internal/version.Version=v1.4.0 \
+//	  -X d3-domain-tool/internal/version.Commit=$(git rev-parse HEAD) \
+//	  -X d3-domain-tool/internal/version.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Without ldflags the commit and date come from the VCS stamp the Go
+// toolchain embeds when building inside a git checkout.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Set with -ldflags "-X d3-domain-tool/internal/version.Name=value".
+var (
+	// Version is the semantic version of the release, e.g. v1.4.0.
+	Version = ""
+	// Commit is the git commit the binary was built from.
+	Commit = ""
+	// Date is when the binary was built, in RFC 3339.
+	Date = ""
+)
+
+// readBuildInfo is replaced in tests.
+var readBuildInfo = debug.ReadBuildInfo
+
+// Info describes the running binary and what it supports.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+	// Features is the support matrix, filled in by the caller: supported
+	// TLDs by kind, analyzer modules, commands.
+	Features []Feature `json:"features,omitempty"`
+}
+
+// Feature is one row of the support matrix.
+type Feature struct {
+	Name  string   `json:"name"`
+	Items []string `json:"items"`
+}
+
+// Get returns the build metadata. Values set with ldflags win over the VCS
+// stamp; a build without either reports version "dev".
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if bi, ok := readBuildInfo(); ok {
+		// go install module@v1.4.0 stamps the module version.
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = s.Value
+				}
+			case "vcs.modified":
+				info.Modified = s.Value == "true" && Commit == ""
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	return info
+}
+
+// Short is the one-line form, e.g. "v1.4.0 (3f2a9c1e0b7d, 2026-10-01T12:00:00Z)".
+func (i Info) Short() string {
+	var meta []string
+	if i.Commit != "" {
+		commit := i.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		if i.Modified {
+			commit += "-dirty"
+		}
+		meta = append(meta, commit)
+	}
+	if i.Date != "" {
+		meta = append(meta, i.Date)
+	}
+	if len(meta) == 0 {
+		return i.Version
+	}
+	return i.Version + " (" + strings.Join(meta, ", ") + ")"
+}
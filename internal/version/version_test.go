@@ -0,0 +1,38 @@
+package version
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	defer func(v, c, d string, read func() (*debug.BuildInfo, bool)) {
+		Version, Commit, Date, readBuildInfo = v, c, d, read
+	}(Version, Commit, Date, readBuildInfo)
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			Main: debug.Module{Version: "(devel)"},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "3f2a9c1e0b7d5a6f8e9d0c1b2a3f4e5d6c7b8a9f"},
+				{Key: "vcs.time", Value: "2026-10-01T12:00:00Z"},
+				{Key: "vcs.modified", Value: "true"},
+			},
+		}, true
+	}
+
+	Version, Commit, Date = "", "", ""
+	info := Get()
+	if info.Version != "dev" || info.Date != "2026-10-01T12:00:00Z" {
+		t.Errorf("VCS stamp: %+v", info)
+	}
+	if got := info.Short(); got != "dev (3f2a9c1e0b7d-dirty, 2026-10-01T12:00:00Z)" {
+		t.Errorf("Short() = %q", got)
+	}
+
+	// ldflags win.
+	Version, Commit, Date = "v1.4.0", "abc1234", "2026-10-02T08:00:00Z"
+	info = Get()
+	if got := info.Short(); got != "v1.4.0 (abc1234, 2026-10-02T08:00:00Z)" {
+		t.Errorf("Short() = %q", got)
+	}
+}
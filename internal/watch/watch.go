@@ -0,0 +1,267 @@
+// Package watch re-analyzes domains on an interval and reports changes to
+// their availability, expiry and tokenization status.
+package watch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/compare"
+	"d3-domain-tool/internal/findings"
+)
+
+// Modules are the analyzer modules a watch needs; the others are skipped
+// to keep polls fast.
+var Modules = []string{"doma", "blockchain", "alt_root", "dns", "whois"}
+
+// Watched fields.
+const (
+	FieldAvailability = "availability"
+	FieldExpiry       = "expiry"
+	FieldTokenization = "tokenization"
+)
+
+// watched are the result paths compare.Diff is filtered to for each
+// watched field.
+var watched = []struct {
+	field string
+	paths []string
+}{
+	{FieldAvailability, compare.VerdictPaths},
+	{FieldExpiry, []string{"blockchain_data.expiry_date", "whois_data.expiry_date", "whois_data.error"}},
+	{FieldTokenization, []string{"doma_data.is_tokenized"}},
+}
+
+// Tokenization values.
+const (
+	Tokenized    = "tokenized"
+	NotTokenized = "not tokenized"
+	unknown      = "unknown"
+)
+
+// State is what is watched of one domain.
+type State struct {
+	Domain string `json:"domain"`
+	// Availability is the analyzer verdict: available, taken or unknown.
+	Availability string     `json:"availability"`
+	Expiry       *time.Time `json:"expiry,omitempty"`
+	Tokenization string     `json:"tokenization"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// Change is a watched field that differs from the previous poll.
+type Change struct {
+	Domain string `json:"domain"`
+	Field  string `json:"field"`
+	Old    string `json:"old"`
+	New    string `json:"new"`
+	// Dropped is set when a taken domain became available.
+	Dropped bool `json:"dropped,omitempty"`
+	// Severity is how much the change matters, as compare rates it.
+	Severity findings.Severity `json:"severity"`
+}
+
+// Result is one poll. The first poll sets the baseline and reports no
+// changes.
+type Result struct {
+	Poll      int       `json:"poll"`
+	States    []State   `json:"states"`
+	Changes   []Change  `json:"changes"`
+	CheckedAt time.Time `json:"checked_at"`
+
+	analyses map[string]*analyzer.Result
+}
+
+// Analysis returns the analysis of domain this poll made, nil when it
+// failed.
+func (r *Result) Analysis(domain string) *analyzer.Result {
+	return r.analyses[domain]
+}
+
+// Dropped returns the domains that became available in this poll.
+func (r *Result) Dropped() []string {
+	var names []string
+	for _, c := range r.Changes {
+		if c.Dropped {
+			names = append(names, c.Domain)
+		}
+	}
+	return names
+}
+
+// Watcher keeps the last known state of each domain between polls, and
+// the last analysis that changes are diffed against.
+type Watcher struct {
+	analyze func(domain string) (*analyzer.Result, error)
+	now     func() time.Time
+	polls   int
+	last    map[string]State
+	results map[string]*analyzer.Result
+}
+
+// NewWatcher returns a watcher that analyzes domains with a, which should
+// run at least Modules.
+func NewWatcher(a *analyzer.Analyzer) *Watcher {
+	return NewWatcherFunc(a.AnalyzeDomain)
+}
+
+// NewWatcherFunc returns a watcher that analyzes domains with analyze.
+func NewWatcherFunc(analyze func(domain string) (*analyzer.Result, error)) *Watcher {
+	return &Watcher{
+		analyze: analyze,
+		now:     time.Now,
+		last:    map[string]State{},
+		results: map[string]*analyzer.Result{},
+	}
+}
+
+// Restore sets the last known states, e.g. saved by an earlier process, so
+// the next poll reports changes against them instead of setting a new
+// baseline.
+func (w *Watcher) Restore(states []State) {
+	for _, s := range states {
+		w.last[s.Domain] = s
+	}
+}
+
+// States returns the last known state of every domain polled, by domain.
+func (w *Watcher) States() []State {
+	states := make([]State, 0, len(w.last))
+	for _, s := range w.last {
+		states = append(states, s)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Domain < states[j].Domain })
+	return states
+}
+
+// Poll analyzes every domain and compares it with the previous poll. A
+// field that cannot be determined this time (a failed lookup) keeps its
+// last known value rather than being reported as changed.
+func (w *Watcher) Poll(domains []string) *Result {
+	w.polls++
+	result := &Result{Poll: w.polls, CheckedAt: w.now(), analyses: map[string]*analyzer.Result{}}
+	for _, domain := range domains {
+		state := State{Domain: domain, Availability: analyzer.VerdictUnknown, Tokenization: unknown}
+		r, err := w.analyze(domain)
+		if err != nil {
+			state.Error = err.Error()
+		} else {
+			state = stateOf(r)
+			result.analyses[domain] = r
+		}
+
+		if old, ok := w.last[domain]; ok {
+			state = carry(old, state)
+			// A failed analysis carries every field, so it changes none.
+			// A restored state has no analysis to diff against; the whole
+			// new result then counts as changed and the states decide.
+			if r != nil {
+				result.Changes = append(result.Changes, diff(old, state, compare.Diff(w.results[domain], r))...)
+			}
+		}
+		if r != nil {
+			w.results[domain] = r
+		}
+		w.last[domain] = state
+		result.States = append(result.States, state)
+	}
+	return result
+}
+
+func stateOf(r *analyzer.Result) State {
+	s := State{Domain: r.Domain, Availability: r.Verdict(), Tokenization: unknown}
+	switch {
+	case r.BlockchainData != nil:
+		s.Expiry = r.BlockchainData.ExpiryDate
+	case r.WhoisData != nil && r.WhoisData.Error == "":
+		s.Expiry = r.WhoisData.ExpiryDate
+	}
+	if d := r.DomaData; d != nil && d.IsTokenized != nil {
+		s.Tokenization = NotTokenized
+		if *d.IsTokenized {
+			s.Tokenization = Tokenized
+		}
+	}
+	if len(r.SectionErrors) > 0 {
+		s.Error = fmt.Sprintf("%s: %s", r.SectionErrors[0].Section, r.SectionErrors[0].Error)
+	}
+	return s
+}
+
+// carry fills the fields of s that could not be determined with their
+// last known values.
+func carry(old, s State) State {
+	if s.Availability == analyzer.VerdictUnknown {
+		s.Availability = old.Availability
+	}
+	if s.Tokenization == unknown {
+		s.Tokenization = old.Tokenization
+	}
+	// An available domain has no expiry, so only a taken domain without
+	// one is missing data.
+	if s.Expiry == nil && s.Availability == analyzer.VerdictTaken {
+		s.Expiry = old.Expiry
+	}
+	return s
+}
+
+// diff reports the watched fields that compare.Diff found changed
+// between the analyses behind old and s. Each is reported with its value
+// in the states, which carry the last known values, so a field whose
+// lookup failed and came back unchanged is not reported.
+func diff(old, s State, resultChanges []compare.Change) []Change {
+	values := map[string][2]string{
+		FieldAvailability: {old.Availability, s.Availability},
+		FieldExpiry:       {formatDate(old.Expiry), formatDate(s.Expiry)},
+		FieldTokenization: {old.Tokenization, s.Tokenization},
+	}
+	var changes []Change
+	for _, w := range watched {
+		v := values[w.field]
+		if v[0] == v[1] {
+			continue
+		}
+		severity, ok := compare.Touching(resultChanges, w.paths...)
+		if !ok {
+			continue
+		}
+		changes = append(changes, Change{
+			Domain:   s.Domain,
+			Field:    w.field,
+			Old:      v[0],
+			New:      v[1],
+			Dropped:  w.field == FieldAvailability && v[0] == analyzer.VerdictTaken && v[1] == analyzer.VerdictAvailable,
+			Severity: severity,
+		})
+	}
+	return changes
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return "none"
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+// Alert posts a poll with changes as JSON to a webhook.
+func Alert(webhook string, result *Result) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send alert: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package watch
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/findings"
+	"d3-domain-tool/internal/doma"
+	"d3-domain-tool/internal/whois"
+)
+
+func TestPoll(t *testing.T) {
+	expiry := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	renewed := expiry.AddDate(1, 0, 0)
+	tokenized := true
+
+	// Each poll answers from the next entry.
+	polls := []func() (*analyzer.Result, error){
+		func() (*analyzer.Result, error) {
+			return &analyzer.Result{Domain: "a.com", WhoisData: &whois.Result{ExpiryDate: &expiry}}, nil
+		},
+		// A failed lookup keeps the last known state.
+		func() (*analyzer.Result, error) { return nil, fmt.Errorf("timeout") },
+		func() (*analyzer.Result, error) {
+			return &analyzer.Result{
+				Domain:    "a.com",
+				WhoisData: &whois.Result{ExpiryDate: &renewed},
+				DomaData:  &doma.Result{IsTokenized: &tokenized},
+			}, nil
+		},
+		func() (*analyzer.Result, error) {
+			return &analyzer.Result{Domain: "a.com", WhoisData: &whois.Result{Available: true}}, nil
+		},
+	}
+	n := 0
+	w := NewWatcher(analyzer.New())
+	w.analyze = func(string) (*analyzer.Result, error) {
+		n++
+		return polls[n-1]()
+	}
+
+	first := w.Poll([]string{"a.com"})
+	if len(first.Changes) != 0 || first.States[0].Availability != analyzer.VerdictTaken {
+		t.Fatalf("unexpected baseline %+v", first)
+	}
+	if second := w.Poll([]string{"a.com"}); len(second.Changes) != 0 || second.States[0].Error != "timeout" {
+		t.Errorf("a failed lookup should not be a change: %+v", second)
+	}
+
+	third := w.Poll([]string{"a.com"})
+	if len(third.Changes) != 2 ||
+		third.Changes[0].Field != FieldExpiry || third.Changes[0].Old != "2026-11-01" || third.Changes[0].New != "2027-11-01" ||
+		third.Changes[1].Field != FieldTokenization || third.Changes[1].New != Tokenized {
+		t.Errorf("unexpected changes %+v", third.Changes)
+	}
+
+	fourth := w.Poll([]string{"a.com"})
+	if dropped := fourth.Dropped(); len(dropped) != 1 || dropped[0] != "a.com" {
+		t.Errorf("expected a.com to be reported as dropped, got %+v", fourth.Changes)
+	}
+	if c := fourth.Changes[0]; c.Field != FieldAvailability || c.Severity != findings.Critical {
+		t.Errorf("a drop should be critical: %+v", c)
+	}
+}
+
+func TestPollRestored(t *testing.T) {
+	expiry := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	w := NewWatcherFunc(func(domain string) (*analyzer.Result, error) {
+		return &analyzer.Result{Domain: domain, WhoisData: &whois.Result{ExpiryDate: &expiry}}, nil
+	})
+	w.Restore([]State{
+		{Domain: "same.com", Availability: analyzer.VerdictTaken, Expiry: &expiry, Tokenization: unknown},
+		{Domain: "dropped.com", Availability: analyzer.VerdictAvailable, Tokenization: unknown},
+	})
+
+	result := w.Poll([]string{"same.com", "dropped.com"})
+	if len(result.Changes) != 2 {
+		t.Fatalf("expected availability and expiry changes of dropped.com only, got %+v", result.Changes)
+	}
+	for _, c := range result.Changes {
+		if c.Domain != "dropped.com" {
+			t.Errorf("unexpected change %+v", c)
+		}
+		if c.Field == FieldExpiry && c.Severity != findings.High {
+			t.Errorf("expiry change severity %s, want high", c.Severity)
+		}
+	}
+}
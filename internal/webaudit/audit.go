@@ -0,0 +1,265 @@
+package webaudit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRedirects bounds how far a redirect chain is followed.
+const maxRedirects = 10
+
+// Auditor inspects a live domain's HTTP behavior: the redirect chain from
+// plain HTTP, HSTS and other security headers.
+type Auditor struct {
+	client     *http.Client
+	preloadAPI string
+}
+
+type Result struct {
+	RedirectChain   []Hop  `json:"redirect_chain"`
+	FinalURL        string `json:"final_url,omitempty"`
+	UpgradesToHTTPS bool   `json:"upgrades_to_https"`
+	MixedRedirects  bool   `json:"mixed_redirects"`
+	HSTS            *HSTS  `json:"hsts,omitempty"`
+	// ApexHSTS is the header of the bare domain's own HTTPS response,
+	// before any redirect; it is the one preload requires.
+	ApexHSTS    *HSTS           `json:"apex_hsts,omitempty"`
+	Headers     map[string]bool `json:"security_headers"`
+	Certificate *Certificate    `json:"certificate,omitempty"`
+	Grade       string          `json:"grade"`
+	Findings    []string        `json:"findings,omitempty"`
+	CheckedAt   time.Time       `json:"checked_at"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// Hop is one response in the redirect chain.
+type Hop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Location   string `json:"location,omitempty"`
+}
+
+// Certificate is the leaf certificate the site presented over HTTPS.
+type Certificate struct {
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	SerialNumber string    `json:"serial_number"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+	DNSNames     []string  `json:"dns_names,omitempty"`
+	SHA256       string    `json:"sha256"`
+}
+
+// certificateOf returns the leaf certificate of an HTTPS response, or nil.
+func certificateOf(resp *http.Response) *Certificate {
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	leaf := resp.TLS.PeerCertificates[0]
+	sum := sha256.Sum256(leaf.Raw)
+	return &Certificate{
+		Subject:      leaf.Subject.String(),
+		Issuer:       leaf.Issuer.String(),
+		SerialNumber: leaf.SerialNumber.Text(16),
+		NotBefore:    leaf.NotBefore,
+		NotAfter:     leaf.NotAfter,
+		DNSNames:     leaf.DNSNames,
+		SHA256:       hex.EncodeToString(sum[:]),
+	}
+}
+
+// HSTS is a parsed Strict-Transport-Security header.
+type HSTS struct {
+	Raw               string `json:"raw"`
+	MaxAge            int    `json:"max_age"`
+	IncludeSubDomains bool   `json:"include_subdomains"`
+	Preload           bool   `json:"preload"`
+}
+
+// SecurityHeaders are the response headers that contribute to the grade.
+var SecurityHeaders = []string{
+	"Strict-Transport-Security",
+	"Content-Security-Policy",
+	"X-Frame-Options",
+	"X-Content-Type-Options",
+	"Referrer-Policy",
+	"Permissions-Policy",
+}
+
+func NewAuditor() *Auditor {
+	return &Auditor{
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		preloadAPI: DefaultPreloadAPI,
+	}
+}
+
+func (a *Auditor) Audit(domain string) (*Result, error) {
+	return a.AuditContext(context.Background(), domain)
+}
+
+// AuditContext is Audit with the requests bounded by ctx.
+func (a *Auditor) AuditContext(ctx context.Context, domain string) (*Result, error) {
+	result := &Result{
+		Headers:   make(map[string]bool),
+		CheckedAt: time.Now(),
+	}
+
+	final, err := a.followChain(ctx, "http://"+domain+"/", result)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.FinalURL = final.Request.URL.String()
+	result.UpgradesToHTTPS = final.Request.URL.Scheme == "https"
+
+	// Headers only count when served over HTTPS (HSTS is ignored over HTTP).
+	headers := final.Header
+	result.Certificate = certificateOf(final)
+	// The client does not follow redirects, so this is the bare domain's
+	// own response even when it redirects elsewhere.
+	if resp, err := a.get(ctx, "https://"+domain+"/"); err == nil {
+		resp.Body.Close()
+		if raw := resp.Header.Get("Strict-Transport-Security"); raw != "" {
+			result.ApexHSTS = ParseHSTS(raw)
+		}
+		if !result.UpgradesToHTTPS {
+			headers = resp.Header
+			result.Certificate = certificateOf(resp)
+		}
+	}
+
+	for _, name := range SecurityHeaders {
+		result.Headers[name] = headers.Get(name) != ""
+	}
+	if raw := headers.Get("Strict-Transport-Security"); raw != "" {
+		result.HSTS = ParseHSTS(raw)
+	}
+
+	result.Grade, result.Findings = grade(result)
+	return result, nil
+}
+
+// followChain requests start and follows redirects manually, recording each
+// hop. It returns the last response.
+func (a *Auditor) followChain(ctx context.Context, start string, result *Result) (*http.Response, error) {
+	current := start
+	sawHTTPS := false
+
+	for i := 0; i <= maxRedirects; i++ {
+		resp, err := a.get(ctx, current)
+		if err != nil {
+			if len(result.RedirectChain) == 0 {
+				return nil, fmt.Errorf("HTTP request failed: %v", err)
+			}
+			return nil, fmt.Errorf("redirect to %s failed: %v", current, err)
+		}
+		resp.Body.Close()
+
+		hop := Hop{URL: current, StatusCode: resp.StatusCode}
+		if resp.Request.URL.Scheme == "https" {
+			sawHTTPS = true
+		} else if sawHTTPS {
+			// Downgraded back to HTTP after reaching HTTPS.
+			result.MixedRedirects = true
+		}
+
+		location := resp.Header.Get("Location")
+		if resp.StatusCode < 300 || resp.StatusCode >= 400 || location == "" {
+			result.RedirectChain = append(result.RedirectChain, hop)
+			return resp, nil
+		}
+
+		next, err := resp.Request.URL.Parse(location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redirect location %q: %v", location, err)
+		}
+		hop.Location = next.String()
+		result.RedirectChain = append(result.RedirectChain, hop)
+		current = next.String()
+	}
+
+	return nil, fmt.Errorf("too many redirects (more than %d)", maxRedirects)
+}
+
+func (a *Auditor) get(ctx context.Context, target string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	return a.client.Do(req)
+}
+
+// ParseHSTS parses a Strict-Transport-Security header value.
+func ParseHSTS(raw string) *HSTS {
+	hsts := &HSTS{Raw: raw}
+	for _, directive := range strings.Split(raw, ";") {
+		directive = strings.TrimSpace(directive)
+		name, value, _ := strings.Cut(directive, "=")
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "max-age":
+			if n, err := strconv.Atoi(strings.Trim(strings.TrimSpace(value), `"`)); err == nil {
+				hsts.MaxAge = n
+			}
+		case "includesubdomains":
+			hsts.IncludeSubDomains = true
+		case "preload":
+			hsts.Preload = true
+		}
+	}
+	return hsts
+}
+
+// grade scores the audit on a 100 point scale and maps it to a letter.
+func grade(r *Result) (string, []string) {
+	score := 100
+	var findings []string
+
+	if !r.UpgradesToHTTPS {
+		score -= 40
+		findings = append(findings, "HTTP is not redirected to HTTPS")
+	}
+	if r.MixedRedirects {
+		score -= 15
+		findings = append(findings, "Redirect chain downgrades from HTTPS back to HTTP")
+	}
+
+	switch {
+	case r.HSTS == nil:
+		score -= 20
+		findings = append(findings, "No HSTS header")
+	case r.HSTS.MaxAge < 15768000:
+		score -= 10
+		findings = append(findings, fmt.Sprintf("HSTS max-age is short (%ds, recommend at least 6 months)", r.HSTS.MaxAge))
+	}
+
+	for _, name := range SecurityHeaders[1:] {
+		if !r.Headers[name] {
+			score -= 5
+			findings = append(findings, "Missing "+name)
+		}
+	}
+
+	switch {
+	case score >= 90:
+		return "A", findings
+	case score >= 75:
+		return "B", findings
+	case score >= 60:
+		return "C", findings
+	case score >= 40:
+		return "D", findings
+	default:
+		return "F", findings
+	}
+}
@@ -0,0 +1,112 @@
+package webaudit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseHSTS(t *testing.T) {
+	hsts := ParseHSTS(`max-age="31536000"; includeSubDomains; preload`)
+	if hsts.MaxAge != 31536000 || !hsts.IncludeSubDomains || !hsts.Preload {
+		t.Errorf("unexpected parse result: %+v", hsts)
+	}
+
+	hsts = ParseHSTS("max-age=300")
+	if hsts.MaxAge != 300 || hsts.IncludeSubDomains || hsts.Preload {
+		t.Errorf("unexpected parse result: %+v", hsts)
+	}
+}
+
+func TestGrade(t *testing.T) {
+	full := &Result{
+		UpgradesToHTTPS: true,
+		HSTS:            &HSTS{MaxAge: 63072000},
+		Headers:         make(map[string]bool),
+	}
+	for _, name := range SecurityHeaders {
+		full.Headers[name] = true
+	}
+	if g, findings := grade(full); g != "A" || len(findings) != 0 {
+		t.Errorf("expected A with no findings, got %s %v", g, findings)
+	}
+
+	bare := &Result{Headers: make(map[string]bool)}
+	if g, _ := grade(bare); g != "F" {
+		t.Errorf("expected F for plain HTTP with no headers, got %s", g)
+	}
+}
+
+func TestPreloadProblems(t *testing.T) {
+	ready := &Result{
+		UpgradesToHTTPS: true,
+		RedirectChain: []Hop{
+			{URL: "http://example.com/", StatusCode: 301, Location: "https://example.com/"},
+			{URL: "https://example.com/", StatusCode: 200},
+		},
+		ApexHSTS: &HSTS{MaxAge: 63072000, IncludeSubDomains: true, Preload: true},
+	}
+	if problems := PreloadProblems(ready); len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+
+	weak := &Result{
+		UpgradesToHTTPS: true,
+		ApexHSTS:        &HSTS{MaxAge: 300},
+	}
+	if problems := PreloadProblems(weak); len(problems) != 3 {
+		t.Errorf("expected 3 problems, got %v", problems)
+	}
+
+	// A header only on the page the apex redirects to does not count.
+	elsewhere := &Result{
+		UpgradesToHTTPS: true,
+		HSTS:            ready.ApexHSTS,
+	}
+	if problems := PreloadProblems(elsewhere); len(problems) != 1 || !strings.Contains(problems[0], "bare domain") {
+		t.Errorf("expected the apex's missing header, got %v", problems)
+	}
+
+	if problems := PreloadProblems(nil); len(problems) != 1 {
+		t.Errorf("expected unreachable problem, got %v", problems)
+	}
+
+	for _, tc := range []struct {
+		location string
+		ok       bool
+	}{
+		{"https://example.com/", true},
+		{"https://EXAMPLE.com:443/login", true},
+		{"https://www.example.com/", false},
+		{"http://example.com/secure", false},
+		{"/secure", false},
+		{"//example.com/", false},
+	} {
+		r := *ready
+		r.RedirectChain = []Hop{
+			{URL: "http://example.com/", StatusCode: 301, Location: tc.location},
+			{URL: "https://www.example.com/", StatusCode: 200},
+		}
+		if problems := PreloadProblems(&r); (len(problems) == 0) != tc.ok {
+			t.Errorf("first redirect to %s: problems %v", tc.location, problems)
+		}
+	}
+}
+
+func TestCertificateOf(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	cert := certificateOf(resp)
+	if cert == nil || len(cert.SHA256) != 64 || cert.SerialNumber == "" || cert.NotAfter.IsZero() {
+		t.Errorf("certificate = %+v", cert)
+	}
+	if certificateOf(&http.Response{}) != nil {
+		t.Error("certificate of a plain HTTP response")
+	}
+}
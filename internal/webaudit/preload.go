@@ -0,0 +1,136 @@
+package webaudit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Chrome's preload submission requirements (https://hstspreload.org).
+const (
+	PreloadMinMaxAge = 31536000 // 1 year
+)
+
+// DefaultPreloadAPI is the hstspreload.org status endpoint.
+const DefaultPreloadAPI = "https://hstspreload.org/api/v2/status"
+
+// PreloadResult reports HSTS preload list membership and whether the
+// domain's current configuration satisfies the submission requirements.
+type PreloadResult struct {
+	// Status is the list status from hstspreload.org: preloaded, pending,
+	// rejected, removed or unknown.
+	Status    string    `json:"status"`
+	Preloaded bool      `json:"preloaded"`
+	Eligible  bool      `json:"eligible"`
+	Problems  []string  `json:"problems,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// CheckPreload looks the domain up on the preload list and evaluates its
+// eligibility. audit may be nil, in which case a web audit is performed.
+func (a *Auditor) CheckPreload(domain string, audit *Result) (*PreloadResult, error) {
+	return a.CheckPreloadContext(context.Background(), domain, audit)
+}
+
+// CheckPreloadContext is CheckPreload with the requests bounded by ctx.
+func (a *Auditor) CheckPreloadContext(ctx context.Context, domain string, audit *Result) (*PreloadResult, error) {
+	result := &PreloadResult{
+		Status:    "unknown",
+		CheckedAt: time.Now(),
+	}
+
+	status, err := a.preloadStatus(ctx, domain)
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Status = status
+		result.Preloaded = status == "preloaded"
+	}
+
+	if audit == nil {
+		audit, _ = a.AuditContext(ctx, domain)
+	}
+	result.Problems = PreloadProblems(audit)
+	result.Eligible = len(result.Problems) == 0
+
+	if result.Preloaded {
+		result.Note = "Browsers will only connect to this domain and all of its subdomains over HTTPS; " +
+			"a new owner must serve valid HTTPS everywhere or request removal (which takes months to ship)"
+	}
+
+	return result, nil
+}
+
+func (a *Auditor) preloadStatus(ctx context.Context, domain string) (string, error) {
+	endpoint := a.preloadAPI + "?domain=" + url.QueryEscape(domain)
+	resp, err := a.get(ctx, endpoint)
+	if err != nil {
+		return "", fmt.Errorf("preload list lookup failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("preload list lookup returned HTTP %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to parse preload list response: %v", err)
+	}
+	if body.Status == "" {
+		return "unknown", nil
+	}
+	return strings.ToLower(body.Status), nil
+}
+
+// PreloadProblems lists the preload requirements the audited configuration
+// does not meet. An empty list means the domain can be submitted.
+func PreloadProblems(audit *Result) []string {
+	if audit == nil || audit.Error != "" {
+		return []string{"Site is not reachable over HTTP/HTTPS"}
+	}
+
+	var problems []string
+	if !audit.UpgradesToHTTPS {
+		problems = append(problems, "HTTP must redirect to HTTPS")
+	} else if len(audit.RedirectChain) > 1 {
+		// The first redirect must stay on the same host so the HSTS header
+		// is set for the apex before moving elsewhere.
+		// A relative Location resolves against the audited URL, so it keeps
+		// the host.
+		first := audit.RedirectChain[0]
+		base, err := url.Parse(first.URL)
+		if err == nil {
+			var u *url.URL
+			if u, err = base.Parse(first.Location); err == nil && (u.Scheme != "https" || !strings.EqualFold(u.Hostname(), base.Hostname())) {
+				problems = append(problems, "First redirect must go to HTTPS on the same host")
+			}
+		}
+	}
+
+	// The header must be on the bare domain's HTTPS response, not only on
+	// wherever it redirects to.
+	hsts := audit.ApexHSTS
+	if hsts == nil {
+		problems = append(problems, "Strict-Transport-Security header is missing from the bare domain's HTTPS response")
+		return problems
+	}
+	if hsts.MaxAge < PreloadMinMaxAge {
+		problems = append(problems, fmt.Sprintf("max-age must be at least %d (is %d)", PreloadMinMaxAge, hsts.MaxAge))
+	}
+	if !hsts.IncludeSubDomains {
+		problems = append(problems, "includeSubDomains directive is missing")
+	}
+	if !hsts.Preload {
+		problems = append(problems, "preload directive is missing")
+	}
+	return problems
+}
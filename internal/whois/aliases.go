@@ -0,0 +1,95 @@
+package whois
+
+import (
+	"regexp"
+	"strings"
+)
+
+// fieldAliases maps localized or registry-specific field names to the
+// English keys parseWhoisData understands, keyed by TLD.
+var fieldAliases = map[string]map[string]string{
+	".jp": {
+		// JPRS English and Japanese responses use "[Field] value".
+		"created on":   "creation date",
+		"expires on":   "expiry date",
+		"last updated": "updated date",
+		"登録年月日":        "creation date",
+		"有効期限":         "expiry date",
+		"最終更新":         "updated date",
+		"ネームサーバ":       "name server",
+		"状態":           "status",
+	},
+	".kr": {
+		"registered date":   "creation date",
+		"expiration date":   "expiry date",
+		"last updated date": "updated date",
+		"authorized agency": "registrar",
+		"host name":         "name server",
+		"등록일":               "creation date",
+		"사용 종료일":            "expiry date",
+		"최근 정보 변경일":         "updated date",
+		"등록대행자":             "registrar",
+		"1차 네임서버 호스트이름":     "name server",
+		"2차 네임서버 호스트이름":     "name server",
+		"도메인 상태":            "status",
+	},
+	".de": {
+		"nserver": "name server",
+		"changed": "updated date",
+	},
+	".fr": {
+		"nserver":     "name server",
+		"last-update": "updated date",
+		"eppstatus":   "status",
+	},
+	".uk": {
+		"registered on":       "creation date",
+		"name servers":        "name server",
+		"registration status": "status",
+	},
+	".br": {
+		"nserver": "name server",
+		"expires": "expiry date",
+		"changed": "updated date",
+	},
+	".it": {
+		"expire date": "expiry date",
+		"last update": "updated date",
+	},
+	".cn": {
+		"sponsoring registrar": "registrar",
+	},
+}
+
+// listPrefix matches the "a. " item markers JPRS puts before field names.
+var listPrefix = regexp.MustCompile(`^[a-z]\.\s*`)
+
+// splitField extracts a field name and value from a WHOIS line, accepting
+// both "Field: value" and the bracketed "[Field] value" layout.
+func splitField(line string) (string, string, bool) {
+	line = listPrefix.ReplaceAllString(line, "")
+
+	if strings.HasPrefix(line, "[") {
+		end := strings.Index(line, "]")
+		if end < 0 {
+			return "", "", false
+		}
+		return strings.TrimSpace(line[1:end]), strings.TrimSpace(line[end+1:]), true
+	}
+
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), true
+}
+
+// canonicalField returns the English key for a field name, translating
+// registry-specific aliases for the TLD.
+func canonicalField(tld, key string) string {
+	key = strings.ToLower(key)
+	if alias, ok := fieldAliases[tld][key]; ok {
+		return alias
+	}
+	return key
+}
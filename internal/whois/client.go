@@ -2,14 +2,36 @@ package whois
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 	"time"
+
+	"d3-domain-tool/internal/proxy"
+	"d3-domain-tool/internal/ratelimit"
 )
 
 type Client struct {
 	timeout time.Duration
+	limiter *ratelimit.Scheduler
+	query   QueryFunc
+	// dial opens the port-43 connection, through a proxy when one is set.
+	dial func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// QueryFunc sends a query to a WHOIS server and returns the raw response.
+// It gives up when ctx is done.
+type QueryFunc func(ctx context.Context, server, domain string) (string, error)
+
+// QueryDomain returns the domain a WHOIS query is about: its last field,
+// since some servers take flags before the name, e.g. "domain example.com".
+func QueryDomain(query string) string {
+	if fields := strings.Fields(query); len(fields) > 0 {
+		return strings.ToLower(fields[len(fields)-1])
+	}
+	return ""
 }
 
 type Result struct {
@@ -22,16 +44,46 @@ type Result struct {
 	UpdatedDate     *time.Time `json:"updated_date,omitempty"`
 	CheckedAt       time.Time  `json:"checked_at"`
 	RawData         string     `json:"raw_data,omitempty"`
+	// Warnings are data quality issues in an answered query, such as a date
+	// in an unknown format; unlike Error they leave the result usable.
+	Warnings        []string   `json:"warnings,omitempty"`
 	Error           string     `json:"error,omitempty"`
 }
 
 func NewClient() *Client {
-	return &Client{
+	c := &Client{
 		timeout: 10 * time.Second,
+		limiter: ratelimit.Default,
+		dial:    proxy.Default.DialContext,
 	}
+	c.query = c.queryWhoisServer
+	return c
+}
+
+// WrapQuery wraps the port-43 query, e.g. to record or replay responses.
+func (c *Client) WrapQuery(wrap func(QueryFunc) QueryFunc) {
+	c.query = wrap(c.query)
+}
+
+// SetTimeout bounds each port-43 query.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// WithTimeout returns a copy of c whose port-43 queries are bounded by
+// timeout, sharing its rate limiter and query wrappers.
+func (c *Client) WithTimeout(timeout time.Duration) *Client {
+	copy := *c
+	copy.timeout = timeout
+	return &copy
 }
 
 func (c *Client) Lookup(domain string) (*Result, error) {
+	return c.LookupContext(context.Background(), domain)
+}
+
+// LookupContext is Lookup bounded by ctx as well as the client's timeout.
+func (c *Client) LookupContext(ctx context.Context, domain string) (*Result, error) {
 	result := &Result{
 		CheckedAt: time.Now(),
 	}
@@ -42,45 +94,97 @@ func (c *Client) Lookup(domain string) (*Result, error) {
 		return result, nil
 	}
 
-	rawData, err := c.queryWhoisServer(whoisServer, domain)
+	rawData, err := c.query(ctx, whoisServer, domain)
 	if err != nil {
 		result.Error = err.Error()
 		return result, nil
 	}
 
 	result.RawData = rawData
-	c.parseWhoisData(rawData, result)
+	c.parseWhoisData(extractTLD(domain), rawData, result)
 
 	return result, nil
 }
 
+// Parse extracts structured fields from a raw WHOIS response captured
+// earlier, without contacting any server.
+func (c *Client) Parse(domain, rawData string) *Result {
+	result := &Result{
+		CheckedAt: time.Now(),
+		RawData:   rawData,
+	}
+	c.parseWhoisData(extractTLD(domain), rawData, result)
+	return result
+}
+
+// Server returns the WHOIS server queried for domain, or "" when the TLD has
+// none configured.
+func (c *Client) Server(domain string) string {
+	return c.getWhoisServer(domain)
+}
+
+// whoisServers are the port-43 servers of the TLDs the client knows.
+var whoisServers = map[string]string{
+	".com":  "whois.verisign-grs.com",
+	".net":  "whois.verisign-grs.com",
+	".org":  "whois.pir.org",
+	".info": "whois.afilias.net",
+	".biz":  "whois.neulevel.biz",
+	".name": "whois.nic.name",
+	".io":   "whois.nic.io",
+	".co":   "whois.nic.co",
+	".me":   "whois.nic.me",
+	".tv":   "whois.nic.tv",
+	".cc":   "ccwhois.verisign-grs.com",
+	".ws":   "whois.website.ws",
+	".de":   "whois.denic.de",
+	".jp":   "whois.jprs.jp",
+	".kr":   "whois.kr",
+	".uk":   "whois.nic.uk",
+	".fr":   "whois.nic.fr",
+	".nl":   "whois.domain-registry.nl",
+	".eu":   "whois.eu",
+	".be":   "whois.dns.be",
+	".it":   "whois.nic.it",
+	".ch":   "whois.nic.ch",
+	".au":   "whois.auda.org.au",
+	".br":   "whois.registro.br",
+	".cn":   "whois.cnnic.cn",
+	".se":   "whois.iis.se",
+}
+
+// TLDs returns the TLDs with a known WHOIS server, sorted, e.g. "com".
+func TLDs() []string {
+	tlds := make([]string, 0, len(whoisServers))
+	for tld := range whoisServers {
+		tlds = append(tlds, strings.TrimPrefix(tld, "."))
+	}
+	sort.Strings(tlds)
+	return tlds
+}
+
 func (c *Client) getWhoisServer(domain string) string {
-	tld := extractTLD(domain)
-	
-	whoisServers := map[string]string{
-		".com":  "whois.verisign-grs.com",
-		".net":  "whois.verisign-grs.com",
-		".org":  "whois.pir.org",
-		".info": "whois.afilias.net",
-		".biz":  "whois.neulevel.biz",
-		".name": "whois.nic.name",
-		".io":   "whois.nic.io",
-		".co":   "whois.nic.co",
-		".me":   "whois.nic.me",
-		".tv":   "whois.nic.tv",
-		".cc":   "ccwhois.verisign-grs.com",
-		".ws":   "whois.website.ws",
-	}
-
-	return whoisServers[tld]
-}
-
-func (c *Client) queryWhoisServer(server, domain string) (string, error) {
-	conn, err := net.DialTimeout("tcp", server+":43", c.timeout)
+	return whoisServers[extractTLD(domain)]
+}
+
+func (c *Client) queryWhoisServer(ctx context.Context, server, domain string) (string, error) {
+	if err := c.limiter.Wait(ctx, server); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	conn, err := c.dial(ctx, "tcp", server+":43")
 	if err != nil {
 		return "", fmt.Errorf("failed to connect to WHOIS server: %v", err)
 	}
 	defer conn.Close()
+	// Slow servers that accept the connection and then trickle (or never
+	// send) the response are cut off at the same deadline.
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
 
 	_, err = conn.Write([]byte(domain + "\r\n"))
 	if err != nil {
@@ -100,50 +204,59 @@ func (c *Client) queryWhoisServer(server, domain string) (string, error) {
 	return response.String(), nil
 }
 
-func (c *Client) parseWhoisData(rawData string, result *Result) {
+func (c *Client) parseWhoisData(tld, rawData string, result *Result) {
 	lines := strings.Split(rawData, "\n")
 	
+	// Nominet (.uk) puts values on the lines below their field name, e.g.
+	// "Registrar:" and then the registrar; pending is that field.
+	pending := ""
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
 		if line == "" {
+			pending = ""
 			continue
 		}
 
-		// Check for "No match" or similar indicators of availability
-		if strings.Contains(strings.ToLower(line), "no match") ||
-		   strings.Contains(strings.ToLower(line), "not found") ||
-		   strings.Contains(strings.ToLower(line), "no data found") {
+		// Check for "No match" or the registry's own availability phrase
+		if isAvailableLine(tld, line) {
 			result.Available = true
 			return
 		}
 
 		// Parse common WHOIS fields
-		if strings.Contains(line, ":") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) != 2 {
-				continue
-			}
-
-			key := strings.TrimSpace(strings.ToLower(parts[0]))
-			value := strings.TrimSpace(parts[1])
+		key, value, ok := splitField(line)
+		if ok && value == "" {
+			pending = key
+			continue
+		}
+		if !ok && pending != "" {
+			key, value, ok = pending, line, true
+		}
+		if ok {
+			key = canonicalField(tld, key)
 
 			switch key {
 			case "registrar":
+				// Nominet appends the registrar's tag: "Name [Tag = TAG]".
+				if i := strings.Index(value, " [Tag = "); i > 0 {
+					value = value[:i]
+				}
 				result.Registrar = value
 			case "creation date", "created", "registration time":
-				if date, err := parseDate(value); err == nil {
+				if date, ok := parseDateField(value, result); ok {
 					result.RegistrationDate = &date
 				}
 			case "expiry date", "expires", "expiration time":
-				if date, err := parseDate(value); err == nil {
+				if date, ok := parseDateField(value, result); ok {
 					result.ExpiryDate = &date
 				}
 			case "updated date", "last modified", "last updated":
-				if date, err := parseDate(value); err == nil {
+				if date, ok := parseDateField(value, result); ok {
 					result.UpdatedDate = &date
 				}
 			case "name server":
-				result.NameServers = append(result.NameServers, value)
+				// Some registries list glue addresses after the host.
+				result.NameServers = append(result.NameServers, strings.Fields(value)[0])
 			case "status", "domain status":
 				result.Status = append(result.Status, value)
 			}
@@ -153,7 +266,22 @@ func (c *Client) parseWhoisData(rawData string, result *Result) {
 	// If we parsed data, domain is not available
 	if result.Registrar != "" || result.RegistrationDate != nil {
 		result.Available = false
+		if result.ExpiryDate == nil && len(result.Warnings) == 0 {
+			result.Warnings = append(result.Warnings, "WHOIS expiry date not found")
+		}
+	}
+}
+
+// parseDateField parses the value of a date field. A date in an unknown
+// format is noted as a warning on result, so it is not mistaken for a
+// missing one.
+func parseDateField(value string, result *Result) (time.Time, bool) {
+	date, err := parseDate(value)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("WHOIS date unparsed: %q", value))
+		return time.Time{}, false
 	}
+	return date, true
 }
 
 func parseDate(dateStr string) (time.Time, error) {
@@ -163,6 +291,15 @@ func parseDate(dateStr string) (time.Time, error) {
 		"2006-01-02",
 		"02-Jan-2006",
 		"2006/01/02",
+		"2006/01/02 15:04:05",
+		"2006. 01. 02.",
+		"20060102",
+	}
+
+	// JPRS appends the time zone in parentheses, e.g. "(JST)", and
+	// registro.br a ticket number, e.g. "20000101 #12345".
+	if i := strings.IndexAny(dateStr, "(#"); i > 0 {
+		dateStr = strings.TrimSpace(dateStr[:i])
 	}
 
 	for _, format := range dateFormats {
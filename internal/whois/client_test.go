@@ -0,0 +1,172 @@
+package whois
+
+import "testing"
+
+func TestParseWhoisData_Availability(t *testing.T) {
+	tests := []struct {
+		tld       string
+		raw       string
+		available bool
+	}{
+		{".com", "No match for \"EXAMPLE-FREE.COM\".\n", true},
+		{".de", "Domain: example-free.de\nStatus: free\n", true},
+		{".de", "Domain: example.de\nNserver: ns1.example.de\nStatus: connect\n", false},
+		{".jp", "[ JPRS database provides information on network administration. ]\n\nNo match!!\n", true},
+		{".jp", "[ JPRSデータベース ]\n\n該当するデータがありません。\n", true},
+		{".nl", "example-free.nl is free\n", true},
+		{".eu", "Domain: example-free.eu\nStatus: AVAILABLE\n", true},
+		{".io", "Domain example-free.io is available for registration\n", true},
+		{".com", "Registrar: Example Registrar, Inc.\nCreation Date: 1995-08-14T04:00:00Z\n", false},
+	}
+
+	c := NewClient()
+	for _, tt := range tests {
+		result := &Result{}
+		c.parseWhoisData(tt.tld, tt.raw, result)
+		if result.Available != tt.available {
+			t.Errorf("%s %q: expected available=%v", tt.tld, tt.raw, tt.available)
+		}
+	}
+}
+
+func TestParseWhoisData_LocalizedFields(t *testing.T) {
+	jp := `[ JPRS database provides information on network administration. ]
+
+Domain Information: [ドメイン情報]
+a. [ドメイン名]                 EXAMPLE.JP
+p. [ネームサーバ]               ns1.example.jp
+p. [ネームサーバ]               ns2.example.jp
+[状態]                          Active
+[登録年月日]                    2001/01/01
+[有効期限]                      2026/01/31
+[最終更新]                      2025/02/01 01:05:09 (JST)
+`
+	kr := `도메인이름                  : example.kr
+등록일                      : 2007. 03. 02.
+최근 정보 변경일            : 2024. 03. 07.
+사용 종료일                 : 2026. 03. 02.
+등록대행자                  : (주)가비아(http://www.gabia.co.kr)
+1차 네임서버 호스트이름     : ns.example.kr
+`
+
+	c := NewClient()
+
+	result := &Result{}
+	c.parseWhoisData(".jp", jp, result)
+	if result.Available {
+		t.Fatal(".jp: expected registered domain")
+	}
+	if result.RegistrationDate == nil || result.RegistrationDate.Year() != 2001 {
+		t.Errorf(".jp: registration date not parsed: %v", result.RegistrationDate)
+	}
+	if result.ExpiryDate == nil || result.ExpiryDate.Year() != 2026 {
+		t.Errorf(".jp: expiry date not parsed: %v", result.ExpiryDate)
+	}
+	if result.UpdatedDate == nil {
+		t.Error(".jp: updated date not parsed")
+	}
+	if len(result.NameServers) != 2 || len(result.Status) != 1 {
+		t.Errorf(".jp: unexpected name servers %v / status %v", result.NameServers, result.Status)
+	}
+
+	result = &Result{}
+	c.parseWhoisData(".kr", kr, result)
+	if result.RegistrationDate == nil || result.RegistrationDate.Month() != 3 {
+		t.Errorf(".kr: registration date not parsed: %v", result.RegistrationDate)
+	}
+	if result.ExpiryDate == nil || result.ExpiryDate.Year() != 2026 {
+		t.Errorf(".kr: expiry date not parsed: %v", result.ExpiryDate)
+	}
+	if result.Registrar == "" {
+		t.Error(".kr: registrar not parsed")
+	}
+	if len(result.NameServers) != 1 || result.NameServers[0] != "ns.example.kr" {
+		t.Errorf(".kr: unexpected name servers %v", result.NameServers)
+	}
+}
+
+func TestParseWhoisData_Warnings(t *testing.T) {
+	c := NewClient()
+
+	result := &Result{}
+	c.parseWhoisData(".de", "Registrar: Example GmbH\nCreation Date: 2. Jan 2024\nExpiry Date: 2030-01-02\n", result)
+	if len(result.Warnings) != 1 || result.Warnings[0] != `WHOIS date unparsed: "2. Jan 2024"` {
+		t.Errorf("warnings = %q", result.Warnings)
+	}
+	if result.ExpiryDate == nil {
+		t.Error("expiry date not parsed next to an unparsed one")
+	}
+
+	result = &Result{}
+	c.parseWhoisData(".com", "Registrar: Example Registrar, Inc.\nCreation Date: 1995-08-14T04:00:00Z\n", result)
+	if len(result.Warnings) != 1 || result.Warnings[0] != "WHOIS expiry date not found" {
+		t.Errorf("warnings = %q", result.Warnings)
+	}
+
+	result = &Result{}
+	c.parseWhoisData(".com", "No match for \"EXAMPLE-FREE.COM\".\n", result)
+	if result.Warnings != nil {
+		t.Errorf("available domain warned: %q", result.Warnings)
+	}
+}
+
+func TestParseWhoisData_SecondLevel(t *testing.T) {
+	nominet := `
+    Domain name:
+        example.co.uk
+
+    Registrar:
+        Example Registrar Ltd [Tag = EXAMPLE]
+        URL: https://registrar.example
+
+    Relevant dates:
+        Registered on: 26-Nov-1996
+        Expiry date:  26-Nov-2026
+        Last updated:  26-Oct-2024
+
+    Registration status:
+        Registered until expiry date.
+
+    Name servers:
+        ns1.example.net
+        ns2.example.net       192.0.2.53
+`
+	registroBR := `domain:      example.com.br
+owner:       Example Ltda
+nserver:     a.dns.br
+created:     20000101 #12345
+changed:     20240315
+expires:     20270101
+status:      published
+`
+
+	c := NewClient()
+
+	result := &Result{}
+	c.parseWhoisData(extractTLD("example.co.uk"), nominet, result)
+	if result.Available || result.Registrar != "Example Registrar Ltd" {
+		t.Errorf(".co.uk: registrar = %q, available = %v", result.Registrar, result.Available)
+	}
+	if result.RegistrationDate == nil || result.RegistrationDate.Year() != 1996 ||
+		result.ExpiryDate == nil || result.ExpiryDate.Year() != 2026 || result.UpdatedDate == nil {
+		t.Errorf(".co.uk: dates %v / %v / %v", result.RegistrationDate, result.ExpiryDate, result.UpdatedDate)
+	}
+	if len(result.NameServers) != 2 || result.NameServers[1] != "ns2.example.net" {
+		t.Errorf(".co.uk: name servers %q", result.NameServers)
+	}
+	if len(result.Status) != 1 || result.Warnings != nil {
+		t.Errorf(".co.uk: status %q, warnings %q", result.Status, result.Warnings)
+	}
+
+	result = &Result{}
+	c.parseWhoisData(extractTLD("example.com.br"), registroBR, result)
+	if result.Available || result.RegistrationDate == nil || result.RegistrationDate.Year() != 2000 {
+		t.Errorf(".com.br: registration date %v, available = %v", result.RegistrationDate, result.Available)
+	}
+	if result.ExpiryDate == nil || result.ExpiryDate.Year() != 2027 || result.Warnings != nil {
+		t.Errorf(".com.br: expiry date %v, warnings %q", result.ExpiryDate, result.Warnings)
+	}
+	if c.Server("example.com.br") != "whois.registro.br" || c.Server("example.co.uk") != "whois.nic.uk" {
+		t.Error("second-level ccTLDs not sent to their registry")
+	}
+}
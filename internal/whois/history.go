@@ -0,0 +1,205 @@
+package whois
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultHistoryAPI is the WhoisXML API WHOIS History endpoint.
+const DefaultHistoryAPI = "https://whois-history.whoisxmlapi.com/api/v1"
+
+// HistoryClient fetches archived WHOIS snapshots from a historical-WHOIS
+// provider. It is only used when an API key is configured.
+type HistoryClient struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+// History summarizes how a domain's registration changed over time.
+type History struct {
+	Provider         string     `json:"provider"`
+	Snapshots        []Snapshot `json:"snapshots"`
+	Registrants      []string   `json:"registrants,omitempty"`
+	Registrars       []string   `json:"registrars,omitempty"`
+	OwnershipChanges int        `json:"ownership_changes"`
+	RegistrarChanges int        `json:"registrar_changes"`
+	CheckedAt        time.Time  `json:"checked_at"`
+	Error            string     `json:"error,omitempty"`
+}
+
+// Snapshot is one archived WHOIS record.
+type Snapshot struct {
+	Date       time.Time `json:"date"`
+	Registrar  string    `json:"registrar,omitempty"`
+	Registrant string    `json:"registrant,omitempty"`
+	Country    string    `json:"country,omitempty"`
+}
+
+func NewHistoryClient(apiKey string) *HistoryClient {
+	return &HistoryClient{
+		endpoint: DefaultHistoryAPI,
+		apiKey:   apiKey,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Endpoint returns the history API URL.
+func (h *HistoryClient) Endpoint() string {
+	return h.endpoint
+}
+
+func (h *HistoryClient) Lookup(domain string) (*History, error) {
+	return h.LookupContext(context.Background(), domain)
+}
+
+// LookupContext is Lookup with the API request bounded by ctx.
+func (h *HistoryClient) LookupContext(ctx context.Context, domain string) (*History, error) {
+	result := &History{
+		Provider:  "WhoisXML API",
+		CheckedAt: time.Now(),
+	}
+
+	snapshots, err := h.fetch(ctx, domain)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Date.Before(snapshots[j].Date)
+	})
+	result.Snapshots = snapshots
+
+	var lastRegistrant, lastRegistrar string
+	for _, s := range snapshots {
+		if registrant := normalizeParty(s.Registrant); registrant != "" {
+			if lastRegistrant != "" && registrant != lastRegistrant {
+				result.OwnershipChanges++
+			}
+			if !containsFold(result.Registrants, s.Registrant) {
+				result.Registrants = append(result.Registrants, s.Registrant)
+			}
+			lastRegistrant = registrant
+		}
+		if registrar := normalizeParty(s.Registrar); registrar != "" {
+			if lastRegistrar != "" && registrar != lastRegistrar {
+				result.RegistrarChanges++
+			}
+			if !containsFold(result.Registrars, s.Registrar) {
+				result.Registrars = append(result.Registrars, s.Registrar)
+			}
+			lastRegistrar = registrar
+		}
+	}
+
+	return result, nil
+}
+
+func (h *HistoryClient) fetch(ctx context.Context, domain string) ([]Snapshot, error) {
+	endpoint := h.endpoint + "?apiKey=" + url.QueryEscape(h.apiKey) +
+		"&domainName=" + url.QueryEscape(domain) + "&mode=purchase&outputFormat=JSON"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WHOIS history endpoint")
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		// The URL carries the API key; report the failure without it.
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("WHOIS history lookup failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("WHOIS history lookup returned HTTP %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Records []struct {
+			RegistrarName string `json:"registrarName"`
+			UpdatedDate   string `json:"updatedDateISO8601"`
+			CreatedDate   string `json:"createdDateISO8601"`
+			Audit         struct {
+				CreatedDate string `json:"createdDate"`
+			} `json:"audit"`
+			RegistrantContact struct {
+				Name         string `json:"name"`
+				Organization string `json:"organization"`
+				Country      string `json:"country"`
+			} `json:"registrantContact"`
+		} `json:"records"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to parse WHOIS history response: %v", err)
+	}
+
+	snapshots := make([]Snapshot, 0, len(body.Records))
+	for _, rec := range body.Records {
+		registrant := rec.RegistrantContact.Organization
+		if registrant == "" {
+			registrant = rec.RegistrantContact.Name
+		}
+		snapshots = append(snapshots, Snapshot{
+			Date:       firstDate(rec.Audit.CreatedDate, rec.UpdatedDate, rec.CreatedDate),
+			Registrar:  strings.TrimSpace(rec.RegistrarName),
+			Registrant: strings.TrimSpace(registrant),
+			Country:    rec.RegistrantContact.Country,
+		})
+	}
+	return snapshots, nil
+}
+
+// firstDate returns the first value that parses as a date.
+func firstDate(values ...string) time.Time {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05 MST", "2006-01-02 15:04:05", "2006-01-02"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// privacyMarkers identify redacted or proxy registrants, which say nothing
+// about who owns the domain and must not count as ownership changes.
+var privacyMarkers = []string{
+	"redacted", "privacy", "private", "proxy", "withheld", "not disclosed",
+	"data protected", "gdpr", "contact privacy", "whoisguard",
+}
+
+// normalizeParty returns a comparable form of a registrant or registrar
+// name, or "" when the value is empty or redacted.
+func normalizeParty(name string) string {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	for _, marker := range privacyMarkers {
+		if strings.Contains(name, marker) {
+			return ""
+		}
+	}
+	name = strings.TrimRight(name, ".")
+	for _, suffix := range []string{", inc", " inc", ", llc", " llc", " ltd", " gmbh", " corp"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	return name
+}
+
+func containsFold(list []string, v string) bool {
+	for _, existing := range list {
+		if normalizeParty(existing) == normalizeParty(v) {
+			return true
+		}
+	}
+	return false
+}
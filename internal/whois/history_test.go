@@ -0,0 +1,65 @@
+package whois
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHistoryLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("apiKey") != "secret" || r.URL.Query().Get("domainName") != "example.com" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"recordsCount": 4, "records": [
+			{"registrarName": "Registrar B, LLC", "audit": {"createdDate": "2021-06-01 00:00:00 UTC"},
+			 "registrantContact": {"organization": "Acme Holdings Inc.", "country": "US"}},
+			{"registrarName": "Registrar A", "audit": {"createdDate": "2010-01-01 00:00:00 UTC"},
+			 "registrantContact": {"name": "Jane Founder", "country": "US"}},
+			{"registrarName": "Registrar B LLC", "audit": {"createdDate": "2023-03-01 00:00:00 UTC"},
+			 "registrantContact": {"organization": "REDACTED FOR PRIVACY"}},
+			{"registrarName": "Registrar A", "audit": {"createdDate": "2015-01-01 00:00:00 UTC"},
+			 "registrantContact": {"name": "Jane Founder", "country": "US"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	h := NewHistoryClient("secret")
+	h.endpoint = server.URL
+
+	history, err := h.Lookup("example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if history.Error != "" {
+		t.Fatalf("unexpected lookup error: %s", history.Error)
+	}
+	if len(history.Snapshots) != 4 || history.Snapshots[0].Registrant != "Jane Founder" {
+		t.Fatalf("expected 4 snapshots oldest first, got %+v", history.Snapshots)
+	}
+	if history.OwnershipChanges != 1 {
+		t.Errorf("expected 1 ownership change (redacted snapshot ignored), got %d", history.OwnershipChanges)
+	}
+	if history.RegistrarChanges != 1 || len(history.Registrars) != 2 {
+		t.Errorf("expected 1 registrar change across 2 registrars, got %d %v", history.RegistrarChanges, history.Registrars)
+	}
+}
+
+func TestHistoryLookup_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	h := NewHistoryClient("bad")
+	h.endpoint = server.URL
+
+	history, err := h.Lookup("example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if history.Error != "WHOIS history lookup returned HTTP 403" {
+		t.Errorf("unexpected error: %q", history.Error)
+	}
+}
@@ -0,0 +1,49 @@
+package whois
+
+import "strings"
+
+// genericAvailablePatterns are phrases most registries use for an
+// unregistered domain.
+var genericAvailablePatterns = []string{
+	"no match",
+	"not found",
+	"no data found",
+	"no entries found",
+	"is available for registration",
+}
+
+// availablePatterns holds registry-specific phrases, keyed by TLD, that are
+// checked in addition to the generic ones.
+var availablePatterns = map[string][]string{
+	".de": {"status: free"},
+	".jp": {"no match!!", "該当するデータがありません"},
+	".kr": {"the requested domain was not found", "등록되어 있지 않습니다", "신청 가능한 도메인"},
+	".uk": {"this domain name has not been registered"},
+	".fr": {"%% no entries found"},
+	".nl": {"is free"},
+	".eu": {"status: available"},
+	".be": {"status: available"},
+	".it": {"status: available"},
+	".ch": {"the queried object does not exist"},
+	".au": {"no data found"},
+	".br": {"no match for"},
+	".cn": {"no matching record"},
+	".se": {"\" not found."},
+}
+
+// isAvailableLine reports whether a WHOIS response line says the domain is
+// unregistered.
+func isAvailableLine(tld, line string) bool {
+	lower := strings.ToLower(line)
+	for _, pattern := range availablePatterns[tld] {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	for _, pattern := range genericAvailablePatterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package whois
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRDAPLookup(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns.json":
+			w.Write([]byte(`{"services": [[["com", "net"], ["` + server.URL + `/com"]]]}`))
+		case "/com/domain/taken.com":
+			w.Write([]byte(`{"ldhName": "TAKEN.COM", "status": ["client transfer prohibited"]}`))
+		case "/com/domain/free.com":
+			http.NotFound(w, r)
+		default:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	c := NewRDAPClient()
+	c.bootstrap = server.URL + "/dns.json"
+
+	taken, _ := c.Lookup("taken.com")
+	if !taken.Registered || len(taken.Status) != 1 || taken.Error != "" {
+		t.Errorf("taken.com: %+v", taken)
+	}
+	free, _ := c.Lookup("free.com")
+	if free.Registered || free.Error != "" {
+		t.Errorf("free.com: %+v", free)
+	}
+	broken, _ := c.Lookup("broken.com")
+	if broken.Error == "" {
+		t.Errorf("broken.com: expected an error, got %+v", broken)
+	}
+	if none, _ := c.Lookup("example.zz"); none.Error == "" || none.Server != "" {
+		t.Errorf("unknown TLD: %+v", none)
+	}
+}
+
+func TestRDAPBootstrapRetry(t *testing.T) {
+	var server *httptest.Server
+	down := true
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/dns.json" && down:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		case r.URL.Path == "/dns.json":
+			w.Write([]byte(`{"services": [[["com"], ["` + server.URL + `/com"]]]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewRDAPClient()
+	c.bootstrap = server.URL + "/dns.json"
+	c.now = func() time.Time { return now }
+
+	if result, _ := c.Lookup("free.com"); !strings.Contains(result.Error, "HTTP 503") {
+		t.Fatalf("expected the bootstrap failure, got %+v", result)
+	}
+	down = false
+	if result, _ := c.Lookup("free.com"); !strings.Contains(result.Error, "HTTP 503") {
+		t.Errorf("expected the failure to be kept until the retry, got %+v", result)
+	}
+	now = now.Add(rdapBootstrapRetry)
+	if result, _ := c.Lookup("free.com"); result.Error != "" || result.Registered {
+		t.Errorf("expected the bootstrap to be loaded again, got %+v", result)
+	}
+}
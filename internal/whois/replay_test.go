@@ -0,0 +1,44 @@
+package whois
+
+import (
+	"testing"
+
+	"d3-domain-tool/internal/vcr"
+)
+
+// TestLookup_Replay runs real Lookup calls against WHOIS responses recorded
+// with -record, so parsing is tested end to end without network access.
+func TestLookup_Replay(t *testing.T) {
+	cassette, err := vcr.Load("testdata/cassette.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient()
+	c.WrapQuery(func(next QueryFunc) QueryFunc {
+		return cassette.WHOIS(next)
+	})
+
+	result, err := c.Lookup("example.com")
+	if err != nil || result.Error != "" {
+		t.Fatalf("unexpected error: %v %s", err, result.Error)
+	}
+	if result.Available || result.Registrar != "RESERVED-Internet Assigned Numbers Authority" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result.RegistrationDate == nil || result.RegistrationDate.Format("2006-01-02") != "1995-08-14" {
+		t.Errorf("unexpected registration date %v", result.RegistrationDate)
+	}
+	if len(result.NameServers) != 2 {
+		t.Errorf("expected 2 name servers, got %v", result.NameServers)
+	}
+
+	result, _ = c.Lookup("unregistered-example-domain.com")
+	if !result.Available {
+		t.Errorf("expected the recorded no-match answer to be available, got %+v", result)
+	}
+
+	result, _ = c.Lookup("not-recorded.com")
+	if result.Error == "" {
+		t.Error("expected an error for a query missing from the cassette")
+	}
+}
@@ -1,61 +1,997 @@
 package main
 
 import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"d3-domain-tool/internal/analyzer"
+	"d3-domain-tool/internal/audit"
+	"d3-domain-tool/internal/baseline"
+	"d3-domain-tool/internal/blockchain"
+	"d3-domain-tool/internal/checker"
+	"d3-domain-tool/internal/cloud"
+	"d3-domain-tool/internal/config"
+	"d3-domain-tool/internal/evidence"
+	"d3-domain-tool/internal/findings"
+	"d3-domain-tool/internal/geodns"
 	"d3-domain-tool/internal/output"
+	"d3-domain-tool/internal/pager"
+	"d3-domain-tool/internal/portfolio"
+	"d3-domain-tool/internal/proxy"
+	"d3-domain-tool/internal/query"
+	"d3-domain-tool/internal/ratelimit"
+	"d3-domain-tool/internal/rawarchive"
+	"d3-domain-tool/internal/signing"
+	"d3-domain-tool/internal/store"
+	"d3-domain-tool/internal/threatintel"
+	"d3-domain-tool/internal/udrp"
+	"d3-domain-tool/internal/usage"
+	"d3-domain-tool/internal/valuation"
+	"d3-domain-tool/internal/vcr"
 )
 
 func main() {
+	// Every HTTP client in the tool uses the default transport; pace all of
+	// them per host with the shared scheduler, and count the calls to paid
+	// providers (refusing those over budget before they are paced). Requests
+	// leave through -proxy or the HTTPS_PROXY environment, like WHOIS.
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.Proxy = proxy.Default.Proxy
+	http.DefaultTransport = usage.Default.Transport(ratelimit.Default.Transport(base))
+
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			path, given, args := config.PathFromArgs(os.Args[2:])
+			cfg, err := config.Load(path, given)
+			if err == nil {
+				err = cmd(append(config.Args(cfg.Commands[os.Args[1]]), args...))
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	var (
-		domain = flag.String("domain", "", "Domain to analyze (required)")
-		format = flag.String("format", "table", "Output format: table, json")
-		help   = flag.Bool("help", false, "Show help message")
+		domain   = flag.String("domain", "", "Domain to analyze (required); separate several with commas for a portfolio run")
+		domFile  = flag.String("file", "", "Read domains from this file, one per line (first column of CSV); - reads stdin")
+		format   = flag.String("format", "table", "Output format: table, json, jsonl, csv, html, stix, cef, leef")
+		outPath  = flag.String("o", "", "Write the report to this file instead of stdout; .json, .jsonl, .csv, .html, .txt, .stix, .cef and .leef set the format")
+		queryStr = flag.String("query", "", "JMESPath expression evaluated against each result; prints its JSON value, e.g. 'whois_data.name_servers'")
+		detail   = flag.String("detail", "", "In multi-domain table output, also show the full report for these comma-separated domains")
+		noPager  = flag.Bool("no-pager", false, "Do not pipe long table output through $PAGER")
+		fieldSel = flag.String("fields", "", "Only output these comma-separated fields, e.g. domain,verdict,whois.expiry_date,valuation.estimated_value")
+		onError  = flag.String("on-error", "degrade", "What to do when a sub-check fails: degrade, warn, fail")
+		failFast = flag.Bool("fail-fast", false, "Abort when any sub-check fails (same as -on-error=fail)")
+		failOn   = flag.String("fail-on", "", "Exit with status 1 when any finding is at least this severe: info, low, medium, high, critical")
+		basePath = flag.String("baseline", "", "Suppress the findings accepted in this baseline file, so only new ones are reported")
+		baseOut  = flag.String("write-baseline", "", "Write the findings of this run to a baseline file that accepts them")
+		baseTTL  = flag.Duration("baseline-ttl", 0, "With -write-baseline, let the suppressions expire after this long, e.g. 2160h (0 = never)")
+		timeout  = flag.Duration("timeout", 0, "Bound each DOMA, blockchain, WHOIS, RDAP and DNS lookup, e.g. 3s (0 = client defaults)")
+		whoisTO  = flag.Duration("whois-timeout", 0, "Bound each WHOIS query, overriding -timeout and the profile")
+		dnsTO    = flag.Duration("dns-timeout", 0, "Bound each DNS lookup, overriding -timeout")
+		verbose  = flag.Bool("verbose-dns", false, "Capture and show raw DNS answers with TTLs")
+		ttls     = flag.Bool("ttl-report", false, "Report record TTLs and DNS change readiness")
+		smtp     = flag.Bool("smtp-probe", false, "Connect to MX hosts to verify mail servers are alive (no mail is sent)")
+		ports    = flag.Bool("scan-ports", false, "Check ports 22, 25, 80, 443 and 8080 on the domain's A records")
+		web      = flag.Bool("web-audit", false, "Audit redirect chain, HSTS and security headers of live domains")
+		preload  = flag.Bool("hsts-preload", false, "Check HSTS preload list membership and preload eligibility")
+		dangle   = flag.Bool("dangling", false, "Detect CNAME/MX/NS/SPF references to hosts that no longer exist")
+		mailSec  = flag.Bool("email-security", false, "Audit SPF (lookup count, permerrors, +all) and DMARC policy with recommendations")
+		seized   = flag.Bool("seizure", false, "Detect law-enforcement seizure nameservers and seizure banners on the home page")
+		signKey  = flag.String("sign-key", "", "Sign JSON results with this ed25519 key file (created if missing)")
+		tsaURL   = flag.String("tsa-url", "", "Obtain an RFC 3161 timestamp for JSON results from this TSA (e.g. https://freetsa.org/tsr)")
+		record   = flag.String("record", "", "Record WHOIS and HTTP traffic into this fixture file for later -replay")
+		replay   = flag.String("replay", "", "Answer WHOIS and HTTP requests from a fixture written with -record instead of the network")
+		evDir    = flag.String("evidence-dir", "", "Save raw WHOIS, DNS answers, HTTP exchanges and the report to a zip in this directory")
+		auditLog = flag.String("audit-log", "", "Append who queried which WHOIS server or API, when and for which domain to this hash-chained log")
+		rawDir   = flag.String("raw-archive", "", "Archive every raw WHOIS and RDAP response, gzip-compressed, in this directory for later re-parsing")
+		rawTTL   = flag.Duration("raw-retention", 0, "With -raw-archive, delete archived responses older than this, e.g. 2160h (0 = keep forever)")
+		proxyURL = flag.String("proxy", "", "Send WHOIS, RDAP and HTTP API traffic through this proxy, e.g. http://proxy:3128 or socks5://127.0.0.1:1080 (default: HTTPS_PROXY)")
+		limits   = flag.String("rate-limits", "", "Per-host request rate overrides, e.g. whois.nic.io=0.5,default=10/20 (requests/sec[/burst])")
+		budgets  = flag.String("budgets", "", "Soft limits of calls per paid provider for this run, e.g. whoisxml=100,eth_rpc=5000: "+strings.Join(usage.Providers, ", "))
+		renewal  = flag.String("renewal-prices", "", "Override yearly renewal prices (USD) per TLD for the carrying cost, e.g. io=45,ai=70")
+		side     = flag.String("negotiate", "", "Add opening, target and walk-away prices to valuations for a buyer or seller")
+		lease    = flag.Bool("lease", false, "Add monthly lease and 12/24/36 month rent-to-own pricing to valuations")
+		capRate  = flag.Float64("cap-rate", valuation.DefaultCapRate, "Yearly return on the estimated value used for -lease pricing")
+		sortBy   = flag.String("sort", "input", "Order of multi-domain results: input, domain, value, expiry")
+		dryRun   = flag.Bool("dry-run", false, "List the servers and APIs that would be contacted without making any calls")
+		altRoots = flag.String("altroot-resolvers", "", "Comma-separated resolvers (host[:port]) for alternative roots such as Namecoin .bit")
+		openNIC  = flag.String("opennic-resolvers", "", "Comma-separated OpenNIC resolvers (host[:port]) for TLDs such as .geek and .libre")
+		geoDNS   = flag.Bool("geodns", false, "Compare answers from several locations to detect GeoDNS / split-horizon routing")
+		history  = flag.String("whois-history-key", "", "WhoisXML API key; adds past registrants, registrars and ownership changes from WHOIS history")
+		disputes = flag.Bool("udrp", false, "Search WIPO and Forum UDRP decisions for disputes over the domain's name")
+		udrpFrom = flag.String("udrp-sources", "", "Replace the UDRP databases: name=url-with-%s, comma-separated")
+		intel    = flag.Bool("threat-intel", false, "Look the domain up in threat-intel feeds (abuse.ch URLhaus and ThreatFox, -threat-feeds) and flag malware and C2 listings")
+		abuseKey = flag.String("abusech-key", os.Getenv("ABUSECH_AUTH_KEY"), "abuse.ch Auth-Key for the URLhaus and ThreatFox feeds of -threat-intel")
+		feedList = flag.String("threat-feeds", "", "Extra -threat-intel feeds: misp=url or taxii=collection-url, comma-separated")
+		mispKey  = flag.String("misp-key", os.Getenv("MISP_API_KEY"), "MISP automation key for misp= feeds")
+		v6Ready  = flag.Bool("ipv6", false, "Grade IPv6 readiness: AAAA records and IPv6 reachability of web, nameservers and MX hosts")
+		ethRPC   = flag.String("eth-rpc", "", "Ethereum JSON-RPC URL, or several comma-separated to fail over; classifies token owners as account, Safe multi-sig, registrar, marketplace or contract")
+		chainDB  = flag.String("chain-cache", "", "Cache -eth-rpc lookups in this file across runs (owner facts for 1h, contract code indefinitely)")
+		chainEv  = flag.Bool("chain-events", false, "With -chain-cache, keep owner facts until ENS Transfer/NewOwner/renewal events touch the name")
+		cacheDir = flag.String("cache-dir", "", "Keep results in this directory and reuse their sections checked within -max-age on later runs")
+		offline  = flag.Bool("offline", false, "Answer only from the results kept in -cache-dir, re-parsing their raw WHOIS and DNS data, with all network access refused")
+		maxAge   = flag.Duration("max-age", 6*time.Hour, "With -cache-dir, fetch again any section checked longer ago than this (0 = fetch everything)")
+		links    = flag.Bool("chain-links", false, "Detect DNS names imported into ENS (DNSSEC) or linked to Unstoppable Domains")
+		udKey    = flag.String("ud-api-key", "", "Unstoppable Domains Resolution API key for -chain-links")
+		domaKey  = flag.String("doma-api-key", os.Getenv("DOMA_API_KEY"), "DOMA API key; looks up the tokenization status of each domain")
+		domaAPI  = flag.String("doma-api", "", "DOMA API base URL (default: https://api.doma.xyz)")
+		subgraph = flag.String("ens-subgraph", "", "ENS subgraph GraphQL URL; adds the registration, renewal and transfer timeline of .eth names")
+		explore  = flag.String("explorers", "", "Block explorer per chain for owner, contract and token links: chain=url, comma-separated")
+		zoneSrc  = flag.String("zones-from", "", "Also analyze the public hosted zones of these DNS providers: route53, clouddns, azure (comma-separated)")
+		profile  = flag.String("profile", "standard", "Analysis depth: quick (DNS only), standard or deep (every passive check, longer WHOIS timeout)")
+		only     = flag.String("only", "", "Run only these comma-separated modules: "+strings.Join(analyzer.Modules, ", "))
+		skip     = flag.String("skip", "", "Skip these comma-separated modules, e.g. whois,doma")
+		noShort  = flag.Bool("no-short-circuit", false, "Run every selected check even when DNS and RDAP show the domain does not exist")
+		geoFrom  = flag.String("geo-vantages", "", "GeoDNS vantage points: name=subnet (EDNS Client Subnet) or name=@resolver[:port], comma-separated")
+		_        = flag.String("config", config.DefaultPath(), "JSON configuration file (YAML is not supported) with flag defaults, API keys and watch-only wallets")
+		noWallet = flag.Bool("no-wallets", false, "Do not add the names held by the configured watch-only wallets")
+		help     = flag.Bool("help", false, "Show help message")
 	)
+	// The configuration file supplies flag defaults, so it is read before
+	// the command line is parsed; -config itself is found by scanning.
+	path, given, _ := config.PathFromArgs(os.Args[1:])
+	cfg, err := config.Load(path, given)
+	if err == nil {
+		err = config.Apply(flag.CommandLine, cfg.Defaults)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 	flag.Parse()
+	if *offline && (*cacheDir == "" || *record != "" || *zoneSrc != "") {
+		fmt.Fprintf(os.Stderr, "Error: -offline needs -cache-dir and cannot be combined with -record or -zones-from\n")
+		os.Exit(1)
+	}
+	// Syncing watch-only wallets queries the subgraph and the UD API, so
+	// -offline skips it.
+	watched := !*noWallet && !*offline && len(cfg.WatchWallets) > 0
 
-	if *help || *domain == "" {
+	// Domains piped in with nothing else to analyze are read from stdin,
+	// as with -file=-.
+	if *domain == "" && *domFile == "" && *zoneSrc == "" && !watched && stdinPiped() {
+		*domFile = "-"
+	}
+	if *help || (*domain == "" && *domFile == "" && *zoneSrc == "" && !watched) {
 		showUsage()
 		return
 	}
 
-	cleanDomain := strings.TrimSpace(strings.ToLower(*domain))
-	if cleanDomain == "" {
+	if *outPath != "" {
+		if inferred, ok := output.FormatFor(*outPath); ok {
+			*format = inferred
+		}
+	}
+	// JSON, JSON Lines and CSV results are printed as each domain finishes
+	// unless they must be sorted first. Table output goes through the
+	// pager and the bulk table needs every result, so it waits for the
+	// whole run.
+	stream := *format != "table" && (*sortBy == "" || *sortBy == "input")
+	// A streamed run reads stdin as it goes, so it can sit at the end of a
+	// pipeline that is still producing names.
+	lazyStdin := *domFile == "-" && stream && !*dryRun
+
+	var domains []string
+	for _, d := range strings.Split(*domain, ",") {
+		if d = analyzer.Canonicalize(d); d != "" {
+			domains = append(domains, d)
+		}
+	}
+	if *domFile != "" && !lazyStdin {
+		listed, err := readDomainFile(*domFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: reading domains: %v\n", err)
+			os.Exit(1)
+		}
+		domains = append(domains, listed...)
+	}
+	if *zoneSrc != "" {
+		providers, err := cloud.ParseProviders(*zoneSrc)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		zones, err := cloud.Domains(providers)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: listing hosted zones: %v\n", err)
+			os.Exit(1)
+		}
+		listed := map[string]bool{}
+		for _, d := range domains {
+			listed[d] = true
+		}
+		for _, z := range zones {
+			if !listed[z] {
+				domains = append(domains, z)
+			}
+		}
+	}
+	if watched {
+		sync := portfolio.SyncWallets(cfg.WatchWallets, walletSources(*subgraph, *udKey))
+		for _, warning := range sync.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+		listed := map[string]bool{}
+		for _, d := range domains {
+			listed[d] = true
+		}
+		added := 0
+		for _, name := range sync.Names() {
+			if !listed[name] {
+				domains = append(domains, name)
+				added++
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Synced %d names from %d watch-only wallets (%d new)\n", len(sync.Names()), sync.Wallets, added)
+	}
+	if len(domains) == 0 && !lazyStdin {
 		fmt.Fprintf(os.Stderr, "Error: Domain cannot be empty\n")
 		os.Exit(1)
 	}
 
-	analyzer := analyzer.New()
-	result, err := analyzer.AnalyzeDomain(cleanDomain)
+	if err := portfolio.Sort(nil, *sortBy); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	policy, err := analyzer.ParseErrorPolicy(*onError)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *failFast {
+		policy = analyzer.PolicyFail
+	}
+	var failSeverity findings.Severity
+	if *failOn != "" {
+		if failSeverity, err = findings.ParseSeverity(*failOn); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -fail-on: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	var accepted *baseline.Baseline
+	if *basePath != "" {
+		if accepted, err = baseline.Load(*basePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -baseline: %v\n", err)
+			os.Exit(1)
+		}
+		if expired := accepted.Expired(time.Now()); len(expired) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: -baseline: %d suppression(s) expired; their findings are reported again\n", len(expired))
+		}
+	}
+
+	if *proxyURL != "" {
+		u, err := proxy.Parse(*proxyURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -proxy: %v\n", err)
+			os.Exit(1)
+		}
+		proxy.Default.Set(u)
+	}
+
+	if *limits != "" {
+		rules, err := ratelimit.ParseRules(*limits)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for host, rule := range rules {
+			ratelimit.Default.Set(host, rule)
+		}
+	}
+
+	if *budgets != "" {
+		limits, err := usage.ParseBudgets(*budgets)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -budgets: %v\n", err)
+			os.Exit(1)
+		}
+		usage.Default.SetBudgets(limits)
+	}
+	usage.Default.AddHosts(usage.EthRPC, splitList(*ethRPC)...)
+	usage.Default.AddHosts(usage.ENSSubgraph, *subgraph)
+
+	if *record != "" && *replay != "" {
+		fmt.Fprintf(os.Stderr, "Error: -record and -replay cannot be combined\n")
+		os.Exit(1)
+	}
+	var auditor *audit.Log
+	if *auditLog != "" {
+		if auditor, err = openAuditLog(*auditLog); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	var rawArchive *rawarchive.Archive
+	if *rawDir != "" {
+		if rawArchive, err = openRawArchive(*rawDir, *rawTTL); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -raw-archive: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *offline {
+		http.DefaultTransport = offlineTransport{}
+	}
+	var cassette *vcr.Cassette
+	if *record != "" {
+		cassette = vcr.NewRecorder()
+	} else if *replay != "" {
+		if cassette, err = vcr.Load(*replay); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Every HTTP client in the tool uses the default transport, so wrapping
+	// it captures all API payloads and web responses.
+	if cassette != nil {
+		http.DefaultTransport = cassette.Transport(http.DefaultTransport)
+	}
+	var bundle *evidence.Bundle
+	if *evDir != "" {
+		bundle = evidence.New()
+		http.DefaultTransport = bundle.Transport(http.DefaultTransport)
+	}
+
+	vantages, err := geodns.ParseVantages(*geoFrom)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	udrpSources, err := udrp.ParseSources(*udrpFrom)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	threatFeeds, err := threatintel.ParseFeeds(*feedList, *mispKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *abuseKey != "" {
+		threatFeeds = append(threatintel.AbuseCH(*abuseKey), threatFeeds...)
+	}
+
+	explorers, err := blockchain.ParseExplorers(*explore)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	depth, err := analyzer.LookupProfile(*profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -profile: %v\n", err)
+		os.Exit(1)
+	}
+	onlyModules, err := analyzer.ParseModules(*only)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -only: %v\n", err)
+		os.Exit(1)
+	}
+	skipModules, err := analyzer.ParseModules(*skip)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -skip: %v\n", err)
+		os.Exit(1)
+	}
+	tlds, err := tldOptions(cfg.TLDs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	renewalPrices, err := valuation.ParseRenewalPrices(*renewal)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error analyzing domain: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	negotiation, err := valuation.ParseSide(*side)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var leaseCapRate float64
+	if *lease {
+		if err := valuation.ValidateCapRate(*capRate); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		leaseCapRate = *capRate
+	}
+
+	var chainCache *blockchain.Cache
+	if *chainDB != "" {
+		if *ethRPC == "" {
+			fmt.Fprintf(os.Stderr, "Error: -chain-cache requires -eth-rpc\n")
+			os.Exit(1)
+		}
+		if chainCache, err = blockchain.LoadCache(*chainDB, *chainEv); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if n, err := chainCache.Sync(blockchain.NewRPCClient(*ethRPC)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: -chain-events: %v; cached owner facts may be stale\n", err)
+		} else if n > 0 {
+			fmt.Fprintf(os.Stderr, "Chain cache: %d entries invalidated by ENS events\n", n)
+		}
+	}
+
+	opts := analyzer.Options{
+		ErrorPolicy: policy,
+		// STIX needs the answers to export the addresses a domain resolves to.
+		VerboseDNS:       *verbose || *format == "stix",
+		TTLReport:        *ttls,
+		SMTPProbe:        *smtp,
+		ScanPorts:        *ports,
+		WebAudit:         *web,
+		HSTSPreload:      *preload,
+		Dangling:         *dangle,
+		EmailSecurity:    *mailSec,
+		Seizure:          *seized,
+		Evidence:         bundle,
+		Cassette:         cassette,
+		AuditLog:         auditor,
+		RawArchive:       rawArchive,
+		AltRootResolvers: splitList(*altRoots),
+		OpenNICResolvers: splitList(*openNIC),
+		GeoDNS:           *geoDNS,
+		GeoVantages:      vantages,
+		IPv6:             *v6Ready,
+		WhoisHistoryKey:  *history,
+		UDRP:             *disputes,
+		UDRPSources:      udrpSources,
+		ThreatIntel:      *intel,
+		ThreatFeeds:      threatFeeds,
+		Explorers:        explorers,
+		EthRPC:           *ethRPC,
+		ChainCache:       chainCache,
+		ENSSubgraph:      *subgraph,
+		ChainLinks:       *links,
+		UDAPIKey:         *udKey,
+		DomaAPIKey:       *domaKey,
+		DomaAPI:          *domaAPI,
+		RenewalPrices:    renewalPrices,
+		Negotiation:      negotiation,
+		LeaseCapRate:     leaseCapRate,
+		Only:             onlyModules,
+		Skip:             skipModules,
+		NoShortCircuit:   *noShort,
+		Timeout:          *timeout,
+		WHOISTimeout:     *whoisTO,
+		DNSTimeout:       *dnsTO,
+		TLDs:             tlds,
+	}
+	var cache *store.Namespace
+	if *cacheDir != "" {
+		st, err := store.Open(*cacheDir)
+		if err == nil {
+			cache, err = st.Namespace("local")
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -cache-dir: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Cache = func(domain string) *analyzer.Result {
+			history, err := cache.History(domain)
+			if err != nil || len(history) == 0 {
+				return nil
+			}
+			return history[len(history)-1]
+		}
+		opts.MaxAge = *maxAge
+	}
+	depth.Apply(&opts)
+	a := analyzer.NewWithOptions(opts)
+
+	var outFile *os.File
+	if *outPath != "" {
+		if outFile, err = os.Create(*outPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	formatter := output.NewFormatter(*format)
-	if err := formatter.Display(result); err != nil {
-		fmt.Fprintf(os.Stderr, "Error displaying results: %v\n", err)
+	if outFile != nil {
+		formatter.SetOutput(outFile)
+	}
+	formatter.SetFields(output.ParseFields(*fieldSel))
+	if *queryStr != "" {
+		expr, err := query.Compile(*queryStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		formatter.SetQuery(expr)
+	}
+
+	if (*signKey != "" || *tsaURL != "") && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: -sign-key and -tsa-url require -format=json\n")
+		os.Exit(1)
+	}
+
+	var key ed25519.PrivateKey
+	if *signKey != "" {
+		var created bool
+		if key, created, err = signing.LoadOrCreateKey(*signKey); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if created {
+			fmt.Fprintf(os.Stderr, "Created signing key %s (public key in %s.pub)\n", *signKey, *signKey)
+		}
+	}
+
+	var tsa *signing.TSAClient
+	if *tsaURL != "" {
+		tsa = signing.NewTSAClient(*tsaURL)
+	}
+
+	// show prints v through display, or as a signed and/or timestamped
+	// envelope when a signing key or TSA is configured.
+	show := func(v any, display func() error) error {
+		if key == nil && tsa == nil {
+			return display()
+		}
+		payload, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var env *signing.Envelope
+		if key != nil {
+			env, err = signing.Sign(key, payload)
+		} else {
+			env, err = signing.NewEnvelope(payload)
+		}
+		if err != nil {
+			return err
+		}
+		if tsa != nil {
+			if err := tsa.Stamp(env); err != nil {
+				return err
+			}
+		}
+		return formatter.DisplaySigned(env)
+	}
+
+	// Table reports are paged on a terminal; stopPager flushes and waits
+	// for the pager and must run before exiting.
+	stopPager := func() {}
+	defer func() { stopPager() }()
+
+	// The pager is for a terminal, not a report file.
+	paged := *format == "table" && !*noPager && outFile == nil
+
+	if *dryRun {
+		if paged {
+			stopPager = pager.Start()
+		}
+		for _, d := range domains {
+			plan, err := a.Plan(d)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error planning domain %s: %v\n", d, err)
+				stopPager()
+				os.Exit(1)
+			}
+			if tsa != nil {
+				plan.Calls = append(plan.Calls, analyzer.PlannedCall{Section: "timestamp", Protocol: "https", Target: *tsaURL, Purpose: "RFC 3161 timestamp of the JSON result"})
+			}
+			if err := formatter.DisplayPlan(plan); err != nil {
+				fmt.Fprintf(os.Stderr, "Error displaying plan: %v\n", err)
+				stopPager()
+				os.Exit(1)
+			}
+		}
+		return
+	}
+
+	// display prints one result in the requested format, signed when
+	// configured.
+	display := func(result *analyzer.Result) {
+		payload, err := formatter.Payload(result)
+		if err == nil {
+			err = show(payload, func() error { return formatter.Display(result) })
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error displaying results: %v\n", err)
+			stopPager()
+			os.Exit(1)
+		}
+	}
+
+	grid := *format == "table" && len(domains) > 1 && *queryStr == ""
+
+	// queue yields the domains to analyze: those known up front, then
+	// under lazyStdin each new line of stdin as it arrives.
+	queue := make(chan string)
+	var inputErr error
+	go func() {
+		defer close(queue)
+		listed := map[string]bool{}
+		for _, d := range domains {
+			listed[d] = true
+			queue <- d
+		}
+		if lazyStdin {
+			inputErr = portfolio.ScanDomains(os.Stdin, func(d string) {
+				if !listed[d] {
+					listed[d] = true
+					queue <- d
+				}
+			})
+		}
+	}()
+
+	var results []*analyzer.Result
+	stats := portfolio.NewStats()
+	for d := range queue {
+		started := time.Now()
+		var result *analyzer.Result
+		if *offline {
+			result, err = cachedResult(a, cache, d)
+		} else {
+			result, err = a.AnalyzeDomain(d)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error analyzing domain %s: %v\n", d, err)
+			stopPager()
+			os.Exit(1)
+		}
+		if accepted != nil {
+			accepted.Apply(result, time.Now())
+		}
+
+		if policy == analyzer.PolicyWarn {
+			for _, se := range result.SectionErrors {
+				fmt.Fprintf(os.Stderr, "Warning: %s: %s: %s\n", d, se.Section, se.Error)
+			}
+		}
+
+		if bundle != nil {
+			if err := bundle.AddJSON(evidence.WithScope(context.Background(), result.Domain), "report.json", result); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: -evidence-dir: %v\n", err)
+			}
+		}
+		if cache != nil && !*offline {
+			if err := cache.Record(result); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: -cache-dir: %v\n", err)
+			}
+		}
+		stats.Add(result, time.Since(started))
+		results = append(results, result)
+		if stream {
+			display(result)
+		}
+	}
+	if inputErr != nil {
+		fmt.Fprintf(os.Stderr, "Error: reading domains: %v\n", inputErr)
+		stopPager()
 		os.Exit(1)
 	}
+	if chainCache != nil {
+		stats.AddCache(chainCache.Stats())
+		if err := chainCache.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: saving chain cache: %v\n", err)
+		}
+	}
+	stats.AddUsage(usage.Default.Snapshot())
+	stats.Finish()
+
+	if err := portfolio.Sort(results, *sortBy); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		stopPager()
+		os.Exit(1)
+	}
+	if paged {
+		stopPager = pager.Start()
+	}
+	if grid {
+		// Bulk table runs get one line per domain; full reports only for
+		// the domains named in -detail.
+		if err := formatter.DisplayGrid(results); err != nil {
+			fmt.Fprintf(os.Stderr, "Error displaying results: %v\n", err)
+			stopPager()
+			os.Exit(1)
+		}
+		for _, d := range splitList(strings.ToLower(*detail)) {
+			found := false
+			for _, result := range results {
+				if result.Domain == d {
+					found = true
+					detailed := output.NewFormatter("table")
+					if outFile != nil {
+						detailed.SetOutput(outFile)
+					}
+					if err := detailed.Display(result); err != nil {
+						fmt.Fprintf(os.Stderr, "Error displaying results: %v\n", err)
+						stopPager()
+						os.Exit(1)
+					}
+				}
+			}
+			if !found {
+				fmt.Fprintf(os.Stderr, "Warning: -detail: %s was not analyzed\n", d)
+			}
+		}
+	} else if !stream {
+		for _, result := range results {
+			display(result)
+		}
+	}
+
+	// Portfolio summary across all analyzed domains
+	if len(results) > 1 && *queryStr == "" {
+		summary := portfolio.Summarize(results)
+		payload := map[string]*portfolio.Summary{"portfolio": summary}
+		if err := show(payload, func() error { return formatter.DisplayPortfolio(summary) }); err != nil {
+			fmt.Fprintf(os.Stderr, "Error displaying portfolio summary: %v\n", err)
+			stopPager()
+			os.Exit(1)
+		}
+		if bundle != nil {
+			if err := bundle.AddJSON(context.Background(), "portfolio.json", payload); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: -evidence-dir: %v\n", err)
+			}
+		}
+	}
+
+	// Run statistics for pipeline health monitoring
+	if len(results) > 1 {
+		if *queryStr != "" {
+			fmt.Fprintf(os.Stderr, "Run: %s\n", stats.Line())
+		} else {
+			payload := map[string]*portfolio.Stats{"run_stats": stats}
+			if err := show(payload, func() error { return formatter.DisplayRunStats(stats) }); err != nil {
+				fmt.Fprintf(os.Stderr, "Error displaying run statistics: %v\n", err)
+				stopPager()
+				os.Exit(1)
+			}
+		}
+	}
+
+	if outFile != nil {
+		err := formatter.Close()
+		if closeErr := outFile.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: writing %s: %v\n", *outPath, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Report written to %s\n", *outPath)
+	}
+
+	if *record != "" {
+		if err := cassette.Save(*record); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			stopPager()
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Recorded %d interactions to %s\n", len(cassette.Interactions), *record)
+	}
+
+	if bundle != nil {
+		path, err := bundle.WriteZip(*evDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			stopPager()
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Evidence bundle written to %s\n", path)
+	}
+
+	if *baseOut != "" {
+		written := baseline.FromResults(results, accepted, *baseTTL, time.Now())
+		if err := baseline.Save(*baseOut, written); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -write-baseline: %v\n", err)
+			stopPager()
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Baseline of %d finding(s) written to %s\n", len(written.Suppressions), *baseOut)
+	}
+
+	// -fail-on gates CI: the report is complete, but the exit status
+	// says whether any finding reached the threshold.
+	if *failOn != "" {
+		var failed []string
+		for _, result := range results {
+			for _, fd := range findings.AtLeast(result.Findings, failSeverity) {
+				failed = append(failed, fmt.Sprintf("%s: [%s] %s: %s", result.Domain, fd.Severity, fd.ID, fd.Detail))
+			}
+		}
+		if len(failed) > 0 {
+			stopPager()
+			for _, line := range failed {
+				fmt.Fprintf(os.Stderr, "Finding: %s\n", line)
+			}
+			fmt.Fprintf(os.Stderr, "Error: -fail-on=%s: %d finding(s)\n", failSeverity, len(failed))
+			os.Exit(1)
+		}
+	}
+}
+
+// offlineTransport refuses every HTTP request under -offline.
+type offlineTransport struct{}
+
+func (offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("-offline: refusing request to %s", req.URL.Host)
+}
+
+// cachedResult answers for domain from its last result in the cache,
+// re-parsing the raw WHOIS response and DNS answers it kept.
+func cachedResult(a *analyzer.Analyzer, cache *store.Namespace, domain string) (*analyzer.Result, error) {
+	history, err := cache.History(analyzer.Canonicalize(domain))
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("-offline: no cached result (analyze it once online with -cache-dir)")
+	}
+	cached := history[len(history)-1]
+	var whoisRaw string
+	if cached.WhoisData != nil {
+		whoisRaw = cached.WhoisData.RawData
+	}
+	var answers []checker.Record
+	if cached.DNSAvailability != nil {
+		answers = cached.DNSAvailability.Answers
+	}
+	return a.Replay(cached, whoisRaw, answers), nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// tldOptions converts the per-TLD overrides of the configuration file.
+func tldOptions(tlds map[string]config.TLDConfig) (map[string]analyzer.TLDOptions, error) {
+	if len(tlds) == 0 {
+		return nil, nil
+	}
+	opts := make(map[string]analyzer.TLDOptions, len(tlds))
+	for tld, t := range tlds {
+		skip, err := analyzer.ParseModules(strings.Join(t.Skip, ","))
+		if err != nil {
+			return nil, fmt.Errorf("config: tlds: %s: skip: %v", tld, err)
+		}
+		opts[tld] = analyzer.TLDOptions{WHOISTimeout: t.Timeout(), Skip: skip, RDAPServer: t.RDAPURL}
+	}
+	return opts, nil
+}
+
+// openAuditLog opens the audit log at path and logs every HTTP request
+// the process makes; WHOIS queries are logged by passing the log to the
+// analyzer.
+func openAuditLog(path string) (*audit.Log, error) {
+	l, err := audit.Open(path, audit.DefaultActor())
+	if err != nil {
+		return nil, err
+	}
+	http.DefaultTransport = l.Transport(http.DefaultTransport)
+	return l, nil
+}
+
+// openRawArchive opens the raw response archive in dir, prunes the
+// responses older than retention, and archives every RDAP response by
+// wrapping the default transport. WHOIS responses are archived by the
+// analyzer.
+func openRawArchive(dir string, retention time.Duration) (*rawarchive.Archive, error) {
+	a, err := rawarchive.Open(dir, retention)
+	if err != nil {
+		return nil, err
+	}
+	a.Warn = func(err error) {
+		fmt.Fprintf(os.Stderr, "Warning: -raw-archive: %v\n", err)
+	}
+	if _, err := a.Prune(); err != nil {
+		return nil, err
+	}
+	http.DefaultTransport = a.Transport(http.DefaultTransport)
+	return a, nil
+}
+
+// readDomainFile reads a domain list from path, or stdin for "-".
+func readDomainFile(path string) ([]string, error) {
+	if path == "-" {
+		return portfolio.ReadDomains(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return portfolio.ReadDomains(f)
+}
+
+// stdinPiped reports whether stdin is a pipe or a redirected file rather
+// than a terminal or /dev/null.
+func stdinPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && (info.Mode()&os.ModeNamedPipe != 0 || info.Mode().IsRegular())
+}
+
+// walletSources returns the registries watch-only wallets are synced
+// from, given the ENS subgraph URL and Unstoppable Domains API key.
+func walletSources(subgraph, udKey string) []portfolio.WalletSource {
+	ens := portfolio.WalletSource{Registry: "ENS", Unavailable: "ENS subgraph not configured (-ens-subgraph)"}
+	if subgraph != "" {
+		ens.Names = blockchain.NewSubgraphClient(subgraph).NamesOwnedBy
+	}
+	ud := portfolio.WalletSource{Registry: "Unstoppable Domains", Unavailable: "API key not configured (-ud-api-key)"}
+	if udKey != "" {
+		checker := blockchain.NewChecker()
+		checker.SetUDKey(udKey)
+		ud.Names = checker.UDNamesOwnedBy
+	}
+	doma := portfolio.WalletSource{Registry: "DOMA", Unavailable: "DOMA data source not configured"}
+	return []portfolio.WalletSource{ens, ud, doma}
 }
 
 func showUsage() {
 	fmt.Println("D3 Domain Analysis Tool")
 	fmt.Println()
 	fmt.Println("Usage:")
-	fmt.Println("  d3-domain-tool -domain=<domain> [-format=table|json]")
+	fmt.Println("  d3-domain-tool -domain=<domain> [-format=table|json] [-on-error=degrade|warn|fail] [-fail-fast]")
+	fmt.Println("  d3-domain-tool <command> [options]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  analyze [options]    Re-analyze a saved evidence bundle offline (-from-evidence=<zip>)")
+	fmt.Println("  audit-export <log>   Verify and export an -audit-log (-since, -until, -domain, -actor)")
+	fmt.Println("  audit-iac <file>...  Cross-check Terraform state or zone exports against WHOIS")
+	fmt.Println("  cloudflare           Analyze every zone of a Cloudflare account (-token, -rules)")
+	fmt.Println("  collateral <domain>  Estimate how much can be borrowed against a tokenized domain (-ltv)")
+	fmt.Println("  compare <domain>...  Compare candidate names side by side, ranked by estimated value")
+	fmt.Println("  dns-audit <domain>   Run delegation, SOA, connectivity and syntax tests")
+	fmt.Println("  ens-bulk-renew <name>...  Build an unsigned Safe batch renewing .eth names due soon (-eth-rpc, -within)")
+	fmt.Println("  ens-renewals <name>...  Suggest low-gas hours to renew .eth names (-eth-rpc)")
+	fmt.Println("  history <domain>     Show past analyses kept with -cache-dir and what changed between them")
+	fmt.Println("  identity <name>      Show which domains, blockchain names and social handles of a name are secured")
+	fmt.Println("  monitor [add|remove|list|import]  Check scheduled domains (@daily, */15m, cron) and notify on change")
+	fmt.Println("  monitor-brand        Match newly registered domains against brand keywords (-keywords, -feed)")
+	fmt.Println("  policy <domain>...   Check domains against a policy file (-rules); exits 1 when a rule fails")
+	fmt.Println("  raw-archive <domain>  List or re-parse the responses kept with -raw-archive (-dir, -reparse)")
+	fmt.Println("  rpc-health           Check the latest block and latency of each Ethereum RPC endpoint (-eth-rpc)")
+	fmt.Println("  serve                Serve analyses over HTTP: REST (/v1/analyze) and GraphQL (/graphql)")
+	fmt.Println("  subdomains <domain>  Enumerate resolving subdomains (wordlist + CT logs)")
+	fmt.Println("  suggest <keyword>    Generate names from a keyword and rank them by availability and value")
+	fmt.Println("  verify <file>        Check signatures on results written with -sign-key")
+	fmt.Println("  version              Show the version, commit, build date and supported TLDs and modules")
+	fmt.Println("  watch <domain>...    Re-analyze on an interval and report availability, expiry and tokenization changes")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  d3-domain-tool -domain=example.com")
 	fmt.Println("  d3-domain-tool -domain=mydomain.eth -format=json")
+	fmt.Println("  d3-domain-tool -domain=example.com -fail-fast")
+	fmt.Println("  d3-domain-tool -file=portfolio.txt -profile=deep -fail-on=high")
+	fmt.Println("  d3-domain-tool -domain=example.com -only=dns,valuation")
+	fmt.Println("  d3-domain-tool -domain=example.com -profile=deep")
+	fmt.Println("  d3-domain-tool -domain=example.com,example.net,example.org")
+	fmt.Println("  d3-domain-tool -zones-from=route53,clouddns -format=csv")
+	fmt.Println("  d3-domain-tool -domain=example.com -format=json -sign-key=report.key > report.json")
 	fmt.Println()
 	fmt.Println("Features:")
 	fmt.Println("  ✅ Check domain availability (DNS + blockchain)")
 	fmt.Println("  🔍 WHOIS data and blockchain metadata")
 	fmt.Println("  💰 Domain value estimation")
 	fmt.Println("  📦 Clean CLI output")
-}
\ No newline at end of file
+}